@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// NameMapping は対応表の1行（旧ファイル名と新しいコメント・タグ）を表す
+type NameMapping struct {
+	Line    int      `json:"-"`           // 行番号（エラー表示用、JSONの場合は要素の番号）
+	OldName string   `json:"old_name"`    // 対象ファイルのファイル名
+	Comment string   `json:"new_comment"` // 新しいコメント（空の場合は変更しない）
+	Tags    []string `json:"tags"`        // 新しいタグ（nil の場合は変更しない）
+}
+
+// ApplyMapOptions は対応表による一括リネーム操作のオプションを表す
+type ApplyMapOptions struct {
+	Writer    io.Writer                 // 出力先
+	DryRun    bool                      // 実際にはリネームしない
+	TagsFile  string                    // タグ定義ファイル（空の場合は tags.toml、ResolveTagsFile で解決する）
+	Sanitizer parakeet.CommentSanitizer // フォーマットされていないファイル名からコメントを作るときのルール
+	Journal   *Journal                  // 実行したリネームを記録するジャーナル（nil の場合は記録しない）
+	Vault     *ObsidianVault            // リネームに合わせて wiki リンクを書き換える Obsidian vault（nil の場合は書き換えない）
+	Hooks     *HookRunner               // リネームの前後に実行するフック（nil の場合は実行しない）
+}
+
+// ApplyMapResult は対応表による一括リネーム操作の結果を表す
+type ApplyMapResult struct {
+	Renamed   map[string]string // リネームしたファイル: 旧ファイル名 -> 新ファイル名
+	Unchanged int               // ファイル名が変わらない行の数
+}
+
+// ReadNameMapping は対応表を読み込む
+// path の拡張子が .json の場合は {"old_name", "new_comment", "tags"} の配列、それ以外はCSV（old_name,new_comment,tags）として読み込む
+func ReadNameMapping(path string) ([]NameMapping, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open name mapping: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return ReadNameMappingJSON(file)
+	}
+	return ReadNameMappingCSV(file)
+}
+
+// ReadNameMappingCSV はCSV（old_name,new_comment,tags の3列、tags は省略可）から対応表を読み込む
+// タグはカンマまたは空白で区切り、空の場合は変更しない。先頭行が "old_name" で始まる場合はヘッダーとして読み飛ばす
+func ReadNameMappingCSV(r io.Reader) ([]NameMapping, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read name mapping: %w", err)
+	}
+
+	mappings := []NameMapping{}
+	for i, record := range records {
+		if len(record) < 2 || len(record) > 3 {
+			return nil, fmt.Errorf("line %d: expected old_name,new_comment,tags but got %d fields", i+1, len(record))
+		}
+
+		oldName := strings.TrimSpace(record[0])
+		if i == 0 && strings.EqualFold(oldName, "old_name") {
+			continue
+		}
+
+		mapping := NameMapping{Line: i + 1, OldName: oldName, Comment: record[1]}
+		if len(record) == 3 && strings.TrimSpace(record[2]) != "" {
+			mapping.Tags = strings.FieldsFunc(record[2], func(r rune) bool {
+				return r == ',' || unicode.IsSpace(r)
+			})
+		}
+		mappings = append(mappings, mapping)
+	}
+
+	return mappings, nil
+}
+
+// ReadNameMappingJSON はJSONの配列から対応表を読み込む
+// tags を省略した場合は変更せず、空の配列の場合はすべてのタグを外す
+func ReadNameMappingJSON(r io.Reader) ([]NameMapping, error) {
+	mappings := []NameMapping{}
+	if err := json.NewDecoder(r).Decode(&mappings); err != nil {
+		return nil, fmt.Errorf("failed to read name mapping: %w", err)
+	}
+	for i := range mappings {
+		mappings[i].Line = i + 1
+	}
+	return mappings, nil
+}
+
+// ApplyNameMapping はディレクトリ内のファイルを対応表に従って一括でリネームする
+// フォーマット済みのファイルはタイムスタンプを保ち、それ以外のファイルには新しいタイムスタンプを割り当てる
+// すべての行を事前に検証（ファイルの存在・重複・タグ定義・ファイル名の衝突）してから実行し、途中で失敗した場合は元に戻す
+func ApplyNameMapping(targetDir string, mappings []NameMapping, opts ApplyMapOptions) (*ApplyMapResult, error) {
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("renamed", "from", "to", "dry_run")
+
+	// ディレクトリの存在チェック
+	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", targetDir)
+	}
+
+	existingTimestamps, err := CollectExistingTimestamps(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect existing timestamps: %w", err)
+	}
+
+	tagsFile := ResolveTagsFile(targetDir, opts.TagsFile)
+
+	result := &ApplyMapResult{
+		Renamed: make(map[string]string),
+	}
+
+	seen := make(map[string]bool)
+	targets := make(map[string]bool)
+	plans := []renamePlan{}
+	for _, mapping := range mappings {
+		line := mapping.Line
+		oldName := strings.TrimSpace(mapping.OldName)
+		if oldName == "" {
+			return nil, fmt.Errorf("line %d: old_name cannot be empty", line)
+		}
+		if filepath.Base(oldName) != oldName {
+			return nil, fmt.Errorf("line %d: old_name must be a file name in the directory: %s", line, oldName)
+		}
+		if seen[oldName] {
+			return nil, fmt.Errorf("line %d: duplicate file: %s", line, oldName)
+		}
+		seen[oldName] = true
+
+		info, err := os.Stat(filepath.Join(targetDir, oldName))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: file does not exist: %s", line, oldName)
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("line %d: cannot rename directory: %s", line, oldName)
+		}
+
+		components := mappedComponents(oldName, existingTimestamps, opts.Sanitizer)
+		if comment := strings.TrimSpace(mapping.Comment); comment != "" {
+			// タグの区切りやファイル名に使えない文字を取り除く
+			components.Comment = parakeet.SanitizeComment(comment)
+		}
+		if mapping.Tags != nil {
+			if len(mapping.Tags) > 0 {
+				if err := ValidateTags(mapping.Tags, tagsFile); err != nil {
+					return nil, fmt.Errorf("line %d: %w", line, err)
+				}
+			}
+			components.Tags = mergeTags(mapping.Tags)
+		}
+
+		newName, err := components.FormatFileNameStrict()
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		existingTimestamps[components.Timestamp] = true
+
+		if newName == oldName {
+			result.Unchanged++
+			continue
+		}
+		if targets[newName] {
+			return nil, fmt.Errorf("line %d: multiple files would be renamed to: %s", line, newName)
+		}
+		if _, err := os.Lstat(filepath.Join(targetDir, newName)); err == nil {
+			return nil, fmt.Errorf("line %d: target file already exists: %s", line, newName)
+		}
+		targets[newName] = true
+
+		plans = append(plans, renamePlan{From: oldName, To: newName})
+	}
+
+	if !opts.DryRun && len(plans) > 0 {
+		if err := applyRenames(opts.Hooks.Wrap(OSFileSystem), targetDir, plans); err != nil {
+			return nil, err
+		}
+		if err := opts.Journal.Record(targetDir, plans...); err != nil {
+			reporter.Warnf("%v\n", err)
+		}
+		if err := opts.Vault.RewriteLinks(targetDir, plans...); err != nil {
+			reporter.Warnf("%v\n", err)
+		}
+	}
+
+	prefix := ""
+	if opts.DryRun {
+		prefix = "[dry-run] "
+	}
+
+	for _, plan := range plans {
+		result.Renamed[plan.From] = plan.To
+		reporter.Emit("renamed", map[string]any{"from": plan.From, "to": plan.To, "dry_run": opts.DryRun}, "%s✓ Renamed: %s → %s\n", prefix, plan.From, plan.To)
+	}
+
+	// サマリーを出力
+	reporter.Printf("\nApply Map Summary:\n")
+	reporter.Printf("  Rows: %d\n", len(mappings))
+	reporter.Printf("  Renamed: %d\n", len(result.Renamed))
+	reporter.Printf("  Unchanged: %d\n", result.Unchanged)
+
+	return result, nil
+}
+
+// mappedComponents は対応表で変更する前のファイル名の構成要素を返す
+// フォーマット済みのファイル名はそのまま、それ以外は新しいタイムスタンプと元のファイル名のコメントを使う
+func mappedComponents(fileName string, existingTimestamps map[string]bool, sanitizer parakeet.CommentSanitizer) parakeet.FileNameComponents {
+	if components, err := parakeet.ParseFileName(fileName); err == nil {
+		return *components
+	}
+
+	ext := filepath.Ext(fileName)
+	return parakeet.FileNameComponents{
+		Timestamp: parakeet.GenerateUniqueTimestamp(existingTimestamps),
+		Comment:   sanitizer.Sanitize(strings.TrimSuffix(fileName, ext)),
+		Tags:      []string{},
+		Extension: strings.TrimPrefix(ext, "."),
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadNameMappingCSV(t *testing.T) {
+	t.Parallel()
+
+	input := "old_name,new_comment,tags\n20250903T083109--draft.pdf,TCP/IP入門,\"network, book\"\nscan.pdf, \"Notes, revised\"\nmemo.md,,go\n"
+	mappings, err := ReadNameMappingCSV(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, []NameMapping{
+		{Line: 2, OldName: "20250903T083109--draft.pdf", Comment: "TCP/IP入門", Tags: []string{"network", "book"}},
+		{Line: 3, OldName: "scan.pdf", Comment: "Notes, revised"},
+		{Line: 4, OldName: "memo.md", Comment: "", Tags: []string{"go"}},
+	}, mappings)
+
+	_, err = ReadNameMappingCSV(strings.NewReader("scan.pdf\n"))
+	assert.Error(t, err)
+}
+
+func TestReadNameMappingJSON(t *testing.T) {
+	t.Parallel()
+
+	input := `[{"old_name": "scan.pdf", "new_comment": "report", "tags": ["go"]}, {"old_name": "memo.md", "tags": []}]`
+	mappings, err := ReadNameMappingJSON(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, []NameMapping{
+		{Line: 1, OldName: "scan.pdf", Comment: "report", Tags: []string{"go"}},
+		{Line: 2, OldName: "memo.md", Tags: []string{}},
+	}, mappings)
+}
+
+func setupApplyMapDir(t *testing.T) string {
+	t.Helper()
+
+	return newTestDir(t, map[string]string{
+		"20250903T083109--draft__network.pdf": "test",
+		"20250903T083110--memo__go.md":        "test",
+		"scan.pdf":                            "test",
+		"tags.toml":                           "[[tag]]\nkey = \"go\"\ndesc = \"Go\"\n\n[[tag]]\nkey = \"network\"\ndesc = \"Network\"\n",
+	})
+}
+
+func TestApplyNameMapping(t *testing.T) {
+	t.Parallel()
+
+	t.Run("コメントとタグを一括で変更する", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setupApplyMapDir(t)
+
+		mappings := []NameMapping{
+			{Line: 1, OldName: "20250903T083109--draft__network.pdf", Comment: "TCP/IP入門", Tags: []string{"network", "go"}},
+			{Line: 2, OldName: "20250903T083110--memo__go.md", Tags: []string{}},
+			{Line: 3, OldName: "scan.pdf", Comment: "report", Tags: []string{"go"}},
+		}
+		result, err := ApplyNameMapping(tmpDir, mappings, ApplyMapOptions{Writer: &bytes.Buffer{}})
+		require.NoError(t, err)
+		require.Len(t, result.Renamed, 3)
+
+		assert.Equal(t, "20250903T083109--TCP IP入門__go_network.pdf", result.Renamed["20250903T083109--draft__network.pdf"])
+		assert.Equal(t, "20250903T083110--memo.md", result.Renamed["20250903T083110--memo__go.md"])
+		assert.True(t, strings.HasSuffix(result.Renamed["scan.pdf"], "--report__go.pdf"))
+
+		for _, newName := range result.Renamed {
+			_, err := os.Stat(filepath.Join(tmpDir, newName))
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("変わらない行は数えるだけ", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setupApplyMapDir(t)
+
+		mappings := []NameMapping{{Line: 1, OldName: "20250903T083110--memo__go.md", Comment: "memo"}}
+		result, err := ApplyNameMapping(tmpDir, mappings, ApplyMapOptions{Writer: &bytes.Buffer{}})
+		require.NoError(t, err)
+		assert.Empty(t, result.Renamed)
+		assert.Equal(t, 1, result.Unchanged)
+	})
+
+	t.Run("dry-run ではリネームしない", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setupApplyMapDir(t)
+
+		mappings := []NameMapping{{Line: 1, OldName: "scan.pdf", Comment: "report"}}
+		result, err := ApplyNameMapping(tmpDir, mappings, ApplyMapOptions{Writer: &bytes.Buffer{}, DryRun: true})
+		require.NoError(t, err)
+		assert.Len(t, result.Renamed, 1)
+
+		_, err = os.Stat(filepath.Join(tmpDir, "scan.pdf"))
+		assert.NoError(t, err)
+	})
+
+	errorTests := []struct {
+		name     string
+		mappings []NameMapping
+		expected string
+	}{
+		{"存在しないファイル", []NameMapping{{Line: 1, OldName: "missing.pdf", Comment: "x"}}, "line 1: file does not exist"},
+		{"重複したファイル", []NameMapping{{Line: 1, OldName: "scan.pdf", Comment: "a"}, {Line: 2, OldName: "scan.pdf", Comment: "b"}}, "line 2: duplicate file"},
+		{"未定義のタグ", []NameMapping{{Line: 1, OldName: "scan.pdf", Tags: []string{"unknown"}}}, "line 1:"},
+		{"ディレクトリ外のファイル", []NameMapping{{Line: 1, OldName: "../scan.pdf", Comment: "x"}}, "must be a file name"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir := setupApplyMapDir(t)
+
+			_, err := ApplyNameMapping(tmpDir, tt.mappings, ApplyMapOptions{Writer: &bytes.Buffer{}})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+
+			// 1つもリネームしない
+			_, err = os.Stat(filepath.Join(tmpDir, "scan.pdf"))
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// ArchiveBy はアーカイブ先のサブディレクトリの単位を表す
+type ArchiveBy string
+
+// アーカイブ先のサブディレクトリの単位
+const (
+	ArchiveByYear  ArchiveBy = "year"  // 2024/
+	ArchiveByMonth ArchiveBy = "month" // 2024/06/
+)
+
+// ParseArchiveBy は文字列からアーカイブ先のサブディレクトリの単位を取得する
+// 空文字列の場合は year を返す
+func ParseArchiveBy(s string) (ArchiveBy, error) {
+	switch by := ArchiveBy(s); by {
+	case "":
+		return ArchiveByYear, nil
+	case ArchiveByYear, ArchiveByMonth:
+		return by, nil
+	default:
+		return "", fmt.Errorf("unknown archive unit: %s (expected year or month)", s)
+	}
+}
+
+// Dir はタイムスタンプの日時に対応するアーカイブ先のサブディレクトリを返す
+func (b ArchiveBy) Dir(t time.Time) string {
+	if b == ArchiveByMonth {
+		return filepath.Join(t.Format("2006"), t.Format("01"))
+	}
+	return t.Format("2006")
+}
+
+// ArchiveOptions はアーカイブ操作のオプションを表す
+type ArchiveOptions struct {
+	Writer io.Writer // 出力先
+	FilterOptions
+	By        ArchiveBy   // サブディレクトリの単位（空の場合は year）
+	OlderThan time.Time   // この日時より前のタイムスタンプのファイルのみ移動する（ゼロ値の場合はすべて）
+	DryRun    bool        // 実際には移動しない
+	Journal   *Journal    // 実行した移動を記録するジャーナル（nil の場合は記録しない）
+	Hooks     *HookRunner // リネームの前後に実行するフック（nil の場合は実行しない）
+}
+
+// ArchiveResult はアーカイブ操作の結果を表す
+type ArchiveResult struct {
+	Moved   map[string]string // 移動したファイル: ファイル名 -> ディレクトリからの相対パス
+	Kept    int               // OlderThan より新しいため移動しなかったファイル数
+	Skipped []string          // 移動先に同名のファイルがあるため移動しなかったファイル
+}
+
+// ArchiveFiles はディレクトリ直下のフォーマット済みファイルを、タイムスタンプの年（または年月）のサブディレクトリに移動する
+// ファイル名は変更しない。すべての移動を事前に確認してから実行し、途中で失敗した場合は元に戻す
+func ArchiveFiles(targetDir string, opts ArchiveOptions) (*ArchiveResult, error) {
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("moved", "from", "to", "dry_run")
+
+	// ディレクトリの存在チェック
+	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", targetDir)
+	}
+
+	by, err := ParseArchiveBy(string(opts.By))
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	result := &ArchiveResult{
+		Moved:   make(map[string]string),
+		Skipped: []string{},
+	}
+
+	plans := []renamePlan{}
+	dirs := make(map[string]bool)
+	for _, entry := range entries {
+		// ディレクトリとシムはスキップ
+		if entry.IsDir() || IsShim(targetDir, entry) {
+			continue
+		}
+
+		fileName := entry.Name()
+		if !opts.Matches(fileName) {
+			continue
+		}
+
+		components, err := parakeet.ParseFileName(fileName)
+		if err != nil {
+			continue
+		}
+		t, err := time.ParseInLocation(timestampLayout, components.Timestamp, time.Local)
+		if err != nil {
+			continue
+		}
+
+		if !opts.OlderThan.IsZero() && !t.Before(opts.OlderThan) {
+			result.Kept++
+			continue
+		}
+
+		dest := by.Dir(t)
+		to := filepath.Join(dest, fileName)
+		if _, err := os.Lstat(filepath.Join(targetDir, to)); err == nil {
+			reporter.Warnf("target file already exists, skipping: %s\n", filepath.ToSlash(to))
+			result.Skipped = append(result.Skipped, fileName)
+			continue
+		}
+
+		plans = append(plans, renamePlan{From: fileName, To: to})
+		dirs[dest] = true
+	}
+
+	prefix := ""
+	if opts.DryRun {
+		prefix = "[dry-run] "
+	}
+
+	if !opts.DryRun && len(plans) > 0 {
+		dests := make([]string, 0, len(dirs))
+		for dest := range dirs {
+			dests = append(dests, dest)
+		}
+		sort.Strings(dests)
+		for _, dest := range dests {
+			if err := os.MkdirAll(filepath.Join(targetDir, dest), 0755); err != nil {
+				return nil, fmt.Errorf("failed to create directory: %w", err)
+			}
+		}
+
+		if err := applyRenames(opts.Hooks.Wrap(OSFileSystem), targetDir, plans); err != nil {
+			return nil, err
+		}
+		if err := opts.Journal.Record(targetDir, plans...); err != nil {
+			reporter.Warnf("%v\n", err)
+		}
+	}
+
+	for _, plan := range plans {
+		to := filepath.ToSlash(plan.To)
+		result.Moved[plan.From] = to
+		reporter.Emit("moved", map[string]any{"from": plan.From, "to": to, "dry_run": opts.DryRun}, "%s✓ Moved: %s → %s\n", prefix, plan.From, to)
+	}
+
+	// サマリーを出力
+	reporter.Printf("\nArchive Summary:\n")
+	reporter.Printf("  Moved: %d\n", len(result.Moved))
+	reporter.Printf("  Kept: %d\n", result.Kept)
+	reporter.Printf("  Skipped: %d\n", len(result.Skipped))
+
+	return result, nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupArchiveDir はアーカイブ対象のファイルを含むディレクトリを作成する
+func setupArchiveDir(t *testing.T) string {
+	t.Helper()
+	return newTestDir(t, map[string]string{
+		"20240615T100000--old__go.pdf": "test",
+		"20241201T100000--winter.md":   "test",
+		"20250301T100000--new.md":      "test",
+		"notes.txt":                    "test",
+	})
+}
+
+func TestArchiveFiles(t *testing.T) {
+	t.Parallel()
+
+	t.Run("年ごと", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setupArchiveDir(t)
+
+		buf := &bytes.Buffer{}
+		result, err := ArchiveFiles(tmpDir, ArchiveOptions{Writer: buf, Journal: NewJournal(tmpDir, "archive")})
+		require.NoError(t, err)
+
+		assert.Len(t, result.Moved, 3)
+		assert.FileExists(t, filepath.Join(tmpDir, "2024", "20240615T100000--old__go.pdf"))
+		assert.FileExists(t, filepath.Join(tmpDir, "2024", "20241201T100000--winter.md"))
+		assert.FileExists(t, filepath.Join(tmpDir, "2025", "20250301T100000--new.md"))
+		assert.FileExists(t, filepath.Join(tmpDir, "notes.txt"))
+		assert.Contains(t, buf.String(), "✓ Moved: 20250301T100000--new.md → 2025/20250301T100000--new.md")
+		assert.Contains(t, buf.String(), "Moved: 3")
+
+		// undo で元に戻せる
+		_, err = UndoLastBatch(tmpDir, UndoOptions{Writer: &bytes.Buffer{}})
+		require.NoError(t, err)
+		assert.FileExists(t, filepath.Join(tmpDir, "20250301T100000--new.md"))
+	})
+
+	t.Run("月ごと、古いファイルのみ", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setupArchiveDir(t)
+
+		olderThan := time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local)
+		result, err := ArchiveFiles(tmpDir, ArchiveOptions{Writer: &bytes.Buffer{}, By: ArchiveByMonth, OlderThan: olderThan})
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]string{
+			"20240615T100000--old__go.pdf": "2024/06/20240615T100000--old__go.pdf",
+			"20241201T100000--winter.md":   "2024/12/20241201T100000--winter.md",
+		}, result.Moved)
+		assert.Equal(t, 1, result.Kept)
+		assert.FileExists(t, filepath.Join(tmpDir, "2024", "06", "20240615T100000--old__go.pdf"))
+		assert.FileExists(t, filepath.Join(tmpDir, "20250301T100000--new.md"))
+	})
+
+	t.Run("dry-run と移動先の重複", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setupArchiveDir(t)
+		require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "2025"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "2025", "20250301T100000--new.md"), []byte("x"), 0644))
+
+		buf := &bytes.Buffer{}
+		result, err := ArchiveFiles(tmpDir, ArchiveOptions{Writer: buf, DryRun: true})
+		require.NoError(t, err)
+
+		assert.Len(t, result.Moved, 2)
+		assert.Equal(t, []string{"20250301T100000--new.md"}, result.Skipped)
+		assert.Contains(t, buf.String(), "[dry-run] ✓ Moved:")
+		assert.NoDirExists(t, filepath.Join(tmpDir, "2024"))
+	})
+
+	t.Run("不明な単位", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setupArchiveDir(t)
+		_, err := ArchiveFiles(tmpDir, ArchiveOptions{Writer: &bytes.Buffer{}, By: "week"})
+		assert.ErrorContains(t, err, "unknown archive unit: week")
+	})
+}
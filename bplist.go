@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"unicode/utf16"
+)
+
+// bplistMagic は macOS のバイナリプロパティリスト（bplist00）の先頭のマジックナンバー
+const bplistMagic = "bplist00"
+
+// bplistTrailerSize はバイナリプロパティリストの末尾のトレーラーのバイト数
+const bplistTrailerSize = 32
+
+// encodeStringArrayPlist は文字列の配列をバイナリプロパティリストにする
+// Finder のタグ（com.apple.metadata:_kMDItemUserTags）の書き込みに使う。ASCII 以外を含む文字列は UTF-16 で書き込む
+func encodeStringArrayPlist(values []string) []byte {
+	numObjects := len(values) + 1
+	refSize := 1
+	if numObjects > 0xff {
+		refSize = 2
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(bplistMagic)
+	offsets := make([]int, 0, numObjects)
+
+	// 先頭のオブジェクトは配列で、続くオブジェクト（文字列）を参照する
+	offsets = append(offsets, buf.Len())
+	writeBplistMarker(&buf, 0xa, len(values))
+	for i := range values {
+		writeBplistUint(&buf, uint64(i+1), refSize)
+	}
+
+	for _, value := range values {
+		offsets = append(offsets, buf.Len())
+		if isASCII(value) {
+			writeBplistMarker(&buf, 0x5, len(value))
+			buf.WriteString(value)
+			continue
+		}
+		units := utf16.Encode([]rune(value))
+		writeBplistMarker(&buf, 0x6, len(units))
+		for _, unit := range units {
+			writeBplistUint(&buf, uint64(unit), 2)
+		}
+	}
+
+	offsetTableOffset := buf.Len()
+	offsetSize := bplistIntSize(uint64(offsetTableOffset))
+	for _, offset := range offsets {
+		writeBplistUint(&buf, uint64(offset), offsetSize)
+	}
+
+	// トレーラー: 未使用6バイト、オフセットのバイト数、参照のバイト数、オブジェクト数、先頭のオブジェクト、オフセット表の位置
+	buf.Write(make([]byte, 6))
+	buf.WriteByte(byte(offsetSize))
+	buf.WriteByte(byte(refSize))
+	writeBplistUint(&buf, uint64(numObjects), 8)
+	writeBplistUint(&buf, 0, 8)
+	writeBplistUint(&buf, uint64(offsetTableOffset), 8)
+
+	return buf.Bytes()
+}
+
+// decodeStringArrayPlist は文字列の配列のバイナリプロパティリストを読み込む
+// Finder のタグの読み込みに使い、配列と文字列以外のオブジェクトはエラーにする
+func decodeStringArrayPlist(data []byte) ([]string, error) {
+	if len(data) < len(bplistMagic)+bplistTrailerSize || string(data[:len(bplistMagic)]) != bplistMagic {
+		return nil, fmt.Errorf("not a binary property list")
+	}
+
+	trailer := data[len(data)-bplistTrailerSize:]
+	offsetSize := int(trailer[6])
+	refSize := int(trailer[7])
+	numObjects := binary.BigEndian.Uint64(trailer[8:16])
+	topObject := binary.BigEndian.Uint64(trailer[16:24])
+	offsetTableOffset := binary.BigEndian.Uint64(trailer[24:32])
+	// 加算・乗算があふれないように、オフセット表が末尾のトレーラーの前に収まるかを割り算で確認する
+	tableEnd := uint64(len(data) - bplistTrailerSize)
+	if offsetSize < 1 || offsetSize > 8 || refSize < 1 || refSize > 8 || topObject >= numObjects ||
+		offsetTableOffset > tableEnd || numObjects > (tableEnd-offsetTableOffset)/uint64(offsetSize) {
+		return nil, fmt.Errorf("invalid binary property list trailer")
+	}
+
+	// オブジェクトはオフセット表より前に収まる
+	objects := data[:offsetTableOffset]
+
+	// objectAt はオブジェクトの番号から先頭の位置を返す
+	objectAt := func(ref uint64) (int, error) {
+		if ref >= numObjects {
+			return 0, fmt.Errorf("invalid object reference: %d", ref)
+		}
+		start := int(offsetTableOffset) + int(ref)*offsetSize
+		offset := readBplistUint(data[start : start+offsetSize])
+		if offset >= offsetTableOffset {
+			return 0, fmt.Errorf("invalid object offset: %d", offset)
+		}
+		return int(offset), nil
+	}
+
+	pos, err := objectAt(topObject)
+	if err != nil {
+		return nil, err
+	}
+	kind, count, pos, err := readBplistMarker(objects, pos)
+	if err != nil {
+		return nil, err
+	}
+	if kind != 0xa {
+		return nil, fmt.Errorf("binary property list is not an array")
+	}
+	if count > (len(objects)-pos)/refSize {
+		return nil, fmt.Errorf("truncated binary property list")
+	}
+
+	values := make([]string, 0, count)
+	for i := range count {
+		ref := readBplistUint(objects[pos+i*refSize : pos+(i+1)*refSize])
+		objPos, err := objectAt(ref)
+		if err != nil {
+			return nil, err
+		}
+		kind, length, objPos, err := readBplistMarker(objects, objPos)
+		if err != nil {
+			return nil, err
+		}
+		switch kind {
+		case 0x5:
+			if length > len(objects)-objPos {
+				return nil, fmt.Errorf("truncated binary property list")
+			}
+			values = append(values, string(objects[objPos:objPos+length]))
+		case 0x6:
+			if length > (len(objects)-objPos)/2 {
+				return nil, fmt.Errorf("truncated binary property list")
+			}
+			units := make([]uint16, length)
+			for j := range units {
+				units[j] = binary.BigEndian.Uint16(objects[objPos+j*2:])
+			}
+			values = append(values, string(utf16.Decode(units)))
+		default:
+			return nil, fmt.Errorf("binary property list array contains a non-string object")
+		}
+	}
+
+	return values, nil
+}
+
+// writeBplistMarker はオブジェクトの種類と長さを書き込む（長さが15以上の場合は続く整数オブジェクトで表す）
+func writeBplistMarker(buf *bytes.Buffer, kind byte, length int) {
+	if length < 0xf {
+		buf.WriteByte(kind<<4 | byte(length))
+		return
+	}
+	buf.WriteByte(kind<<4 | 0xf)
+	size := bplistIntSize(uint64(length))
+	buf.WriteByte(0x10 | byte(bplistSizeExponent(size)))
+	writeBplistUint(buf, uint64(length), size)
+}
+
+// readBplistMarker はオブジェクトの種類と長さを読み込み、内容の先頭の位置を返す
+// 長さはデータの残りのバイト数を超える場合はエラーにする（呼び出し側の位置の計算があふれないようにする）
+func readBplistMarker(data []byte, pos int) (byte, int, int, error) {
+	if pos >= len(data) {
+		return 0, 0, 0, fmt.Errorf("truncated binary property list")
+	}
+	kind, length := data[pos]>>4, int(data[pos]&0xf)
+	pos++
+	if length == 0xf {
+		if pos >= len(data) || data[pos]>>4 != 0x1 {
+			return 0, 0, 0, fmt.Errorf("invalid object length in binary property list")
+		}
+		size := 1 << (data[pos] & 0xf)
+		pos++
+		if size > 8 || size > len(data)-pos {
+			return 0, 0, 0, fmt.Errorf("truncated binary property list")
+		}
+		n := readBplistUint(data[pos : pos+size])
+		pos += size
+		if n > uint64(len(data)-pos) {
+			return 0, 0, 0, fmt.Errorf("truncated binary property list")
+		}
+		length = int(n)
+	}
+	return kind, length, pos, nil
+}
+
+// writeBplistUint は符号なし整数をビッグエンディアンで size バイトに書き込む
+func writeBplistUint(buf *bytes.Buffer, v uint64, size int) {
+	for i := size - 1; i >= 0; i-- {
+		buf.WriteByte(byte(v >> (8 * i)))
+	}
+}
+
+// readBplistUint はビッグエンディアンの符号なし整数を読み込む
+func readBplistUint(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+// bplistIntSize は整数を表すのに必要なバイト数（1, 2, 4, 8）を返す
+func bplistIntSize(v uint64) int {
+	switch {
+	case v <= 0xff:
+		return 1
+	case v <= 0xffff:
+		return 2
+	case v <= 0xffffffff:
+		return 4
+	default:
+		return 8
+	}
+}
+
+// bplistSizeExponent はバイト数を2の指数で返す（整数オブジェクトの種類に使う）
+func bplistSizeExponent(size int) int {
+	exponent := 0
+	for 1<<exponent < size {
+		exponent++
+	}
+	return exponent
+}
+
+// isASCII は文字列が ASCII のみかどうかを返す
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
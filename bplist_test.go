@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringArrayPlist(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		values []string
+	}{
+		{"空", []string{}},
+		{"ASCII", []string{"network", "Red\n6"}},
+		{"ASCII 以外", []string{"仕事", "ネットワーク\n4"}},
+		{"長い文字列", []string{strings.Repeat("a", 300)}},
+		{"多数の要素", strings.Split(strings.Repeat("tag,", 299)+"tag", ",")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			data := encodeStringArrayPlist(tt.values)
+			assert.True(t, strings.HasPrefix(string(data), "bplist00"))
+
+			values, err := decodeStringArrayPlist(data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.values, values)
+		})
+	}
+}
+
+func TestDecodeStringArrayPlist_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := decodeStringArrayPlist([]byte("not a plist"))
+	assert.Error(t, err)
+
+	// 切り詰めたデータ
+	data := encodeStringArrayPlist([]string{"network"})
+	_, err = decodeStringArrayPlist(data[:len(data)-1])
+	assert.Error(t, err)
+
+	// 配列でないオブジェクト（文字列の参照先を整数にする）
+	data = encodeStringArrayPlist([]string{"a"})
+	data[len(bplistMagic)+2] = 0x10
+	_, err = decodeStringArrayPlist(data)
+	assert.Error(t, err)
+}
+
+// craftPlist は先頭を配列とするオブジェクトの生のバイト列からバイナリプロパティリストを組み立てる
+// 参照とオフセットは1バイト、trailer でトレーラーを書き換えられる
+func craftPlist(trailer func([]byte), objects ...[]byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(bplistMagic)
+	offsets := []int{}
+	for _, object := range objects {
+		offsets = append(offsets, buf.Len())
+		buf.Write(object)
+	}
+	offsetTableOffset := buf.Len()
+	for _, offset := range offsets {
+		buf.WriteByte(byte(offset))
+	}
+
+	tail := make([]byte, bplistTrailerSize)
+	tail[6], tail[7] = 1, 1
+	binary.BigEndian.PutUint64(tail[8:16], uint64(len(objects)))
+	binary.BigEndian.PutUint64(tail[24:32], uint64(offsetTableOffset))
+	if trailer != nil {
+		trailer(tail)
+	}
+	buf.Write(tail)
+	return buf.Bytes()
+}
+
+func TestDecodeStringArrayPlist_Hostile(t *testing.T) {
+	t.Parallel()
+
+	max64 := bytes.Repeat([]byte{0xff}, 8)
+	negative := []byte{0x80, 0, 0, 0, 0, 0, 0, 0}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"8バイトの巨大な文字列の長さ", craftPlist(nil, []byte{0xa1, 1}, append([]byte{0x5f, 0x13}, max64...))},
+		{"負になる文字列の長さ", craftPlist(nil, []byte{0xa1, 1}, append([]byte{0x5f, 0x13}, negative...))},
+		{"負になる UTF-16 の長さ", craftPlist(nil, []byte{0xa1, 1}, append([]byte{0x6f, 0x13}, negative...))},
+		{"データより長い文字列", craftPlist(nil, []byte{0xa1, 1}, []byte{0x5f, 0x10, 0x20, 'a'})},
+		{"巨大な配列の長さ", craftPlist(nil, append([]byte{0xaf, 0x13}, max64...))},
+		{"負になる配列の長さ", craftPlist(nil, append([]byte{0xaf, 0x13}, negative...))},
+		{"長さの整数のバイト数が大きすぎる", craftPlist(nil, []byte{0xa1, 1}, []byte{0x5f, 0x1f, 'a'})},
+		{"範囲外の参照", craftPlist(nil, []byte{0xa1, 9}, []byte{0x51, 'a'})},
+		{"巨大なオブジェクト数", craftPlist(func(tail []byte) { copy(tail[8:16], max64) }, []byte{0xa0})},
+		{"巨大なオフセット表の位置", craftPlist(func(tail []byte) { copy(tail[24:32], max64) }, []byte{0xa0})},
+		{"オフセットのバイト数が0", craftPlist(func(tail []byte) { tail[6] = 0 }, []byte{0xa0})},
+		{"参照のバイト数が大きすぎる", craftPlist(func(tail []byte) { tail[7] = 9 }, []byte{0xa0})},
+		{"範囲外の先頭のオブジェクト", craftPlist(func(tail []byte) { copy(tail[16:24], max64) }, []byte{0xa0})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := decodeStringArrayPlist(tt.data)
+			assert.Error(t, err)
+		})
+	}
+
+	// 組み立てた正しいデータは読み込める
+	values, err := decodeStringArrayPlist(craftPlist(nil, []byte{0xa1, 1}, []byte{0x51, 'a'}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, values)
+}
+
+func FuzzDecodeStringArrayPlist(f *testing.F) {
+	f.Add(encodeStringArrayPlist([]string{"network", "仕事"}))
+	f.Add(encodeStringArrayPlist([]string{strings.Repeat("a", 300)}))
+	f.Add(craftPlist(nil, []byte{0xa1, 1}, append([]byte{0x5f, 0x13}, bytes.Repeat([]byte{0xff}, 8)...)))
+
+	// 壊れたデータでもパニックせずにエラーを返す
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, _ = decodeStringArrayPlist(data)
+	})
+}
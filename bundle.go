@@ -0,0 +1,440 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+const (
+	// ManifestFileName はバンドル内のマニフェストのファイル名
+	ManifestFileName = "manifest.json"
+	// bundleFilesDir はバンドル内で管理ファイルを格納するディレクトリ
+	bundleFilesDir = "files"
+)
+
+// ID衝突時の動作
+const (
+	CollisionError  = "error"  // 何も書き込まずにエラーを返す
+	CollisionSkip   = "skip"   // 衝突したファイルを取り込まない
+	CollisionRename = "rename" // 重複しない新しいIDを割り当てる
+)
+
+// bundleConfigFiles はバンドルに含める設定ファイル
+var bundleConfigFiles = []string{TagsFileName, ConfigFileName}
+
+// ManifestEntry はバンドルに含まれる管理ファイルの情報
+type ManifestEntry struct {
+	ID     string `json:"id"`     // タイムスタンプ（ID）
+	File   string `json:"file"`   // ファイル名
+	SHA256 string `json:"sha256"` // 内容のハッシュ
+}
+
+// Manifest はバンドルの内容一覧
+type Manifest struct {
+	CreatedAt string          `json:"created_at"` // 作成日時（RFC3339）
+	Files     []ManifestEntry `json:"files"`      // 管理ファイル
+	Configs   []string        `json:"configs"`    // 設定ファイル
+}
+
+// ExportOptions はバンドル出力操作のオプションを表す
+type ExportOptions struct {
+	Writer io.Writer // 出力先
+	FilterOptions
+	DuplicatePolicy DuplicatePolicy // タイムスタンプ重複の扱い（許可しない重複がある場合はエラー）
+}
+
+// ImportOptions はバンドル取り込み操作のオプションを表す
+type ImportOptions struct {
+	Writer      io.Writer // 出力先
+	OnCollision string    // ID衝突時の動作（error, skip, rename）
+}
+
+// ImportResult はバンドル取り込みの結果を表す
+type ImportResult struct {
+	Imported []string          // 取り込んだファイル名
+	Skipped  []string          // 衝突のため取り込まなかったファイル名
+	Renamed  map[string]string // 新しいIDを割り当てたファイル: 元のファイル名 -> 新しいファイル名
+}
+
+// ExportBundle は管理ディレクトリのファイルと設定を tar.gz のバンドルに書き出す
+func ExportBundle(dirPath, bundlePath string, opts ExportOptions) (*Manifest, error) {
+	reporter := ReporterFor(opts.Writer)
+
+	files, err := collectFilesByID(dirPath, opts.FilterOptions, opts.DuplicatePolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{
+		CreatedAt: time.Now().Format(time.RFC3339),
+		Files:     []ManifestEntry{},
+		Configs:   []string{},
+	}
+
+	out, err := os.Create(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer func() { _ = out.Close() }()
+
+	gw := gzip.NewWriter(out)
+	tw := tar.NewWriter(gw)
+
+	for _, key := range sortedKeys(files) {
+		fileName := files[key]
+		hash, err := addFileToTar(tw, filepath.Join(dirPath, fileName), bundleFilesDir+"/"+fileName)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Files = append(manifest.Files, ManifestEntry{ID: fileID(fileName), File: fileName, SHA256: hash})
+	}
+
+	for _, configFile := range bundleConfigFiles {
+		path := filepath.Join(dirPath, configFile)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if _, err := addFileToTar(tw, path, configFile); err != nil {
+			return nil, err
+		}
+		manifest.Configs = append(manifest.Configs, configFile)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    ManifestFileName,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := gw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	reporter.Successf("Exported %d files to %s\n", len(manifest.Files), bundlePath)
+
+	return manifest, nil
+}
+
+// addFileToTar はファイルを tar に追加し、内容のハッシュを返す
+func addFileToTar(tw *tar.Writer, srcPath, name string) (string, error) {
+	info, err := os.Stat(srcPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to access file: %w", err)
+	}
+
+	f, err := os.Open(srcPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    int64(info.Mode().Perm()),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}); err != nil {
+		return "", fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tw, h), f); err != nil {
+		return "", fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// maxManifestSize はバンドル内のマニフェストの大きさの上限（マニフェストのみメモリに読み込む）
+const maxManifestSize = 64 << 20
+
+// stagedFile は取り込みのために作業用ディレクトリに書き出したバンドルの内容
+type stagedFile struct {
+	path    string      // 作業用ディレクトリ内のパス
+	sha256  string      // 内容のハッシュ
+	mode    os.FileMode // パーミッション
+	modTime time.Time   // 更新日時
+}
+
+// stageBundle はバンドルの各ファイルを stageDir に書き出し、マニフェストと書き出したファイルを返す
+// 内容はメモリに読み込まずにハッシュを計算しながら書き出す。作業用のファイル名は連番にし、バンドル内の名前をパスに使わない
+func stageBundle(in io.Reader, bundlePath, stageDir string) (*Manifest, map[string]stagedFile, error) {
+	gr, err := gzip.NewReader(in)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+	defer func() { _ = gr.Close() }()
+
+	var manifest *Manifest
+	staged := make(map[string]stagedFile)
+	tr := tar.NewReader(gr)
+	for i := 0; ; i++ {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if header.Name == ManifestFileName {
+			if header.Size > maxManifestSize {
+				return nil, nil, fmt.Errorf("manifest is too large: %d bytes", header.Size)
+			}
+			manifest = &Manifest{}
+			if err := json.NewDecoder(io.LimitReader(tr, maxManifestSize)).Decode(manifest); err != nil {
+				return nil, nil, fmt.Errorf("failed to parse manifest: %w", err)
+			}
+			continue
+		}
+
+		path := filepath.Join(stageDir, fmt.Sprint(i))
+		sum, err := stageBundleFile(tr, path)
+		if err != nil {
+			return nil, nil, err
+		}
+		staged[header.Name] = stagedFile{
+			path:    path,
+			sha256:  sum,
+			mode:    os.FileMode(header.Mode).Perm(),
+			modTime: header.ModTime,
+		}
+	}
+
+	if manifest == nil {
+		return nil, nil, fmt.Errorf("manifest not found in bundle: %s", bundlePath)
+	}
+
+	return manifest, staged, nil
+}
+
+// stageBundleFile はバンドルの内容を作業用のファイルに書き出し、内容のハッシュを返す
+func stageBundleFile(r io.Reader, path string) (string, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create file: %w", err)
+	}
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, h), r); err != nil {
+		_ = f.Close()
+		return "", fmt.Errorf("failed to read bundle: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to close file: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// validateBundleName はマニフェストのファイル名が取り込み先のディレクトリ直下を指すか検証する
+// 細工したバンドルで ../ や絶対パスを指定し、取り込み先の外に書き込むことを防ぐ
+func validateBundleName(name string) error {
+	if !filepath.IsLocal(name) || name != filepath.Base(name) {
+		return fmt.Errorf("invalid file name in bundle: %q", name)
+	}
+	return nil
+}
+
+// ImportBundle はバンドルを管理ディレクトリに取り込む
+// 取り込み先に同じIDが存在する場合は OnCollision に従って処理する
+// バンドルの内容は取り込み先の作業用ディレクトリに書き出してから検証し、すべて問題がない場合のみ取り込む
+func ImportBundle(bundlePath, dirPath string, opts ImportOptions) (*ImportResult, error) {
+	reporter := ReporterFor(opts.Writer)
+
+	onCollision := opts.OnCollision
+	if onCollision == "" {
+		onCollision = CollisionError
+	}
+	switch onCollision {
+	case CollisionError, CollisionSkip, CollisionRename:
+	default:
+		return nil, fmt.Errorf("unknown collision policy: %s", onCollision)
+	}
+
+	in, err := os.Open(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer func() { _ = in.Close() }()
+
+	// 作業用ディレクトリは取り込み先と同じファイルシステムに作り、取り込みをリネームで済ませる
+	// 作成した状態ディレクトリは、取り込みに失敗して空のままの場合は削除する
+	stateDir := filepath.Join(dirPath, StateDirName)
+	if _, err := os.Stat(stateDir); os.IsNotExist(err) {
+		defer func() { _ = os.Remove(stateDir) }()
+	}
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	stageDir, err := os.MkdirTemp(stateDir, "import-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(stageDir) }()
+
+	manifest, staged, err := stageBundle(in, bundlePath, stageDir)
+	if err != nil {
+		return nil, err
+	}
+
+	existingTimestamps, err := CollectExistingTimestamps(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect existing timestamps: %w", err)
+	}
+
+	// 書き込む前にファイル名・内容・ID衝突を検証する
+	// IDはマニフェストの値を信用せず、ファイル名から取り出す
+	ids := make(map[string]string, len(manifest.Files))
+	var collisions []string
+	for _, entry := range manifest.Files {
+		if err := validateBundleName(entry.File); err != nil {
+			return nil, err
+		}
+		components, err := parakeet.ParseFileName(entry.File)
+		if err != nil {
+			return nil, fmt.Errorf("file in bundle is not formatted: %s", entry.File)
+		}
+		ids[entry.File] = components.Timestamp
+
+		content, ok := staged[bundleFilesDir+"/"+entry.File]
+		if !ok {
+			return nil, fmt.Errorf("file listed in manifest is missing from bundle: %s", entry.File)
+		}
+		if content.sha256 != entry.SHA256 {
+			return nil, fmt.Errorf("checksum mismatch in bundle: %s", entry.File)
+		}
+		if existingTimestamps[components.Timestamp] {
+			collisions = append(collisions, entry.File)
+		}
+	}
+	for _, configFile := range manifest.Configs {
+		if err := validateBundleName(configFile); err != nil {
+			return nil, err
+		}
+		if !slices.Contains(bundleConfigFiles, configFile) {
+			return nil, fmt.Errorf("unknown config file in bundle: %s", configFile)
+		}
+		if _, ok := staged[configFile]; !ok {
+			return nil, fmt.Errorf("file listed in manifest is missing from bundle: %s", configFile)
+		}
+	}
+	if len(collisions) > 0 && onCollision == CollisionError {
+		sort.Strings(collisions)
+		return nil, fmt.Errorf("ID collision with existing files: %v", collisions)
+	}
+
+	result := &ImportResult{
+		Imported: []string{},
+		Skipped:  []string{},
+		Renamed:  make(map[string]string),
+	}
+
+	for _, entry := range manifest.Files {
+		content := staged[bundleFilesDir+"/"+entry.File]
+		fileName := entry.File
+
+		if existingTimestamps[ids[entry.File]] {
+			if onCollision == CollisionSkip {
+				result.Skipped = append(result.Skipped, fileName)
+				reporter.Warnf("%s (ID collision, skipped)\n", fileName)
+				continue
+			}
+
+			// 元のIDを起点に重複しないIDを割り当てる
+			components, err := parakeet.ParseFileName(fileName)
+			if err != nil {
+				return result, err
+			}
+			base, err := time.ParseInLocation("20060102T150405", components.Timestamp, time.Local)
+			if err != nil {
+				base = time.Now()
+			}
+			components.Timestamp = parakeet.GenerateUniqueTimestampFrom(base, existingTimestamps)
+			fileName, err = components.FormatFileNameStrict()
+			if err != nil {
+				return result, err
+			}
+			result.Renamed[entry.File] = fileName
+			reporter.Warnf("%s → %s (ID collision, renamed)\n", entry.File, fileName)
+		}
+
+		if err := installBundleFile(content, filepath.Join(dirPath, fileName)); err != nil {
+			return result, err
+		}
+
+		if components, err := parakeet.ParseFileName(fileName); err == nil {
+			existingTimestamps[components.Timestamp] = true
+		}
+		result.Imported = append(result.Imported, fileName)
+	}
+
+	// 設定ファイルは既存のものを上書きしない
+	for _, configFile := range manifest.Configs {
+		path := filepath.Join(dirPath, configFile)
+		if _, err := os.Lstat(path); err == nil {
+			reporter.Warnf("%s already exists, skipped\n", configFile)
+			continue
+		}
+		if err := installBundleFile(staged[configFile], path); err != nil {
+			return result, err
+		}
+	}
+
+	reporter.Printf("\nImport Summary:\n")
+	reporter.Printf("  Imported: %d\n", len(result.Imported))
+	reporter.Printf("  Renamed: %d\n", len(result.Renamed))
+	reporter.Printf("  Skipped: %d\n", len(result.Skipped))
+
+	return result, nil
+}
+
+// installBundleFile は作業用ディレクトリに書き出したバンドルの内容を取り込み先に移す（既存のファイルは上書きしない）
+func installBundleFile(content stagedFile, path string) error {
+	if _, err := os.Lstat(path); err == nil {
+		return fmt.Errorf("target file already exists: %s", filepath.Base(path))
+	}
+
+	mode := content.mode
+	if mode == 0 {
+		mode = 0644
+	}
+	if err := os.Chmod(content.path, mode); err != nil {
+		return fmt.Errorf("failed to set file mode: %w", err)
+	}
+	if !content.modTime.IsZero() {
+		if err := os.Chtimes(content.path, content.modTime, content.modTime); err != nil {
+			return fmt.Errorf("failed to set modification time: %w", err)
+		}
+	}
+	if err := os.Rename(content.path, path); err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupBundle(t *testing.T) string {
+	t.Helper()
+
+	srcDir := newTestDir(t, map[string]string{
+		"20250903T083109--TCPIP入門__network.pdf": "tcpip",
+		"20250903T083110--notes.md":             "notes",
+		"invalid-file.txt":                      "skip",
+		TagsFileName:                            "[[tag]]\nkey = \"network\"\n",
+	})
+
+	bundlePath := filepath.Join(srcDir, "out.tar.gz")
+	manifest, err := ExportBundle(srcDir, bundlePath, ExportOptions{Writer: &bytes.Buffer{}})
+	require.NoError(t, err)
+	require.Len(t, manifest.Files, 2)
+	assert.Equal(t, []string{TagsFileName}, manifest.Configs)
+
+	return bundlePath
+}
+
+func TestExportImportBundle(t *testing.T) {
+	t.Parallel()
+	bundlePath := setupBundle(t)
+
+	dstDir, err := os.MkdirTemp("", "parakeet-bundle-dst-*")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dstDir) }()
+
+	buf := &bytes.Buffer{}
+	result, err := ImportBundle(bundlePath, dstDir, ImportOptions{Writer: buf})
+	require.NoError(t, err)
+	assert.Len(t, result.Imported, 2)
+
+	content, err := os.ReadFile(filepath.Join(dstDir, "20250903T083109--TCPIP入門__network.pdf"))
+	require.NoError(t, err)
+	assert.Equal(t, "tcpip", string(content))
+	assert.FileExists(t, filepath.Join(dstDir, TagsFileName))
+	assert.NoFileExists(t, filepath.Join(dstDir, "invalid-file.txt"))
+	assert.Contains(t, buf.String(), "Imported: 2")
+}
+
+func TestImportBundle_Collision(t *testing.T) {
+	t.Parallel()
+	bundlePath := setupBundle(t)
+
+	tests := []struct {
+		name        string
+		onCollision string
+		wantError   bool
+		check       func(t *testing.T, dir string, result *ImportResult)
+	}{
+		{
+			name:        "error",
+			onCollision: CollisionError,
+			wantError:   true,
+		},
+		{
+			name:        "skip",
+			onCollision: CollisionSkip,
+			check: func(t *testing.T, dir string, result *ImportResult) {
+				assert.Equal(t, []string{"20250903T083110--notes.md"}, result.Skipped)
+				assert.Len(t, result.Imported, 1)
+			},
+		},
+		{
+			name:        "rename",
+			onCollision: CollisionRename,
+			check: func(t *testing.T, dir string, result *ImportResult) {
+				assert.Equal(t, "20250903T083111--notes.md", result.Renamed["20250903T083110--notes.md"])
+				assert.FileExists(t, filepath.Join(dir, "20250903T083111--notes.md"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			dstDir, err := os.MkdirTemp("", "parakeet-bundle-collision-*")
+			require.NoError(t, err)
+			defer func() { _ = os.RemoveAll(dstDir) }()
+
+			existing := filepath.Join(dstDir, "20250903T083110--existing.md")
+			require.NoError(t, os.WriteFile(existing, []byte("existing"), 0644))
+
+			result, err := ImportBundle(bundlePath, dstDir, ImportOptions{Writer: &bytes.Buffer{}, OnCollision: tt.onCollision})
+			if tt.wantError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "ID collision")
+				// 何も書き込まれない
+				entries, err := os.ReadDir(dstDir)
+				require.NoError(t, err)
+				assert.Len(t, entries, 1)
+				return
+			}
+			require.NoError(t, err)
+			assert.FileExists(t, existing)
+			tt.check(t, dstDir, result)
+		})
+	}
+}
+
+func TestImportBundle_Errors(t *testing.T) {
+	t.Parallel()
+	_, err := ImportBundle("/non/existent.tar.gz", os.TempDir(), ImportOptions{Writer: &bytes.Buffer{}})
+	assert.Error(t, err)
+
+	_, err = ImportBundle("/non/existent.tar.gz", os.TempDir(), ImportOptions{Writer: &bytes.Buffer{}, OnCollision: "overwrite"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown collision policy")
+}
+
+// writeCraftedBundle はマニフェストと内容を指定してバンドルを書き出す
+func writeCraftedBundle(t *testing.T, path string, manifest Manifest, files map[string]string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	write := func(name string, data []byte) {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data))}))
+		_, err := tw.Write(data)
+		require.NoError(t, err)
+	}
+	for name, content := range files {
+		write(name, []byte(content))
+	}
+	data, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	write(ManifestFileName, data)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+}
+
+func TestImportBundle_CraftedManifest(t *testing.T) {
+	t.Parallel()
+
+	sum := func(content string) string {
+		h := sha256.Sum256([]byte(content))
+		return hex.EncodeToString(h[:])
+	}
+
+	tests := []struct {
+		name     string
+		manifest Manifest
+		files    map[string]string
+		expected string
+	}{
+		{
+			name:     "親ディレクトリへのパス",
+			manifest: Manifest{Files: []ManifestEntry{{ID: "20250903T083109", File: "../20250903T083109--escape.md", SHA256: sum("x")}}},
+			files:    map[string]string{"files/../20250903T083109--escape.md": "x"},
+			expected: "invalid file name in bundle",
+		},
+		{
+			name:     "絶対パスの設定ファイル",
+			manifest: Manifest{Configs: []string{"/tmp/.bashrc"}},
+			files:    map[string]string{"/tmp/.bashrc": "x"},
+			expected: "invalid file name in bundle",
+		},
+		{
+			name:     "サブディレクトリの設定ファイル",
+			manifest: Manifest{Configs: []string{"sub/" + ConfigFileName}},
+			files:    map[string]string{"sub/" + ConfigFileName: "x"},
+			expected: "invalid file name in bundle",
+		},
+		{
+			name:     "知らない設定ファイル",
+			manifest: Manifest{Configs: []string{".bashrc"}},
+			files:    map[string]string{".bashrc": "x"},
+			expected: "unknown config file in bundle",
+		},
+		{
+			name:     "フォーマットされていないファイル",
+			manifest: Manifest{Files: []ManifestEntry{{ID: "20250903T083109", File: "notes.md", SHA256: sum("x")}}},
+			files:    map[string]string{"files/notes.md": "x"},
+			expected: "not formatted",
+		},
+		{
+			// マニフェストのIDではなくファイル名のIDで衝突を判定する
+			name:     "マニフェストのIDが異なる",
+			manifest: Manifest{Files: []ManifestEntry{{ID: "20990101T000000", File: "20250903T083110--notes.md", SHA256: sum("x")}}},
+			files:    map[string]string{"files/20250903T083110--notes.md": "x"},
+			expected: "ID collision",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			root, err := os.MkdirTemp("", "parakeet-bundle-crafted-*")
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = os.RemoveAll(root) })
+
+			dstDir := filepath.Join(root, "dst")
+			require.NoError(t, os.MkdirAll(dstDir, 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(dstDir, "20250903T083110--existing.md"), []byte("existing"), 0644))
+
+			bundlePath := filepath.Join(root, "crafted.tar.gz")
+			writeCraftedBundle(t, bundlePath, tt.manifest, tt.files)
+
+			_, err = ImportBundle(bundlePath, dstDir, ImportOptions{Writer: &bytes.Buffer{}})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+
+			// 取り込み先の外にも中にも何も書き込まれない
+			assert.NoFileExists(t, filepath.Join(root, "20250903T083109--escape.md"))
+			entries, err := os.ReadDir(dstDir)
+			require.NoError(t, err)
+			assert.Len(t, entries, 1)
+		})
+	}
+}
+
+func TestExportBundle_DuplicatePolicy(t *testing.T) {
+	t.Parallel()
+	srcDir := t.TempDir()
+	for _, name := range []string{"20250903T083109--paper.pdf", "20250903T083109--paper.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(srcDir, name), []byte(name), 0644))
+	}
+	bundlePath := filepath.Join(t.TempDir(), "out.tar.gz")
+
+	_, err := ExportBundle(srcDir, bundlePath, ExportOptions{Writer: &bytes.Buffer{}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "duplicate ID 20250903T083109")
+
+	manifest, err := ExportBundle(srcDir, bundlePath, ExportOptions{Writer: &bytes.Buffer{}, DuplicatePolicy: DuplicatePolicyAllowSameBasename})
+	require.NoError(t, err)
+	require.Len(t, manifest.Files, 2)
+	assert.Equal(t, "20250903T083109--paper.pdf", manifest.Files[0].File)
+	assert.Equal(t, "20250903T083109--paper.txt", manifest.Files[1].File)
+	for _, entry := range manifest.Files {
+		assert.Equal(t, "20250903T083109", entry.ID)
+	}
+}
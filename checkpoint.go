@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// StateDirName は parakeet が対象ディレクトリ内に作る作業用ディレクトリの名前
+// ディレクトリのためファイルの一覧には含まれない
+const StateDirName = ".parakeet"
+
+// ErrInterrupted はコンテキストのキャンセル（Ctrl-C など）で一括処理を中断したことを表す
+// 返すエラーはキャンセルの原因（context.Canceled など）も含む
+var ErrInterrupted = errors.New("interrupted")
+
+// Checkpoint は中断した一括処理の進捗を表す
+// --resume で読み込むと、処理済みのファイルをスキップして続きから実行する
+type Checkpoint struct {
+	Command   string   `json:"command"`   // 中断したコマンド
+	Created   string   `json:"created"`   // 作成日時（RFC3339）
+	Processed []string `json:"processed"` // 処理済み（リネーム・コピー・スキップ）の元のファイル名
+}
+
+// CheckpointPath はディレクトリでのコマンドのチェックポイントのパスを返す
+func CheckpointPath(dirPath, command string) string {
+	return filepath.Join(dirPath, StateDirName, "checkpoint-"+command+".json")
+}
+
+// LoadCheckpoint はディレクトリでのコマンドのチェックポイントを読み込む
+func LoadCheckpoint(dirPath, command string) (*Checkpoint, error) {
+	path := CheckpointPath(dirPath, command)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no checkpoint to resume: %s", path)
+		}
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint: %w", err)
+	}
+	if checkpoint.Command != command {
+		return nil, fmt.Errorf("checkpoint is for %s, not %s: %s", checkpoint.Command, command, path)
+	}
+
+	return &checkpoint, nil
+}
+
+// Done はファイルが以前の実行で処理済みかどうかを返す
+// nil の場合はどのファイルも処理済みでない
+func (c *Checkpoint) Done(name string) bool {
+	if c == nil {
+		return false
+	}
+	for _, processed := range c.Processed {
+		if processed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// interruptRun は中断した一括処理のチェックポイントを書き出し、--resume での再開を案内するエラーを返す
+// cause はキャンセルの原因。再開した実行の場合は以前の処理済みのファイルも引き継ぐ。dry-run の場合は書き出さない
+func interruptRun(cause error, dirPath, command string, resumed *Checkpoint, processed []string, dryRun bool) error {
+	if dryRun {
+		return fmt.Errorf("%w: %w", ErrInterrupted, cause)
+	}
+
+	checkpoint := Checkpoint{
+		Command:   command,
+		Created:   time.Now().Format(time.RFC3339),
+		Processed: processed,
+	}
+	if resumed != nil {
+		checkpoint.Processed = append(append([]string{}, resumed.Processed...), processed...)
+	}
+
+	data, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%w: %w: failed to encode checkpoint: %v", ErrInterrupted, cause, err)
+	}
+
+	path := CheckpointPath(dirPath, command)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("%w: %w: failed to create checkpoint directory: %v", ErrInterrupted, cause, err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("%w: %w: failed to write checkpoint: %v", ErrInterrupted, cause, err)
+	}
+
+	return fmt.Errorf("%w: %w: checkpoint written to %s (continue with --resume)", ErrInterrupted, cause, path)
+}
+
+// finishRun は一括処理が最後まで完了した場合に、以前の中断で残したチェックポイントを削除する
+func finishRun(dirPath, command string, dryRun bool) error {
+	if dryRun {
+		return nil
+	}
+
+	path := CheckpointPath(dirPath, command)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove checkpoint: %w", err)
+	}
+	// 作業用ディレクトリが空になった場合のみ削除する
+	_ = os.Remove(filepath.Dir(path))
+
+	return nil
+}
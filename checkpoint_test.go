@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateFileNames_InterruptAndResume(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-checkpoint-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	for _, name := range []string{"a.pdf", "b.pdf", "c.pdf"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("test"), 0644))
+	}
+
+	// 最初のファイルの処理中にキャンセルする
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	opts := RenameOptions{
+		Writer:        &bytes.Buffer{},
+		FilterOptions: FilterOptions{Extensions: []string{"pdf"}},
+		Prompt: func(oldName string, suggested parakeet.FileNameComponents) (parakeet.FileNameComponents, error) {
+			cancel()
+			return suggested, nil
+		},
+	}
+	err = GenerateFileNames(ctx, tmpDir, opts)
+	require.ErrorIs(t, err, ErrInterrupted)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorContains(t, err, "continue with --resume")
+
+	// 処理中のファイルのリネームは完了している
+	invalid, err := GetInvalidFiles(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(tmpDir, "b.pdf"), filepath.Join(tmpDir, "c.pdf")}, invalid)
+
+	checkpoint, err := LoadCheckpoint(tmpDir, "generate")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.pdf"}, checkpoint.Processed)
+
+	// 再開すると残りのファイルを処理し、チェックポイントを削除する
+	require.NoError(t, GenerateFileNames(context.Background(), tmpDir, RenameOptions{
+		Writer:        &bytes.Buffer{},
+		FilterOptions: FilterOptions{Extensions: []string{"pdf"}},
+		Resume:        checkpoint,
+	}))
+	invalid, err = GetInvalidFiles(tmpDir)
+	require.NoError(t, err)
+	assert.Empty(t, invalid)
+	assert.NoFileExists(t, CheckpointPath(tmpDir, "generate"))
+	assert.NoDirExists(t, filepath.Join(tmpDir, StateDirName))
+}
+
+func TestFixFileNames_Resume(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-checkpoint-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	for _, name := range []string{"a.pdf", "b.pdf"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("test"), 0644))
+	}
+
+	// 最初のファイルを断った直後にキャンセルする
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	_, err = FixFileNames(ctx, tmpDir, FixOptions{
+		Writer: &bytes.Buffer{},
+		Confirm: func(oldName, newName string) (bool, error) {
+			cancel()
+			return false, nil
+		},
+	})
+	require.ErrorIs(t, err, ErrInterrupted)
+
+	checkpoint, err := LoadCheckpoint(tmpDir, "fix")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.pdf"}, checkpoint.Processed)
+
+	// 再開した実行では、断ったファイルを再度確認しない
+	asked := []string{}
+	result, err := FixFileNames(context.Background(), tmpDir, FixOptions{
+		Writer: &bytes.Buffer{},
+		Resume: checkpoint,
+		Confirm: func(oldName, newName string) (bool, error) {
+			asked = append(asked, oldName)
+			return true, nil
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b.pdf"}, asked)
+	assert.Len(t, result.Fixed, 1)
+	assert.Equal(t, []string{"a.pdf"}, result.Skipped)
+	assert.NoFileExists(t, CheckpointPath(tmpDir, "fix"))
+}
+
+func TestLoadCheckpoint_Errors(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-checkpoint-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	_, err = LoadCheckpoint(tmpDir, "generate")
+	assert.ErrorContains(t, err, "no checkpoint to resume")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, StateDirName), 0755))
+	require.NoError(t, os.WriteFile(CheckpointPath(tmpDir, "generate"), []byte(`{"command":"fix"}`), 0644))
+	_, err = LoadCheckpoint(tmpDir, "generate")
+	assert.ErrorContains(t, err, "checkpoint is for fix, not generate")
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// CompleteOptions は補完候補の出力操作のオプションを表す
+type CompleteOptions struct {
+	Writer io.Writer // 出力先
+	FilterOptions
+	Prefix   string // 候補の前方一致条件
+	Limit    int    // 出力する候補の数（0 以下の場合は制限なし）
+	Describe bool   // 候補の後にタブ区切りで説明を出力する
+	TagsFile string // タグ定義ファイル（空の場合は tags.toml、ResolveTagsFile で解決する）
+}
+
+// Candidate は補完候補を表す
+type Candidate struct {
+	Value       string // 候補の値
+	Description string // 候補の説明（タグの説明、ファイルのタイトル）
+}
+
+// CompleteTags は前方一致するタグを補完候補として出力し、そのリストを返す
+// ファイルで使われているタグに加えて、tags.toml に定義されたタグも候補にする
+func CompleteTags(targetDir string, opts CompleteOptions) ([]Candidate, error) {
+	index, err := buildCompletionIndex(targetDir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	definitions, err := LoadTagsFromTOML(ResolveTagsFile(targetDir, opts.TagsFile))
+	if err != nil {
+		return nil, err
+	}
+
+	descriptions := make(map[string]string)
+	for _, tag := range index.CompleteTags(opts.Prefix) {
+		descriptions[tag] = ""
+	}
+	lowerPrefix := strings.ToLower(opts.Prefix)
+	for _, def := range definitions {
+		if strings.HasPrefix(strings.ToLower(def.Key), lowerPrefix) {
+			descriptions[def.Key] = def.Desc
+		}
+	}
+
+	candidates := make([]Candidate, 0, len(descriptions))
+	for tag, desc := range descriptions {
+		candidates = append(candidates, Candidate{Value: tag, Description: desc})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Value < candidates[j].Value
+	})
+
+	return emitCandidates(candidates, opts), nil
+}
+
+// CompleteIDs は前方一致するIDを補完候補として出力し、そのリストを返す
+func CompleteIDs(targetDir string, opts CompleteOptions) ([]Candidate, error) {
+	index, err := buildCompletionIndex(targetDir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := index.CompleteIDs(opts.Prefix)
+	candidates := make([]Candidate, 0, len(ids))
+	for _, id := range ids {
+		candidate := Candidate{Value: id}
+		if fileName, ok := index.File(id); ok {
+			if components, err := parakeet.ParseFileName(fileName); err == nil {
+				candidate.Description = components.Comment
+			}
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	return emitCandidates(candidates, opts), nil
+}
+
+// buildCompletionIndex は補完に使うインデックスをディレクトリから作成する
+func buildCompletionIndex(targetDir string, opts CompleteOptions) (*TitleIndex, error) {
+	// ディレクトリの存在チェック
+	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", targetDir)
+	}
+
+	return BuildTitleIndex(targetDir, opts.FilterOptions)
+}
+
+// emitCandidates は候補を1行に1つずつ出力し、出力した候補を返す
+func emitCandidates(candidates []Candidate, opts CompleteOptions) []Candidate {
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("candidate", "value", "description")
+
+	if opts.Limit > 0 && len(candidates) > opts.Limit {
+		candidates = candidates[:opts.Limit]
+	}
+
+	for _, c := range candidates {
+		fields := map[string]any{"value": c.Value, "description": c.Description}
+		if opts.Describe && c.Description != "" {
+			reporter.Emit("candidate", fields, "%s\t%s\n", c.Value, c.Description)
+		} else {
+			reporter.Emit("candidate", fields, "%s\n", c.Value)
+		}
+	}
+
+	return candidates
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupCompleteDir(t *testing.T) string {
+	t.Helper()
+
+	tomlContent := `[[tag]]
+key = "network"
+desc = "ネットワーク"
+
+[[tag]]
+key = "news"
+desc = "ニュース"
+`
+	return newTestDir(t, map[string]string{
+		TagsFileName: tomlContent,
+		"20250903T083109--TCPIP入門__network.pdf": "test content",
+		"20250910T120000--Go入門__go_netbsd.pdf":  "test content",
+		"20251001T000000--メモ.md":                "test content",
+		"notes.txt":                             "test content",
+	})
+}
+
+func TestCompleteTags(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupCompleteDir(t)
+
+	// 使用中のタグと tags.toml に定義されたタグを合わせて候補にする
+	buf := &bytes.Buffer{}
+	candidates, err := CompleteTags(tmpDir, CompleteOptions{Writer: buf, Prefix: "ne"})
+	require.NoError(t, err)
+	assert.Equal(t, []Candidate{
+		{Value: "netbsd"},
+		{Value: "network", Description: "ネットワーク"},
+		{Value: "news", Description: "ニュース"},
+	}, candidates)
+	assert.Equal(t, "netbsd\nnetwork\nnews\n", buf.String())
+
+	// 説明付き・件数制限
+	buf.Reset()
+	_, err = CompleteTags(tmpDir, CompleteOptions{Writer: buf, Prefix: "ne", Limit: 2, Describe: true})
+	require.NoError(t, err)
+	assert.Equal(t, "netbsd\nnetwork\tネットワーク\n", buf.String())
+}
+
+func TestCompleteIDs(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupCompleteDir(t)
+
+	buf := &bytes.Buffer{}
+	candidates, err := CompleteIDs(tmpDir, CompleteOptions{Writer: buf, Prefix: "202509", Describe: true})
+	require.NoError(t, err)
+	assert.Equal(t, []Candidate{
+		{Value: "20250903T083109", Description: "TCPIP入門"},
+		{Value: "20250910T120000", Description: "Go入門"},
+	}, candidates)
+	assert.Equal(t, "20250903T083109\tTCPIP入門\n20250910T120000\tGo入門\n", buf.String())
+
+	// 拡張子フィルタ
+	candidates, err = CompleteIDs(tmpDir, CompleteOptions{Writer: &bytes.Buffer{}, FilterOptions: FilterOptions{Extensions: []string{"md"}}})
+	require.NoError(t, err)
+	assert.Equal(t, []Candidate{{Value: "20251001T000000", Description: "メモ"}}, candidates)
+
+	_, err = CompleteIDs(filepath.Join(tmpDir, "missing"), CompleteOptions{Writer: &bytes.Buffer{}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+	"github.com/pelletier/go-toml/v2"
+)
+
+const (
+	// ConfigFileName は設定ファイルのファイル名
+	ConfigFileName = "parakeet.toml"
+)
+
+// タイムスタンプの取得元
+const (
+	ExtractorNone  = ""      // 現在時刻を使う
+	ExtractorMtime = "mtime" // ファイルの更新日時を使う
+)
+
+// DuplicatePolicy はタイムスタンプを共有するファイルの扱いを表す
+type DuplicatePolicy string
+
+// タイムスタンプ重複の扱い
+const (
+	DuplicatePolicyError             DuplicatePolicy = "error"               // 重複はエラー（デフォルト）
+	DuplicatePolicyWarn              DuplicatePolicy = "warn"                // 重複は警告のみ
+	DuplicatePolicyAllowSameBasename DuplicatePolicy = "allow-same-basename" // 拡張子以外が同じファイル（添付ファイル）の重複は許可し、それ以外はエラー
+	DuplicatePolicyAllowAll          DuplicatePolicy = "allow-all"           // すべての重複を許可
+)
+
+// ParseDuplicatePolicy は文字列からタイムスタンプ重複の扱いを取得する
+// 空文字列の場合はデフォルトの error を返す
+func ParseDuplicatePolicy(s string) (DuplicatePolicy, error) {
+	switch policy := DuplicatePolicy(s); policy {
+	case "":
+		return DuplicatePolicyError, nil
+	case DuplicatePolicyError, DuplicatePolicyWarn, DuplicatePolicyAllowSameBasename, DuplicatePolicyAllowAll:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown duplicate policy: %s (expected error, warn, allow-same-basename or allow-all)", s)
+	}
+}
+
+// Allows はタイムスタンプを共有するファイルの組を許可するかどうかを返す
+func (p DuplicatePolicy) Allows(files []string) bool {
+	switch p {
+	case DuplicatePolicyAllowAll:
+		return true
+	case DuplicatePolicyAllowSameBasename:
+		for _, file := range files[1:] {
+			if duplicateBasename(file) != duplicateBasename(files[0]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// matchKey は別のディレクトリのファイルと対応させるキーを返す
+// 重複を許可する場合はIDだけではファイルが決まらないため、拡張子を含める
+func (p DuplicatePolicy) matchKey(components *parakeet.FileNameComponents) string {
+	if p == DuplicatePolicyAllowSameBasename || p == DuplicatePolicyAllowAll {
+		return components.Timestamp + "." + components.Extension
+	}
+	return components.Timestamp
+}
+
+// Fails はタイムスタンプの重複を失敗とするかどうかを返す
+func (p DuplicatePolicy) Fails() bool {
+	return p != DuplicatePolicyWarn
+}
+
+// duplicateBasename はファイル名から拡張子を除いた部分を返す
+func duplicateBasename(path string) string {
+	name := filepath.Base(path)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// Profile は拡張子ごとの generate の処理ルールを表す
+type Profile struct {
+	Name       string   `toml:"-"`          // プロファイル名（[profile.<name>]）
+	Extensions []string `toml:"extensions"` // 対象拡張子
+	Extractor  string   `toml:"extractor"`  // タイムスタンプの取得元（"" または "mtime"）
+	Tags       []string `toml:"tags"`       // 付与するデフォルトタグ
+	Dest       string   `toml:"dest"`       // 移動先のサブディレクトリ（空の場合は移動しない）
+}
+
+// TagCoverageRule は validate で未定義タグをどこまで許容するかのルールを表す
+// どちらも nil の場合は未定義タグが1つでもあれば失敗する
+type TagCoverageRule struct {
+	MaxUndefinedPercent *float64 `toml:"max_undefined_percent"` // 未定義タグを持つファイルの割合の上限（%）
+	MaxUndefinedTags    *int     `toml:"max_undefined_tags"`    // 異なる未定義タグの数の上限
+}
+
+// Configured はしきい値が設定されているかどうかを返す
+func (r TagCoverageRule) Configured() bool {
+	return r.MaxUndefinedPercent != nil || r.MaxUndefinedTags != nil
+}
+
+// Exceeded は未定義タグがしきい値を超えているかどうかを返す
+// しきい値が設定されていない場合は未定義タグがあれば超えているとみなす
+func (r TagCoverageRule) Exceeded(percent float64, distinct int) bool {
+	if !r.Configured() {
+		return distinct > 0
+	}
+	if r.MaxUndefinedPercent != nil && percent > *r.MaxUndefinedPercent {
+		return true
+	}
+	if r.MaxUndefinedTags != nil && distinct > *r.MaxUndefinedTags {
+		return true
+	}
+	return false
+}
+
+// Config は設定ファイル全体の構造
+type Config struct {
+	Profile         map[string]Profile        `toml:"profile"`
+	Validate        TagCoverageRule           `toml:"validate"`            // validate の未定義タグのしきい値
+	DuplicatePolicy DuplicatePolicy           `toml:"duplicate_policy"`    // タイムスタンプ重複の扱い（generate, validate, doctor, dedup, diff, sync, export で使う）
+	TagsFile        string                    `toml:"tags_file"`           // タグ定義ファイル（対象ディレクトリからの相対パス、空の場合は tags.toml）
+	MaxNameBytes    int                       `toml:"max_name_bytes"`      // generate で生成するファイル名の長さの上限（バイト数、0 の場合は 255）
+	NameBudget      NameBudgetPolicy          `toml:"name_budget"`         // ファイル名が上限を超えた場合の短縮方法
+	Sanitize        parakeet.CommentSanitizer `toml:"sanitize"`            // generate で元のファイル名からコメントを作るときのルール
+	Quota           QuotaConfig               `toml:"quota"`               // stats と validate で警告するディレクトリ・タグごとの上限
+	Legacy          []LegacyRecognizer        `toml:"legacy"`              // generate --legacy で組み込みのルールより先に適用する旧命名規則のルール
+	Open            map[string]string         `toml:"open"`                // open で拡張子ごとに使うアプリケーションのコマンド（例: pdf = "zathura"）
+	Hooks           HookConfig                `toml:"hooks"`               // リネームの前後に実行するシェルコマンド
+	Rules           RuleSeverities            `toml:"rules"`               // validate のルールごとの重さ（例: max-length = "warning"）
+	CustomRules     []CustomRule              `toml:"custom_rule"`         // validate で組み込みのルールに加えて検査するルール
+	Precision       parakeet.Precision        `toml:"timestamp_precision"` // generate で生成するタイムスタンプの精度（second または millisecond）
+	XattrTags       bool                      `toml:"xattr_tags"`          // tag で変更したタグをファイルの拡張属性（user.xdg.tags、Finder のタグ）にも書き込む
+	Portable        bool                      `toml:"portable"`            // Windows 以外でも generate と validate で Windows で使えるファイル名に限定する
+	IDSearchDepth   int                       `toml:"id_search_depth"`     // IDでファイルを検索するサブディレクトリの深さ（0 の場合はサブディレクトリを検索しない）
+}
+
+// LoadConfig は設定ファイルを読み込む
+// ファイルが存在しない場合は空の設定を返す
+func LoadConfig(filePath string) (*Config, error) {
+	// ファイルが存在しない場合は空の設定を返す
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		return &Config{}, nil
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var config Config
+	if err := toml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	if err := config.Rules.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid [rules] in config file: %w", err)
+	}
+	customRules, err := CompileCustomRules(config.CustomRules)
+	if err != nil {
+		return nil, fmt.Errorf("invalid [[custom_rule]] in config file: %w", err)
+	}
+	config.CustomRules = customRules
+
+	for name, profile := range config.Profile {
+		switch profile.Extractor {
+		case ExtractorNone, ExtractorMtime:
+		default:
+			return nil, fmt.Errorf("unknown extractor in profile %s: %s", name, profile.Extractor)
+		}
+		if len(profile.Extensions) == 0 {
+			return nil, fmt.Errorf("profile %s has no extensions", name)
+		}
+	}
+
+	if config.DuplicatePolicy, err = ParseDuplicatePolicy(string(config.DuplicatePolicy)); err != nil {
+		return nil, err
+	}
+	if config.NameBudget, err = ParseNameBudgetPolicy(string(config.NameBudget)); err != nil {
+		return nil, err
+	}
+	if err := config.Sanitize.Validate(); err != nil {
+		return nil, err
+	}
+	if err := config.Quota.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := NewLegacyMatcher(config.Legacy); err != nil {
+		return nil, err
+	}
+	for ext, app := range config.Open {
+		if strings.TrimSpace(app) == "" {
+			return nil, fmt.Errorf("open command for %s is empty", ext)
+		}
+	}
+	if _, err := parakeet.ParsePrecision(string(config.Precision)); err != nil {
+		return nil, err
+	}
+	if config.MaxNameBytes < 0 {
+		return nil, fmt.Errorf("max_name_bytes must not be negative: %d", config.MaxNameBytes)
+	}
+	if config.IDSearchDepth < 0 {
+		return nil, fmt.Errorf("id_search_depth must not be negative: %d", config.IDSearchDepth)
+	}
+
+	if p := config.Validate.MaxUndefinedPercent; p != nil && (*p < 0 || *p > 100) {
+		return nil, fmt.Errorf("max_undefined_percent must be between 0 and 100: %v", *p)
+	}
+	if n := config.Validate.MaxUndefinedTags; n != nil && *n < 0 {
+		return nil, fmt.Errorf("max_undefined_tags must not be negative: %d", *n)
+	}
+
+	return &config, nil
+}
+
+// Profiles はプロファイルを名前順で返す
+func (c *Config) Profiles() []Profile {
+	names := make([]string, 0, len(c.Profile))
+	for name := range c.Profile {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	profiles := make([]Profile, 0, len(names))
+	for _, name := range names {
+		profile := c.Profile[name]
+		profile.Name = name
+		profiles = append(profiles, profile)
+	}
+
+	return profiles
+}
+
+// FindProfile はファイル名の拡張子に一致する最初のプロファイルを返す
+// 一致するプロファイルがない場合は nil を返す
+func FindProfile(profiles []Profile, fileName string) *Profile {
+	for i := range profiles {
+		if parakeet.MatchesExtensions(fileName, profiles[i].Extensions) {
+			return &profiles[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+	// Create temporary directory
+	tmpDir, err := os.MkdirTemp("", "parakeet-config-*")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	content := `[profile.notes]
+extensions = ["md", "txt"]
+
+[profile.images]
+extensions = ["jpg", "png"]
+extractor = "mtime"
+tags = ["photo"]
+dest = "images"
+`
+	configPath := filepath.Join(tmpDir, ConfigFileName)
+	err = os.WriteFile(configPath, []byte(content), 0644)
+	require.NoError(t, err)
+
+	config, err := LoadConfig(configPath)
+	require.NoError(t, err)
+
+	profiles := config.Profiles()
+	require.Len(t, profiles, 2)
+
+	// 名前順に並ぶ
+	assert.Equal(t, "images", profiles[0].Name)
+	assert.Equal(t, []string{"jpg", "png"}, profiles[0].Extensions)
+	assert.Equal(t, ExtractorMtime, profiles[0].Extractor)
+	assert.Equal(t, []string{"photo"}, profiles[0].Tags)
+	assert.Equal(t, "images", profiles[0].Dest)
+	assert.Equal(t, "notes", profiles[1].Name)
+
+	// 拡張子でプロファイルを検索
+	profile := FindProfile(profiles, "IMG_0001.JPG")
+	require.NotNil(t, profile)
+	assert.Equal(t, "images", profile.Name)
+	assert.Nil(t, FindProfile(profiles, "document.pdf"))
+}
+
+func TestLoadConfig_NonExistentFile(t *testing.T) {
+	t.Parallel()
+	config, err := LoadConfig("/non/existent/parakeet.toml")
+	require.NoError(t, err)
+	assert.Empty(t, config.Profiles())
+}
+
+func TestLoadConfig_Validate(t *testing.T) {
+	t.Parallel()
+	tmpFile, err := os.CreateTemp("", "parakeet-*.toml")
+	require.NoError(t, err)
+	defer func() { _ = os.Remove(tmpFile.Name()) }()
+
+	_, err = tmpFile.WriteString("[validate]\nmax_undefined_percent = 12.5\nmax_undefined_tags = 3\n")
+	require.NoError(t, err)
+	_ = tmpFile.Close()
+
+	config, err := LoadConfig(tmpFile.Name())
+	require.NoError(t, err)
+
+	require.NotNil(t, config.Validate.MaxUndefinedPercent)
+	assert.Equal(t, 12.5, *config.Validate.MaxUndefinedPercent)
+	require.NotNil(t, config.Validate.MaxUndefinedTags)
+	assert.Equal(t, 3, *config.Validate.MaxUndefinedTags)
+	assert.True(t, config.Validate.Configured())
+}
+
+func TestLoadConfig_DuplicatePolicy(t *testing.T) {
+	t.Parallel()
+	tmpFile, err := os.CreateTemp("", "parakeet-*.toml")
+	require.NoError(t, err)
+	defer func() { _ = os.Remove(tmpFile.Name()) }()
+
+	_, err = tmpFile.WriteString("duplicate_policy = \"allow-same-basename\"\n")
+	require.NoError(t, err)
+	_ = tmpFile.Close()
+
+	config, err := LoadConfig(tmpFile.Name())
+	require.NoError(t, err)
+	assert.Equal(t, DuplicatePolicyAllowSameBasename, config.DuplicatePolicy)
+}
+
+func TestLoadConfig_NameBudget(t *testing.T) {
+	t.Parallel()
+	tmpFile, err := os.CreateTemp("", "parakeet-*.toml")
+	require.NoError(t, err)
+	defer func() { _ = os.Remove(tmpFile.Name()) }()
+
+	_, err = tmpFile.WriteString("max_name_bytes = 143\nname_budget = \"drop-tags\"\n")
+	require.NoError(t, err)
+	_ = tmpFile.Close()
+
+	config, err := LoadConfig(tmpFile.Name())
+	require.NoError(t, err)
+	assert.Equal(t, 143, config.MaxNameBytes)
+	assert.Equal(t, NameBudgetDropTags, config.NameBudget)
+}
+
+func TestLoadConfig_Sanitize(t *testing.T) {
+	t.Parallel()
+	tmpFile, err := os.CreateTemp("", "parakeet-*.toml")
+	require.NoError(t, err)
+	defer func() { _ = os.Remove(tmpFile.Name()) }()
+
+	_, err = tmpFile.WriteString("[sanitize]\nspace = \"-\"\nlowercase = true\n")
+	require.NoError(t, err)
+	_ = tmpFile.Close()
+
+	config, err := LoadConfig(tmpFile.Name())
+	require.NoError(t, err)
+	assert.Equal(t, parakeet.CommentSanitizer{Space: "-", Lowercase: true}, config.Sanitize)
+}
+
+func TestLoadConfig_Quota(t *testing.T) {
+	t.Parallel()
+	tmpFile, err := os.CreateTemp("", "parakeet-*.toml")
+	require.NoError(t, err)
+	defer func() { _ = os.Remove(tmpFile.Name()) }()
+
+	_, err = tmpFile.WriteString("[quota.directory]\nmax_files = 1000\n\n[quota.tag.video]\nmax_size = \"2GiB\"\n")
+	require.NoError(t, err)
+	_ = tmpFile.Close()
+
+	config, err := LoadConfig(tmpFile.Name())
+	require.NoError(t, err)
+	assert.Equal(t, QuotaLimit{MaxFiles: 1000}, config.Quota.Directory)
+	assert.Equal(t, map[string]QuotaLimit{"video": {MaxSize: "2GiB"}}, config.Quota.Tag)
+	assert.Equal(t, int64(2<<30), config.Quota.Tag["video"].MaxBytes())
+}
+
+func TestLoadConfig_TagsFile(t *testing.T) {
+	t.Parallel()
+	tmpFile, err := os.CreateTemp("", "parakeet-*.toml")
+	require.NoError(t, err)
+	defer func() { _ = os.Remove(tmpFile.Name()) }()
+
+	_, err = tmpFile.WriteString("tags_file = \"../shared/tags.toml\"\n")
+	require.NoError(t, err)
+	_ = tmpFile.Close()
+
+	config, err := LoadConfig(tmpFile.Name())
+	require.NoError(t, err)
+	assert.Equal(t, "../shared/tags.toml", config.TagsFile)
+}
+
+func TestLoadConfig_Open(t *testing.T) {
+	t.Parallel()
+	tmpFile, err := os.CreateTemp("", "parakeet-*.toml")
+	require.NoError(t, err)
+	defer func() { _ = os.Remove(tmpFile.Name()) }()
+
+	_, err = tmpFile.WriteString("[open]\npdf = \"zathura\"\nmd = \"code --wait\"\n")
+	require.NoError(t, err)
+	_ = tmpFile.Close()
+
+	config, err := LoadConfig(tmpFile.Name())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"pdf": "zathura", "md": "code --wait"}, config.Open)
+}
+
+func TestDuplicatePolicy_Allows(t *testing.T) {
+	t.Parallel()
+	attachments := []string{"20250903T083109--report.md", "docs/20250903T083109--report.pdf"}
+	collision := []string{"20250903T083109--report.md", "20250903T083109--memo.md"}
+
+	tests := []struct {
+		policy      DuplicatePolicy
+		attachments bool
+		collision   bool
+		fails       bool
+	}{
+		{policy: "", attachments: false, collision: false, fails: true},
+		{policy: DuplicatePolicyError, attachments: false, collision: false, fails: true},
+		{policy: DuplicatePolicyWarn, attachments: false, collision: false, fails: false},
+		{policy: DuplicatePolicyAllowSameBasename, attachments: true, collision: false, fails: true},
+		{policy: DuplicatePolicyAllowAll, attachments: true, collision: true, fails: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.attachments, tt.policy.Allows(attachments))
+			assert.Equal(t, tt.collision, tt.policy.Allows(collision))
+			assert.Equal(t, tt.fails, tt.policy.Fails())
+		})
+	}
+}
+
+func TestTagCoverageRule_Exceeded(t *testing.T) {
+	t.Parallel()
+	percent := 25.0
+	count := 2
+
+	tests := []struct {
+		name     string
+		rule     TagCoverageRule
+		percent  float64
+		distinct int
+		expected bool
+	}{
+		{name: "no rule, no undefined tags", rule: TagCoverageRule{}, percent: 0, distinct: 0, expected: false},
+		{name: "no rule, undefined tags", rule: TagCoverageRule{}, percent: 1, distinct: 1, expected: true},
+		{name: "percent within", rule: TagCoverageRule{MaxUndefinedPercent: &percent}, percent: 25, distinct: 10, expected: false},
+		{name: "percent exceeded", rule: TagCoverageRule{MaxUndefinedPercent: &percent}, percent: 25.1, distinct: 1, expected: true},
+		{name: "count within", rule: TagCoverageRule{MaxUndefinedTags: &count}, percent: 100, distinct: 2, expected: false},
+		{name: "count exceeded", rule: TagCoverageRule{MaxUndefinedTags: &count}, percent: 0, distinct: 3, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, tt.rule.Exceeded(tt.percent, tt.distinct))
+		})
+	}
+}
+
+func TestLoadConfig_Invalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		content   string
+		errorText string
+	}{
+		{
+			name:      "unknown extractor",
+			content:   "[profile.images]\nextensions = [\"jpg\"]\nextractor = \"exif\"\n",
+			errorText: "unknown extractor in profile images: exif",
+		},
+		{
+			name:      "no extensions",
+			content:   "[profile.images]\ndest = \"images\"\n",
+			errorText: "profile images has no extensions",
+		},
+		{
+			name:      "percent out of range",
+			content:   "[validate]\nmax_undefined_percent = 120.0\n",
+			errorText: "max_undefined_percent must be between 0 and 100",
+		},
+		{
+			name:      "negative tag count",
+			content:   "[validate]\nmax_undefined_tags = -1\n",
+			errorText: "max_undefined_tags must not be negative",
+		},
+		{
+			name:      "unknown duplicate policy",
+			content:   "duplicate_policy = \"ignore\"\n",
+			errorText: "unknown duplicate policy: ignore",
+		},
+		{
+			name:      "unknown name budget policy",
+			content:   "name_budget = \"shrink\"\n",
+			errorText: "unknown name budget policy: shrink",
+		},
+		{
+			name:      "empty open command",
+			content:   "[open]\npdf = \" \"\n",
+			errorText: "open command for pdf is empty",
+		},
+		{
+			name:      "negative max name bytes",
+			content:   "max_name_bytes = -1\n",
+			errorText: "max_name_bytes must not be negative",
+		},
+		{
+			name:      "negative id search depth",
+			content:   "id_search_depth = -1\n",
+			errorText: "id_search_depth must not be negative",
+		},
+		{
+			name:      "invalid sanitize replacement",
+			content:   "[sanitize]\nspace = \"--\"\n",
+			errorText: "invalid sanitize.space",
+		},
+		{
+			name:      "invalid quota size",
+			content:   "[quota.tag.video]\nmax_size = \"lots\"\n",
+			errorText: "quota.tag.video: invalid size",
+		},
+		{
+			name:      "negative quota files",
+			content:   "[quota.directory]\nmax_files = -1\n",
+			errorText: "quota.directory: max_files must not be negative",
+		},
+		{
+			name:      "legacy pattern without title",
+			content:   "[[legacy]]\nname = \"scan\"\npattern = '^SCAN-(?P<date>\\d{8})$'\n",
+			errorText: "legacy pattern scan has no title group",
+		},
+		{
+			name:      "invalid toml",
+			content:   "[profile.images\n",
+			errorText: "failed to parse config file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tmpFile, err := os.CreateTemp("", "parakeet-*.toml")
+			require.NoError(t, err)
+			defer func() { _ = os.Remove(tmpFile.Name()) }()
+
+			_, err = tmpFile.WriteString(tt.content)
+			require.NoError(t, err)
+			_ = tmpFile.Close()
+
+			_, err = LoadConfig(tmpFile.Name())
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorText)
+		})
+	}
+}
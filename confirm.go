@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ConfirmAnswer はリネームの確認に対する回答を表す
+type ConfirmAnswer int
+
+// リネームの確認に対する回答
+const (
+	ConfirmYes  ConfirmAnswer = iota // このファイルをリネームする
+	ConfirmNo                        // このファイルはリネームしない
+	ConfirmAll                       // このファイルと残りのファイルをすべて確認せずにリネームする
+	ConfirmQuit                      // このファイルと残りのファイルをリネームせずに終える
+)
+
+// NewConfirmPrompt はリネームをファイルごとに y/n/a/q で確認する関数を作成する
+// 質問は out に出力し、回答は in から1行ずつ読み込む。入力が終わった場合は q とみなす
+func NewConfirmPrompt(in io.Reader, out io.Writer) func(oldName, newName string) (ConfirmAnswer, error) {
+	reader := bufio.NewReader(in)
+
+	return func(oldName, newName string) (ConfirmAnswer, error) {
+		for {
+			_, _ = fmt.Fprintf(out, "Rename %s → %s? [y,n,a,q,?] ", oldName, newName)
+
+			line, err := reader.ReadString('\n')
+			if err != nil && err != io.EOF {
+				return ConfirmQuit, fmt.Errorf("failed to read answer: %w", err)
+			}
+			if err == io.EOF && line == "" {
+				_, _ = fmt.Fprintln(out)
+				return ConfirmQuit, nil
+			}
+
+			switch strings.ToLower(strings.TrimSpace(line)) {
+			case "y", "yes":
+				return ConfirmYes, nil
+			case "n", "no":
+				return ConfirmNo, nil
+			case "a", "all":
+				return ConfirmAll, nil
+			case "q", "quit":
+				return ConfirmQuit, nil
+			default:
+				_, _ = fmt.Fprintln(out, "y - rename this file\nn - skip this file\na - rename this file and all remaining files\nq - quit; do not rename this file or any remaining files")
+			}
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConfirmPrompt(t *testing.T) {
+	t.Parallel()
+	out := &bytes.Buffer{}
+	confirm := NewConfirmPrompt(strings.NewReader("y\nNo\nwhat\na\nq\n"), out)
+
+	expected := []ConfirmAnswer{ConfirmYes, ConfirmNo, ConfirmAll, ConfirmQuit, ConfirmQuit}
+	for _, want := range expected {
+		answer, err := confirm("a.pdf", "20250101T000000--a.pdf")
+		require.NoError(t, err)
+		assert.Equal(t, want, answer)
+	}
+
+	assert.Contains(t, out.String(), "Rename a.pdf → 20250101T000000--a.pdf? [y,n,a,q,?] ")
+	// 不明な回答にはヘルプを表示して聞き直す
+	assert.Contains(t, out.String(), "a - rename this file and all remaining files")
+}
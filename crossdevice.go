@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// largeFileThreshold は別のファイルシステムへのコピーで進捗を表示するファイルサイズ（バイト）
+const largeFileThreshold = 64 << 20
+
+// RenameProgress は別のファイルシステムへのコピーの進捗の出力先（nil の場合は出力しない）
+// 標準出力の結果と混ざらないように、端末の標準エラー出力を設定する
+var RenameProgress io.Writer
+
+// RenameFile はファイルをリネームする
+// リネーム先が別のファイルシステムで os.Rename が失敗した場合は、コピーして fsync してから元のファイルを削除する
+// フックは実行しない（フックを実行する場合は HookRunner.RenameFile を使う）
+func RenameFile(oldpath, newpath string) error {
+	err := os.Rename(oldpath, newpath)
+	if err != nil && isCrossDeviceError(err) {
+		err = moveAcrossDevices(oldpath, newpath, RenameProgress)
+	}
+	return err
+}
+
+// moveAcrossDevices はファイルを別のファイルシステムに移動する
+// 一時ファイルにコピーしてディスクに書き込んでからリネームし、最後に元のファイルを削除する
+// シンボリックリンク（シム）はリンクを作り直す。大きなファイルはコピーの進捗を progress に出力する
+func moveAcrossDevices(oldpath, newpath string, progress io.Writer) error {
+	info, err := os.Lstat(oldpath)
+	if err != nil {
+		return err
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(oldpath)
+		if err != nil {
+			return err
+		}
+		if err := os.Symlink(target, newpath); err != nil {
+			return err
+		}
+		return os.Remove(oldpath)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("cannot move %s across file systems: not a regular file", oldpath)
+	}
+
+	tmpPath := filepath.Join(filepath.Dir(newpath), "."+filepath.Base(newpath)+".parakeet-tmp")
+	if err := copyAndSync(oldpath, tmpPath, info, progress); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, newpath); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	return os.Remove(oldpath)
+}
+
+// copyAndSync はファイルの内容を dst にコピーし、ディスクに書き込んでからパーミッションと更新日時を揃える
+func copyAndSync(src, dst string, info os.FileInfo, progress io.Writer) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = out
+	if progress != nil && info.Size() >= largeFileThreshold {
+		w = &copyProgress{w: out, out: progress, name: filepath.Base(src), total: info.Size()}
+	}
+
+	if _, err := io.Copy(w, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if p, ok := w.(*copyProgress); ok {
+		p.finish()
+	}
+	if err := out.Sync(); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
+
+// copyProgress は書き込んだバイト数からコピーの進捗を出力する
+type copyProgress struct {
+	w       io.Writer
+	out     io.Writer
+	name    string
+	total   int64
+	written int64
+	percent int64
+}
+
+func (p *copyProgress) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.written += int64(n)
+
+	// 1% 進むごとに同じ行を書き換える
+	if percent := p.written * 100 / p.total; percent != p.percent {
+		p.percent = percent
+		_, _ = fmt.Fprintf(p.out, "\rCopying %s: %3d%% (%d/%d MiB)", p.name, percent, p.written>>20, p.total>>20)
+	}
+	return n, err
+}
+
+// finish は進捗の行を終える
+func (p *copyProgress) finish() {
+	_, _ = fmt.Fprintf(p.out, "\rCopying %s: 100%% (%d/%d MiB)\n", p.name, p.total>>20, p.total>>20)
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package main
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isCrossDeviceError はリネーム先が別のファイルシステムのために失敗したかどうかを返す
+func isCrossDeviceError(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
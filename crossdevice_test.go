@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoveAcrossDevices(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-xdev-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	src := filepath.Join(tmpDir, "20250101T000000--a.pdf")
+	dst := filepath.Join(tmpDir, "dst", "20250101T000000--a__go.pdf")
+	require.NoError(t, os.MkdirAll(filepath.Dir(dst), 0755))
+	require.NoError(t, os.WriteFile(src, []byte("content"), 0600))
+	modTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local)
+	require.NoError(t, os.Chtimes(src, modTime, modTime))
+
+	require.NoError(t, moveAcrossDevices(src, dst, nil))
+
+	assert.NoFileExists(t, src)
+	content, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(content))
+
+	info, err := os.Stat(dst)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	assert.True(t, info.ModTime().Equal(modTime))
+
+	// 一時ファイルは残らない
+	entries, err := os.ReadDir(filepath.Dir(dst))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestMoveAcrossDevices_Symlink(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-xdev-link-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	link := filepath.Join(tmpDir, "old.pdf")
+	require.NoError(t, os.Symlink("20250101T000000--a.pdf", link))
+
+	dst := filepath.Join(tmpDir, "new.pdf")
+	require.NoError(t, moveAcrossDevices(link, dst, nil))
+
+	target, err := os.Readlink(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "20250101T000000--a.pdf", target)
+	_, err = os.Lstat(link)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCopyProgress(t *testing.T) {
+	t.Parallel()
+	out := &bytes.Buffer{}
+	dst := &bytes.Buffer{}
+	p := &copyProgress{w: dst, out: out, name: "big.iso", total: 4 << 20}
+
+	for i := 0; i < 4; i++ {
+		_, err := p.Write(make([]byte, 1<<20))
+		require.NoError(t, err)
+	}
+	p.finish()
+
+	assert.Equal(t, 4<<20, dst.Len())
+	assert.Contains(t, out.String(), "\rCopying big.iso:  25% (1/4 MiB)")
+	assert.True(t, bytes.HasSuffix(out.Bytes(), []byte("\rCopying big.iso: 100% (4/4 MiB)\n")))
+}
+
+func TestIsCrossDeviceError(t *testing.T) {
+	t.Parallel()
+	assert.True(t, isCrossDeviceError(&os.LinkError{Op: "rename", Old: "a", New: "b", Err: syscall.EXDEV}))
+	assert.False(t, isCrossDeviceError(&os.LinkError{Op: "rename", Old: "a", New: "b", Err: syscall.ENOENT}))
+}
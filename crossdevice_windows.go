@@ -0,0 +1,14 @@
+package main
+
+import (
+	"errors"
+	"syscall"
+)
+
+// errorNotSameDevice は別のドライブへの移動で MoveFileEx が返すエラー（ERROR_NOT_SAME_DEVICE）
+const errorNotSameDevice = syscall.Errno(17)
+
+// isCrossDeviceError はリネーム先が別のファイルシステムのために失敗したかどうかを返す
+func isCrossDeviceError(err error) bool {
+	return errors.Is(err, errorNotSameDevice) || errors.Is(err, syscall.EXDEV)
+}
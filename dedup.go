@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// DedupOptions は重複タイムスタンプ解消操作のオプションを表す
+type DedupOptions struct {
+	Writer io.Writer // 出力先
+	FilterOptions
+	DryRun          bool               // 実際にはリネームしない
+	DuplicatePolicy DuplicatePolicy    // タイムスタンプ重複の扱い（許可される重複はそのまま残す）
+	Precision       parakeet.Precision // 新しく割り当てるタイムスタンプの精度（空の場合は秒まで）
+	Journal         *Journal           // 実行したリネームを記録するジャーナル（nil の場合は記録しない）
+	Hooks           *HookRunner        // リネームの前後に実行するフック（nil の場合は実行しない）
+}
+
+// DedupResult は重複タイムスタンプ解消操作の結果を表す
+type DedupResult struct {
+	Renamed map[string]string // リネームしたファイル: 旧ファイル名 -> 新ファイル名
+}
+
+// DeduplicateTimestamps はタイムスタンプが重複するファイルに新しいタイムスタンプを割り当てる
+// 重複グループの最初のファイル（ファイル名順）はそのまま残し、
+// 残りのファイルはコメント・タグ・拡張子を保ったまま、元のタイムスタンプに近い空いているタイムスタンプに変更する
+// DuplicatePolicy が許可する重複はそのまま残す（allow-same-basename では最初のファイルと拡張子以外が同じファイルを残し、
+// 残りのファイルも拡張子以外が同じファイルどうしは同じタイムスタンプにする）
+func DeduplicateTimestamps(targetDir string, opts DedupOptions) (*DedupResult, error) {
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("renamed", "from", "to", "dry_run")
+
+	// ディレクトリの存在チェック
+	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", targetDir)
+	}
+
+	entries, err := os.ReadDir(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	// 既存のタイムスタンプを収集
+	existingTimestamps, err := CollectExistingTimestamps(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect existing timestamps: %w", err)
+	}
+
+	// タイムスタンプごとにファイルをまとめる（ReadDir はファイル名順）
+	groups := make(map[string][]string)
+	for _, entry := range entries {
+		if entry.IsDir() || IsShim(targetDir, entry) {
+			continue
+		}
+
+		fileName := entry.Name()
+		if !opts.Matches(fileName) {
+			continue
+		}
+
+		if components, err := parakeet.ParseFileName(fileName); err == nil {
+			groups[components.Timestamp] = append(groups[components.Timestamp], fileName)
+		}
+	}
+
+	result := &DedupResult{
+		Renamed: make(map[string]string),
+	}
+
+	prefix := ""
+	if opts.DryRun {
+		prefix = "[dry-run] "
+	}
+
+	for _, timestamp := range sortedKeys(groups) {
+		files := groups[timestamp]
+		if len(files) < 2 || opts.DuplicatePolicy.Allows(files) {
+			continue
+		}
+
+		base, err := parseTimestamp(timestamp)
+		if err != nil {
+			base = time.Now()
+		}
+
+		// 最初のファイル以外に新しいタイムスタンプを割り当てる
+		assigned := make(map[string]string) // 拡張子を除いたファイル名 -> 割り当てたタイムスタンプ
+		for _, oldName := range files[1:] {
+			basename := duplicateBasename(oldName)
+			if opts.DuplicatePolicy == DuplicatePolicyAllowSameBasename && basename == duplicateBasename(files[0]) {
+				continue
+			}
+
+			components, err := parakeet.ParseFileName(oldName)
+			if err != nil {
+				continue
+			}
+
+			newTimestamp, ok := assigned[basename]
+			if !ok || opts.DuplicatePolicy != DuplicatePolicyAllowSameBasename {
+				newTimestamp = parakeet.GenerateUniqueTimestampWithPrecision(base, existingTimestamps, opts.Precision)
+			}
+			components.Timestamp = newTimestamp
+			newName, err := components.FormatFileNameStrict()
+			if err != nil {
+				reporter.Warnf("%s (%v, skipping)\n", oldName, err)
+				continue
+			}
+			newPath := filepath.Join(targetDir, newName)
+
+			if _, err := os.Stat(newPath); err == nil {
+				reporter.Warnf("target file already exists, skipping: %s\n", newName)
+				continue
+			}
+
+			if !opts.DryRun {
+				if err := opts.Hooks.RenameFile(filepath.Join(targetDir, oldName), newPath); err != nil {
+					return result, fmt.Errorf("failed to rename file: %w", err)
+				}
+				if err := opts.Journal.Record(targetDir, renamePlan{From: oldName, To: newName}); err != nil {
+					reporter.Warnf("%s (%v)\n", oldName, err)
+				}
+			}
+
+			// 使用したタイムスタンプを記録
+			existingTimestamps[components.Timestamp] = true
+			assigned[basename] = components.Timestamp
+			result.Renamed[oldName] = newName
+			reporter.Emit("renamed", map[string]any{"from": oldName, "to": newName, "dry_run": opts.DryRun}, "%s✓ Renamed: %s → %s\n", prefix, oldName, newName)
+		}
+	}
+
+	// サマリーを出力
+	reporter.Printf("\nDedup Summary:\n")
+	reporter.Printf("  Renamed: %d\n", len(result.Renamed))
+
+	return result, nil
+}
+
+// parseTimestamp はタイムスタンプ（ミリ秒を含むものも）をローカル時刻としてパースする
+func parseTimestamp(timestamp string) (time.Time, error) {
+	if strings.Contains(timestamp, ",") {
+		return time.ParseInLocation(parakeet.TimestampMilliLayout, timestamp, time.Local)
+	}
+	return time.ParseInLocation(timestampLayout, timestamp, time.Local)
+}
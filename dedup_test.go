@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupDedupDir(t *testing.T) string {
+	t.Helper()
+
+	// 内容はファイル名と同じにして、リネーム後も内容が変わらないことを確認する
+	files := map[string]string{}
+	for _, name := range []string{
+		"20250903T083109--alpha__network.pdf",
+		"20250903T083109--beta__infra_network.pdf",
+		"20250903T083109--gamma.txt",
+		"20250903T083110--unique.pdf",
+	} {
+		files[name] = name
+	}
+	return newTestDir(t, files)
+}
+
+func TestDeduplicateTimestamps(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupDedupDir(t)
+
+	buf := &bytes.Buffer{}
+	result, err := DeduplicateTimestamps(tmpDir, DedupOptions{Writer: buf})
+	require.NoError(t, err)
+
+	require.Len(t, result.Renamed, 2)
+	assert.NotContains(t, result.Renamed, "20250903T083109--alpha__network.pdf", "first file in the group is kept")
+	assert.FileExists(t, filepath.Join(tmpDir, "20250903T083109--alpha__network.pdf"))
+
+	// コメント・タグ・拡張子は保たれ、内容も変わらない
+	newName := result.Renamed["20250903T083109--beta__infra_network.pdf"]
+	components, err := parakeet.ParseFileName(newName)
+	require.NoError(t, err)
+	assert.Equal(t, "beta", components.Comment)
+	assert.Equal(t, []string{"infra", "network"}, components.Tags)
+	assert.Equal(t, "pdf", components.Extension)
+	content, err := os.ReadFile(filepath.Join(tmpDir, newName))
+	require.NoError(t, err)
+	assert.Equal(t, "20250903T083109--beta__infra_network.pdf", string(content))
+
+	// 解消後は重複がない
+	validation, err := ValidateFileNames(context.Background(), tmpDir, ValidateOptions{Writer: &bytes.Buffer{}})
+	require.NoError(t, err)
+	assert.False(t, validation.HasDuplicates)
+	assert.Contains(t, buf.String(), "Renamed: 2")
+}
+
+func TestDeduplicateTimestampsDryRun(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupDedupDir(t)
+
+	buf := &bytes.Buffer{}
+	result, err := DeduplicateTimestamps(tmpDir, DedupOptions{Writer: buf, DryRun: true})
+	require.NoError(t, err)
+
+	assert.Len(t, result.Renamed, 2)
+	for oldName := range result.Renamed {
+		assert.FileExists(t, filepath.Join(tmpDir, oldName))
+	}
+	assert.Contains(t, buf.String(), "[dry-run]")
+}
+
+func TestDeduplicateTimestampsExtensionFilter(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupDedupDir(t)
+
+	result, err := DeduplicateTimestamps(tmpDir, DedupOptions{
+		Writer:        &bytes.Buffer{},
+		FilterOptions: FilterOptions{Extensions: []string{"pdf"}},
+	})
+	require.NoError(t, err)
+
+	// txt は対象外のため、pdf の2つ目のみリネームされる
+	require.Len(t, result.Renamed, 1)
+	assert.Contains(t, result.Renamed, "20250903T083109--beta__infra_network.pdf")
+	assert.FileExists(t, filepath.Join(tmpDir, "20250903T083109--gamma.txt"))
+}
+
+func TestDeduplicateTimestampsFromOriginal(t *testing.T) {
+	t.Parallel()
+
+	t.Run("元のタイムスタンプの次の空いている秒にする", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setupDedupDir(t)
+
+		result, err := DeduplicateTimestamps(tmpDir, DedupOptions{Writer: &bytes.Buffer{}})
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]string{
+			"20250903T083109--beta__infra_network.pdf": "20250903T083111--beta__infra_network.pdf",
+			"20250903T083109--gamma.txt":               "20250903T083112--gamma.txt",
+		}, result.Renamed)
+	})
+
+	t.Run("ミリ秒の精度", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setupDedupDir(t)
+
+		result, err := DeduplicateTimestamps(tmpDir, DedupOptions{Writer: &bytes.Buffer{}, Precision: parakeet.PrecisionMillisecond})
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]string{
+			"20250903T083109--beta__infra_network.pdf": "20250903T083109,000--beta__infra_network.pdf",
+			"20250903T083109--gamma.txt":               "20250903T083109,001--gamma.txt",
+		}, result.Renamed)
+	})
+}
+
+func TestDeduplicateTimestampsDuplicatePolicy(t *testing.T) {
+	t.Parallel()
+
+	setup := func(t *testing.T) string {
+		t.Helper()
+		dir := t.TempDir()
+		for _, name := range []string{
+			"20250903T083109--alpha.pdf",
+			"20250903T083109--alpha.txt",
+			"20250903T083109--beta.pdf",
+			"20250903T083109--beta.txt",
+		} {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(name), 0644))
+		}
+		return dir
+	}
+
+	t.Run("allow-same-basename では添付ファイルを残し、同じ名前のファイルは同じタイムスタンプにする", func(t *testing.T) {
+		t.Parallel()
+		dir := setup(t)
+
+		result, err := DeduplicateTimestamps(dir, DedupOptions{Writer: &bytes.Buffer{}, DuplicatePolicy: DuplicatePolicyAllowSameBasename})
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]string{
+			"20250903T083109--beta.pdf": "20250903T083110--beta.pdf",
+			"20250903T083109--beta.txt": "20250903T083110--beta.txt",
+		}, result.Renamed)
+		assert.FileExists(t, filepath.Join(dir, "20250903T083109--alpha.txt"))
+
+		validation, err := ValidateFileNames(context.Background(), dir, ValidateOptions{Writer: &bytes.Buffer{}, DuplicatePolicy: DuplicatePolicyAllowSameBasename})
+		require.NoError(t, err)
+		assert.False(t, validation.HasDuplicates)
+	})
+
+	t.Run("allow-all ではリネームしない", func(t *testing.T) {
+		t.Parallel()
+		dir := setup(t)
+
+		result, err := DeduplicateTimestamps(dir, DedupOptions{Writer: &bytes.Buffer{}, DuplicatePolicy: DuplicatePolicyAllowAll})
+		require.NoError(t, err)
+		assert.Empty(t, result.Renamed)
+	})
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// DiffOptions はディレクトリ比較操作のオプションを表す
+type DiffOptions struct {
+	Writer io.Writer // 出力先
+	FilterOptions
+	DuplicatePolicy DuplicatePolicy // タイムスタンプ重複の扱い（重複を許可する場合はIDと拡張子でファイルを対応させる）
+}
+
+// DiffEntry は同じIDを持つが内容の異なるファイルの組を表す
+type DiffEntry struct {
+	ID    string // タイムスタンプ（ID）
+	FileA string // dirA 側のファイル名
+	FileB string // dirB 側のファイル名
+}
+
+// DiffResult はディレクトリ比較の結果を表す
+type DiffResult struct {
+	OnlyInA         []string    // dirA にのみ存在するファイル名
+	OnlyInB         []string    // dirB にのみ存在するファイル名
+	NameMismatches  []DiffEntry // 同じIDでタイトル・タグが異なるファイル
+	ContentMismatch []DiffEntry // 同じIDで内容のハッシュが異なるファイル
+}
+
+// HasDifferences は差分があるかどうかを返す
+func (r *DiffResult) HasDifferences() bool {
+	return len(r.OnlyInA) > 0 || len(r.OnlyInB) > 0 ||
+		len(r.NameMismatches) > 0 || len(r.ContentMismatch) > 0
+}
+
+// CompareDirectories は2つの管理ディレクトリをIDで比較する
+func CompareDirectories(dirA, dirB string, opts DiffOptions) (*DiffResult, error) {
+	reporter := ReporterFor(opts.Writer)
+	if err := rejectTableFormat(reporter, "diff"); err != nil {
+		return nil, err
+	}
+
+	filesA, err := collectFilesByID(dirA, opts.FilterOptions, opts.DuplicatePolicy)
+	if err != nil {
+		return nil, err
+	}
+	filesB, err := collectFilesByID(dirB, opts.FilterOptions, opts.DuplicatePolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DiffResult{
+		OnlyInA:         []string{},
+		OnlyInB:         []string{},
+		NameMismatches:  []DiffEntry{},
+		ContentMismatch: []DiffEntry{},
+	}
+
+	for _, key := range sortedKeys(filesA) {
+		nameA := filesA[key]
+		nameB, ok := filesB[key]
+		if !ok {
+			result.OnlyInA = append(result.OnlyInA, nameA)
+			reporter.Emit("only_in", map[string]any{"file": nameA, "dir": dirA}, "- %s (only in %s)\n", nameA, dirA)
+			continue
+		}
+
+		id := fileID(nameA)
+		entry := DiffEntry{ID: id, FileA: nameA, FileB: nameB}
+
+		// タイトル・タグの差分
+		if nameA != nameB {
+			result.NameMismatches = append(result.NameMismatches, entry)
+			reporter.Emit("name_differs", map[string]any{"id": id, "file_a": nameA, "file_b": nameB}, "~ %s → %s (name differs)\n", nameA, nameB)
+		}
+
+		// 内容の差分
+		hashA, err := hashFile(filepath.Join(dirA, nameA))
+		if err != nil {
+			return nil, err
+		}
+		hashB, err := hashFile(filepath.Join(dirB, nameB))
+		if err != nil {
+			return nil, err
+		}
+		if hashA != hashB {
+			result.ContentMismatch = append(result.ContentMismatch, entry)
+			reporter.Emit("content_differs", map[string]any{"id": id}, "! %s (content differs)\n", id)
+		}
+	}
+
+	for _, key := range sortedKeys(filesB) {
+		if _, ok := filesA[key]; !ok {
+			result.OnlyInB = append(result.OnlyInB, filesB[key])
+			reporter.Emit("only_in", map[string]any{"file": filesB[key], "dir": dirB}, "+ %s (only in %s)\n", filesB[key], dirB)
+		}
+	}
+
+	// サマリーを出力
+	reporter.Printf("\nDiff Summary:\n")
+	reporter.Printf("  Only in %s: %d\n", dirA, len(result.OnlyInA))
+	reporter.Printf("  Only in %s: %d\n", dirB, len(result.OnlyInB))
+	reporter.Printf("  Name differs: %d\n", len(result.NameMismatches))
+	reporter.Printf("  Content differs: %d\n", len(result.ContentMismatch))
+
+	return result, nil
+}
+
+// collectFilesByID はディレクトリ内のフォーマット済みファイルをIDごとに収集する
+// キーは policy の matchKey（重複を許可する場合はIDと拡張子）で、policy が許可しない重複やキーが同じファイルがある場合はエラーを返す
+func collectFilesByID(dirPath string, filter FilterOptions, policy DuplicatePolicy) (map[string]string, error) {
+	// ディレクトリの存在チェック
+	if _, err := os.Stat(dirPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", dirPath)
+	}
+
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	files := make(map[string]string)
+	byTimestamp := make(map[string][]string)
+	for _, entry := range entries {
+		if entry.IsDir() || IsShim(dirPath, entry) {
+			continue
+		}
+
+		fileName := entry.Name()
+		if !filter.Matches(fileName) {
+			continue
+		}
+
+		components, err := parakeet.ParseFileName(fileName)
+		if err != nil {
+			continue
+		}
+
+		key := policy.matchKey(components)
+		if existing, ok := files[key]; ok {
+			return nil, fmt.Errorf("duplicate ID %s in %s: %s, %s", components.Timestamp, dirPath, existing, fileName)
+		}
+		files[key] = fileName
+		byTimestamp[components.Timestamp] = append(byTimestamp[components.Timestamp], fileName)
+	}
+
+	for _, timestamp := range sortedKeys(byTimestamp) {
+		if names := byTimestamp[timestamp]; len(names) > 1 && !policy.Allows(names) {
+			return nil, fmt.Errorf("duplicate ID %s in %s: %s", timestamp, dirPath, strings.Join(names, ", "))
+		}
+	}
+
+	return files, nil
+}
+
+// fileID はフォーマット済みファイル名のID（タイムスタンプ）を返す
+func fileID(fileName string) string {
+	components, err := parakeet.ParseFileName(fileName)
+	if err != nil {
+		return ""
+	}
+	return components.Timestamp
+}
+
+// hashFile はファイル内容のSHA-256ハッシュを16進文字列で返す
+func hashFile(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to hash file: %w", err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// sortedKeys はマップのキーをソートして返す
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
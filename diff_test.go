@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareDirectories(t *testing.T) {
+	t.Parallel()
+	dirA, err := os.MkdirTemp("", "parakeet-diff-a-*")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dirA) }()
+	dirB, err := os.MkdirTemp("", "parakeet-diff-b-*")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dirB) }()
+
+	filesA := map[string]string{
+		"20250903T083109--same__network.pdf": "same",
+		"20250903T083110--old-title.pdf":     "renamed",
+		"20250903T083111--changed.pdf":       "content A",
+		"20250903T083112--only-in-a.pdf":     "a",
+		"invalid-file.pdf":                   "skip",
+	}
+	filesB := map[string]string{
+		"20250903T083109--same__network.pdf":    "same",
+		"20250903T083110--new-title__infra.pdf": "renamed",
+		"20250903T083111--changed.pdf":          "content B",
+		"20250903T083113--only-in-b.pdf":        "b",
+	}
+
+	for name, content := range filesA {
+		require.NoError(t, os.WriteFile(filepath.Join(dirA, name), []byte(content), 0644))
+	}
+	for name, content := range filesB {
+		require.NoError(t, os.WriteFile(filepath.Join(dirB, name), []byte(content), 0644))
+	}
+
+	buf := &bytes.Buffer{}
+	result, err := CompareDirectories(dirA, dirB, DiffOptions{Writer: buf})
+	require.NoError(t, err)
+
+	assert.True(t, result.HasDifferences())
+	assert.Equal(t, []string{"20250903T083112--only-in-a.pdf"}, result.OnlyInA)
+	assert.Equal(t, []string{"20250903T083113--only-in-b.pdf"}, result.OnlyInB)
+
+	require.Len(t, result.NameMismatches, 1)
+	assert.Equal(t, "20250903T083110", result.NameMismatches[0].ID)
+	assert.Equal(t, "20250903T083110--old-title.pdf", result.NameMismatches[0].FileA)
+	assert.Equal(t, "20250903T083110--new-title__infra.pdf", result.NameMismatches[0].FileB)
+
+	require.Len(t, result.ContentMismatch, 1)
+	assert.Equal(t, "20250903T083111", result.ContentMismatch[0].ID)
+
+	output := buf.String()
+	assert.Contains(t, output, "Diff Summary:")
+	assert.Contains(t, output, "Content differs: 1")
+	assert.NotContains(t, output, "invalid-file")
+}
+
+func TestCompareDirectories_Identical(t *testing.T) {
+	t.Parallel()
+	dirA, err := os.MkdirTemp("", "parakeet-diff-same-a-*")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dirA) }()
+	dirB, err := os.MkdirTemp("", "parakeet-diff-same-b-*")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dirB) }()
+
+	name := "20250903T083109--same.pdf"
+	require.NoError(t, os.WriteFile(filepath.Join(dirA, name), []byte("x"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dirB, name), []byte("x"), 0644))
+
+	result, err := CompareDirectories(dirA, dirB, DiffOptions{Writer: &bytes.Buffer{}})
+	require.NoError(t, err)
+	assert.False(t, result.HasDifferences())
+}
+
+func TestCompareDirectories_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("directory does not exist", func(t *testing.T) {
+		t.Parallel()
+		_, err := CompareDirectories("/nonexistent/a", "/nonexistent/b", DiffOptions{Writer: &bytes.Buffer{}})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "directory does not exist")
+	})
+
+	t.Run("duplicate ID", func(t *testing.T) {
+		t.Parallel()
+		dirA, err := os.MkdirTemp("", "parakeet-diff-dup-*")
+		require.NoError(t, err)
+		defer func() { _ = os.RemoveAll(dirA) }()
+
+		require.NoError(t, os.WriteFile(filepath.Join(dirA, "20250903T083109--a.pdf"), []byte("a"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dirA, "20250903T083109--b.pdf"), []byte("b"), 0644))
+
+		_, err = CompareDirectories(dirA, dirA, DiffOptions{Writer: &bytes.Buffer{}})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "duplicate ID")
+	})
+}
+
+func TestCompareDirectories_DuplicatePolicy(t *testing.T) {
+	t.Parallel()
+	dirA, dirB := t.TempDir(), t.TempDir()
+
+	// 拡張子以外が同じ添付ファイルはIDを共有する
+	for name, content := range map[string]string{
+		"20250903T083109--paper.pdf": "pdf",
+		"20250903T083109--paper.txt": "notes A",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dirA, name), []byte(content), 0644))
+	}
+	for name, content := range map[string]string{
+		"20250903T083109--paper.pdf": "pdf",
+		"20250903T083109--paper.txt": "notes B",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dirB, name), []byte(content), 0644))
+	}
+
+	t.Run("デフォルトでは重複はエラー", func(t *testing.T) {
+		t.Parallel()
+		_, err := CompareDirectories(dirA, dirB, DiffOptions{Writer: &bytes.Buffer{}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "duplicate ID 20250903T083109")
+	})
+
+	t.Run("allow-same-basename ではIDと拡張子で対応させる", func(t *testing.T) {
+		t.Parallel()
+		result, err := CompareDirectories(dirA, dirB, DiffOptions{Writer: &bytes.Buffer{}, DuplicatePolicy: DuplicatePolicyAllowSameBasename})
+		require.NoError(t, err)
+
+		assert.Empty(t, result.OnlyInA)
+		assert.Empty(t, result.OnlyInB)
+		assert.Empty(t, result.NameMismatches)
+		require.Len(t, result.ContentMismatch, 1)
+		assert.Equal(t, DiffEntry{ID: "20250903T083109", FileA: "20250903T083109--paper.txt", FileB: "20250903T083109--paper.txt"}, result.ContentMismatch[0])
+	})
+
+	t.Run("allow-same-basename でも名前の異なる重複はエラー", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "20250903T083109--a.pdf"), []byte("a"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "20250903T083109--b.txt"), []byte("b"), 0644))
+
+		_, err := CompareDirectories(dir, dirB, DiffOptions{Writer: &bytes.Buffer{}, DuplicatePolicy: DuplicatePolicyAllowSameBasename})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "duplicate ID 20250903T083109")
+	})
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// DirCacheFileName はディレクトリの一覧のキャッシュファイルの名前（StateDirName 内に置く）
+const DirCacheFileName = "index.json"
+
+// DirCache はディレクトリの一覧のキャッシュを表す
+// 数万ファイルのディレクトリで list, search, md のたびにファイル名をパースしないように、reindex コマンドで作成する
+// 依存を増やさないため SQLite などのデータベースではなく JSON ファイルに保存し、ディレクトリごとに作成する
+// ディレクトリの更新日時が作成時と変わった場合（ファイルの追加・削除・リネーム）は古いとみなし、使わない
+// ファイルの内容の変更はエントリごとにサイズ・更新日時を確認し、変わったエントリはファイルの情報を更新して使う
+// parakeet が内容を書き換えた場合は Stale を記録し、reindex するまで使わない
+type DirCache struct {
+	DirModTime time.Time       `json:"dir_mtime"`       // 作成時のディレクトリの更新日時
+	Built      time.Time       `json:"built"`           // 作成日時
+	Stale      bool            `json:"stale,omitempty"` // 作成後にファイルの内容を書き換えた（reindex するまで使わない）
+	Entries    []DirCacheEntry `json:"entries"`         // ディレクトリ内のエントリ（名前順）
+}
+
+// DirCacheEntry はキャッシュしたディレクトリ内の1エントリを表す
+// ファイルごとに stat やファイル名のパースをしないように、サイズ・更新日時・パースしたファイル名も記録する
+// reindex --checksums の場合、フォーマット済みのファイルは verify で使うハッシュも記録する
+type DirCacheEntry struct {
+	Name       string                       `json:"name"`                 // ファイル名
+	Type       fs.FileMode                  `json:"type"`                 // ファイルの種類（ディレクトリ・シンボリックリンクなど）
+	Mode       fs.FileMode                  `json:"mode,omitempty"`       // パーミッションを含むモード
+	Size       int64                        `json:"size,omitempty"`       // サイズ（バイト）
+	ModTime    time.Time                    `json:"mtime,omitzero"`       // 更新日時
+	Components *parakeet.FileNameComponents `json:"components,omitempty"` // パースしたファイル名（フォーマット済みのファイルのみ）
+	SHA256     string                       `json:"sha256,omitempty"`     // 内容の SHA-256 ハッシュ（チェックサムを記録した場合のみ）
+}
+
+// DirCachePath はディレクトリの一覧のキャッシュのパスを返す
+func DirCachePath(dirPath string) string {
+	return filepath.Join(dirPath, StateDirName, DirCacheFileName)
+}
+
+// ReindexOptions はキャッシュの作成操作のオプションを表す
+type ReindexOptions struct {
+	Writer    io.Writer // 出力先
+	Clear     bool      // 作成せずにキャッシュを削除する
+	Checksums bool      // フォーマット済みのファイルのハッシュを記録する（前回記録した場合は指定しなくても記録する）
+	Jobs      int       // ハッシュを並列に計算する数（1 以下の場合は並列にしない）
+}
+
+// Reindex はディレクトリの一覧のキャッシュを作成（更新）する
+func Reindex(dirPath string, opts ReindexOptions) (*DirCache, error) {
+	// ディレクトリの存在チェック
+	if _, err := os.Stat(dirPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", dirPath)
+	}
+
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("reindex", "path", "entries", "checksums")
+	path := DirCachePath(dirPath)
+
+	if opts.Clear {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove index cache: %w", err)
+		}
+		reporter.Successf("Removed index cache: %s\n", path)
+		return nil, nil
+	}
+
+	// 作業用ディレクトリの作成でディレクトリの更新日時が変わるため、先に作成する
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	info, err := os.Stat(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get directory info: %w", err)
+	}
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	// 前回のハッシュ（更新日時とサイズが変わっていないファイルは引き継ぐ）
+	previous := make(map[string]DirCacheEntry)
+	if old, err := loadDirCache(dirPath); err == nil {
+		for _, entry := range old.Entries {
+			if entry.SHA256 != "" {
+				previous[entry.Name] = entry
+			}
+		}
+	}
+	checksums := opts.Checksums || len(previous) > 0
+
+	cache := &DirCache{
+		DirModTime: info.ModTime(),
+		Built:      time.Now(),
+		Entries:    make([]DirCacheEntry, 0, len(entries)),
+	}
+	// ハッシュは並列に計算し、エントリはディレクトリの順に記録する
+	type indexed struct {
+		entry DirCacheEntry
+		err   error
+	}
+	results := parallelMap(opts.Jobs, entries, func(entry os.DirEntry) indexed {
+		cacheEntry := DirCacheEntry{Name: entry.Name(), Type: entry.Type()}
+		info, err := entry.Info()
+		if err != nil {
+			return indexed{err: fmt.Errorf("failed to get file info: %w", err)}
+		}
+		cacheEntry.Mode = info.Mode()
+		cacheEntry.Size = info.Size()
+		cacheEntry.ModTime = info.ModTime()
+		if components, err := parakeet.ParseFileName(entry.Name()); err == nil {
+			cacheEntry.Components = components
+		}
+
+		if checksums && entry.Type().IsRegular() && cacheEntry.Components != nil {
+			err := checksumEntry(dirPath, previous[entry.Name()], &cacheEntry)
+			return indexed{entry: cacheEntry, err: err}
+		}
+		return indexed{entry: cacheEntry}
+	})
+	hashed := 0
+	for _, result := range results {
+		if result.err != nil {
+			return nil, result.err
+		}
+		if result.entry.SHA256 != "" {
+			hashed++
+		}
+		cache.Entries = append(cache.Entries, result.entry)
+	}
+
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode index cache: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write index cache: %w", err)
+	}
+
+	reporter.Emit("reindex", map[string]any{"path": path, "entries": len(cache.Entries), "checksums": hashed},
+		"✓ Indexed %d entries: %s\n", len(cache.Entries), path)
+	if checksums {
+		reporter.Printf("  Checksums: %d\n", hashed)
+	}
+	return cache, nil
+}
+
+// checksumEntry はファイルのハッシュを記録する
+// 前回から更新日時とサイズが変わっていないファイルはハッシュを計算し直さずに引き継ぎ、
+// 更新日時を変えずに内容が壊れた場合（ビット腐敗）も verify で検出できるようにする
+func checksumEntry(dirPath string, previous DirCacheEntry, cacheEntry *DirCacheEntry) error {
+	if previous.SHA256 != "" && previous.Size == cacheEntry.Size && previous.ModTime.Equal(cacheEntry.ModTime) {
+		cacheEntry.SHA256 = previous.SHA256
+		return nil
+	}
+
+	sum, err := hashFile(filepath.Join(dirPath, cacheEntry.Name))
+	if err != nil {
+		return err
+	}
+	cacheEntry.SHA256 = sum
+	return nil
+}
+
+// loadDirCache はディレクトリの一覧のキャッシュを読み込む（新しいかどうかは確認しない）
+func loadDirCache(dirPath string) (*DirCache, error) {
+	data, err := os.ReadFile(DirCachePath(dirPath))
+	if err != nil {
+		return nil, err
+	}
+
+	var cache DirCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("failed to decode index cache: %w", err)
+	}
+	return &cache, nil
+}
+
+// invalidateDirCache はディレクトリ内のファイルの内容を書き換えたことをキャッシュに記録し、reindex するまで使わないようにする
+// 記録したハッシュは verify と reindex で引き続き使う。キャッシュがない場合は何もしない
+func invalidateDirCache(dirPath string) error {
+	cache, err := loadDirCache(dirPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if cache.Stale {
+		return nil
+	}
+
+	cache.Stale = true
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return fmt.Errorf("failed to encode index cache: %w", err)
+	}
+	if err := os.WriteFile(DirCachePath(dirPath), data, 0644); err != nil {
+		return fmt.Errorf("failed to write index cache: %w", err)
+	}
+	return nil
+}
+
+// readDirCached はディレクトリ内のエントリを名前順に返す
+// 新しいキャッシュがあればディレクトリを読み込まずにキャッシュを使い、なければ os.ReadDir で読み込む
+func readDirCached(dirPath string) ([]os.DirEntry, error) {
+	if entries, ok := loadFreshDirCache(dirPath); ok {
+		return entries, nil
+	}
+	return os.ReadDir(dirPath)
+}
+
+// readDirFS は fsys が OS のファイルシステムの場合はキャッシュを使い、そうでなければ fsys からディレクトリを読み込む
+func readDirFS(fsys FileSystem, dirPath string) ([]os.DirEntry, error) {
+	// フックはリネームにのみ関係するため、フックを実行するファイルシステムも OS のファイルシステムとして扱う
+	if hooked, ok := fsys.(hookedFileSystem); ok {
+		fsys = hooked.FileSystem
+	}
+	if fsys == OSFileSystem {
+		return readDirCached(dirPath)
+	}
+	return fsys.ReadDir(dirPath)
+}
+
+// rewriteFile はファイルの内容を書き換え、ファイルのあるディレクトリのキャッシュを古いものとして記録する
+func rewriteFile(path string, data []byte) error {
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	return invalidateDirCache(filepath.Dir(path))
+}
+
+// loadFreshDirCache はキャッシュが新しい場合にそのエントリを返す
+// 更新日時の精度が粗いファイルシステムでは、作成と同じ秒にディレクトリを変更しても更新日時が変わらないため、
+// ディレクトリの更新日時が作成日時と同じ秒の場合も古いとみなす
+// ファイル名のパースは省くが、各エントリは lstat してサイズ・更新日時・モードが変わっていれば新しい情報を返す
+// （サブディレクトリ内のリネームや、ディレクトリの更新日時が変わらない内容の書き換えを反映する）
+func loadFreshDirCache(dirPath string) ([]os.DirEntry, bool) {
+	cache, err := loadDirCache(dirPath)
+	if err != nil || cache.Stale {
+		return nil, false
+	}
+
+	info, err := os.Stat(dirPath)
+	if err != nil || !info.ModTime().Equal(cache.DirModTime) {
+		return nil, false
+	}
+	if !cache.DirModTime.Before(cache.Built.Truncate(time.Second)) {
+		return nil, false
+	}
+
+	entries := make([]os.DirEntry, 0, len(cache.Entries))
+	for _, entry := range cache.Entries {
+		info, err := os.Lstat(filepath.Join(dirPath, entry.Name))
+		if err != nil || info.Mode().Type() != entry.Type {
+			// ディレクトリの更新日時を変えずに削除・置き換えられた場合はキャッシュを使わない
+			return nil, false
+		}
+		if info.Size() != entry.Size || !info.ModTime().Equal(entry.ModTime) || info.Mode() != entry.Mode {
+			entry.Size = info.Size()
+			entry.ModTime = info.ModTime()
+			entry.Mode = info.Mode()
+		}
+		entries = append(entries, cachedDirEntry{entry: entry})
+	}
+	return entries, true
+}
+
+// parseDirEntry はディレクトリ内のエントリのファイル名をパースする
+// キャッシュのエントリはパースしたファイル名を記録しているため、パースし直さない
+func parseDirEntry(entry os.DirEntry) (*parakeet.FileNameComponents, error) {
+	if cached, ok := entry.(cachedDirEntry); ok {
+		if cached.entry.Components == nil {
+			return nil, fmt.Errorf("filename does not match format: %s", cached.entry.Name)
+		}
+		components := *cached.entry.Components
+		components.Tags = slices.Clone(components.Tags)
+		return &components, nil
+	}
+	return parakeet.ParseFileName(entry.Name())
+}
+
+// statDirEntry はディレクトリ内のエントリのファイルの情報を返す
+// シンボリックリンクはリンク先の情報を返し、それ以外のキャッシュのエントリはキャッシュした情報を返す
+func statDirEntry(dirPath string, entry os.DirEntry) (fs.FileInfo, error) {
+	if entry.Type()&fs.ModeSymlink != 0 {
+		return os.Stat(filepath.Join(dirPath, entry.Name()))
+	}
+	return entry.Info()
+}
+
+// cachedDirEntry はキャッシュから作成したディレクトリ内のエントリ
+// ファイルの情報（サイズ・更新日時）は loadFreshDirCache で確認したものを返す
+type cachedDirEntry struct {
+	entry DirCacheEntry
+}
+
+func (e cachedDirEntry) Name() string               { return e.entry.Name }
+func (e cachedDirEntry) IsDir() bool                { return e.entry.Type.IsDir() }
+func (e cachedDirEntry) Type() fs.FileMode          { return e.entry.Type }
+func (e cachedDirEntry) Info() (fs.FileInfo, error) { return cachedFileInfo(e), nil }
+
+// cachedFileInfo はキャッシュしたエントリのファイルの情報
+type cachedFileInfo cachedDirEntry
+
+func (i cachedFileInfo) Name() string       { return i.entry.Name }
+func (i cachedFileInfo) Size() int64        { return i.entry.Size }
+func (i cachedFileInfo) Mode() fs.FileMode  { return i.entry.Mode }
+func (i cachedFileInfo) ModTime() time.Time { return i.entry.ModTime }
+func (i cachedFileInfo) IsDir() bool        { return i.entry.Type.IsDir() }
+func (i cachedFileInfo) Sys() any           { return nil }
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReindex(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-dircache-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	for _, name := range []string{"20250903T083109--tcpip__network.pdf", "20250904T000000--memo.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("test"), 0644))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, StateDirName), 0755))
+	past := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(tmpDir, past, past))
+
+	var buf bytes.Buffer
+	cache, err := Reindex(tmpDir, ReindexOptions{Writer: &buf})
+	require.NoError(t, err)
+	assert.Len(t, cache.Entries, 3)
+	assert.Contains(t, buf.String(), "Indexed 3 entries")
+	assert.FileExists(t, DirCachePath(tmpDir))
+
+	// ディレクトリが変わっていなければキャッシュを使う
+	entries, err := readDirCached(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.IsType(t, cachedDirEntry{}, entries[0])
+	assert.True(t, entries[0].IsDir())
+	info, err := entries[1].Info()
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), info.Size())
+	components, err := parseDirEntry(entries[1])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"network"}, components.Tags)
+	_, err = parseDirEntry(entries[0])
+	assert.Error(t, err)
+
+	// キャッシュのエントリは読み込み時に確認した情報を返す
+	require.NoError(t, os.Remove(filepath.Join(tmpDir, "20250904T000000--memo.md")))
+	info, err = entries[2].Info()
+	require.NoError(t, err)
+	assert.Equal(t, "20250904T000000--memo.md", info.Name())
+	assert.Equal(t, int64(4), info.Size())
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250904T000000--memo.md"), []byte("test"), 0644))
+	require.NoError(t, os.Chtimes(tmpDir, past, past))
+
+	files, err := ListFiles(tmpDir, ListOptions{Writer: &bytes.Buffer{}})
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	// ファイルを追加するとディレクトリの更新日時が変わり、キャッシュを使わない
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250905T000000--new.md"), []byte("test"), 0644))
+	entries, err = readDirCached(tmpDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 4)
+	_, cached := entries[0].(cachedDirEntry)
+	assert.False(t, cached)
+
+	files, err = SearchFiles(tmpDir, SearchOptions{Writer: &bytes.Buffer{}, Title: "new"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"20250905T000000--new.md"}, files)
+
+	// 削除
+	_, err = Reindex(tmpDir, ReindexOptions{Writer: &bytes.Buffer{}, Clear: true})
+	require.NoError(t, err)
+	assert.NoFileExists(t, DirCachePath(tmpDir))
+}
+
+func TestReadDirCached_RacyCache(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-dircache-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	cache, err := Reindex(tmpDir, ReindexOptions{Writer: &bytes.Buffer{}})
+	require.NoError(t, err)
+
+	// 作成と同じ秒に更新されたディレクトリのキャッシュは使わない
+	cache.Built = cache.DirModTime
+	data, err := json.Marshal(cache)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(DirCachePath(tmpDir), data, 0644))
+	_, ok := loadFreshDirCache(tmpDir)
+	assert.False(t, ok)
+
+	// ディレクトリの更新日時が作成日時より前の秒であれば使う
+	cache.Built = cache.DirModTime.Add(time.Second)
+	data, err = json.Marshal(cache)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(DirCachePath(tmpDir), data, 0644))
+	_, ok = loadFreshDirCache(tmpDir)
+	assert.True(t, ok)
+}
+
+func TestReadDirCached_RewrittenFile(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-dircache-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	path := filepath.Join(tmpDir, "20250903T083109--memo.md")
+	require.NoError(t, os.WriteFile(path, []byte("test"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, StateDirName), 0755))
+	past := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(tmpDir, past, past))
+	cache, err := Reindex(tmpDir, ReindexOptions{Writer: &bytes.Buffer{}, Checksums: true})
+	require.NoError(t, err)
+	oldSum := cache.Entries[1].SHA256
+	_, ok := loadFreshDirCache(tmpDir)
+	require.True(t, ok)
+
+	// 内容を書き換えてもディレクトリの更新日時は変わらないが、キャッシュは使わない
+	require.NoError(t, rewriteFile(path, []byte("rewritten")))
+	_, ok = loadFreshDirCache(tmpDir)
+	assert.False(t, ok)
+	entries, err := readDirCached(tmpDir)
+	require.NoError(t, err)
+	info, err := statDirEntry(tmpDir, entries[1])
+	require.NoError(t, err)
+	assert.Equal(t, int64(len("rewritten")), info.Size())
+
+	// reindex で書き換えたファイルのハッシュを計算し直す
+	cache, err = Reindex(tmpDir, ReindexOptions{Writer: &bytes.Buffer{}})
+	require.NoError(t, err)
+	assert.False(t, cache.Stale)
+	assert.NotEqual(t, oldSum, cache.Entries[1].SHA256)
+}
+
+func TestReadDirCached_EntryChanges(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+
+	path := filepath.Join(tmpDir, "20250903T083109--memo.md")
+	require.NoError(t, os.WriteFile(path, []byte("test"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "sub", "a.md"), []byte("a"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, StateDirName), 0755))
+	past := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(tmpDir, "sub"), past, past))
+	require.NoError(t, os.Chtimes(tmpDir, past, past))
+	_, err := Reindex(tmpDir, ReindexOptions{Writer: &bytes.Buffer{}})
+	require.NoError(t, err)
+	require.NoError(t, os.Chtimes(tmpDir, past, past))
+
+	// parakeet 以外が内容を書き換えてもディレクトリの更新日時は変わらないが、サイズ・更新日時は新しい値を返す
+	require.NoError(t, os.WriteFile(path, []byte("rewritten"), 0644))
+	modTime := past.Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+	// サブディレクトリ内のリネームはサブディレクトリの更新日時に反映する
+	require.NoError(t, os.Rename(filepath.Join(tmpDir, "sub", "a.md"), filepath.Join(tmpDir, "sub", "b.md")))
+	subInfo, err := os.Stat(filepath.Join(tmpDir, "sub"))
+	require.NoError(t, err)
+
+	entries, ok := loadFreshDirCache(tmpDir)
+	require.True(t, ok)
+	require.Len(t, entries, 3)
+	info, err := entries[1].Info()
+	require.NoError(t, err)
+	assert.Equal(t, int64(len("rewritten")), info.Size())
+	assert.True(t, info.ModTime().Equal(modTime))
+	info, err = entries[2].Info()
+	require.NoError(t, err)
+	assert.True(t, info.ModTime().Equal(subInfo.ModTime()))
+	components, err := parseDirEntry(entries[1])
+	require.NoError(t, err)
+	assert.Equal(t, "memo", components.Comment)
+
+	// ディレクトリの更新日時を戻して削除を隠しても、なくなったエントリがあればキャッシュを使わない
+	require.NoError(t, os.Remove(path))
+	require.NoError(t, os.Chtimes(tmpDir, past, past))
+	_, ok = loadFreshDirCache(tmpDir)
+	assert.False(t, ok)
+}
+
+func TestReindex_NonExistentDirectory(t *testing.T) {
+	t.Parallel()
+	_, err := Reindex("/non/existent", ReindexOptions{Writer: &bytes.Buffer{}})
+	assert.ErrorContains(t, err, "directory does not exist")
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// doctor の検査項目
+const (
+	DoctorCheckTagsFile           = "tags-file"           // タグ定義ファイルがない・読み込めない
+	DoctorCheckLegacyTagsFile     = "legacy-tags-file"    // 読み込まれない tag.toml がある
+	DoctorCheckDuplicateTimestamp = "duplicate-timestamp" // タイムスタンプの重複
+	DoctorCheckInvalidTimestamp   = "invalid-timestamp"   // 日時として解釈できないタイムスタンプ
+	DoctorCheckCaseCollision      = "case-collision"      // 大文字小文字のみ異なるファイル名
+	DoctorCheckWindowsName        = "windows-name"        // Windows で使えないファイル名
+	DoctorCheckLongPath           = "long-path"           // 長すぎるパス・ファイル名
+)
+
+// 検査結果の重大度
+const (
+	DoctorError   = "error"   // 修正が必要（終了コード1）
+	DoctorWarning = "warning" // 確認を推奨
+)
+
+const (
+	// legacyTagsFileName は以前のタグ定義ファイル名（現在は読み込まない）
+	legacyTagsFileName = "tag.toml"
+	// DefaultMaxPathLength は長すぎるとみなすパスの長さ（Windows の MAX_PATH）
+	DefaultMaxPathLength = 260
+	// maxFileNameBytes は多くのファイルシステムでのファイル名の上限（バイト）
+	maxFileNameBytes = 255
+)
+
+// windowsReservedNames は Windows で拡張子にかかわらず使えないファイル名
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// DoctorOptions は環境とアーカイブの検査のオプションを表す
+type DoctorOptions struct {
+	Writer          io.Writer       // 出力先
+	TagsFile        string          // タグ定義ファイル（空の場合は tags.toml、ResolveTagsFile で解決する）
+	MaxPathLength   int             // 長すぎるとみなす絶対パスの長さ（0 の場合は DefaultMaxPathLength）
+	DuplicatePolicy DuplicatePolicy // タイムスタンプ重複の扱い（許可される重複は報告せず、warn では警告にする）
+}
+
+// DoctorFinding は検査で見つかった1件の問題を表す
+type DoctorFinding struct {
+	Check    string // 検査項目
+	Severity string // 重大度（error, warning）
+	Target   string // 対象（ファイル名・タイムスタンプなど）
+	Message  string // 問題の説明
+	Remedy   string // 対処方法
+}
+
+// DoctorResult は検査の結果を表す
+type DoctorResult struct {
+	Findings []DoctorFinding
+}
+
+// HasErrors は修正が必要な問題があるかどうかを返す
+func (r *DoctorResult) HasErrors() bool {
+	for _, finding := range r.Findings {
+		if finding.Severity == DoctorError {
+			return true
+		}
+	}
+	return false
+}
+
+// RunDoctor はタグ定義ファイルとディレクトリ内のファイル名を検査し、問題ごとに対処方法を出力する
+func RunDoctor(dirPath string, opts DoctorOptions) (*DoctorResult, error) {
+	// ディレクトリの存在チェック
+	if _, err := os.Stat(dirPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", dirPath)
+	}
+
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	absDir, err := filepath.Abs(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve directory: %w", err)
+	}
+
+	maxPathLength := opts.MaxPathLength
+	if maxPathLength <= 0 {
+		maxPathLength = DefaultMaxPathLength
+	}
+
+	result := &DoctorResult{}
+	add := func(check, severity, target, message, remedy string) {
+		result.Findings = append(result.Findings, DoctorFinding{Check: check, Severity: severity, Target: target, Message: message, Remedy: remedy})
+	}
+
+	checkTagsFiles(dirPath, opts.TagsFile, add)
+
+	byTimestamp := map[string][]string{}
+	byLowerName := map[string][]string{}
+	for _, entry := range entries {
+		// シムはスキップ（ディレクトリも名前の衝突と Windows の制限は検査する）
+		if IsShim(dirPath, entry) {
+			continue
+		}
+
+		fileName := entry.Name()
+		if fileName == StateDirName {
+			continue
+		}
+		byLowerName[strings.ToLower(fileName)] = append(byLowerName[strings.ToLower(fileName)], fileName)
+
+		// ID のあるファイルは retitle を、まだ ID のないファイルやディレクトリは名前の変更を案内する
+		components, err := parakeet.ParseFileName(fileName)
+		formatted := err == nil && !entry.IsDir()
+		retitle := ""
+		if formatted {
+			retitle = fmt.Sprintf("`parakeet retitle %s <title>`", components.Timestamp)
+		}
+
+		if reason := windowsNameProblem(fileName); reason != "" {
+			remedy := "Rename it so it can be synced to Windows (it has no ID yet; rename it directly or run `parakeet generate`)."
+			if formatted {
+				remedy = "Rename the file so it can be synced to Windows (e.g. " + retitle + ")."
+			}
+			add(DoctorCheckWindowsName, DoctorError, fileName, reason, remedy)
+		}
+
+		if len(fileName) > maxFileNameBytes {
+			remedy := "Rename it to a shorter name."
+			if formatted {
+				remedy = "Shorten the title with " + retitle + " or drop tags with `parakeet tag rm`."
+			}
+			add(DoctorCheckLongPath, DoctorError, fileName, fmt.Sprintf("file name is %d bytes (limit %d)", len(fileName), maxFileNameBytes), remedy)
+		} else if path := filepath.Join(absDir, fileName); len(path) > maxPathLength {
+			remedy := "Rename it to a shorter name or move the archive to a shorter directory."
+			if formatted {
+				remedy = "Shorten the title with " + retitle + " or move the archive to a shorter directory."
+			}
+			add(DoctorCheckLongPath, DoctorWarning, fileName, fmt.Sprintf("path is %d characters (limit %d)", len(path), maxPathLength), remedy)
+		}
+
+		if !formatted {
+			continue
+		}
+		if _, err := time.Parse(timestampLayout, components.Timestamp); err != nil {
+			add(DoctorCheckInvalidTimestamp, DoctorError, fileName, fmt.Sprintf("timestamp %q is not a valid date and time", components.Timestamp),
+				"Run `parakeet fix` to repair the file name, or rename it with a valid YYYYMMDDTHHMMSS timestamp.")
+			continue
+		}
+		byTimestamp[components.Timestamp] = append(byTimestamp[components.Timestamp], fileName)
+	}
+
+	for _, timestamp := range sortedKeys(byTimestamp) {
+		files := byTimestamp[timestamp]
+		sort.Strings(files)
+		if len(files) < 2 || opts.DuplicatePolicy.Allows(files) {
+			continue
+		}
+		severity := DoctorError
+		if !opts.DuplicatePolicy.Fails() {
+			severity = DoctorWarning
+		}
+		add(DoctorCheckDuplicateTimestamp, severity, timestamp, fmt.Sprintf("%d files share this timestamp: %s", len(files), strings.Join(files, ", ")),
+			"Run `parakeet dedup` to assign new timestamps to all but the first file.")
+	}
+
+	for _, key := range sortedKeys(byLowerName) {
+		files := byLowerName[key]
+		if len(files) < 2 {
+			continue
+		}
+		sort.Strings(files)
+		add(DoctorCheckCaseCollision, DoctorError, files[0], fmt.Sprintf("file names differ only in case: %s", strings.Join(files, ", ")),
+			"Rename one of the files; they overwrite each other on case-insensitive file systems (macOS, Windows).")
+	}
+
+	reportDoctorFindings(ReporterFor(opts.Writer), result)
+	return result, nil
+}
+
+// checkTagsFiles はタグ定義ファイルの有無・書式と、読み込まれない tag.toml を検査する
+func checkTagsFiles(dirPath, tagsFile string, add func(check, severity, target, message, remedy string)) {
+	tagsPath := ResolveTagsFile(dirPath, tagsFile)
+	_, statErr := os.Stat(tagsPath)
+	switch {
+	case os.IsNotExist(statErr):
+		add(DoctorCheckTagsFile, DoctorWarning, tagsPath, "tag definition file not found; any tag is accepted",
+			"Create "+TagsFileName+" to define allowed tags (see `parakeet tagdef list`).")
+	case statErr != nil:
+		add(DoctorCheckTagsFile, DoctorError, tagsPath, statErr.Error(), "Check the permissions of the tag definition file.")
+	default:
+		validator, err := NewTagValidator(tagsPath)
+		switch {
+		case err != nil:
+			add(DoctorCheckTagsFile, DoctorError, tagsPath, err.Error(), "Fix the TOML syntax of the tag definition file.")
+		case !validator.HasDefinitions():
+			// テーブル名の誤り（[tags.xxx] など）は TOML としては正しいため、定義が0件になる
+			add(DoctorCheckTagsFile, DoctorWarning, tagsPath, "tag definition file has no [[tag]] entries; any tag is accepted",
+				"Define each tag as a [[tag]] table with a key; other tables (e.g. [tags.network]) are ignored.")
+		}
+	}
+
+	legacyPath := filepath.Join(dirPath, legacyTagsFileName)
+	if _, err := os.Stat(legacyPath); err != nil {
+		return
+	}
+	if statErr == nil {
+		add(DoctorCheckLegacyTagsFile, DoctorError, legacyPath, "both "+legacyTagsFileName+" and "+TagsFileName+" exist; only "+TagsFileName+" is read",
+			"Merge the definitions from "+legacyTagsFileName+" into "+TagsFileName+" and delete "+legacyTagsFileName+".")
+		return
+	}
+	add(DoctorCheckLegacyTagsFile, DoctorError, legacyPath, legacyTagsFileName+" is not read",
+		"Rename "+legacyTagsFileName+" to "+TagsFileName+".")
+}
+
+// windowsNameProblem はファイル名が Windows で使えない理由を返す（問題がない場合は空文字列）
+func windowsNameProblem(fileName string) string {
+	if i := strings.IndexAny(fileName, `<>:"\|?*`); i >= 0 {
+		return fmt.Sprintf("contains %q, which is not allowed on Windows", fileName[i])
+	}
+	for _, r := range fileName {
+		if r < 0x20 {
+			return "contains a control character, which is not allowed on Windows"
+		}
+	}
+	if strings.HasSuffix(fileName, ".") || strings.HasSuffix(fileName, " ") {
+		return "ends with a dot or space, which Windows strips"
+	}
+	base, _, _ := strings.Cut(fileName, ".")
+	if windowsReservedNames[strings.ToUpper(strings.TrimRight(base, " "))] {
+		return fmt.Sprintf("%s is a reserved device name on Windows", base)
+	}
+	return ""
+}
+
+// reportDoctorFindings は問題と対処方法、サマリーを出力する
+func reportDoctorFindings(reporter Reporter, result *DoctorResult) {
+	reporter.Columns("finding", "check", "severity", "target", "message", "remedy")
+	errors, warnings := 0, 0
+	for _, finding := range result.Findings {
+		symbol := "✗"
+		if finding.Severity == DoctorError {
+			errors++
+		} else {
+			symbol = "⚠"
+			warnings++
+		}
+		reporter.Emit("finding", map[string]any{
+			"check":    finding.Check,
+			"severity": finding.Severity,
+			"target":   finding.Target,
+			"message":  finding.Message,
+			"remedy":   finding.Remedy,
+		}, "%s [%s] %s: %s\n    → %s\n", symbol, finding.Check, finding.Target, finding.Message, finding.Remedy)
+	}
+
+	if len(result.Findings) == 0 {
+		reporter.Successf("No problems found\n")
+		return
+	}
+
+	reporter.Printf("\nDoctor Summary:\n")
+	reporter.Printf("  Errors: %d\n", errors)
+	reporter.Printf("  Warnings: %d\n", warnings)
+}
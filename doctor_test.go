@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunDoctor(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-doctor-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	files := []string{
+		"20250903T083109--tcpip__network.pdf",
+		"20250903T083109--dns__network.pdf",
+		"20251399T256161--broken.pdf",
+		"Report.txt",
+		"report.txt",
+		"CON.txt",
+		"20250904T000000--" + strings.Repeat("a", 230) + ".md",
+	}
+	for _, name := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("test"), 0644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "tag.toml"), []byte("[tags.network]\n"), 0644))
+
+	buf := &bytes.Buffer{}
+	result, err := RunDoctor(tmpDir, DoctorOptions{Writer: buf})
+	require.NoError(t, err)
+	assert.True(t, result.HasErrors())
+
+	checks := map[string]DoctorFinding{}
+	for _, finding := range result.Findings {
+		checks[finding.Check] = finding
+	}
+	assert.Equal(t, DoctorWarning, checks[DoctorCheckTagsFile].Severity)
+	assert.Contains(t, checks[DoctorCheckLegacyTagsFile].Remedy, "Rename tag.toml to tags.toml")
+	assert.Equal(t, "20250903T083109", checks[DoctorCheckDuplicateTimestamp].Target)
+	assert.Equal(t, "20251399T256161--broken.pdf", checks[DoctorCheckInvalidTimestamp].Target)
+	assert.Contains(t, checks[DoctorCheckCaseCollision].Message, "Report.txt, report.txt")
+	assert.Equal(t, "CON.txt", checks[DoctorCheckWindowsName].Target)
+	// ID のないファイルには retitle ではなく名前の変更を案内する
+	assert.NotContains(t, checks[DoctorCheckWindowsName].Remedy, "retitle")
+	assert.Contains(t, checks[DoctorCheckWindowsName].Remedy, "rename it directly")
+	assert.Equal(t, DoctorWarning, checks[DoctorCheckLongPath].Severity)
+	assert.Contains(t, checks[DoctorCheckLongPath].Message, "characters (limit 260)")
+	assert.Contains(t, checks[DoctorCheckLongPath].Remedy, "`parakeet retitle 20250904T000000 <title>`")
+
+	output := buf.String()
+	assert.Contains(t, output, "✗ [duplicate-timestamp] 20250903T083109: 2 files share this timestamp")
+	assert.Contains(t, output, "    → Run `parakeet dedup`")
+	assert.Contains(t, output, "Doctor Summary:")
+}
+
+func TestRunDoctor_TagsFiles(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-doctor-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	// 問題がない場合
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, TagsFileName), []byte("[[tag]]\nkey = \"network\"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250903T083109--tcpip__network.pdf"), []byte("test"), 0644))
+	buf := &bytes.Buffer{}
+	result, err := RunDoctor(tmpDir, DoctorOptions{Writer: buf})
+	require.NoError(t, err)
+	assert.Empty(t, result.Findings)
+	assert.Contains(t, buf.String(), "No problems found")
+
+	// テーブル名を誤って定義が0件の tags.toml は警告
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, TagsFileName), []byte("[tags.network]\ndescription = \"network\"\n"), 0644))
+	result, err = RunDoctor(tmpDir, DoctorOptions{Writer: &bytes.Buffer{}})
+	require.NoError(t, err)
+	require.Len(t, result.Findings, 1)
+	assert.Equal(t, DoctorCheckTagsFile, result.Findings[0].Check)
+	assert.Equal(t, DoctorWarning, result.Findings[0].Severity)
+	assert.Contains(t, result.Findings[0].Message, "no [[tag]] entries")
+
+	// tag.toml との混在と、読み込めない tags.toml
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "tag.toml"), []byte(""), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, TagsFileName), []byte("[tags\n"), 0644))
+	result, err = RunDoctor(tmpDir, DoctorOptions{Writer: &bytes.Buffer{}})
+	require.NoError(t, err)
+	require.Len(t, result.Findings, 2)
+	assert.Equal(t, DoctorCheckTagsFile, result.Findings[0].Check)
+	assert.Equal(t, DoctorError, result.Findings[0].Severity)
+	assert.Contains(t, result.Findings[1].Message, "both tag.toml and tags.toml exist")
+
+	// 長すぎるパスは警告
+	result, err = RunDoctor(tmpDir, DoctorOptions{Writer: &bytes.Buffer{}, MaxPathLength: 10})
+	require.NoError(t, err)
+	assert.Equal(t, DoctorCheckLongPath, result.Findings[len(result.Findings)-1].Check)
+	assert.Equal(t, DoctorWarning, result.Findings[len(result.Findings)-1].Severity)
+}
+
+func TestWindowsNameProblem(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"20250903T083109--tcpip__network.pdf", ""},
+		{"nul", "reserved device name"},
+		{"com1.tar.gz", "reserved device name"},
+		{"console.txt", ""},
+		{"a?b.txt", "not allowed on Windows"},
+		{"memo.", "ends with a dot or space"},
+		{"tab\tname.txt", "control character"},
+	}
+	for _, tt := range tests {
+		got := windowsNameProblem(tt.name)
+		if tt.want == "" {
+			assert.Empty(t, got, tt.name)
+			continue
+		}
+		assert.Contains(t, got, tt.want, tt.name)
+	}
+}
+
+func TestRunDoctor_DuplicatePolicy(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	for _, name := range []string{
+		"20250903T083109--paper.pdf",
+		"20250903T083109--paper.txt",
+		"20250903T083110--a.pdf",
+		"20250903T083110--b.pdf",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("test"), 0644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, TagsFileName), []byte("[[tag]]\nkey = \"network\"\n"), 0644))
+
+	duplicates := func(result *DoctorResult) map[string]string {
+		found := map[string]string{}
+		for _, finding := range result.Findings {
+			if finding.Check == DoctorCheckDuplicateTimestamp {
+				found[finding.Target] = finding.Severity
+			}
+		}
+		return found
+	}
+
+	result, err := RunDoctor(dir, DoctorOptions{Writer: &bytes.Buffer{}, DuplicatePolicy: DuplicatePolicyAllowSameBasename})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"20250903T083110": DoctorError}, duplicates(result))
+
+	result, err = RunDoctor(dir, DoctorOptions{Writer: &bytes.Buffer{}, DuplicatePolicy: DuplicatePolicyWarn})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"20250903T083109": DoctorWarning, "20250903T083110": DoctorWarning}, duplicates(result))
+	assert.False(t, result.HasErrors())
+
+	result, err = RunDoctor(dir, DoctorOptions{Writer: &bytes.Buffer{}, DuplicatePolicy: DuplicatePolicyAllowAll})
+	require.NoError(t, err)
+	assert.Empty(t, duplicates(result))
+}
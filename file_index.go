@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/template"
+	"time"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// IndexRecord はファイルインデックスの1件（パースしたファイル名とファイルの情報）を表す
+type IndexRecord struct {
+	Path      string    `json:"path"`      // ファイルのパス
+	Timestamp string    `json:"timestamp"` // タイムスタンプ（ID）
+	Comment   string    `json:"comment"`   // コメント（タイトル）
+	Tags      []string  `json:"tags"`      // タグ
+	Extension string    `json:"extension"` // 拡張子
+	Size      int64     `json:"size"`      // サイズ（バイト）
+	ModTime   time.Time `json:"mtime"`     // 更新日時
+}
+
+// fields は Reporter に渡すレコードのフィールドを返す
+func (r IndexRecord) fields() map[string]any {
+	return map[string]any{
+		"path":      r.Path,
+		"timestamp": r.Timestamp,
+		"comment":   r.Comment,
+		"tags":      r.Tags,
+		"extension": r.Extension,
+		"size":      r.Size,
+		"mtime":     r.ModTime.Format(time.RFC3339),
+	}
+}
+
+// ExportIndexOptions はファイルインデックスの出力のオプションを表す
+type ExportIndexOptions struct {
+	Writer io.Writer // 出力先（--format json, jsonl の Reporter ではレコードを JSON で出力する）
+	FilterOptions
+	Template *template.Template // human 形式で出力する1ファイル1行のテンプレート（nil の場合はパスのみ）
+}
+
+// ExportIndex はディレクトリ内のフォーマット済みファイルをパースし、1ファイル1レコードで出力する
+// 外部のツールがファイル名のパーサーを実装しなくてもアーカイブを扱えるようにする
+func ExportIndex(dirPath string, opts ExportIndexOptions) ([]IndexRecord, error) {
+	// ディレクトリの存在チェック
+	if _, err := os.Stat(dirPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", dirPath)
+	}
+
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("file", "timestamp", "comment", "tags", "extension", "size", "mtime", "path")
+
+	records := []IndexRecord{}
+	for _, entry := range entries {
+		// ディレクトリとシムはスキップ
+		if entry.IsDir() || IsShim(dirPath, entry) {
+			continue
+		}
+
+		fileName := entry.Name()
+		if !opts.Matches(fileName) {
+			continue
+		}
+
+		// フォーマット済みファイルのみ処理
+		components, err := parakeet.ParseFileName(fileName)
+		if err != nil {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get file info: %w", err)
+		}
+
+		tags := components.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+
+		record := IndexRecord{
+			Path:      filepath.Join(dirPath, fileName),
+			Timestamp: components.Timestamp,
+			Comment:   components.Comment,
+			Tags:      tags,
+			Extension: components.Extension,
+			Size:      info.Size(),
+			ModTime:   info.ModTime(),
+		}
+		records = append(records, record)
+
+		line := record.Path + "\n"
+		if opts.Template != nil {
+			line, err = executeOutputTemplate(opts.Template, OutputTemplateData{
+				FileNameComponents: *components,
+				File:               fileName,
+				Path:               record.Path,
+				Size:               record.Size,
+				ModTime:            record.ModTime,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		reporter.Emit("file", record.fields(), "%s", line)
+	}
+
+	return records, nil
+}
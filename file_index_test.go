@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportIndex(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-file-index-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	mtime := time.Date(2025, 9, 3, 8, 31, 9, 0, time.UTC)
+	for _, name := range []string{"20250903T083109--tcpip__network_infra.pdf", "20250904T000000--memo.md", "invalid-file.pdf"} {
+		path := filepath.Join(tmpDir, name)
+		require.NoError(t, os.WriteFile(path, []byte("content"), 0644))
+		require.NoError(t, os.Chtimes(path, mtime, mtime))
+	}
+
+	var buf bytes.Buffer
+	reporter, err := NewReporter(&buf, ReporterConfig{Format: FormatJSONL})
+	require.NoError(t, err)
+
+	records, err := ExportIndex(tmpDir, ExportIndexOptions{Writer: reporter})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, IndexRecord{
+		Path:      filepath.Join(tmpDir, "20250903T083109--tcpip__network_infra.pdf"),
+		Timestamp: "20250903T083109",
+		Comment:   "tcpip",
+		Tags:      []string{"network", "infra"},
+		Extension: "pdf",
+		Size:      7,
+		ModTime:   records[0].ModTime,
+	}, records[0])
+	assert.True(t, records[0].ModTime.Equal(mtime))
+
+	// 1行1レコードの JSON（タグのないファイルは空の配列）
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	var record map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+	assert.Equal(t, "memo", record["comment"])
+	assert.Equal(t, []any{}, record["tags"])
+	assert.Equal(t, "md", record["extension"])
+	recordTime, err := time.Parse(time.RFC3339, record["mtime"].(string))
+	require.NoError(t, err)
+	assert.True(t, recordTime.Equal(mtime))
+
+	// 絞り込み
+	records, err = ExportIndex(tmpDir, ExportIndexOptions{Writer: &bytes.Buffer{}, FilterOptions: FilterOptions{Extensions: []string{"md"}}})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "20250904T000000", records[0].Timestamp)
+}
+
+func TestExportIndex_NonExistentDirectory(t *testing.T) {
+	t.Parallel()
+	_, err := ExportIndex("/non/existent", ExportIndexOptions{Writer: &bytes.Buffer{}})
+	assert.ErrorContains(t, err, "directory does not exist")
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FileSystem はファイル名の操作で使うファイルシステムを表す
+// io/fs は読み込みのみのため、リネームなどの書き込み操作を加えている
+// オプションで nil を指定した場合は OS のファイルシステム（OSFileSystem）を使う
+type FileSystem interface {
+	Stat(name string) (fs.FileInfo, error)
+	ReadDir(name string) ([]fs.DirEntry, error)
+	ReadFile(name string) ([]byte, error)
+	Readlink(name string) (string, error)
+	Rename(oldpath, newpath string) error
+	Symlink(oldname, newname string) error
+	MkdirAll(name string, perm fs.FileMode) error
+	Abs(name string) (string, error) // 親ディレクトリをたどるための絶対パス
+}
+
+// OSFileSystem は OS のファイルシステム
+var OSFileSystem FileSystem = osFileSystem{}
+
+// fileSystemOrOS は fsys が nil の場合に OS のファイルシステムを返す
+func fileSystemOrOS(fsys FileSystem) FileSystem {
+	if fsys == nil {
+		return OSFileSystem
+	}
+	return fsys
+}
+
+// osFileSystem は os パッケージの関数をそのまま呼び出す
+type osFileSystem struct{}
+
+func (osFileSystem) Stat(name string) (fs.FileInfo, error)        { return os.Stat(name) }
+func (osFileSystem) ReadDir(name string) ([]fs.DirEntry, error)   { return os.ReadDir(name) }
+func (osFileSystem) ReadFile(name string) ([]byte, error)         { return os.ReadFile(name) }
+func (osFileSystem) Readlink(name string) (string, error)         { return os.Readlink(name) }
+func (osFileSystem) Rename(oldpath, newpath string) error         { return RenameFile(oldpath, newpath) }
+func (osFileSystem) Symlink(oldname, newname string) error        { return os.Symlink(oldname, newname) }
+func (osFileSystem) MkdirAll(name string, perm fs.FileMode) error { return os.MkdirAll(name, perm) }
+func (osFileSystem) Abs(name string) (string, error)              { return filepath.Abs(name) }
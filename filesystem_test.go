@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemFileSystem(t *testing.T) {
+	t.Parallel()
+	files := fstest.MapFS{
+		"docs/a.pdf": {Data: []byte("a")},
+	}
+	fsys := NewMemFileSystem(files)
+
+	info, err := fsys.Stat("/docs")
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+
+	_, err = fsys.Stat("docs/missing.pdf")
+	assert.True(t, os.IsNotExist(err))
+
+	require.NoError(t, fsys.Rename("docs/a.pdf", "docs/b.pdf"))
+	assert.NotContains(t, files, "docs/a.pdf")
+	data, err := fsys.ReadFile("docs/b.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(data))
+
+	// 存在しないディレクトリへはリネームできない
+	assert.ErrorIs(t, fsys.Rename("docs/b.pdf", "other/b.pdf"), fs.ErrNotExist)
+
+	require.NoError(t, fsys.MkdirAll("docs/sub/deep", 0755))
+	info, err = fsys.Stat("docs/sub")
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+
+	require.NoError(t, fsys.Symlink("b.pdf", "docs/a.pdf"))
+	target, err := fsys.Readlink("docs/a.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, "b.pdf", target)
+	_, err = fsys.Readlink("docs/b.pdf")
+	assert.Error(t, err)
+
+	abs, err := fsys.Abs("docs/../docs/sub")
+	require.NoError(t, err)
+	assert.Equal(t, "/docs/sub", abs)
+}
+
+func TestOperations_MemFileSystem(t *testing.T) {
+	t.Parallel()
+	files := fstest.MapFS{
+		"tags.toml":                         {Data: []byte("[[tag]]\nkey = \"book\"\n\n[[tag]]\nkey = \"work\"\n")},
+		"docs/20250903T083109--report.pdf":  {Data: []byte("test")},
+		"docs/report_final.pdf":             {Data: []byte("test")},
+		"docs/notes.txt":                    {Data: []byte("test")},
+		"docs/20250903T083110--old__ml.pdf": {Data: []byte("test")},
+	}
+	fsys := NewMemFileSystem(files)
+
+	// 親ディレクトリのtags.tomlも読み込む
+	result, err := ValidateFileNames(context.Background(), "docs", ValidateOptions{Writer: &bytes.Buffer{}, FilterOptions: FilterOptions{Extensions: []string{"pdf"}}, FS: fsys})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"report_final.pdf"}, result.InvalidFiles)
+	assert.Equal(t, map[string][]string{"20250903T083110--old__ml.pdf": {"ml"}}, result.UndefinedTagFiles)
+
+	require.NoError(t, GenerateFileNames(context.Background(), "docs", RenameOptions{Writer: &bytes.Buffer{}, FilterOptions: FilterOptions{Extensions: []string{"pdf"}}, FS: fsys}))
+	generated := ""
+	for name := range files {
+		if dir, base := filepath.Split(name); dir == "docs/" && parakeet.IsFormatted(base) {
+			if components, err := parakeet.ParseFileName(base); err == nil && components.Comment == "report_final" {
+				generated = base
+			}
+		}
+	}
+	require.NotEmpty(t, generated, "generated file not found: %v", files)
+	assert.NotContains(t, files, "docs/report_final.pdf")
+	assert.Contains(t, files, "docs/notes.txt")
+
+	require.NoError(t, SetTags("docs/20250903T083109--report.pdf", []string{"work"}, TagOptions{Writer: &bytes.Buffer{}, FS: fsys}))
+	assert.Contains(t, files, "docs/20250903T083109--report__work.pdf")
+	assert.ErrorContains(t, SetTags("docs/20250903T083109--report__work.pdf", []string{"unknown"}, TagOptions{Writer: &bytes.Buffer{}, FS: fsys}), "unknown")
+
+	_, err = AddTag("docs", "book", TagBulkOptions{Writer: &bytes.Buffer{}, IDs: []string{"20250903T083109"}, FS: fsys})
+	require.NoError(t, err)
+	assert.Contains(t, files, "docs/20250903T083109--report__book_work.pdf")
+
+	renamed, err := RenameTag("docs", "ml", "book", TagRenameOptions{Writer: &bytes.Buffer{}, FS: fsys})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"20250903T083110--old__ml.pdf": "20250903T083110--old__book.pdf"}, renamed.Renamed)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, GenerateMarkdownTable("docs", MarkdownOptions{Writer: buf, FilterOptions: FilterOptions{Extensions: []string{"pdf"}}, FS: fsys}))
+	output := buf.String()
+	assert.Contains(t, output, "| 20250903T083109 | report | book, work |")
+	assert.Contains(t, output, "| 20250903T083110 | old | book |")
+	assert.Contains(t, output, "| report_final |")
+
+	// 存在しないディレクトリ
+	_, err = ValidateFileNames(context.Background(), "missing", ValidateOptions{Writer: &bytes.Buffer{}, FS: fsys})
+	assert.ErrorContains(t, err, "directory does not exist: missing")
+}
+
+func TestTagRules_MemFileSystem(t *testing.T) {
+	t.Parallel()
+	// tags.tomlがない場合はどのコマンドも書式のみチェックする
+	files := fstest.MapFS{
+		"docs/20250903T083109--report.pdf":  {Data: []byte("test")},
+		"docs/20250903T083110--old__ml.pdf": {Data: []byte("test")},
+	}
+	fsys := NewMemFileSystem(files)
+
+	require.NoError(t, SetTags("docs/20250903T083109--report.pdf", []string{"work"}, TagOptions{Writer: &bytes.Buffer{}, FS: fsys}))
+	assert.Contains(t, files, "docs/20250903T083109--report__work.pdf")
+
+	_, err := AddTag("docs", "todo", TagBulkOptions{Writer: &bytes.Buffer{}, IDs: []string{"20250903T083109"}, FS: fsys})
+	require.NoError(t, err)
+	assert.Contains(t, files, "docs/20250903T083109--report__todo_work.pdf")
+
+	_, err = RenameTag("docs", "ml", "ai", TagRenameOptions{Writer: &bytes.Buffer{}, FS: fsys})
+	require.NoError(t, err)
+	assert.Contains(t, files, "docs/20250903T083110--old__ai.pdf")
+
+	// 書式は常にチェックする
+	assert.ErrorContains(t, SetTags("docs/20250903T083110--old__ai.pdf", []string{"bad_tag"}, TagOptions{Writer: &bytes.Buffer{}, FS: fsys}), "special characters")
+	_, err = AddTag("docs", "bad-tag", TagBulkOptions{Writer: &bytes.Buffer{}, All: true, FS: fsys})
+	assert.ErrorContains(t, err, "special characters")
+	_, err = RenameTag("docs", "ai", "bad.tag", TagRenameOptions{Writer: &bytes.Buffer{}, FS: fsys})
+	assert.ErrorContains(t, err, "special characters")
+}
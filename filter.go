@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// 日付範囲の指定で受け付けるフォーマット
+const (
+	dateLayout      = "2006-01-02"
+	timestampLayout = parakeet.TimestampLayout
+	dateTimeLayout  = "2006-01-02T15:04:05"
+)
+
+// FilterOptions はディレクトリ内のファイルを処理対象に絞り込む共通の条件を表す
+// 各コマンドのオプションに埋め込んで使う
+type FilterOptions struct {
+	Extensions []string       // 対象拡張子（空の場合は全ファイル）
+	Since      time.Time      // この日時以降のタイムスタンプのみ対象（ゼロ値の場合は制限なし）
+	Until      time.Time      // この日時以前のタイムスタンプのみ対象（ゼロ値の場合は制限なし）
+	Exclude    *IgnoreMatcher // 一致するファイルを対象外にする（nil の場合は除外しない）
+	Query      *Query         // 一致するファイルのみ対象（nil の場合は制限なし）
+	// tags.toml などのメタデータファイルも対象にする（デフォルトは対象外）
+	IncludeMetadata bool
+}
+
+// MetadataFileNames は parakeet 自身が使うメタデータファイルの名前
+// 管理対象のファイルではないため、デフォルトで処理対象から除外する
+var MetadataFileNames = []string{TagsFileName, ConfigFileName, IgnoreFileName}
+
+// IsMetadataFile はファイルが parakeet のメタデータファイルかどうかを返す
+func IsMetadataFile(fileName string) bool {
+	return slices.Contains(MetadataFileNames, filepath.Base(fileName))
+}
+
+// Matches はファイル名が絞り込み条件に一致するかチェックする
+// 日付範囲が指定されている場合、タイムスタンプを持たないファイルは一致しない
+func (f FilterOptions) Matches(fileName string) bool {
+	if f.Excludes(fileName) {
+		return false
+	}
+
+	if !parakeet.MatchesExtensions(fileName, f.Extensions) {
+		return false
+	}
+
+	if !f.Query.Match(fileName) {
+		return false
+	}
+
+	if !f.HasDateRange() {
+		return true
+	}
+
+	components, err := parakeet.ParseFileName(fileName)
+	if err != nil {
+		return false
+	}
+
+	t, err := time.ParseInLocation(timestampLayout, components.Timestamp, time.Local)
+	if err != nil {
+		return false
+	}
+
+	if !f.Since.IsZero() && t.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && t.After(f.Until) {
+		return false
+	}
+
+	return true
+}
+
+// Excludes はファイルが除外パターンまたはメタデータファイルとして対象外かどうかを返す
+func (f FilterOptions) Excludes(fileName string) bool {
+	if !f.IncludeMetadata && IsMetadataFile(fileName) {
+		return true
+	}
+	return f.Exclude.Match(fileName)
+}
+
+// HasDateRange は日付範囲が指定されているかどうかを返す
+func (f FilterOptions) HasDateRange() bool {
+	return !f.Since.IsZero() || !f.Until.IsZero()
+}
+
+// ParseDateBound は日付範囲の指定をパースする
+// "2025-09-01" 形式の日付、"20250903T083109" 形式のタイムスタンプ、"2025-09-03T08:31:09" 形式の日時と、
+// 現在からの相対的な指定（today, yesterday, 7d, 2w, "last week", "3 days ago" など）を受け付ける
+// endOfDay が true の場合、日付のみの指定（today, yesterday を含む）はその日の終わり（23:59:59）として扱う
+func ParseDateBound(s string, endOfDay bool) (time.Time, error) {
+	return parseDateBoundAt(s, endOfDay, time.Now())
+}
+
+// parseDateBoundAt は now を現在時刻として日付範囲の指定をパースする
+func parseDateBoundAt(s string, endOfDay bool, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.ParseInLocation(dateLayout, s, time.Local); err == nil {
+		return dayBound(t, endOfDay), nil
+	}
+
+	for _, layout := range []string{timestampLayout, dateTimeLayout} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	if t, ok := parseRelativeDate(s, endOfDay, now); ok {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date (expected YYYY-MM-DD, YYYYMMDDTHHMMSS or a relative date such as 7d, yesterday, \"last week\"): %s", s)
+}
+
+// relativeDatePattern は "7d" や "3 days ago" の形式の相対的な日時
+var relativeDatePattern = regexp.MustCompile(`^(\d+)\s*(h|hours?|d|days?|w|weeks?|m|months?|y|years?)(\s+ago)?$`)
+
+// parseRelativeDate は現在からの相対的な日時をパースする
+// today, yesterday はその日の始まり（endOfDay の場合は終わり）、
+// 7d, 2w, 3m（月）, 1y, 12h, "3 days ago", "last week" は現在からその期間を遡った日時になる
+func parseRelativeDate(s string, endOfDay bool, now time.Time) (time.Time, bool) {
+	s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch s {
+	case "now":
+		return now, true
+	case "today":
+		return dayBound(today, endOfDay), true
+	case "yesterday":
+		return dayBound(today.AddDate(0, 0, -1), endOfDay), true
+	}
+
+	amount, unit := 1, ""
+	if rest, ok := strings.CutPrefix(s, "last "); ok {
+		unit = rest
+	} else if m := relativeDatePattern.FindStringSubmatch(s); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return time.Time{}, false
+		}
+		amount, unit = n, m[2]
+	} else {
+		return time.Time{}, false
+	}
+
+	switch strings.TrimSuffix(unit, "s") {
+	case "h", "hour":
+		return now.Add(-time.Duration(amount) * time.Hour), true
+	case "d", "day":
+		return now.AddDate(0, 0, -amount), true
+	case "w", "week":
+		return now.AddDate(0, 0, -7*amount), true
+	case "m", "month":
+		return now.AddDate(0, -amount, 0), true
+	case "y", "year":
+		return now.AddDate(-amount, 0, 0), true
+	}
+	return time.Time{}, false
+}
+
+// dayBound は日付の始まり（endOfDay の場合は終わりの 23:59:59）を返す
+func dayBound(day time.Time, endOfDay bool) time.Time {
+	if endOfDay {
+		return day.AddDate(0, 0, 1).Add(-time.Second)
+	}
+	return day
+}
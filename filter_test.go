@@ -0,0 +1,188 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterOptions_Matches(t *testing.T) {
+	t.Parallel()
+	since := time.Date(2025, 9, 1, 0, 0, 0, 0, time.Local)
+	until := time.Date(2025, 9, 30, 23, 59, 59, 0, time.Local)
+
+	tests := []struct {
+		name     string
+		filter   FilterOptions
+		fileName string
+		expected bool
+	}{
+		{
+			name:     "no extensions matches all",
+			filter:   FilterOptions{},
+			fileName: "document.pdf",
+			expected: true,
+		},
+		{
+			name:     "matching extension",
+			filter:   FilterOptions{Extensions: []string{"pdf", "txt"}},
+			fileName: "20250903T083109--document.pdf",
+			expected: true,
+		},
+		{
+			name:     "non-matching extension",
+			filter:   FilterOptions{Extensions: []string{"pdf"}},
+			fileName: "notes.md",
+			expected: false,
+		},
+		{
+			name:     "within date range",
+			filter:   FilterOptions{Since: since, Until: until},
+			fileName: "20250903T083109--document.pdf",
+			expected: true,
+		},
+		{
+			name:     "before since",
+			filter:   FilterOptions{Since: since},
+			fileName: "20250831T235959--document.pdf",
+			expected: false,
+		},
+		{
+			name:     "until is inclusive",
+			filter:   FilterOptions{Until: until},
+			fileName: "20250930T235959--document.pdf",
+			expected: true,
+		},
+		{
+			name:     "after until",
+			filter:   FilterOptions{Until: until},
+			fileName: "20251001T000000--document.pdf",
+			expected: false,
+		},
+		{
+			name:     "unformatted file with date range",
+			filter:   FilterOptions{Since: since},
+			fileName: "document.pdf",
+			expected: false,
+		},
+		{
+			name:     "metadata file is skipped",
+			filter:   FilterOptions{},
+			fileName: "tags.toml",
+			expected: false,
+		},
+		{
+			name:     "metadata file with extension filter",
+			filter:   FilterOptions{Extensions: []string{"toml"}},
+			fileName: "parakeet.toml",
+			expected: false,
+		},
+		{
+			name:     "include metadata",
+			filter:   FilterOptions{IncludeMetadata: true},
+			fileName: ".parakeetignore",
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, tt.filter.Matches(tt.fileName))
+		})
+	}
+}
+
+func TestParseDateBound(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		input     string
+		endOfDay  bool
+		expected  time.Time
+		wantError bool
+	}{
+		{
+			name:     "empty",
+			input:    "",
+			expected: time.Time{},
+		},
+		{
+			name:     "date as start of day",
+			input:    "2025-09-01",
+			expected: time.Date(2025, 9, 1, 0, 0, 0, 0, time.Local),
+		},
+		{
+			name:     "date as end of day",
+			input:    "2025-09-01",
+			endOfDay: true,
+			expected: time.Date(2025, 9, 1, 23, 59, 59, 0, time.Local),
+		},
+		{
+			name:     "full timestamp",
+			input:    "20250903T083109",
+			endOfDay: true,
+			expected: time.Date(2025, 9, 3, 8, 31, 9, 0, time.Local),
+		},
+		{
+			name:     "date time",
+			input:    "2025-09-03T08:31:09",
+			expected: time.Date(2025, 9, 3, 8, 31, 9, 0, time.Local),
+		},
+		{
+			name:      "invalid",
+			input:     "next week",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := ParseDateBound(tt.input, tt.endOfDay)
+			if tt.wantError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.expected.Equal(result), "expected %v, got %v", tt.expected, result)
+		})
+	}
+}
+
+func TestParseDateBound_Relative(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2025, 9, 10, 15, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		input    string
+		endOfDay bool
+		expected time.Time
+	}{
+		{"now", false, now},
+		{"today", false, time.Date(2025, 9, 10, 0, 0, 0, 0, time.Local)},
+		{"today", true, time.Date(2025, 9, 10, 23, 59, 59, 0, time.Local)},
+		{"yesterday", false, time.Date(2025, 9, 9, 0, 0, 0, 0, time.Local)},
+		{"Yesterday", true, time.Date(2025, 9, 9, 23, 59, 59, 0, time.Local)},
+		{"12h", false, time.Date(2025, 9, 10, 3, 30, 0, 0, time.Local)},
+		{"7d", true, time.Date(2025, 9, 3, 15, 30, 0, 0, time.Local)},
+		{"2w", false, time.Date(2025, 8, 27, 15, 30, 0, 0, time.Local)},
+		{"3m", false, time.Date(2025, 6, 10, 15, 30, 0, 0, time.Local)},
+		{"1y", false, time.Date(2024, 9, 10, 15, 30, 0, 0, time.Local)},
+		{"3 days ago", false, time.Date(2025, 9, 7, 15, 30, 0, 0, time.Local)},
+		{"last week", false, time.Date(2025, 9, 3, 15, 30, 0, 0, time.Local)},
+		{"last  month", false, time.Date(2025, 8, 10, 15, 30, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		result, err := parseDateBoundAt(tt.input, tt.endOfDay, now)
+		require.NoError(t, err, tt.input)
+		assert.True(t, tt.expected.Equal(result), "%s: expected %v, got %v", tt.input, tt.expected, result)
+	}
+
+	for _, input := range []string{"last fortnight", "7x", "-3d", "days ago"} {
+		_, err := parseDateBoundAt(input, false, now)
+		assert.ErrorContains(t, err, "invalid date", input)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// FixOptions はファイル名修正操作のオプションを表す
+type FixOptions struct {
+	Writer io.Writer // 出力先
+	FilterOptions
+	DryRun  bool                                        // 実際にはリネームしない
+	Confirm func(oldName, newName string) (bool, error) // ファイルごとの確認（nil の場合は確認しない）
+	Resume  *Checkpoint                                 // 再開する実行のチェックポイント（処理済みのファイルをスキップする）
+	Journal *Journal                                    // 実行したリネームを記録するジャーナル（nil の場合は記録しない）
+	Hooks   *HookRunner                                 // リネームの前後に実行するフック（nil の場合は実行しない）
+}
+
+// FixResult はファイル名修正操作の結果を表す
+type FixResult struct {
+	Fixed   map[string]string // 修正したファイル: 旧ファイル名 -> 新ファイル名
+	Skipped []string          // 修正しなかったファイル名
+}
+
+// FixFileNames は ValidateFileNames が無効と判定したファイルを正しいフォーマットにリネームする
+// コメントは既存のファイル名から作成し、重複しないタイムスタンプを付与する
+func FixFileNames(ctx context.Context, targetDir string, opts FixOptions) (*FixResult, error) {
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("fixed", "from", "to", "dry_run")
+
+	validation, err := ValidateFileNames(ctx, targetDir, ValidateOptions{
+		Writer:        io.Discard,
+		FilterOptions: opts.FilterOptions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 既存のタイムスタンプを収集
+	existingTimestamps, err := CollectExistingTimestamps(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect existing timestamps: %w", err)
+	}
+
+	result := &FixResult{
+		Fixed:   make(map[string]string),
+		Skipped: []string{},
+	}
+
+	prefix := ""
+	if opts.DryRun {
+		prefix = "[dry-run] "
+	}
+
+	// チェックポイントに記録する処理済みのファイル名
+	processed := []string{}
+
+	for _, oldName := range validation.InvalidFiles {
+		// キャンセルされた場合は、処理中のファイルを終えた時点で止める
+		if ctx.Err() != nil {
+			break
+		}
+
+		// 中断した実行で処理済み（確認で断ったものを含む）のファイルはスキップ
+		if opts.Resume.Done(oldName) {
+			result.Skipped = append(result.Skipped, oldName)
+			continue
+		}
+
+		ext := filepath.Ext(oldName)
+		baseName := strings.TrimSuffix(oldName, ext)
+		if ext != "" {
+			ext = ext[1:] // 先頭のドットを削除
+		}
+
+		timestamp := parakeet.GenerateUniqueTimestampFrom(time.Now(), existingTimestamps)
+		components := parakeet.FileNameComponents{
+			Timestamp: timestamp,
+			Comment:   parakeet.SanitizeComment(baseName),
+			Tags:      []string{},
+			Extension: ext,
+		}
+		// タイムスタンプや区切りのみが無効なファイルはコメントとタグを整えて残す
+		if parsed, err := parakeet.ParseFileName(oldName); err == nil && parsed.Comment != "" {
+			components.Comment = parakeet.SanitizeComment(parsed.Comment)
+			for _, tag := range parsed.Tags {
+				if tag != "" {
+					components.Tags = append(components.Tags, tag)
+				}
+			}
+			// タイムスタンプが有効な場合はそのまま使う
+			if parakeet.ValidateTimestamp(parsed.Timestamp) == nil {
+				components.Timestamp = parsed.Timestamp
+			}
+		}
+		newName := components.FormatFileName()
+		newPath := filepath.Join(targetDir, newName)
+
+		if _, err := os.Stat(newPath); err == nil {
+			reporter.Warnf("target file already exists, skipping: %s\n", newName)
+			result.Skipped = append(result.Skipped, oldName)
+			continue
+		}
+
+		if opts.Confirm != nil {
+			ok, err := opts.Confirm(oldName, newName)
+			if err != nil {
+				return result, err
+			}
+			if !ok {
+				result.Skipped = append(result.Skipped, oldName)
+				processed = append(processed, oldName)
+				continue
+			}
+		}
+
+		if !opts.DryRun {
+			if err := opts.Hooks.RenameFile(filepath.Join(targetDir, oldName), newPath); err != nil {
+				return result, fmt.Errorf("failed to rename file: %w", err)
+			}
+			if err := opts.Journal.Record(targetDir, renamePlan{From: oldName, To: newName}); err != nil {
+				reporter.Warnf("%s (%v)\n", oldName, err)
+			}
+		}
+
+		// 使用したタイムスタンプを記録
+		existingTimestamps[components.Timestamp] = true
+		result.Fixed[oldName] = newName
+		processed = append(processed, oldName)
+		reporter.Emit("fixed", map[string]any{"from": oldName, "to": newName, "dry_run": opts.DryRun}, "%s✓ Fixed: %s → %s\n", prefix, oldName, newName)
+	}
+
+	// サマリーを出力
+	reporter.Printf("\nFix Summary:\n")
+	reporter.Printf("  Fixed: %d\n", len(result.Fixed))
+	reporter.Printf("  Skipped: %d\n", len(result.Skipped))
+
+	if err := ctx.Err(); err != nil {
+		return result, interruptRun(err, targetDir, "fix", opts.Resume, processed, opts.DryRun)
+	}
+	return result, finishRun(targetDir, "fix", opts.DryRun)
+}
+
+// ConfirmFix はファイルごとにリネームしてよいかをインタラクティブに確認する
+func ConfirmFix(oldName, newName string) (bool, error) {
+	prompt := &survey.Confirm{
+		Message: fmt.Sprintf("Rename %s → %s?", oldName, newName),
+		Default: true,
+	}
+
+	var ok bool
+	if err := survey.AskOne(prompt, &ok); err != nil {
+		return false, err
+	}
+
+	return ok, nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupFixDir(t *testing.T) string {
+	t.Helper()
+
+	return newTestDir(t, map[string]string{
+		"20250903T083109--valid.pdf": "test content",
+		"report__final.pdf":          "test content",
+		"notes.txt":                  "test content",
+	})
+}
+
+func TestFixFileNames(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupFixDir(t)
+
+	buf := &bytes.Buffer{}
+	result, err := FixFileNames(context.Background(), tmpDir, FixOptions{Writer: buf})
+	require.NoError(t, err)
+
+	require.Len(t, result.Fixed, 2)
+	assert.Empty(t, result.Skipped)
+
+	// 修正後のファイル名は正しいフォーマットで、タイムスタンプは重複しない
+	timestamps := map[string]bool{"20250903T083109": true}
+	for oldName, newName := range result.Fixed {
+		assert.NoFileExists(t, filepath.Join(tmpDir, oldName))
+		assert.FileExists(t, filepath.Join(tmpDir, newName))
+
+		components, err := parakeet.ParseFileName(newName)
+		require.NoError(t, err)
+		assert.Empty(t, components.Tags)
+		assert.False(t, timestamps[components.Timestamp], "duplicate timestamp: %s", components.Timestamp)
+		timestamps[components.Timestamp] = true
+	}
+
+	components, err := parakeet.ParseFileName(result.Fixed["report__final.pdf"])
+	require.NoError(t, err)
+	assert.Equal(t, "report_final", components.Comment)
+	assert.Equal(t, "pdf", components.Extension)
+
+	// 修正後はすべて有効
+	validation, err := ValidateFileNames(context.Background(), tmpDir, ValidateOptions{Writer: &bytes.Buffer{}})
+	require.NoError(t, err)
+	assert.Empty(t, validation.InvalidFiles)
+	assert.Contains(t, buf.String(), "Fixed: 2")
+}
+
+func TestFixFileNamesDryRun(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupFixDir(t)
+
+	buf := &bytes.Buffer{}
+	result, err := FixFileNames(context.Background(), tmpDir, FixOptions{Writer: buf, DryRun: true})
+	require.NoError(t, err)
+
+	assert.Len(t, result.Fixed, 2)
+	assert.FileExists(t, filepath.Join(tmpDir, "report__final.pdf"))
+	assert.FileExists(t, filepath.Join(tmpDir, "notes.txt"))
+	assert.Contains(t, buf.String(), "[dry-run]")
+}
+
+func TestFixFileNamesConfirm(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupFixDir(t)
+
+	var asked []string
+	opts := FixOptions{
+		Writer: &bytes.Buffer{},
+		Confirm: func(oldName, _ string) (bool, error) {
+			asked = append(asked, oldName)
+			return oldName == "notes.txt", nil
+		},
+	}
+
+	result, err := FixFileNames(context.Background(), tmpDir, opts)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"notes.txt", "report__final.pdf"}, asked)
+	assert.Len(t, result.Fixed, 1)
+	assert.Contains(t, result.Fixed, "notes.txt")
+	assert.Equal(t, []string{"report__final.pdf"}, result.Skipped)
+	assert.FileExists(t, filepath.Join(tmpDir, "report__final.pdf"))
+}
+
+func TestFixFileNamesExtensionFilter(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupFixDir(t)
+
+	result, err := FixFileNames(context.Background(), tmpDir, FixOptions{
+		Writer:        &bytes.Buffer{},
+		FilterOptions: FilterOptions{Extensions: []string{"txt"}},
+	})
+	require.NoError(t, err)
+
+	assert.Len(t, result.Fixed, 1)
+	assert.Contains(t, result.Fixed, "notes.txt")
+	assert.FileExists(t, filepath.Join(tmpDir, "report__final.pdf"))
+}
+
+func TestFixFileNamesInvalidTimestamp(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-fix-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20251399T256161--report__work_draft.pdf"), []byte("test content"), 0644))
+
+	result, err := FixFileNames(context.Background(), tmpDir, FixOptions{Writer: &bytes.Buffer{}})
+	require.NoError(t, err)
+	require.Len(t, result.Fixed, 1)
+
+	// タイムスタンプのみ振り直し、コメントとタグは残す
+	components, err := parakeet.ParseFileName(result.Fixed["20251399T256161--report__work_draft.pdf"])
+	require.NoError(t, err)
+	assert.NotEqual(t, "20251399T256161", components.Timestamp)
+	assert.NoError(t, parakeet.ValidateTimestamp(components.Timestamp))
+	assert.Equal(t, "report", components.Comment)
+	assert.Equal(t, []string{"work", "draft"}, components.Tags)
+}
+
+func TestFixFileNamesReservedSeparators(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-fix-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	for _, name := range []string{"20250903T083109--a--b__work.pdf", "20250903T083110--draft__.pdf"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("test content"), 0644))
+	}
+
+	result, err := FixFileNames(context.Background(), tmpDir, FixOptions{Writer: &bytes.Buffer{}})
+	require.NoError(t, err)
+
+	// 有効なタイムスタンプはそのまま残し、区切りのみを整える
+	assert.Equal(t, map[string]string{
+		"20250903T083109--a--b__work.pdf": "20250903T083109--a-b__work.pdf",
+		"20250903T083110--draft__.pdf":    "20250903T083110--draft.pdf",
+	}, result.Fixed)
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// FlattenOptions はサブディレクトリのファイルをまとめる操作のオプションを表す
+type FlattenOptions struct {
+	Writer io.Writer // 出力先
+	FilterOptions
+	DryRun  bool        // 実際には移動しない
+	Journal *Journal    // 実行した移動を記録するジャーナル（nil の場合は記録しない）
+	Hooks   *HookRunner // リネームの前後に実行するフック（nil の場合は実行しない）
+}
+
+// FlattenResult はサブディレクトリのファイルをまとめる操作の結果を表す
+type FlattenResult struct {
+	Moved      map[string]string // 移動したファイル: ディレクトリからの相対パス -> 新しいファイル名
+	Duplicates []string          // タイムスタンプが重複したため新しいタイムスタンプを割り当てたファイル（相対パス）
+}
+
+// FlattenFiles はサブディレクトリのフォーマット済みファイルをディレクトリ直下に移動する（archive の逆）
+// 直下や他のファイルとタイムスタンプが重複する場合は、元のタイムスタンプ以降の重複しないタイムスタンプを割り当てて警告する
+// 同じサブディレクトリで同じタイムスタンプを持つファイル（添付ファイルなど）には同じタイムスタンプを割り当てる
+// . で始まるディレクトリは対象外。すべての移動を事前に確認してから実行し、途中で失敗した場合は元に戻す
+func FlattenFiles(targetDir string, opts FlattenOptions) (*FlattenResult, error) {
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("moved", "from", "to", "dry_run")
+
+	// ディレクトリの存在チェック
+	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", targetDir)
+	}
+
+	existingTimestamps, err := CollectExistingTimestamps(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect existing timestamps: %w", err)
+	}
+
+	result := &FlattenResult{
+		Moved:      make(map[string]string),
+		Duplicates: []string{},
+	}
+
+	// サブディレクトリとタイムスタンプの組ごとに割り当てたタイムスタンプ
+	assigned := make(map[string]string)
+	// 移動先のファイル名（重複の検出用）
+	targets := make(map[string]bool)
+	plans := []renamePlan{}
+
+	err = filepath.WalkDir(targetDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != targetDir && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		dir := filepath.Dir(path)
+		if dir == filepath.Clean(targetDir) || IsShim(dir, entry) {
+			return nil
+		}
+
+		fileName := entry.Name()
+		if !opts.Matches(fileName) {
+			return nil
+		}
+
+		components, err := parakeet.ParseFileName(fileName)
+		if err != nil {
+			return nil
+		}
+
+		rel, err := filepath.Rel(targetDir, path)
+		if err != nil {
+			return err
+		}
+
+		key := filepath.Join(filepath.Dir(rel), components.Timestamp)
+		timestamp, ok := assigned[key]
+		if !ok {
+			timestamp = components.Timestamp
+			if existingTimestamps[timestamp] {
+				base, err := time.ParseInLocation(timestampLayout, timestamp, time.Local)
+				if err != nil {
+					base = time.Now()
+				}
+				timestamp = parakeet.GenerateUniqueTimestampFrom(base, existingTimestamps)
+			}
+			assigned[key] = timestamp
+			existingTimestamps[timestamp] = true
+		}
+
+		original := components.Timestamp
+		components.Timestamp = timestamp
+		newName, err := components.FormatFileNameStrict()
+		if err != nil {
+			reporter.Warnf("%s (%v, skipping)\n", filepath.ToSlash(rel), err)
+			return nil
+		}
+		if targets[newName] {
+			reporter.Warnf("multiple files would be moved to %s, skipping: %s\n", newName, filepath.ToSlash(rel))
+			return nil
+		}
+		targets[newName] = true
+
+		if timestamp != original {
+			result.Duplicates = append(result.Duplicates, filepath.ToSlash(rel))
+			reporter.Warnf("%s (duplicate timestamp: %s, assigned %s)\n", filepath.ToSlash(rel), original, timestamp)
+		}
+
+		plans = append(plans, renamePlan{From: rel, To: newName})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	prefix := ""
+	if opts.DryRun {
+		prefix = "[dry-run] "
+	}
+
+	if !opts.DryRun && len(plans) > 0 {
+		if err := applyRenames(opts.Hooks.Wrap(OSFileSystem), targetDir, plans); err != nil {
+			return nil, err
+		}
+		if err := opts.Journal.Record(targetDir, plans...); err != nil {
+			reporter.Warnf("%v\n", err)
+		}
+	}
+
+	for _, plan := range plans {
+		from := filepath.ToSlash(plan.From)
+		result.Moved[from] = plan.To
+		reporter.Emit("moved", map[string]any{"from": from, "to": plan.To, "dry_run": opts.DryRun}, "%s✓ Moved: %s → %s\n", prefix, from, plan.To)
+	}
+
+	// サマリーを出力
+	reporter.Printf("\nFlatten Summary:\n")
+	reporter.Printf("  Moved: %d\n", len(result.Moved))
+	reporter.Printf("  Duplicate timestamps: %d\n", len(result.Duplicates))
+
+	return result, nil
+}
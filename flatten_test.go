@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlattenFiles(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-flatten-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	files := []string{
+		"20250101T000000--root.md",
+		"2024/06/20240615T100000--old__go.pdf",
+		"2024/12/20250101T000000--clash.md",
+		"2024/12/20250101T000000--clash.pdf",
+		"2025/20250301T100000--new.md",
+		".trash/20230101T000000--deleted.md",
+		"2025/memo.txt",
+	}
+	for _, name := range files {
+		path := filepath.Join(tmpDir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(name), 0644))
+	}
+
+	buf := &bytes.Buffer{}
+	result, err := FlattenFiles(tmpDir, FlattenOptions{Writer: buf, Journal: NewJournal(tmpDir, "flatten")})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"2024/06/20240615T100000--old__go.pdf": "20240615T100000--old__go.pdf",
+		"2024/12/20250101T000000--clash.md":    "20250101T000001--clash.md",
+		"2024/12/20250101T000000--clash.pdf":   "20250101T000001--clash.pdf",
+		"2025/20250301T100000--new.md":         "20250301T100000--new.md",
+	}, result.Moved)
+	assert.Len(t, result.Duplicates, 2)
+	assert.Contains(t, buf.String(), "2024/12/20250101T000000--clash.md (duplicate timestamp: 20250101T000000, assigned 20250101T000001)")
+
+	assert.FileExists(t, filepath.Join(tmpDir, "20250101T000001--clash.pdf"))
+	assert.FileExists(t, filepath.Join(tmpDir, "2025", "memo.txt"))
+	assert.FileExists(t, filepath.Join(tmpDir, ".trash", "20230101T000000--deleted.md"))
+
+	// undo で元に戻せる
+	_, err = UndoLastBatch(tmpDir, UndoOptions{Writer: &bytes.Buffer{}})
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(tmpDir, "2024", "06", "20240615T100000--old__go.pdf"))
+}
+
+func TestFlattenFiles_DryRun(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-flatten-dry-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "2024"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "2024", "20240101T000000--a.md"), []byte("a"), 0644))
+
+	buf := &bytes.Buffer{}
+	result, err := FlattenFiles(tmpDir, FlattenOptions{Writer: buf, DryRun: true})
+	require.NoError(t, err)
+
+	assert.Len(t, result.Moved, 1)
+	assert.Contains(t, buf.String(), "[dry-run] ✓ Moved: 2024/20240101T000000--a.md → 20240101T000000--a.md")
+	assert.FileExists(t, filepath.Join(tmpDir, "2024", "20240101T000000--a.md"))
+}
@@ -1,70 +1,37 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
-	"time"
-)
-
-// FileNameComponents はフォーマット済みファイル名の構成要素を表す
-type FileNameComponents struct {
-	Timestamp string   // タイムスタンプ（ISO8601形式: 20250903T083109）
-	Comment   string   // 人間が読めるコメント
-	Tags      []string // タグのリスト
-	Extension string   // 拡張子
-}
-
-// GenerateTimestamp は現在時刻からタイムスタンプを生成する
-// フォーマット: YYYYMMDDTHHMMSS
-func GenerateTimestamp() string {
-	return time.Now().Format("20060102T150405")
-}
-
-// GenerateUniqueTimestamp は既存のタイムスタンプと重複しないタイムスタンプを生成する
-// existingTimestamps に既存のタイムスタンプのリストを渡す
-func GenerateUniqueTimestamp(existingTimestamps map[string]bool) string {
-	timestamp := GenerateTimestamp()
-
-	// 重複がなければそのまま返す
-	if !existingTimestamps[timestamp] {
-		return timestamp
-	}
 
-	// 重複する場合は、タイムスタンプをパースして1秒ずつ進める
-	t, err := time.Parse("20060102T150405", timestamp)
-	if err != nil {
-		// パースに失敗した場合は現在時刻を返す
-		return timestamp
-	}
-
-	// 重複しないタイムスタンプが見つかるまで1秒ずつ進める
-	for {
-		t = t.Add(1 * time.Second)
-		newTimestamp := t.Format("20060102T150405")
-		if !existingTimestamps[newTimestamp] {
-			return newTimestamp
-		}
-	}
-}
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
 
 // CollectExistingTimestamps はディレクトリ内のフォーマット済みファイルからタイムスタンプを収集する
 func CollectExistingTimestamps(dirPath string) (map[string]bool, error) {
+	return collectExistingTimestamps(OSFileSystem, dirPath)
+}
+
+// collectExistingTimestamps は fsys 上のディレクトリからタイムスタンプを収集する
+func collectExistingTimestamps(fsys FileSystem, dirPath string) (map[string]bool, error) {
 	timestamps := make(map[string]bool)
 
-	entries, err := os.ReadDir(dirPath)
+	entries, err := fsys.ReadDir(dirPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read directory: %w", err)
 	}
 
 	for _, entry := range entries {
-		if entry.IsDir() {
+		if entry.IsDir() || isShim(fsys, dirPath, entry) {
 			continue
 		}
 
 		// フォーマット済みファイルからタイムスタンプを抽出
-		if components, err := ParseFileName(entry.Name()); err == nil {
+		if components, err := parakeet.ParseFileName(entry.Name()); err == nil {
 			timestamps[components.Timestamp] = true
 		}
 	}
@@ -72,27 +39,37 @@ func CollectExistingTimestamps(dirPath string) (map[string]bool, error) {
 	return timestamps, nil
 }
 
+// AmbiguousIDError はIDに複数のファイルが一致したことを表す
+type AmbiguousIDError struct {
+	ID    string   // 指定したID
+	Files []string // 一致したファイルのパス
+}
+
+// Error はエラーメッセージを返す
+func (e *AmbiguousIDError) Error() string {
+	return fmt.Sprintf("multiple files found with ID %s:\n%s", e.ID, strings.Join(e.Files, "\n"))
+}
+
 // FindFileByID はディレクトリ内からIDに一致するファイルを検索する
-// 複数のファイルが見つかった場合はエラーを返す
+// サブディレクトリは検索しない（年・月のフォルダに整理したファイルは FindFileByIDWithDepth で検索する）
+// 完全に一致するファイルがない場合は、タイムスタンプの前方一致（20250903）、時刻の前方一致（083109）、
+// T を除いたタイムスタンプの前方一致（20250903083109）で検索し、1つに決まればそれを返す
+// 複数のファイルが見つかった場合は *AmbiguousIDError を返す
 func FindFileByID(dirPath, id string) (string, error) {
-	entries, err := os.ReadDir(dirPath)
-	if err != nil {
-		return "", fmt.Errorf("failed to read directory: %w", err)
-	}
-
-	var matchedFiles []string
+	return FindFileByIDWithDepth(dirPath, id, 0)
+}
 
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
+// FindFileByIDWithDepth は FindFileByID と同じくIDに一致するファイルを検索する
+// depth はサブディレクトリをたどる深さ（0 の場合は dirPath の直下のみ）。. で始まるディレクトリは検索しない
+func FindFileByIDWithDepth(dirPath, id string, depth int) (string, error) {
+	var exactFiles, prefixFiles []string
+	if err := collectIDMatches(dirPath, id, depth, &exactFiles, &prefixFiles); err != nil {
+		return "", err
+	}
 
-		// フォーマット済みファイルからタイムスタンプを抽出
-		if components, err := ParseFileName(entry.Name()); err == nil {
-			if components.Timestamp == id {
-				matchedFiles = append(matchedFiles, filepath.Join(dirPath, entry.Name()))
-			}
-		}
+	matchedFiles := exactFiles
+	if len(matchedFiles) == 0 {
+		matchedFiles = prefixFiles
 	}
 
 	if len(matchedFiles) == 0 {
@@ -100,99 +77,89 @@ func FindFileByID(dirPath, id string) (string, error) {
 	}
 
 	if len(matchedFiles) > 1 {
-		return "", fmt.Errorf("multiple files found with ID %s:\n%s", id, strings.Join(matchedFiles, "\n"))
+		return "", &AmbiguousIDError{ID: id, Files: matchedFiles}
 	}
 
 	return matchedFiles[0], nil
 }
 
-// FormatFileName は構成要素からフォーマット済みファイル名を生成する
-// フォーマット: {timestamp}--{comment}__{tag1}_{tag2}.{extension}
-func (c FileNameComponents) FormatFileName() string {
-	var parts []string
-
-	// タイムスタンプとコメント部分
-	parts = append(parts, fmt.Sprintf("%s--%s", c.Timestamp, c.Comment))
-
-	// タグ部分（存在する場合）
-	if len(c.Tags) > 0 {
-		parts = append(parts, strings.Join(c.Tags, "_"))
+// collectIDMatches はディレクトリとサブディレクトリから、IDに完全に一致するファイルと前方一致するファイルを集める
+func collectIDMatches(dirPath, id string, depth int, exactFiles, prefixFiles *[]string) error {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return fmt.Errorf("failed to read directory: %w", err)
 	}
 
-	// ダブルアンダースコアで結合
-	baseName := strings.Join(parts, "__")
+	for _, entry := range entries {
+		if entry.IsDir() {
+			if depth > 0 && !strings.HasPrefix(entry.Name(), ".") {
+				if err := collectIDMatches(filepath.Join(dirPath, entry.Name()), id, depth-1, exactFiles, prefixFiles); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if IsShim(dirPath, entry) {
+			continue
+		}
 
-	// 拡張子を追加
-	if c.Extension != "" {
-		return fmt.Sprintf("%s.%s", baseName, c.Extension)
+		// フォーマット済みファイルからタイムスタンプを抽出
+		components, err := parakeet.ParseFileName(entry.Name())
+		if err != nil {
+			continue
+		}
+		filePath := filepath.Join(dirPath, entry.Name())
+		if components.Timestamp == id {
+			*exactFiles = append(*exactFiles, filePath)
+		} else if matchesIDPrefix(components.Timestamp, id) {
+			*prefixFiles = append(*prefixFiles, filePath)
+		}
 	}
 
-	return baseName
+	return nil
 }
 
-// ParseFileName はフォーマット済みファイル名を構成要素にパースする
-func ParseFileName(filename string) (*FileNameComponents, error) {
-	// 拡張子を削除
-	ext := filepath.Ext(filename)
-	baseName := strings.TrimSuffix(filename, ext)
-	if ext != "" {
-		ext = ext[1:] // 先頭のドットを削除
-	}
-
-	// ダブルアンダースコアで分割
-	parts := strings.Split(baseName, "__")
-	if len(parts) < 1 {
-		return nil, fmt.Errorf("invalid filename format: %s", filename)
+// matchesIDPrefix は省略したID（タイムスタンプ・時刻・T を除いたタイムスタンプの前方一致）がタイムスタンプに一致するかどうかを返す
+func matchesIDPrefix(timestamp, id string) bool {
+	if id == "" {
+		return false
 	}
 
-	// タイムスタンプとコメントをパース（最初の部分）
-	timestampCommentParts := strings.SplitN(parts[0], "--", 2)
-	if len(timestampCommentParts) != 2 {
-		return nil, fmt.Errorf("invalid timestamp-comment format: %s", parts[0])
-	}
+	date, clock, _ := strings.Cut(timestamp, "T")
+	return strings.HasPrefix(timestamp, id) ||
+		strings.HasPrefix(clock, id) ||
+		strings.HasPrefix(date+clock, id)
+}
 
-	components := &FileNameComponents{
-		Timestamp: timestampCommentParts[0],
-		Comment:   timestampCommentParts[1],
-		Extension: ext,
-	}
+// FindFileByIDInteractive は FindFileByID と同じくIDに一致するファイルを検索する
+// 複数のファイルが一致した場合、端末から実行していれば候補から1つを選ばせる
+func FindFileByIDInteractive(dirPath, id string, depth int) (string, error) {
+	filePath, err := FindFileByIDWithDepth(dirPath, id, depth)
 
-	// タグをパース（残りの部分）
-	if len(parts) > 1 {
-		for i := 1; i < len(parts); i++ {
-			tags := strings.Split(parts[i], "_")
-			components.Tags = append(components.Tags, tags...)
-		}
+	var ambiguous *AmbiguousIDError
+	if errors.As(err, &ambiguous) && IsTerminal(os.Stdin) && IsTerminal(os.Stderr) {
+		return ChooseAmbiguousFile(ambiguous)
 	}
-
-	return components, nil
+	return filePath, err
 }
 
-// IsFormatted はファイル名が正しいフォーマットかどうかをチェックする
-func IsFormatted(filename string) bool {
-	_, err := ParseFileName(filename)
-	return err == nil
-}
-
-// MatchesExtensions はファイル名が指定された拡張子のいずれかに一致するかチェックする
-// extensions が空の場合は常に true を返す
-func MatchesExtensions(filename string, extensions []string) bool {
-	// 拡張子指定がない場合はすべて対象
-	if len(extensions) == 0 {
-		return true
+// ChooseAmbiguousFile はIDに一致した複数のファイルからインタラクティブに1つを選ぶ
+// 標準出力をコマンド置換で使えるように、候補は標準エラー出力に表示する
+func ChooseAmbiguousFile(ambiguous *AmbiguousIDError) (string, error) {
+	options := make([]string, 0, len(ambiguous.Files))
+	for _, file := range ambiguous.Files {
+		options = append(options, filepath.Base(file))
 	}
 
-	ext := filepath.Ext(filename)
-	if ext != "" {
-		ext = ext[1:] // 先頭のドットを削除
+	prompt := &survey.Select{
+		Message: fmt.Sprintf("Multiple files match ID %s. Choose one:", ambiguous.ID),
+		Options: options,
 	}
 
-	// 拡張子が一致するかチェック
-	for _, targetExt := range extensions {
-		if strings.EqualFold(ext, targetExt) {
-			return true
-		}
+	var choice int
+	if err := survey.AskOne(prompt, &choice, survey.WithStdio(os.Stdin, os.Stderr, os.Stderr)); err != nil {
+		return "", err
 	}
 
-	return false
+	return ambiguous.Files[choice], nil
 }
@@ -1,368 +1,15 @@
 package main
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
-	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-func TestGenerateTimestamp(t *testing.T) {
-	t.Parallel()
-	timestamp := GenerateTimestamp()
-
-	// Check format (YYYYMMDDTHHMMSS)
-	assert.Len(t, timestamp, 15, "Timestamp should be 15 characters")
-	assert.Contains(t, timestamp, "T", "Timestamp should contain 'T' separator")
-
-	// Check if parseable as expected format
-	_, err := time.Parse("20060102T150405", timestamp)
-	assert.NoError(t, err, "Timestamp should be parseable")
-}
-
-func TestFormatFileName(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		name       string
-		components FileNameComponents
-		expected   string
-	}{
-		{
-			name: "basic filename with extension",
-			components: FileNameComponents{
-				Timestamp: "20250903T083109",
-				Comment:   "TCPIP入門",
-				Tags:      []string{"network", "infra"},
-				Extension: "pdf",
-			},
-			expected: "20250903T083109--TCPIP入門__network_infra.pdf",
-		},
-		{
-			name: "filename without tags",
-			components: FileNameComponents{
-				Timestamp: "20250903T083109",
-				Comment:   "sample",
-				Tags:      []string{},
-				Extension: "txt",
-			},
-			expected: "20250903T083109--sample.txt",
-		},
-		{
-			name: "filename with single tag",
-			components: FileNameComponents{
-				Timestamp: "20250903T083109",
-				Comment:   "document",
-				Tags:      []string{"important"},
-				Extension: "doc",
-			},
-			expected: "20250903T083109--document__important.doc",
-		},
-		{
-			name: "filename without extension",
-			components: FileNameComponents{
-				Timestamp: "20250903T083109",
-				Comment:   "noext",
-				Tags:      []string{"tag1", "tag2"},
-				Extension: "",
-			},
-			expected: "20250903T083109--noext__tag1_tag2",
-		},
-		{
-			name: "filename with multiple tags",
-			components: FileNameComponents{
-				Timestamp: "20250101T120000",
-				Comment:   "multi-tag-file",
-				Tags:      []string{"tag1", "tag2", "tag3", "tag4"},
-				Extension: "md",
-			},
-			expected: "20250101T120000--multi-tag-file__tag1_tag2_tag3_tag4.md",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
-			result := tt.components.FormatFileName()
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
-func TestParseFileName(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		name     string
-		filename string
-		expected *FileNameComponents
-		wantErr  bool
-	}{
-		{
-			name:     "valid filename with tags",
-			filename: "20250903T083109--TCPIP入門__network_infra.pdf",
-			expected: &FileNameComponents{
-				Timestamp: "20250903T083109",
-				Comment:   "TCPIP入門",
-				Tags:      []string{"network", "infra"},
-				Extension: "pdf",
-			},
-			wantErr: false,
-		},
-		{
-			name:     "valid filename without tags",
-			filename: "20250903T083109--sample.txt",
-			expected: &FileNameComponents{
-				Timestamp: "20250903T083109",
-				Comment:   "sample",
-				Tags:      nil,
-				Extension: "txt",
-			},
-			wantErr: false,
-		},
-		{
-			name:     "valid filename with single tag",
-			filename: "20250903T083109--document__important.doc",
-			expected: &FileNameComponents{
-				Timestamp: "20250903T083109",
-				Comment:   "document",
-				Tags:      []string{"important"},
-				Extension: "doc",
-			},
-			wantErr: false,
-		},
-		{
-			name:     "valid filename without extension",
-			filename: "20250903T083109--noext__tag1_tag2",
-			expected: &FileNameComponents{
-				Timestamp: "20250903T083109",
-				Comment:   "noext",
-				Tags:      []string{"tag1", "tag2"},
-				Extension: "",
-			},
-			wantErr: false,
-		},
-		{
-			name:     "invalid filename - no double dash",
-			filename: "20250903T083109_TCPIP.pdf",
-			expected: nil,
-			wantErr:  true,
-		},
-		{
-			name:     "invalid filename - empty",
-			filename: "",
-			expected: nil,
-			wantErr:  true,
-		},
-		{
-			name:     "valid filename with multiple tag groups",
-			filename: "20250101T120000--multi__tag1_tag2__tag3_tag4.md",
-			expected: &FileNameComponents{
-				Timestamp: "20250101T120000",
-				Comment:   "multi",
-				Tags:      []string{"tag1", "tag2", "tag3", "tag4"},
-				Extension: "md",
-			},
-			wantErr: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
-			result, err := ParseFileName(tt.filename)
-
-			if tt.wantErr {
-				assert.Error(t, err)
-				assert.Nil(t, result)
-			} else {
-				require.NoError(t, err)
-				require.NotNil(t, result)
-				assert.Equal(t, tt.expected.Timestamp, result.Timestamp)
-				assert.Equal(t, tt.expected.Comment, result.Comment)
-				assert.Equal(t, tt.expected.Extension, result.Extension)
-				assert.Equal(t, tt.expected.Tags, result.Tags)
-			}
-		})
-	}
-}
-
-func TestIsFormatted(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		name     string
-		filename string
-		expected bool
-	}{
-		{
-			name:     "valid formatted filename",
-			filename: "20250903T083109--TCPIP入門__network_infra.pdf",
-			expected: true,
-		},
-		{
-			name:     "valid formatted filename without tags",
-			filename: "20250903T083109--sample.txt",
-			expected: true,
-		},
-		{
-			name:     "invalid filename",
-			filename: "regular_file.pdf",
-			expected: false,
-		},
-		{
-			name:     "invalid filename - no timestamp",
-			filename: "TCPIP入門__network.pdf",
-			expected: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
-			result := IsFormatted(tt.filename)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
-func TestFormatParseRoundTrip(t *testing.T) {
-	t.Parallel()
-	// Test that formatting and parsing are inverse operations
-	original := FileNameComponents{
-		Timestamp: "20250903T083109",
-		Comment:   "test-file",
-		Tags:      []string{"tag1", "tag2", "tag3"},
-		Extension: "pdf",
-	}
-
-	formatted := original.FormatFileName()
-	parsed, err := ParseFileName(formatted)
-
-	require.NoError(t, err)
-	assert.Equal(t, original.Timestamp, parsed.Timestamp)
-	assert.Equal(t, original.Comment, parsed.Comment)
-	assert.Equal(t, original.Extension, parsed.Extension)
-	assert.Equal(t, original.Tags, parsed.Tags)
-}
-
-func TestMatchesExtensions(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		name       string
-		filename   string
-		extensions []string
-		expected   bool
-	}{
-		{
-			name:       "no extensions filter - match all",
-			filename:   "test.txt",
-			extensions: []string{},
-			expected:   true,
-		},
-		{
-			name:       "nil extensions filter - match all",
-			filename:   "test.pdf",
-			extensions: nil,
-			expected:   true,
-		},
-		{
-			name:       "exact match",
-			filename:   "test.pdf",
-			extensions: []string{"pdf"},
-			expected:   true,
-		},
-		{
-			name:       "case insensitive match",
-			filename:   "test.PDF",
-			extensions: []string{"pdf"},
-			expected:   true,
-		},
-		{
-			name:       "multiple extensions - first match",
-			filename:   "test.txt",
-			extensions: []string{"txt", "pdf", "md"},
-			expected:   true,
-		},
-		{
-			name:       "multiple extensions - last match",
-			filename:   "test.md",
-			extensions: []string{"txt", "pdf", "md"},
-			expected:   true,
-		},
-		{
-			name:       "no match",
-			filename:   "test.jpg",
-			extensions: []string{"txt", "pdf", "md"},
-			expected:   false,
-		},
-		{
-			name:       "no extension file - match when no ext specified",
-			filename:   "test",
-			extensions: []string{},
-			expected:   true,
-		},
-		{
-			name:       "no extension file - no match when ext specified",
-			filename:   "test",
-			extensions: []string{"txt"},
-			expected:   false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
-			result := MatchesExtensions(tt.filename, tt.extensions)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
-func TestGenerateUniqueTimestamp(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		name                string
-		existingTimestamps  map[string]bool
-		shouldBeDifferent   bool
-	}{
-		{
-			name:               "no existing timestamps",
-			existingTimestamps: map[string]bool{},
-			shouldBeDifferent:  false,
-		},
-		{
-			name: "existing timestamp matches current time",
-			existingTimestamps: map[string]bool{
-				GenerateTimestamp(): true,
-			},
-			shouldBeDifferent: true,
-		},
-		{
-			name: "multiple existing timestamps",
-			existingTimestamps: map[string]bool{
-				"20250903T083109": true,
-				"20250903T083110": true,
-				"20250903T083111": true,
-			},
-			shouldBeDifferent: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
-			result := GenerateUniqueTimestamp(tt.existingTimestamps)
-
-			// 生成されたタイムスタンプが既存のものと重複しないことを確認
-			assert.False(t, tt.existingTimestamps[result], "Generated timestamp should not exist in existing timestamps")
-
-			// タイムスタンプのフォーマットを確認
-			assert.Len(t, result, 15, "Timestamp should be 15 characters")
-		})
-	}
-}
-
 func TestCollectExistingTimestamps(t *testing.T) {
 	t.Parallel()
 	// Create temporary directory
@@ -496,3 +143,84 @@ func TestFindFileByID_SkipsInvalidFiles(t *testing.T) {
 	require.NoError(t, err)
 	assert.Contains(t, foundPath, "20250903T083109--valid.txt")
 }
+
+func TestFindFileByID_Prefix(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-findbyid-prefix-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	for _, name := range []string{
+		"20250903T083109--report.pdf",
+		"20250904T083109--memo.md",
+		"20250904T120000--notes.md",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("test"), 0644))
+	}
+
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"20250903", "20250903T083109--report.pdf"},
+		{"20250904T12", "20250904T120000--notes.md"},
+		{"1200", "20250904T120000--notes.md"},
+		{"20250904120000", "20250904T120000--notes.md"},
+	}
+	for _, tt := range tests {
+		foundPath, err := FindFileByID(tmpDir, tt.id)
+		require.NoError(t, err, tt.id)
+		assert.Equal(t, filepath.Join(tmpDir, tt.want), foundPath, tt.id)
+	}
+
+	// 複数のファイルに一致する場合は候補を返す
+	_, err = FindFileByID(tmpDir, "083109")
+	var ambiguous *AmbiguousIDError
+	require.True(t, errors.As(err, &ambiguous), err)
+	assert.Equal(t, []string{
+		filepath.Join(tmpDir, "20250903T083109--report.pdf"),
+		filepath.Join(tmpDir, "20250904T083109--memo.md"),
+	}, ambiguous.Files)
+	assert.Contains(t, err.Error(), "multiple files found with ID 083109")
+
+	_, err = FindFileByID(tmpDir, "2026")
+	assert.ErrorContains(t, err, "no file found")
+}
+
+func TestFindFileByIDWithDepth(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-findbyid-depth-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	// 年・月のフォルダに整理したファイル
+	archived := filepath.Join(tmpDir, "2025", "09", "20250903T083109--report.pdf")
+	require.NoError(t, os.MkdirAll(filepath.Dir(archived), 0755))
+	require.NoError(t, os.WriteFile(archived, []byte("test"), 0644))
+
+	// . で始まるディレクトリは検索しない
+	hidden := filepath.Join(tmpDir, StateDirName, "20250904T000000--state.md")
+	require.NoError(t, os.MkdirAll(filepath.Dir(hidden), 0755))
+	require.NoError(t, os.WriteFile(hidden, []byte("test"), 0644))
+
+	// FindFileByID はサブディレクトリを検索しない
+	_, err = FindFileByID(tmpDir, "20250903T083109")
+	assert.ErrorContains(t, err, "no file found")
+
+	foundPath, err := FindFileByIDWithDepth(tmpDir, "20250903T083109", 2)
+	require.NoError(t, err)
+	assert.Equal(t, archived, foundPath)
+
+	// 省略したIDもサブディレクトリから検索する
+	foundPath, err = FindFileByIDWithDepth(tmpDir, "20250903", 2)
+	require.NoError(t, err)
+	assert.Equal(t, archived, foundPath)
+
+	_, err = FindFileByIDWithDepth(tmpDir, "20250903T083109", 1)
+	assert.ErrorContains(t, err, "no file found")
+	_, err = FindFileByIDWithDepth(tmpDir, "20250903T083109", 0)
+	assert.ErrorContains(t, err, "no file found")
+
+	_, err = FindFileByIDWithDepth(tmpDir, "20250904T000000", 2)
+	assert.ErrorContains(t, err, "no file found")
+}
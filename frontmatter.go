@@ -0,0 +1,359 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// FrontmatterExtensions は frontmatter sync の対象にする拡張子
+var FrontmatterExtensions = []string{"md", "markdown"}
+
+// frontmatterDelimiter は YAML frontmatter の開始と終了の行
+const frontmatterDelimiter = "---"
+
+// frontmatterKeys は frontmatter sync が管理するキー（追加する順）
+var frontmatterKeys = []string{"id", "title", "tags", "date"}
+
+// FrontmatterOptions は frontmatter の同期操作のオプションを表す
+type FrontmatterOptions struct {
+	Writer io.Writer // 出力先
+	FilterOptions
+	Reverse  bool        // frontmatter の title と tags からファイル名を変更する（デフォルトはファイル名から frontmatter を更新する）
+	DryRun   bool        // 実際には変更せず、実行内容のみ表示する
+	TagsFile string      // タグ定義ファイル（空の場合は tags.toml、ResolveTagsFile で解決する、--reverse のみ）
+	Journal  *Journal    // 実行したリネームを記録するジャーナル（nil の場合は記録しない、--reverse のみ）
+	Hooks    *HookRunner // リネームの前後に実行するフック（nil の場合は実行しない、--reverse のみ）
+}
+
+// FrontmatterResult は frontmatter の同期操作の結果を表す
+type FrontmatterResult struct {
+	TotalFiles int               // 対象のファイル数
+	Updated    []string          // frontmatter を更新したファイル
+	Renamed    map[string]string // リネームしたファイル（旧ファイル名 → 新ファイル名、--reverse のみ）
+}
+
+// Frontmatter は Markdown ファイルの YAML frontmatter を表す
+// parakeet が管理するキー以外の行（コメントや他のキー）はそのまま保持する
+type Frontmatter struct {
+	lines []string // 区切りの行を除いた frontmatter の行
+	found bool     // ファイルに frontmatter があったかどうか
+}
+
+// SplitFrontmatter は Markdown の内容を frontmatter と本文に分ける
+// frontmatter がない場合は空の Frontmatter と内容全体を返す
+func SplitFrontmatter(content string) (*Frontmatter, string) {
+	content = strings.TrimPrefix(content, "\ufeff")
+	firstLine, rest, found := strings.Cut(content, "\n")
+	if !found || strings.TrimRight(firstLine, "\r") != frontmatterDelimiter {
+		return &Frontmatter{}, content
+	}
+
+	lines := []string{}
+	for {
+		line, next, more := strings.Cut(rest, "\n")
+		trimmed := strings.TrimRight(line, "\r")
+		if trimmed == frontmatterDelimiter || trimmed == "..." {
+			return &Frontmatter{lines: lines, found: true}, next
+		}
+		if !more {
+			// 終了の区切りがない場合は frontmatter とみなさない
+			return &Frontmatter{}, content
+		}
+		lines = append(lines, trimmed)
+		rest = next
+	}
+}
+
+// String は区切りの行を含む frontmatter を返す（空の場合は空文字列）
+func (f *Frontmatter) String() string {
+	if !f.found && len(f.lines) == 0 {
+		return ""
+	}
+	return frontmatterDelimiter + "\n" + strings.Join(append(slices.Clone(f.lines), frontmatterDelimiter), "\n") + "\n"
+}
+
+// Get はトップレベルのキーの値の行（キーの行と、続くインデントされた行）を返す
+func (f *Frontmatter) Get(key string) (string, []string, bool) {
+	start, end := f.find(key)
+	if start < 0 {
+		return "", nil, false
+	}
+	_, value, _ := strings.Cut(f.lines[start], ":")
+	return strings.TrimSpace(value), f.lines[start+1 : end], true
+}
+
+// Set はトップレベルのキーの値を置き換える（キーがない場合は末尾に追加する）
+func (f *Frontmatter) Set(key, value string) {
+	line := key + ": " + value
+	start, end := f.find(key)
+	if start < 0 {
+		f.lines = append(f.lines, line)
+		return
+	}
+	f.lines = slices.Replace(f.lines, start, end, line)
+}
+
+// find はトップレベルのキーの行の範囲を返す（キーがない場合は -1）
+func (f *Frontmatter) find(key string) (int, int) {
+	for i, line := range f.lines {
+		name, _, ok := strings.Cut(line, ":")
+		if !ok || name != key {
+			continue
+		}
+
+		end := i + 1
+		for end < len(f.lines) && (strings.HasPrefix(f.lines[end], " ") || strings.HasPrefix(f.lines[end], "\t") || strings.HasPrefix(f.lines[end], "- ")) {
+			end++
+		}
+		return i, end
+	}
+	return -1, -1
+}
+
+// StringValue はキーの値を文字列として返す（引用符を外す）
+func (f *Frontmatter) StringValue(key string) (string, bool) {
+	value, _, ok := f.Get(key)
+	if !ok {
+		return "", false
+	}
+	return unquoteYAML(value), true
+}
+
+// Tags は tags の値（[a, b] 形式、- a 形式のリスト、または1つの値）を返す
+func (f *Frontmatter) Tags() ([]string, bool) {
+	value, block, ok := f.Get("tags")
+	if !ok {
+		return nil, false
+	}
+
+	tags := []string{}
+	switch {
+	case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+		for _, item := range strings.Split(strings.TrimSuffix(strings.TrimPrefix(value, "["), "]"), ",") {
+			if tag := unquoteYAML(strings.TrimSpace(item)); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	case value != "":
+		tags = append(tags, unquoteYAML(value))
+	default:
+		for _, line := range block {
+			item, ok := strings.CutPrefix(strings.TrimSpace(line), "- ")
+			if tag := unquoteYAML(strings.TrimSpace(item)); ok && tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags, true
+}
+
+// unquoteYAML は YAML のスカラーの引用符を外す
+func unquoteYAML(value string) string {
+	if len(value) >= 2 {
+		switch {
+		case value[0] == '"' && value[len(value)-1] == '"':
+			if s, err := strconv.Unquote(value); err == nil {
+				return s
+			}
+		case value[0] == '\'' && value[len(value)-1] == '\'':
+			return strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+		}
+	}
+	// 行末のコメントを除く
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return value
+}
+
+// quoteYAML は文字列を YAML のダブルクォートのスカラーにする（JSON の文字列は YAML としても有効）
+func quoteYAML(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// formatYAMLTags はタグを [a, b] 形式で返す
+func formatYAMLTags(tags []string) string {
+	return "[" + strings.Join(tags, ", ") + "]"
+}
+
+// SyncFrontmatter はディレクトリ内の Markdown ファイルの frontmatter（id, title, tags, date）をファイル名に合わせる
+// Reverse の場合は逆に frontmatter の title と tags からファイル名を変更する
+// 管理するキー以外の frontmatter と本文はそのまま保持し、値が変わらない場合は書き込まない
+func SyncFrontmatter(targetDir string, opts FrontmatterOptions) (*FrontmatterResult, error) {
+	// ディレクトリの存在チェック
+	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", targetDir)
+	}
+
+	entries, err := os.ReadDir(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	reporter := ReporterFor(opts.Writer)
+	if opts.Reverse {
+		reporter.Columns("renamed", "from", "to", "dry_run")
+	} else {
+		reporter.Columns("updated", "file", "dry_run")
+	}
+
+	var validator *TagValidator
+	if opts.Reverse {
+		if validator, err = NewTagValidator(ResolveTagsFile(targetDir, opts.TagsFile)); err != nil {
+			return nil, err
+		}
+	}
+
+	prefix := ""
+	if opts.DryRun {
+		prefix = "[dry-run] "
+	}
+
+	result := &FrontmatterResult{Updated: []string{}, Renamed: map[string]string{}}
+	plans := []renamePlan{}
+	for _, entry := range entries {
+		// ディレクトリとシムはスキップ
+		if entry.IsDir() || IsShim(targetDir, entry) {
+			continue
+		}
+
+		fileName := entry.Name()
+		if !parakeet.MatchesExtensions(fileName, FrontmatterExtensions) || !opts.Matches(fileName) {
+			continue
+		}
+
+		// フォーマット済みファイルのみ処理
+		components, err := parakeet.ParseFileName(fileName)
+		if err != nil {
+			continue
+		}
+		result.TotalFiles++
+
+		filePath := filepath.Join(targetDir, fileName)
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		frontmatter, body := SplitFrontmatter(string(content))
+
+		if opts.Reverse {
+			plan, err := frontmatterRenamePlan(fileName, components, frontmatter, validator)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", fileName, err)
+			}
+			if plan != nil {
+				plans = append(plans, *plan)
+			}
+			continue
+		}
+
+		if !updateFrontmatter(frontmatter, components) {
+			continue
+		}
+		if !opts.DryRun {
+			if err := rewriteFile(filePath, []byte(frontmatter.String()+body)); err != nil {
+				return nil, fmt.Errorf("failed to write file: %w", err)
+			}
+		}
+		result.Updated = append(result.Updated, fileName)
+		reporter.Emit("updated", map[string]any{"file": fileName, "dry_run": opts.DryRun}, "%s✓ Updated: %s\n", prefix, fileName)
+	}
+
+	if opts.Reverse {
+		if !opts.DryRun {
+			if err := applyRenames(opts.Hooks.Wrap(OSFileSystem), targetDir, plans); err != nil {
+				return nil, err
+			}
+			if err := opts.Journal.Record(targetDir, plans...); err != nil {
+				return nil, err
+			}
+		}
+		for _, plan := range plans {
+			result.Renamed[plan.From] = plan.To
+			reporter.Emit("renamed", map[string]any{"from": plan.From, "to": plan.To, "dry_run": opts.DryRun}, "%s✓ Renamed: %s → %s\n", prefix, plan.From, plan.To)
+		}
+	}
+
+	// サマリーを出力
+	reporter.Printf("\nFrontmatter Summary:\n")
+	reporter.Printf("  Files: %d\n", result.TotalFiles)
+	if opts.Reverse {
+		reporter.Printf("  Renamed: %d\n", len(result.Renamed))
+	} else {
+		reporter.Printf("  Updated: %d\n", len(result.Updated))
+	}
+
+	return result, nil
+}
+
+// updateFrontmatter はファイル名の要素に合わせて frontmatter の id, title, tags, date を更新し、変更したかどうかを返す
+// 既存の値が同じ意味の場合（タイトルの整形前の表記、時刻付きの日付など）は書き換えない
+func updateFrontmatter(frontmatter *Frontmatter, components *parakeet.FileNameComponents) bool {
+	changed := false
+	for _, key := range frontmatterKeys {
+		switch key {
+		case "id":
+			if value, _ := frontmatter.StringValue(key); value != components.Timestamp {
+				frontmatter.Set(key, quoteYAML(components.Timestamp))
+				changed = true
+			}
+		case "title":
+			if value, ok := frontmatter.StringValue(key); !ok || parakeet.SanitizeComment(value) != components.Comment {
+				frontmatter.Set(key, quoteYAML(components.Comment))
+				changed = true
+			}
+		case "tags":
+			tags := components.Tags
+			if tags == nil {
+				tags = []string{}
+			}
+			if value, ok := frontmatter.Tags(); !ok || !tagsEqual(value, tags) {
+				frontmatter.Set(key, formatYAMLTags(tags))
+				changed = true
+			}
+		case "date":
+			date := timestampDate(components.Timestamp)
+			if value, _ := frontmatter.StringValue(key); !strings.HasPrefix(value, date) {
+				frontmatter.Set(key, date)
+				changed = true
+			}
+		}
+	}
+
+	frontmatter.found = true
+	return changed
+}
+
+// frontmatterRenamePlan は frontmatter の title と tags からファイル名の変更を計画する（変更しない場合は nil）
+// タイムスタンプ（ID）は変更しない
+func frontmatterRenamePlan(fileName string, components *parakeet.FileNameComponents, frontmatter *Frontmatter, validator *TagValidator) (*renamePlan, error) {
+	updated := *components
+	if title, ok := frontmatter.StringValue("title"); ok && strings.TrimSpace(title) != "" {
+		updated.Comment = parakeet.SanitizeComment(strings.TrimSpace(title))
+	}
+	// タグが変わる場合のみ tags.toml で検証する
+	if tags, ok := frontmatter.Tags(); ok && !tagsEqual(tags, components.Tags) {
+		if err := validator.Validate(tags); err != nil {
+			return nil, err
+		}
+		updated.Tags = mergeTags(tags)
+	}
+
+	newName, err := updated.FormatFileNameStrict()
+	if err != nil {
+		return nil, err
+	}
+	if newName == fileName {
+		return nil, nil
+	}
+	return &renamePlan{From: fileName, To: newName}, nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitFrontmatter(t *testing.T) {
+	t.Parallel()
+
+	frontmatter, body := SplitFrontmatter("---\ntitle: a\n---\n# body\n")
+	assert.Equal(t, "# body\n", body)
+	title, ok := frontmatter.StringValue("title")
+	assert.True(t, ok)
+	assert.Equal(t, "a", title)
+
+	// frontmatter がない、または閉じていない場合は本文のみ
+	for _, content := range []string{"# body\n", "---\ntitle: a\n# body\n", ""} {
+		frontmatter, body := SplitFrontmatter(content)
+		assert.Equal(t, "", frontmatter.String(), content)
+		assert.Equal(t, content, body)
+	}
+
+	// CRLF の区切り
+	frontmatter, body = SplitFrontmatter("---\r\ntitle: 'it''s'\r\n---\r\nbody")
+	assert.Equal(t, "body", body)
+	title, _ = frontmatter.StringValue("title")
+	assert.Equal(t, "it's", title)
+}
+
+func TestFrontmatter_Tags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		content string
+		want    []string
+	}{
+		{"---\ntags: [network, \"infra\"]\n---\n", []string{"network", "infra"}},
+		{"---\ntags:\n  - network\n  - infra\nother: x\n---\n", []string{"network", "infra"}},
+		{"---\ntags:\n- network\n---\n", []string{"network"}},
+		{"---\ntags: network\n---\n", []string{"network"}},
+		{"---\ntags: []\n---\n", []string{}},
+	}
+	for _, tt := range tests {
+		frontmatter, _ := SplitFrontmatter(tt.content)
+		tags, ok := frontmatter.Tags()
+		assert.True(t, ok, tt.content)
+		assert.Equal(t, tt.want, tags, tt.content)
+	}
+
+	frontmatter, _ := SplitFrontmatter("---\ntitle: a\n---\n")
+	_, ok := frontmatter.Tags()
+	assert.False(t, ok)
+}
+
+func TestSyncFrontmatter(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-frontmatter-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	files := map[string]string{
+		// 管理しないキーと本文は残し、ブロック形式のタグは置き換える
+		"20250903T083109--tcpip__network_infra.md": "---\ntitle: Old\naliases: [tcp]\ntags:\n  - old\n---\n# TCP/IP\n",
+		// frontmatter がなければ追加する
+		"20250904T000000--memo.md": "# memo\n",
+		// 同じ意味の値は書き換えない
+		"20250905T000000--Weekly sync__work.md": "---\ntitle: \"Weekly sync\"\nid: 20250905T000000\ntags: [work]\ndate: 2025-09-05T00:00:00\n---\n",
+		// Markdown 以外は対象外
+		"20250906T000000--notes.txt": "notes\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644))
+	}
+
+	// --dry-run では書き込まない
+	result, err := SyncFrontmatter(tmpDir, FrontmatterOptions{Writer: &bytes.Buffer{}, DryRun: true})
+	require.NoError(t, err)
+	assert.Len(t, result.Updated, 2)
+	content, err := os.ReadFile(filepath.Join(tmpDir, "20250904T000000--memo.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# memo\n", string(content))
+
+	var buf bytes.Buffer
+	result, err = SyncFrontmatter(tmpDir, FrontmatterOptions{Writer: &buf})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.TotalFiles)
+	assert.Equal(t, []string{"20250903T083109--tcpip__network_infra.md", "20250904T000000--memo.md"}, result.Updated)
+	assert.Contains(t, buf.String(), "Updated: 2")
+
+	content, err = os.ReadFile(filepath.Join(tmpDir, "20250903T083109--tcpip__network_infra.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "---\ntitle: \"tcpip\"\naliases: [tcp]\ntags: [network, infra]\nid: \"20250903T083109\"\ndate: 2025-09-03\n---\n# TCP/IP\n", string(content))
+
+	content, err = os.ReadFile(filepath.Join(tmpDir, "20250904T000000--memo.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "---\nid: \"20250904T000000\"\ntitle: \"memo\"\ntags: []\ndate: 2025-09-04\n---\n# memo\n", string(content))
+
+	// 2回目は何も変更しない
+	result, err = SyncFrontmatter(tmpDir, FrontmatterOptions{Writer: &bytes.Buffer{}})
+	require.NoError(t, err)
+	assert.Empty(t, result.Updated)
+}
+
+func TestSyncFrontmatter_Reverse(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-frontmatter-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "tags.toml"), []byte("[[tag]]\nkey = \"network\"\ndesc = \"Network related\"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250904T000000--memo.md"), []byte("---\ntitle: Meeting memo\ntags: [network]\n---\n"), 0644))
+	// タグが変わらなければ未定義のタグでも検証しない
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250905T000000--notes__legacy.md"), []byte("---\ntags: [legacy]\n---\n"), 0644))
+
+	result, err := SyncFrontmatter(tmpDir, FrontmatterOptions{Writer: &bytes.Buffer{}, Reverse: true, Journal: NewJournal(tmpDir, "frontmatter sync")})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"20250904T000000--memo.md": "20250904T000000--Meeting memo__network.md"}, result.Renamed)
+	assert.FileExists(t, filepath.Join(tmpDir, "20250904T000000--Meeting memo__network.md"))
+	assert.FileExists(t, filepath.Join(tmpDir, "20250905T000000--notes__legacy.md"))
+	assert.FileExists(t, JournalPath(tmpDir))
+
+	// 定義されていないタグへの変更はエラー
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250906T000000--draft.md"), []byte("---\ntags: [unknown]\n---\n"), 0644))
+	_, err = SyncFrontmatter(tmpDir, FrontmatterOptions{Writer: &bytes.Buffer{}, Reverse: true})
+	assert.ErrorContains(t, err, "20250906T000000--draft.md")
+}
+
+func TestSyncFrontmatter_NonExistentDirectory(t *testing.T) {
+	t.Parallel()
+	_, err := SyncFrontmatter("/non/existent", FrontmatterOptions{Writer: &bytes.Buffer{}})
+	assert.ErrorContains(t, err, "directory does not exist")
+}
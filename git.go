@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// GitWorkTree は dir を含む git のワークツリーのルートを返す
+func GitWorkTree(dir string) (string, error) {
+	out, err := runGit(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("not inside a git work tree: %s", dir)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// gitFileSystem は git で管理しているファイルのリネームを git mv で行うファイルシステム
+// 管理していないファイルは元のファイルシステムでリネームする
+type gitFileSystem struct {
+	FileSystem
+	workTree string // ワークツリーのルート
+}
+
+// NewGitFileSystem は dir を含む git のワークツリーで、git mv でリネームするファイルシステムを作成する
+// git mv でリネームすると、リネームがインデックスに登録され履歴が追いやすくなる
+func NewGitFileSystem(dir string) (FileSystem, error) {
+	workTree, err := GitWorkTree(dir)
+	if err != nil {
+		return nil, err
+	}
+	return gitFileSystem{FileSystem: OSFileSystem, workTree: workTree}, nil
+}
+
+// Rename は git で管理しているファイルを git mv で、それ以外を元のファイルシステムでリネームする
+func (g gitFileSystem) Rename(oldpath, newpath string) error {
+	oldAbs, err := filepath.Abs(oldpath)
+	if err != nil {
+		return err
+	}
+	newAbs, err := filepath.Abs(newpath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := runGit(g.workTree, "ls-files", "--error-unmatch", "--", oldAbs); err != nil {
+		return g.FileSystem.Rename(oldpath, newpath)
+	}
+
+	_, err = runGit(g.workTree, "mv", "--", oldAbs, newAbs)
+	return err
+}
+
+// GitIgnoreMatcher は dir 内で .gitignore などにより無視しているファイルを除外する IgnoreMatcher を返す
+func GitIgnoreMatcher(dir string) (*IgnoreMatcher, error) {
+	out, err := runGit(dir, "ls-files", "--others", "--ignored", "--exclude-standard", "--directory", "-z")
+	if err != nil {
+		return nil, err
+	}
+
+	// git が出力する dir からの相対パス（ディレクトリは / で終わる）をそのまま一致するパターンにする
+	patterns := []string{}
+	for _, path := range strings.Split(out, "\x00") {
+		if path == "" {
+			continue
+		}
+		patterns = append(patterns, "/"+escapeIgnorePattern(path))
+	}
+
+	return ParseIgnorePatterns(patterns)
+}
+
+// GitStagedFiles は dir 内でインデックスに登録した（追加・変更・リネームした）ファイルのパスを返す
+// 削除したファイルは含めない
+func GitStagedFiles(dir string) ([]string, error) {
+	out, err := runGit(dir, "diff", "--cached", "--name-only", "--diff-filter=ACMR", "--relative", "-z")
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{}
+	for _, path := range strings.Split(out, "\x00") {
+		if path == "" {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, filepath.FromSlash(path)))
+	}
+	return paths, nil
+}
+
+// escapeIgnorePattern はパスの glob の特殊文字をエスケープする
+func escapeIgnorePattern(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		switch r {
+		case '\\', '*', '?', '[':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// runGit は dir で git コマンドを実行し、標準出力を返す
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s failed: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+	return string(out), nil
+}
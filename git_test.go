@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupGitDir は git のワークツリーを作成し、tracked をインデックスに登録する
+func setupGitDir(t *testing.T, tracked, untracked []string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	files := map[string]string{}
+	for _, name := range append(append([]string{}, tracked...), untracked...) {
+		files[name] = "test content"
+	}
+	tmpDir := newTestDir(t, files)
+
+	_, err := runGit(tmpDir, "init", "-q")
+	require.NoError(t, err)
+	for _, name := range tracked {
+		_, err := runGit(tmpDir, "add", "--", name)
+		require.NoError(t, err)
+	}
+
+	return tmpDir
+}
+
+func TestGitWorkTree(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := setupGitDir(t, nil, nil)
+	workTree, err := GitWorkTree(tmpDir)
+	require.NoError(t, err)
+
+	expected, err := filepath.EvalSymlinks(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, expected, workTree)
+}
+
+func TestGitIgnoreMatcher(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := setupGitDir(t, []string{".gitignore"}, []string{"secret[1].pdf", "build/out.pdf", "keep.pdf"})
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".gitignore"), []byte("secret*.pdf\nbuild/\n"), 0644))
+
+	matcher, err := GitIgnoreMatcher(tmpDir)
+	require.NoError(t, err)
+	assert.True(t, matcher.Match("secret[1].pdf"))
+	assert.True(t, matcher.Match("build/out.pdf"))
+	assert.False(t, matcher.Match("keep.pdf"))
+	assert.False(t, matcher.Match("secret1.pdf"))
+}
+
+func TestGenerateFileNames_Git(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := setupGitDir(t, []string{"tracked.pdf", ".gitignore"}, []string{"untracked.pdf", "ignored.pdf"})
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".gitignore"), []byte("ignored.pdf\n"), 0644))
+
+	fsys, err := NewGitFileSystem(tmpDir)
+	require.NoError(t, err)
+	ignored, err := GitIgnoreMatcher(tmpDir)
+	require.NoError(t, err)
+
+	opts := RenameOptions{
+		Writer:        &bytes.Buffer{},
+		FilterOptions: FilterOptions{Extensions: []string{"pdf"}, Exclude: ignored},
+		FS:            fsys,
+	}
+	require.NoError(t, GenerateFileNames(context.Background(), tmpDir, opts))
+
+	// 無視しているファイルはリネームしない
+	_, err = os.Stat(filepath.Join(tmpDir, "ignored.pdf"))
+	assert.NoError(t, err)
+
+	// 管理しているファイルのリネームはインデックスに登録される
+	status, err := runGit(tmpDir, "status", "--porcelain")
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(status), "\n")
+	found := false
+	for _, line := range lines {
+		if strings.HasPrefix(line, "A  ") && strings.HasSuffix(line, "--tracked.pdf") {
+			found = true
+		}
+	}
+	assert.True(t, found, status)
+	assert.NotContains(t, status, " tracked.pdf")
+
+	// 管理していないファイルも通常どおりリネームする
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.NotContains(t, names, "untracked.pdf")
+}
+
+func TestNewGitFileSystem_NotWorkTree(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	tmpDir, err := os.MkdirTemp("", "parakeet-test-nogit-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	_, err = NewGitFileSystem(tmpDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not inside a git work tree")
+}
+
+func TestGitStagedFiles(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := setupGitDir(t, []string{"20250903T083109--staged.pdf", "notes/draft.md"}, []string{"untracked.pdf"})
+
+	paths, err := GitStagedFiles(filepath.Join(tmpDir, "notes"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(tmpDir, "notes", "draft.md")}, paths)
+
+	paths, err = GitStagedFiles(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(tmpDir, "20250903T083109--staged.pdf"),
+		filepath.Join(tmpDir, "notes", "draft.md"),
+	}, paths)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// preCommitMarker は parakeet が書き出した pre-commit フックであることを示す行
+const preCommitMarker = "# Installed by parakeet hook install"
+
+// HookInstallOptions は pre-commit フックのインストールのオプションを表す
+type HookInstallOptions struct {
+	Writer  io.Writer // 出力先
+	Command string    // フックから実行する parakeet のコマンド（空の場合は parakeet）
+	Force   bool      // parakeet 以外が書き出したフックも上書きする
+}
+
+// InstallPreCommitHook は dir を含む git リポジトリに、コミットするファイルのファイル名を検証する pre-commit フックを書き出し、そのパスを返す
+// フックは parakeet --quiet validate --strict --staged <dir> を実行し、問題がある場合はコミットを中止する
+// parakeet 以外が書き出したフックがある場合は Force を指定しない限りエラーにする
+func InstallPreCommitHook(dir string, opts HookInstallOptions) (string, error) {
+	reporter := ReporterFor(opts.Writer)
+
+	workTree, err := GitWorkTree(dir)
+	if err != nil {
+		return "", err
+	}
+
+	// core.hooksPath を設定している場合はそのディレクトリに書き出す
+	out, err := runGit(dir, "rev-parse", "--git-path", "hooks/pre-commit")
+	if err != nil {
+		return "", err
+	}
+	hookPath := filepath.FromSlash(strings.TrimSpace(out))
+	if !filepath.IsAbs(hookPath) {
+		hookPath = filepath.Join(dir, hookPath)
+	}
+
+	if data, err := os.ReadFile(hookPath); err == nil && !strings.Contains(string(data), preCommitMarker) && !opts.Force {
+		return "", fmt.Errorf("pre-commit hook already exists: %s (use --force to overwrite)", hookPath)
+	}
+
+	// フックはワークツリーのルートで実行されるため、対象ディレクトリはルートからの相対パスにする
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(absDir); err == nil {
+		absDir = resolved
+	}
+	target, err := filepath.Rel(workTree, absDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve directory: %w", err)
+	}
+
+	command := opts.Command
+	if command == "" {
+		command = "parakeet"
+	}
+
+	script := fmt.Sprintf("#!/bin/sh\n%s\nexec %s --quiet validate --strict --staged %s\n",
+		preCommitMarker, command, shellQuote(filepath.ToSlash(target)))
+
+	if err := os.MkdirAll(filepath.Dir(hookPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+	if err := os.WriteFile(hookPath, []byte(script), 0755); err != nil {
+		return "", fmt.Errorf("failed to write pre-commit hook: %w", err)
+	}
+	// 既存のファイルを上書きした場合も実行できるようにする
+	if err := os.Chmod(hookPath, 0755); err != nil {
+		return "", fmt.Errorf("failed to write pre-commit hook: %w", err)
+	}
+
+	reporter.Successf("Installed pre-commit hook: %s\n", hookPath)
+	return hookPath, nil
+}
+
+// shellQuote は文字列を sh の単一引用符で囲む
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
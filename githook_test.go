@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallPreCommitHook(t *testing.T) {
+	t.Parallel()
+
+	t.Run("サブディレクトリを検証するフックを書き出す", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setupGitDir(t, nil, []string{"notes/memo.md"})
+
+		buf := &bytes.Buffer{}
+		hookPath, err := InstallPreCommitHook(filepath.Join(tmpDir, "notes"), HookInstallOptions{Writer: buf})
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(tmpDir, ".git", "hooks", "pre-commit"), hookPath)
+		assert.Contains(t, buf.String(), "Installed pre-commit hook")
+
+		data, err := os.ReadFile(hookPath)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "exec parakeet --quiet validate --strict --staged 'notes'\n")
+
+		info, err := os.Stat(hookPath)
+		require.NoError(t, err)
+		assert.NotZero(t, info.Mode().Perm()&0100)
+
+		// parakeet が書き出したフックは上書きする
+		_, err = InstallPreCommitHook(tmpDir, HookInstallOptions{Writer: &bytes.Buffer{}, Command: "/usr/local/bin/parakeet"})
+		require.NoError(t, err)
+		data, err = os.ReadFile(hookPath)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "exec /usr/local/bin/parakeet --quiet validate --strict --staged '.'\n")
+	})
+
+	t.Run("既存のフックは --force なしでは上書きしない", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setupGitDir(t, nil, nil)
+
+		hookPath := filepath.Join(tmpDir, ".git", "hooks", "pre-commit")
+		require.NoError(t, os.MkdirAll(filepath.Dir(hookPath), 0755))
+		require.NoError(t, os.WriteFile(hookPath, []byte("#!/bin/sh\nmake lint\n"), 0755))
+
+		_, err := InstallPreCommitHook(tmpDir, HookInstallOptions{Writer: &bytes.Buffer{}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "pre-commit hook already exists")
+
+		_, err = InstallPreCommitHook(tmpDir, HookInstallOptions{Writer: &bytes.Buffer{}, Force: true})
+		require.NoError(t, err)
+		data, err := os.ReadFile(hookPath)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), preCommitMarker)
+	})
+}
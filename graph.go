@@ -0,0 +1,124 @@
+package main
+
+import (
+	"io"
+	"strings"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// GraphOptions はグラフ出力操作のオプションを表す
+type GraphOptions struct {
+	Writer io.Writer // 出力先
+	FilterOptions
+}
+
+// GraphNode はグラフのノード（ファイルまたはタグ）を表す
+type GraphNode struct {
+	ID    string // ノードの識別子（ファイルはファイル名、タグは "tag:" + タグ）
+	Kind  string // "file" または "tag"
+	Label string // 表示名（ファイルはタイトル、タグはタグ名）
+}
+
+// GraphEdge はグラフの辺（タグの付与またはファイル間のリンク）を表す
+type GraphEdge struct {
+	From string // 始点のノードの識別子
+	To   string // 終点のノードの識別子
+	Kind string // "tag" または "link"
+}
+
+// Graph はファイル・タグをノード、タグの付与とファイル間のリンクを辺とするグラフを表す
+type Graph struct {
+	Nodes []GraphNode
+	Edges []GraphEdge
+}
+
+// BuildGraph はディレクトリ内のファイルとタグ、ファイル間のリンクからグラフを作成する
+// ノードと辺はファイル名の順に並べ、タグのノードは最初に出現した順に並べる
+func BuildGraph(targetDir string, filter FilterOptions) (*Graph, error) {
+	links, err := BuildLinkGraph(targetDir, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &Graph{Nodes: []GraphNode{}, Edges: []GraphEdge{}}
+	tagNodes := []GraphNode{}
+	seenTags := make(map[string]bool)
+
+	for _, fileName := range links.Files {
+		components, err := parakeet.ParseFileName(fileName)
+		if err != nil {
+			continue
+		}
+		graph.Nodes = append(graph.Nodes, GraphNode{ID: fileName, Kind: "file", Label: components.Comment})
+
+		for _, tag := range components.Tags {
+			tagID := "tag:" + tag
+			if !seenTags[tag] {
+				seenTags[tag] = true
+				tagNodes = append(tagNodes, GraphNode{ID: tagID, Kind: "tag", Label: tag})
+			}
+			graph.Edges = append(graph.Edges, GraphEdge{From: fileName, To: tagID, Kind: "tag"})
+		}
+
+		for _, target := range links.Links[fileName] {
+			graph.Edges = append(graph.Edges, GraphEdge{From: fileName, To: target, Kind: "link"})
+		}
+	}
+	graph.Nodes = append(graph.Nodes, tagNodes...)
+
+	return graph, nil
+}
+
+// ExportGraph はディレクトリのグラフを出力する
+// human と dot では Graphviz の DOT 形式で、json などではノードと辺を1件ずつのレコードで出力する
+func ExportGraph(targetDir string, opts GraphOptions) (*Graph, error) {
+	reporter := ReporterFor(opts.Writer)
+	if err := rejectTableFormat(reporter, "graph"); err != nil {
+		return nil, err
+	}
+
+	graph, err := BuildGraph(targetDir, opts.FilterOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	reporter.Dataf("digraph parakeet {\n")
+	reporter.Dataf("  rankdir=LR;\n")
+	reporter.Dataf("  node [shape=box];\n")
+
+	for _, node := range graph.Nodes {
+		attrs := "label=" + dotQuote(node.Label)
+		if node.Kind == "tag" {
+			attrs += ", shape=ellipse"
+		}
+		reporter.Emit("node", map[string]any{
+			"id":    node.ID,
+			"kind":  node.Kind,
+			"label": node.Label,
+		}, "  %s [%s];\n", dotQuote(node.ID), attrs)
+	}
+
+	for _, edge := range graph.Edges {
+		attrs := ""
+		if edge.Kind == "tag" {
+			attrs = " [style=dashed]"
+		}
+		reporter.Emit("edge", map[string]any{
+			"from": edge.From,
+			"to":   edge.To,
+			"kind": edge.Kind,
+		}, "  %s -> %s%s;\n", dotQuote(edge.From), dotQuote(edge.To), attrs)
+	}
+
+	reporter.Dataf("}\n")
+
+	return graph, nil
+}
+
+// dotQuote は DOT の識別子・ラベルとして使えるように文字列を二重引用符で囲む
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
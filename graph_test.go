@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportGraph(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-graph-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250101T000000--index__go_note.md"), []byte("see 20250102T000000\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250102T000000--say \"hi\"__go.md"), []byte("hi\n"), 0644))
+
+	buf := &bytes.Buffer{}
+	graph, err := ExportGraph(tmpDir, GraphOptions{Writer: buf})
+	require.NoError(t, err)
+
+	assert.Equal(t, []GraphNode{
+		{ID: "20250101T000000--index__go_note.md", Kind: "file", Label: "index"},
+		{ID: "20250102T000000--say \"hi\"__go.md", Kind: "file", Label: "say \"hi\""},
+		{ID: "tag:go", Kind: "tag", Label: "go"},
+		{ID: "tag:note", Kind: "tag", Label: "note"},
+	}, graph.Nodes)
+	assert.Contains(t, graph.Edges, GraphEdge{From: "20250101T000000--index__go_note.md", To: "20250102T000000--say \"hi\"__go.md", Kind: "link"})
+	assert.Len(t, graph.Edges, 4)
+
+	output := buf.String()
+	assert.Contains(t, output, "digraph parakeet {\n")
+	assert.Contains(t, output, `  "20250102T000000--say \"hi\"__go.md" [label="say \"hi\""];`)
+	assert.Contains(t, output, `  "tag:go" [label="go", shape=ellipse];`)
+	assert.Contains(t, output, `  "20250101T000000--index__go_note.md" -> "tag:note" [style=dashed];`)
+	assert.Contains(t, output, `  "20250101T000000--index__go_note.md" -> "20250102T000000--say \"hi\"__go.md";`)
+	assert.True(t, bytes.HasSuffix(buf.Bytes(), []byte("}\n")))
+}
+
+func TestExportGraph_JSON(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-graph-json-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250101T000000--a__go.pdf"), []byte("pdf"), 0644))
+
+	out := &bytes.Buffer{}
+	reporter, err := NewReporter(out, ReporterConfig{Format: FormatJSONL})
+	require.NoError(t, err)
+
+	_, err = ExportGraph(tmpDir, GraphOptions{Writer: reporter})
+	require.NoError(t, err)
+	require.NoError(t, reporter.Flush())
+
+	assert.Equal(t, `{"event":"node","id":"20250101T000000--a__go.pdf","kind":"file","label":"a"}
+{"event":"node","id":"tag:go","kind":"tag","label":"go"}
+{"event":"edge","from":"20250101T000000--a__go.pdf","kind":"tag","to":"tag:go"}
+`, out.String())
+}
+
+func TestExportGraph_Quiet(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250101T000000--a__go.pdf"), []byte("pdf"), 0644))
+
+	// --quiet でも DOT として正しい出力にする
+	buf := &bytes.Buffer{}
+	reporter, err := NewReporter(buf, ReporterConfig{Format: FormatDOT, Level: LevelQuiet})
+	require.NoError(t, err)
+	_, err = ExportGraph(tmpDir, GraphOptions{Writer: reporter})
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(buf.String(), "digraph parakeet {\n"))
+	assert.True(t, strings.HasSuffix(buf.String(), "}\n"))
+}
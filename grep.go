@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// GrepDefaultExtensions は --ext を指定しない場合に内容を検索するテキストファイルの拡張子
+var GrepDefaultExtensions = []string{"md", "txt", "org"}
+
+// binarySniffSize はバイナリかどうかを判定するために読む先頭のバイト数
+const binarySniffSize = 8000
+
+// GrepOptions は内容検索操作のオプションを表す
+type GrepOptions struct {
+	Writer io.Writer // 出力先
+	FilterOptions
+	SortOptions
+	Regex      bool // pattern を正規表現として扱う
+	IgnoreCase bool // 大文字小文字を区別しない
+}
+
+// GrepMatch はファイルの内容で一致した1行を表す
+type GrepMatch struct {
+	File  string // ファイル名
+	ID    string // タイムスタンプ（ID）
+	Title string // タイトル
+	Line  int    // 行番号（1始まり）
+	Text  string // 一致した行
+}
+
+// GrepFiles はディレクトリ内のフォーマット済みのテキストファイルの内容を検索する
+// 一致した行を「ID | タイトル | 行」の形式で出力し、そのリストを返す
+// 拡張子の指定がない場合は md, txt, org のみを対象とし、バイナリファイルはスキップする
+func GrepFiles(targetDir, pattern string, opts GrepOptions) ([]GrepMatch, error) {
+	// ディレクトリの存在チェック
+	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", targetDir)
+	}
+
+	if pattern == "" {
+		return nil, fmt.Errorf("pattern cannot be empty")
+	}
+	matchLine, err := newLineMatcher(pattern, opts.Regex, opts.IgnoreCase)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(opts.Extensions) == 0 {
+		opts.Extensions = GrepDefaultExtensions
+	}
+
+	entries, err := os.ReadDir(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("match", "id", "title", "line", "text", "file")
+
+	files := []string{}
+	for _, entry := range entries {
+		// ディレクトリとシムはスキップ
+		if entry.IsDir() || IsShim(targetDir, entry) {
+			continue
+		}
+
+		fileName := entry.Name()
+
+		// 拡張子フィルタリング
+		if !opts.Matches(fileName) {
+			continue
+		}
+
+		// フォーマット済みファイルのみ処理
+		if !parakeet.IsFormatted(fileName) {
+			continue
+		}
+
+		files = append(files, fileName)
+	}
+
+	if err := opts.SortFiles(files); err != nil {
+		return nil, err
+	}
+
+	matches := []GrepMatch{}
+	for _, fileName := range files {
+		components, err := parakeet.ParseFileName(fileName)
+		if err != nil {
+			continue
+		}
+
+		found, err := grepFile(filepath.Join(targetDir, fileName), matchLine)
+		if err != nil {
+			reporter.Warnf("Failed to read %s: %v\n", fileName, err)
+			continue
+		}
+
+		for _, m := range found {
+			m.File = fileName
+			m.ID = components.Timestamp
+			m.Title = components.Comment
+			matches = append(matches, m)
+
+			reporter.Emit("match", map[string]any{
+				"file":  m.File,
+				"id":    m.ID,
+				"title": m.Title,
+				"line":  m.Line,
+				"text":  m.Text,
+			}, "%s | %s | %s\n", m.ID, m.Title, m.Text)
+		}
+	}
+
+	return matches, nil
+}
+
+// grepFile はファイルの内容から一致した行を返す（バイナリファイルの場合は何も返さない）
+func grepFile(path string, matchLine func(string) bool) ([]GrepMatch, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	reader := bufio.NewReader(file)
+	head, err := reader.Peek(binarySniffSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+	if bytes.IndexByte(head, 0) >= 0 {
+		return nil, nil
+	}
+
+	matches := []GrepMatch{}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if matchLine(line) {
+			matches = append(matches, GrepMatch{Line: lineNumber, Text: line})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+// newLineMatcher は行の一致判定関数を作成する
+func newLineMatcher(pattern string, useRegex, ignoreCase bool) (func(string) bool, error) {
+	if useRegex {
+		if ignoreCase {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex: %w", err)
+		}
+		return re.MatchString, nil
+	}
+
+	if ignoreCase {
+		lowerPattern := strings.ToLower(pattern)
+		return func(line string) bool {
+			return strings.Contains(strings.ToLower(line), lowerPattern)
+		}, nil
+	}
+	return func(line string) bool {
+		return strings.Contains(line, pattern)
+	}, nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrepFiles(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-grep-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	files := map[string]string{
+		"20250903T083109--tcpip__network.md": "# TCP/IP\nTODO: 図を追加\r\nメモ\n",
+		"20250904T000000--memo.txt":          "todo later\n",
+		"20250905T000000--paper.pdf":         "TODO in pdf\n",
+		"20250906T000000--blob.org":          "TODO\x00binary\n",
+		"notes.md":                           "TODO unformatted\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644))
+	}
+
+	// デフォルトはテキストファイルのみ、大文字小文字を区別する
+	var buf bytes.Buffer
+	matches, err := GrepFiles(tmpDir, "TODO", GrepOptions{Writer: &buf})
+	require.NoError(t, err)
+	assert.Equal(t, []GrepMatch{
+		{File: "20250903T083109--tcpip__network.md", ID: "20250903T083109", Title: "tcpip", Line: 2, Text: "TODO: 図を追加"},
+	}, matches)
+	assert.Equal(t, "20250903T083109 | tcpip | TODO: 図を追加\n", buf.String())
+
+	// 大文字小文字を区別しない
+	matches, err = GrepFiles(tmpDir, "todo", GrepOptions{Writer: &bytes.Buffer{}, IgnoreCase: true})
+	require.NoError(t, err)
+	require.Len(t, matches, 2)
+	assert.Equal(t, "memo", matches[1].Title)
+
+	// 拡張子を指定した場合はその拡張子のみ
+	matches, err = GrepFiles(tmpDir, "TODO", GrepOptions{Writer: &bytes.Buffer{}, FilterOptions: FilterOptions{Extensions: []string{"pdf"}}})
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "paper", matches[0].Title)
+
+	// 正規表現
+	matches, err = GrepFiles(tmpDir, `^#\s+TCP`, GrepOptions{Writer: &bytes.Buffer{}, Regex: true})
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, 1, matches[0].Line)
+
+	_, err = GrepFiles(tmpDir, "(", GrepOptions{Writer: &bytes.Buffer{}, Regex: true})
+	assert.ErrorContains(t, err, "invalid regex")
+}
+
+func TestGrepFiles_NonExistentDirectory(t *testing.T) {
+	t.Parallel()
+	_, err := GrepFiles("/non/existent", "TODO", GrepOptions{Writer: &bytes.Buffer{}})
+	assert.ErrorContains(t, err, "directory does not exist")
+}
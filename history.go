@@ -0,0 +1,53 @@
+package main
+
+import (
+	"io"
+	"strings"
+)
+
+// HistoryOptions は操作履歴の表示のオプションを表す
+type HistoryOptions struct {
+	Writer  io.Writer // 出力先
+	Limit   int       // 表示する件数の上限（0 の場合は制限なし）
+	Command string    // このコマンドの記録のみ表示する（tag は tag add, tag rm なども含む。空の場合は制限なし）
+}
+
+// ShowHistory はディレクトリのジャーナルに記録されたリネームを新しい順に表示する
+func ShowHistory(dirPath string, opts HistoryOptions) ([]JournalEntry, error) {
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("entry", "time", "command", "dir", "from", "to")
+
+	entries, err := ReadJournal(JournalPath(dirPath))
+	if err != nil {
+		return nil, err
+	}
+
+	history := []JournalEntry{}
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if opts.Command != "" && entry.Command != opts.Command && !strings.HasPrefix(entry.Command, opts.Command+" ") {
+			continue
+		}
+		if opts.Limit > 0 && len(history) >= opts.Limit {
+			break
+		}
+		history = append(history, entry)
+	}
+
+	if len(history) == 0 {
+		reporter.Printf("No operations recorded\n")
+		return history, nil
+	}
+
+	for _, entry := range history {
+		reporter.Emit("entry", map[string]any{
+			"time":    entry.Time,
+			"command": entry.Command,
+			"dir":     entry.Dir,
+			"from":    entry.From,
+			"to":      entry.To,
+		}, "%s  %-10s  %s → %s\n", entry.Time, entry.Command, entry.From, entry.To)
+	}
+
+	return history, nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournal_Record(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-journal-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	// nil の場合は記録しない
+	var none *Journal
+	require.NoError(t, none.Record(tmpDir, renamePlan{From: "a.pdf", To: "b.pdf"}))
+	assert.NoDirExists(t, filepath.Join(tmpDir, StateDirName))
+
+	journal := NewJournal(tmpDir, "fix")
+	require.NoError(t, journal.Record(tmpDir, renamePlan{From: "a.pdf", To: "b.pdf"}))
+	require.NoError(t, journal.Record(tmpDir, renamePlan{From: "c.pdf", To: "d.pdf"}))
+
+	entries, err := ReadJournal(JournalPath(tmpDir))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "fix", entries[0].Command)
+	assert.Equal(t, "a.pdf", entries[0].From)
+	assert.Equal(t, "d.pdf", entries[1].To)
+
+	// 存在しないジャーナルは空
+	entries, err = ReadJournal(filepath.Join(tmpDir, "missing.jsonl"))
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	brokenPath := filepath.Join(tmpDir, "broken.jsonl")
+	require.NoError(t, os.WriteFile(brokenPath, []byte("{}\n{\n"), 0644))
+	_, err = ReadJournal(brokenPath)
+	assert.ErrorContains(t, err, "failed to parse journal line 2")
+}
+
+func TestShowHistory(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-history-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	buf := &bytes.Buffer{}
+	_, err = ShowHistory(tmpDir, HistoryOptions{Writer: buf})
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "No operations recorded")
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "report.pdf"), []byte("test"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250903T083109--notes.pdf"), []byte("test"), 0644))
+
+	require.NoError(t, GenerateFileNames(context.Background(), tmpDir, RenameOptions{
+		Writer:        &bytes.Buffer{},
+		FilterOptions: FilterOptions{Extensions: []string{"pdf"}},
+		Journal:       NewJournal(tmpDir, "generate"),
+	}))
+	require.NoError(t, SetTags(filepath.Join(tmpDir, "20250903T083109--notes.pdf"), []string{"work"}, TagOptions{
+		Writer:  &bytes.Buffer{},
+		Journal: NewJournal(tmpDir, "tag"),
+	}))
+	_, err = AddTag(tmpDir, "todo", TagBulkOptions{
+		Writer:  &bytes.Buffer{},
+		IDs:     []string{"20250903T083109"},
+		Journal: NewJournal(tmpDir, "tag add"),
+	})
+	require.NoError(t, err)
+
+	// 新しい順に表示する
+	buf = &bytes.Buffer{}
+	history, err := ShowHistory(tmpDir, HistoryOptions{Writer: buf})
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, "tag add", history[0].Command)
+	assert.Equal(t, "20250903T083109--notes__todo_work.pdf", history[0].To)
+	assert.Equal(t, "tag", history[1].Command)
+	assert.Equal(t, "generate", history[2].Command)
+	assert.Equal(t, "report.pdf", history[2].From)
+	assert.Contains(t, buf.String(), "20250903T083109--notes.pdf → 20250903T083109--notes__work.pdf")
+
+	// tag は tag add も含む
+	history, err = ShowHistory(tmpDir, HistoryOptions{Writer: &bytes.Buffer{}, Command: "tag"})
+	require.NoError(t, err)
+	assert.Len(t, history, 2)
+
+	history, err = ShowHistory(tmpDir, HistoryOptions{Writer: &bytes.Buffer{}, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "tag add", history[0].Command)
+}
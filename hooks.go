@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// HooksDirName はフックの実行ファイルを置くディレクトリの名前（StateDirName 内に置く）
+const HooksDirName = "hooks"
+
+// フックの種類（.parakeet/hooks/ の実行ファイル名）
+const (
+	HookPreRename  = "pre-rename"  // リネームの前に実行する。失敗した場合はリネームしない
+	HookPostRename = "post-rename" // リネームの後に実行する。失敗してもリネームは取り消さない
+)
+
+// HookConfig は parakeet.toml で設定するフックのシェルコマンドを表す
+type HookConfig struct {
+	PreRename  []string `toml:"pre_rename"`  // リネームの前に実行するコマンド
+	PostRename []string `toml:"post_rename"` // リネームの後に実行するコマンド
+}
+
+// HookRunner はリネームの前後にフックを実行する
+// フックは .parakeet/hooks/pre-rename, post-rename の実行ファイルと、parakeet.toml の [hooks] のシェルコマンド
+// リネームするファイルのディレクトリから親をたどって、最初に .parakeet/hooks か parakeet.toml があるディレクトリのフックを使う
+// フックには PARAKEET_HOOK, PARAKEET_OLD_PATH, PARAKEET_NEW_PATH, PARAKEET_DIR を環境変数で渡し、そのディレクトリで実行する
+// 途中で失敗したリネームを元に戻すときもフックを実行する（post-rename で外部のデータベースなどを元に戻せるようにする）
+type HookRunner struct {
+	Output io.Writer // フックの標準出力・標準エラー出力と、post-rename の失敗の出力先
+
+	mu    sync.Mutex
+	cache map[string]*hookSet // ディレクトリ -> そのディレクトリに適用するフック
+}
+
+// hookSet はディレクトリに適用するフックを表す
+type hookSet struct {
+	root  string              // フックを設定したディレクトリ
+	hooks map[string][]string // フックの種類 -> 実行するコマンド（実行ファイルは絶対パス、それ以外はシェルコマンド）
+	files map[string]bool     // 実行ファイルとして直接実行するコマンド
+}
+
+// NewHookRunner はフックの出力を output に書き出す HookRunner を作成する
+func NewHookRunner(output io.Writer) *HookRunner {
+	return &HookRunner{Output: output, cache: make(map[string]*hookSet)}
+}
+
+// hookRunnerKey はコンテキストに HookRunner を格納するキー
+type hookRunnerKey struct{}
+
+// WithHookRunner はフックを実行する HookRunner をコンテキストに格納する
+// main で --hooks が指定された場合のみ格納し、各コマンドはオプションの Hooks に渡す
+func WithHookRunner(ctx context.Context, runner *HookRunner) context.Context {
+	return context.WithValue(ctx, hookRunnerKey{}, runner)
+}
+
+// HookRunnerFromContext はコンテキストの HookRunner を返す（格納されていない場合は nil で、フックを実行しない）
+func HookRunnerFromContext(ctx context.Context) *HookRunner {
+	runner, _ := ctx.Value(hookRunnerKey{}).(*HookRunner)
+	return runner
+}
+
+// Wrap は fsys のリネームの前後にフックを実行する FileSystem を返す（r が nil の場合は fsys をそのまま返す）
+func (r *HookRunner) Wrap(fsys FileSystem) FileSystem {
+	if r == nil {
+		return fsys
+	}
+	return hookedFileSystem{FileSystem: fsys, hooks: r}
+}
+
+// RenameFile は RenameFile でリネームし、前後にフックを実行する（r が nil の場合はフックを実行しない）
+func (r *HookRunner) RenameFile(oldpath, newpath string) error {
+	return r.Wrap(OSFileSystem).Rename(oldpath, newpath)
+}
+
+// hookedFileSystem はリネームの前後にフックを実行する FileSystem
+type hookedFileSystem struct {
+	FileSystem
+	hooks *HookRunner
+}
+
+// Rename は pre-rename が成功した場合のみリネームし、リネームの後に post-rename を実行する
+func (h hookedFileSystem) Rename(oldpath, newpath string) error {
+	if err := h.hooks.Run(HookPreRename, oldpath, newpath); err != nil {
+		return err
+	}
+	if err := h.FileSystem.Rename(oldpath, newpath); err != nil {
+		return err
+	}
+	return h.hooks.Run(HookPostRename, oldpath, newpath)
+}
+
+// Run は oldPath から newPath へのリネームの hook を実行する
+// pre-rename が失敗した場合はエラーを返し、post-rename の失敗は Output に出力するだけにする
+func (r *HookRunner) Run(hook, oldPath, newPath string) error {
+	if r == nil {
+		return nil
+	}
+
+	oldAbs, err := filepath.Abs(oldPath)
+	if err != nil {
+		return err
+	}
+	newAbs, err := filepath.Abs(newPath)
+	if err != nil {
+		return err
+	}
+
+	set, err := r.hooksFor(filepath.Dir(oldAbs))
+	if err != nil {
+		return err
+	}
+	if set == nil {
+		return nil
+	}
+
+	for _, command := range set.hooks[hook] {
+		var cmd *exec.Cmd
+		if set.files[command] {
+			cmd = exec.Command(command)
+		} else if runtime.GOOS == "windows" {
+			cmd = exec.Command("cmd", "/c", command)
+		} else {
+			cmd = exec.Command("sh", "-c", command)
+		}
+		cmd.Dir = set.root
+		cmd.Env = append(os.Environ(),
+			"PARAKEET_HOOK="+hook,
+			"PARAKEET_OLD_PATH="+oldAbs,
+			"PARAKEET_NEW_PATH="+newAbs,
+			"PARAKEET_DIR="+set.root,
+		)
+		if r.Output != nil {
+			cmd.Stdout = r.Output
+			cmd.Stderr = r.Output
+		}
+
+		if err := cmd.Run(); err != nil {
+			if hook == HookPreRename {
+				return fmt.Errorf("%s hook failed: %s: %w", hook, command, err)
+			}
+			if r.Output != nil {
+				_, _ = fmt.Fprintf(r.Output, "%s hook failed: %s: %v\n", hook, command, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// hooksFor は dir のファイルのリネームに適用するフックを返す（フックがない場合は nil）
+func (r *HookRunner) hooksFor(dir string) (*hookSet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cache == nil {
+		r.cache = make(map[string]*hookSet)
+	}
+	if set, ok := r.cache[dir]; ok {
+		return set, nil
+	}
+
+	var set *hookSet
+	for current := dir; ; current = filepath.Dir(current) {
+		found, err := loadHookSet(current)
+		if err != nil {
+			return nil, err
+		}
+		if found != nil {
+			set = found
+			break
+		}
+		if filepath.Dir(current) == current {
+			break
+		}
+	}
+
+	r.cache[dir] = set
+	return set, nil
+}
+
+// loadHookSet は root の .parakeet/hooks と parakeet.toml からフックを読み込む
+// どちらもない場合は nil を返す
+func loadHookSet(root string) (*hookSet, error) {
+	hooksDir := filepath.Join(root, StateDirName, HooksDirName)
+	configPath := filepath.Join(root, ConfigFileName)
+
+	_, dirErr := os.Stat(hooksDir)
+	_, configErr := os.Stat(configPath)
+	if dirErr != nil && configErr != nil {
+		return nil, nil
+	}
+
+	set := &hookSet{
+		root:  root,
+		hooks: make(map[string][]string),
+		files: make(map[string]bool),
+	}
+
+	for _, hook := range []string{HookPreRename, HookPostRename} {
+		path := filepath.Join(hooksDir, hook)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			set.hooks[hook] = append(set.hooks[hook], path)
+			set.files[path] = true
+		}
+	}
+
+	if configErr == nil {
+		config, err := LoadConfig(configPath)
+		if err != nil {
+			return nil, err
+		}
+		set.hooks[HookPreRename] = append(set.hooks[HookPreRename], config.Hooks.PreRename...)
+		set.hooks[HookPostRename] = append(set.hooks[HookPostRename], config.Hooks.PostRename...)
+	}
+
+	return set, nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupHooksDir は .parakeet/hooks/ にフックのスクリプトを置いたディレクトリを作成する
+func setupHooksDir(t *testing.T, hooks map[string]string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hook scripts require sh")
+	}
+
+	tmpDir := t.TempDir()
+	hooksDir := filepath.Join(tmpDir, StateDirName, HooksDirName)
+	require.NoError(t, os.MkdirAll(hooksDir, 0755))
+	for name, script := range hooks {
+		require.NoError(t, os.WriteFile(filepath.Join(hooksDir, name), []byte("#!/bin/sh\n"+script+"\n"), 0755))
+	}
+
+	return tmpDir
+}
+
+func TestHookRunner_Run(t *testing.T) {
+	t.Parallel()
+
+	t.Run("環境変数でパスを渡す", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setupHooksDir(t, map[string]string{
+			HookPreRename:  `echo "$PARAKEET_HOOK $(basename "$PARAKEET_OLD_PATH") $(basename "$PARAKEET_NEW_PATH")" >> "$PARAKEET_DIR/hooks.log"`,
+			HookPostRename: `echo "$PARAKEET_HOOK $(basename "$PARAKEET_OLD_PATH") $(basename "$PARAKEET_NEW_PATH")" >> hooks.log`,
+		})
+		subDir := filepath.Join(tmpDir, "2025")
+		require.NoError(t, os.MkdirAll(subDir, 0755))
+
+		runner := NewHookRunner(&bytes.Buffer{})
+		oldPath := filepath.Join(subDir, "a.pdf")
+		newPath := filepath.Join(subDir, "20250903T083109--a.pdf")
+		require.NoError(t, runner.Run(HookPreRename, oldPath, newPath))
+		require.NoError(t, runner.Run(HookPostRename, oldPath, newPath))
+
+		log, err := os.ReadFile(filepath.Join(tmpDir, "hooks.log"))
+		require.NoError(t, err)
+		assert.Equal(t, "pre-rename a.pdf 20250903T083109--a.pdf\npost-rename a.pdf 20250903T083109--a.pdf\n", string(log))
+	})
+
+	t.Run("pre-rename の失敗はエラー、post-rename の失敗は出力のみ", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setupHooksDir(t, map[string]string{
+			HookPreRename:  "exit 1",
+			HookPostRename: "exit 2",
+		})
+
+		buf := &bytes.Buffer{}
+		runner := NewHookRunner(buf)
+		oldPath := filepath.Join(tmpDir, "a.pdf")
+		newPath := filepath.Join(tmpDir, "b.pdf")
+
+		err := runner.Run(HookPreRename, oldPath, newPath)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "pre-rename hook failed")
+
+		require.NoError(t, runner.Run(HookPostRename, oldPath, newPath))
+		assert.Contains(t, buf.String(), "post-rename hook failed")
+	})
+
+	t.Run("parakeet.toml のシェルコマンド", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setupHooksDir(t, nil)
+		config := "[hooks]\npost_rename = [\"echo \\\"$PARAKEET_HOOK\\\" > config-hook.log\"]\n"
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ConfigFileName), []byte(config), 0644))
+
+		runner := NewHookRunner(&bytes.Buffer{})
+		require.NoError(t, runner.Run(HookPostRename, filepath.Join(tmpDir, "a.pdf"), filepath.Join(tmpDir, "b.pdf")))
+
+		log, err := os.ReadFile(filepath.Join(tmpDir, "config-hook.log"))
+		require.NoError(t, err)
+		assert.Equal(t, "post-rename\n", string(log))
+	})
+
+	t.Run("nil の場合は何もしない", func(t *testing.T) {
+		t.Parallel()
+		var runner *HookRunner
+		assert.NoError(t, runner.Run(HookPreRename, "a.pdf", "b.pdf"))
+	})
+}
+
+func TestHookRunner_Options(t *testing.T) {
+	t.Parallel()
+
+	script := `echo "$PARAKEET_HOOK $(basename "$PARAKEET_OLD_PATH")" >> "$PARAKEET_DIR/hooks.log"`
+	setup := func(t *testing.T) string {
+		t.Helper()
+		tmpDir := setupHooksDir(t, map[string]string{HookPreRename: script, HookPostRename: script})
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "a.pdf"), []byte("a"), 0644))
+		return tmpDir
+	}
+
+	t.Run("オプションで渡した場合のみ実行する", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setup(t)
+
+		require.NoError(t, GenerateFileNames(context.Background(), tmpDir, RenameOptions{
+			Writer:        &bytes.Buffer{},
+			FilterOptions: FilterOptions{Extensions: []string{"pdf"}},
+			Hooks:         NewHookRunner(&bytes.Buffer{}),
+		}))
+
+		log, err := os.ReadFile(filepath.Join(tmpDir, "hooks.log"))
+		require.NoError(t, err)
+		assert.Equal(t, "pre-rename a.pdf\npost-rename a.pdf\n", string(log))
+	})
+
+	t.Run("デフォルトでは実行しない", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setup(t)
+
+		require.NoError(t, GenerateFileNames(context.Background(), tmpDir, RenameOptions{
+			Writer:        &bytes.Buffer{},
+			FilterOptions: FilterOptions{Extensions: []string{"pdf"}},
+			Hooks:         HookRunnerFromContext(context.Background()),
+		}))
+		assert.NoFileExists(t, filepath.Join(tmpDir, "hooks.log"))
+	})
+
+	t.Run("pre-rename が失敗した場合はリネームしない", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setupHooksDir(t, map[string]string{HookPreRename: "exit 1"})
+		oldPath := filepath.Join(tmpDir, "20250903T083109--a.pdf")
+		require.NoError(t, os.WriteFile(oldPath, []byte("a"), 0644))
+
+		_, err := RetimeFile(oldPath, RetimeOptions{Writer: &bytes.Buffer{}, To: "20250101T090000", Hooks: NewHookRunner(&bytes.Buffer{})})
+		require.Error(t, err)
+		assert.FileExists(t, oldPath)
+	})
+}
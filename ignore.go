@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFileName は処理対象から除外するファイルのパターンを書くファイルの名前（.gitignore と同じ書式）
+const IgnoreFileName = ".parakeetignore"
+
+// ignoreRule は除外パターンの1行を表す
+type ignoreRule struct {
+	pattern *regexp.Regexp
+	negate  bool // ! で始まる（除外しない）
+	dirOnly bool // / で終わる（ディレクトリのみ一致）
+}
+
+// IgnoreMatcher は .gitignore と同じ書式のパターンでファイルを除外する
+// nil の場合は何も除外しない
+type IgnoreMatcher struct {
+	rules []ignoreRule
+}
+
+// ParseIgnorePatterns は .gitignore と同じ書式のパターンを解釈する
+// 空行と # で始まる行は無視し、後に書いたパターンほど優先する
+func ParseIgnorePatterns(patterns []string) (*IgnoreMatcher, error) {
+	matcher := &IgnoreMatcher{}
+	for _, line := range patterns {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\#`) || strings.HasPrefix(line, `\!`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		// 途中に / を含むパターンはディレクトリからの相対パスに、含まないパターンはどの階層の名前にも一致する
+		anchored := strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+
+		expr := globToRegexp(line)
+		if !anchored {
+			expr = "(.*/)?" + expr
+		}
+		pattern, err := regexp.Compile("^" + expr + "$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignore pattern: %s", line)
+		}
+		rule.pattern = pattern
+		matcher.rules = append(matcher.rules, rule)
+	}
+
+	return matcher, nil
+}
+
+// LoadIgnoreFile は除外パターンのファイルを読み込む
+// ファイルが存在しない場合は何も除外しない IgnoreMatcher を返す
+func LoadIgnoreFile(path string) (*IgnoreMatcher, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &IgnoreMatcher{}, nil
+		}
+		return nil, fmt.Errorf("failed to open ignore file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	lines := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ignore file: %w", err)
+	}
+
+	matcher, err := ParseIgnorePatterns(lines)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return matcher, nil
+}
+
+// Append は other のパターンを後に加えた IgnoreMatcher を返す（other のパターンが優先する）
+func (m *IgnoreMatcher) Append(other *IgnoreMatcher) *IgnoreMatcher {
+	merged := &IgnoreMatcher{}
+	if m != nil {
+		merged.rules = append(merged.rules, m.rules...)
+	}
+	if other != nil {
+		merged.rules = append(merged.rules, other.rules...)
+	}
+	return merged
+}
+
+// Match はディレクトリからの相対パスのファイルを除外するかどうかを返す
+// .gitignore と同じく、除外したディレクトリ内のファイルは ! のパターンでも対象に戻らない
+func (m *IgnoreMatcher) Match(relPath string) bool {
+	if m == nil || len(m.rules) == 0 {
+		return false
+	}
+
+	relPath = filepath.ToSlash(relPath)
+	parts := strings.Split(relPath, "/")
+	for i := 1; i < len(parts); i++ {
+		if m.match(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return m.match(relPath, false)
+}
+
+// match は最後に一致したパターンで除外するかどうかを決める
+func (m *IgnoreMatcher) match(relPath string, isDir bool) bool {
+	ignored := false
+	for _, rule := range m.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.pattern.MatchString(relPath) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// globToRegexp は glob パターンを正規表現に変換する
+// * と ? は / に一致せず、** はディレクトリをまたいで一致する
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnoreMatcher_Match(t *testing.T) {
+	t.Parallel()
+
+	matcher, err := ParseIgnorePatterns([]string{
+		"# メタデータ",
+		"tag.toml",
+		"*.tmp",
+		"!keep.tmp",
+		"/README.md",
+		"drafts/",
+		"docs/**/*.bak",
+		"[Tt]humbs.db",
+		"",
+		`\#notes.txt`,
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"tag.toml", true},
+		{"sub/tag.toml", true},
+		{"download.tmp", true},
+		{"keep.tmp", false},
+		{"README.md", true},
+		{"sub/README.md", false},
+		{"drafts/a.pdf", true},
+		{"drafts", false},
+		{"docs/a/b/old.bak", true},
+		{"docs/old.bak", true},
+		{"old.bak", false},
+		{"thumbs.db", true},
+		{"Thumbs.db", true},
+		{"#notes.txt", true},
+		{"20250903T083109--report.pdf", false},
+		{"日本語.tmp", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matcher.Match(tt.path), tt.path)
+	}
+
+	// nil の場合は除外しない
+	var none *IgnoreMatcher
+	assert.False(t, none.Match("tag.toml"))
+}
+
+func TestIgnoreMatcher_Append(t *testing.T) {
+	t.Parallel()
+
+	file, err := ParseIgnorePatterns([]string{"*.md"})
+	require.NoError(t, err)
+	flags, err := ParseIgnorePatterns([]string{"!index.md"})
+	require.NoError(t, err)
+
+	merged := file.Append(flags)
+	assert.True(t, merged.Match("README.md"))
+	assert.False(t, merged.Match("index.md"))
+}
+
+func TestLoadIgnoreFile(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-ignore-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	// 存在しない場合は何も除外しない
+	matcher, err := LoadIgnoreFile(filepath.Join(tmpDir, IgnoreFileName))
+	require.NoError(t, err)
+	assert.False(t, matcher.Match("tag.toml"))
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, IgnoreFileName), []byte("tag.toml\nREADME.md\n*.tmp\n"), 0644))
+	matcher, err = LoadIgnoreFile(filepath.Join(tmpDir, IgnoreFileName))
+	require.NoError(t, err)
+	assert.True(t, matcher.Match("tag.toml"))
+	assert.True(t, matcher.Match("a.tmp"))
+}
+
+func TestValidateFileNames_Exclude(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-ignore-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	for _, name := range []string{"20250903T083109--report.pdf", "tag.toml", "README.md", "download.tmp", "invalid.pdf"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("test"), 0644))
+	}
+
+	exclude, err := ParseIgnorePatterns([]string{"tag.toml", "README.md", "*.tmp"})
+	require.NoError(t, err)
+
+	result, err := ValidateFileNames(context.Background(), tmpDir, ValidateOptions{
+		Writer:        &bytes.Buffer{},
+		FilterOptions: FilterOptions{Exclude: exclude},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"invalid.pdf"}, result.InvalidFiles)
+	assert.Equal(t, 2, result.TotalFiles)
+}
+
+func TestGenerateFileNames_Exclude(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-ignore-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "README.md"), []byte("test"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "notes.md"), []byte("test"), 0644))
+
+	exclude, err := ParseIgnorePatterns([]string{"README.md"})
+	require.NoError(t, err)
+
+	require.NoError(t, GenerateFileNames(context.Background(), tmpDir, RenameOptions{
+		Writer:        &bytes.Buffer{},
+		FilterOptions: FilterOptions{Extensions: []string{"md"}, Exclude: exclude},
+	}))
+	assert.FileExists(t, filepath.Join(tmpDir, "README.md"))
+	assert.NoFileExists(t, filepath.Join(tmpDir, "notes.md"))
+}
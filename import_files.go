@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// ImportFilesOptions は外部のファイルを管理ディレクトリに取り込む操作のオプションを表す
+type ImportFilesOptions struct {
+	Writer    io.Writer                 // 出力先
+	Comment   string                    // すべてのファイルに付けるコメント（空の場合は元のファイル名から作る）
+	Tags      []string                  // 追加するタグ
+	Move      bool                      // コピーの代わりに移動する
+	DryRun    bool                      // 実際には取り込まない
+	TagsFile  string                    // タグ定義ファイル（空の場合は tags.toml、ResolveTagsFile で解決する）
+	Sanitizer parakeet.CommentSanitizer // 元のファイル名からコメントを作るときのルール
+	Hooks     *HookRunner               // リネームの前後に実行するフック（nil の場合は実行しない）
+}
+
+// ImportFilesResult は外部のファイルを取り込む操作の結果を表す
+type ImportFilesResult struct {
+	Imported map[string]string // 取り込んだファイル: 元のパス -> 新しいファイル名
+}
+
+// ImportFiles は管理ディレクトリの外にあるファイルをフォーマット済みのファイル名でディレクトリにコピー（Move の場合は移動）する
+// フォーマット済みのファイル名はタイムスタンプ・コメント・タグを引き継ぎ、それ以外は現在時刻のタイムスタンプと元のファイル名のコメントを使う
+// タイムスタンプがディレクトリ内のファイルと重複する場合は重複しないタイムスタンプを割り当てる
+// すべてのファイルを事前に確認してから取り込む
+func ImportFiles(sources []string, targetDir string, opts ImportFilesOptions) (*ImportFilesResult, error) {
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("imported", "from", "to", "moved", "dry_run")
+
+	// ディレクトリの存在チェック
+	if info, err := os.Stat(targetDir); os.IsNotExist(err) || (err == nil && !info.IsDir()) {
+		return nil, fmt.Errorf("directory does not exist: %s", targetDir)
+	}
+
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no files to import")
+	}
+
+	if len(opts.Tags) > 0 {
+		if err := ValidateTags(opts.Tags, ResolveTagsFile(targetDir, opts.TagsFile)); err != nil {
+			return nil, err
+		}
+	}
+
+	existingTimestamps, err := CollectExistingTimestamps(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect existing timestamps: %w", err)
+	}
+
+	comment := ""
+	if strings.TrimSpace(opts.Comment) != "" {
+		comment = parakeet.SanitizeComment(strings.TrimSpace(opts.Comment))
+	}
+
+	plans := []renamePlan{}
+	targets := make(map[string]bool)
+	for _, src := range sources {
+		info, err := os.Stat(src)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, fmt.Errorf("file does not exist: %s", src)
+			}
+			return nil, fmt.Errorf("failed to access file: %w", err)
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("cannot import directory: %s", src)
+		}
+
+		components := importComponents(filepath.Base(src), existingTimestamps, opts.Sanitizer)
+		if comment != "" {
+			components.Comment = comment
+		}
+		components.Tags = mergeTags(components.Tags, opts.Tags)
+
+		newName, err := components.FormatFileNameStrict()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", src, err)
+		}
+		if targets[newName] {
+			return nil, fmt.Errorf("multiple files would be imported as: %s", newName)
+		}
+		if _, err := os.Lstat(filepath.Join(targetDir, newName)); err == nil {
+			return nil, fmt.Errorf("target file already exists: %s", newName)
+		}
+		targets[newName] = true
+		existingTimestamps[components.Timestamp] = true
+
+		plans = append(plans, renamePlan{From: src, To: newName})
+	}
+
+	result := &ImportFilesResult{
+		Imported: make(map[string]string),
+	}
+
+	prefix := ""
+	if opts.DryRun {
+		prefix = "[dry-run] "
+	}
+
+	for _, plan := range plans {
+		if !opts.DryRun {
+			dst := filepath.Join(targetDir, plan.To)
+			if opts.Move {
+				if err := opts.Hooks.RenameFile(plan.From, dst); err != nil {
+					return result, fmt.Errorf("failed to move file: %w", err)
+				}
+			} else if err := copyFile(plan.From, dst); err != nil {
+				return result, err
+			}
+		}
+
+		result.Imported[plan.From] = plan.To
+		reporter.Emit("imported", map[string]any{"from": plan.From, "to": plan.To, "moved": opts.Move, "dry_run": opts.DryRun}, "%s✓ Imported: %s → %s\n", prefix, plan.From, plan.To)
+	}
+
+	// サマリーを出力
+	reporter.Printf("\nImport Summary:\n")
+	reporter.Printf("  Imported: %d\n", len(result.Imported))
+
+	return result, nil
+}
+
+// importComponents は取り込むファイルのファイル名の構成要素を返す
+// フォーマット済みのファイル名は構成要素を引き継ぎ、タイムスタンプが重複する場合のみ以降の重複しないタイムスタンプにする
+func importComponents(fileName string, existingTimestamps map[string]bool, sanitizer parakeet.CommentSanitizer) parakeet.FileNameComponents {
+	if parakeet.IsFormatted(fileName) {
+		if components, err := parakeet.ParseFileName(fileName); err == nil {
+			if existingTimestamps[components.Timestamp] {
+				base, err := time.ParseInLocation(timestampLayout, components.Timestamp, time.Local)
+				if err != nil {
+					base = time.Now()
+				}
+				components.Timestamp = parakeet.GenerateUniqueTimestampFrom(base, existingTimestamps)
+			}
+			return *components
+		}
+	}
+
+	ext := filepath.Ext(fileName)
+	baseName := strings.TrimSuffix(fileName, ext)
+
+	return parakeet.FileNameComponents{
+		Timestamp: parakeet.GenerateUniqueTimestamp(existingTimestamps),
+		Comment:   sanitizer.Sanitize(baseName),
+		Tags:      []string{},
+		Extension: strings.TrimPrefix(ext, "."),
+	}
+}
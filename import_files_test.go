@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportFiles(t *testing.T) {
+	t.Parallel()
+	srcDir, err := os.MkdirTemp("", "parakeet-import-src-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(srcDir) })
+	archiveDir, err := os.MkdirTemp("", "parakeet-import-dst-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(archiveDir) })
+
+	require.NoError(t, os.WriteFile(filepath.Join(archiveDir, "tags.toml"), []byte("[[tag]]\nkey = \"go\"\ndesc = \"Go\"\n\n[[tag]]\nkey = \"book\"\ndesc = \"Book\"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(archiveDir, "20250101T000000--existing.md"), []byte("x"), 0644))
+
+	plain := filepath.Join(srcDir, "My Report.pdf")
+	formatted := filepath.Join(srcDir, "20250101T000000--notes__book.md")
+	require.NoError(t, os.WriteFile(plain, []byte("pdf"), 0644))
+	require.NoError(t, os.WriteFile(formatted, []byte("notes"), 0644))
+
+	buf := &bytes.Buffer{}
+	result, err := ImportFiles([]string{plain, formatted}, archiveDir, ImportFilesOptions{Writer: buf, Tags: []string{"go"}})
+	require.NoError(t, err)
+	require.Len(t, result.Imported, 2)
+
+	// フォーマットされていないファイルは元のファイル名をコメントにする
+	plainName := result.Imported[plain]
+	components, err := parakeet.ParseFileName(plainName)
+	require.NoError(t, err)
+	assert.Equal(t, "My Report", components.Comment)
+	assert.Equal(t, []string{"go"}, components.Tags)
+	assert.Equal(t, "pdf", components.Extension)
+
+	// フォーマット済みのファイルは構成要素を引き継ぎ、重複するタイムスタンプのみ変える
+	assert.Equal(t, "20250101T000001--notes__book_go.md", result.Imported[formatted])
+	assert.FileExists(t, filepath.Join(archiveDir, "20250101T000001--notes__book_go.md"))
+
+	// コピーなので元のファイルは残る
+	assert.FileExists(t, plain)
+	assert.Contains(t, buf.String(), "Imported: 2")
+}
+
+func TestImportFiles_MoveAndComment(t *testing.T) {
+	t.Parallel()
+	srcDir, err := os.MkdirTemp("", "parakeet-import-mv-src-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(srcDir) })
+	archiveDir, err := os.MkdirTemp("", "parakeet-import-mv-dst-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(archiveDir) })
+
+	src := filepath.Join(srcDir, "scan001.jpg")
+	require.NoError(t, os.WriteFile(src, []byte("jpg"), 0644))
+
+	result, err := ImportFiles([]string{src}, archiveDir, ImportFilesOptions{Writer: &bytes.Buffer{}, Comment: "領収書", Move: true})
+	require.NoError(t, err)
+
+	newName := result.Imported[src]
+	assert.Contains(t, newName, "--領収書.jpg")
+	assert.FileExists(t, filepath.Join(archiveDir, newName))
+	assert.NoFileExists(t, src)
+}
+
+func TestImportFiles_Errors(t *testing.T) {
+	t.Parallel()
+	archiveDir, err := os.MkdirTemp("", "parakeet-import-err-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(archiveDir) })
+
+	_, err = ImportFiles([]string{filepath.Join(archiveDir, "missing.pdf")}, archiveDir, ImportFilesOptions{Writer: &bytes.Buffer{}})
+	assert.ErrorContains(t, err, "file does not exist")
+
+	_, err = ImportFiles([]string{archiveDir}, archiveDir, ImportFilesOptions{Writer: &bytes.Buffer{}})
+	assert.ErrorContains(t, err, "cannot import directory")
+
+	_, err = ImportFiles([]string{"x"}, filepath.Join(archiveDir, "nope"), ImportFilesOptions{Writer: &bytes.Buffer{}})
+	assert.ErrorContains(t, err, "directory does not exist")
+}
+
+func TestImportFiles_TagRules(t *testing.T) {
+	t.Parallel()
+
+	// tags.tomlがない場合は tag add などと同じく書式のみチェックする
+	archiveDir := t.TempDir()
+	src := filepath.Join(t.TempDir(), "memo.txt")
+	require.NoError(t, os.WriteFile(src, []byte("memo"), 0644))
+
+	result, err := ImportFiles([]string{src}, archiveDir, ImportFilesOptions{Writer: &bytes.Buffer{}, Tags: []string{"todo"}})
+	require.NoError(t, err)
+	assert.Contains(t, result.Imported[src], "__todo.txt")
+
+	_, err = ImportFiles([]string{src}, archiveDir, ImportFilesOptions{Writer: &bytes.Buffer{}, Tags: []string{"bad_tag"}})
+	assert.ErrorContains(t, err, "tag cannot contain special characters")
+
+	// 定義がある場合は未定義のタグを拒否する
+	require.NoError(t, os.WriteFile(filepath.Join(archiveDir, TagsFileName), []byte("[[tag]]\nkey = \"go\"\n"), 0644))
+	_, err = ImportFiles([]string{src}, archiveDir, ImportFilesOptions{Writer: &bytes.Buffer{}, Tags: []string{"todo"}})
+	assert.ErrorContains(t, err, "undefined tags in tags.toml: todo")
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// DefaultIndexFileName は index コマンドのデフォルト出力ファイル名
+	DefaultIndexFileName = "index.md"
+	// IndexBeginMarker は自動生成部分の開始マーカー
+	IndexBeginMarker = "<!-- BEGIN parakeet -->"
+	// IndexEndMarker は自動生成部分の終了マーカー
+	IndexEndMarker = "<!-- END parakeet -->"
+)
+
+// IndexOptions はインデックスファイル更新操作のオプションを表す
+type IndexOptions struct {
+	Writer io.Writer // 出力先
+	FilterOptions
+	SortOptions
+	MarkdownLayout
+	Output string // 出力ファイルのパス（空の場合は targetDir/index.md）
+}
+
+// UpdateIndexFile はディレクトリ内のファイル一覧でインデックスファイルを更新する
+// 既存のファイルはマーカーの間のみ書き換え、手書きの部分は保持する
+func UpdateIndexFile(targetDir string, opts IndexOptions) error {
+	reporter := ReporterFor(opts.Writer)
+
+	output := opts.Output
+	if output == "" {
+		output = filepath.Join(targetDir, DefaultIndexFileName)
+	}
+
+	// Markdown表を生成
+	var table bytes.Buffer
+	if err := GenerateMarkdownTable(targetDir, MarkdownOptions{
+		Writer:         &table,
+		FilterOptions:  opts.FilterOptions,
+		SortOptions:    opts.SortOptions,
+		MarkdownLayout: opts.MarkdownLayout,
+		LinkDir:        filepath.Dir(output),
+	}); err != nil {
+		return err
+	}
+
+	return updateMarkedFile(output, table.String(), IndexBeginMarker, IndexEndMarker, reporter)
+}
+
+// updateMarkedFile はファイルのマーカーの間を content で置き換えて原子的に書き込む
+// ファイルが存在しない場合は新しく作成し、内容が変わらない場合は書き込まない
+func updateMarkedFile(output, content, beginMarker, endMarker string, reporter Reporter) error {
+	// 既存の内容を読み込む
+	existing := ""
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(output); err == nil {
+		data, err := os.ReadFile(output)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+		existing = string(data)
+		perm = info.Mode().Perm()
+	}
+
+	updated, err := replaceBetween(existing, content, beginMarker, endMarker)
+	if err != nil {
+		return fmt.Errorf("%s: %w", output, err)
+	}
+
+	if updated == existing {
+		reporter.Successf("No changes made: %s\n", output)
+		return nil
+	}
+
+	if err := WriteFileAtomic(output, []byte(updated), perm); err != nil {
+		return err
+	}
+
+	reporter.Successf("Updated: %s\n", output)
+
+	return nil
+}
+
+// ReplaceBetweenMarkers は開始・終了マーカーの間を content で置き換える
+// マーカーがない場合は末尾にマーカーと content を追加する
+func ReplaceBetweenMarkers(existing, content string) (string, error) {
+	return replaceBetween(existing, content, IndexBeginMarker, IndexEndMarker)
+}
+
+// replaceBetween は指定した開始・終了マーカーの間を content で置き換える
+func replaceBetween(existing, content, beginMarker, endMarker string) (string, error) {
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	section := beginMarker + "\n" + content + endMarker
+
+	begin := strings.Index(existing, beginMarker)
+	end := strings.Index(existing, endMarker)
+
+	switch {
+	case begin < 0 && end < 0:
+		// マーカーがなければ末尾に追加
+		if existing != "" && !strings.HasSuffix(existing, "\n") {
+			existing += "\n"
+		}
+		if existing != "" {
+			existing += "\n"
+		}
+		return existing + section + "\n", nil
+	case begin < 0 || end < 0 || end < begin:
+		return "", fmt.Errorf("unbalanced markers: %s ... %s", beginMarker, endMarker)
+	}
+
+	return existing[:begin] + section + existing[end+len(endMarker):], nil
+}
+
+// WriteFileAtomic は一時ファイルに書き込んでからリネームすることで、ファイルを原子的に置き換える
+// 途中でクラッシュしても中途半端な内容のファイルが残らない
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	// 失敗した場合は一時ファイルを削除
+	success := false
+	defer func() {
+		if !success {
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to sync temporary file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return fmt.Errorf("failed to set permissions: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace file: %w", err)
+	}
+
+	success = true
+	return nil
+}
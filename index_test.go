@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplaceBetweenMarkers(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		existing  string
+		content   string
+		expected  string
+		wantError bool
+	}{
+		{
+			name:     "empty file",
+			existing: "",
+			content:  "table\n",
+			expected: IndexBeginMarker + "\ntable\n" + IndexEndMarker + "\n",
+		},
+		{
+			name:     "append to file without markers",
+			existing: "# Index",
+			content:  "table\n",
+			expected: "# Index\n\n" + IndexBeginMarker + "\ntable\n" + IndexEndMarker + "\n",
+		},
+		{
+			name:     "replace between markers",
+			existing: "# Index\n" + IndexBeginMarker + "\nold\n" + IndexEndMarker + "\nfooter\n",
+			content:  "new",
+			expected: "# Index\n" + IndexBeginMarker + "\nnew\n" + IndexEndMarker + "\nfooter\n",
+		},
+		{
+			name:      "missing end marker",
+			existing:  IndexBeginMarker + "\nold\n",
+			content:   "new",
+			wantError: true,
+		},
+		{
+			name:      "markers in wrong order",
+			existing:  IndexEndMarker + "\nold\n" + IndexBeginMarker,
+			content:   "new",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := ReplaceBetweenMarkers(tt.existing, tt.content)
+			if tt.wantError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestUpdateIndexFile(t *testing.T) {
+	t.Parallel()
+	// Create temporary directory
+	tmpDir, err := os.MkdirTemp("", "parakeet-index-*")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250903T083109--TCPIP入門__network.pdf"), []byte("x"), 0644))
+
+	indexPath := filepath.Join(tmpDir, DefaultIndexFileName)
+	handWritten := "# My Archive\n\nHand-written intro.\n\n" + IndexBeginMarker + "\nstale\n" + IndexEndMarker + "\n\n## Notes\n"
+	require.NoError(t, os.WriteFile(indexPath, []byte(handWritten), 0600))
+
+	buf := &bytes.Buffer{}
+	err = UpdateIndexFile(tmpDir, IndexOptions{Writer: buf})
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "✓ Updated:")
+
+	data, err := os.ReadFile(indexPath)
+	require.NoError(t, err)
+	content := string(data)
+	assert.True(t, strings.HasPrefix(content, "# My Archive\n\nHand-written intro.\n\n"+IndexBeginMarker))
+	assert.Contains(t, content, "| 20250903T083109 | TCPIP入門 | network |")
+	assert.NotContains(t, content, "stale")
+	assert.True(t, strings.HasSuffix(content, IndexEndMarker+"\n\n## Notes\n"))
+
+	// パーミッションが保持される
+	info, err := os.Stat(indexPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// 一時ファイルが残っていない
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	// 2回目は変更なし
+	buf.Reset()
+	err = UpdateIndexFile(tmpDir, IndexOptions{Writer: buf})
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "✓ No changes made")
+}
+
+func TestUpdateIndexFile_UnbalancedMarkers(t *testing.T) {
+	t.Parallel()
+	// Create temporary directory
+	tmpDir, err := os.MkdirTemp("", "parakeet-index-unbalanced-*")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	indexPath := filepath.Join(tmpDir, DefaultIndexFileName)
+	original := "# Index\n" + IndexBeginMarker + "\n"
+	require.NoError(t, os.WriteFile(indexPath, []byte(original), 0644))
+
+	err = UpdateIndexFile(tmpDir, IndexOptions{Writer: &bytes.Buffer{}})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unbalanced markers")
+
+	// 元のファイルは変更されない
+	data, err := os.ReadFile(indexPath)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(data))
+}
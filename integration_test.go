@@ -2,11 +2,13 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/kijimaD/parakeet/pkg/parakeet"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -37,11 +39,11 @@ func TestIntegration_GenerateAndValidate(t *testing.T) {
 	// Step 1: Validate before formatting (should all be invalid)
 	validateBuf := &bytes.Buffer{}
 	validateOpts := ValidateOptions{
-		Writer:     validateBuf,
-		Extensions: nil,
+		Writer:        validateBuf,
+		FilterOptions: FilterOptions{Extensions: nil},
 	}
 
-	result, err := ValidateFileNames(tmpDir, validateOpts)
+	result, err := ValidateFileNames(context.Background(), tmpDir, validateOpts)
 	require.NoError(t, err)
 	assert.Equal(t, 5, result.TotalFiles)
 	assert.Equal(t, 0, result.ValidFiles, "All files should be invalid before formatting")
@@ -53,11 +55,11 @@ func TestIntegration_GenerateAndValidate(t *testing.T) {
 	// Step 2: Generate formatted names
 	generateBuf := &bytes.Buffer{}
 	generateOpts := RenameOptions{
-		Writer:     generateBuf,
-		Extensions: []string{"pdf", "jpg", "txt", "pptx", "xlsx"},
+		Writer:        generateBuf,
+		FilterOptions: FilterOptions{Extensions: []string{"pdf", "jpg", "txt", "pptx", "xlsx"}},
 	}
 
-	err = GenerateFileNames(tmpDir, generateOpts)
+	err = GenerateFileNames(context.Background(), tmpDir, generateOpts)
 	require.NoError(t, err)
 
 	output = generateBuf.String()
@@ -70,17 +72,17 @@ func TestIntegration_GenerateAndValidate(t *testing.T) {
 	require.Len(t, entries, 5, "Should still have 5 files")
 
 	for _, entry := range entries {
-		assert.True(t, IsFormatted(entry.Name()), "File %s should be formatted", entry.Name())
+		assert.True(t, parakeet.IsFormatted(entry.Name()), "File %s should be formatted", entry.Name())
 	}
 
 	// Step 4: Validate after formatting (should all be valid)
 	validateBuf2 := &bytes.Buffer{}
 	validateOpts2 := ValidateOptions{
-		Writer:     validateBuf2,
-		Extensions: nil,
+		Writer:        validateBuf2,
+		FilterOptions: FilterOptions{Extensions: nil},
 	}
 
-	result2, err := ValidateFileNames(tmpDir, validateOpts2)
+	result2, err := ValidateFileNames(context.Background(), tmpDir, validateOpts2)
 	require.NoError(t, err)
 	assert.Equal(t, 5, result2.TotalFiles)
 	assert.Equal(t, 5, result2.ValidFiles, "All files should be valid after formatting")
@@ -107,12 +109,12 @@ func TestIntegration_ExtensionFiltering(t *testing.T) {
 
 	// Create files with various extensions
 	testFiles := map[string]string{
-		"report.pdf":     "PDF content",
-		"readme.md":      "Markdown content",
-		"data.csv":       "CSV content",
-		"script.py":      "Python script",
-		"notes.txt":      "Text notes",
-		"config.yaml":    "YAML config",
+		"report.pdf":  "PDF content",
+		"readme.md":   "Markdown content",
+		"data.csv":    "CSV content",
+		"script.py":   "Python script",
+		"notes.txt":   "Text notes",
+		"config.yaml": "YAML config",
 	}
 
 	for name, content := range testFiles {
@@ -124,11 +126,11 @@ func TestIntegration_ExtensionFiltering(t *testing.T) {
 	// Step 1: Generate formatted names for PDF and TXT files only
 	generateBuf := &bytes.Buffer{}
 	generateOpts := RenameOptions{
-		Writer:     generateBuf,
-		Extensions: []string{"pdf", "txt"},
+		Writer:        generateBuf,
+		FilterOptions: FilterOptions{Extensions: []string{"pdf", "txt"}},
 	}
 
-	err = GenerateFileNames(tmpDir, generateOpts)
+	err = GenerateFileNames(context.Background(), tmpDir, generateOpts)
 	require.NoError(t, err)
 
 	output := generateBuf.String()
@@ -143,7 +145,7 @@ func TestIntegration_ExtensionFiltering(t *testing.T) {
 	unformattedCount := 0
 
 	for _, entry := range entries {
-		if IsFormatted(entry.Name()) {
+		if parakeet.IsFormatted(entry.Name()) {
 			formattedCount++
 			ext := filepath.Ext(entry.Name())
 			if ext != "" {
@@ -161,11 +163,11 @@ func TestIntegration_ExtensionFiltering(t *testing.T) {
 	// Step 3: Validate only MD and CSV files
 	validateBuf := &bytes.Buffer{}
 	validateOpts := ValidateOptions{
-		Writer:     validateBuf,
-		Extensions: []string{"md", "csv"},
+		Writer:        validateBuf,
+		FilterOptions: FilterOptions{Extensions: []string{"md", "csv"}},
 	}
 
-	result, err := ValidateFileNames(tmpDir, validateOpts)
+	result, err := ValidateFileNames(context.Background(), tmpDir, validateOpts)
 	require.NoError(t, err)
 	assert.Equal(t, 2, result.TotalFiles, "Should check only 2 files")
 	assert.Equal(t, 0, result.ValidFiles, "MD and CSV should be invalid")
@@ -198,11 +200,11 @@ func TestIntegration_MixedScenario(t *testing.T) {
 	// Step 1: Validate current state
 	validateBuf1 := &bytes.Buffer{}
 	validateOpts1 := ValidateOptions{
-		Writer:     validateBuf1,
-		Extensions: nil,
+		Writer:        validateBuf1,
+		FilterOptions: FilterOptions{Extensions: nil},
 	}
 
-	result1, err := ValidateFileNames(tmpDir, validateOpts1)
+	result1, err := ValidateFileNames(context.Background(), tmpDir, validateOpts1)
 	require.NoError(t, err)
 	assert.Equal(t, 5, result1.TotalFiles)
 	assert.Equal(t, 3, result1.ValidFiles)
@@ -214,11 +216,11 @@ func TestIntegration_MixedScenario(t *testing.T) {
 	// Step 2: Generate formatted names for unformatted files
 	generateBuf := &bytes.Buffer{}
 	generateOpts := RenameOptions{
-		Writer:     generateBuf,
-		Extensions: []string{"pdf", "txt", "jpg"},
+		Writer:        generateBuf,
+		FilterOptions: FilterOptions{Extensions: []string{"pdf", "txt", "jpg"}},
 	}
 
-	err = GenerateFileNames(tmpDir, generateOpts)
+	err = GenerateFileNames(context.Background(), tmpDir, generateOpts)
 	require.NoError(t, err)
 
 	output2 := generateBuf.String()
@@ -228,11 +230,11 @@ func TestIntegration_MixedScenario(t *testing.T) {
 	// Step 3: Validate all files are now formatted
 	validateBuf2 := &bytes.Buffer{}
 	validateOpts2 := ValidateOptions{
-		Writer:     validateBuf2,
-		Extensions: nil,
+		Writer:        validateBuf2,
+		FilterOptions: FilterOptions{Extensions: nil},
 	}
 
-	result2, err := ValidateFileNames(tmpDir, validateOpts2)
+	result2, err := ValidateFileNames(context.Background(), tmpDir, validateOpts2)
 	require.NoError(t, err)
 	assert.Equal(t, 5, result2.TotalFiles)
 	assert.Equal(t, 5, result2.ValidFiles, "All files should be valid now")
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// ResolveJobs は --jobs の値から並列数を決める
+// 0 の場合は CPU 数、1 の場合は並列にしない。負の値はエラーにする
+func ResolveJobs(jobs int) (int, error) {
+	if jobs < 0 {
+		return 0, fmt.Errorf("invalid --jobs: %d (must be 0 or more)", jobs)
+	}
+	if jobs == 0 {
+		return runtime.NumCPU(), nil
+	}
+	return jobs, nil
+}
+
+// parallelMap は items の各要素に fn を最大 jobs 個のゴルーチンで適用し、結果を items と同じ順序で返す
+// 出力や結果の集計は呼び出し元で順に行い、実行ごとに出力の順序が変わらないようにする。
+// jobs が1以下の場合は呼び出し元のゴルーチンで順に処理する。fn は並列に呼ばれるため、共有する状態を変更してはならない
+func parallelMap[T, R any](jobs int, items []T, fn func(T) R) []R {
+	results := make([]R, len(items))
+	if jobs <= 1 || len(items) <= 1 {
+		for i, item := range items {
+			results[i] = fn(item)
+		}
+		return results
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(jobs, len(items)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = fn(items[i])
+			}
+		}()
+	}
+	for i := range items {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveJobs(t *testing.T) {
+	t.Parallel()
+
+	jobs, err := ResolveJobs(4)
+	require.NoError(t, err)
+	assert.Equal(t, 4, jobs)
+
+	jobs, err = ResolveJobs(0)
+	require.NoError(t, err)
+	assert.Equal(t, runtime.NumCPU(), jobs)
+
+	_, err = ResolveJobs(-1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid --jobs")
+}
+
+func TestParallelMap(t *testing.T) {
+	t.Parallel()
+
+	items := make([]int, 1000)
+	for i := range items {
+		items[i] = i
+	}
+
+	for _, jobs := range []int{0, 1, 3, 16} {
+		t.Run(fmt.Sprintf("jobs=%d", jobs), func(t *testing.T) {
+			t.Parallel()
+			results := parallelMap(jobs, items, func(i int) string { return fmt.Sprint(i * 2) })
+			require.Len(t, results, len(items))
+			for i, result := range results {
+				assert.Equal(t, fmt.Sprint(i*2), result)
+			}
+		})
+	}
+
+	assert.Empty(t, parallelMap(8, []int{}, func(i int) int { return i }))
+}
+
+func TestJobs_DeterministicOutput(t *testing.T) {
+	t.Parallel()
+
+	mtime := time.Date(2025, 9, 3, 8, 31, 9, 0, time.Local)
+	newFiles := func() fstest.MapFS {
+		files := fstest.MapFS{
+			"tags.toml": {Data: []byte("[[tags]]\nname = \"network\"\n")},
+		}
+		for i := range 200 {
+			switch i % 4 {
+			case 0:
+				files[fmt.Sprintf("scan-%03d.pdf", i)] = &fstest.MapFile{ModTime: mtime}
+			case 1:
+				files[fmt.Sprintf("20250903T0831%02d--note-%03d__network.pdf", i%60, i)] = &fstest.MapFile{ModTime: mtime}
+			case 2:
+				files[fmt.Sprintf("20250903T0832%02d--note-%03d__unknown.pdf", i%60, i)] = &fstest.MapFile{ModTime: mtime}
+			default:
+				files[fmt.Sprintf("invalid-%03d.pdf", i)] = &fstest.MapFile{ModTime: mtime}
+			}
+		}
+		return files
+	}
+
+	// 並列数によらず、検証の結果と出力の順序は同じ
+	validate := func(jobs int) (*ValidateResult, string) {
+		buf := &bytes.Buffer{}
+		result, err := ValidateFileNames(context.Background(), ".", ValidateOptions{Writer: buf, FilterOptions: FilterOptions{Extensions: []string{"pdf"}}, FS: NewMemFileSystem(newFiles()), Jobs: jobs})
+		require.NoError(t, err)
+		return result, buf.String()
+	}
+	serialResult, serialOutput := validate(1)
+	parallelResult, parallelOutput := validate(8)
+	assert.Equal(t, serialResult.InvalidFiles, parallelResult.InvalidFiles)
+	assert.Equal(t, serialResult.UndefinedTagFiles, parallelResult.UndefinedTagFiles)
+	assert.Equal(t, serialResult.ValidFiles, parallelResult.ValidFiles)
+	assert.Equal(t, serialOutput, parallelOutput)
+
+	// 並列数によらず、同じファイルに同じタイムスタンプを割り当てる
+	generate := func(jobs int) (fstest.MapFS, string) {
+		files := newFiles()
+		buf := &bytes.Buffer{}
+		err := GenerateFileNames(context.Background(), ".", RenameOptions{Writer: buf, FilterOptions: FilterOptions{Extensions: []string{"pdf"}}, FS: NewMemFileSystem(files), FromMtime: true, Jobs: jobs})
+		require.NoError(t, err)
+		return files, buf.String()
+	}
+	serialFiles, serialOutput := generate(1)
+	parallelFiles, parallelOutput := generate(8)
+	assert.Equal(t, serialFiles, parallelFiles)
+	assert.Equal(t, serialOutput, parallelOutput)
+}
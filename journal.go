@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// JournalEntry はジャーナルに記録する1件のリネームを表す
+type JournalEntry struct {
+	Time    string `json:"time"`               // 実行日時（RFC3339）
+	Command string `json:"command"`            // 実行したコマンド
+	Dir     string `json:"dir"`                // 対象ディレクトリ
+	From    string `json:"from"`               // 旧ファイル名
+	To      string `json:"to"`                 // 新ファイル名
+	Batch   string `json:"batch,omitempty"`    // 同じコマンドの実行で記録したリネームに共通の ID
+	Undo    string `json:"undo,omitempty"`     // 取り消したバッチの ID（undo の場合のみ）
+	ModTime string `json:"mod_time,omitempty"` // リネーム直後の新ファイルの更新日時（RFC3339Nano）
+	Size    int64  `json:"size,omitempty"`     // リネーム直後の新ファイルのサイズ
+}
+
+// BatchKey はエントリが属するバッチを返す
+// バッチ ID のない古い記録は実行日時とコマンドが同じものを同じバッチとみなす
+func (e JournalEntry) BatchKey() string {
+	if e.Batch != "" {
+		return e.Batch
+	}
+	return e.Time + " " + e.Command
+}
+
+// AppendJournal は実行したリネームをジャーナルファイル（1行1件のJSON）に追記する
+// ジャーナルがあれば、あとから変更内容の確認や手作業での巻き戻しができる
+func AppendJournal(journalPath, command, dirPath string, plans []renamePlan) error {
+	return appendJournal(Journal{Path: journalPath, Command: command}, dirPath, plans)
+}
+
+// appendJournal は実行したリネームを journal のバッチとして追記する
+// 新ファイルの更新日時とサイズも記録し、undo がその後の変更を検出できるようにする
+func appendJournal(journal Journal, dirPath string, plans []renamePlan) error {
+	if len(plans) == 0 {
+		return nil
+	}
+
+	absDir, err := filepath.Abs(dirPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve directory: %w", err)
+	}
+
+	file, err := os.OpenFile(journal.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	now := time.Now().Format(time.RFC3339)
+	encoder := json.NewEncoder(file)
+	for _, plan := range plans {
+		entry := JournalEntry{Time: now, Command: journal.Command, Dir: absDir, From: plan.From, To: plan.To, Batch: journal.Batch, Undo: journal.Undo}
+		if info, err := os.Stat(filepath.Join(absDir, plan.To)); err == nil {
+			entry.ModTime = info.ModTime().Format(time.RFC3339Nano)
+			entry.Size = info.Size()
+		}
+		if err := encoder.Encode(entry); err != nil {
+			return fmt.Errorf("failed to write journal: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// JournalFileName はディレクトリのジャーナルファイルの名前（StateDirName 内に置く）
+const JournalFileName = "journal.jsonl"
+
+// Journal はコマンドが実行したリネームを記録するジャーナル
+// nil の場合は記録しない
+type Journal struct {
+	Path    string // ジャーナルファイルのパス
+	Command string // 記録するコマンド名
+	Batch   string // 記録するリネームに共通のバッチ ID（undo はバッチ単位で取り消す）
+	Undo    string // 取り消したバッチの ID（undo の場合のみ）
+}
+
+// JournalPath はディレクトリのジャーナルファイルのパスを返す
+func JournalPath(dirPath string) string {
+	return filepath.Join(dirPath, StateDirName, JournalFileName)
+}
+
+// NewJournal はディレクトリのジャーナルに command のリネームを記録する Journal を作成する
+func NewJournal(dirPath, command string) *Journal {
+	return &Journal{Path: JournalPath(dirPath), Command: command, Batch: newBatchID()}
+}
+
+// newBatchID は実行ごとに異なるバッチ ID を返す
+func newBatchID() string {
+	return time.Now().Format("20060102T150405.000000000")
+}
+
+// Record は dirPath で実行したリネームをジャーナルに追記する
+// ジャーナルのディレクトリがない場合は作成する
+func (j *Journal) Record(dirPath string, plans ...renamePlan) error {
+	if j == nil || len(plans) == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(j.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	return appendJournal(*j, dirPath, plans)
+}
+
+// recordFileRename は1つのファイルのリネームを、ファイルのあるディレクトリのジャーナルに記録し、wiki リンクを書き換える
+// ID で再帰的に見つけたファイルもそのディレクトリで undo できるようにする。失敗してもリネームは取り消さずに警告する
+func recordFileRename(command, oldPath, newPath string, w io.Writer) {
+	reporter := ReporterFor(w)
+	dirPath := filepath.Dir(oldPath)
+	plan := renamePlan{From: filepath.Base(oldPath), To: filepath.Base(newPath)}
+
+	if err := NewJournal(dirPath, command).Record(dirPath, plan); err != nil {
+		reporter.Warnf("%s (%v)\n", plan.From, err)
+	}
+	if err := obsidianVaultFor(dirPath, w).RewriteLinks(dirPath, plan); err != nil {
+		reporter.Warnf("%s (%v)\n", plan.From, err)
+	}
+}
+
+// ReadJournal はジャーナルファイルのすべての記録を古い順に読み込む
+// ファイルが存在しない場合は空のスライスを返す
+func ReadJournal(journalPath string) ([]JournalEntry, error) {
+	file, err := os.Open(journalPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []JournalEntry{}, nil
+		}
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	entries := []JournalEntry{}
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse journal line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// LegacyRecognizer は旧命名規則のファイル名（拡張子を除く）から日付とタイトルを取り出すルールを表す
+// Pattern は名前付きグループ title と、任意で date を持つ正規表現
+type LegacyRecognizer struct {
+	Name       string `toml:"name"`        // ルール名（報告用）
+	Pattern    string `toml:"pattern"`     // ファイル名に一致させる正規表現
+	DateLayout string `toml:"date_layout"` // date グループを解釈する time パッケージのレイアウト（空の場合は 2006-01-02）
+}
+
+// DefaultLegacyRecognizers は generate --legacy で設定ファイルのルールの後に適用する組み込みのルール
+var DefaultLegacyRecognizers = []LegacyRecognizer{
+	{
+		Name:       "screenshot",
+		Pattern:    `^(?P<title>Screen ?[Ss]hot) (?P<date>\d{4}-\d{2}-\d{2} at \d{2}\.\d{2}\.\d{2})$`,
+		DateLayout: "2006-01-02 at 15.04.05",
+	},
+	{
+		Name:    "date-prefix",
+		Pattern: `^(?P<date>\d{4}-\d{2}-\d{2})[ _.-]+(?P<title>.+)$`,
+	},
+	{
+		Name:       "compact-date-prefix",
+		Pattern:    `^(?P<date>\d{8})[ _-]+(?P<title>.+)$`,
+		DateLayout: "20060102",
+	},
+	{
+		Name:    "date-suffix",
+		Pattern: `^(?P<title>.+?)[ _.-]+(?P<date>\d{4}-\d{2}-\d{2})$`,
+	},
+	{
+		Name:    "version-suffix",
+		Pattern: `^(?P<title>.+?)(?:[ _-]+(?:[vV]\d+|final|FINAL|copy|\(\d+\)))+$`,
+	},
+}
+
+// LegacyMatch は旧命名規則のファイル名から取り出した情報を表す
+type LegacyMatch struct {
+	Time        time.Time // ファイル名の日付（日付を含まない場合はゼロ値）
+	Title       string    // 日付や版の表記を除いたタイトル
+	Recognizers []string  // 一致したルール名（適用順）
+}
+
+// LegacyMatcher はコンパイル済みの旧命名規則のルールを表す
+// nil の場合はどのファイル名にも一致しない
+type LegacyMatcher struct {
+	rules []legacyRule
+}
+
+// legacyRule はコンパイル済みの1つのルール
+type legacyRule struct {
+	LegacyRecognizer
+	re *regexp.Regexp
+}
+
+// NewLegacyMatcher はルールをコンパイルする
+// 正規表現が不正な場合や title グループがない場合はエラーを返す
+func NewLegacyMatcher(recognizers []LegacyRecognizer) (*LegacyMatcher, error) {
+	matcher := &LegacyMatcher{}
+	for i, r := range recognizers {
+		name := r.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid legacy pattern %s: %w", name, err)
+		}
+		if re.SubexpIndex("title") < 0 {
+			return nil, fmt.Errorf("legacy pattern %s has no title group: %s", name, r.Pattern)
+		}
+
+		r.Name = name
+		if r.DateLayout == "" {
+			r.DateLayout = dateLayout
+		}
+		matcher.rules = append(matcher.rules, legacyRule{LegacyRecognizer: r, re: re})
+	}
+	return matcher, nil
+}
+
+// Match はルールを順に適用し、ファイル名から日付とタイトルを取り出す
+// 一致したルールのタイトルに後続のルールを適用するため、日付の除去と版の表記の除去を組み合わせられる
+// 日付は最初に一致したルールのものを使い、解釈できない日付を持つルールは一致しないものとみなす
+func (m *LegacyMatcher) Match(baseName string) (*LegacyMatch, bool) {
+	if m == nil {
+		return nil, false
+	}
+
+	match := &LegacyMatch{Title: baseName}
+	for _, rule := range m.rules {
+		groups := rule.re.FindStringSubmatch(match.Title)
+		if groups == nil {
+			continue
+		}
+
+		title := strings.TrimSpace(groups[rule.re.SubexpIndex("title")])
+		if title == "" {
+			continue
+		}
+
+		if i := rule.re.SubexpIndex("date"); i >= 0 && groups[i] != "" {
+			t, err := time.ParseInLocation(rule.DateLayout, groups[i], time.Local)
+			if err != nil {
+				continue
+			}
+			if match.Time.IsZero() {
+				match.Time = t
+			}
+		}
+
+		match.Title = title
+		match.Recognizers = append(match.Recognizers, rule.Name)
+	}
+
+	return match, len(match.Recognizers) > 0
+}
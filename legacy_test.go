@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLegacyMatcher_Match(t *testing.T) {
+	t.Parallel()
+	matcher, err := NewLegacyMatcher(DefaultLegacyRecognizers)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		baseName    string
+		time        time.Time
+		title       string
+		recognizers []string
+	}{
+		{
+			name:        "date prefix",
+			baseName:    "2023-01-15 report",
+			time:        time.Date(2023, 1, 15, 0, 0, 0, 0, time.Local),
+			title:       "report",
+			recognizers: []string{"date-prefix"},
+		},
+		{
+			name:        "compact date prefix",
+			baseName:    "20230115_meeting notes",
+			time:        time.Date(2023, 1, 15, 0, 0, 0, 0, time.Local),
+			title:       "meeting notes",
+			recognizers: []string{"compact-date-prefix"},
+		},
+		{
+			name:        "date suffix",
+			baseName:    "invoice-2022-12-01",
+			time:        time.Date(2022, 12, 1, 0, 0, 0, 0, time.Local),
+			title:       "invoice",
+			recognizers: []string{"date-suffix"},
+		},
+		{
+			name:        "version suffix",
+			baseName:    "report_v2_final",
+			title:       "report",
+			recognizers: []string{"version-suffix"},
+		},
+		{
+			name:        "date prefix and version suffix",
+			baseName:    "2023-01-15 report_v2_final (1)",
+			time:        time.Date(2023, 1, 15, 0, 0, 0, 0, time.Local),
+			title:       "report",
+			recognizers: []string{"date-prefix", "version-suffix"},
+		},
+		{
+			name:        "screenshot",
+			baseName:    "Screenshot 2024-03-02 at 10.30.15",
+			time:        time.Date(2024, 3, 2, 10, 30, 15, 0, time.Local),
+			title:       "Screenshot",
+			recognizers: []string{"screenshot"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			match, ok := matcher.Match(tt.baseName)
+			require.True(t, ok)
+			assert.True(t, tt.time.Equal(match.Time), "time: %v", match.Time)
+			assert.Equal(t, tt.title, match.Title)
+			assert.Equal(t, tt.recognizers, match.Recognizers)
+		})
+	}
+
+	// 日付として解釈できない数字や、ルールに一致しないファイル名
+	for _, baseName := range []string{"12345678 notes", "2023-13-45 report", "plain name"} {
+		match, ok := matcher.Match(baseName)
+		if ok {
+			assert.True(t, match.Time.IsZero(), baseName)
+		}
+	}
+
+	// nil の場合はどのファイル名にも一致しない
+	var none *LegacyMatcher
+	_, ok := none.Match("2023-01-15 report")
+	assert.False(t, ok)
+}
+
+func TestNewLegacyMatcher_Errors(t *testing.T) {
+	t.Parallel()
+	_, err := NewLegacyMatcher([]LegacyRecognizer{{Name: "broken", Pattern: `(`}})
+	assert.ErrorContains(t, err, "invalid legacy pattern broken")
+
+	_, err = NewLegacyMatcher([]LegacyRecognizer{{Pattern: `^(?P<date>\d{8})$`}})
+	assert.ErrorContains(t, err, "legacy pattern #1 has no title group")
+}
+
+func TestGenerateFileNames_Legacy(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-legacy-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	for _, name := range []string{"2023-01-15 report_final.pdf", "SCAN-0042-20210704-receipt.pdf"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("test"), 0644))
+	}
+
+	// 設定ファイルのルールは組み込みのルールより先に適用する
+	recognizers := append([]LegacyRecognizer{{
+		Name:       "scanner",
+		Pattern:    `^SCAN-\d+-(?P<date>\d{8})-(?P<title>.+)$`,
+		DateLayout: "20060102",
+	}}, DefaultLegacyRecognizers...)
+	matcher, err := NewLegacyMatcher(recognizers)
+	require.NoError(t, err)
+
+	opts := RenameOptions{Writer: &bytes.Buffer{}, FilterOptions: FilterOptions{Extensions: []string{"pdf"}}, Legacy: matcher}
+	require.NoError(t, GenerateFileNames(context.Background(), tmpDir, opts))
+
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	names := []string{}
+	for _, entry := range entries {
+		components, err := parakeet.ParseFileName(entry.Name())
+		require.NoError(t, err, entry.Name())
+		names = append(names, components.Timestamp+" "+components.Comment)
+	}
+	assert.ElementsMatch(t, []string{"20210704T000000 receipt", "20230115T000000 report"}, names)
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// LinkExtensions はリンクを抽出するテキストファイルの拡張子
+var LinkExtensions = []string{"md", "org"}
+
+// linkIDPattern は本文中の ID（フォーマット済みファイル名の先頭を含む、ミリ秒まで含む ID も対象）
+var linkIDPattern = regexp.MustCompile(`\b\d{8}T\d{6}(?:,\d{3})?\b`)
+
+// LinksOptions はリンク表示操作のオプションを表す
+type LinksOptions struct {
+	Writer io.Writer // 出力先
+	FilterOptions
+}
+
+// LinkGraph はディレクトリ内のファイル間のリンクを表す
+type LinkGraph struct {
+	Files   []string            // フォーマット済みのファイル名（ソート済み）
+	Links   map[string][]string // リンク元のファイル名 -> リンク先のファイル名（ソート済み）
+	Missing map[string][]string // リンク元のファイル名 -> ファイルが見つからない ID（ソート済み）
+}
+
+// BuildLinkGraph はディレクトリ内の Markdown・Org ファイルから他のファイルの ID への参照を抽出する
+// ID とフォーマット済みファイル名のどちらで書かれた参照もリンクとみなす。自分自身の ID への参照は除く
+func BuildLinkGraph(targetDir string, filter FilterOptions) (*LinkGraph, error) {
+	// ディレクトリの存在チェック
+	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", targetDir)
+	}
+
+	entries, err := os.ReadDir(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	graph := &LinkGraph{
+		Files:   []string{},
+		Links:   make(map[string][]string),
+		Missing: make(map[string][]string),
+	}
+
+	// ID ごとのファイル名（拡張子違いの同じ ID は両方にリンクする）
+	filesByID := make(map[string][]string)
+	for _, entry := range entries {
+		// ディレクトリとシムはスキップ
+		if entry.IsDir() || IsShim(targetDir, entry) {
+			continue
+		}
+
+		fileName := entry.Name()
+		if !filter.Matches(fileName) {
+			continue
+		}
+
+		components, err := parakeet.ParseFileName(fileName)
+		if err != nil {
+			continue
+		}
+
+		graph.Files = append(graph.Files, fileName)
+		filesByID[components.Timestamp] = append(filesByID[components.Timestamp], fileName)
+	}
+	sort.Strings(graph.Files)
+
+	for _, fileName := range graph.Files {
+		if !parakeet.MatchesExtensions(fileName, LinkExtensions) {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(targetDir, fileName))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+
+		components, err := parakeet.ParseFileName(fileName)
+		if err != nil {
+			continue
+		}
+
+		seen := map[string]bool{components.Timestamp: true}
+		for _, id := range linkIDPattern.FindAllString(string(data), -1) {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			targets, ok := filesByID[id]
+			if !ok {
+				graph.Missing[fileName] = append(graph.Missing[fileName], id)
+				continue
+			}
+			graph.Links[fileName] = append(graph.Links[fileName], targets...)
+		}
+
+		sort.Strings(graph.Links[fileName])
+		sort.Strings(graph.Missing[fileName])
+	}
+
+	return graph, nil
+}
+
+// Backlinks は fileName にリンクしているファイル名をソートして返す
+func (g *LinkGraph) Backlinks(fileName string) []string {
+	backlinks := []string{}
+	for _, source := range g.Files {
+		for _, target := range g.Links[source] {
+			if target == fileName {
+				backlinks = append(backlinks, source)
+				break
+			}
+		}
+	}
+	return backlinks
+}
+
+// ShowLinks は ID で指定したファイルからリンクしているファイルを「ID | タイトル」の形式で出力し、そのリストを返す
+// ファイルが見つからない ID は警告として出力する
+func ShowLinks(targetDir, id string, opts LinksOptions) ([]string, error) {
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("link", linkedFileColumns...)
+
+	graph, fileName, err := linkGraphFor(targetDir, id, opts.FilterOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	links := graph.Links[fileName]
+	if links == nil {
+		links = []string{}
+	}
+	for _, link := range links {
+		emitLinkedFile(reporter, "link", link)
+	}
+	for _, missing := range graph.Missing[fileName] {
+		reporter.Warnf("%s (no file with this ID)\n", missing)
+	}
+
+	return links, nil
+}
+
+// ShowBacklinks は ID で指定したファイルにリンクしているファイルを「ID | タイトル」の形式で出力し、そのリストを返す
+func ShowBacklinks(targetDir, id string, opts LinksOptions) ([]string, error) {
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("backlink", linkedFileColumns...)
+
+	graph, fileName, err := linkGraphFor(targetDir, id, opts.FilterOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	backlinks := graph.Backlinks(fileName)
+	for _, backlink := range backlinks {
+		emitLinkedFile(reporter, "backlink", backlink)
+	}
+
+	return backlinks, nil
+}
+
+// linkGraphFor はディレクトリのリンクグラフと、ID で指定したファイルのファイル名を返す
+func linkGraphFor(targetDir, id string, filter FilterOptions) (*LinkGraph, string, error) {
+	filePath, err := FindFileByIDWithDepth(targetDir, id, 0)
+	if err != nil {
+		return nil, "", err
+	}
+
+	graph, err := BuildLinkGraph(targetDir, filter)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return graph, filepath.Base(filePath), nil
+}
+
+// linkedFileColumns はリンクでつながったファイルのレコードの列
+var linkedFileColumns = []string{"id", "title", "tags", "file"}
+
+// emitLinkedFile はリンクでつながったファイルを「ID | タイトル」の形式で出力する
+func emitLinkedFile(reporter Reporter, event, fileName string) {
+	components, err := parakeet.ParseFileName(fileName)
+	if err != nil {
+		return
+	}
+
+	reporter.Emit(event, map[string]any{
+		"file":  fileName,
+		"id":    components.Timestamp,
+		"title": components.Comment,
+		"tags":  components.Tags,
+	}, "%s | %s\n", components.Timestamp, components.Comment)
+}
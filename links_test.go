@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupLinkDir はリンクを含むファイルのあるディレクトリを作成する
+func setupLinkDir(t *testing.T) string {
+	t.Helper()
+	return newTestDir(t, map[string]string{
+		"20250101T000000--index__note.md":   "id: 20250101T000000\n- [[20250102T000000--go入門__go.md]]\n- 20250103T000000\n- 20250109T000000\n",
+		"20250102T000000--go入門__go.md":      "see 20250103T000000 and 20250102T000000\n",
+		"20250103T000000--tcp__network.org": "* back to [[file:20250101T000000--index__note.md]]\n",
+		"20250103T000000--tcp__network.pdf": "%PDF 20250101T000000",
+		"memo.md":                           "20250101T000000\n",
+	})
+}
+
+func TestBuildLinkGraph(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupLinkDir(t)
+
+	graph, err := BuildLinkGraph(tmpDir, FilterOptions{})
+	require.NoError(t, err)
+
+	assert.Len(t, graph.Files, 4)
+	assert.Equal(t, []string{
+		"20250102T000000--go入門__go.md",
+		"20250103T000000--tcp__network.org",
+		"20250103T000000--tcp__network.pdf",
+	}, graph.Links["20250101T000000--index__note.md"])
+	assert.Equal(t, []string{"20250109T000000"}, graph.Missing["20250101T000000--index__note.md"])
+
+	// PDF の内容からはリンクを抽出しない
+	assert.Empty(t, graph.Links["20250103T000000--tcp__network.pdf"])
+	assert.Equal(t, []string{"20250101T000000--index__note.md", "20250102T000000--go入門__go.md"}, graph.Backlinks("20250103T000000--tcp__network.org"))
+}
+
+func TestShowLinksAndBacklinks(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupLinkDir(t)
+
+	buf := &bytes.Buffer{}
+	links, err := ShowLinks(tmpDir, "20250101T000000", LinksOptions{Writer: buf})
+	require.NoError(t, err)
+	assert.Len(t, links, 3)
+	assert.Contains(t, buf.String(), "20250102T000000 | go入門\n")
+	assert.Contains(t, buf.String(), "20250109T000000 (no file with this ID)")
+
+	buf.Reset()
+	backlinks, err := ShowBacklinks(tmpDir, "20250101T000000", LinksOptions{Writer: buf})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"20250103T000000--tcp__network.org"}, backlinks)
+	assert.Equal(t, "20250103T000000 | tcp\n", buf.String())
+
+	// 存在しない ID
+	_, err = ShowBacklinks(tmpDir, "20991231T000000", LinksOptions{Writer: buf})
+	assert.Error(t, err)
+}
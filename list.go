@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+	"golang.org/x/text/width"
+)
+
+// ListOptions は一覧表示操作のオプションを表す
+type ListOptions struct {
+	Writer io.Writer // 出力先
+	FilterOptions
+	QueueFilter
+	SortOptions
+	Tags     []string           // すべてのタグを持つファイルのみ対象（空の場合は制限なし）
+	Limit    int                // 表示するファイル数の上限（0 の場合は制限なし）
+	Template *template.Template // 1ファイル1行の出力テンプレート（nil の場合は列で表示）
+}
+
+// listRow は一覧表示の1行を表す
+type listRow struct {
+	file       string
+	components *parakeet.FileNameComponents
+	size       int64
+	modTime    time.Time
+}
+
+// ListFiles はディレクトリ内のフォーマット済みファイルを ID・タイトル・タグ・拡張子・サイズの列で一覧表示し、そのリストを返す
+func ListFiles(targetDir string, opts ListOptions) ([]string, error) {
+	// ディレクトリの存在チェック
+	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", targetDir)
+	}
+
+	entries, err := readDirCached(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("file", "id", "title", "tags", "ext", "size", "file")
+
+	files := []string{}
+	rows := map[string]listRow{}
+	for _, entry := range entries {
+		// ディレクトリとシムはスキップ
+		if entry.IsDir() || IsShim(targetDir, entry) {
+			continue
+		}
+
+		fileName := entry.Name()
+
+		// 絞り込み
+		if !opts.Matches(fileName) {
+			continue
+		}
+
+		// フォーマット済みファイルのみ処理
+		components, err := parseDirEntry(entry)
+		if err != nil {
+			continue
+		}
+
+		// ステータス・優先度・タグで絞り込み
+		if !opts.MatchesQueue(components.Tags) || !matchTags(components.Tags, opts.Tags, false) {
+			continue
+		}
+
+		info, err := statDirEntry(targetDir, entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get file info: %w", err)
+		}
+
+		files = append(files, fileName)
+		rows[fileName] = listRow{file: fileName, components: components, size: info.Size(), modTime: info.ModTime()}
+	}
+
+	if err := opts.SortFiles(files); err != nil {
+		return nil, err
+	}
+	if opts.Limit > 0 && len(files) > opts.Limit {
+		files = files[:opts.Limit]
+	}
+
+	if opts.Template != nil {
+		for _, fileName := range files {
+			row := rows[fileName]
+			line, err := executeOutputTemplate(opts.Template, OutputTemplateData{
+				FileNameComponents: *row.components,
+				File:               fileName,
+				Path:               filepath.Join(targetDir, fileName),
+				Size:               row.size,
+				ModTime:            row.modTime,
+			})
+			if err != nil {
+				return nil, err
+			}
+			reporter.Emit("file", row.fields(), "%s", line)
+		}
+		return files, nil
+	}
+
+	// 列の幅を揃える（全角文字は2桁として数える）
+	headers := []string{"ID", "TITLE", "TAGS", "EXT", "SIZE"}
+	cells := make([][]string, 0, len(files))
+	for _, fileName := range files {
+		row := rows[fileName]
+		cells = append(cells, []string{
+			row.components.Timestamp,
+			row.components.Comment,
+			strings.Join(row.components.Tags, ","),
+			row.components.Extension,
+			FormatBytes(row.size),
+		})
+	}
+	widths := make([]int, len(headers))
+	for _, line := range append([][]string{headers}, cells...) {
+		for i, cell := range line {
+			widths[i] = max(widths[i], displayWidth(cell))
+		}
+	}
+
+	if len(files) > 0 {
+		reporter.Dataf("%s\n", formatListLine(headers, widths))
+	}
+	for i, fileName := range files {
+		row := rows[fileName]
+		reporter.Emit("file", row.fields(), "%s\n", formatListLine(cells[i], widths))
+	}
+
+	return files, nil
+}
+
+// fields は Reporter に渡すレコードのフィールドを返す
+// タグのないファイルも tags を空の配列として出力する
+func (r listRow) fields() map[string]any {
+	tags := r.components.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return map[string]any{
+		"file":  r.file,
+		"id":    r.components.Timestamp,
+		"title": r.components.Comment,
+		"tags":  tags,
+		"ext":   r.components.Extension,
+		"size":  r.size,
+	}
+}
+
+// formatListLine は列を幅に合わせて空白で揃えた1行にする（最後の列のサイズは右寄せ）
+func formatListLine(cells []string, widths []int) string {
+	parts := make([]string, len(cells))
+	for i, cell := range cells {
+		padding := strings.Repeat(" ", widths[i]-displayWidth(cell))
+		if i == len(cells)-1 {
+			parts[i] = padding + cell
+		} else {
+			parts[i] = cell + padding
+		}
+	}
+	return strings.Join(parts, "  ")
+}
+
+// displayWidth は端末に表示したときの文字列の幅を返す（全角文字は2桁）
+func displayWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		switch width.LookupRune(r).Kind() {
+		case width.EastAsianWide, width.EastAsianFullwidth:
+			n += 2
+		default:
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListFiles(t *testing.T) {
+	t.Parallel()
+	// Create temporary directory
+	tmpDir, err := os.MkdirTemp("", "parakeet-list-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	testFiles := []string{
+		"20250831T120000--august.pdf",
+		"20250903T083109--september.pdf",
+		"20251001T000000--october.md",
+		"invalid-file.pdf",
+	}
+	for _, name := range testFiles {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("test content"), 0644))
+	}
+
+	tests := []struct {
+		name     string
+		filter   FilterOptions
+		expected []string
+	}{
+		{
+			name:     "all formatted files",
+			filter:   FilterOptions{},
+			expected: testFiles[:3],
+		},
+		{
+			name:     "extension filter",
+			filter:   FilterOptions{Extensions: []string{"pdf"}},
+			expected: testFiles[:2],
+		},
+		{
+			name: "date range",
+			filter: FilterOptions{
+				Since: time.Date(2025, 9, 1, 0, 0, 0, 0, time.Local),
+				Until: time.Date(2025, 9, 30, 23, 59, 59, 0, time.Local),
+			},
+			expected: []string{testFiles[1]},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			buf := &bytes.Buffer{}
+			files, err := ListFiles(tmpDir, ListOptions{Writer: buf, FilterOptions: tt.filter})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, files)
+			assert.NotContains(t, buf.String(), "invalid-file")
+		})
+	}
+}
+
+func TestListFiles_JSONOutput(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-list-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250903T083109--memo.md"), []byte("memo"), 0644))
+
+	var buf bytes.Buffer
+	reporter, err := NewReporter(&buf, ReporterConfig{Format: FormatJSON})
+	require.NoError(t, err)
+	_, err = ListFiles(tmpDir, ListOptions{Writer: reporter})
+	require.NoError(t, err)
+	require.NoError(t, reporter.Flush())
+
+	// タグのないファイルは空の配列
+	assert.Contains(t, buf.String(), `"tags": []`)
+}
+
+func TestListFiles_NonExistentDirectory(t *testing.T) {
+	t.Parallel()
+	_, err := ListFiles("/non/existent", ListOptions{Writer: &bytes.Buffer{}})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "directory does not exist")
+}
+
+func TestListFiles_Columns(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-list-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250831T120000--入門__network.pdf"), []byte("1234"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250903T083109--notes__network_todo.md"), []byte("12"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20251001T000000--report.txt"), make([]byte, 2048), 0644))
+
+	buf := &bytes.Buffer{}
+	files, err := ListFiles(tmpDir, ListOptions{Writer: buf})
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	assert.Equal(t, []string{
+		"ID               TITLE   TAGS          EXT    SIZE",
+		"20250831T120000  入門    network       pdf      4B",
+		"20250903T083109  notes   network,todo  md       2B",
+		"20251001T000000  report                txt  2.0KiB",
+	}, lines)
+}
+
+func TestListFiles_SortTagLimit(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-list-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	testFiles := []string{
+		"20250831T120000--august__work.pdf",
+		"20250903T083109--september__work.md",
+		"20251001T000000--october.txt",
+	}
+	for _, name := range testFiles {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("test"), 0644))
+	}
+
+	tests := []struct {
+		name     string
+		opts     ListOptions
+		expected []string
+	}{
+		{
+			name:     "sort by ext",
+			opts:     ListOptions{SortOptions: SortOptions{SortBy: SortByExt}},
+			expected: []string{testFiles[1], testFiles[0], testFiles[2]},
+		},
+		{
+			name:     "reverse timestamp",
+			opts:     ListOptions{SortOptions: SortOptions{SortBy: SortByTimestamp, Reverse: true}},
+			expected: []string{testFiles[2], testFiles[1], testFiles[0]},
+		},
+		{
+			name:     "tag",
+			opts:     ListOptions{Tags: []string{"work"}},
+			expected: testFiles[:2],
+		},
+		{
+			name:     "limit after sort",
+			opts:     ListOptions{SortOptions: SortOptions{Reverse: true}, Limit: 1},
+			expected: []string{testFiles[2]},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tt.opts.Writer = &bytes.Buffer{}
+			files, err := ListFiles(tmpDir, tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, files)
+		})
+	}
+}
+
+func TestListFiles_Quiet(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250903T083109--memo.md"), []byte("memo"), 0644))
+
+	// --quiet でもヘッダーは出力する
+	buf := &bytes.Buffer{}
+	reporter, err := NewReporter(buf, ReporterConfig{Format: FormatHuman, Level: LevelQuiet})
+	require.NoError(t, err)
+	_, err = ListFiles(tmpDir, ListOptions{Writer: reporter})
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "ID"))
+	assert.Contains(t, lines[1], "20250903T083109")
+}
@@ -3,10 +3,16 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"text/template"
 
+	"github.com/kijimaD/parakeet/pkg/parakeet"
 	"github.com/urfave/cli/v3"
 )
 
@@ -14,157 +20,3190 @@ func main() {
 	cmd := &cli.Command{
 		Name:  "parakeet",
 		Usage: "タイムスタンプベースのフォーマットでファイル名を管理するツール",
-		Commands: []*cli.Command{
-			{
-				Name:  "generate",
-				Usage: "ディレクトリ内のファイルにタイムスタンプ付きのフォーマット済みファイル名を生成する",
-				Flags: []cli.Flag{
-					&cli.StringSliceFlag{
-						Name:    "ext",
-						Aliases: []string{"e"},
-						Usage:   "対象拡張子（カンマ区切り、例: pdf,txt,md）",
-					},
-				},
-				Action: func(_ context.Context, cmd *cli.Command) error {
-					// 拡張子指定は必須
-					extensions := cmd.StringSlice("ext")
-					if len(extensions) == 0 {
-						return fmt.Errorf("--ext flag is required: specify at least one file extension (e.g., --ext pdf --ext txt)")
-					}
-
-					// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
-					targetDir := "."
-					if cmd.Args().Len() > 0 {
-						targetDir = cmd.Args().Get(0)
-					}
-
-					opts := RenameOptions{
-						Writer:     os.Stdout,
-						Extensions: extensions,
-					}
-
-					return GenerateFileNames(targetDir, opts)
-				},
-			},
-			{
-				Name:  "validate",
-				Usage: "ディレクトリ内のファイル名が正しいフォーマットかをチェックする",
-				Flags: []cli.Flag{
-					&cli.StringSliceFlag{
-						Name:    "ext",
-						Aliases: []string{"e"},
-						Usage:   "対象拡張子（カンマ区切り、例: pdf,txt,md）",
-					},
-				},
-				Action: func(_ context.Context, cmd *cli.Command) error {
-					// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
-					targetDir := "."
-					if cmd.Args().Len() > 0 {
-						targetDir = cmd.Args().Get(0)
-					}
-
-					opts := ValidateOptions{
-						Writer:     os.Stdout,
-						Extensions: cmd.StringSlice("ext"),
-					}
-
-					result, err := ValidateFileNames(targetDir, opts)
-					if err != nil {
-						return err
-					}
-
-					// 無効なファイル、重複、未定義タグがある場合は終了コード1を返す
-					if len(result.InvalidFiles) > 0 || result.HasDuplicates || result.HasUndefinedTags {
-						os.Exit(1)
-					}
-
-					return nil
-				},
-			},
-			{
-				Name:  "md",
-				Usage: "ディレクトリ内のファイル一覧をMarkdown表形式で出力する",
-				Flags: []cli.Flag{
-					&cli.StringSliceFlag{
-						Name:    "ext",
-						Aliases: []string{"e"},
-						Usage:   "対象拡張子（カンマ区切り、例: pdf,txt,md）",
-					},
-				},
-				Action: func(_ context.Context, cmd *cli.Command) error {
-					// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
-					targetDir := "."
-					if cmd.Args().Len() > 0 {
-						targetDir = cmd.Args().Get(0)
-					}
-
-					opts := MarkdownOptions{
-						Writer:     os.Stdout,
-						Extensions: cmd.StringSlice("ext"),
-					}
-
-					return GenerateMarkdownTable(targetDir, opts)
-				},
-			},
-			{
-				Name:      "tag",
-				Usage:     "ファイルのタグをインタラクティブに編集する",
-				ArgsUsage: "<id>",
-				Flags: []cli.Flag{
-					&cli.BoolFlag{
-						Name:    "show",
-						Aliases: []string{"s"},
-						Usage:   "現在のタグを表示する",
-					},
-					&cli.StringSliceFlag{
-						Name:    "set",
-						Aliases: []string{"t"},
-						Usage:   "タグを直接指定する（カンマ区切り、例: --set tag1 --set tag2）",
-					},
-				},
-				Action: func(_ context.Context, cmd *cli.Command) error {
-					// IDを取得
-					if cmd.Args().Len() == 0 {
-						return fmt.Errorf("ID is required")
-					}
-					id := cmd.Args().Get(0)
-
-					// IDでファイルを検索
-					filePath, err := FindFileByID(".", id)
-					if err != nil {
-						return fmt.Errorf("file not found: %w", err)
-					}
-
-					// --show フラグの場合はタグを表示
-					if cmd.Bool("show") {
-						return ShowTags(filePath, os.Stdout)
-					}
-
-					// --set フラグが指定された場合は非インタラクティブモード
-					if setTags := cmd.StringSlice("set"); len(setTags) > 0 {
-						// tags.tomlに対してバリデーション
-						if err := ValidateTags(setTags, TagsFileName); err != nil {
-							return err
-						}
-
-						// タグを設定
-						return SetTags(filePath, setTags, os.Stdout)
-					}
-
-					// デフォルトはインタラクティブモード
-					opts := TagOptions{
-						Interactive: true,
-						Writer:      os.Stdout,
-					}
-
-					return EditTags(filePath, opts)
-				},
+		Flags: append(outputFlags(), tagsFileFlag(),
+			&cli.BoolFlag{
+				Name:  "hooks",
+				Usage: "リネームの前後のフック（.parakeet/hooks/, parakeet.toml の [hooks]）を実行する（信頼できるディレクトリでのみ指定する）",
 			},
+		),
+		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			reporter, err := reporterFromCommand(cmd)
+			if err != nil {
+				return ctx, err
+			}
+			// 別のファイルシステムへの移動でコピーする大きなファイルの進捗は端末にのみ表示する
+			if IsTerminal(os.Stderr) {
+				RenameProgress = os.Stderr
+			}
+			// フックは任意のコマンドを実行するため、--hooks を指定した場合のみ実行する
+			// フックの出力は標準エラー出力に出して、結果の出力と混ざらないようにする
+			if cmd.Bool("hooks") {
+				ctx = WithHookRunner(ctx, NewHookRunner(os.Stderr))
+			}
+			return WithReporter(ctx, reporter), nil
+		},
+		After: func(ctx context.Context, _ *cli.Command) error {
+			return ReporterFromContext(ctx).Flush()
 		},
+		// 終了コードを持つエラーも After で出力を書き出してから終了する
+		ExitErrHandler: func(context.Context, *cli.Command, error) {},
+		Commands:       commands(),
+		// サブコマンド・フラグに加えてタグとIDを補完する（parakeet completion bash|zsh|fish）
+		EnableShellCompletion:           true,
+		ShellComplete:                   completeRoot,
+		ConfigureShellCompletionCommand: configureCompletionCommand,
 	}
 
 	if err := cmd.Run(context.Background(), os.Args); err != nil {
+		var exitErr cli.ExitCoder
+		if errors.As(err, &exitErr) {
+			if err.Error() != "" {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+			os.Exit(exitErr.ExitCode())
+		}
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		log.Fatal(err)
 	}
 }
+
+// errChecksFailed は検査で問題が見つかったことを終了コード1で知らせるエラー（メッセージは出力済み）
+var errChecksFailed = cli.Exit("", 1)
+
+// errWarningsFound は警告のみの問題が見つかったことを終了コード2で知らせるエラー（メッセージは出力済み）
+var errWarningsFound = cli.Exit("", 2)
+
+// commandGroup は名詞でまとめたコマンドのグループを表す
+type commandGroup struct {
+	name     string                // グループ名（parakeet <name> <verb>）
+	usage    string                // グループの説明
+	commands []func() *cli.Command // グループに含めるコマンド
+	flat     bool                  // 従来のフラットなコマンド名でも実行できるようにするかどうか
+}
+
+// commandGroups はコマンドのグループ一覧
+var commandGroups = []commandGroup{
+	{
+		name:     "file",
+		usage:    "ファイル名の生成・検証・編集",
+		commands: []func() *cli.Command{generateCommand, validateCommand, doctorCommand, fixCommand, dedupCommand, newCommand, retitleCommand, retimeCommand, mvCommand, archiveCommand, flattenCommand, applyCommand, applyMapCommand, openCommand, pathCommand, tagCommand, cleanShimsCommand, historyCommand, undoCommand},
+		flat:     true,
+	},
+	{
+		name:     "catalog",
+		usage:    "ファイルの一覧・検索・インデックス出力",
+		commands: []func() *cli.Command{listCommand, searchCommand, grepCommand, linksCommand, backlinksCommand, nextCommand, statsCommand, relatedCommand, graphCommand, mdCommand, indexCommand, reindexCommand, verifyCommand, verifyLinksCommand, mcpCommand},
+		flat:     true,
+	},
+	{
+		name:     "dir",
+		usage:    "管理ディレクトリ間の比較・同期・バックアップ",
+		commands: []func() *cli.Command{diffCommand, syncCommand, exportCommand, importCommand},
+		flat:     true,
+	},
+	{
+		name:     "tagdef",
+		usage:    "タグ定義（tags.toml）の操作",
+		commands: []func() *cli.Command{tagdefListCommand, tagdefSummaryCommand},
+	},
+	{
+		name:     "frontmatter",
+		usage:    "Markdownファイルの frontmatter（id, title, tags, date）とファイル名の同期",
+		commands: []func() *cli.Command{frontmatterSyncCommand},
+	},
+	{
+		name:     "hook",
+		usage:    "git のフックでファイル名を検証する",
+		commands: []func() *cli.Command{hookInstallCommand},
+	},
+	{
+		name:     "complete",
+		usage:    "エディタ・シェル補完向けにタグとIDの候補を出力する",
+		commands: []func() *cli.Command{completeTagsCommand, completeIDsCommand},
+	},
+}
+
+// commands はルートに登録するコマンドを返す
+// グループ化したコマンド（parakeet file tag など）に加えて、
+// 従来のフラットなコマンド名（parakeet tag など）を別名としてヘルプに表示せずに登録する
+func commands() []*cli.Command {
+	var groups, flat []*cli.Command
+	for _, group := range commandGroups {
+		subcommands := make([]*cli.Command, 0, len(group.commands))
+		for _, build := range group.commands {
+			subcommands = append(subcommands, build())
+
+			if group.flat {
+				cmd := build()
+				cmd.Hidden = true
+				flat = append(flat, cmd)
+			}
+		}
+
+		groups = append(groups, &cli.Command{
+			Name:     group.name,
+			Usage:    group.usage,
+			Commands: subcommands,
+		})
+	}
+
+	return append(groups, flat...)
+}
+
+// generateCommand は generate コマンドを返す
+func generateCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "generate",
+		Usage: "ディレクトリ内のファイルにタイムスタンプ付きのフォーマット済みファイル名を生成する",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:    "ext",
+				Aliases: []string{"e"},
+				Usage:   "対象拡張子（カンマ区切り、例: pdf,txt,md）",
+			},
+			&cli.StringFlag{
+				Name:  "throttle",
+				Usage: "ファイル操作の速度制限（例: 50/s, 600/m）",
+			},
+			&cli.BoolFlag{
+				Name:  "from-mtime",
+				Usage: "現在時刻の代わりにファイルの更新日時からタイムスタンプを生成する",
+			},
+			&cli.IntFlag{
+				Name:  "max-name-bytes",
+				Usage: "ファイル名の長さの上限（バイト数、parakeet.toml の設定より優先、デフォルトは 255）",
+			},
+			&cli.StringFlag{
+				Name:  "name-budget",
+				Usage: "ファイル名が上限を超えた場合の短縮方法（truncate, drop-tags, abbreviate, prompt, error、parakeet.toml の設定より優先）",
+			},
+			&cli.BoolFlag{
+				Name:    "interactive",
+				Aliases: []string{"i"},
+				Usage:   "ファイルごとにコメントとタグを入力する",
+			},
+			&cli.BoolFlag{
+				Name:  "confirm",
+				Usage: "リネームの前にファイルごとに確認する（y: リネーム, n: スキップ, a: 残りもすべてリネーム, q: 終了）",
+			},
+			&cli.BoolFlag{
+				Name:  "skip-open",
+				Usage: "他のプロセスが書き込み用に開いているファイルをスキップする（Linux のみ）",
+			},
+			shimFlag(),
+			resumeFlag(),
+			&cli.BoolFlag{
+				Name:  "atomic",
+				Usage: "すべてのリネームを確認してからまとめて実行し、途中で失敗した場合は実行済みのリネームを元に戻す",
+			},
+			&cli.BoolFlag{
+				Name:  "git",
+				Usage: "git で管理しているファイルは git mv でリネームし、.gitignore で無視しているファイルを対象外にする",
+			},
+			&cli.StringFlag{
+				Name:  "plan",
+				Usage: "リネームせずに計画をJSONファイルに書き出す（parakeet apply で実行する）",
+			},
+			portableFlag(),
+			jobsFlag(),
+			&cli.StringFlag{
+				Name:  "precision",
+				Usage: "タイムスタンプの精度（second, millisecond: 20250903T083109,123 のようにミリ秒まで含め、1秒に多数のファイルがある場合も実際の時刻に近いタイムスタンプにする。parakeet.toml の timestamp_precision より優先）",
+			},
+			&cli.BoolFlag{
+				Name:  "legacy",
+				Usage: "旧命名規則のファイル名（2023-01-15 report.pdf, report_v2_final.pdf など）から日付とタイトルを取り出す",
+			},
+			duplicatePolicyFlag(),
+			excludeFlag(),
+			includeMetadataFlag(),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 0 {
+				targetDir = cmd.Args().Get(0)
+			}
+
+			throttle, err := ParseThrottle(cmd.String("throttle"))
+			if err != nil {
+				return err
+			}
+
+			// 設定ファイルからプロファイルを読み込む
+			config, err := LoadConfig(filepath.Join(targetDir, ConfigFileName))
+			if err != nil {
+				return err
+			}
+
+			// 拡張子指定またはプロファイルは必須
+			extensions := cmd.StringSlice("ext")
+			if len(extensions) == 0 && len(config.Profile) == 0 {
+				return fmt.Errorf("--ext flag is required: specify at least one file extension (e.g., --ext pdf --ext txt)")
+			}
+
+			policy, err := duplicatePolicyFromCommand(cmd, config)
+			if err != nil {
+				return err
+			}
+
+			budget, err := nameBudgetFromCommand(cmd, config)
+			if err != nil {
+				return err
+			}
+
+			exclude, err := excludeFromCommand(cmd, targetDir)
+			if err != nil {
+				return err
+			}
+
+			var fsys FileSystem
+			if cmd.Bool("git") {
+				if fsys, err = NewGitFileSystem(targetDir); err != nil {
+					return err
+				}
+				ignored, err := GitIgnoreMatcher(targetDir)
+				if err != nil {
+					return err
+				}
+				exclude = exclude.Append(ignored)
+			}
+
+			opts := RenameOptions{
+				Writer:          stdout,
+				Hooks:           HookRunnerFromContext(ctx),
+				FilterOptions:   FilterOptions{Extensions: extensions, Exclude: exclude, IncludeMetadata: cmd.Bool("include-metadata")},
+				Profiles:        config.Profiles(),
+				Throttle:        throttle,
+				DuplicatePolicy: policy,
+				TagsFile:        tagsFileFromConfig(cmd, config),
+				FromMtime:       cmd.Bool("from-mtime"),
+				NameBudget:      budget,
+				Sanitizer:       config.Sanitize,
+				SkipOpen:        cmd.Bool("skip-open"),
+				Shims:           cmd.Bool("shim"),
+				FS:              fsys,
+				Atomic:          cmd.Bool("atomic"),
+				Journal:         NewJournal(targetDir, "generate"),
+				Vault:           obsidianVaultFor(targetDir, stdout),
+				Portable:        portableFromCommand(cmd, config),
+			}
+			if opts.Precision, err = precisionFromCommand(cmd, config); err != nil {
+				return err
+			}
+			if opts.Jobs, err = jobsFromCommand(cmd); err != nil {
+				return err
+			}
+			if opts.Resume, err = resumeFromCommand(cmd, targetDir, "generate"); err != nil {
+				return err
+			}
+			if cmd.Bool("legacy") {
+				opts.Legacy, err = NewLegacyMatcher(append(append([]LegacyRecognizer{}, config.Legacy...), DefaultLegacyRecognizers...))
+				if err != nil {
+					return err
+				}
+			}
+			if cmd.Bool("interactive") {
+				opts.Prompt, err = NewGeneratePrompt(targetDir, opts.TagsFile, opts.Sanitizer)
+				if err != nil {
+					return err
+				}
+			}
+			if cmd.Bool("confirm") {
+				// 質問は標準エラー出力に出して、リネームの結果と混ざらないようにする
+				opts.Confirm = NewConfirmPrompt(os.Stdin, os.Stderr)
+			}
+
+			planPath := cmd.String("plan")
+			if planPath != "" {
+				if opts.Plan, err = NewRenamePlan(targetDir, "generate"); err != nil {
+					return err
+				}
+			}
+
+			// Ctrl-C では処理中のファイルを終えてから中断し、チェックポイントを書き出す
+			ctx, stop := notifyInterrupt(ctx)
+			defer stop()
+
+			if err := GenerateFileNames(ctx, targetDir, opts); err != nil {
+				return err
+			}
+
+			if opts.Plan != nil {
+				if err := WriteRenamePlan(planPath, opts.Plan); err != nil {
+					return err
+				}
+				stdout.Successf("Plan written: %s (%d renames)\n", planPath, len(opts.Plan.Renames))
+			}
+			return nil
+		},
+	}
+}
+
+// validateCommand は validate コマンドを返す
+func validateCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "validate",
+		Usage: "ディレクトリ内のファイル名が正しいフォーマットかをチェックする",
+		Flags: append(filterFlags(),
+			&cli.FloatFlag{
+				Name:  "max-undefined-percent",
+				Usage: "未定義タグを持つファイルの割合の上限（%、parakeet.toml の設定より優先）",
+			},
+			&cli.IntFlag{
+				Name:  "max-undefined-tags",
+				Usage: "異なる未定義タグの数の上限（parakeet.toml の設定より優先）",
+			},
+			&cli.BoolFlag{
+				Name:  "stdin",
+				Usage: "標準入力から1行に1つのパスを読み込み、そのファイルのみ検証する",
+			},
+			&cli.BoolFlag{
+				Name:  "staged",
+				Usage: "git のインデックスに登録したファイル（コミットするファイル）のみ検証する",
+			},
+			&cli.StringFlag{
+				Name:  "baseline",
+				Usage: "以前の結果（JSON）と比較し、新しく増えた問題がある場合のみ失敗する",
+			},
+			&cli.BoolFlag{
+				Name:  "update-baseline",
+				Usage: "現在の結果を --baseline のファイルに書き出す",
+			},
+			&cli.BoolFlag{
+				Name:  "strict",
+				Usage: "重複するタイムスタンプ（duplicate_policy が warn の場合も）と未定義タグ（しきい値に関係なく）も失敗にする",
+			},
+			&cli.StringSliceFlag{
+				Name:  "enable",
+				Usage: "ルールを有効にする（" + ruleIDList() + "、parakeet.toml の [rules] より優先）",
+			},
+			&cli.StringSliceFlag{
+				Name:  "disable",
+				Usage: "ルールを無効にする（parakeet.toml の [rules] より優先）",
+			},
+			&cli.StringFlag{
+				Name:  "fail-on",
+				Usage: "終了コードを失敗にする重さ（error: error の問題で終了コード1, warning: 警告のみの場合も終了コード2）",
+				Value: string(SeverityError),
+			},
+			portableFlag(),
+			jobsFlag(),
+			duplicatePolicyFlag(),
+			excludeFlag(),
+		),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 0 {
+				targetDir = cmd.Args().Get(0)
+			}
+
+			filter, err := filterOptionsFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			if filter.Exclude, err = excludeFromCommand(cmd, targetDir); err != nil {
+				return err
+			}
+
+			// 設定ファイルから未定義タグのしきい値を読み込む
+			config, err := LoadConfig(filepath.Join(targetDir, ConfigFileName))
+			if err != nil {
+				return err
+			}
+
+			coverage := config.Validate
+			if cmd.IsSet("max-undefined-percent") {
+				percent := cmd.Float("max-undefined-percent")
+				coverage.MaxUndefinedPercent = &percent
+			}
+			if cmd.IsSet("max-undefined-tags") {
+				count := cmd.Int("max-undefined-tags")
+				coverage.MaxUndefinedTags = &count
+			}
+
+			policy, err := duplicatePolicyFromCommand(cmd, config)
+			if err != nil {
+				return err
+			}
+
+			enable := cmd.StringSlice("enable")
+			if portableFromCommand(cmd, config) {
+				enable = append(append([]string{}, enable...), PortableRules...)
+			}
+			rules, err := config.Rules.With(enable, cmd.StringSlice("disable"))
+			if err != nil {
+				return err
+			}
+
+			failOn, err := ParseSeverity(cmd.String("fail-on"))
+			if err != nil || failOn == SeverityOff {
+				return fmt.Errorf("invalid --fail-on: %s (expected error or warning)", cmd.String("fail-on"))
+			}
+
+			// 検証前にベースラインを読み込む（更新する場合は不要）
+			baselinePath := cmd.String("baseline")
+			if cmd.Bool("update-baseline") && baselinePath == "" {
+				return fmt.Errorf("--update-baseline requires --baseline")
+			}
+			var baseline *ValidateBaseline
+			if baselinePath != "" && !cmd.Bool("update-baseline") {
+				baseline, err = LoadValidateBaseline(baselinePath)
+				if err != nil {
+					return err
+				}
+			}
+
+			opts := ValidateOptions{
+				Writer:          stdout,
+				FilterOptions:   filter,
+				TagCoverage:     coverage,
+				DuplicatePolicy: policy,
+				TagsFile:        tagsFileFromConfig(cmd, config),
+				Quota:           config.Quota,
+				Strict:          cmd.Bool("strict"),
+				Rules:           rules,
+				MaxNameBytes:    config.MaxNameBytes,
+				CustomRules:     config.CustomRules,
+			}
+			if opts.Jobs, err = jobsFromCommand(cmd); err != nil {
+				return err
+			}
+			if vault, ok := FindObsidianVault(targetDir); ok {
+				opts.Vault = vault
+			}
+
+			if cmd.Bool("stdin") && cmd.Bool("staged") {
+				return fmt.Errorf("--stdin and --staged cannot be used together")
+			}
+
+			var result *ValidateResult
+			if cmd.Bool("staged") {
+				paths, err := GitStagedFiles(targetDir)
+				if err != nil {
+					return err
+				}
+				result, err = ValidateFilePaths(ctx, paths, opts)
+				if err != nil {
+					return err
+				}
+			} else if cmd.Bool("stdin") {
+				paths, err := ReadPathList(os.Stdin)
+				if err != nil {
+					return err
+				}
+				result, err = ValidateFilePaths(ctx, paths, opts)
+				if err != nil {
+					return err
+				}
+			} else {
+				result, err = ValidateFileNames(ctx, targetDir, opts)
+				if err != nil {
+					return err
+				}
+			}
+
+			if cmd.Bool("update-baseline") {
+				written, err := WriteValidateBaseline(baselinePath, result)
+				if err != nil {
+					return err
+				}
+				stdout.Successf("Baseline written: %s (%d problems)\n", baselinePath, len(written.Problems))
+				return nil
+			}
+
+			// ベースラインがある場合は新しく増えた問題がある場合のみ終了コード1を返す
+			if baseline != nil {
+				if CompareBaseline(result, baseline, stdout).HasErrors() {
+					return errChecksFailed
+				}
+				return nil
+			}
+
+			// 無効なファイル、重複、しきい値を超える未定義タグがある場合は終了コード1を返す
+			if result.HasErrors() {
+				return errChecksFailed
+			}
+			// --fail-on warning の場合は警告のみでも終了コード2を返す
+			if failOn == SeverityWarning && result.HasWarnings() {
+				return errWarningsFound
+			}
+
+			return nil
+		},
+	}
+}
+
+// doctorCommand は doctor コマンドを返す
+func doctorCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "doctor",
+		Usage:     "タグ定義ファイルとファイル名の問題（重複・無効なタイムスタンプ、大文字小文字の衝突、Windows で使えない名前、長すぎるパス）を検査し、対処方法を表示する",
+		ArgsUsage: "[dir]",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:  "max-path",
+				Usage: "長すぎるとみなす絶対パスの長さ",
+				Value: DefaultMaxPathLength,
+			},
+			duplicatePolicyFlag(),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 0 {
+				targetDir = cmd.Args().Get(0)
+			}
+
+			tagsFile, err := tagsFileFromCommand(cmd, targetDir)
+			if err != nil {
+				return err
+			}
+
+			config, err := LoadConfig(filepath.Join(targetDir, ConfigFileName))
+			if err != nil {
+				return err
+			}
+
+			policy, err := duplicatePolicyFromCommand(cmd, config)
+			if err != nil {
+				return err
+			}
+
+			result, err := RunDoctor(targetDir, DoctorOptions{
+				Writer:          stdout,
+				TagsFile:        tagsFile,
+				MaxPathLength:   cmd.Int("max-path"),
+				DuplicatePolicy: policy,
+			})
+			if err != nil {
+				return err
+			}
+
+			// 修正が必要な問題がある場合は終了コード1を返す
+			if result.HasErrors() {
+				return errChecksFailed
+			}
+			return nil
+		},
+	}
+}
+
+// fixCommand は fix コマンドを返す
+func fixCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "fix",
+		Usage:     "無効なフォーマットのファイル名を正しいフォーマットに修正する",
+		ArgsUsage: "[dir]",
+		Flags: append(filterFlags(),
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Aliases: []string{"n"},
+				Usage:   "実際には変更せず、実行内容のみ表示する",
+			},
+			&cli.BoolFlag{
+				Name:    "interactive",
+				Aliases: []string{"i"},
+				Usage:   "ファイルごとに確認する",
+			},
+			resumeFlag(),
+		),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 0 {
+				targetDir = cmd.Args().Get(0)
+			}
+
+			filter, err := filterOptionsFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+
+			opts := FixOptions{
+				Writer:        stdout,
+				Hooks:         HookRunnerFromContext(ctx),
+				FilterOptions: filter,
+				DryRun:        cmd.Bool("dry-run"),
+				Journal:       NewJournal(targetDir, "fix"),
+			}
+			if cmd.Bool("interactive") {
+				opts.Confirm = ConfirmFix
+			}
+			if opts.Resume, err = resumeFromCommand(cmd, targetDir, "fix"); err != nil {
+				return err
+			}
+
+			// Ctrl-C では処理中のファイルを終えてから中断し、チェックポイントを書き出す
+			ctx, stop := notifyInterrupt(ctx)
+			defer stop()
+
+			_, err = FixFileNames(ctx, targetDir, opts)
+			return err
+		},
+	}
+}
+
+// dedupCommand は dedup コマンドを返す
+func dedupCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "dedup",
+		Usage:     "タイムスタンプが重複するファイルに新しいタイムスタンプを割り当てる",
+		ArgsUsage: "[dir]",
+		Flags: append(filterFlags(),
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Aliases: []string{"n"},
+				Usage:   "実際には変更せず、実行内容のみ表示する",
+			},
+			duplicatePolicyFlag(),
+			&cli.StringFlag{
+				Name:  "precision",
+				Usage: "新しく割り当てるタイムスタンプの精度（second, millisecond、parakeet.toml の timestamp_precision より優先）",
+			},
+		),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 0 {
+				targetDir = cmd.Args().Get(0)
+			}
+
+			filter, err := filterOptionsFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+
+			config, err := LoadConfig(filepath.Join(targetDir, ConfigFileName))
+			if err != nil {
+				return err
+			}
+
+			policy, err := duplicatePolicyFromCommand(cmd, config)
+			if err != nil {
+				return err
+			}
+
+			opts := DedupOptions{
+				Writer:          stdout,
+				Hooks:           HookRunnerFromContext(ctx),
+				FilterOptions:   filter,
+				DryRun:          cmd.Bool("dry-run"),
+				DuplicatePolicy: policy,
+				Journal:         NewJournal(targetDir, "dedup"),
+			}
+			if opts.Precision, err = precisionFromCommand(cmd, config); err != nil {
+				return err
+			}
+
+			_, err = DeduplicateTimestamps(targetDir, opts)
+			return err
+		},
+	}
+}
+
+// archiveCommand は archive コマンドを返す
+func archiveCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "archive",
+		Usage:     "フォーマット済みのファイルをタイムスタンプの年（2024/）または年月（2024/06/）のサブディレクトリに移動する",
+		ArgsUsage: "[dir]",
+		Flags: append(filterFlags(),
+			&cli.StringFlag{
+				Name:  "by",
+				Usage: "サブディレクトリの単位（year, month）",
+				Value: string(ArchiveByYear),
+			},
+			&cli.StringFlag{
+				Name:  "older-than",
+				Usage: "この期間・日付より古いファイルのみ移動する（例: 1y, 6m, 2024-01-01）",
+			},
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Aliases: []string{"n"},
+				Usage:   "実際には移動せず、実行内容のみ表示する",
+			},
+		),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 0 {
+				targetDir = cmd.Args().Get(0)
+			}
+
+			filter, err := filterOptionsFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+
+			by, err := ParseArchiveBy(cmd.String("by"))
+			if err != nil {
+				return err
+			}
+			olderThan, err := ParseDateBound(cmd.String("older-than"), false)
+			if err != nil {
+				return fmt.Errorf("invalid --older-than: %w", err)
+			}
+
+			opts := ArchiveOptions{
+				Writer:        stdout,
+				Hooks:         HookRunnerFromContext(ctx),
+				FilterOptions: filter,
+				By:            by,
+				OlderThan:     olderThan,
+				DryRun:        cmd.Bool("dry-run"),
+				Journal:       NewJournal(targetDir, "archive"),
+			}
+
+			_, err = ArchiveFiles(targetDir, opts)
+			return err
+		},
+	}
+}
+
+// flattenCommand は flatten コマンドを返す
+func flattenCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "flatten",
+		Usage:     "サブディレクトリのフォーマット済みのファイルをディレクトリ直下に移動する（タイムスタンプが重複する場合は新しいタイムスタンプを割り当てる）",
+		ArgsUsage: "[dir]",
+		Flags: append(filterFlags(),
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Aliases: []string{"n"},
+				Usage:   "実際には移動せず、実行内容のみ表示する",
+			},
+		),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 0 {
+				targetDir = cmd.Args().Get(0)
+			}
+
+			filter, err := filterOptionsFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+
+			opts := FlattenOptions{
+				Writer:        stdout,
+				Hooks:         HookRunnerFromContext(ctx),
+				FilterOptions: filter,
+				DryRun:        cmd.Bool("dry-run"),
+				Journal:       NewJournal(targetDir, "flatten"),
+			}
+
+			_, err = FlattenFiles(targetDir, opts)
+			return err
+		},
+	}
+}
+
+// applyCommand は apply コマンドを返す
+func applyCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "apply",
+		Usage:     "generate --plan で書き出した計画のリネームを実行する（計画のあとに変更されたファイルがある場合は実行しない）",
+		ArgsUsage: "<plan.json>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Aliases: []string{"n"},
+				Usage:   "実際にはリネームせず、実行内容のみ表示する",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			if cmd.Args().Len() < 1 {
+				return fmt.Errorf("plan file is required")
+			}
+
+			plan, err := LoadRenamePlan(cmd.Args().Get(0))
+			if err != nil {
+				return err
+			}
+
+			opts := ApplyOptions{
+				Writer:  stdout,
+				Hooks:   HookRunnerFromContext(ctx),
+				DryRun:  cmd.Bool("dry-run"),
+				Journal: NewJournal(plan.Dir, plan.Command),
+				Vault:   obsidianVaultFor(plan.Dir, stdout),
+			}
+
+			_, err = ApplyRenamePlan(plan, opts)
+			return err
+		},
+	}
+}
+
+// applyMapCommand は apply-map コマンドを返す
+func applyMapCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "apply-map",
+		Usage:     "旧ファイル名と新しいコメント・タグの対応表（CSV: old_name,new_comment,tags または JSON）に従って一括でリネームする",
+		ArgsUsage: "<mapping.csv|mapping.json> [dir]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Aliases: []string{"n"},
+				Usage:   "実際にはリネームせず、実行内容のみ表示する",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			if cmd.Args().Len() < 1 {
+				return fmt.Errorf("mapping file is required")
+			}
+
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 1 {
+				targetDir = cmd.Args().Get(1)
+			}
+
+			mappings, err := ReadNameMapping(cmd.Args().Get(0))
+			if err != nil {
+				return err
+			}
+
+			config, err := LoadConfig(filepath.Join(targetDir, ConfigFileName))
+			if err != nil {
+				return err
+			}
+
+			opts := ApplyMapOptions{
+				Writer:    stdout,
+				Hooks:     HookRunnerFromContext(ctx),
+				DryRun:    cmd.Bool("dry-run"),
+				TagsFile:  tagsFileFromConfig(cmd, config),
+				Sanitizer: config.Sanitize,
+				Journal:   NewJournal(targetDir, "apply-map"),
+				Vault:     obsidianVaultFor(targetDir, stdout),
+			}
+
+			_, err = ApplyNameMapping(targetDir, mappings, opts)
+			return err
+		},
+	}
+}
+
+// mdCommand は md コマンドを返す
+func mdCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "md",
+		Usage: "ディレクトリ内のファイル一覧をMarkdown表形式で出力する",
+		Flags: append(append(append(filterFlags(), sortFlags()...), markdownLayoutFlags()...),
+			excludeFlag(),
+			queryFlag(),
+			&cli.StringFlag{
+				Name:  "update",
+				Usage: "出力せず、既存のファイル（README.md など）の <!-- parakeet:start --> と <!-- parakeet:end --> の間を書き換える",
+			},
+			&cli.StringFlag{
+				Name:  "group-by",
+				Usage: "見出しごとに表を分ける（tag, month）",
+			},
+		),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 0 {
+				targetDir = cmd.Args().Get(0)
+			}
+
+			filter, err := filterOptionsFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			if filter.Exclude, err = excludeFromCommand(cmd, targetDir); err != nil {
+				return err
+			}
+			if filter.Query, err = queryFromCommand(cmd); err != nil {
+				return err
+			}
+
+			opts := MarkdownOptions{
+				Writer:         stdout,
+				FilterOptions:  filter,
+				SortOptions:    sortOptionsFromCommand(cmd),
+				MarkdownLayout: markdownLayoutFromCommand(cmd),
+				GroupBy:        cmd.String("group-by"),
+			}
+
+			if path := cmd.String("update"); path != "" {
+				return UpdateMarkdownFile(targetDir, path, opts)
+			}
+
+			return GenerateMarkdownTable(targetDir, opts)
+		},
+	}
+}
+
+// listCommand は list コマンドを返す
+func listCommand() *cli.Command {
+	return &cli.Command{
+		Name:          "list",
+		Usage:         "フォーマット済みファイルをID・タイトル・タグ・拡張子・サイズの列で一覧表示する",
+		ArgsUsage:     "[dir]",
+		ShellComplete: shellComplete(completionSpec{tagFlags: []string{"tag", "t"}}),
+		Flags: append(append(append(filterFlags(), queueFlags()...), sortFlags()...),
+			&cli.StringSliceFlag{
+				Name:    "tag",
+				Aliases: []string{"t"},
+				Usage:   "このタグを持つファイルのみ対象（複数指定時はすべてに一致）",
+			},
+			&cli.IntFlag{
+				Name:  "limit",
+				Usage: "表示するファイル数の上限（0 の場合は制限なし）",
+			},
+			templateFlag(),
+			queryFlag(),
+		),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 0 {
+				targetDir = cmd.Args().Get(0)
+			}
+
+			filter, err := filterOptionsFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+
+			queue, err := ParseQueueFilter(cmd.String("status"), cmd.String("priority"))
+			if err != nil {
+				return err
+			}
+
+			if filter.Query, err = queryFromCommand(cmd); err != nil {
+				return err
+			}
+
+			tmpl, err := templateFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+
+			opts := ListOptions{
+				Writer:        stdout,
+				FilterOptions: filter,
+				QueueFilter:   queue,
+				SortOptions:   sortOptionsFromCommand(cmd),
+				Tags:          cmd.StringSlice("tag"),
+				Limit:         cmd.Int("limit"),
+				Template:      tmpl,
+			}
+
+			_, err = ListFiles(targetDir, opts)
+			return err
+		},
+	}
+}
+
+// grepCommand は grep コマンドを返す
+func grepCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "grep",
+		Usage:     "フォーマット済みのテキストファイル（md, txt, org）の内容を検索し、ID・タイトル・一致した行を出力する",
+		ArgsUsage: "<pattern> [dir]",
+		Flags: append(append(filterFlags(), sortFlags()...),
+			&cli.BoolFlag{
+				Name:  "regex",
+				Usage: "pattern を正規表現として扱う",
+			},
+			&cli.BoolFlag{
+				Name:    "ignore-case",
+				Aliases: []string{"i"},
+				Usage:   "大文字小文字を区別しない",
+			},
+		),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			if cmd.Args().Len() < 1 {
+				return fmt.Errorf("pattern is required")
+			}
+			pattern := cmd.Args().Get(0)
+
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 1 {
+				targetDir = cmd.Args().Get(1)
+			}
+
+			filter, err := filterOptionsFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+
+			opts := GrepOptions{
+				Writer:        stdout,
+				FilterOptions: filter,
+				SortOptions:   sortOptionsFromCommand(cmd),
+				Regex:         cmd.Bool("regex"),
+				IgnoreCase:    cmd.Bool("ignore-case"),
+			}
+
+			matches, err := GrepFiles(targetDir, pattern, opts)
+			if err != nil {
+				return err
+			}
+			// grep と同じく、一致しない場合はスクリプトで判別できるように終了コード1にする
+			if len(matches) == 0 {
+				return cli.Exit("", 1)
+			}
+			return nil
+		},
+	}
+}
+
+// linksCommand は links コマンドを返す
+func linksCommand() *cli.Command {
+	return linkNeighborhoodCommand("links", "IDで指定したファイル（md, org）の本文が参照している他のファイルのIDとタイトルを出力する", ShowLinks)
+}
+
+// backlinksCommand は backlinks コマンドを返す
+func backlinksCommand() *cli.Command {
+	return linkNeighborhoodCommand("backlinks", "IDで指定したファイルを本文（md, org）で参照しているファイルのIDとタイトルを出力する", ShowBacklinks)
+}
+
+// linkNeighborhoodCommand はファイルのリンク先・リンク元を出力するコマンドを返す
+func linkNeighborhoodCommand(name, usage string, show func(targetDir, id string, opts LinksOptions) ([]string, error)) *cli.Command {
+	return &cli.Command{
+		Name:          name,
+		Usage:         usage,
+		ArgsUsage:     "<id> [dir]",
+		ShellComplete: shellComplete(completionSpec{args: []completionKind{completeID}}),
+		Flags:         filterFlags(),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			if cmd.Args().Len() < 1 {
+				return fmt.Errorf("ID is required")
+			}
+			id := cmd.Args().Get(0)
+
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 1 {
+				targetDir = cmd.Args().Get(1)
+			}
+
+			filter, err := filterOptionsFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+
+			_, err = show(targetDir, id, LinksOptions{Writer: stdout, FilterOptions: filter})
+			return err
+		},
+	}
+}
+
+// relatedCommand は related コマンドを返す
+func relatedCommand() *cli.Command {
+	return &cli.Command{
+		Name:          "related",
+		Usage:         "IDで指定したファイルとタグを共有するファイルを、共通のタグが多い順に出力する",
+		ArgsUsage:     "<id> [dir]",
+		ShellComplete: shellComplete(completionSpec{args: []completionKind{completeID}}),
+		Flags: append(filterFlags(),
+			&cli.IntFlag{
+				Name:  "limit",
+				Usage: "表示するファイル数の上限（0 の場合は制限なし）",
+				Value: DefaultRelatedLimit,
+			},
+			&cli.BoolFlag{
+				Name:  "proximity",
+				Usage: "共通のタグの数が同じファイルをタイムスタンプが近い順に並べる（デフォルトは新しい順）",
+			},
+		),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			if cmd.Args().Len() < 1 {
+				return fmt.Errorf("ID is required")
+			}
+			id := cmd.Args().Get(0)
+
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 1 {
+				targetDir = cmd.Args().Get(1)
+			}
+
+			filter, err := filterOptionsFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+
+			opts := RelatedOptions{
+				Writer:        stdout,
+				FilterOptions: filter,
+				Limit:         cmd.Int("limit"),
+				Proximity:     cmd.Bool("proximity"),
+			}
+
+			_, err = FindRelatedFiles(targetDir, id, opts)
+			return err
+		},
+	}
+}
+
+// graphCommand は graph コマンドを返す
+func graphCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "graph",
+		Usage:     "ファイルとタグをノード、タグの付与とファイル間のリンクを辺とするグラフを出力する（--format dot で Graphviz、json などでノードと辺のレコード）",
+		ArgsUsage: "[dir]",
+		Flags:     filterFlags(),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 0 {
+				targetDir = cmd.Args().Get(0)
+			}
+
+			filter, err := filterOptionsFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+
+			_, err = ExportGraph(targetDir, GraphOptions{Writer: stdout, FilterOptions: filter})
+			return err
+		},
+	}
+}
+
+// searchCommand は search コマンドを返す
+func searchCommand() *cli.Command {
+	return &cli.Command{
+		Name:          "search",
+		Usage:         "タグとタイトルでファイルを検索する",
+		ArgsUsage:     "[dir]",
+		ShellComplete: shellComplete(completionSpec{tagFlags: []string{"tag", "t"}}),
+		Flags: append(append(append(filterFlags(), queueFlags()...), sortFlags()...),
+			&cli.StringSliceFlag{
+				Name:    "tag",
+				Aliases: []string{"t"},
+				Usage:   "検索するタグ（複数指定時はすべてに一致）",
+			},
+			&cli.BoolFlag{
+				Name:  "any",
+				Usage: "複数のタグのいずれかに一致するファイルを検索する",
+			},
+			&cli.StringFlag{
+				Name:  "title",
+				Usage: "タイトルの検索文字列（大文字小文字を区別しない部分一致）",
+			},
+			&cli.BoolFlag{
+				Name:  "regex",
+				Usage: "--title を正規表現として扱う",
+			},
+		),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 0 {
+				targetDir = cmd.Args().Get(0)
+			}
+
+			filter, err := filterOptionsFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+
+			queue, err := ParseQueueFilter(cmd.String("status"), cmd.String("priority"))
+			if err != nil {
+				return err
+			}
+
+			opts := SearchOptions{
+				Writer:        stdout,
+				FilterOptions: filter,
+				QueueFilter:   queue,
+				SortOptions:   sortOptionsFromCommand(cmd),
+				Tags:          cmd.StringSlice("tag"),
+				MatchAnyTag:   cmd.Bool("any"),
+				Title:         cmd.String("title"),
+				TitleRegex:    cmd.Bool("regex"),
+			}
+
+			_, err = SearchFiles(targetDir, opts)
+			return err
+		},
+	}
+}
+
+// nextCommand は next コマンドを返す
+func nextCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "next",
+		Usage:     "完了していないファイルを優先度（p1, p2, ...）の高い順に表示する",
+		ArgsUsage: "[dir]",
+		Flags: append(filterFlags(),
+			&cli.IntFlag{
+				Name:  "limit",
+				Usage: "表示するファイル数",
+				Value: DefaultNextLimit,
+			},
+		),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 0 {
+				targetDir = cmd.Args().Get(0)
+			}
+
+			filter, err := filterOptionsFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+
+			opts := NextOptions{
+				Writer:        stdout,
+				FilterOptions: filter,
+				Limit:         cmd.Int("limit"),
+			}
+
+			_, err = NextFiles(targetDir, opts)
+			return err
+		},
+	}
+}
+
+// statsCommand は stats コマンドを返す
+func statsCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "stats",
+		Usage:     "フォーマット済みファイルの月・拡張子・タグごとの件数と容量を表示する",
+		ArgsUsage: "[dir]",
+		Flags: append(filterFlags(),
+			&cli.StringFlag{
+				Name:  "trend",
+				Usage: "増加傾向の出力形式（table, sparkline, csv）",
+				Value: TrendTable,
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "集計結果（月・拡張子・タグごとの内訳を含む）を1つの JSON オブジェクトとして出力する",
+			},
+		),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 0 {
+				targetDir = cmd.Args().Get(0)
+			}
+
+			filter, err := filterOptionsFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+
+			// 設定ファイルからディレクトリ・タグごとの上限を読み込む
+			config, err := LoadConfig(filepath.Join(targetDir, ConfigFileName))
+			if err != nil {
+				return err
+			}
+
+			opts := StatsOptions{
+				Writer:        stdout,
+				FilterOptions: filter,
+				Trend:         cmd.String("trend"),
+				Quota:         config.Quota,
+				JSON:          cmd.Bool("json"),
+			}
+
+			_, err = ShowStats(targetDir, opts)
+			return err
+		},
+	}
+}
+
+// indexCommand は index コマンドを返す
+func indexCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "index",
+		Usage: "ファイル一覧でインデックスファイル（index.md）のマーカー間を更新する",
+		Flags: append(append(append(filterFlags(), sortFlags()...), markdownLayoutFlags()...),
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "出力ファイルのパス（デフォルトは対象ディレクトリの index.md）",
+			},
+		),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 0 {
+				targetDir = cmd.Args().Get(0)
+			}
+
+			filter, err := filterOptionsFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+
+			opts := IndexOptions{
+				Writer:         stdout,
+				FilterOptions:  filter,
+				SortOptions:    sortOptionsFromCommand(cmd),
+				MarkdownLayout: markdownLayoutFromCommand(cmd),
+				Output:         cmd.String("output"),
+			}
+
+			return UpdateIndexFile(targetDir, opts)
+		},
+	}
+}
+
+// reindexCommand は reindex コマンドを返す
+func reindexCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "reindex",
+		Usage:     "list, search, md で使うディレクトリの一覧のキャッシュ（" + StateDirName + "/" + DirCacheFileName + "）を作成・更新する",
+		ArgsUsage: "[dir]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "clear",
+				Usage: "キャッシュを削除する",
+			},
+			&cli.BoolFlag{
+				Name:  "checksums",
+				Usage: "フォーマット済みのファイルのハッシュ（SHA-256）も記録し、verify で内容の変更を検出できるようにする（一度記録すると以降の reindex でも記録する）",
+			},
+			jobsFlag(),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 0 {
+				targetDir = cmd.Args().Get(0)
+			}
+
+			jobs, err := jobsFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+
+			_, err = Reindex(targetDir, ReindexOptions{Writer: stdout, Clear: cmd.Bool("clear"), Checksums: cmd.Bool("checksums"), Jobs: jobs})
+			return err
+		},
+	}
+}
+
+// verifyCommand は verify コマンドを返す
+func verifyCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "verify",
+		Usage:     "ファイルのハッシュを計算し直し、reindex --checksums で記録したハッシュと比べて内容の変更やビット腐敗を報告する",
+		ArgsUsage: "[dir]",
+		Flags:     append(filterFlags(), jobsFlag()),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 0 {
+				targetDir = cmd.Args().Get(0)
+			}
+
+			filter, err := filterOptionsFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+
+			jobs, err := jobsFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+
+			result, err := VerifyChecksums(targetDir, VerifyOptions{Writer: stdout, FilterOptions: filter, Jobs: jobs})
+			if err != nil {
+				return err
+			}
+			if result.HasErrors() {
+				return errChecksFailed
+			}
+			return nil
+		},
+	}
+}
+
+// mcpCommand は mcp コマンドを返す
+func mcpCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "mcp",
+		Usage:     "ディレクトリを Model Context Protocol のサーバー（標準入出力）として公開し、AI アシスタントから一覧・検索・メタデータの取得・タグの設定をできるようにする",
+		ArgsUsage: "[dir]",
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 0 {
+				targetDir = cmd.Args().Get(0)
+			}
+
+			tagsFile, err := tagsFileFromCommand(cmd, targetDir)
+			if err != nil {
+				return err
+			}
+
+			return ServeMCP(ctx, targetDir, os.Stdin, os.Stdout, MCPOptions{TagsFile: tagsFile, Hooks: HookRunnerFromContext(ctx)})
+		},
+	}
+}
+
+// verifyLinksCommand は verify-links コマンドを返す
+func verifyLinksCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "verify-links",
+		Usage:     "Markdownファイル（index.md など）のリンクとIDが既存のファイルに解決できるか検証する",
+		ArgsUsage: "[file]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "dir",
+				Usage: "IDを検索するディレクトリ（デフォルトはMarkdownファイルのディレクトリ）",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// 対象ファイルを取得（デフォルトはカレントディレクトリの index.md）
+			markdownPath := DefaultIndexFileName
+			if cmd.Args().Len() > 0 {
+				markdownPath = cmd.Args().Get(0)
+			}
+
+			opts := VerifyLinksOptions{
+				Writer: stdout,
+				Dir:    cmd.String("dir"),
+			}
+
+			result, err := VerifyLinks(markdownPath, opts)
+			if err != nil {
+				return err
+			}
+
+			// 解決できないリンクがある場合は終了コード1を返す
+			if result.HasDangling() {
+				return errChecksFailed
+			}
+
+			return nil
+		},
+	}
+}
+
+// diffCommand は diff コマンドを返す
+func diffCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "diff",
+		Usage:     "2つの管理ディレクトリをIDで比較する",
+		ArgsUsage: "<dirA> <dirB>",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:    "ext",
+				Aliases: []string{"e"},
+				Usage:   "対象拡張子（カンマ区切り、例: pdf,txt,md）",
+			},
+			duplicatePolicyFlag(),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			if cmd.Args().Len() < 2 {
+				return fmt.Errorf("two directories are required")
+			}
+
+			// タイムスタンプ重複の扱いは dirA の設定ファイルから読み込む
+			config, err := LoadConfig(filepath.Join(cmd.Args().Get(0), ConfigFileName))
+			if err != nil {
+				return err
+			}
+
+			policy, err := duplicatePolicyFromCommand(cmd, config)
+			if err != nil {
+				return err
+			}
+
+			opts := DiffOptions{
+				Writer:          stdout,
+				FilterOptions:   FilterOptions{Extensions: cmd.StringSlice("ext")},
+				DuplicatePolicy: policy,
+			}
+
+			result, err := CompareDirectories(cmd.Args().Get(0), cmd.Args().Get(1), opts)
+			if err != nil {
+				return err
+			}
+
+			// 差分がある場合は終了コード1を返す
+			if result.HasDifferences() {
+				return errChecksFailed
+			}
+
+			return nil
+		},
+	}
+}
+
+// syncCommand は sync コマンドを返す
+func syncCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "sync",
+		Usage: "管理ディレクトリ間をIDで一方向に同期する",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "from",
+				Usage:    "同期元ディレクトリ",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "to",
+				Usage:    "同期先ディレクトリ",
+				Required: true,
+			},
+			&cli.StringSliceFlag{
+				Name:    "ext",
+				Aliases: []string{"e"},
+				Usage:   "対象拡張子（カンマ区切り、例: pdf,txt,md）",
+			},
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Aliases: []string{"n"},
+				Usage:   "実際には変更せず、実行内容のみ表示する",
+			},
+			&cli.StringFlag{
+				Name:  "throttle",
+				Usage: "ファイル操作の速度制限（例: 50/s, 600/m）",
+			},
+			resumeFlag(),
+			duplicatePolicyFlag(),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			throttle, err := ParseThrottle(cmd.String("throttle"))
+			if err != nil {
+				return err
+			}
+
+			// タイムスタンプ重複の扱いは同期元の設定ファイルから読み込む
+			config, err := LoadConfig(filepath.Join(cmd.String("from"), ConfigFileName))
+			if err != nil {
+				return err
+			}
+
+			policy, err := duplicatePolicyFromCommand(cmd, config)
+			if err != nil {
+				return err
+			}
+
+			opts := SyncOptions{
+				Writer:          stdout,
+				Hooks:           HookRunnerFromContext(ctx),
+				FilterOptions:   FilterOptions{Extensions: cmd.StringSlice("ext")},
+				DryRun:          cmd.Bool("dry-run"),
+				DuplicatePolicy: policy,
+				Throttle:        throttle,
+				Journal:         NewJournal(cmd.String("to"), "sync"),
+			}
+			if opts.Resume, err = resumeFromCommand(cmd, cmd.String("to"), "sync"); err != nil {
+				return err
+			}
+
+			// Ctrl-C では処理中のファイルを終えてから中断し、チェックポイントを書き出す
+			ctx, stop := notifyInterrupt(ctx)
+			defer stop()
+
+			result, err := SyncDirectories(ctx, cmd.String("from"), cmd.String("to"), opts)
+			if err != nil {
+				return err
+			}
+
+			// コンフリクトがある場合は終了コード1を返す
+			if len(result.Conflicts) > 0 {
+				return errChecksFailed
+			}
+
+			return nil
+		},
+	}
+}
+
+// newCommand は new コマンドを返す
+func newCommand() *cli.Command {
+	return &cli.Command{
+		Name:          "new",
+		Usage:         "タイトルから新しいフォーマット済みファイルを作成する",
+		ArgsUsage:     "<title>",
+		ShellComplete: shellComplete(completionSpec{tagFlags: []string{"tag", "t"}}),
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "dir",
+				Usage: "作成先ディレクトリ",
+				Value: ".",
+			},
+			&cli.StringFlag{
+				Name:  "template",
+				Usage: "使用するテンプレート名（.parakeet/templates/<name>.toml, .parakeet/templates/<name>.<ext> または templates/<name>.toml。{{.Timestamp}} {{.Title}} {{.Tags}} {{.Date}} を展開する）",
+			},
+			&cli.StringSliceFlag{
+				Name:    "tag",
+				Aliases: []string{"t"},
+				Usage:   "付与するタグ",
+			},
+			&cli.StringFlag{
+				Name:    "ext",
+				Aliases: []string{"e"},
+				Usage:   "拡張子（デフォルトはテンプレートの拡張子または md）",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			if cmd.Args().Len() == 0 {
+				return fmt.Errorf("title is required")
+			}
+
+			tagsFile, err := tagsFileFromCommand(cmd, cmd.String("dir"))
+			if err != nil {
+				return err
+			}
+
+			opts := NewOptions{
+				Writer:    stdout,
+				Dir:       cmd.String("dir"),
+				Template:  cmd.String("template"),
+				Tags:      cmd.StringSlice("tag"),
+				Extension: cmd.String("ext"),
+				TagsFile:  tagsFile,
+			}
+
+			_, err = CreateNewFile(cmd.Args().Get(0), opts)
+			return err
+		},
+	}
+}
+
+// retitleCommand は retitle コマンドを返す
+func retitleCommand() *cli.Command {
+	return &cli.Command{
+		Name:          "retitle",
+		Usage:         "IDで指定したファイルのタイトル（コメント）を変更する",
+		ArgsUsage:     "<id> <title>",
+		ShellComplete: shellComplete(completionSpec{args: []completionKind{completeID, completeNothing}}),
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "from",
+				Usage: "IDとタイトルの対応を書いたCSV（id,title）から一括で変更する",
+			},
+			&cli.StringFlag{
+				Name:  "dir",
+				Usage: "--from で変更する対象ディレクトリ",
+				Value: ".",
+			},
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Aliases: []string{"n"},
+				Usage:   "実際には変更せず、実行内容のみ表示する（--from のみ）",
+			},
+			&cli.StringFlag{
+				Name:  "journal",
+				Usage: "実行したリネームを追記するジャーナルファイルのパス（--from のみ、デフォルトは .parakeet/journal.jsonl）",
+			},
+			shimFlag(),
+			depthFlag(),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// CSVから一括で変更する
+			if from := cmd.String("from"); from != "" {
+				file, err := os.Open(from)
+				if err != nil {
+					return fmt.Errorf("failed to open title mapping: %w", err)
+				}
+				defer func() { _ = file.Close() }()
+
+				mappings, err := ReadTitleMapping(file)
+				if err != nil {
+					return err
+				}
+
+				opts := RetitleBatchOptions{
+					Writer:  stdout,
+					Hooks:   HookRunnerFromContext(ctx),
+					DryRun:  cmd.Bool("dry-run"),
+					Journal: NewJournal(cmd.String("dir"), "retitle"),
+					Shims:   cmd.Bool("shim"),
+					Vault:   obsidianVaultFor(cmd.String("dir"), stdout),
+				}
+				if path := cmd.String("journal"); path != "" {
+					opts.Journal.Path = path
+				}
+
+				_, err = RetitleFromMapping(cmd.String("dir"), mappings, opts)
+				return err
+			}
+
+			if cmd.Args().Len() < 2 {
+				return fmt.Errorf("ID and title are required")
+			}
+
+			// IDでファイルを検索
+			depth, err := depthFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			filePath, err := FindFileByIDInteractive(".", cmd.Args().Get(0), depth)
+			if err != nil {
+				return fmt.Errorf("file not found: %w", err)
+			}
+
+			newPath, err := RetitleFile(filePath, cmd.Args().Get(1), stdout, HookRunnerFromContext(ctx))
+			if err != nil {
+				return err
+			}
+			if newPath != filePath {
+				recordFileRename("retitle", filePath, newPath, stdout)
+			}
+
+			if cmd.Bool("shim") && newPath != filePath {
+				return CreateShim(filePath, newPath)
+			}
+			return nil
+		},
+	}
+}
+
+// retimeCommand は retime コマンドを返す
+func retimeCommand() *cli.Command {
+	return &cli.Command{
+		Name:          "retime",
+		Usage:         "IDで指定したファイルのタイムスタンプ（ID）のみを変更する。--sync-mtime ではファイルの更新日時をタイムスタンプに合わせる",
+		ArgsUsage:     "<id> | --sync-mtime [dir]",
+		ShellComplete: shellComplete(completionSpec{args: []completionKind{completeID}}),
+		Flags: append(filterFlags(),
+			&cli.StringFlag{
+				Name:  "to",
+				Usage: "新しいタイムスタンプ（例: 20250101T090000、同じディレクトリの他のファイルと重複する場合はエラー）",
+			},
+			&cli.BoolFlag{
+				Name:  "from-mtime",
+				Usage: "ファイルの更新日時からタイムスタンプを生成する（重複する場合は以降の重複しないタイムスタンプにする）",
+			},
+			&cli.StringFlag{
+				Name:  "precision",
+				Usage: "--from-mtime で生成するタイムスタンプの精度（second, millisecond、parakeet.toml の timestamp_precision より優先）",
+			},
+			&cli.BoolFlag{
+				Name:  "sync-mtime",
+				Usage: "ディレクトリ直下のファイルの更新日時をファイル名のタイムスタンプに合わせる（ファイルマネージャの更新日時順を parakeet の順序と一致させる）",
+			},
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Aliases: []string{"n"},
+				Usage:   "実際には変更せず、実行内容のみ表示する（--sync-mtime のみ）",
+			},
+			shimFlag(),
+			depthFlag(),
+		),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// ファイルの更新日時をタイムスタンプに合わせる
+			if cmd.Bool("sync-mtime") {
+				if cmd.IsSet("to") || cmd.Bool("from-mtime") {
+					return fmt.Errorf("--sync-mtime cannot be used with --to or --from-mtime")
+				}
+
+				// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+				targetDir := "."
+				if cmd.Args().Len() > 0 {
+					targetDir = cmd.Args().Get(0)
+				}
+
+				filter, err := filterOptionsFromCommand(cmd)
+				if err != nil {
+					return err
+				}
+
+				_, err = SyncMtimes(targetDir, SyncMtimeOptions{Writer: stdout, FilterOptions: filter, DryRun: cmd.Bool("dry-run")})
+				return err
+			}
+
+			if cmd.Args().Len() < 1 {
+				return fmt.Errorf("ID is required")
+			}
+
+			config, err := LoadConfig(filepath.Join(".", ConfigFileName))
+			if err != nil {
+				return err
+			}
+			opts := RetimeOptions{
+				Writer:    stdout,
+				Hooks:     HookRunnerFromContext(ctx),
+				To:        cmd.String("to"),
+				FromMtime: cmd.Bool("from-mtime"),
+			}
+			if opts.Precision, err = precisionFromCommand(cmd, config); err != nil {
+				return err
+			}
+
+			// IDでファイルを検索
+			depth, err := depthFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			filePath, err := FindFileByIDInteractive(".", cmd.Args().Get(0), depth)
+			if err != nil {
+				return fmt.Errorf("file not found: %w", err)
+			}
+
+			newPath, err := RetimeFile(filePath, opts)
+			if err != nil {
+				return err
+			}
+			if newPath != filePath {
+				recordFileRename("retime", filePath, newPath, stdout)
+			}
+
+			if cmd.Bool("shim") && newPath != filePath {
+				return CreateShim(filePath, newPath)
+			}
+			return nil
+		},
+	}
+}
+
+// shimFlag はリネーム後に旧ファイル名のシムを残すフラグを返す
+func shimFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "shim",
+		Usage: "旧ファイル名から新しいファイルへのシンボリックリンクを残す（clean-shims で削除する）",
+	}
+}
+
+// resumeFlag は中断した一括処理をチェックポイントから再開するフラグを返す
+func resumeFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "resume",
+		Usage: "Ctrl-C などで中断した前回の実行を、チェックポイントから続きを再開する",
+	}
+}
+
+// resumeFromCommand は --resume が指定された場合にチェックポイントを読み込む（指定がない場合は nil）
+func resumeFromCommand(cmd *cli.Command, dirPath, command string) (*Checkpoint, error) {
+	if !cmd.Bool("resume") {
+		return nil, nil
+	}
+	return LoadCheckpoint(dirPath, command)
+}
+
+// notifyInterrupt は SIGINT/SIGTERM を受け取るとキャンセルされるコンテキストを返す
+// シグナルを受け取ってもプロセスは終了せず、一括処理は処理中のファイルを終えてから中断する
+func notifyInterrupt(ctx context.Context) (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+}
+
+// cleanShimsCommand は clean-shims コマンドを返す
+func cleanShimsCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "clean-shims",
+		Usage:     "猶予期間を過ぎたシムとリンク先のないシムを削除する",
+		ArgsUsage: "[dir]",
+		Flags: []cli.Flag{
+			&cli.DurationFlag{
+				Name:  "older-than",
+				Usage: "この時間より前に作成したシムを削除する（例: 720h）",
+				Value: DefaultShimGracePeriod,
+			},
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Aliases: []string{"n"},
+				Usage:   "実際には削除せず、実行内容のみ表示する",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			targetDir := "."
+			if cmd.Args().Len() > 0 {
+				targetDir = cmd.Args().Get(0)
+			}
+
+			opts := CleanShimsOptions{
+				Writer:    stdout,
+				OlderThan: cmd.Duration("older-than"),
+				DryRun:    cmd.Bool("dry-run"),
+			}
+
+			_, err := CleanShims(targetDir, opts)
+			return err
+		},
+	}
+}
+
+// historyCommand は history コマンドを返す
+func historyCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "history",
+		Usage:     "ジャーナル（.parakeet/journal.jsonl）に記録されたリネームを新しい順に表示する",
+		ArgsUsage: "[dir]",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:  "limit",
+				Usage: "表示する件数の上限（0 の場合は制限なし）",
+				Value: 20,
+			},
+			&cli.StringFlag{
+				Name:  "command",
+				Usage: "このコマンドの記録のみ表示する（例: generate, tag）",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			targetDir := "."
+			if cmd.Args().Len() > 0 {
+				targetDir = cmd.Args().Get(0)
+			}
+
+			opts := HistoryOptions{
+				Writer:  stdout,
+				Limit:   cmd.Int("limit"),
+				Command: cmd.String("command"),
+			}
+
+			_, err := ShowHistory(targetDir, opts)
+			return err
+		},
+	}
+}
+
+// undoCommand は undo コマンドを返す
+func undoCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "undo",
+		Usage:     "ジャーナルに記録された直近のコマンドのリネームを元に戻す（リネーム後に変更されたファイルがある場合は何もしない）",
+		ArgsUsage: "[dir]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Aliases: []string{"n"},
+				Usage:   "実際には元に戻さず、実行内容のみ表示する",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			targetDir := "."
+			if cmd.Args().Len() > 0 {
+				targetDir = cmd.Args().Get(0)
+			}
+
+			opts := UndoOptions{
+				Writer: stdout,
+				Hooks:  HookRunnerFromContext(ctx),
+				DryRun: cmd.Bool("dry-run"),
+			}
+
+			_, err := UndoLastBatch(targetDir, opts)
+			return err
+		},
+	}
+}
+
+// mvCommand は mv コマンドを返す
+func mvCommand() *cli.Command {
+	return &cli.Command{
+		Name:          "mv",
+		Usage:         "IDで指定したファイルを別の管理ディレクトリへ移動する",
+		ArgsUsage:     "<id> <target-dir>",
+		ShellComplete: shellComplete(completionSpec{args: []completionKind{completeID, completeNothing}}),
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "rewrite-refs",
+				Usage: "移動元ディレクトリのテキストファイル内の参照を書き換える",
+			},
+			depthFlag(),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			if cmd.Args().Len() < 2 {
+				return fmt.Errorf("ID and target directory are required")
+			}
+
+			depth, err := depthFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+
+			opts := MoveOptions{
+				Writer:      stdout,
+				Hooks:       HookRunnerFromContext(ctx),
+				RewriteRefs: cmd.Bool("rewrite-refs"),
+				Depth:       depth,
+			}
+
+			_, err = MoveFileByID(".", cmd.Args().Get(0), cmd.Args().Get(1), opts)
+			return err
+		},
+	}
+}
+
+// openCommand は open コマンドを返す
+func openCommand() *cli.Command {
+	return &cli.Command{
+		Name:          "open",
+		Usage:         "IDで指定したファイルをアプリケーションで開く（parakeet.toml の [open] で拡張子ごとに指定、なければ xdg-open, open, start）",
+		ArgsUsage:     "<id>",
+		ShellComplete: shellComplete(completionSpec{args: []completionKind{completeID}}),
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Aliases: []string{"n"},
+				Usage:   "実際には開かず、実行するコマンドのみ表示する",
+			},
+			depthFlag(),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// IDを取得
+			if cmd.Args().Len() == 0 {
+				return fmt.Errorf("ID is required")
+			}
+
+			// IDでファイルを検索
+			depth, err := depthFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			filePath, err := FindFileByIDInteractive(".", cmd.Args().Get(0), depth)
+			if err != nil {
+				return fmt.Errorf("file not found: %w", err)
+			}
+
+			config, err := LoadConfig(ConfigFileName)
+			if err != nil {
+				return err
+			}
+
+			_, err = OpenFile(filePath, OpenOptions{
+				Writer: stdout,
+				Apps:   config.Open,
+				DryRun: cmd.Bool("dry-run"),
+			})
+			return err
+		},
+	}
+}
+
+// pathCommand は path コマンドを返す
+func pathCommand() *cli.Command {
+	return &cli.Command{
+		Name:          "path",
+		Usage:         "IDで指定したファイルの絶対パスのみを出力する（見つからない場合は終了コード1、例: vim \"$(parakeet path <id>)\"）",
+		ArgsUsage:     "<id>",
+		ShellComplete: shellComplete(completionSpec{args: []completionKind{completeID}}),
+		Flags:         []cli.Flag{depthFlag()},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// IDを取得
+			if cmd.Args().Len() == 0 {
+				return cli.Exit("ID is required", 1)
+			}
+			id := cmd.Args().Get(0)
+
+			depth, err := depthFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			filePath, err := FindFileByIDInteractive(".", id, depth)
+			if err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+			absPath, err := filepath.Abs(filePath)
+			if err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			stdout.Columns("path", "id", "path")
+			stdout.Emit("path", map[string]any{"id": id, "path": absPath}, "%s\n", absPath)
+			return nil
+		},
+	}
+}
+
+// exportCommand は export コマンドを返す
+func exportCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "export",
+		Usage:     "管理ディレクトリのファイルと設定をバンドルに書き出す（--bundle を省略した場合はファイルの一覧を出力する）",
+		ArgsUsage: "[dir]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "bundle",
+				Usage: "出力するバンドルのパス（tar.gz、省略した場合はパースしたファイル名・サイズ・更新日時を --format json, jsonl, csv, tsv, org で出力する）",
+			},
+			&cli.StringSliceFlag{
+				Name:    "ext",
+				Aliases: []string{"e"},
+				Usage:   "対象拡張子（カンマ区切り、例: pdf,txt,md）",
+			},
+			templateFlag(),
+			queryFlag(),
+			duplicatePolicyFlag(),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 0 {
+				targetDir = cmd.Args().Get(0)
+			}
+
+			query, err := queryFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			filter := FilterOptions{Extensions: cmd.StringSlice("ext"), Query: query}
+
+			// バンドルを指定しない場合はファイルの一覧を出力する
+			if cmd.String("bundle") == "" {
+				tmpl, err := templateFromCommand(cmd)
+				if err != nil {
+					return err
+				}
+				_, err = ExportIndex(targetDir, ExportIndexOptions{Writer: stdout, FilterOptions: filter, Template: tmpl})
+				return err
+			}
+
+			config, err := LoadConfig(filepath.Join(targetDir, ConfigFileName))
+			if err != nil {
+				return err
+			}
+
+			policy, err := duplicatePolicyFromCommand(cmd, config)
+			if err != nil {
+				return err
+			}
+
+			opts := ExportOptions{
+				Writer:          stdout,
+				FilterOptions:   filter,
+				DuplicatePolicy: policy,
+			}
+
+			_, err = ExportBundle(targetDir, cmd.String("bundle"), opts)
+			return err
+		},
+	}
+}
+
+// importCommand は import コマンドを返す
+func importCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "import",
+		Usage:     "外部のファイルをフォーマット済みのファイル名で管理ディレクトリにコピーする（--bundle の場合はバンドルを取り込む）",
+		ArgsUsage: "<src>... <dir> | --bundle <path> [dir]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "bundle",
+				Usage: "取り込むバンドルのパス（tar.gz）",
+			},
+			&cli.StringFlag{
+				Name:  "on-collision",
+				Usage: "バンドルの取り込みでのID衝突時の動作（error, skip, rename）",
+				Value: CollisionError,
+			},
+			&cli.StringFlag{
+				Name:  "comment",
+				Usage: "取り込むファイルのコメント（省略した場合は元のファイル名から作る）",
+			},
+			&cli.StringSliceFlag{
+				Name:    "tag",
+				Aliases: []string{"t"},
+				Usage:   "取り込むファイルに付けるタグ（複数指定可）",
+			},
+			&cli.BoolFlag{
+				Name:  "move",
+				Usage: "コピーの代わりに移動する",
+			},
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Aliases: []string{"n"},
+				Usage:   "実際には取り込まず、実行内容のみ表示する",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// 外部のファイルを取り込む（最後の引数が管理ディレクトリ）
+			if !cmd.IsSet("bundle") {
+				if cmd.Args().Len() < 2 {
+					return fmt.Errorf("source files and a target directory are required (or --bundle)")
+				}
+				args := cmd.Args().Slice()
+				targetDir := args[len(args)-1]
+
+				config, err := LoadConfig(filepath.Join(targetDir, ConfigFileName))
+				if err != nil {
+					return err
+				}
+
+				opts := ImportFilesOptions{
+					Writer:    stdout,
+					Hooks:     HookRunnerFromContext(ctx),
+					Comment:   cmd.String("comment"),
+					Tags:      cmd.StringSlice("tag"),
+					Move:      cmd.Bool("move"),
+					DryRun:    cmd.Bool("dry-run"),
+					TagsFile:  tagsFileFromConfig(cmd, config),
+					Sanitizer: config.Sanitize,
+				}
+
+				_, err = ImportFiles(args[:len(args)-1], targetDir, opts)
+				return err
+			}
+
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 0 {
+				targetDir = cmd.Args().Get(0)
+			}
+
+			opts := ImportOptions{
+				Writer:      stdout,
+				OnCollision: cmd.String("on-collision"),
+			}
+
+			_, err := ImportBundle(cmd.String("bundle"), targetDir, opts)
+			return err
+		},
+	}
+}
+
+// tagCommand は tag コマンドを返す
+func tagCommand() *cli.Command {
+	return &cli.Command{
+		Name:          "tag",
+		Usage:         "ファイルのタグをインタラクティブに編集する",
+		ArgsUsage:     "<id>",
+		ShellComplete: shellComplete(completionSpec{args: []completionKind{completeID}, tagFlags: []string{"set", "t"}}),
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "show",
+				Aliases: []string{"s"},
+				Usage:   "現在のタグを表示する",
+			},
+			&cli.StringSliceFlag{
+				Name:    "set",
+				Aliases: []string{"t"},
+				Usage:   "タグを直接指定する（カンマ区切り、例: --set tag1 --set tag2）",
+			},
+			&cli.BoolFlag{
+				Name:  "xattr",
+				Usage: "タグをファイルの拡張属性（Linux: user.xdg.tags, macOS: Finder のタグ）にも書き込む（parakeet.toml の xattr_tags = true でも有効）",
+			},
+			depthFlag(),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// IDを取得
+			if cmd.Args().Len() == 0 {
+				return fmt.Errorf("ID is required")
+			}
+			id := cmd.Args().Get(0)
+
+			// IDでファイルを検索
+			depth, err := depthFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			filePath, err := FindFileByIDInteractive(".", id, depth)
+			if err != nil {
+				return fmt.Errorf("file not found: %w", err)
+			}
+
+			// --show フラグの場合はタグを表示
+			if cmd.Bool("show") {
+				return ShowTags(filePath, stdout)
+			}
+
+			config, err := LoadConfig(filepath.Join(filepath.Dir(filePath), ConfigFileName))
+			if err != nil {
+				return err
+			}
+			tagsFile := tagsFileFromConfig(cmd, config)
+			xattr := cmd.Bool("xattr") || config.XattrTags
+
+			// --set フラグが指定された場合は非インタラクティブモード
+			if setTags := cmd.StringSlice("set"); len(setTags) > 0 {
+				// ファイルのディレクトリから解決したtags.tomlに対してバリデーション
+				if err := ValidateTags(setTags, ResolveTagsFile(filepath.Dir(filePath), tagsFile)); err != nil {
+					return err
+				}
+
+				// タグを設定
+				return SetTags(filePath, setTags, TagOptions{Writer: stdout, Hooks: HookRunnerFromContext(ctx), TagsFile: tagsFile, Journal: NewJournal(filepath.Dir(filePath), "tag"), Vault: obsidianVaultFor(filepath.Dir(filePath), stdout), Xattr: xattr})
+			}
+
+			// デフォルトはインタラクティブモード
+			opts := TagOptions{
+				Interactive: true,
+				Writer:      stdout,
+				Hooks:       HookRunnerFromContext(ctx),
+				TagsFile:    tagsFile,
+				Journal:     NewJournal(filepath.Dir(filePath), "tag"),
+				Vault:       obsidianVaultFor(filepath.Dir(filePath), stdout),
+				Xattr:       xattr,
+			}
+
+			return EditTags(filePath, opts)
+		},
+		Commands: []*cli.Command{
+			tagRenameCommand(),
+			tagAddCommand(),
+			tagRemoveCommand(),
+			tagImportFinderCommand(),
+		},
+	}
+}
+
+// tagRenameCommand は tag rename コマンドを返す
+func tagRenameCommand() *cli.Command {
+	return &cli.Command{
+		Name:          "rename",
+		Usage:         "ディレクトリ内のファイルのタグを一括でリネームする",
+		ArgsUsage:     "<old> <new>",
+		ShellComplete: shellComplete(completionSpec{args: []completionKind{completeTag, completeNothing}}),
+		Flags: append(filterFlags(),
+			&cli.StringFlag{
+				Name:  "dir",
+				Usage: "対象ディレクトリ",
+				Value: ".",
+			},
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Aliases: []string{"n"},
+				Usage:   "実際には変更せず、実行内容のみ表示する",
+			},
+		),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			if cmd.Args().Len() < 2 {
+				return fmt.Errorf("old and new tags are required")
+			}
+
+			filter, err := filterOptionsFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+
+			tagsFile, err := tagsFileFromCommand(cmd, cmd.String("dir"))
+			if err != nil {
+				return err
+			}
+
+			opts := TagRenameOptions{
+				Writer:        stdout,
+				Hooks:         HookRunnerFromContext(ctx),
+				FilterOptions: filter,
+				DryRun:        cmd.Bool("dry-run"),
+				TagsFile:      tagsFile,
+				Journal:       NewJournal(cmd.String("dir"), "tag rename"),
+			}
+
+			_, err = RenameTag(cmd.String("dir"), cmd.Args().Get(0), cmd.Args().Get(1), opts)
+			return err
+		},
+	}
+}
+
+// tagAddCommand は tag add コマンドを返す
+func tagAddCommand() *cli.Command {
+	return &cli.Command{
+		Name:          "add",
+		Usage:         "IDで指定したファイルにタグを一括で追加する",
+		ArgsUsage:     "<tag> [id...]",
+		ShellComplete: shellComplete(completionSpec{args: []completionKind{completeTag, completeID}, tagFlags: []string{"tag"}}),
+		Flags:         tagBulkFlags(),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			opts, err := tagBulkOptionsFromCommand(ctx, cmd)
+			if err != nil {
+				return err
+			}
+
+			_, err = AddTag(cmd.String("dir"), cmd.Args().Get(0), opts)
+			return err
+		},
+	}
+}
+
+// tagRemoveCommand は tag rm コマンドを返す
+func tagRemoveCommand() *cli.Command {
+	return &cli.Command{
+		Name:          "rm",
+		Usage:         "IDで指定したファイルからタグを一括で削除する",
+		ArgsUsage:     "<tag> [id...]",
+		ShellComplete: shellComplete(completionSpec{args: []completionKind{completeTag, completeID}, tagFlags: []string{"tag"}}),
+		Flags:         tagBulkFlags(),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			opts, err := tagBulkOptionsFromCommand(ctx, cmd)
+			if err != nil {
+				return err
+			}
+
+			_, err = RemoveTag(cmd.String("dir"), cmd.Args().Get(0), opts)
+			return err
+		},
+	}
+}
+
+// tagImportFinderCommand は tag import-finder コマンドを返す
+func tagImportFinderCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "import-finder",
+		Usage:     "Finder のタグ（Linux では user.xdg.tags）をファイル名のタグに取り込む（tags.toml に定義されていないタグは取り込まない）",
+		ArgsUsage: "[dir]",
+		Flags: append(filterFlags(),
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Aliases: []string{"n"},
+				Usage:   "実際には変更せず、実行内容のみ表示する",
+			},
+		),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 0 {
+				targetDir = cmd.Args().Get(0)
+			}
+
+			filter, err := filterOptionsFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+
+			tagsFile, err := tagsFileFromCommand(cmd, targetDir)
+			if err != nil {
+				return err
+			}
+
+			opts := FinderImportOptions{
+				Writer:        stdout,
+				Hooks:         HookRunnerFromContext(ctx),
+				FilterOptions: filter,
+				DryRun:        cmd.Bool("dry-run"),
+				TagsFile:      tagsFile,
+				Journal:       NewJournal(targetDir, "tag import-finder"),
+				Vault:         obsidianVaultFor(targetDir, stdout),
+			}
+
+			_, err = ImportFinderTags(targetDir, opts)
+			return err
+		},
+	}
+}
+
+// tagBulkFlags は tag add/rm コマンドの共通フラグを返す
+func tagBulkFlags() []cli.Flag {
+	return append(filterFlags(),
+		queryFlag(),
+		&cli.StringFlag{
+			Name:  "dir",
+			Usage: "対象ディレクトリ",
+			Value: ".",
+		},
+		&cli.BoolFlag{
+			Name:  "all",
+			Usage: "ディレクトリ内のすべてのファイルを対象にする",
+		},
+		&cli.StringFlag{
+			Name:  "tag",
+			Usage: "このタグを持つファイルのみ対象にする",
+		},
+		&cli.BoolFlag{
+			Name:    "dry-run",
+			Aliases: []string{"n"},
+			Usage:   "実際には変更せず、実行内容のみ表示する",
+		},
+	)
+}
+
+// tagBulkOptionsFromCommand はコマンドの引数とフラグから tag add/rm のオプションを作成する
+func tagBulkOptionsFromCommand(ctx context.Context, cmd *cli.Command) (TagBulkOptions, error) {
+	if cmd.Args().Len() == 0 {
+		return TagBulkOptions{}, fmt.Errorf("tag is required")
+	}
+
+	filter, err := filterOptionsFromCommand(cmd)
+	if err != nil {
+		return TagBulkOptions{}, err
+	}
+	if filter.Query, err = queryFromCommand(cmd); err != nil {
+		return TagBulkOptions{}, err
+	}
+
+	tagsFile, err := tagsFileFromCommand(cmd, cmd.String("dir"))
+	if err != nil {
+		return TagBulkOptions{}, err
+	}
+
+	return TagBulkOptions{
+		Writer:        ReporterFromContext(ctx),
+		Hooks:         HookRunnerFromContext(ctx),
+		FilterOptions: filter,
+		IDs:           cmd.Args().Slice()[1:],
+		All:           cmd.Bool("all"),
+		WithTag:       cmd.String("tag"),
+		DryRun:        cmd.Bool("dry-run"),
+		TagsFile:      tagsFile,
+		Journal:       NewJournal(cmd.String("dir"), "tag "+cmd.Name),
+	}, nil
+}
+
+// completeTagsCommand は complete tags コマンドを返す
+func completeTagsCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "tags",
+		Usage:     "前方一致するタグ（使用中のタグと tags.toml の定義）を1行に1つ出力する",
+		ArgsUsage: "[dir]",
+		Flags:     completeFlags(),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			targetDir, opts, err := completeOptionsFromCommand(ctx, cmd)
+			if err != nil {
+				return err
+			}
+
+			_, err = CompleteTags(targetDir, opts)
+			return err
+		},
+	}
+}
+
+// completeIDsCommand は complete ids コマンドを返す
+func completeIDsCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "ids",
+		Usage:     "前方一致するIDを1行に1つ出力する",
+		ArgsUsage: "[dir]",
+		Flags:     completeFlags(),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			targetDir, opts, err := completeOptionsFromCommand(ctx, cmd)
+			if err != nil {
+				return err
+			}
+
+			_, err = CompleteIDs(targetDir, opts)
+			return err
+		},
+	}
+}
+
+// completeFlags は complete コマンド共通のフラグを返す
+func completeFlags() []cli.Flag {
+	return append(filterFlags(),
+		&cli.StringFlag{
+			Name:  "prefix",
+			Usage: "候補の前方一致条件",
+		},
+		&cli.IntFlag{
+			Name:  "limit",
+			Usage: "出力する候補の数（0 の場合は制限なし）",
+		},
+		&cli.BoolFlag{
+			Name:  "describe",
+			Usage: "候補の後にタブ区切りで説明（タグの説明、ファイルのタイトル）を出力する",
+		},
+	)
+}
+
+// completeOptionsFromCommand はコマンドの引数とフラグから対象ディレクトリと補完のオプションを作成する
+func completeOptionsFromCommand(ctx context.Context, cmd *cli.Command) (string, CompleteOptions, error) {
+	// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+	targetDir := "."
+	if cmd.Args().Len() > 0 {
+		targetDir = cmd.Args().Get(0)
+	}
+
+	filter, err := filterOptionsFromCommand(cmd)
+	if err != nil {
+		return "", CompleteOptions{}, err
+	}
+
+	tagsFile, err := tagsFileFromCommand(cmd, targetDir)
+	if err != nil {
+		return "", CompleteOptions{}, err
+	}
+
+	return targetDir, CompleteOptions{
+		Writer:        ReporterFromContext(ctx),
+		FilterOptions: filter,
+		Prefix:        cmd.String("prefix"),
+		Limit:         cmd.Int("limit"),
+		Describe:      cmd.Bool("describe"),
+		TagsFile:      tagsFile,
+	}, nil
+}
+
+// hookInstallCommand は hook install コマンドを返す
+func hookInstallCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "install",
+		Usage:     "コミットするファイルのファイル名を検証する git の pre-commit フック（parakeet --quiet validate --strict --staged）を書き出す",
+		ArgsUsage: "[dir]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "command",
+				Usage: "フックから実行する parakeet のコマンド",
+				Value: "parakeet",
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "parakeet 以外が書き出した pre-commit フックも上書きする",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 0 {
+				targetDir = cmd.Args().Get(0)
+			}
+
+			opts := HookInstallOptions{
+				Writer:  stdout,
+				Command: cmd.String("command"),
+				Force:   cmd.Bool("force"),
+			}
+
+			_, err := InstallPreCommitHook(targetDir, opts)
+			return err
+		},
+	}
+}
+
+// tagdefListCommand は tagdef list コマンドを返す
+func tagdefListCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "list",
+		Usage:     "tags.toml に定義されたタグを一覧表示する",
+		ArgsUsage: "[dir]",
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 0 {
+				targetDir = cmd.Args().Get(0)
+			}
+
+			tagsFile, err := tagsFileFromCommand(cmd, targetDir)
+			if err != nil {
+				return err
+			}
+
+			_, err = ListTagDefinitions(targetDir, tagsFile, stdout)
+			return err
+		},
+	}
+}
+
+// frontmatterSyncCommand は frontmatter sync コマンドを返す
+func frontmatterSyncCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "sync",
+		Usage:     "Markdownファイルの YAML frontmatter の id, title, tags, date をファイル名から書き込む（--reverse でファイル名を frontmatter から変更する）",
+		ArgsUsage: "[dir]",
+		Flags: append(filterFlags(),
+			&cli.BoolFlag{
+				Name:  "reverse",
+				Usage: "frontmatter の title と tags からファイル名を変更する（IDは変更しない）",
+			},
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Aliases: []string{"n"},
+				Usage:   "実際には変更せず、実行内容のみ表示する",
+			},
+		),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 0 {
+				targetDir = cmd.Args().Get(0)
+			}
+
+			filter, err := filterOptionsFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			tagsFile, err := tagsFileFromCommand(cmd, targetDir)
+			if err != nil {
+				return err
+			}
+
+			_, err = SyncFrontmatter(targetDir, FrontmatterOptions{
+				Writer:        stdout,
+				Hooks:         HookRunnerFromContext(ctx),
+				FilterOptions: filter,
+				Reverse:       cmd.Bool("reverse"),
+				DryRun:        cmd.Bool("dry-run"),
+				TagsFile:      tagsFile,
+				Journal:       NewJournal(targetDir, "frontmatter sync"),
+			})
+			return err
+		},
+	}
+}
+
+// tagdefSummaryCommand は tagdef summary コマンドを返す
+func tagdefSummaryCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "summary",
+		Usage:     "タグごとの説明・件数・最終使用日の表で README.md のマーカー間を更新する",
+		ArgsUsage: "[dir]",
+		Flags: append(filterFlags(),
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "出力ファイルのパス（デフォルトは対象ディレクトリの README.md）",
+			},
+		),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			stdout := ReporterFromContext(ctx)
+
+			// 対象ディレクトリを取得（デフォルトはカレントディレクトリ）
+			targetDir := "."
+			if cmd.Args().Len() > 0 {
+				targetDir = cmd.Args().Get(0)
+			}
+
+			filter, err := filterOptionsFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+
+			tagsFile, err := tagsFileFromCommand(cmd, targetDir)
+			if err != nil {
+				return err
+			}
+
+			opts := TagSummaryOptions{
+				Writer:        stdout,
+				FilterOptions: filter,
+				Output:        cmd.String("output"),
+				TagsFile:      tagsFile,
+			}
+
+			return UpdateTagSummaryFile(targetDir, opts)
+		},
+	}
+}
+
+// outputFlags は出力形式の共通フラグを返す
+func outputFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "出力フォーマット（human, json, ndjson, jsonl, csv, tsv, org, dot）",
+			Value: FormatHuman,
+		},
+		&cli.StringFlag{
+			Name:  "color",
+			Usage: "色付けの設定（auto, always, never）",
+			Value: ColorAuto,
+		},
+		&cli.BoolFlag{
+			Name:    "quiet",
+			Aliases: []string{"q"},
+			Usage:   "警告とエラーのみ出力する",
+		},
+		&cli.BoolFlag{
+			Name:  "verbose",
+			Usage: "詳細なメッセージも出力する",
+		},
+	}
+}
+
+// tagsFileFlag はタグ定義ファイルを指定する全コマンド共通のフラグを返す
+func tagsFileFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "tags-file",
+		Usage: "タグ定義ファイル（対象ディレクトリから親ディレクトリへ順に探す、parakeet.toml の tags_file より優先）",
+	}
+}
+
+// tagsFileFromCommand はフラグと dir の設定ファイルからタグ定義ファイルの指定を取得する
+func tagsFileFromCommand(cmd *cli.Command, dir string) (string, error) {
+	if cmd.IsSet("tags-file") {
+		return cmd.String("tags-file"), nil
+	}
+
+	config, err := LoadConfig(filepath.Join(dir, ConfigFileName))
+	if err != nil {
+		return "", err
+	}
+	return config.TagsFile, nil
+}
+
+// tagsFileFromConfig は読み込み済みの設定ファイルからタグ定義ファイルの指定を取得する
+// フラグが指定された場合は設定ファイルより優先する
+func tagsFileFromConfig(cmd *cli.Command, config *Config) string {
+	if cmd.IsSet("tags-file") {
+		return cmd.String("tags-file")
+	}
+	return config.TagsFile
+}
+
+// reporterFromCommand はコマンドのフラグから標準出力向けの Reporter を作成する
+func reporterFromCommand(cmd *cli.Command) (Reporter, error) {
+	if cmd.Bool("quiet") && cmd.Bool("verbose") {
+		return nil, fmt.Errorf("--quiet and --verbose cannot be used together")
+	}
+
+	color, err := ResolveColor(cmd.String("color"), os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+
+	level := LevelNormal
+	if cmd.Bool("quiet") {
+		level = LevelQuiet
+	} else if cmd.Bool("verbose") {
+		level = LevelVerbose
+	}
+
+	return NewReporter(os.Stdout, ReporterConfig{
+		Format: cmd.String("format"),
+		Color:  color,
+		Level:  level,
+	})
+}
+
+// filterFlags は絞り込み条件の共通フラグを返す
+func filterFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:    "ext",
+			Aliases: []string{"e"},
+			Usage:   "対象拡張子（カンマ区切り、例: pdf,txt,md）",
+		},
+		&cli.StringFlag{
+			Name:  "since",
+			Usage: "この日時以降のファイルのみ対象（例: 2025-09-01, 20250901T000000, 7d, yesterday, \"last week\"）",
+		},
+		&cli.StringFlag{
+			Name:  "until",
+			Usage: "この日時以前のファイルのみ対象（例: 2025-09-30, 20250930T235959, yesterday, 2w）",
+		},
+		includeMetadataFlag(),
+	}
+}
+
+// includeMetadataFlag はメタデータファイルを処理対象に含めるフラグを返す
+func includeMetadataFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "include-metadata",
+		Usage: "tags.toml, parakeet.toml, .parakeetignore も対象にする（デフォルトは対象外）",
+	}
+}
+
+// depthFlag はIDでファイルを検索するサブディレクトリの深さのフラグを返す
+func depthFlag() cli.Flag {
+	return &cli.IntFlag{
+		Name:  "depth",
+		Usage: "IDでファイルを検索するサブディレクトリの深さ（デフォルトは 0 でカレントディレクトリのみ、parakeet.toml の id_search_depth より優先）",
+	}
+}
+
+// depthFromCommand はIDでファイルを検索するサブディレクトリの深さを取得する
+// フラグが指定された場合はカレントディレクトリの設定ファイルより優先する
+func depthFromCommand(cmd *cli.Command) (int, error) {
+	if cmd.IsSet("depth") {
+		if depth := cmd.Int("depth"); depth < 0 {
+			return 0, fmt.Errorf("--depth must not be negative: %d", depth)
+		}
+		return cmd.Int("depth"), nil
+	}
+	config, err := LoadConfig(ConfigFileName)
+	if err != nil {
+		return 0, err
+	}
+	return config.IDSearchDepth, nil
+}
+
+// queryFlag はクエリでファイルを選択するフラグを返す
+func queryFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "query",
+		Usage: "クエリに一致するファイルのみ対象（例: 'tag:network AND ext:pdf AND date>=2025-01'。tag, ext, title, id, date と AND, OR, NOT, 括弧）",
+	}
+}
+
+// queryFromCommand は --query フラグからクエリを作成する（指定がない場合は nil）
+func queryFromCommand(cmd *cli.Command) (*Query, error) {
+	text := cmd.String("query")
+	if text == "" {
+		return nil, nil
+	}
+	return ParseQuery(text)
+}
+
+// excludeFlag は処理対象から除外するファイルのパターンを指定するフラグを返す
+func excludeFlag() cli.Flag {
+	return &cli.StringSliceFlag{
+		Name:  "exclude",
+		Usage: "一致するファイルを対象外にする glob パターン（.gitignore と同じ書式、複数指定可。" + IgnoreFileName + " より優先）",
+	}
+}
+
+// excludeFromCommand はディレクトリの .parakeetignore と --exclude フラグから除外パターンを作成する
+// フラグのパターンは .parakeetignore のパターンより優先する
+func excludeFromCommand(cmd *cli.Command, dirPath string) (*IgnoreMatcher, error) {
+	ignore, err := LoadIgnoreFile(filepath.Join(dirPath, IgnoreFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	flags, err := ParseIgnorePatterns(cmd.StringSlice("exclude"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid --exclude: %w", err)
+	}
+
+	return ignore.Append(flags), nil
+}
+
+// templateFlag は1ファイル1行の出力テンプレートを指定するフラグを返す
+func templateFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "template",
+		Usage: "1ファイル1行の出力テンプレート（text/template 形式、またはそのファイルのパス。例: '{{.Timestamp}} {{.Comment}} {{join .Tags \",\"}}'）",
+	}
+}
+
+// templateFromCommand は --template フラグからテンプレートを作成する（指定がない場合は nil）
+func templateFromCommand(cmd *cli.Command) (*template.Template, error) {
+	text := cmd.String("template")
+	if text == "" {
+		return nil, nil
+	}
+	return ParseOutputTemplate(text)
+}
+
+// duplicatePolicyFlag はタイムスタンプ重複の扱いを指定するフラグを返す
+func duplicatePolicyFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "duplicate-policy",
+		Usage: "タイムスタンプ重複の扱い（error, warn, allow-same-basename, allow-all、parakeet.toml の設定より優先）",
+	}
+}
+
+// duplicatePolicyFromCommand はフラグと設定ファイルからタイムスタンプ重複の扱いを取得する
+// フラグが指定された場合は設定ファイルより優先する
+func duplicatePolicyFromCommand(cmd *cli.Command, config *Config) (DuplicatePolicy, error) {
+	if cmd.IsSet("duplicate-policy") {
+		return ParseDuplicatePolicy(cmd.String("duplicate-policy"))
+	}
+	return ParseDuplicatePolicy(string(config.DuplicatePolicy))
+}
+
+// precisionFromCommand はフラグと設定ファイルからタイムスタンプの精度を取得する
+// フラグが指定された場合は設定ファイルより優先する
+func precisionFromCommand(cmd *cli.Command, config *Config) (parakeet.Precision, error) {
+	if cmd.IsSet("precision") {
+		return parakeet.ParsePrecision(cmd.String("precision"))
+	}
+	return parakeet.ParsePrecision(string(config.Precision))
+}
+
+// portableFlag は Windows で使えるファイル名に限定するフラグ
+func portableFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "portable",
+		Usage: "Windows で使えるファイル名に限定する（使えない文字・予約されたデバイス名・末尾のドットと空白・MAX_PATH、Windows では常に有効）",
+	}
+}
+
+// portableFromCommand はフラグと設定ファイルから Windows で使えるファイル名に限定するかどうかを取得する
+func portableFromCommand(cmd *cli.Command, config *Config) bool {
+	return cmd.Bool("portable") || config.Portable || PortableByDefault
+}
+
+// jobsFlag は大きなディレクトリ向けにエントリの処理を並列に行う数のフラグ
+func jobsFlag() cli.Flag {
+	return &cli.IntFlag{
+		Name:  "jobs",
+		Usage: "stat・ハッシュの計算・タグのチェックを並列に行う数（0: CPU 数。出力の順序は並列数によらない）",
+		Value: 1,
+	}
+}
+
+// jobsFromCommand はフラグから並列数を取得する
+func jobsFromCommand(cmd *cli.Command) (int, error) {
+	return ResolveJobs(cmd.Int("jobs"))
+}
+
+// nameBudgetFromCommand はフラグと設定ファイルからファイル名の長さの上限と短縮方法を取得する
+// フラグが指定された場合は設定ファイルより優先する
+func nameBudgetFromCommand(cmd *cli.Command, config *Config) (NameBudget, error) {
+	budget := NameBudget{
+		MaxBytes: config.MaxNameBytes,
+		Policy:   config.NameBudget,
+	}
+
+	if cmd.IsSet("max-name-bytes") {
+		budget.MaxBytes = cmd.Int("max-name-bytes")
+		if budget.MaxBytes <= 0 {
+			return NameBudget{}, fmt.Errorf("--max-name-bytes must be positive: %d", budget.MaxBytes)
+		}
+	}
+	if cmd.IsSet("name-budget") {
+		policy, err := ParseNameBudgetPolicy(cmd.String("name-budget"))
+		if err != nil {
+			return NameBudget{}, err
+		}
+		budget.Policy = policy
+	}
+
+	if budget.Policy == NameBudgetPrompt {
+		budget.Choose = ChooseNameTrim
+	}
+
+	return budget, nil
+}
+
+// sortFlags は一覧出力の並び順を指定するフラグを返す
+func sortFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "sort",
+			Usage: "並び順（id, timestamp, title, ext）",
+			Value: SortByID,
+		},
+		&cli.StringFlag{
+			Name:  "collation",
+			Usage: "--sort title の照合順序（bytes, unicode, ja）",
+			Value: CollationUnicode,
+		},
+		&cli.BoolFlag{
+			Name:  "reverse",
+			Usage: "逆順に並べる",
+		},
+	}
+}
+
+// sortOptionsFromCommand はコマンドのフラグから並び順を作成する
+func sortOptionsFromCommand(cmd *cli.Command) SortOptions {
+	return SortOptions{
+		SortBy:    cmd.String("sort"),
+		Collation: cmd.String("collation"),
+		Reverse:   cmd.Bool("reverse"),
+	}
+}
+
+// markdownLayoutFlags はMarkdown表の列とリンクを指定するフラグを返す
+func markdownLayoutFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "links",
+			Usage: "Title 列をファイルへの相対リンクにする",
+		},
+		&cli.StringSliceFlag{
+			Name:  "columns",
+			Usage: "ID・Title・Tags の後に追加する列（カンマ区切り、ext, size, date）",
+		},
+	}
+}
+
+// markdownLayoutFromCommand はコマンドのフラグからMarkdown表の列とリンクの指定を作成する
+func markdownLayoutFromCommand(cmd *cli.Command) MarkdownLayout {
+	return MarkdownLayout{
+		Links:   cmd.Bool("links"),
+		Columns: cmd.StringSlice("columns"),
+	}
+}
+
+// queueFlags はステータス・優先度で絞り込むフラグを返す
+func queueFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "status",
+			Usage: "ステータスタグで絞り込む（todo, doing, done, none）",
+		},
+		&cli.StringFlag{
+			Name:  "priority",
+			Usage: "この優先度以上のファイルのみ対象（例: p2 は p1 と p2）",
+		},
+	}
+}
+
+// filterOptionsFromCommand はコマンドのフラグから絞り込み条件を作成する
+func filterOptionsFromCommand(cmd *cli.Command) (FilterOptions, error) {
+	since, err := ParseDateBound(cmd.String("since"), false)
+	if err != nil {
+		return FilterOptions{}, fmt.Errorf("invalid --since: %w", err)
+	}
+
+	until, err := ParseDateBound(cmd.String("until"), true)
+	if err != nil {
+		return FilterOptions{}, fmt.Errorf("invalid --until: %w", err)
+	}
+
+	return FilterOptions{
+		Extensions:      cmd.StringSlice("ext"),
+		Since:           since,
+		Until:           until,
+		IncludeMetadata: cmd.Bool("include-metadata"),
+	}, nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+)
+
+func TestCommands(t *testing.T) {
+	t.Parallel()
+
+	visible := map[string]*cli.Command{}
+	hidden := map[string]*cli.Command{}
+	for _, cmd := range commands() {
+		if cmd.Hidden {
+			hidden[cmd.Name] = cmd
+		} else {
+			visible[cmd.Name] = cmd
+		}
+	}
+
+	// ヘルプにはグループのみ表示する
+	assert.Len(t, visible, len(commandGroups))
+	for _, group := range commandGroups {
+		require.Contains(t, visible, group.name)
+	}
+
+	// 従来のフラットなコマンド名はグループ内のコマンドと同じ名前で残る
+	for _, name := range []string{"generate", "validate", "doctor", "fix", "dedup", "md", "list", "search", "grep", "links", "backlinks", "next", "stats", "related", "graph", "index", "reindex", "verify", "verify-links", "mcp", "diff", "sync", "new", "retitle", "retime", "mv", "archive", "flatten", "apply", "apply-map", "open", "path", "export", "import", "tag", "clean-shims", "history", "undo"} {
+		assert.Contains(t, hidden, name)
+	}
+	assert.NotContains(t, hidden, "tagdef")
+
+	file := visible["file"]
+	require.NotNil(t, file.Command("tag"))
+	require.NotNil(t, visible["tagdef"].Command("list"))
+	require.NotNil(t, visible["complete"].Command("ids"))
+
+	// グループ内とフラットなコマンドは別のインスタンス
+	assert.False(t, file.Command("tag") == hidden["tag"])
+}
+
+func TestValidateCommand_ExitCode(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-exit-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	// 重複のみの場合は --strict の場合だけ失敗する
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250903T083109--a.pdf"), []byte("a"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250903T083109--b.pdf"), []byte("b"), 0644))
+
+	run := func(args ...string) error {
+		buf := &bytes.Buffer{}
+		root := &cli.Command{
+			Name:           "parakeet",
+			Writer:         buf,
+			ExitErrHandler: func(context.Context, *cli.Command, error) {},
+			Commands:       []*cli.Command{validateCommand()},
+		}
+		ctx := WithReporter(context.Background(), ReporterFor(buf))
+		return root.Run(ctx, append([]string{"parakeet", "validate"}, args...))
+	}
+
+	require.NoError(t, run("--duplicate-policy", "warn", tmpDir))
+
+	err = run("--duplicate-policy", "warn", "--strict", tmpDir)
+	var exitErr cli.ExitCoder
+	require.True(t, errors.As(err, &exitErr), err)
+	assert.Equal(t, 1, exitErr.ExitCode())
+}
+
+func TestPathCommand_ExitCode(t *testing.T) {
+	t.Parallel()
+
+	run := func(args ...string) (string, error) {
+		buf := &bytes.Buffer{}
+		root := &cli.Command{
+			Name:           "parakeet",
+			Writer:         buf,
+			ExitErrHandler: func(context.Context, *cli.Command, error) {},
+			Commands:       []*cli.Command{pathCommand()},
+		}
+		ctx := WithReporter(context.Background(), ReporterFor(buf))
+		err := root.Run(ctx, append([]string{"parakeet", "path"}, args...))
+		return buf.String(), err
+	}
+
+	// 存在しないIDは終了コード1で、何も出力しない
+	output, err := run("20990101T000000")
+	var exitErr cli.ExitCoder
+	require.True(t, errors.As(err, &exitErr), err)
+	assert.Equal(t, 1, exitErr.ExitCode())
+	assert.Empty(t, output)
+
+	_, err = run()
+	require.True(t, errors.As(err, &exitErr), err)
+	assert.Equal(t, 1, exitErr.ExitCode())
+}
+
+func TestGrepCommand_ExitCode(t *testing.T) {
+	t.Parallel()
+	tmpDir := newTestDir(t, map[string]string{
+		"20250903T083109--memo.md": "TODO: write tests\n",
+	})
+
+	run := func(args ...string) (string, error) {
+		buf := &bytes.Buffer{}
+		root := &cli.Command{
+			Name:           "parakeet",
+			Writer:         buf,
+			ExitErrHandler: func(context.Context, *cli.Command, error) {},
+			Commands:       []*cli.Command{grepCommand()},
+		}
+		ctx := WithReporter(context.Background(), ReporterFor(buf))
+		err := root.Run(ctx, append([]string{"parakeet", "grep"}, args...))
+		return buf.String(), err
+	}
+
+	output, err := run("TODO", tmpDir)
+	require.NoError(t, err)
+	assert.Contains(t, output, "20250903T083109 | memo | TODO: write tests")
+
+	// 一致しない場合は grep と同じく終了コード1
+	output, err = run("FIXME", tmpDir)
+	var exitErr cli.ExitCoder
+	require.True(t, errors.As(err, &exitErr), err)
+	assert.Equal(t, 1, exitErr.ExitCode())
+	assert.Empty(t, output)
+}
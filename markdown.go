@@ -1,69 +1,369 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+const (
+	// MarkdownBeginMarker は md --update で書き換える部分の開始マーカー
+	MarkdownBeginMarker = "<!-- parakeet:start -->"
+	// MarkdownEndMarker は md --update で書き換える部分の終了マーカー
+	MarkdownEndMarker = "<!-- parakeet:end -->"
+)
+
+// Markdown表に追加できる列
+const (
+	MarkdownColumnExt  = "ext"  // 拡張子
+	MarkdownColumnSize = "size" // サイズ
+	MarkdownColumnDate = "date" // タイムスタンプの日付（YYYY-MM-DD）
+)
+
+// Markdown表をグループ化する単位
+const (
+	MarkdownGroupByTag   = "tag"   // タグごと
+	MarkdownGroupByMonth = "month" // タイムスタンプの年月（YYYY-MM）ごと
 )
 
+// markdownUntaggedGroup はタグでグループ化したときのタグのないファイルの見出し
+const markdownUntaggedGroup = "(untagged)"
+
+// markdownColumnHeaders は追加できる列の見出し
+var markdownColumnHeaders = map[string]string{
+	MarkdownColumnExt:  "Extension",
+	MarkdownColumnSize: "Size",
+	MarkdownColumnDate: "Date",
+}
+
+// MarkdownLayout はMarkdown表の列とリンクの指定を表す
+// 各コマンドのオプションに埋め込んで使う
+type MarkdownLayout struct {
+	Links   bool     // Title 列をファイルへの相対リンクにする
+	Columns []string // ID・Title・Tags の後に追加する列（ext, size, date）
+}
+
+// recordColumns は表の1行のレコードの列を返す（grouped の場合はグループの見出しの列を含める）
+func (l MarkdownLayout) recordColumns(grouped bool) []string {
+	columns := []string{"id"}
+	if grouped {
+		columns = append(columns, "group")
+	}
+	columns = append(columns, "title", "tags")
+	if l.Links {
+		columns = append(columns, "link")
+	}
+	return append(columns, l.Columns...)
+}
+
+// Validate は追加する列の指定をチェックする
+func (l MarkdownLayout) Validate() error {
+	for _, column := range l.Columns {
+		if _, ok := markdownColumnHeaders[column]; !ok {
+			return fmt.Errorf("unknown column: %s (expected ext, size or date)", column)
+		}
+	}
+	return nil
+}
+
 // MarkdownOptions はMarkdown出力操作のオプションを表す
 type MarkdownOptions struct {
-	Writer     io.Writer // 出力先
-	Extensions []string  // 対象拡張子（空の場合は全ファイル）
+	Writer io.Writer // 出力先
+	FilterOptions
+	SortOptions
+	MarkdownLayout
+	GroupBy string     // 見出しごとに表を分ける単位（tag, month、空の場合は1つの表）
+	LinkDir string     // リンクの相対パスの基準ディレクトリ（空の場合は targetDir）
+	FS      FileSystem // ファイルシステム（nil の場合は OS のファイルシステム）
 }
 
 // GenerateMarkdownTable はディレクトリ内のファイル一覧をMarkdown表形式で出力する
 func GenerateMarkdownTable(targetDir string, opts MarkdownOptions) error {
+	fsys := fileSystemOrOS(opts.FS)
+
 	// ディレクトリの存在チェック
-	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+	if _, err := fsys.Stat(targetDir); os.IsNotExist(err) {
 		return fmt.Errorf("directory does not exist: %s", targetDir)
 	}
 
 	// ディレクトリを読み込む
-	entries, err := os.ReadDir(targetDir)
+	entries, err := readDirFS(fsys, targetDir)
 	if err != nil {
 		return fmt.Errorf("failed to read directory: %w", err)
 	}
 
-	// ヘッダーを出力
-	_, _ = fmt.Fprintln(opts.Writer, "| ID | Title | Tags |")
-	_, _ = fmt.Fprintln(opts.Writer, "|---|---|---|")
+	// 並び順と列の指定をチェック
+	if err := opts.SortOptions.Validate(); err != nil {
+		return err
+	}
+	if err := opts.MarkdownLayout.Validate(); err != nil {
+		return err
+	}
+
+	if err := validateMarkdownGroupBy(opts.GroupBy); err != nil {
+		return err
+	}
+
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("file", opts.MarkdownLayout.recordColumns(opts.GroupBy != "")...)
 
 	// ファイルを処理
+	files := []string{}
+	entriesByName := map[string]markdownEntry{}
 	for _, entry := range entries {
-		// ディレクトリはスキップ
-		if entry.IsDir() {
+		// ディレクトリとシムはスキップ
+		if entry.IsDir() || isShim(fsys, targetDir, entry) {
 			continue
 		}
 
 		fileName := entry.Name()
 
 		// 拡張子フィルタリング
-		if !MatchesExtensions(fileName, opts.Extensions) {
+		if !opts.Matches(fileName) {
 			continue
 		}
 
 		// フォーマット済みファイルのみ処理
-		components, err := ParseFileName(fileName)
+		components, err := parseDirEntry(entry)
 		if err != nil {
 			// フォーマット外のファイルはスキップ
 			continue
 		}
 
+		files = append(files, fileName)
+		entriesByName[fileName] = markdownEntry{entry: entry, components: components}
+	}
+
+	if err := opts.SortFiles(files); err != nil {
+		return err
+	}
+
+	linkDir := opts.LinkDir
+	if linkDir == "" {
+		linkDir = targetDir
+	}
+
+	table := markdownTable{
+		reporter:  reporter,
+		fsys:      fsys,
+		targetDir: targetDir,
+		linkDir:   linkDir,
+		layout:    opts.MarkdownLayout,
+		entries:   entriesByName,
+	}
+
+	if opts.GroupBy == "" {
+		return table.write(files, "")
+	}
+
+	// グループごとに見出しと表を出力
+	names, groups := groupMarkdownFiles(files, entriesByName, opts.GroupBy)
+	for i, name := range names {
+		if i > 0 {
+			reporter.Dataf("\n")
+		}
+		reporter.Dataf("## %s\n\n", name)
+		if err := table.write(groups[name], name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// markdownTable はMarkdown表を出力するための設定を表す
+type markdownTable struct {
+	reporter  Reporter
+	fsys      FileSystem
+	targetDir string
+	linkDir   string
+	layout    MarkdownLayout
+	entries   map[string]markdownEntry // ファイル名ごとのエントリ
+}
+
+// markdownEntry は表に出力するファイルのエントリとパースしたファイル名を表す
+type markdownEntry struct {
+	entry      os.DirEntry
+	components *parakeet.FileNameComponents
+}
+
+// write はヘッダーとファイルごとの行を出力する（group はグループ化した場合の見出し）
+func (t markdownTable) write(files []string, group string) error {
+	// ヘッダーを出力
+	headers := []string{"ID", "Title", "Tags"}
+	for _, column := range t.layout.Columns {
+		headers = append(headers, markdownColumnHeaders[column])
+	}
+	t.reporter.Dataf("| %s |\n", strings.Join(headers, " | "))
+	t.reporter.Dataf("|%s\n", strings.Repeat("---|", len(headers)))
+
+	for _, fileName := range files {
+		components := t.entries[fileName].components
+
 		// タグを結合
 		tagsStr := ""
 		if len(components.Tags) > 0 {
 			tagsStr = strings.Join(components.Tags, ", ")
 		}
 
+		fields := map[string]any{
+			"id":    components.Timestamp,
+			"title": components.Comment,
+			"tags":  components.Tags,
+		}
+		if group != "" {
+			fields["group"] = group
+		}
+
+		title := components.Comment
+		if t.layout.Links {
+			link, err := relativeLink(t.linkDir, filepath.Join(t.targetDir, fileName))
+			if err != nil {
+				return err
+			}
+			title = fmt.Sprintf("[%s](%s)", components.Comment, markdownLinkEscaper.Replace(link))
+			// レコードにはエスケープしないパスを含める（--format org ではリンクにする）
+			fields["link"] = link
+		}
+		cells := []string{components.Timestamp, title, tagsStr}
+
+		for _, column := range t.layout.Columns {
+			var cell string
+			switch column {
+			case MarkdownColumnExt:
+				cell = components.Extension
+				fields["ext"] = components.Extension
+			case MarkdownColumnSize:
+				info, err := t.stat(fileName)
+				if err != nil {
+					return fmt.Errorf("failed to get file info: %w", err)
+				}
+				cell = FormatBytes(info.Size())
+				fields["size"] = info.Size()
+			case MarkdownColumnDate:
+				cell = timestampDate(components.Timestamp)
+				fields["date"] = cell
+			}
+			cells = append(cells, cell)
+		}
+
 		// Markdown行を出力
-		_, _ = fmt.Fprintf(opts.Writer, "| %s | %s | %s |\n",
-			components.Timestamp,
-			components.Comment,
-			tagsStr,
-		)
+		t.reporter.Emit("file", fields, "| %s |\n", strings.Join(cells, " | "))
 	}
 
 	return nil
 }
+
+// stat はファイルの情報を返す（キャッシュのエントリはキャッシュした情報を返す）
+func (t markdownTable) stat(fileName string) (fs.FileInfo, error) {
+	if cached, ok := t.entries[fileName].entry.(cachedDirEntry); ok && !cached.entry.Type.IsDir() && cached.entry.Type&fs.ModeSymlink == 0 {
+		return cached.Info()
+	}
+	return t.fsys.Stat(filepath.Join(t.targetDir, fileName))
+}
+
+// validateMarkdownGroupBy はグループ化の指定をチェックする
+func validateMarkdownGroupBy(groupBy string) error {
+	switch groupBy {
+	case "", MarkdownGroupByTag, MarkdownGroupByMonth:
+		return nil
+	}
+	return fmt.Errorf("unknown group: %s (expected tag or month)", groupBy)
+}
+
+// groupMarkdownFiles はファイルをグループに分け、見出しの一覧（昇順）とグループごとのファイルを返す
+// タグでグループ化する場合、複数のタグを持つファイルはそれぞれのグループに含め、タグのないファイルは最後のグループにまとめる
+// グループ内のファイルは files の順序を保つ
+func groupMarkdownFiles(files []string, entries map[string]markdownEntry, groupBy string) ([]string, map[string][]string) {
+	groups := map[string][]string{}
+	for _, fileName := range files {
+		components := entries[fileName].components
+
+		var keys []string
+		switch groupBy {
+		case MarkdownGroupByTag:
+			keys = components.Tags
+			if len(keys) == 0 {
+				keys = []string{markdownUntaggedGroup}
+			}
+		case MarkdownGroupByMonth:
+			keys = []string{timestampMonth(components.Timestamp)}
+		}
+		for _, key := range keys {
+			groups[key] = append(groups[key], fileName)
+		}
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		if name != markdownUntaggedGroup {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if _, ok := groups[markdownUntaggedGroup]; ok {
+		names = append(names, markdownUntaggedGroup)
+	}
+	return names, groups
+}
+
+// timestampMonth はタイムスタンプの年月を YYYY-MM 形式で返す（解釈できない場合はそのまま返す）
+func timestampMonth(timestamp string) string {
+	t, err := time.Parse(timestampLayout, timestamp)
+	if err != nil {
+		return timestamp
+	}
+	return t.Format("2006-01")
+}
+
+// relativeLink は base ディレクトリから path へのリンク用の相対パス（/ 区切り）を返す
+func relativeLink(base, path string) (string, error) {
+	rel, err := filepath.Rel(base, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve link: %w", err)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// markdownLinkEscaper はMarkdownのリンクが途切れないように、リンク先の空白と括弧をエスケープする
+var markdownLinkEscaper = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29")
+
+// timestampDate はタイムスタンプの日付部分を YYYY-MM-DD 形式で返す（解釈できない場合はそのまま返す）
+func timestampDate(timestamp string) string {
+	t, err := time.Parse(timestampLayout, timestamp)
+	if err != nil {
+		return timestamp
+	}
+	return t.Format(dateLayout)
+}
+
+// UpdateMarkdownFile は既存のファイル（README.md など）のマーカーの間をファイル一覧のMarkdown表で置き換える
+// 手書きの部分は保持し、内容が変わらない場合は書き込まない
+func UpdateMarkdownFile(targetDir, path string, opts MarkdownOptions) error {
+	reporter := ReporterFor(opts.Writer)
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("file does not exist: %s", path)
+	}
+
+	// リンクは書き込むファイルからの相対パスにする
+	if opts.LinkDir == "" {
+		opts.LinkDir = filepath.Dir(path)
+	}
+
+	var table bytes.Buffer
+	opts.Writer = &table
+	if err := GenerateMarkdownTable(targetDir, opts); err != nil {
+		return err
+	}
+
+	return updateMarkedFile(path, table.String(), MarkdownBeginMarker, MarkdownEndMarker, reporter)
+}
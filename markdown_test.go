@@ -35,8 +35,8 @@ func TestGenerateMarkdownTable(t *testing.T) {
 	// Generate markdown table
 	buf := &bytes.Buffer{}
 	opts := MarkdownOptions{
-		Writer:     buf,
-		Extensions: nil, // すべてのファイルを対象
+		Writer:        buf,
+		FilterOptions: FilterOptions{Extensions: nil}, // すべてのファイルを対象
 	}
 
 	err = GenerateMarkdownTable(tmpDir, opts)
@@ -85,8 +85,8 @@ func TestGenerateMarkdownTable_WithExtensionFilter(t *testing.T) {
 	// Generate markdown table with extension filter
 	buf := &bytes.Buffer{}
 	opts := MarkdownOptions{
-		Writer:     buf,
-		Extensions: []string{"pdf"},
+		Writer:        buf,
+		FilterOptions: FilterOptions{Extensions: []string{"pdf"}},
 	}
 
 	err = GenerateMarkdownTable(tmpDir, opts)
@@ -115,8 +115,8 @@ func TestGenerateMarkdownTable_EmptyDirectory(t *testing.T) {
 	// Generate markdown table
 	buf := &bytes.Buffer{}
 	opts := MarkdownOptions{
-		Writer:     buf,
-		Extensions: nil,
+		Writer:        buf,
+		FilterOptions: FilterOptions{Extensions: nil},
 	}
 
 	err = GenerateMarkdownTable(tmpDir, opts)
@@ -155,8 +155,8 @@ func TestGenerateMarkdownTable_NoTags(t *testing.T) {
 	// Generate markdown table
 	buf := &bytes.Buffer{}
 	opts := MarkdownOptions{
-		Writer:     buf,
-		Extensions: nil,
+		Writer:        buf,
+		FilterOptions: FilterOptions{Extensions: nil},
 	}
 
 	err = GenerateMarkdownTable(tmpDir, opts)
@@ -173,8 +173,8 @@ func TestGenerateMarkdownTable_NonExistentDirectory(t *testing.T) {
 	t.Parallel()
 	buf := &bytes.Buffer{}
 	opts := MarkdownOptions{
-		Writer:     buf,
-		Extensions: nil,
+		Writer:        buf,
+		FilterOptions: FilterOptions{Extensions: nil},
 	}
 
 	err := GenerateMarkdownTable("/non/existent/directory", opts)
@@ -204,8 +204,8 @@ func TestGenerateMarkdownTable_MultipleTags(t *testing.T) {
 	// Generate markdown table
 	buf := &bytes.Buffer{}
 	opts := MarkdownOptions{
-		Writer:     buf,
-		Extensions: nil,
+		Writer:        buf,
+		FilterOptions: FilterOptions{Extensions: nil},
 	}
 
 	err = GenerateMarkdownTable(tmpDir, opts)
@@ -217,3 +217,143 @@ func TestGenerateMarkdownTable_MultipleTags(t *testing.T) {
 	assert.Contains(t, output, "| 20250903T083109 | document | tag1, tag2, tag3 |")
 	assert.Contains(t, output, "| 20250903T083110 | note | urgent, important |")
 }
+
+func TestGenerateMarkdownTable_LinksAndColumns(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-markdown-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250903T083109--tcp ip (draft)__network.pdf"), make([]byte, 2048), 0644))
+
+	buf := &bytes.Buffer{}
+	err = GenerateMarkdownTable(tmpDir, MarkdownOptions{
+		Writer:         buf,
+		MarkdownLayout: MarkdownLayout{Links: true, Columns: []string{MarkdownColumnDate, MarkdownColumnExt, MarkdownColumnSize}},
+	})
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, []string{
+		"| ID | Title | Tags | Date | Extension | Size |",
+		"|---|---|---|---|---|---|",
+		"| 20250903T083109 | [tcp ip (draft)](20250903T083109--tcp%20ip%20%28draft%29__network.pdf) | network | 2025-09-03 | pdf | 2.0KiB |",
+	}, lines)
+
+	// リンクは LinkDir からの相対パスにする
+	buf = &bytes.Buffer{}
+	err = GenerateMarkdownTable(tmpDir, MarkdownOptions{
+		Writer:         buf,
+		MarkdownLayout: MarkdownLayout{Links: true},
+		LinkDir:        filepath.Join(tmpDir, "docs"),
+	})
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "](../20250903T083109--tcp%20ip%20%28draft%29__network.pdf)")
+
+	err = GenerateMarkdownTable(tmpDir, MarkdownOptions{
+		Writer:         &bytes.Buffer{},
+		MarkdownLayout: MarkdownLayout{Columns: []string{"owner"}},
+	})
+	assert.ErrorContains(t, err, "unknown column: owner")
+}
+
+func TestGenerateMarkdownTable_GroupBy(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-markdown-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	for _, name := range []string{
+		"20250903T083109--tcpip__network_infra.pdf",
+		"20251001T000000--dns__network.pdf",
+		"20251002T000000--memo.md",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("test"), 0644))
+	}
+
+	// タグごと（複数のタグを持つファイルはそれぞれに、タグのないファイルは最後に出力）
+	buf := &bytes.Buffer{}
+	err = GenerateMarkdownTable(tmpDir, MarkdownOptions{Writer: buf, GroupBy: MarkdownGroupByTag})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"## infra",
+		"",
+		"| ID | Title | Tags |",
+		"|---|---|---|",
+		"| 20250903T083109 | tcpip | network, infra |",
+		"",
+		"## network",
+		"",
+		"| ID | Title | Tags |",
+		"|---|---|---|",
+		"| 20250903T083109 | tcpip | network, infra |",
+		"| 20251001T000000 | dns | network |",
+		"",
+		"## (untagged)",
+		"",
+		"| ID | Title | Tags |",
+		"|---|---|---|",
+		"| 20251002T000000 | memo |  |",
+	}, strings.Split(strings.TrimSpace(buf.String()), "\n"))
+
+	// 年月ごと
+	buf = &bytes.Buffer{}
+	err = GenerateMarkdownTable(tmpDir, MarkdownOptions{Writer: buf, GroupBy: MarkdownGroupByMonth})
+	require.NoError(t, err)
+	output := buf.String()
+	assert.Equal(t, 2, strings.Count(output, "| ID | Title | Tags |"))
+	assert.Less(t, strings.Index(output, "## 2025-09\n"), strings.Index(output, "## 2025-10\n"))
+	assert.Less(t, strings.Index(output, "## 2025-10\n"), strings.Index(output, "| 20251001T000000 | dns | network |"))
+
+	err = GenerateMarkdownTable(tmpDir, MarkdownOptions{Writer: &bytes.Buffer{}, GroupBy: "year"})
+	assert.ErrorContains(t, err, "unknown group: year")
+}
+
+func TestUpdateMarkdownFile(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-markdown-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	filesDir := filepath.Join(tmpDir, "files")
+	require.NoError(t, os.Mkdir(filesDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(filesDir, "20250903T083109--TCPIP入門__network.pdf"), []byte("x"), 0644))
+
+	readmePath := filepath.Join(tmpDir, "README.md")
+	handWritten := "# Archive\n\n" + MarkdownBeginMarker + "\nstale\n" + MarkdownEndMarker + "\n\nFooter\n"
+	require.NoError(t, os.WriteFile(readmePath, []byte(handWritten), 0644))
+
+	opts := MarkdownOptions{Writer: &bytes.Buffer{}, MarkdownLayout: MarkdownLayout{Links: true}}
+	require.NoError(t, UpdateMarkdownFile(filesDir, readmePath, opts))
+
+	data, err := os.ReadFile(readmePath)
+	require.NoError(t, err)
+	assert.Equal(t, "# Archive\n\n"+MarkdownBeginMarker+"\n"+
+		"| ID | Title | Tags |\n"+
+		"|---|---|---|\n"+
+		"| 20250903T083109 | [TCPIP入門](files/20250903T083109--TCPIP入門__network.pdf) | network |\n"+
+		MarkdownEndMarker+"\n\nFooter\n", string(data))
+
+	// 2回目は変更なし
+	buf := &bytes.Buffer{}
+	opts.Writer = buf
+	require.NoError(t, UpdateMarkdownFile(filesDir, readmePath, opts))
+	assert.Contains(t, buf.String(), "✓ No changes made")
+
+	err = UpdateMarkdownFile(filesDir, filepath.Join(tmpDir, "missing.md"), opts)
+	assert.ErrorContains(t, err, "file does not exist")
+}
+
+func TestGenerateMarkdownTable_Quiet(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250903T083109--file1__go.pdf"), []byte("test content"), 0644))
+
+	// --quiet でも表のヘッダーと見出しは出力する
+	buf := &bytes.Buffer{}
+	reporter, err := NewReporter(buf, ReporterConfig{Format: FormatHuman, Level: LevelQuiet})
+	require.NoError(t, err)
+	require.NoError(t, GenerateMarkdownTable(tmpDir, MarkdownOptions{Writer: reporter, GroupBy: MarkdownGroupByTag}))
+
+	assert.Equal(t, "## go\n\n| ID | Title | Tags |\n|---|---|---|\n| 20250903T083109 | file1 | go |\n", buf.String())
+}
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// MCPProtocolVersions は対応する Model Context Protocol のバージョン（新しい順）
+var MCPProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// JSON-RPC のエラーコード
+const (
+	jsonRPCParseError     = -32700
+	jsonRPCInvalidRequest = -32600
+	jsonRPCMethodNotFound = -32601
+	jsonRPCInvalidParams  = -32602
+)
+
+// MCPOptions は MCP サーバーのオプションを表す
+type MCPOptions struct {
+	TagsFile string      // タグ定義ファイル（空の場合は tags.toml、ResolveTagsFile で解決する）
+	Hooks    *HookRunner // リネームの前後に実行するフック（nil の場合は実行しない）
+}
+
+// jsonRPCMessage は JSON-RPC 2.0 のリクエスト・通知を表す
+type jsonRPCMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// jsonRPCResponse は JSON-RPC 2.0 のレスポンスを表す
+type jsonRPCResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *jsonRPCError   `json:"error,omitempty"`
+}
+
+// jsonRPCError は JSON-RPC 2.0 のエラーを表す
+type jsonRPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// mcpTool は MCP のツールの定義を表す
+type mcpTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+// mcpTools は parakeet が公開するツール
+var mcpTools = []mcpTool{
+	{
+		Name:        "list_files",
+		Description: "List formatted files with their parsed ID (timestamp), title, tags, extension, size and modification time.",
+		InputSchema: mcpSchema(map[string]any{
+			"ext":   map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "Extensions to include, e.g. [\"pdf\", \"md\"]"},
+			"query": map[string]any{"type": "string", "description": "Selection query, e.g. 'tag:network AND ext:pdf AND date>=2025-01'"},
+			"since": map[string]any{"type": "string", "description": "Only files at or after this date (YYYY-MM-DD, 7d, yesterday, ...)"},
+			"until": map[string]any{"type": "string", "description": "Only files at or before this date"},
+		}),
+	},
+	{
+		Name:        "search_files",
+		Description: "Search formatted files by tags and title. Returns matching file names.",
+		InputSchema: mcpSchema(map[string]any{
+			"tags":  map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "Tags the files must have"},
+			"any":   map[string]any{"type": "boolean", "description": "Match files with any of the tags instead of all"},
+			"title": map[string]any{"type": "string", "description": "Case-insensitive substring of the title"},
+			"since": map[string]any{"type": "string", "description": "Only files at or after this date (YYYY-MM-DD, 7d, yesterday, ...)"},
+			"until": map[string]any{"type": "string", "description": "Only files at or before this date"},
+		}),
+	},
+	{
+		Name:        "get_file",
+		Description: "Get the metadata of the file with the given ID (timestamp such as 20250903T083109).",
+		InputSchema: mcpSchema(map[string]any{
+			"id": map[string]any{"type": "string", "description": "File ID (timestamp)"},
+		}, "id"),
+	},
+	{
+		Name:        "set_tags",
+		Description: "Replace the tags of the file with the given ID. Tags are validated against tags.toml and the file is renamed safely; the rename is recorded so it can be reverted with `parakeet undo`.",
+		InputSchema: mcpSchema(map[string]any{
+			"id":   map[string]any{"type": "string", "description": "File ID (timestamp)"},
+			"tags": map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "New tags (an empty list removes all tags)"},
+		}, "id", "tags"),
+	},
+}
+
+// mcpSchema はツールの引数の JSON Schema を作成する
+func mcpSchema(properties map[string]any, required ...string) map[string]any {
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// ServeMCP は dirPath のアーカイブを Model Context Protocol のサーバーとして in/out（改行区切りの JSON-RPC）で公開する
+// AI アシスタントは生の mv ではなく、parakeet のバリデーションとリネームを通してファイルを整理できる
+// in が EOF になるかコンテキストがキャンセルされるまで処理を続ける
+func ServeMCP(ctx context.Context, dirPath string, in io.Reader, out io.Writer, opts MCPOptions) error {
+	// ディレクトリの存在チェック
+	if _, err := os.Stat(dirPath); os.IsNotExist(err) {
+		return fmt.Errorf("directory does not exist: %s", dirPath)
+	}
+
+	server := &mcpServer{dir: dirPath, opts: opts}
+	encoder := json.NewEncoder(out)
+	reader := bufio.NewReader(in)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if response := server.handle(line); response != nil {
+				if err := encoder.Encode(response); err != nil {
+					return fmt.Errorf("failed to write response: %w", err)
+				}
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to read request: %w", err)
+		}
+	}
+}
+
+// mcpServer は MCP のリクエストを処理する
+type mcpServer struct {
+	dir  string
+	opts MCPOptions
+}
+
+// handle は1行のメッセージを処理し、レスポンスを返す（通知の場合は nil）
+func (s *mcpServer) handle(line []byte) *jsonRPCResponse {
+	var message jsonRPCMessage
+	if err := json.Unmarshal(line, &message); err != nil {
+		return &jsonRPCResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &jsonRPCError{Code: jsonRPCParseError, Message: "parse error"}}
+	}
+
+	// ID のないメッセージは通知（レスポンスを返さない）
+	if len(message.ID) == 0 {
+		return nil
+	}
+
+	response := &jsonRPCResponse{JSONRPC: "2.0", ID: message.ID}
+	if message.JSONRPC != "2.0" || message.Method == "" {
+		response.Error = &jsonRPCError{Code: jsonRPCInvalidRequest, Message: "invalid request"}
+		return response
+	}
+
+	switch message.Method {
+	case "initialize":
+		response.Result = s.initialize(message.Params)
+	case "ping":
+		response.Result = map[string]any{}
+	case "tools/list":
+		response.Result = map[string]any{"tools": mcpTools}
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(message.Params, &params); err != nil || params.Name == "" {
+			response.Error = &jsonRPCError{Code: jsonRPCInvalidParams, Message: "invalid params"}
+			return response
+		}
+		result, err := s.callTool(params.Name, params.Arguments)
+		if errors.Is(err, errUnknownMCPTool) {
+			response.Error = &jsonRPCError{Code: jsonRPCInvalidParams, Message: err.Error()}
+			return response
+		}
+		response.Result = mcpToolResult(result, err)
+	default:
+		response.Error = &jsonRPCError{Code: jsonRPCMethodNotFound, Message: "method not found: " + message.Method}
+	}
+
+	return response
+}
+
+// initialize はクライアントが要求したプロトコルのバージョンに対応していればそれを、そうでなければ最新のバージョンを返す
+func (s *mcpServer) initialize(params json.RawMessage) map[string]any {
+	var request struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	_ = json.Unmarshal(params, &request)
+
+	version := MCPProtocolVersions[0]
+	if slices.Contains(MCPProtocolVersions, request.ProtocolVersion) {
+		version = request.ProtocolVersion
+	}
+
+	return map[string]any{
+		"protocolVersion": version,
+		"capabilities":    map[string]any{"tools": map[string]any{}},
+		"serverInfo":      map[string]any{"name": "parakeet", "version": "1.0.0"},
+	}
+}
+
+// errUnknownMCPTool は存在しないツールが呼び出されたことを表す
+var errUnknownMCPTool = errors.New("unknown tool")
+
+// mcpArguments はツールの引数を表す
+type mcpArguments struct {
+	Ext   []string `json:"ext"`
+	Query string   `json:"query"`
+	Since string   `json:"since"`
+	Until string   `json:"until"`
+	Tags  []string `json:"tags"`
+	Any   bool     `json:"any"`
+	Title string   `json:"title"`
+	ID    string   `json:"id"`
+}
+
+// filter は引数から絞り込み条件を作成する
+func (a mcpArguments) filter() (FilterOptions, error) {
+	since, err := ParseDateBound(a.Since, false)
+	if err != nil {
+		return FilterOptions{}, fmt.Errorf("invalid since: %w", err)
+	}
+	until, err := ParseDateBound(a.Until, true)
+	if err != nil {
+		return FilterOptions{}, fmt.Errorf("invalid until: %w", err)
+	}
+
+	filter := FilterOptions{Extensions: a.Ext, Since: since, Until: until}
+	if a.Query != "" {
+		if filter.Query, err = ParseQuery(a.Query); err != nil {
+			return FilterOptions{}, err
+		}
+	}
+	return filter, nil
+}
+
+// callTool はツールを実行し、結果（JSON に変換する値）を返す
+func (s *mcpServer) callTool(name string, rawArguments json.RawMessage) (any, error) {
+	var args mcpArguments
+	if len(rawArguments) > 0 {
+		if err := json.Unmarshal(rawArguments, &args); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	switch name {
+	case "list_files":
+		filter, err := args.filter()
+		if err != nil {
+			return nil, err
+		}
+		return ExportIndex(s.dir, ExportIndexOptions{Writer: &bytes.Buffer{}, FilterOptions: filter})
+	case "search_files":
+		filter, err := args.filter()
+		if err != nil {
+			return nil, err
+		}
+		return SearchFiles(s.dir, SearchOptions{Writer: &bytes.Buffer{}, FilterOptions: filter, Tags: args.Tags, MatchAnyTag: args.Any, Title: args.Title})
+	case "get_file":
+		return s.getFile(args.ID)
+	case "set_tags":
+		if args.Tags == nil {
+			return nil, fmt.Errorf("tags is required")
+		}
+		return s.setTags(args.ID, args.Tags)
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnknownMCPTool, name)
+	}
+}
+
+// getFile はIDに一致するファイルの情報を返す
+func (s *mcpServer) getFile(id string) (*IndexRecord, error) {
+	if id == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+
+	path, err := FindFileByID(s.dir, id)
+	if err != nil {
+		return nil, err
+	}
+	components, err := parakeet.ParseFileName(filepath.Base(path))
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info: %w", err)
+	}
+
+	tags := components.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return &IndexRecord{
+		Path:      path,
+		Timestamp: components.Timestamp,
+		Comment:   components.Comment,
+		Tags:      tags,
+		Extension: components.Extension,
+		Size:      info.Size(),
+		ModTime:   info.ModTime(),
+	}, nil
+}
+
+// setTags はIDに一致するファイルのタグを置き換え、リネーム後のファイルの情報を返す
+func (s *mcpServer) setTags(id string, tags []string) (*IndexRecord, error) {
+	if id == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+
+	path, err := FindFileByID(s.dir, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var output bytes.Buffer
+	if err := SetTags(path, slices.Clone(tags), TagOptions{
+		Writer:   &output,
+		Hooks:    s.opts.Hooks,
+		TagsFile: s.opts.TagsFile,
+		Journal:  NewJournal(s.dir, "mcp set_tags"),
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.getFile(id)
+}
+
+// mcpToolResult はツールの結果を MCP の tools/call の結果に変換する（ツールのエラーは isError で返す）
+func mcpToolResult(result any, err error) map[string]any {
+	if err != nil {
+		return map[string]any{
+			"content": []map[string]any{{"type": "text", "text": strings.TrimSpace(err.Error())}},
+			"isError": true,
+		}
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcpToolResult(nil, fmt.Errorf("failed to encode result: %w", err))
+	}
+	return map[string]any{
+		"content": []map[string]any{{"type": "text", "text": string(data)}},
+		"isError": false,
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runMCP は改行区切りのリクエストを MCP サーバーに渡し、レスポンスを返す
+func runMCP(t *testing.T, dir string, requests ...string) []map[string]any {
+	t.Helper()
+
+	var out bytes.Buffer
+	require.NoError(t, ServeMCP(context.Background(), dir, strings.NewReader(strings.Join(requests, "\n")+"\n"), &out, MCPOptions{}))
+
+	responses := []map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var response map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &response))
+		responses = append(responses, response)
+	}
+	return responses
+}
+
+// mcpResultText はツールの結果のテキストを返す
+func mcpResultText(t *testing.T, response map[string]any) (string, bool) {
+	t.Helper()
+
+	result, ok := response["result"].(map[string]any)
+	require.True(t, ok, response)
+	content := result["content"].([]any)
+	require.Len(t, content, 1)
+	return content[0].(map[string]any)["text"].(string), result["isError"].(bool)
+}
+
+func TestServeMCP_Protocol(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-mcp-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	responses := runMCP(t, tmpDir,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"0"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"unknown"}`,
+		`not json`,
+	)
+	require.Len(t, responses, 4)
+
+	// 対応するバージョンはそのまま返す
+	result := responses[0]["result"].(map[string]any)
+	assert.Equal(t, "2025-03-26", result["protocolVersion"])
+	assert.Equal(t, "parakeet", result["serverInfo"].(map[string]any)["name"])
+
+	names := []string{}
+	for _, tool := range responses[1]["result"].(map[string]any)["tools"].([]any) {
+		names = append(names, tool.(map[string]any)["name"].(string))
+	}
+	assert.Equal(t, []string{"list_files", "search_files", "get_file", "set_tags"}, names)
+
+	assert.Equal(t, float64(jsonRPCMethodNotFound), responses[2]["error"].(map[string]any)["code"])
+	assert.Nil(t, responses[3]["id"])
+	assert.Equal(t, float64(jsonRPCParseError), responses[3]["error"].(map[string]any)["code"])
+}
+
+func TestServeMCP_Tools(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-mcp-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "tags.toml"), []byte("[[tag]]\nkey = \"network\"\ndesc = \"Network related\"\n\n[[tag]]\nkey = \"infra\"\ndesc = \"Infra related\"\n"), 0644))
+	for _, name := range []string{"20250903T083109--tcpip__network.pdf", "20250904T000000--memo.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("content"), 0644))
+	}
+
+	responses := runMCP(t, tmpDir,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"list_files","arguments":{"ext":["pdf"]}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"search_files","arguments":{"tags":["network"]}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"get_file","arguments":{"id":"20250904T000000"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"set_tags","arguments":{"id":"20250904T000000","tags":["infra"]}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"set_tags","arguments":{"id":"20250904T000000","tags":["unknown"]}}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"get_file","arguments":{"id":"20990101T000000"}}}`,
+		`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"rm","arguments":{}}}`,
+	)
+	require.Len(t, responses, 7)
+
+	var records []IndexRecord
+	text, isError := mcpResultText(t, responses[0])
+	require.False(t, isError, text)
+	require.NoError(t, json.Unmarshal([]byte(text), &records))
+	require.Len(t, records, 1)
+	assert.Equal(t, "tcpip", records[0].Comment)
+
+	var files []string
+	text, isError = mcpResultText(t, responses[1])
+	require.False(t, isError, text)
+	require.NoError(t, json.Unmarshal([]byte(text), &files))
+	assert.Equal(t, []string{"20250903T083109--tcpip__network.pdf"}, files)
+
+	var record IndexRecord
+	text, isError = mcpResultText(t, responses[2])
+	require.False(t, isError, text)
+	require.NoError(t, json.Unmarshal([]byte(text), &record))
+	assert.Equal(t, "memo", record.Comment)
+	assert.Equal(t, []string{}, record.Tags)
+
+	// タグの設定はリネームし、履歴に記録する
+	text, isError = mcpResultText(t, responses[3])
+	require.False(t, isError, text)
+	require.NoError(t, json.Unmarshal([]byte(text), &record))
+	assert.Equal(t, []string{"infra"}, record.Tags)
+	assert.FileExists(t, filepath.Join(tmpDir, "20250904T000000--memo__infra.md"))
+	assert.NoFileExists(t, filepath.Join(tmpDir, "20250904T000000--memo.md"))
+
+	// 定義されていないタグと存在しないIDはツールのエラー
+	_, isError = mcpResultText(t, responses[4])
+	assert.True(t, isError)
+	assert.FileExists(t, filepath.Join(tmpDir, "20250904T000000--memo__infra.md"))
+	_, isError = mcpResultText(t, responses[5])
+	assert.True(t, isError)
+
+	// 存在しないツールはプロトコルのエラー
+	assert.Equal(t, float64(jsonRPCInvalidParams), responses[6]["error"].(map[string]any)["code"])
+}
+
+func TestServeMCP_NonExistentDirectory(t *testing.T) {
+	t.Parallel()
+	err := ServeMCP(context.Background(), "/non/existent", strings.NewReader(""), &bytes.Buffer{}, MCPOptions{})
+	assert.ErrorContains(t, err, "directory does not exist")
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing/fstest"
+)
+
+// MemFileSystem はテストで使うメモリ上のファイルシステム
+// ディスクに書き込まずに操作の結果を確かめる
+// パスはルートからの相対パスとして扱い、先頭の / は無視する
+type MemFileSystem struct {
+	mu    sync.Mutex
+	files fstest.MapFS
+}
+
+// NewMemFileSystem は files を初期内容とするメモリ上のファイルシステムを作成する
+// files は複製せずにそのまま使うため、操作の結果を files から確認できる
+func NewMemFileSystem(files fstest.MapFS) *MemFileSystem {
+	if files == nil {
+		files = fstest.MapFS{}
+	}
+	return &MemFileSystem{files: files}
+}
+
+// memPath は fstest.MapFS で使うパス（スラッシュ区切りで先頭の / がないもの）に変換する
+func memPath(name string) string {
+	p := strings.TrimPrefix(path.Clean(filepath.ToSlash(name)), "/")
+	if p == "" {
+		return "."
+	}
+	return p
+}
+
+// Stat はファイルの情報を返す
+func (m *MemFileSystem) Stat(name string) (fs.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.files.Stat(memPath(name))
+}
+
+// ReadDir はディレクトリ内のエントリを名前順に返す
+func (m *MemFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.files.ReadDir(memPath(name))
+}
+
+// ReadFile はファイルの内容を返す
+func (m *MemFileSystem) ReadFile(name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.files.ReadFile(memPath(name))
+}
+
+// Readlink はシンボリックリンクのリンク先を返す（リンク先は Data に保持する）
+func (m *MemFileSystem) Readlink(name string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	file, ok := m.files[memPath(name)]
+	if !ok {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrNotExist}
+	}
+	if file.Mode&fs.ModeSymlink == 0 {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+	}
+	return string(file.Data), nil
+}
+
+// Rename はファイルをリネームする
+// os.Rename と同じように、リネーム先のファイルは上書きする（ディレクトリのリネームには対応しない）
+func (m *MemFileSystem) Rename(oldpath, newpath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	from, to := memPath(oldpath), memPath(newpath)
+	file, ok := m.files[from]
+	if !ok {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.ErrNotExist}
+	}
+	if file.Mode.IsDir() {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fmt.Errorf("renaming directories is not supported")}
+	}
+	if info, err := m.files.Stat(path.Dir(to)); err != nil || !info.IsDir() {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.ErrNotExist}
+	}
+	delete(m.files, from)
+	m.files[to] = file
+	return nil
+}
+
+// Symlink は newname に oldname へのシンボリックリンクを作成する
+func (m *MemFileSystem) Symlink(oldname, newname string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name := memPath(newname)
+	if _, ok := m.files[name]; ok {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: fs.ErrExist}
+	}
+	m.files[name] = &fstest.MapFile{Data: []byte(oldname), Mode: fs.ModeSymlink | 0777}
+	return nil
+}
+
+// MkdirAll はディレクトリを親ディレクトリも含めて作成する
+func (m *MemFileSystem) MkdirAll(name string, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for dir := memPath(name); dir != "."; dir = path.Dir(dir) {
+		if file, ok := m.files[dir]; ok {
+			if !file.Mode.IsDir() {
+				return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrExist}
+			}
+			continue
+		}
+		m.files[dir] = &fstest.MapFile{Mode: fs.ModeDir | perm}
+	}
+	return nil
+}
+
+// Abs はルートを / とした絶対パスを返す
+func (m *MemFileSystem) Abs(name string) (string, error) {
+	p := memPath(name)
+	if p == "." {
+		return "/", nil
+	}
+	return "/" + p, nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// referenceExtensions は参照の書き換え対象とするテキストファイルの拡張子
+var referenceExtensions = []string{"md", "org", "txt"}
+
+// MoveOptions はファイル移動操作のオプションを表す
+type MoveOptions struct {
+	Writer      io.Writer   // 出力先
+	RewriteRefs bool        // 移動元ディレクトリのテキストファイル内の参照を書き換える
+	Depth       int         // IDでファイルを検索するサブディレクトリの深さ（0 の場合は srcDir の直下のみ）
+	Hooks       *HookRunner // リネームの前後に実行するフック（nil の場合は実行しない）
+}
+
+// MoveFileByID はIDで指定したファイルを別の管理ディレクトリへ移動し、移動後のパスを返す
+// 移動先に同じIDのファイルが既に存在する場合はエラーを返す
+func MoveFileByID(srcDir, id, targetDir string, opts MoveOptions) (string, error) {
+	reporter := ReporterFor(opts.Writer)
+
+	filePath, err := FindFileByIDWithDepth(srcDir, id, opts.Depth)
+	if err != nil {
+		return "", fmt.Errorf("file not found: %w", err)
+	}
+
+	// 移動先ディレクトリのチェック
+	info, err := os.Stat(targetDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("directory does not exist: %s", targetDir)
+		}
+		return "", fmt.Errorf("failed to access directory: %w", err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("target is not a directory: %s", targetDir)
+	}
+
+	// 移動先でのIDの一意性をチェック
+	// 短縮したIDでも指定できるため、見つかったファイル名のタイムスタンプで比べる
+	fileName := filepath.Base(filePath)
+	components, err := parakeet.ParseFileName(fileName)
+	if err != nil {
+		return "", fmt.Errorf("file name is not in correct format: %w", err)
+	}
+	existingTimestamps, err := CollectExistingTimestamps(targetDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to collect existing timestamps: %w", err)
+	}
+	if existingTimestamps[components.Timestamp] {
+		return "", fmt.Errorf("ID %s already exists in %s", components.Timestamp, targetDir)
+	}
+
+	newPath := filepath.Join(targetDir, fileName)
+	if _, err := os.Stat(newPath); err == nil {
+		return "", fmt.Errorf("target file already exists: %s", newPath)
+	}
+
+	if err := opts.Hooks.RenameFile(filePath, newPath); err != nil {
+		return "", fmt.Errorf("failed to move file: %w", err)
+	}
+
+	reporter.Successf("Moved: %s → %s\n", filePath, newPath)
+
+	if opts.RewriteRefs {
+		rel, err := filepath.Rel(srcDir, newPath)
+		if err != nil {
+			return newPath, fmt.Errorf("failed to resolve relative path: %w", err)
+		}
+		if err := rewriteReferences(srcDir, fileName, filepath.ToSlash(rel), opts.Writer); err != nil {
+			return newPath, err
+		}
+	}
+
+	return newPath, nil
+}
+
+// rewriteReferences はディレクトリ内のテキストファイルに含まれる oldRef を newRef に書き換える
+func rewriteReferences(dirPath, oldRef, newRef string, w io.Writer) error {
+	reporter := ReporterFor(w)
+
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !parakeet.MatchesExtensions(entry.Name(), referenceExtensions) {
+			continue
+		}
+
+		path := filepath.Join(dirPath, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+
+		if !bytes.Contains(data, []byte(oldRef)) {
+			continue
+		}
+
+		updated := bytes.ReplaceAll(data, []byte(oldRef), []byte(newRef))
+		if err := rewriteFile(path, updated); err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+
+		reporter.Successf("Updated references: %s\n", entry.Name())
+	}
+
+	return nil
+}
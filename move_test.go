@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoveFileByID(t *testing.T) {
+	t.Parallel()
+	// Create temporary directories
+	srcDir, err := os.MkdirTemp("", "parakeet-mv-src-*")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(srcDir) }()
+	dstDir, err := os.MkdirTemp("", "parakeet-mv-dst-*")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dstDir) }()
+
+	fileName := "20250903T083109--TCPIP入門__network.pdf"
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, fileName), []byte("content"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "20250903T083110--index.md"), []byte("see ["+fileName+"]("+fileName+")\n"), 0644))
+
+	buf := &bytes.Buffer{}
+	newPath, err := MoveFileByID(srcDir, "20250903T083109", dstDir, MoveOptions{Writer: buf, RewriteRefs: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(dstDir, fileName), newPath)
+	assert.FileExists(t, newPath)
+	assert.NoFileExists(t, filepath.Join(srcDir, fileName))
+	assert.Contains(t, buf.String(), "✓ Moved:")
+
+	// 参照が書き換えられている
+	rel, err := filepath.Rel(srcDir, newPath)
+	require.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(srcDir, "20250903T083110--index.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "("+filepath.ToSlash(rel)+")")
+	assert.Contains(t, buf.String(), "✓ Updated references: 20250903T083110--index.md")
+}
+
+func TestMoveFileByID_Errors(t *testing.T) {
+	t.Parallel()
+	// Create temporary directories
+	srcDir, err := os.MkdirTemp("", "parakeet-mv-err-src-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(srcDir) })
+	dstDir, err := os.MkdirTemp("", "parakeet-mv-err-dst-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dstDir) })
+
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "20250903T083109--a.pdf"), []byte("a"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dstDir, "20250903T083109--other.pdf"), []byte("b"), 0644))
+
+	tests := []struct {
+		name      string
+		id        string
+		targetDir string
+		errorText string
+	}{
+		{
+			name:      "ID not found",
+			id:        "20250903T000000",
+			targetDir: dstDir,
+			errorText: "file not found",
+		},
+		{
+			name:      "target directory does not exist",
+			id:        "20250903T083109",
+			targetDir: "/non/existent",
+			errorText: "directory does not exist",
+		},
+		{
+			name:      "ID already exists in target",
+			id:        "20250903T083109",
+			targetDir: dstDir,
+			errorText: "already exists",
+		},
+		{
+			name:      "short ID already exists in target",
+			id:        "083109",
+			targetDir: dstDir,
+			errorText: "ID 20250903T083109 already exists",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := MoveFileByID(srcDir, tt.id, tt.targetDir, MoveOptions{Writer: &bytes.Buffer{}})
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorText)
+
+			// 移動元のファイルは残っている
+			assert.FileExists(t, filepath.Join(srcDir, "20250903T083109--a.pdf"))
+		})
+	}
+}
+
+func TestMoveFileByID_Depth(t *testing.T) {
+	t.Parallel()
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+
+	archived := filepath.Join(srcDir, "2025", "20250903T083109--report.pdf")
+	require.NoError(t, os.MkdirAll(filepath.Dir(archived), 0755))
+	require.NoError(t, os.WriteFile(archived, []byte("content"), 0644))
+
+	// デフォルトではサブディレクトリを検索しない
+	_, err := MoveFileByID(srcDir, "20250903", dstDir, MoveOptions{Writer: &bytes.Buffer{}})
+	assert.ErrorContains(t, err, "no file found")
+	assert.FileExists(t, archived)
+
+	newPath, err := MoveFileByID(srcDir, "20250903", dstDir, MoveOptions{Writer: &bytes.Buffer{}, Depth: 1})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dstDir, "20250903T083109--report.pdf"), newPath)
+	assert.NoFileExists(t, archived)
+}
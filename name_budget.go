@@ -0,0 +1,243 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// DefaultMaxNameBytes はファイル名の長さの上限のデフォルト（多くのファイルシステムの NAME_MAX）
+const DefaultMaxNameBytes = 255
+
+// ErrNameTooLong はファイル名を上限に収められない場合のエラー
+var ErrNameTooLong = errors.New("file name too long")
+
+// abbreviationMark はコメントの中間を省略したことを表す文字列
+const abbreviationMark = "~"
+
+// NameBudgetPolicy はファイル名が長さの上限を超えた場合の短縮方法を表す
+type NameBudgetPolicy string
+
+// ファイル名の短縮方法
+const (
+	NameBudgetTruncate   NameBudgetPolicy = "truncate"   // コメントの末尾を切り詰める（デフォルト）
+	NameBudgetDropTags   NameBudgetPolicy = "drop-tags"  // 末尾のタグから削除し、それでも超える場合はコメントを切り詰める
+	NameBudgetAbbreviate NameBudgetPolicy = "abbreviate" // コメントの先頭と末尾を残して中間を省略する
+	NameBudgetPrompt     NameBudgetPolicy = "prompt"     // 短縮方法をファイルごとにインタラクティブに選ぶ
+	NameBudgetError      NameBudgetPolicy = "error"      // 短縮せずにエラーとしてスキップする
+)
+
+// ParseNameBudgetPolicy は文字列からファイル名の短縮方法を取得する
+// 空文字列の場合はデフォルトの truncate を返す
+func ParseNameBudgetPolicy(s string) (NameBudgetPolicy, error) {
+	switch policy := NameBudgetPolicy(s); policy {
+	case "":
+		return NameBudgetTruncate, nil
+	case NameBudgetTruncate, NameBudgetDropTags, NameBudgetAbbreviate, NameBudgetPrompt, NameBudgetError:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown name budget policy: %s (expected truncate, drop-tags, abbreviate, prompt or error)", s)
+	}
+}
+
+// NameTrim はファイル名を上限に収めた結果を表す
+type NameTrim struct {
+	Policy     NameBudgetPolicy            // 使った短縮方法（短縮しなかった場合は空）
+	Components parakeet.FileNameComponents // 短縮後の構成要素
+	Trimmed    []string                    // 短縮した内容の説明（短縮しなかった場合は空）
+}
+
+// NameBudget はファイル名の長さの上限と、超えた場合の短縮方法を表す
+type NameBudget struct {
+	MaxBytes int              // ファイル名の長さの上限（バイト数、0 以下の場合は DefaultMaxNameBytes）
+	Policy   NameBudgetPolicy // 上限を超えた場合の短縮方法（空の場合は truncate）
+	// Choose は prompt のときに候補から使う短縮方法を選ぶ（nil の場合は最初の候補を使う）
+	Choose func(fileName string, candidates []NameTrim) (int, error)
+}
+
+// Fit はファイル名が上限に収まるように構成要素を短縮する
+// 上限に収まっている場合はそのまま返し、収められない場合は ErrNameTooLong を返す
+func (b NameBudget) Fit(components parakeet.FileNameComponents) (*NameTrim, error) {
+	limit := b.MaxBytes
+	if limit <= 0 {
+		limit = DefaultMaxNameBytes
+	}
+
+	fileName := components.FormatFileName()
+	if len(fileName) <= limit {
+		return &NameTrim{Components: components}, nil
+	}
+
+	switch b.Policy {
+	case "", NameBudgetTruncate:
+		return truncateComment(components, limit)
+	case NameBudgetDropTags:
+		return dropTags(components, limit)
+	case NameBudgetAbbreviate:
+		return abbreviateComment(components, limit)
+	case NameBudgetPrompt:
+		candidates := []NameTrim{}
+		for _, fit := range []func(parakeet.FileNameComponents, int) (*NameTrim, error){truncateComment, dropTags, abbreviateComment} {
+			if trim, err := fit(components, limit); err == nil {
+				candidates = append(candidates, *trim)
+			}
+		}
+		if len(candidates) == 0 {
+			return nil, fmt.Errorf("%w: exceeds %d bytes and cannot be shortened: %s", ErrNameTooLong, limit, fileName)
+		}
+		if b.Choose == nil {
+			return &candidates[0], nil
+		}
+		i, err := b.Choose(fileName, candidates)
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || i >= len(candidates) {
+			return nil, fmt.Errorf("invalid choice: %d", i)
+		}
+		return &candidates[i], nil
+	default:
+		return nil, fmt.Errorf("%w: exceeds %d bytes (%d bytes): %s", ErrNameTooLong, limit, len(fileName), fileName)
+	}
+}
+
+// commentBudget はコメント以外の部分を除いてコメントに使えるバイト数を返す
+func commentBudget(components parakeet.FileNameComponents, limit int) int {
+	return limit - (len(components.FormatFileName()) - len(components.Comment))
+}
+
+// truncateComment はコメントの末尾を切り詰めてファイル名を上限に収める
+func truncateComment(components parakeet.FileNameComponents, limit int) (*NameTrim, error) {
+	budget := commentBudget(components, limit)
+	if budget < 1 {
+		return nil, fmt.Errorf("%w: exceeds %d bytes even without a comment: %s", ErrNameTooLong, limit, components.FormatFileName())
+	}
+
+	original := components.Comment
+	components.Comment = trimCommentEdge(truncateBytes(original, budget))
+	if components.Comment == "" {
+		return nil, fmt.Errorf("%w: exceeds %d bytes and the comment cannot be truncated: %s", ErrNameTooLong, limit, components.FormatFileName())
+	}
+
+	return &NameTrim{
+		Policy:     NameBudgetTruncate,
+		Components: components,
+		Trimmed:    []string{fmt.Sprintf("comment truncated: %s → %s", original, components.Comment)},
+	}, nil
+}
+
+// dropTags は末尾のタグから削除してファイル名を上限に収める
+// すべてのタグを削除しても超える場合はコメントも切り詰める
+func dropTags(components parakeet.FileNameComponents, limit int) (*NameTrim, error) {
+	tags := append([]string{}, components.Tags...)
+	dropped := []string{}
+	for len(tags) > 0 && len(components.FormatFileName()) > limit {
+		dropped = append([]string{tags[len(tags)-1]}, dropped...)
+		tags = tags[:len(tags)-1]
+		components.Tags = tags
+	}
+
+	trimmed := []string{}
+	if len(dropped) > 0 {
+		trimmed = append(trimmed, fmt.Sprintf("tags dropped: %s", strings.Join(dropped, ", ")))
+	}
+
+	if len(components.FormatFileName()) > limit {
+		trim, err := truncateComment(components, limit)
+		if err != nil {
+			return nil, err
+		}
+		components = trim.Components
+		trimmed = append(trimmed, trim.Trimmed...)
+	}
+
+	return &NameTrim{
+		Policy:     NameBudgetDropTags,
+		Components: components,
+		Trimmed:    trimmed,
+	}, nil
+}
+
+// abbreviateComment はコメントの先頭と末尾を残し、中間を省略してファイル名を上限に収める
+// 省略記号を入れる余裕がない場合はコメントの末尾を切り詰める
+func abbreviateComment(components parakeet.FileNameComponents, limit int) (*NameTrim, error) {
+	original := components.Comment
+	budget := commentBudget(components, limit) - len(abbreviationMark)
+	head := truncateBytes(original, max(budget+1, 0)/2)
+	tail := tailBytes(original, budget-len(head))
+	if head == "" || tail == "" {
+		trim, err := truncateComment(components, limit)
+		if err != nil {
+			return nil, err
+		}
+		trim.Policy = NameBudgetAbbreviate
+		return trim, nil
+	}
+
+	components.Comment = strings.TrimRight(head, " -_.") + abbreviationMark + strings.TrimLeft(tail, " -_.")
+
+	return &NameTrim{
+		Policy:     NameBudgetAbbreviate,
+		Components: components,
+		Trimmed:    []string{fmt.Sprintf("comment abbreviated: %s → %s", original, components.Comment)},
+	}, nil
+}
+
+// trimCommentEdge は切り詰めたコメントの末尾の区切り文字を取り除く
+// 取り除くと空になる場合はそのまま返す
+func trimCommentEdge(s string) string {
+	if trimmed := strings.TrimRight(s, " -_."); trimmed != "" {
+		return trimmed
+	}
+	return s
+}
+
+// truncateBytes は文字の途中で切らないように文字列を先頭から n バイト以内に切り詰める
+func truncateBytes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// tailBytes は文字の途中で切らないように文字列の末尾 n バイト以内を返す
+func tailBytes(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if len(s) <= n {
+		return s
+	}
+	start := len(s) - n
+	for start < len(s) && !utf8.RuneStart(s[start]) {
+		start++
+	}
+	return s[start:]
+}
+
+// ChooseNameTrim は短縮方法の候補からインタラクティブに1つを選ぶ
+func ChooseNameTrim(fileName string, candidates []NameTrim) (int, error) {
+	options := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		options = append(options, fmt.Sprintf("%s: %s", c.Policy, c.Components.FormatFileName()))
+	}
+
+	prompt := &survey.Select{
+		Message: fmt.Sprintf("%s is too long. Choose how to shorten it:", fileName),
+		Options: options,
+	}
+
+	var choice int
+	if err := survey.AskOne(prompt, &choice); err != nil {
+		return 0, err
+	}
+
+	return choice, nil
+}
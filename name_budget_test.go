@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNameBudgetPolicy(t *testing.T) {
+	t.Parallel()
+
+	policy, err := ParseNameBudgetPolicy("")
+	require.NoError(t, err)
+	assert.Equal(t, NameBudgetTruncate, policy)
+
+	policy, err = ParseNameBudgetPolicy("drop-tags")
+	require.NoError(t, err)
+	assert.Equal(t, NameBudgetDropTags, policy)
+
+	_, err = ParseNameBudgetPolicy("shrink")
+	assert.Error(t, err)
+}
+
+func TestNameBudget_Fit(t *testing.T) {
+	t.Parallel()
+
+	// "20250903T083109--" (17) + コメント + "__go_network" (12) + ".pdf" (4)
+	components := parakeet.FileNameComponents{
+		Timestamp: "20250903T083109",
+		Comment:   "very-long-title-of-a-document",
+		Tags:      []string{"go", "network"},
+		Extension: "pdf",
+	}
+
+	tests := []struct {
+		name     string
+		budget   NameBudget
+		expected string
+		trimmed  []string
+	}{
+		{
+			name:     "fits",
+			budget:   NameBudget{},
+			expected: "20250903T083109--very-long-title-of-a-document__go_network.pdf",
+		},
+		{
+			name:     "truncate",
+			budget:   NameBudget{MaxBytes: 48, Policy: NameBudgetTruncate},
+			expected: "20250903T083109--very-long-title__go_network.pdf",
+			trimmed:  []string{"comment truncated: very-long-title-of-a-document → very-long-title"},
+		},
+		{
+			name:     "drop tags",
+			budget:   NameBudget{MaxBytes: 58, Policy: NameBudgetDropTags},
+			expected: "20250903T083109--very-long-title-of-a-document__go.pdf",
+			trimmed:  []string{"tags dropped: network"},
+		},
+		{
+			name:     "drop tags and truncate",
+			budget:   NameBudget{MaxBytes: 36, Policy: NameBudgetDropTags},
+			expected: "20250903T083109--very-long-title.pdf",
+			trimmed: []string{
+				"tags dropped: go, network",
+				"comment truncated: very-long-title-of-a-document → very-long-title",
+			},
+		},
+		{
+			name:     "abbreviate",
+			budget:   NameBudget{MaxBytes: 48, Policy: NameBudgetAbbreviate},
+			expected: "20250903T083109--very-lo~ocument__go_network.pdf",
+			trimmed:  []string{"comment abbreviated: very-long-title-of-a-document → very-lo~ocument"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			trim, err := tt.budget.Fit(components)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, trim.Components.FormatFileName())
+			assert.Equal(t, tt.trimmed, trim.Trimmed)
+		})
+	}
+}
+
+func TestNameBudget_FitMultibyte(t *testing.T) {
+	t.Parallel()
+
+	// 文字の途中で切らない
+	components := parakeet.FileNameComponents{Timestamp: "20250903T083109", Comment: strings.Repeat("入門", 10), Extension: "md"}
+	trim, err := NameBudget{MaxBytes: 30}.Fit(components)
+	require.NoError(t, err)
+	assert.Equal(t, "20250903T083109--入門入.md", trim.Components.FormatFileName())
+
+	trim, err = NameBudget{MaxBytes: 34, Policy: NameBudgetAbbreviate}.Fit(components)
+	require.NoError(t, err)
+	assert.Equal(t, "20250903T083109--入門~入門.md", trim.Components.FormatFileName())
+}
+
+func TestNameBudget_FitErrors(t *testing.T) {
+	t.Parallel()
+
+	components := parakeet.FileNameComponents{Timestamp: "20250903T083109", Comment: "title", Tags: []string{"network"}, Extension: "pdf"}
+
+	_, err := NameBudget{MaxBytes: 20, Policy: NameBudgetError}.Fit(components)
+	assert.ErrorIs(t, err, ErrNameTooLong)
+
+	// コメントを空にしても収まらない
+	_, err = NameBudget{MaxBytes: 20}.Fit(components)
+	assert.ErrorIs(t, err, ErrNameTooLong)
+}
+
+func TestNameBudget_FitPrompt(t *testing.T) {
+	t.Parallel()
+
+	components := parakeet.FileNameComponents{Timestamp: "20250903T083109", Comment: "very-long-title", Tags: []string{"network"}, Extension: "pdf"}
+
+	var offered []NameBudgetPolicy
+	budget := NameBudget{
+		MaxBytes: 36,
+		Policy:   NameBudgetPrompt,
+		Choose: func(fileName string, candidates []NameTrim) (int, error) {
+			for _, c := range candidates {
+				offered = append(offered, c.Policy)
+			}
+			return 1, nil
+		},
+	}
+	trim, err := budget.Fit(components)
+	require.NoError(t, err)
+	assert.Equal(t, []NameBudgetPolicy{NameBudgetTruncate, NameBudgetDropTags, NameBudgetAbbreviate}, offered)
+	assert.Equal(t, "20250903T083109--very-long-title.pdf", trim.Components.FormatFileName())
+
+	// 選択を中断した場合はそのエラーを返す
+	budget.Choose = func(string, []NameTrim) (int, error) { return 0, errors.New("interrupted") }
+	_, err = budget.Fit(components)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNameTooLong)
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+	"github.com/pelletier/go-toml/v2"
+)
+
+const (
+	// TemplatesDirName はテンプレートを置くディレクトリ名
+	TemplatesDirName = "templates"
+	// DefaultNewExtension は new コマンドのデフォルト拡張子
+	DefaultNewExtension = "md"
+)
+
+// NewOptions は新規ファイル作成操作のオプションを表す
+type NewOptions struct {
+	Writer    io.Writer // 出力先
+	Dir       string    // 作成先ディレクトリ
+	Template  string    // テンプレート名（空の場合はテンプレートなし）
+	Tags      []string  // 付与するタグ（テンプレートのタグに追加される）
+	Extension string    // 拡張子（空の場合はテンプレートまたはデフォルト）
+	TagsFile  string    // タグ定義ファイル（空の場合は tags.toml、ResolveTagsFile で解決する）
+}
+
+// FileTemplate はテンプレートファイルで定義される新規ファイルの雛形
+type FileTemplate struct {
+	Extension string   `toml:"extension"` // 拡張子
+	Tags      []string `toml:"tags"`      // デフォルトタグ
+	Body      string   `toml:"body"`      // 本文（text/template 形式）
+}
+
+// TemplateData はテンプレートの本文に埋め込む値
+type TemplateData struct {
+	ID        string   // タイムスタンプ（ID）
+	Timestamp string   // タイムスタンプ（ID と同じ）
+	Title     string   // タイトル
+	Date      string   // 作成日（YYYY-MM-DD）
+	Tags      []string // タグ
+}
+
+// LoadTemplate はテンプレートを名前で読み込む
+// .parakeet/templates/ の <name>.toml または <name>.<ext>（ファイルの内容を本文、拡張子を作成するファイルの拡張子とする）、
+// templates/<name>.toml の順に探す
+func LoadTemplate(dirPath, name string) (*FileTemplate, error) {
+	if strings.ContainsAny(name, "/\\") || name == "" || strings.HasPrefix(name, ".") {
+		return nil, fmt.Errorf("invalid template name: %s", name)
+	}
+
+	stateTemplatesDir := filepath.Join(dirPath, StateDirName, TemplatesDirName)
+	if tmpl, err := loadTOMLTemplate(filepath.Join(stateTemplatesDir, name+".toml")); err == nil || !os.IsNotExist(err) {
+		return tmpl, err
+	}
+
+	matches, err := filepath.Glob(filepath.Join(stateTemplatesDir, name+".*"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find template: %w", err)
+	}
+	if len(matches) > 1 {
+		return nil, fmt.Errorf("multiple templates found for %s:\n%s", name, strings.Join(matches, "\n"))
+	}
+	if len(matches) == 1 {
+		body, err := os.ReadFile(matches[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template: %w", err)
+		}
+		return &FileTemplate{
+			Extension: strings.TrimPrefix(filepath.Ext(matches[0]), "."),
+			Body:      string(body),
+		}, nil
+	}
+
+	templatePath := filepath.Join(dirPath, TemplatesDirName, name+".toml")
+	tmpl, err := loadTOMLTemplate(templatePath)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("template not found: %s (looked in %s and %s)", name, stateTemplatesDir, filepath.Join(dirPath, TemplatesDirName))
+	}
+	return tmpl, err
+}
+
+// loadTOMLTemplate は TOML 形式のテンプレートを読み込む（ファイルが存在しない場合は os.IsNotExist のエラーを返す）
+func loadTOMLTemplate(templatePath string) (*FileTemplate, error) {
+	data, err := os.ReadFile(templatePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+
+	var tmpl FileTemplate
+	if err := toml.Unmarshal(data, &tmpl); err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	return &tmpl, nil
+}
+
+// CreateNewFile はタイトルから新しいフォーマット済みファイルを作成し、そのパスを返す
+func CreateNewFile(title string, opts NewOptions) (string, error) {
+	reporter := ReporterFor(opts.Writer)
+
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "", fmt.Errorf("title cannot be empty")
+	}
+	if strings.Contains(title, "--") || strings.Contains(title, "__") || strings.ContainsAny(title, "/\\") {
+		return "", fmt.Errorf("title cannot contain separators (--, __, /): %s", title)
+	}
+
+	dir := opts.Dir
+	if dir == "" {
+		dir = "."
+	}
+
+	tmpl := &FileTemplate{}
+	if opts.Template != "" {
+		loaded, err := LoadTemplate(dir, opts.Template)
+		if err != nil {
+			return "", err
+		}
+		tmpl = loaded
+	}
+
+	// 拡張子の決定（オプション > テンプレート > デフォルト）
+	ext := opts.Extension
+	if ext == "" {
+		ext = tmpl.Extension
+	}
+	if ext == "" {
+		ext = DefaultNewExtension
+	}
+	ext = strings.TrimPrefix(ext, ".")
+
+	// タグを統合（重複を除きソート）
+	tags := mergeTags(tmpl.Tags, opts.Tags)
+
+	validator, err := NewTagValidator(ResolveTagsFile(dir, opts.TagsFile))
+	if err != nil {
+		return "", err
+	}
+	if err := validator.Validate(tags); err != nil {
+		return "", err
+	}
+
+	// 重複しないタイムスタンプを生成
+	existingTimestamps, err := CollectExistingTimestamps(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to collect existing timestamps: %w", err)
+	}
+	now := time.Now()
+	timestamp := parakeet.GenerateUniqueTimestampFrom(now, existingTimestamps)
+
+	components := parakeet.FileNameComponents{
+		Timestamp: timestamp,
+		Comment:   title,
+		Tags:      tags,
+		Extension: ext,
+	}
+
+	// 本文をレンダリング
+	body, err := renderTemplateBody(tmpl.Body, TemplateData{
+		ID:        timestamp,
+		Timestamp: timestamp,
+		Title:     title,
+		Date:      now.Format("2006-01-02"),
+		Tags:      tags,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	fileName, err := components.FormatFileNameStrict()
+	if err != nil {
+		return "", err
+	}
+
+	filePath := filepath.Join(dir, fileName)
+	f, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to create file: %w", err)
+	}
+	if _, err := f.WriteString(body); err != nil {
+		_ = f.Close()
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to close file: %w", err)
+	}
+
+	reporter.Successf("Created: %s\n", fileName)
+
+	return filePath, nil
+}
+
+// renderTemplateBody はテンプレートの本文にデータを埋め込む（list --template と同じ join, date などの関数を使える）
+func renderTemplateBody(body string, data TemplateData) (string, error) {
+	if body == "" {
+		return "", nil
+	}
+
+	t, err := template.New("body").Funcs(outputTemplateFuncs).Parse(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template body: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template body: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// mergeTags は複数のタグリストを重複を除いて統合し、ソートして返す
+func mergeTags(tagLists ...[]string) []string {
+	seen := make(map[string]bool)
+	merged := make([]string, 0)
+	for _, tags := range tagLists {
+		for _, tag := range tags {
+			if !seen[tag] {
+				seen[tag] = true
+				merged = append(merged, tag)
+			}
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
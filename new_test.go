@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateNewFile(t *testing.T) {
+	t.Parallel()
+	// Create temporary directory
+	tmpDir, err := os.MkdirTemp("", "parakeet-new-*")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	buf := &bytes.Buffer{}
+	filePath, err := CreateNewFile("Weekly sync", NewOptions{
+		Writer: buf,
+		Dir:    tmpDir,
+		Tags:   []string{"work"},
+	})
+	require.NoError(t, err)
+
+	components, err := parakeet.ParseFileName(filepath.Base(filePath))
+	require.NoError(t, err)
+	assert.Equal(t, "Weekly sync", components.Comment)
+	assert.Equal(t, []string{"work"}, components.Tags)
+	assert.Equal(t, DefaultNewExtension, components.Extension)
+	assert.Contains(t, buf.String(), "✓ Created:")
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Empty(t, content)
+}
+
+func TestCreateNewFile_WithTemplate(t *testing.T) {
+	t.Parallel()
+	// Create temporary directory with a template
+	tmpDir, err := os.MkdirTemp("", "parakeet-new-template-*")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, TemplatesDirName), 0755))
+	tmplContent := `extension = "org"
+tags = ["meeting"]
+body = """
+* {{.Title}}
+ID: {{.ID}}
+Date: {{.Date}}
+"""
+`
+	err = os.WriteFile(filepath.Join(tmpDir, TemplatesDirName, "meeting.toml"), []byte(tmplContent), 0644)
+	require.NoError(t, err)
+
+	filePath, err := CreateNewFile("Weekly sync", NewOptions{
+		Writer:   &bytes.Buffer{},
+		Dir:      tmpDir,
+		Template: "meeting",
+		Tags:     []string{"work", "meeting"},
+	})
+	require.NoError(t, err)
+
+	components, err := parakeet.ParseFileName(filepath.Base(filePath))
+	require.NoError(t, err)
+	assert.Equal(t, "org", components.Extension)
+	assert.Equal(t, []string{"meeting", "work"}, components.Tags)
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(content), "* Weekly sync\n"))
+	assert.Contains(t, string(content), "ID: "+components.Timestamp)
+	assert.Contains(t, string(content), "Date: "+time.Now().Format("2006-01-02"))
+}
+
+func TestCreateNewFile_StateTemplate(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-new-template-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	// .parakeet/templates/ の本文だけのファイル（拡張子が作成するファイルの拡張子になる）
+	templatesDir := filepath.Join(tmpDir, StateDirName, TemplatesDirName)
+	require.NoError(t, os.MkdirAll(templatesDir, 0755))
+	body := "# {{.Title}}\n\nID: {{.Timestamp}}\nDate: {{.Date}}\nTags: {{join .Tags \", \"}}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(templatesDir, "weekly-report.md"), []byte(body), 0644))
+
+	filePath, err := CreateNewFile("Week 36", NewOptions{
+		Writer:   &bytes.Buffer{},
+		Dir:      tmpDir,
+		Template: "weekly-report",
+		Tags:     []string{"work", "report"},
+	})
+	require.NoError(t, err)
+
+	components, err := parakeet.ParseFileName(filepath.Base(filePath))
+	require.NoError(t, err)
+	assert.Equal(t, "md", components.Extension)
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "# Week 36\n\nID: "+components.Timestamp+"\nDate: "+time.Now().Format("2006-01-02")+"\nTags: report, work\n", string(content))
+}
+
+func TestLoadTemplate(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-new-template-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	templatesDir := filepath.Join(tmpDir, StateDirName, TemplatesDirName)
+	require.NoError(t, os.MkdirAll(templatesDir, 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, TemplatesDirName), 0755))
+
+	// .parakeet/templates/ が templates/ より優先する
+	require.NoError(t, os.WriteFile(filepath.Join(templatesDir, "meeting.toml"), []byte("extension = \"org\"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, TemplatesDirName, "meeting.toml"), []byte("extension = \"txt\"\n"), 0644))
+	tmpl, err := LoadTemplate(tmpDir, "meeting")
+	require.NoError(t, err)
+	assert.Equal(t, "org", tmpl.Extension)
+
+	// 同じ名前で拡張子の異なるファイルは曖昧
+	require.NoError(t, os.WriteFile(filepath.Join(templatesDir, "note.md"), []byte("md"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(templatesDir, "note.org"), []byte("org"), 0644))
+	_, err = LoadTemplate(tmpDir, "note")
+	assert.ErrorContains(t, err, "multiple templates found for note")
+
+	_, err = LoadTemplate(tmpDir, "../meeting")
+	assert.ErrorContains(t, err, "invalid template name")
+}
+
+func TestCreateNewFile_Errors(t *testing.T) {
+	t.Parallel()
+	// Create temporary directory
+	tmpDir, err := os.MkdirTemp("", "parakeet-new-errors-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	tests := []struct {
+		name      string
+		title     string
+		opts      NewOptions
+		errorText string
+	}{
+		{
+			name:      "empty title",
+			title:     "  ",
+			opts:      NewOptions{Writer: &bytes.Buffer{}, Dir: tmpDir},
+			errorText: "title cannot be empty",
+		},
+		{
+			name:      "title with separator",
+			title:     "a__b",
+			opts:      NewOptions{Writer: &bytes.Buffer{}, Dir: tmpDir},
+			errorText: "title cannot contain separators",
+		},
+		{
+			name:      "missing template",
+			title:     "title",
+			opts:      NewOptions{Writer: &bytes.Buffer{}, Dir: tmpDir, Template: "missing"},
+			errorText: "template not found",
+		},
+		{
+			name:      "invalid tag",
+			title:     "title",
+			opts:      NewOptions{Writer: &bytes.Buffer{}, Dir: tmpDir, Tags: []string{"a_b"}},
+			errorText: "tag cannot contain special characters",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := CreateNewFile(tt.title, tt.opts)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorText)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"strings"
+)
+
+// stopWords はタイトル比較時に無視する英語の一般的な単語
+var stopWords = map[string]bool{
+	"a": true, "an": true, "the": true,
+	"and": true, "or": true, "of": true,
+	"to": true, "in": true, "on": true,
+	"for": true, "with": true, "by": true,
+}
+
+// NormalizeTitle はタイトルを比較用に正規化する
+// 大文字小文字、記号、一般的なストップワードの違いを吸収し、
+// "The TCP/IP Guide" と "tcp ip guide" が同じ文字列になるようにする
+func NormalizeTitle(title string) string {
+	words := splitTerms(title)
+
+	normalized := make([]string, 0, len(words))
+	for _, word := range words {
+		if stopWords[word] {
+			continue
+		}
+		normalized = append(normalized, word)
+	}
+
+	// ストップワードのみのタイトルは単語をそのまま使う
+	if len(normalized) == 0 {
+		normalized = words
+	}
+
+	return strings.Join(normalized, " ")
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTitle(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		title    string
+		expected string
+	}{
+		{
+			name:     "case and punctuation",
+			title:    "The TCP/IP Guide",
+			expected: "tcp ip guide",
+		},
+		{
+			name:     "already normalized",
+			title:    "tcp ip guide",
+			expected: "tcp ip guide",
+		},
+		{
+			name:     "separators and extra spaces",
+			title:    "Go  -  Programming, 2nd Edition!",
+			expected: "go programming 2nd edition",
+		},
+		{
+			name:     "japanese title",
+			title:    "TCPIP入門",
+			expected: "tcpip入門",
+		},
+		{
+			name:     "only stop words",
+			title:    "The And",
+			expected: "the and",
+		},
+		{
+			name:     "empty",
+			title:    "",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, NormalizeTitle(tt.title))
+		})
+	}
+}
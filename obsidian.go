@@ -0,0 +1,291 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// ObsidianConfigDirName は Obsidian vault のルートにある設定ディレクトリ名
+	ObsidianConfigDirName = ".obsidian"
+)
+
+// wikiLinkPattern は Obsidian の wiki リンク（[[note]], [[note|alias]], [[note#heading]], ![[image.png]]）
+// 1番目のグループはリンク先、2番目のグループは見出し・ブロック参照・別名
+var wikiLinkPattern = regexp.MustCompile(`\[\[([^\[\]|#^]*)([^\[\]]*)\]\]`)
+
+// ObsidianVault は Obsidian vault 内の wiki リンクをリネームに合わせて書き換える
+type ObsidianVault struct {
+	Root   string    // vault のルートディレクトリ（.obsidian/ があるディレクトリ）
+	Writer io.Writer // 出力先
+}
+
+// BrokenWikiLink はノートに解決できなかった wiki リンクを表す
+type BrokenWikiLink struct {
+	File   string // リンクを含むノートの vault からの相対パス
+	Line   int    // 行番号（1始まり）
+	Target string // リンク先
+}
+
+// FindObsidianVault は dirPath とその親ディレクトリから .obsidian/ を探し、vault のルートを返す
+// vault 内でない場合は false を返す
+func FindObsidianVault(dirPath string) (string, bool) {
+	dir, err := filepath.Abs(dirPath)
+	if err != nil {
+		return "", false
+	}
+
+	for {
+		if info, err := os.Stat(filepath.Join(dir, ObsidianConfigDirName)); err == nil && info.IsDir() {
+			return dir, true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// obsidianVaultFor は dirPath が vault 内の場合にリンクを書き換える ObsidianVault を返す
+// vault 内でない場合は nil を返す
+func obsidianVaultFor(dirPath string, w io.Writer) *ObsidianVault {
+	root, ok := FindObsidianVault(dirPath)
+	if !ok {
+		return nil
+	}
+	return &ObsidianVault{Root: root, Writer: w}
+}
+
+// RewriteLinks は dirPath で実行したリネームに合わせて、vault 内のノートの wiki リンクを書き換える
+// v が nil の場合は何もしない
+func (v *ObsidianVault) RewriteLinks(dirPath string, plans ...renamePlan) error {
+	if v == nil || len(plans) == 0 {
+		return nil
+	}
+	reporter := ReporterFor(v.Writer)
+
+	absDir, err := filepath.Abs(dirPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve directory: %w", err)
+	}
+
+	renames := make([]wikiLinkRename, 0, len(plans))
+	for _, plan := range plans {
+		from, err := v.relPath(filepath.Join(absDir, plan.From))
+		if err != nil {
+			return err
+		}
+		to, err := v.relPath(filepath.Join(absDir, plan.To))
+		if err != nil {
+			return err
+		}
+		renames = append(renames, wikiLinkRename{From: from, To: to})
+	}
+
+	notes, err := vaultFiles(v.Root)
+	if err != nil {
+		return err
+	}
+
+	for _, note := range notes {
+		if !isMarkdownNote(note) {
+			continue
+		}
+
+		notePath := filepath.Join(v.Root, filepath.FromSlash(note))
+		data, err := os.ReadFile(notePath)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+
+		updated, changed := rewriteWikiLinks(string(data), renames)
+		if !changed {
+			continue
+		}
+
+		if err := rewriteFile(notePath, []byte(updated)); err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+
+		reporter.Successf("Updated links: %s\n", note)
+	}
+
+	return nil
+}
+
+// relPath は vault のルートからのスラッシュ区切りの相対パスを返す
+func (v *ObsidianVault) relPath(filePath string) (string, error) {
+	rel, err := filepath.Rel(v.Root, filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path in vault: %w", err)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// wikiLinkRename は vault のルートからの相対パスで表したリネームを表す
+type wikiLinkRename struct {
+	From string
+	To   string
+}
+
+// rewriteWikiLinks は content 内のリネームされたノートへの wiki リンクを新しい名前に書き換える
+// 見出し・ブロック参照・別名と、リンクにフォルダや拡張子 .md を書いているかどうかはそのまま保つ
+func rewriteWikiLinks(content string, renames []wikiLinkRename) (string, bool) {
+	changed := false
+
+	updated := wikiLinkPattern.ReplaceAllStringFunc(content, func(link string) string {
+		match := wikiLinkPattern.FindStringSubmatch(link)
+		target, rest := match[1], match[2]
+
+		for _, rename := range renames {
+			if newTarget, ok := renameWikiLinkTarget(target, rename); ok {
+				changed = true
+				return "[[" + newTarget + rest + "]]"
+			}
+		}
+		return link
+	})
+
+	return updated, changed
+}
+
+// renameWikiLinkTarget はリンク先がリネーム前のファイルを指す場合に、リネーム後のリンク先を返す
+// フォルダを含むリンクは vault のルートからのパスで、含まないリンクはファイル名で比較する
+func renameWikiLinkTarget(target string, rename wikiLinkRename) (string, bool) {
+	trimmed := strings.TrimSpace(target)
+	if trimmed == "" {
+		return "", false
+	}
+
+	from, to := rename.From, rename.To
+	if !strings.Contains(trimmed, "/") {
+		from, to = path.Base(from), path.Base(to)
+	}
+
+	if wikiLinkName(trimmed) != wikiLinkName(from) {
+		return "", false
+	}
+
+	// Markdown のノートは、リンクに拡張子を書いていない場合は拡張子なしで書き換える
+	if isMarkdownNote(to) && !isMarkdownNote(trimmed) {
+		to = strings.TrimSuffix(to, path.Ext(to))
+	}
+	return to, true
+}
+
+// wikiLinkName はリンク先を比較するための名前を返す（Markdown のノートは拡張子を除く）
+func wikiLinkName(name string) string {
+	if isMarkdownNote(name) {
+		return strings.TrimSuffix(name, path.Ext(name))
+	}
+	return name
+}
+
+// isMarkdownNote は Obsidian のノート（.md ファイル）かどうかを返す
+func isMarkdownNote(name string) bool {
+	return strings.EqualFold(path.Ext(name), ".md")
+}
+
+// FindBrokenWikiLinks は vault 内のノートの wiki リンクのうち、ファイルに解決できないものを返す
+// ページ内の見出しへのリンク（[[#heading]]）は検証しない
+func FindBrokenWikiLinks(root string) ([]BrokenWikiLink, error) {
+	files, err := vaultFiles(root)
+	if err != nil {
+		return nil, err
+	}
+
+	// リンク先として解決できる名前（ファイル名と vault からの相対パス）
+	names := make(map[string]bool)
+	for _, file := range files {
+		names[wikiLinkName(file)] = true
+		names[wikiLinkName(path.Base(file))] = true
+	}
+
+	broken := []BrokenWikiLink{}
+	for _, file := range files {
+		if !isMarkdownNote(file) {
+			continue
+		}
+
+		links, err := findBrokenLinksInNote(filepath.Join(root, filepath.FromSlash(file)), names)
+		if err != nil {
+			return nil, err
+		}
+		for _, link := range links {
+			link.File = file
+			broken = append(broken, link)
+		}
+	}
+
+	return broken, nil
+}
+
+// findBrokenLinksInNote はノート内の wiki リンクのうち names に含まれないものを返す
+func findBrokenLinksInNote(notePath string, names map[string]bool) ([]BrokenWikiLink, error) {
+	file, err := os.Open(notePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	broken := []BrokenWikiLink{}
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		for _, match := range wikiLinkPattern.FindAllStringSubmatch(scanner.Text(), -1) {
+			target := strings.TrimSpace(match[1])
+			if target == "" {
+				continue
+			}
+			if !names[wikiLinkName(target)] {
+				broken = append(broken, BrokenWikiLink{Line: lineNumber, Target: target})
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return broken, nil
+}
+
+// vaultFiles は vault 内のすべてのファイルの vault からの相対パスをソートして返す
+// .obsidian/ などドットで始まるディレクトリはスキップする
+func vaultFiles(root string) ([]string, error) {
+	files := []string{}
+	err := filepath.WalkDir(root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if filePath != root && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault: %w", err)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
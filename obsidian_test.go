@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestVault は .obsidian/ を持つ vault を作成し、そのルートを返す
+func newTestVault(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ObsidianConfigDirName), 0755))
+	return root
+}
+
+func TestFindObsidianVault(t *testing.T) {
+	t.Parallel()
+	root := newTestVault(t)
+	sub := filepath.Join(root, "notes")
+	require.NoError(t, os.MkdirAll(sub, 0755))
+
+	found, ok := FindObsidianVault(sub)
+	require.True(t, ok)
+	expected, err := filepath.Abs(root)
+	require.NoError(t, err)
+	assert.Equal(t, expected, found)
+
+	plain, err := os.MkdirTemp("", "parakeet-novault-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(plain) })
+	_, ok = FindObsidianVault(plain)
+	assert.False(t, ok)
+	assert.Nil(t, obsidianVaultFor(plain, &bytes.Buffer{}))
+}
+
+func TestRewriteWikiLinks(t *testing.T) {
+	t.Parallel()
+	renames := []wikiLinkRename{
+		{From: "notes/20250101T000000--old.md", To: "notes/20250101T000000--new__go.md"},
+		{From: "img.png", To: "20250102T000000--img.png"},
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		changed  bool
+	}{
+		{"名前", "see [[20250101T000000--old]]", "see [[20250101T000000--new__go]]", true},
+		{"拡張子付き", "[[20250101T000000--old.md]]", "[[20250101T000000--new__go.md]]", true},
+		{"見出しと別名", "[[20250101T000000--old#intro|Old]]", "[[20250101T000000--new__go#intro|Old]]", true},
+		{"フォルダ付き", "[[notes/20250101T000000--old]]", "[[notes/20250101T000000--new__go]]", true},
+		{"埋め込み", "![[img.png]]", "![[20250102T000000--img.png]]", true},
+		{"別のノート", "[[other]] and [[20250101T000000--older]]", "[[other]] and [[20250101T000000--older]]", false},
+		{"ページ内", "[[#heading]]", "[[#heading]]", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			updated, changed := rewriteWikiLinks(tt.content, renames)
+			assert.Equal(t, tt.expected, updated)
+			assert.Equal(t, tt.changed, changed)
+		})
+	}
+}
+
+func TestObsidianVault_RewriteLinksOnTag(t *testing.T) {
+	t.Parallel()
+	root := newTestVault(t)
+
+	fileName := "20250101T000000--draft.md"
+	filePath := filepath.Join(root, fileName)
+	require.NoError(t, os.WriteFile(filePath, []byte("draft\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "daily"), 0755))
+	notePath := filepath.Join(root, "daily", "today.md")
+	require.NoError(t, os.WriteFile(notePath, []byte("- [[20250101T000000--draft|draft]]\n"), 0644))
+	configPath := filepath.Join(root, ObsidianConfigDirName, "app.md")
+	require.NoError(t, os.WriteFile(configPath, []byte("[[20250101T000000--draft]]\n"), 0644))
+
+	buf := &bytes.Buffer{}
+	err := SetTags(filePath, []string{"go"}, TagOptions{Writer: buf, Vault: obsidianVaultFor(root, buf)})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(notePath)
+	require.NoError(t, err)
+	assert.Equal(t, "- [[20250101T000000--draft__go|draft]]\n", string(content))
+	assert.Contains(t, buf.String(), "Updated links: daily/today.md")
+
+	// .obsidian/ 内のファイルは書き換えない
+	config, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "[[20250101T000000--draft]]\n", string(config))
+}
+
+func TestFindBrokenWikiLinks(t *testing.T) {
+	t.Parallel()
+	root := newTestVault(t)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "notes"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "notes", "a.md"), []byte("a\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "img.png"), []byte("png"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "index.md"), []byte("[[a]] [[notes/a.md]] ![[img.png]] [[#top]]\n[[missing]] [[a#sec|x]]\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ObsidianConfigDirName, "x.md"), []byte("[[ignored]]\n"), 0644))
+
+	broken, err := FindBrokenWikiLinks(root)
+	require.NoError(t, err)
+	assert.Equal(t, []BrokenWikiLink{{File: "index.md", Line: 2, Target: "missing"}}, broken)
+
+	buf := &bytes.Buffer{}
+	result, err := ValidateFileNames(context.Background(), root, ValidateOptions{Writer: buf, Vault: root})
+	require.NoError(t, err)
+	assert.Len(t, result.BrokenLinks, 1)
+	assert.Contains(t, buf.String(), "index.md:2 (broken link: [[missing]])")
+	assert.Contains(t, buf.String(), "Broken links: 1")
+}
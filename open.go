@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// OpenOptions はファイルを開く操作のオプションを表す
+type OpenOptions struct {
+	Writer io.Writer         // 出力先
+	Apps   map[string]string // 拡張子ごとに開くアプリケーションのコマンド（parakeet.toml の [open]、例: pdf = "zathura"）
+	DryRun bool              // 実際には開かず、実行するコマンドのみ表示する
+
+	// Launch はコマンドを起動する（nil の場合は終了を待たずに起動する。テストで差し替える）
+	Launch func(name string, args ...string) error
+}
+
+// OpenFileByID はIDで指定したファイルをアプリケーションで開き、開いたファイルのパスを返す
+// 拡張子に対応するアプリケーションが設定されていない場合は OS の既定のアプリケーション（xdg-open, open, start）で開く
+func OpenFileByID(dirPath, id string, opts OpenOptions) (string, error) {
+	filePath, err := FindFileByID(dirPath, id)
+	if err != nil {
+		return "", fmt.Errorf("file not found: %w", err)
+	}
+
+	return OpenFile(filePath, opts)
+}
+
+// OpenFile はファイルをアプリケーションで開き、開いたファイルの絶対パスを返す
+func OpenFile(filePath string, opts OpenOptions) (string, error) {
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("open", "file", "command", "dry_run")
+
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve absolute path: %w", err)
+	}
+
+	name, args, err := openerFor(absPath, opts.Apps, runtime.GOOS)
+	if err != nil {
+		return "", err
+	}
+
+	fields := map[string]any{"file": absPath, "command": append([]string{name}, args...), "dry_run": opts.DryRun}
+	if opts.DryRun {
+		reporter.Emit("open", fields, "[DRY RUN] %s\n", strings.Join(append([]string{name}, args...), " "))
+		return absPath, nil
+	}
+
+	launch := opts.Launch
+	if launch == nil {
+		launch = startCommand
+	}
+	if err := launch(name, args...); err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+
+	reporter.Emit("open", fields, "Opened: %s\n", absPath)
+	return absPath, nil
+}
+
+// openerFor はファイルを開くコマンドと引数を返す
+// apps に拡張子のコマンドがあればそれを（空白区切りで引数を指定できる）、なければ goos の既定のコマンドを使う
+func openerFor(path string, apps map[string]string, goos string) (string, []string, error) {
+	if components, err := parakeet.ParseFileName(filepath.Base(path)); err == nil {
+		for ext, app := range apps {
+			if strings.EqualFold(strings.TrimPrefix(ext, "."), components.Extension) {
+				fields := strings.Fields(app)
+				if len(fields) == 0 {
+					return "", nil, fmt.Errorf("empty open command for extension: %s", ext)
+				}
+				return fields[0], append(fields[1:], path), nil
+			}
+		}
+	}
+
+	switch goos {
+	case "darwin":
+		return "open", []string{path}, nil
+	case "windows":
+		// start は cmd の組み込みコマンド。最初の引数はウィンドウのタイトル
+		return "cmd", []string{"/c", "start", "", path}, nil
+	default:
+		return "xdg-open", []string{path}, nil
+	}
+}
+
+// startCommand はコマンドを起動し、終了を待たずに戻る
+func startCommand(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenerFor(t *testing.T) {
+	t.Parallel()
+
+	path := "/docs/20250903T083109--report__network.pdf"
+	tests := []struct {
+		name string
+		apps map[string]string
+		goos string
+		want []string
+	}{
+		{"linux", nil, "linux", []string{"xdg-open", path}},
+		{"darwin", nil, "darwin", []string{"open", path}},
+		{"windows", nil, "windows", []string{"cmd", "/c", "start", "", path}},
+		{"override", map[string]string{"pdf": "zathura --fork"}, "linux", []string{"zathura", "--fork", path}},
+		{"override with dot and case", map[string]string{".PDF": "evince"}, "darwin", []string{"evince", path}},
+		{"other extension", map[string]string{"md": "vim"}, "linux", []string{"xdg-open", path}},
+	}
+	for _, tt := range tests {
+		name, args, err := openerFor(path, tt.apps, tt.goos)
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, tt.want, append([]string{name}, args...), tt.name)
+	}
+}
+
+func TestOpenFileByID(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-open-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	filePath := filepath.Join(tmpDir, "20250903T083109--report.pdf")
+	require.NoError(t, os.WriteFile(filePath, []byte("test"), 0644))
+
+	var launched []string
+	var buf bytes.Buffer
+	opened, err := OpenFileByID(tmpDir, "20250903T083109", OpenOptions{
+		Writer: &buf,
+		Apps:   map[string]string{"pdf": "zathura"},
+		Launch: func(name string, args ...string) error {
+			launched = append([]string{name}, args...)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, filePath, opened)
+	assert.Equal(t, []string{"zathura", filePath}, launched)
+	assert.Contains(t, buf.String(), "Opened: "+filePath)
+
+	// --dry-run の場合は起動しない
+	launched = nil
+	buf.Reset()
+	_, err = OpenFileByID(tmpDir, "20250903T083109", OpenOptions{
+		Writer: &buf,
+		Apps:   map[string]string{"pdf": "zathura"},
+		DryRun: true,
+		Launch: func(name string, args ...string) error {
+			launched = append([]string{name}, args...)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	assert.Nil(t, launched)
+	assert.Contains(t, buf.String(), "[DRY RUN] zathura "+filePath)
+
+	_, err = OpenFileByID(tmpDir, "20990101T000000", OpenOptions{Writer: &bytes.Buffer{}})
+	assert.ErrorContains(t, err, "file not found")
+}
@@ -0,0 +1,6 @@
+package main
+
+import "errors"
+
+// ErrOpenCheckUnsupported は実行中のプラットフォームで開かれているファイルを検出できない場合のエラー
+var ErrOpenCheckUnsupported = errors.New("detecting files open by other processes is not supported on this platform")
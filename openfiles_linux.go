@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// procRoot は procfs のマウント先
+const procRoot = "/proc"
+
+// FilesOpenForWriting はディレクトリ直下のファイルのうち、いずれかのプロセスが書き込み用に開いているものを返す
+// /proc/<pid>/fd と /proc/<pid>/fdinfo を調べる（lsof と同じ方法）ため、権限のないプロセスのファイルは検出できない
+// 戻り値のキーはファイル名
+func FilesOpenForWriting(dirPath string) (map[string]bool, error) {
+	absDir, err := filepath.Abs(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve directory: %w", err)
+	}
+	// fd のリンク先はシンボリックリンクを解決したパスになる
+	if resolved, err := filepath.EvalSymlinks(absDir); err == nil {
+		absDir = resolved
+	}
+
+	procs, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", procRoot, err)
+	}
+
+	open := make(map[string]bool)
+	for _, proc := range procs {
+		if _, err := strconv.Atoi(proc.Name()); err != nil {
+			continue
+		}
+
+		fdDir := filepath.Join(procRoot, proc.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			// 終了したプロセスや権限のないプロセスはスキップ
+			continue
+		}
+
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil || filepath.Dir(target) != absDir {
+				continue
+			}
+
+			if openedForWriting(filepath.Join(procRoot, proc.Name(), "fdinfo", fd.Name())) {
+				open[filepath.Base(target)] = true
+			}
+		}
+	}
+
+	return open, nil
+}
+
+// openedForWriting は fdinfo の flags からファイルディスクリプタが書き込み可能かどうかを返す
+func openedForWriting(fdinfoPath string) bool {
+	file, err := os.Open(fdinfoPath)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		value, ok := strings.CutPrefix(scanner.Text(), "flags:")
+		if !ok {
+			continue
+		}
+
+		flags, err := strconv.ParseInt(strings.TrimSpace(value), 8, 64)
+		if err != nil {
+			return false
+		}
+		return int(flags)&(os.O_WRONLY|os.O_RDWR) != 0
+	}
+
+	return false
+}
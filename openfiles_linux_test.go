@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilesOpenForWriting(t *testing.T) {
+	t.Parallel()
+
+	tmpDir, err := os.MkdirTemp("", "parakeet-open-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	for _, name := range []string{"downloading.pdf", "reading.pdf", "closed.pdf"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("test content"), 0644))
+	}
+
+	writing, err := os.OpenFile(filepath.Join(tmpDir, "downloading.pdf"), os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	defer func() { _ = writing.Close() }()
+
+	reading, err := os.Open(filepath.Join(tmpDir, "reading.pdf"))
+	require.NoError(t, err)
+	defer func() { _ = reading.Close() }()
+
+	// 書き込み用に開いているファイルのみ検出する
+	open, err := FilesOpenForWriting(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"downloading.pdf": true}, open)
+}
+
+func TestGenerateFileNames_SkipOpen(t *testing.T) {
+	t.Parallel()
+
+	tmpDir, err := os.MkdirTemp("", "parakeet-open-generate-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	for _, name := range []string{"downloading.pdf", "done.pdf"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("test content"), 0644))
+	}
+
+	writing, err := os.OpenFile(filepath.Join(tmpDir, "downloading.pdf"), os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	opts := RenameOptions{
+		Writer:        buf,
+		FilterOptions: FilterOptions{Extensions: []string{"pdf"}},
+		SkipOpen:      true,
+	}
+	require.NoError(t, GenerateFileNames(context.Background(), tmpDir, opts))
+	assert.FileExists(t, filepath.Join(tmpDir, "downloading.pdf"))
+	assert.NoFileExists(t, filepath.Join(tmpDir, "done.pdf"))
+	assert.Contains(t, buf.String(), "downloading.pdf (open for writing by another process, skipped)")
+
+	// 閉じた後の実行でリネームされる
+	require.NoError(t, writing.Close())
+	require.NoError(t, GenerateFileNames(context.Background(), tmpDir, opts))
+	assert.NoFileExists(t, filepath.Join(tmpDir, "downloading.pdf"))
+}
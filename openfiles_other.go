@@ -0,0 +1,9 @@
+//go:build !linux
+
+package main
+
+// FilesOpenForWriting はディレクトリ直下のファイルのうち、いずれかのプロセスが書き込み用に開いているものを返す
+// 戻り値のキーはファイル名。Linux 以外では検出できないため ErrOpenCheckUnsupported を返す
+func FilesOpenForWriting(dirPath string) (map[string]bool, error) {
+	return nil, ErrOpenCheckUnsupported
+}
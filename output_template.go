@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// OutputTemplateData は --template に渡す1ファイル分のデータを表す
+// ファイル名の構成要素（{{.Timestamp}}, {{.Comment}}, {{.Tags}}, {{.Extension}}）とファイルの情報を持つ
+type OutputTemplateData struct {
+	parakeet.FileNameComponents
+	File    string    // ファイル名
+	Path    string    // ファイルのパス
+	Size    int64     // サイズ（バイト）
+	ModTime time.Time // 更新日時
+}
+
+// outputTemplateFuncs はテンプレートで使える関数
+var outputTemplateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"bytes": FormatBytes,
+	"date":  timestampDate,
+}
+
+// ParseOutputTemplate は1ファイル1行の出力テンプレートを解釈する
+// text が存在するファイルのパスであればその内容を、そうでなければ text そのものをテンプレートとして扱う
+// 末尾に改行がない場合は改行を補う
+func ParseOutputTemplate(text string) (*template.Template, error) {
+	if info, err := os.Stat(text); err == nil && !info.IsDir() {
+		content, err := os.ReadFile(text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template: %w", err)
+		}
+		text = string(content)
+	}
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+
+	tmpl, err := template.New("output").Funcs(outputTemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// executeOutputTemplate は1ファイル分のデータでテンプレートを実行した結果を返す
+func executeOutputTemplate(tmpl *template.Template, data OutputTemplateData) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+	return buf.String(), nil
+}
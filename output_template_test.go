@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOutputTemplate(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-template-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	data := OutputTemplateData{File: "20250903T083109--tcpip__network_infra.pdf", Size: 2048}
+	data.Timestamp = "20250903T083109"
+	data.Comment = "tcpip"
+	data.Tags = []string{"network", "infra"}
+	data.Extension = "pdf"
+
+	// インライン（末尾の改行を補う）
+	tmpl, err := ParseOutputTemplate(`{{date .Timestamp}} {{.Comment}} [{{join .Tags ","}}] {{bytes .Size}}`)
+	require.NoError(t, err)
+	line, err := executeOutputTemplate(tmpl, data)
+	require.NoError(t, err)
+	assert.Equal(t, "2025-09-03 tcpip [network,infra] 2.0KiB\n", line)
+
+	// ファイルのパス
+	path := filepath.Join(tmpDir, "line.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte("{{.File}}\n"), 0644))
+	tmpl, err = ParseOutputTemplate(path)
+	require.NoError(t, err)
+	line, err = executeOutputTemplate(tmpl, data)
+	require.NoError(t, err)
+	assert.Equal(t, "20250903T083109--tcpip__network_infra.pdf\n", line)
+
+	_, err = ParseOutputTemplate("{{.Comment")
+	assert.ErrorContains(t, err, "invalid template")
+
+	tmpl, err = ParseOutputTemplate("{{.Owner}}")
+	require.NoError(t, err)
+	_, err = executeOutputTemplate(tmpl, data)
+	assert.ErrorContains(t, err, "failed to execute template")
+}
+
+func TestListFiles_Template(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-template-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	for _, name := range []string{"20250903T083109--tcpip__network.pdf", "20250904T000000--memo.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("test"), 0644))
+	}
+
+	tmpl, err := ParseOutputTemplate("{{.Extension}}\t{{.Comment}}\t{{.Path}}")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	_, err = ListFiles(tmpDir, ListOptions{Writer: &buf, Template: tmpl})
+	require.NoError(t, err)
+	assert.Equal(t, "pdf\ttcpip\t"+filepath.Join(tmpDir, "20250903T083109--tcpip__network.pdf")+"\n"+
+		"md\tmemo\t"+filepath.Join(tmpDir, "20250904T000000--memo.md")+"\n", buf.String())
+}
@@ -0,0 +1,14 @@
+// Package parakeet はファイル命名規則 {timestamp}--{comment}__{tag1}_{tag2}.{ext} の
+// 生成・パース・検証を提供する
+//
+// このパッケージが扱うのはファイル名の文字列のみで、提供するのは次の範囲に限る
+//   - ファイル名の構成要素のパースと組み立て（FileNameComponents, ParseFileName, FormatFileName）
+//   - タイムスタンプの生成と精度（GenerateUniqueTimestampWithPrecision, Precision）
+//   - ファイル名・タイムスタンプの検証（ValidateFileName, ValidateTimestamp）
+//   - 元のファイル名からコメントを作るルール（CommentSanitizer）
+//
+// ディレクトリ単位の操作（generate のリネーム、ディレクトリの validate、tag、md の表の出力）は
+// 出力・設定ファイル・シム・フックに依存するため parakeet コマンド（package main）にあり、
+// 公開 API ではない。他の Go プログラムから使う場合は、このパッケージでファイル名を扱い、
+// ディレクトリの走査とリネームは呼び出し側で行う
+package parakeet
@@ -0,0 +1,237 @@
+package parakeet
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// TimestampLayout はタイムスタンプ（ID）の time パッケージでのレイアウト
+const TimestampLayout = "20060102T150405"
+
+// TimestampMilliLayout はミリ秒まで含むタイムスタンプの time パッケージでのレイアウト
+// ISO 8601 の小数の区切りのうち、拡張子の区切りと紛らわしくないカンマを使う（例: 20250903T083109,123）
+// time.Parse(TimestampLayout, ...) でもパースできる
+const TimestampMilliLayout = "20060102T150405,000"
+
+// Precision はタイムスタンプの精度を表す
+type Precision string
+
+// タイムスタンプの精度
+const (
+	PrecisionSecond      Precision = "second"      // 秒まで（20250903T083109）。重複する場合は1秒ずつ進める
+	PrecisionMillisecond Precision = "millisecond" // ミリ秒まで（20250903T083109,123）。重複する場合は1ミリ秒ずつ進める
+)
+
+// ParsePrecision は文字列からタイムスタンプの精度を取得する（空の場合は second）
+func ParsePrecision(s string) (Precision, error) {
+	switch precision := Precision(s); precision {
+	case "":
+		return PrecisionSecond, nil
+	case PrecisionSecond, PrecisionMillisecond:
+		return precision, nil
+	default:
+		return "", fmt.Errorf("unknown timestamp precision: %s (expected second or millisecond)", s)
+	}
+}
+
+// layout は精度に対応するレイアウトと、重複した場合に進める時間を返す
+func (p Precision) layout() (string, time.Duration) {
+	if p == PrecisionMillisecond {
+		return TimestampMilliLayout, time.Millisecond
+	}
+	return TimestampLayout, time.Second
+}
+
+// Format は時刻を精度に応じたタイムスタンプにする
+func (p Precision) Format(t time.Time) string {
+	layout, _ := p.layout()
+	return t.Format(layout)
+}
+
+// ErrReservedSeparator はコメントやタグに区切り（--, __）と誤認される文字列が含まれる場合のエラー
+var ErrReservedSeparator = errors.New("reserved separator")
+
+// FileNameComponents はフォーマット済みファイル名の構成要素を表す
+type FileNameComponents struct {
+	Timestamp string   // タイムスタンプ（ISO8601形式: 20250903T083109）
+	Comment   string   // 人間が読めるコメント
+	Tags      []string // タグのリスト
+	Extension string   // 拡張子
+}
+
+// GenerateTimestamp は現在時刻からタイムスタンプを生成する
+// フォーマット: YYYYMMDDTHHMMSS
+func GenerateTimestamp() string {
+	return time.Now().Format(TimestampLayout)
+}
+
+// GenerateUniqueTimestamp は既存のタイムスタンプと重複しないタイムスタンプを生成する
+// existingTimestamps に既存のタイムスタンプのリストを渡す
+func GenerateUniqueTimestamp(existingTimestamps map[string]bool) string {
+	return GenerateUniqueTimestampFrom(time.Now(), existingTimestamps)
+}
+
+// GenerateUniqueTimestampFrom は指定時刻を起点に既存のタイムスタンプと重複しないタイムスタンプを生成する
+// 重複する場合は1秒ずつ進める
+func GenerateUniqueTimestampFrom(base time.Time, existingTimestamps map[string]bool) string {
+	return GenerateUniqueTimestampWithPrecision(base, existingTimestamps, PrecisionSecond)
+}
+
+// GenerateUniqueTimestampWithPrecision は指定時刻を起点に、指定した精度で既存のタイムスタンプと重複しないタイムスタンプを生成する
+// 重複する場合は精度の単位（1秒または1ミリ秒）ずつ進める。1秒に多数のファイルを作る場合、
+// ミリ秒の精度にすると実際の時刻から大きくずれたタイムスタンプにならない
+func GenerateUniqueTimestampWithPrecision(base time.Time, existingTimestamps map[string]bool, precision Precision) string {
+	layout, step := precision.layout()
+	t := base
+	for {
+		timestamp := t.Format(layout)
+		if !existingTimestamps[timestamp] {
+			return timestamp
+		}
+		t = t.Add(step)
+	}
+}
+
+// FormatFileName は構成要素からフォーマット済みファイル名を生成する
+// フォーマット: {timestamp}--{comment}__{tag1}_{tag2}.{extension}
+func (c FileNameComponents) FormatFileName() string {
+	var parts []string
+
+	// タイムスタンプとコメント部分
+	parts = append(parts, fmt.Sprintf("%s--%s", c.Timestamp, c.Comment))
+
+	// タグ部分（存在する場合）
+	if len(c.Tags) > 0 {
+		parts = append(parts, strings.Join(c.Tags, "_"))
+	}
+
+	// ダブルアンダースコアで結合
+	baseName := strings.Join(parts, "__")
+
+	// 拡張子を追加
+	if c.Extension != "" {
+		return fmt.Sprintf("%s.%s", baseName, c.Extension)
+	}
+
+	return baseName
+}
+
+// FormatFileNameStrict は構成要素が区切りと誤認される文字列を含まないことを確認してからファイル名を生成する
+// ファイルを作成・リネームするときは、パースし直すと別の構成要素になるファイル名を作らないようにこちらを使う
+func (c FileNameComponents) FormatFileNameStrict() (string, error) {
+	if err := c.Validate(); err != nil {
+		return "", err
+	}
+	return c.FormatFileName(), nil
+}
+
+// Validate はコメントとタグに区切りと誤認される文字列が含まれていないかチェックする
+// コメントの "--" と "__"、タグがある場合のコメント末尾の "_"、空のタグ、"_" を含むタグは
+// ファイル名をパースし直したときに別の構成要素になる
+func (c FileNameComponents) Validate() error {
+	for _, sep := range []string{"--", "__"} {
+		if strings.Contains(c.Comment, sep) {
+			return fmt.Errorf("%w in comment: %q contains %q", ErrReservedSeparator, c.Comment, sep)
+		}
+	}
+
+	if len(c.Tags) > 0 && strings.HasSuffix(c.Comment, "_") {
+		return fmt.Errorf("%w in comment: %q ends with \"_\"", ErrReservedSeparator, c.Comment)
+	}
+
+	for _, tag := range c.Tags {
+		if tag == "" {
+			return fmt.Errorf("%w in tags: empty tag", ErrReservedSeparator)
+		}
+		if strings.Contains(tag, "_") {
+			return fmt.Errorf("%w in tags: %q contains \"_\"", ErrReservedSeparator, tag)
+		}
+	}
+
+	return nil
+}
+
+// ParseFileNameStrict はフォーマット済みファイル名をパースし、
+// コメントやタグに区切りと誤認される文字列を含むファイル名（20250903T083109--a--b.txt、20250903T083109--a__.txt など）を拒否する
+func ParseFileNameStrict(filename string) (*FileNameComponents, error) {
+	components, err := ParseFileName(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := components.Validate(); err != nil {
+		return nil, err
+	}
+
+	return components, nil
+}
+
+// ParseFileName はフォーマット済みファイル名を構成要素にパースする
+func ParseFileName(filename string) (*FileNameComponents, error) {
+	// 拡張子を削除
+	ext := filepath.Ext(filename)
+	baseName := strings.TrimSuffix(filename, ext)
+	if ext != "" {
+		ext = ext[1:] // 先頭のドットを削除
+	}
+
+	// ダブルアンダースコアで分割
+	parts := strings.Split(baseName, "__")
+	if len(parts) < 1 {
+		return nil, fmt.Errorf("invalid filename format: %s", filename)
+	}
+
+	// タイムスタンプとコメントをパース（最初の部分）
+	timestampCommentParts := strings.SplitN(parts[0], "--", 2)
+	if len(timestampCommentParts) != 2 {
+		return nil, fmt.Errorf("invalid timestamp-comment format: %s", parts[0])
+	}
+
+	components := &FileNameComponents{
+		Timestamp: timestampCommentParts[0],
+		Comment:   timestampCommentParts[1],
+		Extension: ext,
+	}
+
+	// タグをパース（残りの部分）
+	if len(parts) > 1 {
+		for i := 1; i < len(parts); i++ {
+			tags := strings.Split(parts[i], "_")
+			components.Tags = append(components.Tags, tags...)
+		}
+	}
+
+	return components, nil
+}
+
+// IsFormatted はファイル名が正しいフォーマットかどうかをチェックする
+func IsFormatted(filename string) bool {
+	_, err := ParseFileName(filename)
+	return err == nil
+}
+
+// MatchesExtensions はファイル名が指定された拡張子のいずれかに一致するかチェックする
+// extensions が空の場合は常に true を返す
+func MatchesExtensions(filename string, extensions []string) bool {
+	// 拡張子指定がない場合はすべて対象
+	if len(extensions) == 0 {
+		return true
+	}
+
+	ext := filepath.Ext(filename)
+	if ext != "" {
+		ext = ext[1:] // 先頭のドットを削除
+	}
+
+	// 拡張子が一致するかチェック
+	for _, targetExt := range extensions {
+		if strings.EqualFold(ext, targetExt) {
+			return true
+		}
+	}
+
+	return false
+}
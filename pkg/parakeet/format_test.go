@@ -0,0 +1,492 @@
+package parakeet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateTimestamp(t *testing.T) {
+	t.Parallel()
+	timestamp := GenerateTimestamp()
+
+	// Check format (YYYYMMDDTHHMMSS)
+	assert.Len(t, timestamp, 15, "Timestamp should be 15 characters")
+	assert.Contains(t, timestamp, "T", "Timestamp should contain 'T' separator")
+
+	// Check if parseable as expected format
+	_, err := time.Parse("20060102T150405", timestamp)
+	assert.NoError(t, err, "Timestamp should be parseable")
+}
+
+func TestFormatFileName(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		components FileNameComponents
+		expected   string
+	}{
+		{
+			name: "basic filename with extension",
+			components: FileNameComponents{
+				Timestamp: "20250903T083109",
+				Comment:   "TCPIP入門",
+				Tags:      []string{"network", "infra"},
+				Extension: "pdf",
+			},
+			expected: "20250903T083109--TCPIP入門__network_infra.pdf",
+		},
+		{
+			name: "filename without tags",
+			components: FileNameComponents{
+				Timestamp: "20250903T083109",
+				Comment:   "sample",
+				Tags:      []string{},
+				Extension: "txt",
+			},
+			expected: "20250903T083109--sample.txt",
+		},
+		{
+			name: "filename with single tag",
+			components: FileNameComponents{
+				Timestamp: "20250903T083109",
+				Comment:   "document",
+				Tags:      []string{"important"},
+				Extension: "doc",
+			},
+			expected: "20250903T083109--document__important.doc",
+		},
+		{
+			name: "filename without extension",
+			components: FileNameComponents{
+				Timestamp: "20250903T083109",
+				Comment:   "noext",
+				Tags:      []string{"tag1", "tag2"},
+				Extension: "",
+			},
+			expected: "20250903T083109--noext__tag1_tag2",
+		},
+		{
+			name: "filename with multiple tags",
+			components: FileNameComponents{
+				Timestamp: "20250101T120000",
+				Comment:   "multi-tag-file",
+				Tags:      []string{"tag1", "tag2", "tag3", "tag4"},
+				Extension: "md",
+			},
+			expected: "20250101T120000--multi-tag-file__tag1_tag2_tag3_tag4.md",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result := tt.components.FormatFileName()
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestParseFileName(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		filename string
+		expected *FileNameComponents
+		wantErr  bool
+	}{
+		{
+			name:     "valid filename with tags",
+			filename: "20250903T083109--TCPIP入門__network_infra.pdf",
+			expected: &FileNameComponents{
+				Timestamp: "20250903T083109",
+				Comment:   "TCPIP入門",
+				Tags:      []string{"network", "infra"},
+				Extension: "pdf",
+			},
+			wantErr: false,
+		},
+		{
+			name:     "valid filename without tags",
+			filename: "20250903T083109--sample.txt",
+			expected: &FileNameComponents{
+				Timestamp: "20250903T083109",
+				Comment:   "sample",
+				Tags:      nil,
+				Extension: "txt",
+			},
+			wantErr: false,
+		},
+		{
+			name:     "valid filename with single tag",
+			filename: "20250903T083109--document__important.doc",
+			expected: &FileNameComponents{
+				Timestamp: "20250903T083109",
+				Comment:   "document",
+				Tags:      []string{"important"},
+				Extension: "doc",
+			},
+			wantErr: false,
+		},
+		{
+			name:     "valid filename without extension",
+			filename: "20250903T083109--noext__tag1_tag2",
+			expected: &FileNameComponents{
+				Timestamp: "20250903T083109",
+				Comment:   "noext",
+				Tags:      []string{"tag1", "tag2"},
+				Extension: "",
+			},
+			wantErr: false,
+		},
+		{
+			name:     "invalid filename - no double dash",
+			filename: "20250903T083109_TCPIP.pdf",
+			expected: nil,
+			wantErr:  true,
+		},
+		{
+			name:     "invalid filename - empty",
+			filename: "",
+			expected: nil,
+			wantErr:  true,
+		},
+		{
+			name:     "valid filename with multiple tag groups",
+			filename: "20250101T120000--multi__tag1_tag2__tag3_tag4.md",
+			expected: &FileNameComponents{
+				Timestamp: "20250101T120000",
+				Comment:   "multi",
+				Tags:      []string{"tag1", "tag2", "tag3", "tag4"},
+				Extension: "md",
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := ParseFileName(tt.filename)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, result)
+				assert.Equal(t, tt.expected.Timestamp, result.Timestamp)
+				assert.Equal(t, tt.expected.Comment, result.Comment)
+				assert.Equal(t, tt.expected.Extension, result.Extension)
+				assert.Equal(t, tt.expected.Tags, result.Tags)
+			}
+		})
+	}
+}
+
+func TestIsFormatted(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		filename string
+		expected bool
+	}{
+		{
+			name:     "valid formatted filename",
+			filename: "20250903T083109--TCPIP入門__network_infra.pdf",
+			expected: true,
+		},
+		{
+			name:     "valid formatted filename without tags",
+			filename: "20250903T083109--sample.txt",
+			expected: true,
+		},
+		{
+			name:     "invalid filename",
+			filename: "regular_file.pdf",
+			expected: false,
+		},
+		{
+			name:     "invalid filename - no timestamp",
+			filename: "TCPIP入門__network.pdf",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result := IsFormatted(tt.filename)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestFormatParseRoundTrip(t *testing.T) {
+	t.Parallel()
+	// Test that formatting and parsing are inverse operations
+	original := FileNameComponents{
+		Timestamp: "20250903T083109",
+		Comment:   "test-file",
+		Tags:      []string{"tag1", "tag2", "tag3"},
+		Extension: "pdf",
+	}
+
+	formatted := original.FormatFileName()
+	parsed, err := ParseFileName(formatted)
+
+	require.NoError(t, err)
+	assert.Equal(t, original.Timestamp, parsed.Timestamp)
+	assert.Equal(t, original.Comment, parsed.Comment)
+	assert.Equal(t, original.Extension, parsed.Extension)
+	assert.Equal(t, original.Tags, parsed.Tags)
+}
+
+func TestMatchesExtensions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		filename   string
+		extensions []string
+		expected   bool
+	}{
+		{
+			name:       "no extensions filter - match all",
+			filename:   "test.txt",
+			extensions: []string{},
+			expected:   true,
+		},
+		{
+			name:       "nil extensions filter - match all",
+			filename:   "test.pdf",
+			extensions: nil,
+			expected:   true,
+		},
+		{
+			name:       "exact match",
+			filename:   "test.pdf",
+			extensions: []string{"pdf"},
+			expected:   true,
+		},
+		{
+			name:       "case insensitive match",
+			filename:   "test.PDF",
+			extensions: []string{"pdf"},
+			expected:   true,
+		},
+		{
+			name:       "multiple extensions - first match",
+			filename:   "test.txt",
+			extensions: []string{"txt", "pdf", "md"},
+			expected:   true,
+		},
+		{
+			name:       "multiple extensions - last match",
+			filename:   "test.md",
+			extensions: []string{"txt", "pdf", "md"},
+			expected:   true,
+		},
+		{
+			name:       "no match",
+			filename:   "test.jpg",
+			extensions: []string{"txt", "pdf", "md"},
+			expected:   false,
+		},
+		{
+			name:       "no extension file - match when no ext specified",
+			filename:   "test",
+			extensions: []string{},
+			expected:   true,
+		},
+		{
+			name:       "no extension file - no match when ext specified",
+			filename:   "test",
+			extensions: []string{"txt"},
+			expected:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result := MatchesExtensions(tt.filename, tt.extensions)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestGenerateUniqueTimestamp(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name               string
+		existingTimestamps map[string]bool
+		shouldBeDifferent  bool
+	}{
+		{
+			name:               "no existing timestamps",
+			existingTimestamps: map[string]bool{},
+			shouldBeDifferent:  false,
+		},
+		{
+			name: "existing timestamp matches current time",
+			existingTimestamps: map[string]bool{
+				GenerateTimestamp(): true,
+			},
+			shouldBeDifferent: true,
+		},
+		{
+			name: "multiple existing timestamps",
+			existingTimestamps: map[string]bool{
+				"20250903T083109": true,
+				"20250903T083110": true,
+				"20250903T083111": true,
+			},
+			shouldBeDifferent: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result := GenerateUniqueTimestamp(tt.existingTimestamps)
+
+			// 生成されたタイムスタンプが既存のものと重複しないことを確認
+			assert.False(t, tt.existingTimestamps[result], "Generated timestamp should not exist in existing timestamps")
+
+			// タイムスタンプのフォーマットを確認
+			assert.Len(t, result, 15, "Timestamp should be 15 characters")
+		})
+	}
+}
+
+func TestGenerateUniqueTimestampFrom(t *testing.T) {
+	t.Parallel()
+	base := time.Date(2025, 9, 3, 8, 31, 9, 0, time.Local)
+
+	// 重複がなければ起点時刻をそのまま使う
+	assert.Equal(t, "20250903T083109", GenerateUniqueTimestampFrom(base, map[string]bool{}))
+
+	// 重複する場合は1秒ずつ進める
+	existing := map[string]bool{
+		"20250903T083109": true,
+		"20250903T083110": true,
+	}
+	assert.Equal(t, "20250903T083111", GenerateUniqueTimestampFrom(base, existing))
+}
+
+func TestGenerateUniqueTimestampWithPrecision(t *testing.T) {
+	t.Parallel()
+	base := time.Date(2025, 9, 3, 8, 31, 9, 123456789, time.Local)
+
+	assert.Equal(t, "20250903T083109", GenerateUniqueTimestampWithPrecision(base, map[string]bool{}, PrecisionSecond))
+	assert.Equal(t, "20250903T083109,123", GenerateUniqueTimestampWithPrecision(base, map[string]bool{}, PrecisionMillisecond))
+
+	// 重複する場合は1ミリ秒ずつ進める
+	existing := map[string]bool{
+		"20250903T083109,123": true,
+		"20250903T083109,124": true,
+	}
+	timestamp := GenerateUniqueTimestampWithPrecision(base, existing, PrecisionMillisecond)
+	assert.Equal(t, "20250903T083109,125", timestamp)
+
+	// ミリ秒まで含むファイル名もパース・検証できる
+	fileName := FileNameComponents{Timestamp: timestamp, Comment: "memo", Extension: "md"}.FormatFileName()
+	assert.Equal(t, "20250903T083109,125--memo.md", fileName)
+	assert.NoError(t, ValidateFileName(fileName))
+	assert.NoError(t, ValidateFileName("20250903T083109,125--memo"))
+}
+
+func TestParsePrecision(t *testing.T) {
+	t.Parallel()
+
+	precision, err := ParsePrecision("")
+	assert.NoError(t, err)
+	assert.Equal(t, PrecisionSecond, precision)
+
+	precision, err = ParsePrecision("millisecond")
+	assert.NoError(t, err)
+	assert.Equal(t, PrecisionMillisecond, precision)
+
+	_, err = ParsePrecision("nanosecond")
+	assert.Error(t, err)
+}
+
+func TestFileNameComponents_Validate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		components FileNameComponents
+		wantErr    bool
+	}{
+		{
+			name:       "valid",
+			components: FileNameComponents{Timestamp: "20250903T083109", Comment: "a-b_c", Tags: []string{"tag1"}, Extension: "txt"},
+		},
+		{
+			name:       "trailing underscore without tags",
+			components: FileNameComponents{Timestamp: "20250903T083109", Comment: "draft_", Extension: "txt"},
+		},
+		{
+			name:       "timestamp separator in comment",
+			components: FileNameComponents{Timestamp: "20250903T083109", Comment: "a--b", Extension: "txt"},
+			wantErr:    true,
+		},
+		{
+			name:       "tag separator in comment",
+			components: FileNameComponents{Timestamp: "20250903T083109", Comment: "a__b", Extension: "txt"},
+			wantErr:    true,
+		},
+		{
+			name:       "trailing underscore with tags",
+			components: FileNameComponents{Timestamp: "20250903T083109", Comment: "draft_", Tags: []string{"tag1"}, Extension: "txt"},
+			wantErr:    true,
+		},
+		{
+			name:       "empty tag",
+			components: FileNameComponents{Timestamp: "20250903T083109", Comment: "draft", Tags: []string{""}, Extension: "txt"},
+			wantErr:    true,
+		},
+		{
+			name:       "underscore in tag",
+			components: FileNameComponents{Timestamp: "20250903T083109", Comment: "draft", Tags: []string{"a_b"}, Extension: "txt"},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.components.Validate()
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrReservedSeparator)
+
+			// 区切りを含む構成要素からはファイル名を生成しない
+			_, err = tt.components.FormatFileNameStrict()
+			assert.ErrorIs(t, err, ErrReservedSeparator)
+		})
+	}
+}
+
+func TestParseFileNameStrict(t *testing.T) {
+	t.Parallel()
+	components, err := ParseFileNameStrict("20250903T083109--draft__tag1_tag2.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "draft", components.Comment)
+	assert.Equal(t, []string{"tag1", "tag2"}, components.Tags)
+
+	// ParseFileName では受け付けるが、区切りが曖昧なファイル名
+	for _, name := range []string{
+		"20250903T083109--a--b.txt",
+		"20250903T083109--draft__.txt",
+		"20250903T083109--draft___tag1.txt",
+	} {
+		assert.True(t, IsFormatted(name), name)
+		_, err := ParseFileNameStrict(name)
+		assert.ErrorIs(t, err, ErrReservedSeparator, name)
+	}
+}
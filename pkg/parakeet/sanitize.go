@@ -0,0 +1,83 @@
+package parakeet
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultComment はコメントが空になった場合に使うコメント
+const DefaultComment = "untitled"
+
+var (
+	// unsafeCommentChars はファイル名に使えない文字
+	unsafeCommentChars = regexp.MustCompile(`[\\/:*?"<>|\x00-\x1f]+`)
+	// repeatedUnderscores はタグの区切りと誤認される連続したアンダースコア
+	repeatedUnderscores = regexp.MustCompile(`_{2,}`)
+	// repeatedDashes はタイムスタンプの区切りと紛らわしい連続したダッシュ
+	repeatedDashes = regexp.MustCompile(`-{2,}`)
+	// repeatedSpaces は連続した空白
+	repeatedSpaces = regexp.MustCompile(`\s+`)
+)
+
+// CommentSanitizer はファイル名の一部をコメントに整えるルールを表す
+// ゼロ値は空白とアンダースコアをそのまま残すデフォルトのルール
+type CommentSanitizer struct {
+	Space      string `toml:"space"`      // 空白の置き換え先（" ", "-", "_" のいずれか、空または " " の場合は空白のまま）
+	Underscore string `toml:"underscore"` // アンダースコアの置き換え先（"_", "-", " " のいずれか、空または "_" の場合はアンダースコアのまま）
+	Lowercase  bool   `toml:"lowercase"`  // 小文字にする
+}
+
+// Validate は置き換え先の指定をチェックする
+func (c CommentSanitizer) Validate() error {
+	switch c.Space {
+	case "", " ", "-", "_":
+	default:
+		return fmt.Errorf("invalid sanitize.space: %q (expected \" \", \"-\" or \"_\")", c.Space)
+	}
+
+	switch c.Underscore {
+	case "", "_", "-", " ":
+	default:
+		return fmt.Errorf("invalid sanitize.underscore: %q (expected \"_\", \"-\" or \" \")", c.Underscore)
+	}
+
+	return nil
+}
+
+// Sanitize は文字列をコメントとして使える形に整える
+// ファイル名に使えない文字を除き、空白とアンダースコアを置き換え、
+// 区切り（--, __）と誤認される連続したダッシュ・アンダースコアを1つにまとめる
+// 結果が空になる場合は DefaultComment を返す
+func (c CommentSanitizer) Sanitize(s string) string {
+	s = unsafeCommentChars.ReplaceAllString(s, " ")
+	s = repeatedSpaces.ReplaceAllString(s, " ")
+
+	if c.Underscore != "" {
+		s = strings.ReplaceAll(s, "_", c.Underscore)
+	}
+	if c.Space != "" {
+		s = strings.ReplaceAll(s, " ", c.Space)
+	}
+
+	// 置き換えで連続した区切り文字もまとめる
+	s = repeatedUnderscores.ReplaceAllString(s, "_")
+	s = repeatedDashes.ReplaceAllString(s, "-")
+	s = repeatedSpaces.ReplaceAllString(s, " ")
+
+	if c.Lowercase {
+		s = strings.ToLower(s)
+	}
+
+	s = strings.Trim(s, " -_.")
+	if s == "" {
+		return DefaultComment
+	}
+	return s
+}
+
+// SanitizeComment はデフォルトのルールでファイル名の一部をコメントとして使える文字列に整える
+// ファイル名に使えない文字を除き、タグの区切り（__）と誤認される部分を1つのアンダースコアにする
+func SanitizeComment(s string) string {
+	return CommentSanitizer{}.Sanitize(s)
+}
@@ -0,0 +1,84 @@
+package parakeet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeComment(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "plain", input: "meeting notes", expected: "meeting notes"},
+		{name: "tag separator", input: "report__final", expected: "report_final"},
+		{name: "timestamp separator", input: "draft--v2---final", expected: "draft-v2-final"},
+		{name: "unsafe characters", input: "a:b?c", expected: "a b c"},
+		{name: "trim", input: " _draft- ", expected: "draft"},
+		{name: "empty", input: "__", expected: DefaultComment},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, SanitizeComment(tt.input))
+		})
+	}
+}
+
+func TestCommentSanitizer_Sanitize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		sanitizer CommentSanitizer
+		input     string
+		expected  string
+	}{
+		{
+			name:      "spaces and underscores to dashes",
+			sanitizer: CommentSanitizer{Space: "-", Underscore: "-"},
+			input:     "Meeting Notes_2025 - final",
+			expected:  "Meeting-Notes-2025-final",
+		},
+		{
+			name:      "spaces to underscores",
+			sanitizer: CommentSanitizer{Space: "_"},
+			input:     "a  _ b",
+			expected:  "a_b",
+		},
+		{
+			name:      "underscores to spaces",
+			sanitizer: CommentSanitizer{Underscore: " "},
+			input:     "scan__0001_final",
+			expected:  "scan 0001 final",
+		},
+		{
+			name:      "lowercase",
+			sanitizer: CommentSanitizer{Space: "-", Lowercase: true},
+			input:     "TCP IP 入門",
+			expected:  "tcp-ip-入門",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, tt.sanitizer.Sanitize(tt.input))
+		})
+	}
+}
+
+func TestCommentSanitizer_Validate(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, CommentSanitizer{}.Validate())
+	assert.NoError(t, CommentSanitizer{Space: "-", Underscore: " "}.Validate())
+	assert.NoError(t, CommentSanitizer{Space: " ", Underscore: "_"}.Validate())
+	assert.EqualError(t, CommentSanitizer{Space: "__"}.Validate(), `invalid sanitize.space: "__" (expected " ", "-" or "_")`)
+	assert.EqualError(t, CommentSanitizer{Underscore: "/"}.Validate(), `invalid sanitize.underscore: "/" (expected "_", "-" or " ")`)
+}
@@ -0,0 +1,48 @@
+package parakeet
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidTimestamp はタイムスタンプが YYYYMMDDTHHMMSS（または YYYYMMDDTHHMMSS,mmm）形式の実在する日時でない場合のエラー
+var ErrInvalidTimestamp = errors.New("invalid timestamp")
+
+// ValidateFileName は単一のファイル名をバリデーションする
+func ValidateFileName(filename string) error {
+	components, err := ParseFileNameStrict(filename)
+	if err != nil {
+		return err
+	}
+
+	// タイムスタンプの形式チェック（YYYYMMDDTHHMMSS）
+	if err := ValidateTimestamp(components.Timestamp); err != nil {
+		return err
+	}
+
+	// コメントが空でないかチェック
+	if components.Comment == "" {
+		return fmt.Errorf("comment cannot be empty")
+	}
+
+	return nil
+}
+
+// ValidateTimestamp はタイムスタンプが YYYYMMDDTHHMMSS 形式（ミリ秒まで含む場合は YYYYMMDDTHHMMSS,mmm 形式）の実在する日時かどうかをチェックする
+// 20251399T256161 のように長さが正しくても存在しない日時は ErrInvalidTimestamp を返す
+func ValidateTimestamp(timestamp string) error {
+	layout := TimestampLayout
+	switch {
+	case len(timestamp) == len(TimestampMilliLayout) && timestamp[len(TimestampLayout)] == ',':
+		layout = TimestampMilliLayout
+	case len(timestamp) != len(TimestampLayout):
+		return fmt.Errorf("%w length: expected %d or %d, got %d", ErrInvalidTimestamp, len(TimestampLayout), len(TimestampMilliLayout), len(timestamp))
+	}
+
+	if _, err := time.Parse(layout, timestamp); err != nil {
+		return fmt.Errorf("%w: %s is not a real date and time", ErrInvalidTimestamp, timestamp)
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package parakeet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateFileName(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		filename string
+		wantErr  bool
+	}{
+		{
+			name:     "valid filename",
+			filename: "20250903T083109--test__tag1.txt",
+			wantErr:  false,
+		},
+		{
+			name:     "valid filename without tags",
+			filename: "20250903T083109--test.txt",
+			wantErr:  false,
+		},
+		{
+			name:     "invalid filename format",
+			filename: "invalid.txt",
+			wantErr:  true,
+		},
+		{
+			name:     "invalid timestamp",
+			filename: "2025--test.txt",
+			wantErr:  true,
+		},
+		{
+			name:     "timestamp is not a real date",
+			filename: "20251399T256161--test.txt",
+			wantErr:  true,
+		},
+		{
+			name:     "separator in comment",
+			filename: "20250903T083109--a--b.txt",
+			wantErr:  true,
+		},
+		{
+			name:     "trailing tag separator",
+			filename: "20250903T083109--test__.txt",
+			wantErr:  true,
+		},
+		{
+			name:     "leap day",
+			filename: "20240229T235959--test.txt",
+			wantErr:  false,
+		},
+		{
+			name:     "day out of range in february",
+			filename: "20250229T000000--test.txt",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := ValidateFileName(tt.filename)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateTimestamp(t *testing.T) {
+	t.Parallel()
+	assert.NoError(t, ValidateTimestamp("20250903T083109"))
+	assert.ErrorIs(t, ValidateTimestamp("20251399T256161"), ErrInvalidTimestamp)
+	assert.ErrorIs(t, ValidateTimestamp("2025"), ErrInvalidTimestamp)
+	assert.EqualError(t, ValidateTimestamp("2025"), "invalid timestamp length: expected 15 or 19, got 4")
+	assert.NoError(t, ValidateTimestamp("20250903T083109,123"))
+	assert.ErrorIs(t, ValidateTimestamp("20250903T083109.123"), ErrInvalidTimestamp)
+	assert.ErrorIs(t, ValidateTimestamp("20250903T083109,12a"), ErrInvalidTimestamp)
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// RenamePlan は generate --plan で書き出し、apply で実行するリネームの計画を表す
+type RenamePlan struct {
+	Command string          `json:"command"` // 計画したコマンド
+	Created string          `json:"created"` // 作成日時（RFC3339）
+	Dir     string          `json:"dir"`     // 対象ディレクトリ（絶対パス）
+	Renames []PlannedRename `json:"renames"` // 実行するリネーム（計画した順）
+}
+
+// PlannedRename は計画した1件のリネームを表す
+type PlannedRename struct {
+	From    string `json:"from"`     // 旧ファイル名
+	To      string `json:"to"`       // 新ファイル名（プロファイルの移動先はディレクトリからの相対パス）
+	ModTime string `json:"mod_time"` // 計画時の旧ファイルの更新日時（RFC3339Nano）
+	Size    int64  `json:"size"`     // 計画時の旧ファイルのサイズ
+}
+
+// NewRenamePlan は dirPath での command のリネームの計画を作成する
+func NewRenamePlan(dirPath, command string) (*RenamePlan, error) {
+	absDir, err := filepath.Abs(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve directory: %w", err)
+	}
+
+	return &RenamePlan{
+		Command: command,
+		Created: time.Now().Format(time.RFC3339),
+		Dir:     absDir,
+		Renames: []PlannedRename{},
+	}, nil
+}
+
+// Add はリネームを計画に追加する
+// info は旧ファイルの情報で、apply の前にファイルが変更されていないかの確認に使う
+func (p *RenamePlan) Add(from, to string, info os.FileInfo) {
+	p.Renames = append(p.Renames, PlannedRename{
+		From:    from,
+		To:      to,
+		ModTime: info.ModTime().Format(time.RFC3339Nano),
+		Size:    info.Size(),
+	})
+}
+
+// WriteRenamePlan は計画をJSONファイルに書き出す
+func WriteRenamePlan(path string, plan *RenamePlan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode plan: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write plan: %w", err)
+	}
+	return nil
+}
+
+// LoadRenamePlan はJSONファイルから計画を読み込む
+func LoadRenamePlan(path string) (*RenamePlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %w", err)
+	}
+
+	var plan RenamePlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+	if plan.Dir == "" {
+		return nil, fmt.Errorf("plan has no directory: %s", path)
+	}
+
+	return &plan, nil
+}
+
+// ApplyOptions は計画の実行のオプションを表す
+type ApplyOptions struct {
+	Writer  io.Writer      // 出力先
+	DryRun  bool           // 実際にはリネームしない
+	Journal *Journal       // 実行したリネームを記録するジャーナル（nil の場合は記録しない）
+	Vault   *ObsidianVault // リネームに合わせて wiki リンクを書き換える Obsidian vault（nil の場合は書き換えない）
+	Hooks   *HookRunner    // リネームの前後に実行するフック（nil の場合は実行しない）
+}
+
+// ApplyRenamePlan は計画したリネームを実行する
+// 計画のあとに旧ファイルが変更・削除された場合や、新ファイル名がすでに存在する場合は1つもリネームしない
+// 途中で失敗した場合は実行済みのリネームを元に戻す
+func ApplyRenamePlan(plan *RenamePlan, opts ApplyOptions) ([]renamePlan, error) {
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("renamed", "from", "to", "dry_run")
+
+	// ディレクトリの存在チェック
+	if _, err := os.Stat(plan.Dir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", plan.Dir)
+	}
+
+	// すべてのファイルを確認してから実行する
+	plans := make([]renamePlan, 0, len(plan.Renames))
+	dirs := make(map[string]bool)
+	targets := make(map[string]bool)
+	for _, rename := range plan.Renames {
+		info, err := os.Stat(filepath.Join(plan.Dir, rename.From))
+		if err != nil {
+			return nil, fmt.Errorf("file has been renamed or removed since planning: %s", rename.From)
+		}
+		modTime, err := time.Parse(time.RFC3339Nano, rename.ModTime)
+		if err != nil || !info.ModTime().Equal(modTime) || info.Size() != rename.Size {
+			return nil, fmt.Errorf("file has been modified since planning: %s", rename.From)
+		}
+		if targets[rename.To] {
+			return nil, fmt.Errorf("multiple files would be renamed to: %s", rename.To)
+		}
+		if _, err := os.Lstat(filepath.Join(plan.Dir, rename.To)); err == nil {
+			return nil, fmt.Errorf("target file already exists: %s", rename.To)
+		}
+		targets[rename.To] = true
+
+		plans = append(plans, renamePlan{From: rename.From, To: rename.To})
+		if dir := filepath.Dir(rename.To); dir != "." {
+			dirs[dir] = true
+		}
+	}
+
+	// 計画のあとに作成されたファイルが計画したタイムスタンプを使っていないか確認する
+	if err := checkPlannedTimestamps(plan); err != nil {
+		return nil, err
+	}
+
+	prefix := ""
+	if opts.DryRun {
+		prefix = "[dry-run] "
+	}
+
+	if !opts.DryRun && len(plans) > 0 {
+		// プロファイルの移動先のディレクトリを作成する
+		dests := make([]string, 0, len(dirs))
+		for dir := range dirs {
+			dests = append(dests, dir)
+		}
+		sort.Strings(dests)
+		for _, dest := range dests {
+			if err := os.MkdirAll(filepath.Join(plan.Dir, dest), 0755); err != nil {
+				return nil, fmt.Errorf("failed to create directory: %w", err)
+			}
+		}
+
+		if err := applyRenames(opts.Hooks.Wrap(OSFileSystem), plan.Dir, plans); err != nil {
+			return nil, err
+		}
+		if err := opts.Journal.Record(plan.Dir, plans...); err != nil {
+			reporter.Warnf("%v\n", err)
+		}
+		if err := opts.Vault.RewriteLinks(plan.Dir, plans...); err != nil {
+			reporter.Warnf("%v\n", err)
+		}
+	}
+
+	for _, p := range plans {
+		reporter.Emit("renamed", map[string]any{"from": p.From, "to": p.To, "dry_run": opts.DryRun}, "%s✓ Renamed: %s → %s\n", prefix, p.From, p.To)
+	}
+
+	// サマリーを出力
+	reporter.Printf("\nApply Summary:\n")
+	reporter.Printf("  Command: %s (%s)\n", plan.Command, plan.Created)
+	reporter.Printf("  Renamed: %d\n", len(plans))
+
+	return plans, nil
+}
+
+// checkPlannedTimestamps は計画で新しく割り当てた（または別のディレクトリに移動する）タイムスタンプが、
+// 移動先のディレクトリのリネームしないファイルで使われていないかを確認する
+func checkPlannedTimestamps(plan *RenamePlan) error {
+	renamed := make(map[string]bool, len(plan.Renames))
+	for _, rename := range plan.Renames {
+		renamed[rename.From] = true
+	}
+
+	existing := make(map[string]map[string]bool) // 移動先のディレクトリ -> リネームしないファイルのタイムスタンプ
+	for _, rename := range plan.Renames {
+		to, err := parakeet.ParseFileName(filepath.Base(rename.To))
+		if err != nil {
+			continue
+		}
+		dir := filepath.Dir(rename.To)
+		if from, err := parakeet.ParseFileName(rename.From); err == nil && from.Timestamp == to.Timestamp && dir == "." {
+			continue
+		}
+
+		timestamps, ok := existing[dir]
+		if !ok {
+			timestamps = make(map[string]bool)
+			entries, err := os.ReadDir(filepath.Join(plan.Dir, dir))
+			if err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to read directory: %w", err)
+			}
+			for _, entry := range entries {
+				if entry.IsDir() || (dir == "." && renamed[entry.Name()]) || IsShim(filepath.Join(plan.Dir, dir), entry) {
+					continue
+				}
+				if components, err := parakeet.ParseFileName(entry.Name()); err == nil {
+					timestamps[components.Timestamp] = true
+				}
+			}
+			existing[dir] = timestamps
+		}
+
+		if timestamps[to.Timestamp] {
+			return fmt.Errorf("ID %s has been used by another file since planning: %s", to.Timestamp, rename.To)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePlannedFiles は generate --plan で計画を作り、ファイルに書き出してから読み込み直す
+func writePlannedFiles(t *testing.T, names ...string) (string, *RenamePlan) {
+	t.Helper()
+	files := map[string]string{}
+	for _, name := range names {
+		files[name] = "test content"
+	}
+	tmpDir := newTestDir(t, files)
+
+	plan, err := NewRenamePlan(tmpDir, "generate")
+	require.NoError(t, err)
+	opts := RenameOptions{
+		Writer:        &bytes.Buffer{},
+		FilterOptions: FilterOptions{Extensions: []string{"pdf"}},
+		Plan:          plan,
+	}
+	require.NoError(t, GenerateFileNames(context.Background(), tmpDir, opts))
+
+	planPath := filepath.Join(tmpDir, "plan.json")
+	require.NoError(t, WriteRenamePlan(planPath, plan))
+	loaded, err := LoadRenamePlan(planPath)
+	require.NoError(t, err)
+
+	return tmpDir, loaded
+}
+
+func TestGenerateFileNames_Plan(t *testing.T) {
+	t.Parallel()
+
+	tmpDir, plan := writePlannedFiles(t, "a.pdf", "b.pdf")
+
+	// 計画の作成ではリネームしない
+	for _, name := range []string{"a.pdf", "b.pdf"} {
+		_, err := os.Stat(filepath.Join(tmpDir, name))
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, "generate", plan.Command)
+	require.Len(t, plan.Renames, 2)
+	for _, rename := range plan.Renames {
+		assert.Contains(t, rename.To, "--"+rename.From[:1])
+		assert.Equal(t, int64(len("test content")), rename.Size)
+	}
+}
+
+func TestGenerateFileNames_PlanWithProfiles(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-test-plan-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "photo.jpg"), []byte("test content"), 0644))
+
+	plan, err := NewRenamePlan(tmpDir, "generate")
+	require.NoError(t, err)
+	require.NoError(t, GenerateFileNames(context.Background(), tmpDir, RenameOptions{
+		Writer:   &bytes.Buffer{},
+		Profiles: []Profile{{Name: "images", Extensions: []string{"jpg"}, Dest: "images"}},
+		Plan:     plan,
+	}))
+
+	// 計画のみの場合は移動先を作成しない
+	require.Len(t, plan.Renames, 1)
+	assert.NoDirExists(t, filepath.Join(tmpDir, "images"))
+
+	// 実行するときに作成する
+	_, err = ApplyRenamePlan(plan, ApplyOptions{Writer: &bytes.Buffer{}})
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(tmpDir, plan.Renames[0].To))
+	assert.Equal(t, "images", filepath.Dir(plan.Renames[0].To))
+}
+
+func TestApplyRenamePlan(t *testing.T) {
+	t.Parallel()
+
+	t.Run("計画したリネームを実行する", func(t *testing.T) {
+		t.Parallel()
+		tmpDir, plan := writePlannedFiles(t, "a.pdf", "b.pdf")
+
+		journal := NewJournal(tmpDir, plan.Command)
+		applied, err := ApplyRenamePlan(plan, ApplyOptions{Writer: &bytes.Buffer{}, Journal: journal})
+		require.NoError(t, err)
+		assert.Len(t, applied, 2)
+
+		for _, rename := range plan.Renames {
+			_, err := os.Stat(filepath.Join(tmpDir, rename.From))
+			assert.True(t, os.IsNotExist(err))
+			_, err = os.Stat(filepath.Join(tmpDir, rename.To))
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("dry-run ではリネームしない", func(t *testing.T) {
+		t.Parallel()
+		tmpDir, plan := writePlannedFiles(t, "a.pdf")
+
+		buf := &bytes.Buffer{}
+		_, err := ApplyRenamePlan(plan, ApplyOptions{Writer: buf, DryRun: true})
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "[dry-run] ✓ Renamed: a.pdf")
+
+		_, err = os.Stat(filepath.Join(tmpDir, "a.pdf"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("計画のあとに変更されたファイルがある場合は1つもリネームしない", func(t *testing.T) {
+		t.Parallel()
+		tmpDir, plan := writePlannedFiles(t, "a.pdf", "b.pdf")
+
+		later := time.Now().Add(time.Hour)
+		require.NoError(t, os.Chtimes(filepath.Join(tmpDir, "b.pdf"), later, later))
+
+		_, err := ApplyRenamePlan(plan, ApplyOptions{Writer: &bytes.Buffer{}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "modified since planning: b.pdf")
+
+		for _, name := range []string{"a.pdf", "b.pdf"} {
+			_, err := os.Stat(filepath.Join(tmpDir, name))
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("計画のあとに削除されたファイルがある場合はエラー", func(t *testing.T) {
+		t.Parallel()
+		tmpDir, plan := writePlannedFiles(t, "a.pdf")
+
+		require.NoError(t, os.Remove(filepath.Join(tmpDir, "a.pdf")))
+
+		_, err := ApplyRenamePlan(plan, ApplyOptions{Writer: &bytes.Buffer{}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "removed since planning: a.pdf")
+	})
+
+	t.Run("新しいファイル名がすでに存在する場合はエラー", func(t *testing.T) {
+		t.Parallel()
+		tmpDir, plan := writePlannedFiles(t, "a.pdf")
+
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, plan.Renames[0].To), []byte("other"), 0644))
+
+		_, err := ApplyRenamePlan(plan, ApplyOptions{Writer: &bytes.Buffer{}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "target file already exists")
+	})
+
+	t.Run("計画したタイムスタンプを計画のあとに作成されたファイルが使っている場合は1つもリネームしない", func(t *testing.T) {
+		t.Parallel()
+		tmpDir, plan := writePlannedFiles(t, "a.pdf", "b.pdf")
+
+		components, err := parakeet.ParseFileName(plan.Renames[1].To)
+		require.NoError(t, err)
+		other := components.Timestamp + "--other.md"
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, other), []byte("other"), 0644))
+
+		_, err = ApplyRenamePlan(plan, ApplyOptions{Writer: &bytes.Buffer{}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ID "+components.Timestamp+" has been used by another file since planning")
+
+		for _, name := range []string{"a.pdf", "b.pdf"} {
+			assert.FileExists(t, filepath.Join(tmpDir, name))
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"runtime"
+	"strings"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// windowsInvalidChars は Windows のファイル名に使えない文字
+const windowsInvalidChars = `<>:"/\|?*`
+
+// PortableByDefault は Windows で使えるファイル名に限定するのをデフォルトにするかどうか（Windows の場合）
+var PortableByDefault = runtime.GOOS == "windows"
+
+// portableSeverity は windows-name, max-path ルールのデフォルトの重さ（Windows では error、それ以外では off）
+func portableSeverity() Severity {
+	if PortableByDefault {
+		return SeverityError
+	}
+	return SeverityOff
+}
+
+// PortableComponents はタグと拡張子から Windows のファイル名に使えない文字（制御文字を含む）を取り除く
+// コメントは CommentSanitizer が同じ文字を取り除くため変更しない
+// 文字を取り除いて空になったタグは外し、拡張子の末尾のドットと空白は取り除く
+func PortableComponents(components parakeet.FileNameComponents) parakeet.FileNameComponents {
+	tags := make([]string, 0, len(components.Tags))
+	for _, tag := range components.Tags {
+		if tag = stripWindowsInvalidChars(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	components.Tags = tags
+	components.Extension = strings.TrimRight(stripWindowsInvalidChars(components.Extension), ". ")
+	return components
+}
+
+// stripWindowsInvalidChars は Windows のファイル名に使えない文字と制御文字を取り除く
+func stripWindowsInvalidChars(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(windowsInvalidChars, r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// portableNameLimit は dir に置くファイル名が Windows の MAX_PATH を超えないための長さの上限（バイト数）を返す
+// limit（0 以下の場合は DefaultMaxNameBytes）より短い場合のみ小さくする
+func portableNameLimit(absDir string, limit int) int {
+	if limit <= 0 {
+		limit = DefaultMaxNameBytes
+	}
+	// パスの区切りの分を引く
+	if available := DefaultMaxPathLength - len(absDir) - 1; available < limit {
+		return max(available, 1)
+	}
+	return limit
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPortableComponents(t *testing.T) {
+	t.Parallel()
+
+	components := PortableComponents(parakeet.FileNameComponents{
+		Timestamp: "20250903T083109",
+		Comment:   "memo",
+		Tags:      []string{"a:b", "???", "go"},
+		Extension: "m|d. ",
+	})
+	assert.Equal(t, []string{"ab", "go"}, components.Tags)
+	assert.Equal(t, "md", components.Extension)
+	assert.Equal(t, "memo", components.Comment)
+}
+
+func TestPortableNameLimit(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, DefaultMaxNameBytes, portableNameLimit("/tmp", 0))
+	assert.Equal(t, 100, portableNameLimit("/tmp", 100))
+	assert.Equal(t, 59, portableNameLimit("/"+strings.Repeat("a", 199), 0))
+	assert.Equal(t, 1, portableNameLimit("/"+strings.Repeat("a", 300), 0))
+}
+
+func TestGenerateFileNames_Portable(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-test-portable-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	// 絶対パスが 220 文字のディレクトリに置く（ファイル名に使えるのは 39 文字）
+	absDir, err := filepath.Abs(tmpDir)
+	require.NoError(t, err)
+	deepDir := filepath.Join(absDir, strings.Repeat("d", 220-len(absDir)-1))
+	require.NoError(t, os.MkdirAll(deepDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(deepDir, "a-very-long-downloaded-file-name-for-windows.pdf"), []byte("test content"), 0644))
+
+	opts := RenameOptions{
+		Writer:        &bytes.Buffer{},
+		FilterOptions: FilterOptions{Extensions: []string{"pdf"}},
+		Portable:      true,
+	}
+	require.NoError(t, GenerateFileNames(context.Background(), deepDir, opts))
+
+	entries, err := os.ReadDir(deepDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	name := entries[0].Name()
+	assert.True(t, parakeet.IsFormatted(name), name)
+	assert.LessOrEqual(t, len(filepath.Join(deepDir, name)), DefaultMaxPathLength)
+}
+
+func TestValidateFileNames_Portable(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-validate-portable-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	absDir, err := filepath.Abs(tmpDir)
+	require.NoError(t, err)
+	longName := "20250903T083111--" + strings.Repeat("a", DefaultMaxPathLength-len(absDir)) + ".md"
+	for _, name := range []string{"20250903T083109--memo__a:b.md", "20250903T083110--ok.md", longName} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("content"), 0644))
+	}
+
+	// Windows 以外ではデフォルトで検査しない
+	if !PortableByDefault {
+		result, err := ValidateFileNames(context.Background(), tmpDir, ValidateOptions{Writer: &bytes.Buffer{}})
+		require.NoError(t, err)
+		assert.Empty(t, result.InvalidFiles)
+	}
+
+	rules, err := RuleSeverities{}.With(PortableRules, nil)
+	require.NoError(t, err)
+	buf := &bytes.Buffer{}
+	result, err := ValidateFileNames(context.Background(), tmpDir, ValidateOptions{Writer: buf, Rules: rules})
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"20250903T083109--memo__a:b.md", longName}, result.InvalidFiles)
+	assert.ElementsMatch(t, result.InvalidFiles, result.NonPortableNames)
+	assert.Equal(t, 1, result.ValidFiles)
+	assert.Contains(t, buf.String(), `contains ':', which is not allowed on Windows`)
+	assert.Contains(t, buf.String(), "Not portable: 2")
+
+	for _, problem := range result.Problems() {
+		assert.Equal(t, "not portable", problem.Detail)
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// Query はファイルを選択するクエリを表す
+// 例: tag:network AND ext:pdf AND date>=2025-01
+// nil の場合はすべてのファイルに一致する
+type Query struct {
+	text  string
+	match queryNode
+}
+
+// queryNode はクエリの条件の一致判定関数
+type queryNode func(components *parakeet.FileNameComponents) bool
+
+// queryDatePattern はクエリで比較する日付（YYYY, YYYY-MM, YYYY-MM-DD）
+var queryDatePattern = regexp.MustCompile(`^\d{4}(-\d{2}(-\d{2})?)?$`)
+
+// queryOperators はクエリの条件で使える比較演算子（長いものから順に照合する）
+var queryOperators = []string{">=", "<=", "!=", ":", "=", ">", "<"}
+
+// ParseQuery はクエリを解釈する
+// 条件は tag:, ext:, title:（部分一致）, id:（前方一致）, date（:, =, !=, >=, <=, >, <）で、
+// AND, OR, NOT と括弧で組み合わせる。演算子を省略して並べた条件は AND として扱う
+func ParseQuery(text string) (*Query, error) {
+	tokens, err := tokenizeQuery(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("invalid query: empty")
+	}
+
+	p := &queryParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("invalid query: unexpected %q", p.tokens[p.pos])
+	}
+
+	return &Query{text: text, match: node}, nil
+}
+
+// String はクエリの文字列を返す
+func (q *Query) String() string {
+	if q == nil {
+		return ""
+	}
+	return q.text
+}
+
+// Match はフォーマット済みのファイル名がクエリに一致するかどうかを返す（フォーマット外のファイルは一致しない）
+func (q *Query) Match(fileName string) bool {
+	if q == nil {
+		return true
+	}
+	components, err := parakeet.ParseFileName(fileName)
+	if err != nil {
+		return false
+	}
+	return q.match(components)
+}
+
+// tokenizeQuery はクエリを括弧と条件・演算子の単語に分ける（"..." の中の空白と括弧は区切らない）
+func tokenizeQuery(text string) ([]string, error) {
+	tokens := []string{}
+	var current strings.Builder
+	inQuote := false
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, r := range text {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			current.WriteRune(r)
+		case inQuote:
+			current.WriteRune(r)
+		case r == ' ' || r == '\t':
+			flush()
+		case r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, string(r))
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if inQuote {
+		return nil, fmt.Errorf("invalid query: unterminated quote")
+	}
+	flush()
+
+	return tokens, nil
+}
+
+// queryParser はクエリの単語列を解釈する
+type queryParser struct {
+	tokens []string
+	pos    int
+}
+
+// peek は次の単語を返す（AND, OR, NOT は大文字小文字を区別しない）
+func (p *queryParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	token := p.tokens[p.pos]
+	switch upper := strings.ToUpper(token); upper {
+	case "AND", "OR", "NOT":
+		return upper
+	}
+	return token
+}
+
+// parseOr は OR で区切った条件を解釈する
+func (p *queryParser) parseOr() (queryNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "OR" {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(c *parakeet.FileNameComponents) bool { return l(c) || right(c) }
+	}
+	return left, nil
+}
+
+// parseAnd は AND で区切った（または並べた）条件を解釈する
+func (p *queryParser) parseAnd() (queryNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch p.peek() {
+		case "AND":
+			p.pos++
+		case "", "OR", ")":
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(c *parakeet.FileNameComponents) bool { return l(c) && right(c) }
+	}
+}
+
+// parseUnary は NOT・括弧・条件を解釈する
+func (p *queryParser) parseUnary() (queryNode, error) {
+	switch token := p.peek(); token {
+	case "":
+		return nil, fmt.Errorf("invalid query: unexpected end")
+	case "NOT":
+		p.pos++
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(c *parakeet.FileNameComponents) bool { return !node(c) }, nil
+	case "(":
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("invalid query: missing )")
+		}
+		p.pos++
+		return node, nil
+	case ")", "AND", "OR":
+		return nil, fmt.Errorf("invalid query: unexpected %q", token)
+	default:
+		p.pos++
+		return parseQueryTerm(token)
+	}
+}
+
+// parseQueryTerm は field:value や date>=2025-01 の形式の条件を解釈する
+func parseQueryTerm(term string) (queryNode, error) {
+	field, op, value := "", "", ""
+	for i := range term {
+		for _, candidate := range queryOperators {
+			if strings.HasPrefix(term[i:], candidate) {
+				field, op, value = term[:i], candidate, term[i+len(candidate):]
+				break
+			}
+		}
+		if op != "" {
+			break
+		}
+	}
+	if op == "" || field == "" {
+		return nil, fmt.Errorf("invalid query: %s (expected field:value)", term)
+	}
+	value = strings.Trim(value, `"`)
+	if value == "" {
+		return nil, fmt.Errorf("invalid query: %s (missing value)", term)
+	}
+
+	var node queryNode
+	switch strings.ToLower(field) {
+	case "tag":
+		node = func(c *parakeet.FileNameComponents) bool { return slices.Contains(c.Tags, value) }
+	case "ext":
+		node = func(c *parakeet.FileNameComponents) bool {
+			return strings.EqualFold(c.Extension, strings.TrimPrefix(value, "."))
+		}
+	case "title":
+		lowerValue := strings.ToLower(value)
+		node = func(c *parakeet.FileNameComponents) bool {
+			return strings.Contains(strings.ToLower(c.Comment), lowerValue)
+		}
+	case "id":
+		node = func(c *parakeet.FileNameComponents) bool { return strings.HasPrefix(c.Timestamp, value) }
+	case "date":
+		return parseQueryDate(term, op, value)
+	default:
+		return nil, fmt.Errorf("invalid query: unknown field %s (expected tag, ext, title, id or date)", field)
+	}
+
+	switch op {
+	case ":", "=":
+		return node, nil
+	case "!=":
+		return func(c *parakeet.FileNameComponents) bool { return !node(c) }, nil
+	default:
+		return nil, fmt.Errorf("invalid query: %s (%s can only be used with date)", term, op)
+	}
+}
+
+// parseQueryDate は日付の条件を解釈する
+// タイムスタンプの日付（YYYY-MM-DD）の先頭を指定した日付と同じ長さで比較するため、date>=2025-01 は2025年1月以降に一致する
+func parseQueryDate(term, op, value string) (queryNode, error) {
+	if !queryDatePattern.MatchString(value) {
+		return nil, fmt.Errorf("invalid query: %s (expected YYYY, YYYY-MM or YYYY-MM-DD)", term)
+	}
+
+	return func(c *parakeet.FileNameComponents) bool {
+		date := timestampDate(c.Timestamp)
+		if len(date) < len(value) {
+			return false
+		}
+		date = date[:len(value)]
+		switch op {
+		case ">=":
+			return date >= value
+		case "<=":
+			return date <= value
+		case ">":
+			return date > value
+		case "<":
+			return date < value
+		case "!=":
+			return date != value
+		default:
+			return date == value
+		}
+	}, nil
+}
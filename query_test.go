@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuery_Match(t *testing.T) {
+	t.Parallel()
+
+	files := []string{
+		"20250903T083109--tcpip__network.pdf",
+		"20250103T000000--dns basics__network_infra.md",
+		"20241203T000000--old report__network.pdf",
+		"20250904T000000--memo.PDF",
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"tag:network AND ext:pdf AND date>=2025-01", []string{files[0]}},
+		{"tag:network ext:pdf", []string{files[0], files[2]}},
+		{"ext:pdf", []string{files[0], files[2], files[3]}},
+		{"tag:infra OR title:memo", []string{files[1], files[3]}},
+		{"NOT tag:network", []string{files[3]}},
+		{"not (tag:network or ext:md)", []string{files[3]}},
+		{"tag!=network", []string{files[3]}},
+		{`title:"DNS basics"`, []string{files[1]}},
+		{"id:202409 OR id:2024", []string{files[2]}},
+		{"date<2025", []string{files[2]}},
+		{"date:2025-09", []string{files[0], files[3]}},
+		{"date>2025-01-03 AND date<=2025-09-03", []string{files[0]}},
+		{"date!=2025", []string{files[2]}},
+		{"(tag:infra OR tag:network) AND NOT (date:2025-01 OR date:2024)", []string{files[0]}},
+	}
+	for _, tt := range tests {
+		query, err := ParseQuery(tt.query)
+		require.NoError(t, err, tt.query)
+
+		matched := []string{}
+		for _, file := range files {
+			if query.Match(file) {
+				matched = append(matched, file)
+			}
+		}
+		assert.Equal(t, tt.want, matched, tt.query)
+	}
+
+	// フォーマット外のファイルは一致しない
+	query, err := ParseQuery("NOT tag:network")
+	require.NoError(t, err)
+	assert.False(t, query.Match("notes.pdf"))
+
+	// nil の場合はすべてに一致する
+	var none *Query
+	assert.True(t, none.Match("notes.pdf"))
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		query     string
+		errorText string
+	}{
+		{"", "empty"},
+		{"network", "expected field:value"},
+		{"owner:me", "unknown field owner"},
+		{"tag:", "missing value"},
+		{"tag>network", "> can only be used with date"},
+		{"date>=2025-1", "expected YYYY, YYYY-MM or YYYY-MM-DD"},
+		{"(tag:a OR tag:b", "missing )"},
+		{"tag:a OR", "unexpected end"},
+		{"tag:a )", `unexpected ")"`},
+		{"AND tag:a", `unexpected "AND"`},
+		{`title:"tcp`, "unterminated quote"},
+	}
+	for _, tt := range tests {
+		_, err := ParseQuery(tt.query)
+		assert.ErrorContains(t, err, tt.errorText, tt.query)
+	}
+}
+
+func TestAddTag_Query(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-query-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	for _, name := range []string{"20250903T083109--tcpip__network.pdf", "20241203T000000--old__network.pdf"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("test"), 0644))
+	}
+
+	query, err := ParseQuery("tag:network AND date<2025")
+	require.NoError(t, err)
+
+	result, err := AddTag(tmpDir, "archived", TagBulkOptions{Writer: &bytes.Buffer{}, FilterOptions: FilterOptions{Query: query}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"20241203T000000--old__network.pdf": "20241203T000000--old__archived_network.pdf"}, result.Renamed)
+}
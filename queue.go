@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// 予約済みのステータスタグ
+const (
+	StatusTodo  = "todo"  // 未着手
+	StatusDoing = "doing" // 作業中
+	StatusDone  = "done"  // 完了
+	StatusNone  = "none"  // ステータスタグなし（--status の指定用、タグとしては使わない）
+)
+
+// DefaultNextLimit は next コマンドで表示するファイル数のデフォルト
+const DefaultNextLimit = 10
+
+// statusTags はステータスタグの一覧
+var statusTags = []string{StatusTodo, StatusDoing, StatusDone}
+
+// priorityTagPattern は優先度タグ（p1 が最も高い）
+var priorityTagPattern = regexp.MustCompile(`^p([1-9])$`)
+
+// TagStatus はタグからステータスを返す
+// ステータスタグがない場合は StatusNone を返す
+func TagStatus(tags []string) string {
+	for _, tag := range tags {
+		for _, status := range statusTags {
+			if tag == status {
+				return status
+			}
+		}
+	}
+	return StatusNone
+}
+
+// TagPriority はタグから優先度（1が最も高い）を返す
+// 優先度タグがない場合は 0 を返し、複数ある場合は最も高い優先度を返す
+func TagPriority(tags []string) int {
+	priority := 0
+	for _, tag := range tags {
+		if match := priorityTagPattern.FindStringSubmatch(tag); match != nil {
+			p, _ := strconv.Atoi(match[1])
+			if priority == 0 || p < priority {
+				priority = p
+			}
+		}
+	}
+	return priority
+}
+
+// isQueueTag はタグがステータスタグまたは優先度タグかどうかを返す
+func isQueueTag(tag string) bool {
+	return TagStatus([]string{tag}) != StatusNone || TagPriority([]string{tag}) > 0
+}
+
+// QueueFilter はステータスと優先度による絞り込み条件を表す
+type QueueFilter struct {
+	Status      string // 対象のステータス（空の場合は制限なし）
+	MaxPriority int    // この優先度以上（p1〜pN）のファイルのみ対象（0 の場合は制限なし）
+}
+
+// ParseQueueFilter はフラグの値から絞り込み条件を作成する
+// priority は "p2" または "2" の形式で指定する
+func ParseQueueFilter(status, priority string) (QueueFilter, error) {
+	filter := QueueFilter{}
+
+	switch status {
+	case "", StatusTodo, StatusDoing, StatusDone, StatusNone:
+		filter.Status = status
+	default:
+		return QueueFilter{}, fmt.Errorf("unknown status: %s (expected todo, doing, done or none)", status)
+	}
+
+	if priority != "" {
+		if !strings.HasPrefix(priority, "p") {
+			priority = "p" + priority
+		}
+		filter.MaxPriority = TagPriority([]string{priority})
+		if filter.MaxPriority == 0 {
+			return QueueFilter{}, fmt.Errorf("invalid priority: %s (expected p1-p9)", priority)
+		}
+	}
+
+	return filter, nil
+}
+
+// MatchesQueue はタグが絞り込み条件に一致するかチェックする
+func (f QueueFilter) MatchesQueue(tags []string) bool {
+	if f.Status != "" && TagStatus(tags) != f.Status {
+		return false
+	}
+
+	if f.MaxPriority > 0 {
+		priority := TagPriority(tags)
+		if priority == 0 || priority > f.MaxPriority {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NextOptions は next 操作のオプションを表す
+type NextOptions struct {
+	Writer io.Writer // 出力先
+	FilterOptions
+	Limit int // 表示するファイル数（0 以下の場合は DefaultNextLimit）
+}
+
+// NextFiles は完了していないファイルを優先度の高い順に出力し、そのリストを返す
+// 優先度タグのないファイルは最後に並べ、同じ優先度では古いファイルを先にする
+func NextFiles(targetDir string, opts NextOptions) ([]string, error) {
+	// ディレクトリの存在チェック
+	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", targetDir)
+	}
+
+	entries, err := os.ReadDir(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("file", "id", "priority", "status", "file")
+
+	type candidate struct {
+		fileName   string
+		components *parakeet.FileNameComponents
+		priority   int
+	}
+
+	candidates := []candidate{}
+	for _, entry := range entries {
+		// ディレクトリとシムはスキップ
+		if entry.IsDir() || IsShim(targetDir, entry) {
+			continue
+		}
+
+		fileName := entry.Name()
+
+		// 絞り込み
+		if !opts.Matches(fileName) {
+			continue
+		}
+
+		// フォーマット済みファイルのみ処理
+		components, err := parakeet.ParseFileName(fileName)
+		if err != nil {
+			continue
+		}
+
+		if TagStatus(components.Tags) == StatusDone {
+			continue
+		}
+
+		// 優先度タグがない場合は最も低い優先度として扱う
+		priority := TagPriority(components.Tags)
+		if priority == 0 {
+			priority = 10
+		}
+
+		candidates = append(candidates, candidate{fileName: fileName, components: components, priority: priority})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].priority != candidates[j].priority {
+			return candidates[i].priority < candidates[j].priority
+		}
+		return candidates[i].components.Timestamp < candidates[j].components.Timestamp
+	})
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultNextLimit
+	}
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	files := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		files = append(files, c.fileName)
+
+		priority := "-"
+		if p := TagPriority(c.components.Tags); p > 0 {
+			priority = fmt.Sprintf("p%d", p)
+		}
+		status := TagStatus(c.components.Tags)
+
+		reporter.Emit("file", map[string]any{
+			"file":     c.fileName,
+			"id":       c.components.Timestamp,
+			"priority": priority,
+			"status":   status,
+		}, "%-2s %-5s %s\n", priority, status, c.fileName)
+	}
+
+	return files, nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagStatusAndPriority(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		tags     []string
+		status   string
+		priority int
+	}{
+		{name: "no reserved tags", tags: []string{"network"}, status: StatusNone, priority: 0},
+		{name: "todo p2", tags: []string{"network", "p2", "todo"}, status: StatusTodo, priority: 2},
+		{name: "highest priority wins", tags: []string{"p3", "p1"}, status: StatusNone, priority: 1},
+		{name: "p10 is not a priority", tags: []string{"p10", "done"}, status: StatusDone, priority: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.status, TagStatus(tt.tags))
+			assert.Equal(t, tt.priority, TagPriority(tt.tags))
+		})
+	}
+}
+
+func TestParseQueueFilter(t *testing.T) {
+	t.Parallel()
+
+	filter, err := ParseQueueFilter("todo", "p2")
+	require.NoError(t, err)
+	assert.Equal(t, QueueFilter{Status: StatusTodo, MaxPriority: 2}, filter)
+
+	filter, err = ParseQueueFilter("", "3")
+	require.NoError(t, err)
+	assert.Equal(t, QueueFilter{MaxPriority: 3}, filter)
+
+	_, err = ParseQueueFilter("later", "")
+	assert.Error(t, err)
+
+	_, err = ParseQueueFilter("", "high")
+	assert.Error(t, err)
+}
+
+func TestQueueFilter_MatchesQueue(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, QueueFilter{}.MatchesQueue(nil))
+	assert.True(t, QueueFilter{Status: StatusTodo}.MatchesQueue([]string{"todo"}))
+	assert.False(t, QueueFilter{Status: StatusTodo}.MatchesQueue([]string{"done"}))
+	assert.True(t, QueueFilter{Status: StatusNone}.MatchesQueue([]string{"network"}))
+	assert.True(t, QueueFilter{MaxPriority: 2}.MatchesQueue([]string{"p1"}))
+	assert.False(t, QueueFilter{MaxPriority: 2}.MatchesQueue([]string{"p3"}))
+	assert.False(t, QueueFilter{MaxPriority: 2}.MatchesQueue([]string{"todo"}))
+}
+
+func setupQueueDir(t *testing.T) string {
+	t.Helper()
+
+	return newTestDir(t, map[string]string{
+		"20250903T083109--old-task__p2_todo.md": "test content",
+		"20250903T083110--urgent__p1_doing.md":  "test content",
+		"20250903T083111--finished__done_p1.md": "test content",
+		"20250903T083112--inbox.md":             "test content",
+		"20250903T083113--new-task__p2_todo.md": "test content",
+		"notes.txt":                             "test content",
+	})
+}
+
+func TestNextFiles(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupQueueDir(t)
+
+	buf := &bytes.Buffer{}
+	files, err := NextFiles(tmpDir, NextOptions{Writer: buf})
+	require.NoError(t, err)
+
+	// 完了したファイルを除き、優先度順・古い順に並ぶ
+	assert.Equal(t, []string{
+		"20250903T083110--urgent__p1_doing.md",
+		"20250903T083109--old-task__p2_todo.md",
+		"20250903T083113--new-task__p2_todo.md",
+		"20250903T083112--inbox.md",
+	}, files)
+	assert.Contains(t, buf.String(), "p1 doing 20250903T083110--urgent__p1_doing.md")
+	assert.Contains(t, buf.String(), "-  none  20250903T083112--inbox.md")
+
+	files, err = NextFiles(tmpDir, NextOptions{Writer: &bytes.Buffer{}, Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+}
+
+func TestListAndSearchFiles_QueueFilter(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupQueueDir(t)
+
+	files, err := ListFiles(tmpDir, ListOptions{Writer: &bytes.Buffer{}, QueueFilter: QueueFilter{Status: StatusTodo}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"20250903T083109--old-task__p2_todo.md",
+		"20250903T083113--new-task__p2_todo.md",
+	}, files)
+
+	files, err = SearchFiles(tmpDir, SearchOptions{Writer: &bytes.Buffer{}, QueueFilter: QueueFilter{MaxPriority: 1}, Title: "urgent"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"20250903T083110--urgent__p1_doing.md"}, files)
+}
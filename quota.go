@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// 上限の対象
+const (
+	QuotaScopeDirectory = "directory" // ディレクトリ全体
+	QuotaScopeTag       = "tag"       // タグごと
+)
+
+// QuotaLimit はファイル数と合計サイズの上限（ソフトリミット）を表す
+// 0 または空の場合はその項目を制限しない
+type QuotaLimit struct {
+	MaxFiles int    `toml:"max_files"` // ファイル数の上限
+	MaxSize  string `toml:"max_size"`  // 合計サイズの上限（500MiB, 2GiB など）
+}
+
+// MaxBytes は合計サイズの上限をバイト数で返す（制限しない場合は 0）
+// MaxSize は LoadConfig で検証済みのため、解釈できない場合も 0 を返す
+func (l QuotaLimit) MaxBytes() int64 {
+	if l.MaxSize == "" {
+		return 0
+	}
+	n, err := ParseByteSize(l.MaxSize)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// Validate は上限の指定をチェックする
+func (l QuotaLimit) Validate() error {
+	if l.MaxFiles < 0 {
+		return fmt.Errorf("max_files must not be negative: %d", l.MaxFiles)
+	}
+	if l.MaxSize != "" {
+		if _, err := ParseByteSize(l.MaxSize); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// QuotaConfig はディレクトリ・タグごとの上限の設定を表す
+// 上限を超えても stats と validate で警告するのみで、失敗にはしない
+type QuotaConfig struct {
+	Directory QuotaLimit            `toml:"directory"` // ディレクトリ全体の上限
+	Tag       map[string]QuotaLimit `toml:"tag"`       // タグごとの上限: タグ名 -> 上限
+}
+
+// Configured は上限が1つでも設定されているかどうかを返す
+func (q QuotaConfig) Configured() bool {
+	return q.Directory != (QuotaLimit{}) || len(q.Tag) > 0
+}
+
+// Validate はすべての上限の指定をチェックする
+func (q QuotaConfig) Validate() error {
+	if err := q.Directory.Validate(); err != nil {
+		return fmt.Errorf("quota.directory: %w", err)
+	}
+	for tag, limit := range q.Tag {
+		if err := limit.Validate(); err != nil {
+			return fmt.Errorf("quota.tag.%s: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+// QuotaExceeded は上限を超えたディレクトリまたはタグを表す
+type QuotaExceeded struct {
+	Scope   string   `json:"scope"`   // 上限の対象（directory, tag）
+	Name    string   `json:"name"`    // ディレクトリのパスまたはタグ名
+	Files   int      `json:"files"`   // ファイル数
+	Bytes   int64    `json:"bytes"`   // 合計サイズ
+	Reasons []string `json:"reasons"` // 超えた上限の説明
+}
+
+// CheckQuotas はディレクトリ全体とタグごとのファイル数・合計サイズを上限と比較する
+// 拡張子や期間の絞り込みに関係なく、ディレクトリ内のすべてのファイルを数える（タグはフォーマット済みファイルのみ）
+func CheckQuotas(dirPath string, quota QuotaConfig) ([]QuotaExceeded, error) {
+	return checkQuotas(OSFileSystem, dirPath, quota)
+}
+
+// checkQuotas は fsys 上のディレクトリの上限をチェックする
+func checkQuotas(fsys FileSystem, dirPath string, quota QuotaConfig) ([]QuotaExceeded, error) {
+	exceeded := []QuotaExceeded{}
+	if !quota.Configured() {
+		return exceeded, nil
+	}
+
+	entries, err := fsys.ReadDir(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	var dirFiles int
+	var dirBytes int64
+	tagFiles := make(map[string]int)
+	tagBytes := make(map[string]int64)
+	for _, entry := range entries {
+		if entry.IsDir() || isShim(fsys, dirPath, entry) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get file info: %w", err)
+		}
+
+		dirFiles++
+		dirBytes += info.Size()
+
+		components, err := parakeet.ParseFileName(entry.Name())
+		if err != nil {
+			continue
+		}
+		for _, tag := range components.Tags {
+			if _, ok := quota.Tag[tag]; ok {
+				tagFiles[tag]++
+				tagBytes[tag] += info.Size()
+			}
+		}
+	}
+
+	if e, ok := checkQuotaLimit(QuotaScopeDirectory, dirPath, dirFiles, dirBytes, quota.Directory); ok {
+		exceeded = append(exceeded, e)
+	}
+
+	tags := make([]string, 0, len(quota.Tag))
+	for tag := range quota.Tag {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		if e, ok := checkQuotaLimit(QuotaScopeTag, tag, tagFiles[tag], tagBytes[tag], quota.Tag[tag]); ok {
+			exceeded = append(exceeded, e)
+		}
+	}
+
+	return exceeded, nil
+}
+
+// checkQuotaLimit はファイル数と合計サイズが上限を超えているかどうかを返す
+func checkQuotaLimit(scope, name string, files int, bytes int64, limit QuotaLimit) (QuotaExceeded, bool) {
+	e := QuotaExceeded{Scope: scope, Name: name, Files: files, Bytes: bytes}
+	if limit.MaxFiles > 0 && files > limit.MaxFiles {
+		e.Reasons = append(e.Reasons, fmt.Sprintf("%d files (max %d)", files, limit.MaxFiles))
+	}
+	if maxBytes := limit.MaxBytes(); maxBytes > 0 && bytes > maxBytes {
+		e.Reasons = append(e.Reasons, fmt.Sprintf("%s (max %s)", FormatBytes(bytes), FormatBytes(maxBytes)))
+	}
+	return e, len(e.Reasons) > 0
+}
+
+// reportQuotas は上限を超えたディレクトリとタグを警告として出力する
+func reportQuotas(reporter Reporter, exceeded []QuotaExceeded) {
+	for _, e := range exceeded {
+		reporter.Warnf("%s %s exceeds quota: %s\n", e.Scope, e.Name, strings.Join(e.Reasons, ", "))
+	}
+}
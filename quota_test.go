@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupQuotaDir(t *testing.T) string {
+	t.Helper()
+
+	return newTestDir(t, map[string]string{
+		"20250903T083109--a__video.mp4":      strings.Repeat("x", 2048),
+		"20250903T083110--b__video_talk.mp4": strings.Repeat("x", 2048),
+		"20250903T083111--c__talk.pdf":       strings.Repeat("x", 100),
+		"unformatted.txt":                    strings.Repeat("x", 10),
+	})
+}
+
+func TestCheckQuotas(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupQuotaDir(t)
+
+	quota := QuotaConfig{
+		Directory: QuotaLimit{MaxFiles: 3},
+		Tag: map[string]QuotaLimit{
+			"video": {MaxFiles: 5, MaxSize: "3KiB"},
+			"talk":  {MaxFiles: 2},
+			"empty": {MaxFiles: 1},
+		},
+	}
+
+	exceeded, err := CheckQuotas(tmpDir, quota)
+	require.NoError(t, err)
+	assert.Equal(t, []QuotaExceeded{
+		{Scope: QuotaScopeDirectory, Name: tmpDir, Files: 4, Bytes: 4206, Reasons: []string{"4 files (max 3)"}},
+		{Scope: QuotaScopeTag, Name: "video", Files: 2, Bytes: 4096, Reasons: []string{"4.0KiB (max 3.0KiB)"}},
+	}, exceeded)
+
+	// 上限を設定していない場合はチェックしない
+	exceeded, err = CheckQuotas("/nonexistent/directory", QuotaConfig{})
+	require.NoError(t, err)
+	assert.Empty(t, exceeded)
+}
+
+func TestQuotaWarnings(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupQuotaDir(t)
+	quota := QuotaConfig{Tag: map[string]QuotaLimit{"video": {MaxFiles: 1}}}
+
+	t.Run("stats", func(t *testing.T) {
+		t.Parallel()
+		buf := &bytes.Buffer{}
+		result, err := ShowStats(tmpDir, StatsOptions{Writer: buf, Quota: quota})
+		require.NoError(t, err)
+		assert.Len(t, result.QuotaExceeded, 1)
+		assert.Contains(t, buf.String(), "tag video exceeds quota: 2 files (max 1)")
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		t.Parallel()
+		buf := &bytes.Buffer{}
+		result, err := ValidateFileNames(context.Background(), tmpDir, ValidateOptions{Writer: buf, FilterOptions: FilterOptions{Extensions: []string{"mp4"}}, Quota: quota})
+		require.NoError(t, err)
+		assert.Len(t, result.QuotaExceeded, 1)
+		// 上限を超えても警告のみ
+		assert.False(t, result.HasErrors())
+
+		output := buf.String()
+		assert.Contains(t, output, "Quota exceeded: 1")
+		assert.Contains(t, output, "tag video exceeds quota: 2 files (max 1)")
+	})
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// DefaultRelatedLimit は related で表示するファイル数のデフォルト
+const DefaultRelatedLimit = 10
+
+// RelatedOptions は関連ファイル検索操作のオプションを表す
+type RelatedOptions struct {
+	Writer io.Writer // 出力先
+	FilterOptions
+	Limit     int  // 表示するファイル数の上限（0 の場合は制限なし）
+	Proximity bool // 共通のタグの数が同じファイルは、タイムスタンプが近い順に並べる
+}
+
+// RelatedFile は関連ファイルの候補を表す
+type RelatedFile struct {
+	File     string        // ファイル名
+	ID       string        // タイムスタンプ（ID）
+	Title    string        // タイトル
+	Shared   []string      // 共通のタグ（ソート済み）
+	Distance time.Duration // 対象のファイルとのタイムスタンプの差（絶対値）
+}
+
+// FindRelatedFiles は ID で指定したファイルとタグを共有するファイルを、共通のタグが多い順に出力し、そのリストを返す
+// 共通のタグの数が同じ場合は新しい順（Proximity の場合はタイムスタンプが近い順）に並べる
+func FindRelatedFiles(targetDir, id string, opts RelatedOptions) ([]RelatedFile, error) {
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("related", "id", "title", "shared", "score", "days", "file")
+
+	filePath, err := FindFileByIDWithDepth(targetDir, id, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := parakeet.ParseFileName(filepath.Base(filePath))
+	if err != nil {
+		return nil, fmt.Errorf("file name is not in correct format: %w", err)
+	}
+	if len(base.Tags) == 0 {
+		return nil, fmt.Errorf("file has no tags: %s", filepath.Base(filePath))
+	}
+	baseTime, err := time.ParseInLocation(timestampLayout, base.Timestamp, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp: %s", base.Timestamp)
+	}
+
+	entries, err := os.ReadDir(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	baseTags := make(map[string]bool, len(base.Tags))
+	for _, tag := range base.Tags {
+		baseTags[tag] = true
+	}
+
+	related := []RelatedFile{}
+	for _, entry := range entries {
+		// ディレクトリとシムはスキップ
+		if entry.IsDir() || IsShim(targetDir, entry) {
+			continue
+		}
+
+		fileName := entry.Name()
+		if !opts.Matches(fileName) {
+			continue
+		}
+
+		components, err := parakeet.ParseFileName(fileName)
+		if err != nil || components.Timestamp == base.Timestamp {
+			continue
+		}
+
+		shared := []string{}
+		for _, tag := range components.Tags {
+			if baseTags[tag] {
+				shared = append(shared, tag)
+			}
+		}
+		if len(shared) == 0 {
+			continue
+		}
+		sort.Strings(shared)
+
+		t, err := time.ParseInLocation(timestampLayout, components.Timestamp, time.Local)
+		if err != nil {
+			continue
+		}
+
+		related = append(related, RelatedFile{
+			File:     fileName,
+			ID:       components.Timestamp,
+			Title:    components.Comment,
+			Shared:   shared,
+			Distance: t.Sub(baseTime).Abs(),
+		})
+	}
+
+	sort.SliceStable(related, func(i, j int) bool {
+		a, b := related[i], related[j]
+		if len(a.Shared) != len(b.Shared) {
+			return len(a.Shared) > len(b.Shared)
+		}
+		if opts.Proximity && a.Distance != b.Distance {
+			return a.Distance < b.Distance
+		}
+		if a.ID != b.ID {
+			return a.ID > b.ID
+		}
+		return a.File < b.File
+	})
+
+	if opts.Limit > 0 && len(related) > opts.Limit {
+		related = related[:opts.Limit]
+	}
+
+	for _, r := range related {
+		days := int(r.Distance.Hours() / 24)
+		reporter.Emit("related", map[string]any{
+			"file":   r.File,
+			"id":     r.ID,
+			"title":  r.Title,
+			"shared": r.Shared,
+			"score":  len(r.Shared),
+			"days":   days,
+		}, "%s | %s | %d shared: %s | %d days apart\n", r.ID, r.Title, len(r.Shared), strings.Join(r.Shared, ", "), days)
+	}
+
+	return related, nil
+}
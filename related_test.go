@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindRelatedFiles(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-related-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	for _, name := range []string{
+		"20250601T000000--base__go_network_book.md",
+		"20250101T000000--old__go_network.pdf",
+		"20250605T000000--near__go.md",
+		"20251201T000000--far__book.md",
+		"20250602T000000--none__cooking.md",
+		"20250603T000000--untagged.md",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("x"), 0644))
+	}
+
+	ids := func(files []RelatedFile) []string {
+		result := []string{}
+		for _, f := range files {
+			result = append(result, f.ID)
+		}
+		return result
+	}
+
+	t.Run("共通のタグが多い順、同数は新しい順", func(t *testing.T) {
+		t.Parallel()
+		buf := &bytes.Buffer{}
+		related, err := FindRelatedFiles(tmpDir, "20250601T000000", RelatedOptions{Writer: buf})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"20250101T000000", "20251201T000000", "20250605T000000"}, ids(related))
+		assert.Equal(t, []string{"go", "network"}, related[0].Shared)
+		assert.Contains(t, buf.String(), "20250101T000000 | old | 2 shared: go, network | 151 days apart\n")
+	})
+
+	t.Run("タイムスタンプが近い順", func(t *testing.T) {
+		t.Parallel()
+		related, err := FindRelatedFiles(tmpDir, "20250601T000000", RelatedOptions{Writer: &bytes.Buffer{}, Proximity: true, Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"20250101T000000", "20250605T000000"}, ids(related))
+	})
+
+	t.Run("タグのないファイル", func(t *testing.T) {
+		t.Parallel()
+		_, err := FindRelatedFiles(tmpDir, "20250603T000000", RelatedOptions{Writer: &bytes.Buffer{}})
+		assert.ErrorContains(t, err, "file has no tags")
+	})
+}
@@ -1,44 +1,149 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/kijimaD/parakeet/pkg/parakeet"
 )
 
 // RenameOptions はリネーム操作のオプションを表す
 type RenameOptions struct {
-	Writer     io.Writer // 出力先
-	Extensions []string  // 対象拡張子（空の場合は全ファイル）
+	Writer io.Writer // 出力先
+	FilterOptions
+	Profiles        []Profile                 // 拡張子ごとの処理ルール（空の場合はプロファイルなし）
+	Throttle        *Throttle                 // リネーム・stat操作の速度制限（nil の場合は制限なし）
+	DuplicatePolicy DuplicatePolicy           // タイムスタンプ重複の扱い（allow-same-basename, allow-all の場合は重複を許す）
+	TagsFile        string                    // タグ定義ファイル（空の場合は tags.toml、ResolveTagsFile で解決する）
+	FromMtime       bool                      // 現在時刻の代わりにファイルの更新日時からタイムスタンプを生成する
+	NameBudget      NameBudget                // ファイル名の長さの上限と、超えた場合の短縮方法
+	Sanitizer       parakeet.CommentSanitizer // 元のファイル名からコメントを作るときのルール
+	SkipOpen        bool                      // 他のプロセスが書き込み用に開いているファイルをスキップする（次回の実行で再試行する）
+	Shims           bool                      // リネーム後に旧ファイル名から新しいファイルへのシンボリックリンク（シム）を残す
+	Legacy          *LegacyMatcher            // 旧命名規則のファイル名から日付とタイトルを取り出す（nil の場合は取り出さない）
+	FS              FileSystem                // ファイルシステム（nil の場合は OS のファイルシステム）。SkipOpen の検出は OS のファイルシステムのみ対象
+	Resume          *Checkpoint               // 再開する実行のチェックポイント（処理済みのファイルをスキップする）
+	Atomic          bool                      // すべてのリネームを予定してからまとめて実行し、途中で失敗した場合は実行済みのリネームを元に戻す
+	Journal         *Journal                  // 実行したリネームを記録するジャーナル（nil の場合は記録しない）
+	Vault           *ObsidianVault            // リネームに合わせて wiki リンクを書き換える Obsidian vault（nil の場合は書き換えない）
+	Plan            *RenamePlan               // リネームせずに計画に追加する（nil の場合はリネームする）
+	Precision       parakeet.Precision        // タイムスタンプの精度（空の場合は秒まで）
+	Portable        bool                      // Windows で使えるファイル名にする（使えない文字を取り除き、MAX_PATH を超えないように短縮する）
+	Jobs            int                       // シムの判定と更新日時の取得を並列に行う数（1 以下の場合は並列にしない）
+	Hooks           *HookRunner               // リネームの前後に実行するフック（nil の場合は実行しない）
+	// Prompt はファイルごとにコメントとタグを決める（nil の場合は元のファイル名を整えたものをコメントにし、プロファイルのタグを付ける）
+	Prompt func(oldName string, suggested parakeet.FileNameComponents) (parakeet.FileNameComponents, error)
+	// Confirm はファイルごとにリネームしてよいかを確認する（nil の場合は確認しない）
+	Confirm func(oldName, newName string) (ConfirmAnswer, error)
 }
 
 // GenerateFileNames はディレクトリ内のすべてのファイルにフォーマット済みファイル名を生成する
-func GenerateFileNames(targetDir string, opts RenameOptions) error {
+// ctx がキャンセルされた場合は処理中のファイルを終えた時点で止め、チェックポイントを書き出す
+func GenerateFileNames(ctx context.Context, targetDir string, opts RenameOptions) error {
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("planned", "from", "to")
+	fsys := opts.Hooks.Wrap(fileSystemOrOS(opts.FS))
+
 	// ディレクトリの存在チェック
-	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+	if _, err := fsys.Stat(targetDir); os.IsNotExist(err) {
 		return fmt.Errorf("directory does not exist: %s", targetDir)
 	}
 
 	// ディレクトリを読み込む
-	entries, err := os.ReadDir(targetDir)
+	entries, err := fsys.ReadDir(targetDir)
 	if err != nil {
 		return fmt.Errorf("failed to read directory: %w", err)
 	}
 
 	// 既存のタイムスタンプを収集
-	existingTimestamps, err := CollectExistingTimestamps(targetDir)
+	existingTimestamps, err := collectExistingTimestamps(fsys, targetDir)
 	if err != nil {
 		return fmt.Errorf("failed to collect existing timestamps: %w", err)
 	}
 
+	// プロファイルのデフォルトタグと移動先を準備（計画のみの場合は移動先を作成しない）
+	if err := prepareProfiles(fsys, targetDir, opts.Profiles, opts.TagsFile, existingTimestamps, opts.Plan == nil); err != nil {
+		return err
+	}
+
+	// 書き込み中のファイル（ダウンロード中・書き出し中など）を検出する
+	var openFiles map[string]bool
+	if opts.SkipOpen {
+		openFiles, err = FilesOpenForWriting(targetDir)
+		if errors.Is(err, ErrOpenCheckUnsupported) {
+			reporter.Warnf("%v, open files are not skipped\n", err)
+		} else if err != nil {
+			return err
+		}
+	}
+
+	// 拡張子以外が同じファイルに割り当てたタイムスタンプ（重複を許すポリシーの場合のみ使う）
+	basenameTimestamps := make(map[string]string)
+
 	processedCount := 0
 	skippedCount := 0
 
-	for _, entry := range entries {
-		// ディレクトリはスキップ
-		if entry.IsDir() {
+	// チェックポイントに記録する処理済みのファイル名
+	processed := []string{}
+
+	// リネームの完了を報告し、旧ファイル名への参照が解決できるようにシムを残す（失敗してもリネームは取り消さない）
+	renamed := func(oldPath, newPath string) {
+		oldName, newName := filepath.Base(oldPath), filepath.Base(newPath)
+		reporter.Verbosef("Renamed: %s → %s\n", oldName, newName)
+		processedCount++
+		processed = append(processed, oldName)
+
+		// プロファイルの移動先はディレクトリからの相対パスで記録する
+		to, err := filepath.Rel(targetDir, newPath)
+		if err != nil {
+			to = newName
+		}
+		if err := opts.Journal.Record(targetDir, renamePlan{From: oldName, To: to}); err != nil {
+			reporter.Warnf("%s (%v)\n", oldName, err)
+		}
+		if err := opts.Vault.RewriteLinks(targetDir, renamePlan{From: oldName, To: to}); err != nil {
+			reporter.Warnf("%s (%v)\n", oldName, err)
+		}
+
+		if opts.Shims {
+			if err := createShim(fsys, oldPath, newPath); err != nil {
+				reporter.Warnf("%s (%v)\n", oldName, err)
+			}
+		}
+	}
+
+	// --atomic の場合にまとめて実行するリネーム
+	tx := newRenameTransaction(fsys)
+	tx.throttle = opts.Throttle
+
+	// 確認で a と答えた後は残りのファイルを確認しない
+	confirm := opts.Confirm
+
+	// エントリごとの stat は大きなディレクトリで時間がかかるため、先に並列で済ませる
+	// タイムスタンプの割り当てとリネームはエントリの順に行い、結果が並列数によらないようにする
+	facts := parallelMap(opts.Jobs, entries, func(entry os.DirEntry) generateEntry {
+		if ctx.Err() != nil {
+			return generateEntry{}
+		}
+		return inspectGenerateEntry(fsys, targetDir, entry, opts)
+	})
+
+	for i, entry := range entries {
+		// キャンセルされた場合は、処理中のファイルを終えた時点で止める
+		if ctx.Err() != nil {
+			break
+		}
+
+		// ディレクトリと以前のリネームで残したシムはスキップ
+		if facts[i].Skip {
 			continue
 		}
 
@@ -46,12 +151,33 @@ func GenerateFileNames(targetDir string, opts RenameOptions) error {
 		oldPath := filepath.Join(targetDir, oldName)
 
 		// 拡張子フィルタリング
-		if !MatchesExtensions(oldName, opts.Extensions) {
+		// 拡張子指定がなくプロファイルがある場合は、いずれかのプロファイルに一致するファイルのみ対象
+		profile := FindProfile(opts.Profiles, oldName)
+		if len(opts.Extensions) == 0 && len(opts.Profiles) > 0 {
+			if profile == nil || opts.Excludes(oldName) {
+				continue
+			}
+		} else if !opts.Matches(oldName) {
 			continue
 		}
 
 		// すでにフォーマット済みの場合はスキップ
-		if IsFormatted(oldName) {
+		if parakeet.IsFormatted(oldName) {
+			reporter.Verbosef("%s (already formatted, skipped)\n", oldName)
+			skippedCount++
+			continue
+		}
+
+		// 中断した実行で処理済みのファイルはスキップ
+		if opts.Resume.Done(oldName) {
+			reporter.Verbosef("%s (processed before interruption, skipped)\n", oldName)
+			skippedCount++
+			continue
+		}
+
+		// 書き込み中のファイルはリネームせず、次回の実行に回す
+		if openFiles[oldName] {
+			reporter.Warnf("%s (open for writing by another process, skipped)\n", oldName)
 			skippedCount++
 			continue
 		}
@@ -64,42 +190,296 @@ func GenerateFileNames(targetDir string, opts RenameOptions) error {
 		}
 
 		// 重複しないタイムスタンプを生成
-		timestamp := GenerateUniqueTimestamp(existingTimestamps)
+		// --from-mtime またはプロファイルの抽出方法が mtime の場合は更新日時を基準にする
+		base := time.Now()
+		if !facts[i].ModTime.IsZero() {
+			base = facts[i].ModTime
+		}
+
+		// 旧命名規則に一致する場合はファイル名の日付とタイトルを使う
+		title := baseName
+		if match, ok := opts.Legacy.Match(baseName); ok {
+			title = match.Title
+			if !match.Time.IsZero() {
+				base = match.Time
+			}
+			reporter.Verbosef("%s (legacy name: %s)\n", oldName, strings.Join(match.Recognizers, ", "))
+		}
+		timestamp := generateTimestamp(base, baseName, existingTimestamps, basenameTimestamps, opts.DuplicatePolicy, opts.Precision)
 
 		// タイムスタンプ付きの新しいファイル名を作成
-		components := FileNameComponents{
+		components := parakeet.FileNameComponents{
 			Timestamp: timestamp,
-			Comment:   baseName,
+			Comment:   opts.Sanitizer.Sanitize(title),
 			Tags:      []string{}, // デフォルトではタグなし
 			Extension: ext,
 		}
 
-		newName := components.FormatFileName()
-		newPath := filepath.Join(targetDir, newName)
+		newDir := targetDir
+		if profile != nil {
+			components.Tags = append([]string{}, profile.Tags...)
+			sort.Strings(components.Tags)
+			newDir = filepath.Join(targetDir, profile.Dest)
+		}
+
+		// インタラクティブモードではコメントとタグを入力してもらう
+		if opts.Prompt != nil {
+			components, err = opts.Prompt(oldName, components)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Windows で使えるファイル名にする（タグと拡張子の使えない文字を取り除き、パスが MAX_PATH を超えないように短縮する）
+		budget := opts.NameBudget
+		if opts.Portable {
+			components = PortableComponents(components)
+			if absDir, err := fsys.Abs(newDir); err == nil {
+				budget.MaxBytes = portableNameLimit(absDir, budget.MaxBytes)
+			}
+		}
+
+		// ファイル名が長さの上限を超える場合は短縮し、短縮した内容を報告する
+		trim, err := budget.Fit(components)
+		if errors.Is(err, ErrNameTooLong) {
+			reporter.Errorf("%s (%v)\n", oldName, err)
+			skippedCount++
+			processed = append(processed, oldName)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		components = trim.Components
+		for _, trimmed := range trim.Trimmed {
+			reporter.Warnf("%s: %s\n", oldName, trimmed)
+		}
+
+		newName, err := components.FormatFileNameStrict()
+		if err != nil {
+			reporter.Errorf("%s (%v)\n", oldName, err)
+			skippedCount++
+			processed = append(processed, oldName)
+			continue
+		}
+		if opts.Portable {
+			if problem := windowsNameProblem(newName); problem != "" {
+				reporter.Errorf("%s (%s)\n", oldName, problem)
+				skippedCount++
+				processed = append(processed, oldName)
+				continue
+			}
+		}
+		newPath := filepath.Join(newDir, newName)
 
 		// 使用したタイムスタンプを記録
 		existingTimestamps[timestamp] = true
 
 		// 新しいファイル名がすでに存在するかチェック
-		if _, err := os.Stat(newPath); err == nil {
-			_, _ = fmt.Fprintf(opts.Writer, "Warning: target file already exists, skipping: %s\n", newName)
+		opts.Throttle.Wait()
+		if _, err := fsys.Stat(newPath); err == nil {
+			reporter.Warnf("target file already exists, skipping: %s\n", newName)
 			skippedCount++
+			processed = append(processed, oldName)
+			continue
+		}
+
+		// ファイルごとにリネームしてよいかを確認する
+		if confirm != nil {
+			answer, err := confirm(oldName, newName)
+			if err != nil {
+				return err
+			}
+			if answer == ConfirmQuit {
+				break
+			}
+			if answer == ConfirmNo {
+				delete(existingTimestamps, timestamp)
+				reporter.Verbosef("Skipped: %s\n", oldName)
+				skippedCount++
+				processed = append(processed, oldName)
+				continue
+			}
+			if answer == ConfirmAll {
+				confirm = nil
+			}
+		}
+
+		// --plan ではリネームせずに計画に追加する
+		if opts.Plan != nil {
+			info, err := fsys.Stat(oldPath)
+			if err != nil {
+				reporter.Errorf("%s (%v)\n", oldName, err)
+				continue
+			}
+			to, err := filepath.Rel(targetDir, newPath)
+			if err != nil {
+				to = newName
+			}
+			opts.Plan.Add(oldName, to, info)
+			reporter.Emit("planned", map[string]any{"from": oldName, "to": to}, "Planned: %s → %s\n", oldName, to)
+			processedCount++
+			continue
+		}
+
+		// --atomic ではすべてのファイルを確認してからまとめてリネームする
+		if opts.Atomic {
+			tx.Add(oldPath, newPath)
 			continue
 		}
 
 		// ファイルをリネーム
-		if err := os.Rename(oldPath, newPath); err != nil {
-			_, _ = fmt.Fprintf(opts.Writer, "Error renaming %s: %v\n", oldName, err)
+		opts.Throttle.Wait()
+		if err := fsys.Rename(oldPath, newPath); err != nil {
+			reporter.Errorf("%s (rename failed: %v)\n", oldName, err)
 			continue
 		}
+		renamed(oldPath, newPath)
+	}
 
-		processedCount++
+	if opts.Atomic {
+		// 予定の途中でキャンセルされた場合は1つもリネームしない
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("%w: %w: no files were renamed", ErrInterrupted, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		for _, step := range tx.steps {
+			renamed(step.From, step.To)
+		}
 	}
 
 	// サマリーを出力
-	_, _ = fmt.Fprintf(opts.Writer, "\nSummary:\n")
-	_, _ = fmt.Fprintf(opts.Writer, "  Processed: %d\n", processedCount)
-	_, _ = fmt.Fprintf(opts.Writer, "  Skipped: %d\n", skippedCount)
+	reporter.Printf("\nSummary:\n")
+	reporter.Printf("  Processed: %d\n", processedCount)
+	reporter.Printf("  Skipped: %d\n", skippedCount)
+
+	if err := ctx.Err(); err != nil {
+		return interruptRun(err, targetDir, "generate", opts.Resume, processed, opts.Plan != nil)
+	}
+	return finishRun(targetDir, "generate", opts.Plan != nil)
+}
+
+// generateEntry は generate でリネームの前に調べたエントリの情報を表す
+type generateEntry struct {
+	Skip    bool      // ディレクトリまたはシムのため対象外
+	ModTime time.Time // タイムスタンプの基準にする更新日時（更新日時を使わない場合と取得できない場合はゼロ値）
+}
+
+// inspectGenerateEntry はエントリが対象外かどうかと、必要な場合は更新日時を調べる
+// parallelMap から並列に呼ばれるため、opts と fsys は読み込みのみ行う
+func inspectGenerateEntry(fsys FileSystem, targetDir string, entry os.DirEntry, opts RenameOptions) generateEntry {
+	if entry.IsDir() || isShim(fsys, targetDir, entry) {
+		return generateEntry{Skip: true}
+	}
+
+	// --from-mtime またはプロファイルの抽出方法が mtime の場合のみ更新日時を取得する
+	profile := FindProfile(opts.Profiles, entry.Name())
+	if !opts.FromMtime && (profile == nil || profile.Extractor != ExtractorMtime) {
+		return generateEntry{}
+	}
+	info, err := entry.Info()
+	if err != nil {
+		return generateEntry{}
+	}
+	return generateEntry{ModTime: info.ModTime()}
+}
+
+// NewGeneratePrompt は generate のインタラクティブモードで使う入力関数を作成する
+// コメントは元のファイル名を整えたものを初期値として入力し、タグは tags.toml の定義から選ぶ
+// 入力したコメントも sanitizer で整える
+func NewGeneratePrompt(targetDir, tagsFile string, sanitizer parakeet.CommentSanitizer) (func(string, parakeet.FileNameComponents) (parakeet.FileNameComponents, error), error) {
+	tomlPath := ResolveTagsFile(targetDir, tagsFile)
+	validator, err := NewTagValidator(tomlPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(oldName string, suggested parakeet.FileNameComponents) (parakeet.FileNameComponents, error) {
+		prompt := &survey.Input{
+			Message: fmt.Sprintf("Comment for %s:", oldName),
+			Default: suggested.Comment,
+		}
+
+		var comment string
+		if err := survey.AskOne(prompt, &comment); err != nil {
+			return parakeet.FileNameComponents{}, err
+		}
+		suggested.Comment = sanitizer.Sanitize(comment)
+
+		tags, err := promptForTags(suggested.Tags, tomlPath, validator)
+		if err != nil {
+			return parakeet.FileNameComponents{}, fmt.Errorf("failed to get tags: %w", err)
+		}
+		if err := validator.Validate(tags); err != nil {
+			return parakeet.FileNameComponents{}, err
+		}
+		suggested.Tags = tags
+
+		return suggested, nil
+	}, nil
+}
+
+// generateTimestamp はタイムスタンプ重複の扱いに従って新しいファイルのタイムスタンプを決める
+// allow-same-basename と allow-all では拡張子以外が同じファイルに同じタイムスタンプを割り当て、
+// allow-all では既存のファイルとの重複も避けない
+func generateTimestamp(base time.Time, baseName string, existingTimestamps map[string]bool, basenameTimestamps map[string]string, policy DuplicatePolicy, precision parakeet.Precision) string {
+	if policy != DuplicatePolicyAllowSameBasename && policy != DuplicatePolicyAllowAll {
+		return parakeet.GenerateUniqueTimestampWithPrecision(base, existingTimestamps, precision)
+	}
+
+	if timestamp, ok := basenameTimestamps[baseName]; ok {
+		return timestamp
+	}
+
+	timestamp := precision.Format(base)
+	if policy == DuplicatePolicyAllowSameBasename {
+		timestamp = parakeet.GenerateUniqueTimestampWithPrecision(base, existingTimestamps, precision)
+	}
+	basenameTimestamps[baseName] = timestamp
+	return timestamp
+}
+
+// prepareProfiles はプロファイルのデフォルトタグを検証し、create の場合は移動先ディレクトリを作成する
+// 移動先に既にあるタイムスタンプは existingTimestamps に追加する
+func prepareProfiles(fsys FileSystem, targetDir string, profiles []Profile, tagsFile string, existingTimestamps map[string]bool, create bool) error {
+	if len(profiles) == 0 {
+		return nil
+	}
+
+	validator, err := newTagValidator(fsys, resolveTagsFile(fsys, targetDir, tagsFile))
+	if err != nil {
+		return err
+	}
+
+	for _, profile := range profiles {
+		if err := validator.Validate(profile.Tags); err != nil {
+			return fmt.Errorf("invalid tags in profile %s: %w", profile.Name, err)
+		}
+
+		if profile.Dest == "" {
+			continue
+		}
+
+		destDir := filepath.Join(targetDir, profile.Dest)
+		if create {
+			if err := fsys.MkdirAll(destDir, 0755); err != nil {
+				return fmt.Errorf("failed to create directory: %w", err)
+			}
+		} else if _, err := fsys.Stat(destDir); os.IsNotExist(err) {
+			// まだない移動先には既存のタイムスタンプもない
+			continue
+		}
+
+		timestamps, err := collectExistingTimestamps(fsys, destDir)
+		if err != nil {
+			return fmt.Errorf("failed to collect existing timestamps: %w", err)
+		}
+		for timestamp := range timestamps {
+			existingTimestamps[timestamp] = true
+		}
+	}
 
 	return nil
 }
@@ -2,11 +2,16 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/kijimaD/parakeet/pkg/parakeet"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -29,8 +34,8 @@ func TestGenerateFileNames(t *testing.T) {
 				"document.doc",
 			},
 			opts: RenameOptions{
-				Writer:     &bytes.Buffer{},
-				Extensions: []string{"txt", "pdf", "doc"},
+				Writer:        &bytes.Buffer{},
+				FilterOptions: FilterOptions{Extensions: []string{"txt", "pdf", "doc"}},
 			},
 			expectError:   false,
 			expectRenamed: 3,
@@ -43,8 +48,8 @@ func TestGenerateFileNames(t *testing.T) {
 				"20250903T083109--already-formatted__tag1.pdf",
 			},
 			opts: RenameOptions{
-				Writer:     &bytes.Buffer{},
-				Extensions: []string{"txt", "pdf"},
+				Writer:        &bytes.Buffer{},
+				FilterOptions: FilterOptions{Extensions: []string{"txt", "pdf"}},
 			},
 			expectError:   false,
 			expectRenamed: 1,
@@ -59,8 +64,8 @@ func TestGenerateFileNames(t *testing.T) {
 				"noextension",
 			},
 			opts: RenameOptions{
-				Writer:     &bytes.Buffer{},
-				Extensions: []string{"jpg", "png", "md", ""},
+				Writer:        &bytes.Buffer{},
+				FilterOptions: FilterOptions{Extensions: []string{"jpg", "png", "md", ""}},
 			},
 			expectError:   false,
 			expectRenamed: 4,
@@ -84,7 +89,7 @@ func TestGenerateFileNames(t *testing.T) {
 			}
 
 			// Run the function
-			err = GenerateFileNames(tmpDir, tt.opts)
+			err = GenerateFileNames(context.Background(), tmpDir, tt.opts)
 
 			// Check error expectation
 			if tt.expectError {
@@ -104,11 +109,11 @@ func TestGenerateFileNames(t *testing.T) {
 				}
 
 				// Check if file is formatted (should be for renamed files)
-				if IsFormatted(entry.Name()) {
+				if parakeet.IsFormatted(entry.Name()) {
 					// Verify the file wasn't already formatted in setup
 					alreadyFormatted := false
 					for _, setupFile := range tt.setupFiles {
-						if setupFile == entry.Name() && IsFormatted(setupFile) {
+						if setupFile == entry.Name() && parakeet.IsFormatted(setupFile) {
 							alreadyFormatted = true
 							break
 						}
@@ -127,11 +132,11 @@ func TestGenerateFileNames(t *testing.T) {
 func TestGenerateFileNames_NonExistentDirectory(t *testing.T) {
 	t.Parallel()
 	opts := RenameOptions{
-		Writer:     &bytes.Buffer{},
-		Extensions: []string{"txt"},
+		Writer:        &bytes.Buffer{},
+		FilterOptions: FilterOptions{Extensions: []string{"txt"}},
 	}
 
-	err := GenerateFileNames("/non/existent/directory", opts)
+	err := GenerateFileNames(context.Background(), "/non/existent/directory", opts)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "directory does not exist")
 }
@@ -144,11 +149,11 @@ func TestGenerateFileNames_EmptyDirectory(t *testing.T) {
 	defer func() { _ = os.RemoveAll(tmpDir) }()
 
 	opts := RenameOptions{
-		Writer:     &bytes.Buffer{},
-		Extensions: []string{"txt"},
+		Writer:        &bytes.Buffer{},
+		FilterOptions: FilterOptions{Extensions: []string{"txt"}},
 	}
 
-	err = GenerateFileNames(tmpDir, opts)
+	err = GenerateFileNames(context.Background(), tmpDir, opts)
 	assert.NoError(t, err)
 
 	// Verify directory is still empty
@@ -175,11 +180,11 @@ func TestGenerateFileNames_SkipsDirectories(t *testing.T) {
 	require.NoError(t, err)
 
 	opts := RenameOptions{
-		Writer:     &bytes.Buffer{},
-		Extensions: []string{"txt"},
+		Writer:        &bytes.Buffer{},
+		FilterOptions: FilterOptions{Extensions: []string{"txt"}},
 	}
 
-	err = GenerateFileNames(tmpDir, opts)
+	err = GenerateFileNames(context.Background(), tmpDir, opts)
 	assert.NoError(t, err)
 
 	// Verify subdirectory still exists with original name
@@ -198,7 +203,7 @@ func TestGenerateFileNames_SkipsDirectories(t *testing.T) {
 			assert.Equal(t, "subdir", entry.Name(), "Directory name should not change")
 		} else {
 			fileCount++
-			assert.True(t, IsFormatted(entry.Name()), "File should be formatted")
+			assert.True(t, parakeet.IsFormatted(entry.Name()), "File should be formatted")
 		}
 	}
 
@@ -230,11 +235,11 @@ func TestGenerateFileNames_PreservesExtension(t *testing.T) {
 	}
 
 	opts := RenameOptions{
-		Writer:     &bytes.Buffer{},
-		Extensions: []string{"pdf", "docx", "jpg", ""},
+		Writer:        &bytes.Buffer{},
+		FilterOptions: FilterOptions{Extensions: []string{"pdf", "docx", "jpg", ""}},
 	}
 
-	err = GenerateFileNames(tmpDir, opts)
+	err = GenerateFileNames(context.Background(), tmpDir, opts)
 	require.NoError(t, err)
 
 	// Verify extensions are preserved
@@ -287,11 +292,11 @@ func TestGenerateFileNames_WithExtensionFilter(t *testing.T) {
 	// Test with extension filter
 	buf := &bytes.Buffer{}
 	opts := RenameOptions{
-		Writer:     buf,
-		Extensions: []string{"txt", "pdf"},
+		Writer:        buf,
+		FilterOptions: FilterOptions{Extensions: []string{"txt", "pdf"}},
 	}
 
-	err = GenerateFileNames(tmpDir, opts)
+	err = GenerateFileNames(context.Background(), tmpDir, opts)
 	require.NoError(t, err)
 
 	// Verify only txt and pdf files were renamed
@@ -300,7 +305,7 @@ func TestGenerateFileNames_WithExtensionFilter(t *testing.T) {
 
 	renamedCount := 0
 	for _, entry := range entries {
-		if IsFormatted(entry.Name()) {
+		if parakeet.IsFormatted(entry.Name()) {
 			renamedCount++
 			ext := filepath.Ext(entry.Name())
 			if ext != "" {
@@ -341,11 +346,11 @@ func TestGenerateFileNames_ActualRename(t *testing.T) {
 	// Perform actual rename
 	buf := &bytes.Buffer{}
 	opts := RenameOptions{
-		Writer:     buf,
-		Extensions: []string{"pdf", "jpg", "txt"},
+		Writer:        buf,
+		FilterOptions: FilterOptions{Extensions: []string{"pdf", "jpg", "txt"}},
 	}
 
-	err = GenerateFileNames(tmpDir, opts)
+	err = GenerateFileNames(context.Background(), tmpDir, opts)
 	require.NoError(t, err)
 
 	// Verify files were actually renamed
@@ -355,7 +360,7 @@ func TestGenerateFileNames_ActualRename(t *testing.T) {
 
 	// Check all files are now formatted
 	for _, entry := range entries {
-		assert.True(t, IsFormatted(entry.Name()), "File %s should be formatted", entry.Name())
+		assert.True(t, parakeet.IsFormatted(entry.Name()), "File %s should be formatted", entry.Name())
 	}
 
 	// Verify file contents were preserved
@@ -390,11 +395,11 @@ func TestGenerateFileNames_NoDuplicateTimestamps(t *testing.T) {
 	// Perform rename
 	buf := &bytes.Buffer{}
 	opts := RenameOptions{
-		Writer:     buf,
-		Extensions: []string{"txt"},
+		Writer:        buf,
+		FilterOptions: FilterOptions{Extensions: []string{"txt"}},
 	}
 
-	err = GenerateFileNames(tmpDir, opts)
+	err = GenerateFileNames(context.Background(), tmpDir, opts)
 	require.NoError(t, err)
 
 	// Collect all timestamps
@@ -410,7 +415,85 @@ func TestGenerateFileNames_NoDuplicateTimestamps(t *testing.T) {
 	assert.Len(t, entries, numFiles)
 
 	for _, entry := range entries {
-		assert.True(t, IsFormatted(entry.Name()), "File should be formatted")
+		assert.True(t, parakeet.IsFormatted(entry.Name()), "File should be formatted")
+	}
+}
+
+func TestGenerateFileNames_DuplicatePolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		policy     DuplicatePolicy
+		timestamps int
+	}{
+		{policy: DuplicatePolicyError, timestamps: 3},
+		{policy: DuplicatePolicyAllowSameBasename, timestamps: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			t.Parallel()
+			tmpDir := t.TempDir()
+			for _, name := range []string{"report.md", "report.pdf", "memo.md"} {
+				require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("test content"), 0644))
+			}
+
+			err := GenerateFileNames(context.Background(), tmpDir, RenameOptions{
+				Writer:          &bytes.Buffer{},
+				FilterOptions:   FilterOptions{Extensions: []string{"md", "pdf"}},
+				DuplicatePolicy: tt.policy,
+			})
+			require.NoError(t, err)
+
+			timestamps, err := CollectExistingTimestamps(tmpDir)
+			require.NoError(t, err)
+			assert.Len(t, timestamps, tt.timestamps)
+
+			// 重複を許す場合も拡張子以外が同じファイルのみタイムスタンプを共有する
+			validation, err := ValidateFileNames(context.Background(), tmpDir, ValidateOptions{Writer: &bytes.Buffer{}, DuplicatePolicy: DuplicatePolicyAllowSameBasename})
+			require.NoError(t, err)
+			assert.False(t, validation.HasErrors())
+		})
+	}
+}
+
+func TestGenerateFileNames_OrderedTimestamps(t *testing.T) {
+	t.Parallel()
+	// Create temporary directory
+	tmpDir, err := os.MkdirTemp("", "parakeet-test-ordered-*")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	// Create files that will be renamed within the same second
+	numFiles := 20
+	for i := 0; i < numFiles; i++ {
+		fileName := fmt.Sprintf("file%02d.txt", i)
+		err := os.WriteFile(filepath.Join(tmpDir, fileName), []byte("test content"), 0644)
+		require.NoError(t, err)
+	}
+
+	err = GenerateFileNames(context.Background(), tmpDir, RenameOptions{
+		Writer:        &bytes.Buffer{},
+		FilterOptions: FilterOptions{Extensions: []string{"txt"}},
+	})
+	require.NoError(t, err)
+
+	// コメント（元のファイル名）の順にIDが単調増加していることを確認
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, entries, numFiles)
+
+	idByComment := make(map[string]string)
+	for _, entry := range entries {
+		components, err := parakeet.ParseFileName(entry.Name())
+		require.NoError(t, err)
+		idByComment[components.Comment] = components.Timestamp
+	}
+
+	for i := 1; i < numFiles; i++ {
+		prev := idByComment[fmt.Sprintf("file%02d", i-1)]
+		curr := idByComment[fmt.Sprintf("file%02d", i)]
+		assert.Less(t, prev, curr, "IDs should be unique and ordered")
 	}
 }
 
@@ -448,11 +531,11 @@ func TestGenerateFileNames_WithExistingFormattedFiles(t *testing.T) {
 	// Perform rename
 	buf := &bytes.Buffer{}
 	opts := RenameOptions{
-		Writer:     buf,
-		Extensions: []string{"txt", "pdf"},
+		Writer:        buf,
+		FilterOptions: FilterOptions{Extensions: []string{"txt", "pdf"}},
 	}
 
-	err = GenerateFileNames(tmpDir, opts)
+	err = GenerateFileNames(context.Background(), tmpDir, opts)
 	require.NoError(t, err)
 
 	// Collect all timestamps
@@ -468,3 +551,320 @@ func TestGenerateFileNames_WithExistingFormattedFiles(t *testing.T) {
 		assert.NoError(t, err, "Existing file should still exist: %s", existingFile)
 	}
 }
+
+func TestGenerateFileNames_FromMtime(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-test-mtime-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	// 同じ更新日時のファイルは1秒ずつずらす
+	mtime := time.Date(2019, 5, 6, 7, 8, 9, 0, time.Local)
+	for _, name := range []string{"a.pdf", "b.pdf"} {
+		path := filepath.Join(tmpDir, name)
+		require.NoError(t, os.WriteFile(path, []byte("test content"), 0644))
+		require.NoError(t, os.Chtimes(path, mtime, mtime))
+	}
+
+	opts := RenameOptions{
+		Writer:        &bytes.Buffer{},
+		FilterOptions: FilterOptions{Extensions: []string{"pdf"}},
+		FromMtime:     true,
+	}
+	require.NoError(t, GenerateFileNames(context.Background(), tmpDir, opts))
+
+	assert.FileExists(t, filepath.Join(tmpDir, "20190506T070809--a.pdf"))
+	assert.FileExists(t, filepath.Join(tmpDir, "20190506T070810--b.pdf"))
+}
+
+func TestGenerateFileNames_NameBudget(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-test-budget-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	for _, name := range []string{"a-very-long-downloaded-file-name.pdf", "x.pdf"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("test content"), 0644))
+	}
+
+	buf := &bytes.Buffer{}
+	opts := RenameOptions{
+		Writer:        buf,
+		FilterOptions: FilterOptions{Extensions: []string{"pdf"}},
+		NameBudget:    NameBudget{MaxBytes: 32},
+	}
+	require.NoError(t, GenerateFileNames(context.Background(), tmpDir, opts))
+
+	// 長いコメントは上限に収まるように切り詰め、短縮した内容を報告する
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+		assert.LessOrEqual(t, len(entry.Name()), 32)
+	}
+	require.Len(t, names, 2)
+	assert.Contains(t, names[0]+names[1], "--a-very-long.pdf")
+	assert.Contains(t, buf.String(), "comment truncated: a-very-long-downloaded-file-name → a-very-long")
+
+	// error ポリシーでは短縮せずにスキップする
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "another-long-file-name.pdf"), []byte("test content"), 0644))
+	buf.Reset()
+	opts.NameBudget.Policy = NameBudgetError
+	require.NoError(t, GenerateFileNames(context.Background(), tmpDir, opts))
+	assert.FileExists(t, filepath.Join(tmpDir, "another-long-file-name.pdf"))
+	assert.Contains(t, buf.String(), "file name too long")
+}
+
+func TestGenerateFileNames_Precision(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-test-precision-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	// 同じ時刻に更新した多数のファイルも、同じ秒のタイムスタンプにする
+	mtime := time.Date(2025, 9, 3, 8, 31, 9, 100*int(time.Millisecond), time.Local)
+	for i := range 50 {
+		path := filepath.Join(tmpDir, fmt.Sprintf("scan%02d.pdf", i))
+		require.NoError(t, os.WriteFile(path, []byte("test content"), 0644))
+		require.NoError(t, os.Chtimes(path, mtime, mtime))
+	}
+
+	opts := RenameOptions{
+		Writer:        &bytes.Buffer{},
+		FilterOptions: FilterOptions{Extensions: []string{"pdf"}},
+		FromMtime:     true,
+		Precision:     parakeet.PrecisionMillisecond,
+	}
+	require.NoError(t, GenerateFileNames(context.Background(), tmpDir, opts))
+
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 50)
+	for _, entry := range entries {
+		assert.True(t, strings.HasPrefix(entry.Name(), "20250903T083109,1"), entry.Name())
+		assert.NoError(t, parakeet.ValidateFileName(entry.Name()))
+	}
+}
+
+func TestGenerateFileNames_Sanitizer(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-test-sanitize-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "Annual Report__2025--draft.pdf"), []byte("test content"), 0644))
+
+	opts := RenameOptions{
+		Writer:        &bytes.Buffer{},
+		FilterOptions: FilterOptions{Extensions: []string{"pdf"}},
+		Sanitizer:     parakeet.CommentSanitizer{Space: "-", Underscore: "-", Lowercase: true},
+	}
+	require.NoError(t, GenerateFileNames(context.Background(), tmpDir, opts))
+
+	// 区切りと誤認される部分を含まないコメントになり、タグとして解釈されない
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	components, err := parakeet.ParseFileName(entries[0].Name())
+	require.NoError(t, err)
+	assert.Equal(t, "annual-report-2025-draft", components.Comment)
+	assert.Empty(t, components.Tags)
+}
+
+func TestGenerateFileNames_Prompt(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-test-prompt-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	for _, name := range []string{"scan_0001.pdf", "scan_0002.pdf"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("test content"), 0644))
+	}
+
+	// ファイルごとに入力したコメントとタグでリネームする
+	answers := map[string]parakeet.FileNameComponents{
+		"scan_0001.pdf": {Comment: "TCPIP入門", Tags: []string{"network"}},
+		"scan_0002.pdf": {Comment: "Go入門", Tags: []string{"go", "network"}},
+	}
+	var prompted []string
+	opts := RenameOptions{
+		Writer:        &bytes.Buffer{},
+		FilterOptions: FilterOptions{Extensions: []string{"pdf"}},
+		Prompt: func(oldName string, suggested parakeet.FileNameComponents) (parakeet.FileNameComponents, error) {
+			prompted = append(prompted, oldName)
+			assert.Equal(t, strings.TrimSuffix(oldName, ".pdf"), suggested.Comment)
+			suggested.Comment = answers[oldName].Comment
+			suggested.Tags = answers[oldName].Tags
+			return suggested, nil
+		},
+	}
+	require.NoError(t, GenerateFileNames(context.Background(), tmpDir, opts))
+	assert.Equal(t, []string{"scan_0001.pdf", "scan_0002.pdf"}, prompted)
+
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	for _, entry := range entries {
+		components, err := parakeet.ParseFileName(entry.Name())
+		require.NoError(t, err)
+		assert.Contains(t, []string{"TCPIP入門", "Go入門"}, components.Comment)
+		assert.Contains(t, components.Tags, "network")
+	}
+
+	// 入力を中断した場合はそこで終了する
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "scan_0003.pdf"), []byte("test content"), 0644))
+	opts.Prompt = func(string, parakeet.FileNameComponents) (parakeet.FileNameComponents, error) {
+		return parakeet.FileNameComponents{}, errors.New("interrupted")
+	}
+	assert.Error(t, GenerateFileNames(context.Background(), tmpDir, opts))
+	assert.FileExists(t, filepath.Join(tmpDir, "scan_0003.pdf"))
+}
+
+func TestGenerateFileNames_Confirm(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		answers  []ConfirmAnswer
+		atomic   bool
+		asked    int
+		expected []string // リネームされずに残るファイル
+	}{
+		{"ファイルごとに確認", []ConfirmAnswer{ConfirmYes, ConfirmNo, ConfirmYes, ConfirmNo}, false, 4, []string{"b.pdf", "d.pdf"}},
+		{"残りをすべてリネーム", []ConfirmAnswer{ConfirmNo, ConfirmAll}, false, 2, []string{"a.pdf"}},
+		{"途中で終了", []ConfirmAnswer{ConfirmYes, ConfirmQuit}, false, 2, []string{"b.pdf", "c.pdf", "d.pdf"}},
+		{"atomic で途中で終了", []ConfirmAnswer{ConfirmNo, ConfirmYes, ConfirmQuit}, true, 3, []string{"a.pdf", "c.pdf", "d.pdf"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir, err := os.MkdirTemp("", "parakeet-test-confirm-*")
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+			for _, name := range []string{"a.pdf", "b.pdf", "c.pdf", "d.pdf"} {
+				require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("test content"), 0644))
+			}
+
+			asked := 0
+			opts := RenameOptions{
+				Writer: &bytes.Buffer{},
+				Atomic: tt.atomic,
+				Confirm: func(oldName, newName string) (ConfirmAnswer, error) {
+					assert.True(t, strings.HasSuffix(newName, "--"+oldName))
+					answer := tt.answers[asked]
+					asked++
+					return answer, nil
+				},
+			}
+			require.NoError(t, GenerateFileNames(context.Background(), tmpDir, opts))
+			assert.Equal(t, tt.asked, asked)
+
+			remaining := []string{}
+			entries, err := os.ReadDir(tmpDir)
+			require.NoError(t, err)
+			for _, entry := range entries {
+				if !entry.IsDir() && !parakeet.IsFormatted(entry.Name()) {
+					remaining = append(remaining, entry.Name())
+				}
+			}
+			assert.Equal(t, tt.expected, remaining)
+		})
+	}
+}
+
+func TestGenerateFileNames_WithProfiles(t *testing.T) {
+	t.Parallel()
+	// Create temporary directory
+	tmpDir, err := os.MkdirTemp("", "parakeet-test-profiles-*")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for _, name := range []string{"photo.jpg", "notes.md", "document.pdf"} {
+		err := os.WriteFile(filepath.Join(tmpDir, name), []byte("test content"), 0644)
+		require.NoError(t, err)
+	}
+
+	// 写真の更新日時を過去に設定
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local)
+	require.NoError(t, os.Chtimes(filepath.Join(tmpDir, "photo.jpg"), mtime, mtime))
+
+	opts := RenameOptions{
+		Writer: &bytes.Buffer{},
+		Profiles: []Profile{
+			{Name: "images", Extensions: []string{"jpg"}, Extractor: ExtractorMtime, Tags: []string{"photo", "camera"}, Dest: "images"},
+			{Name: "notes", Extensions: []string{"md"}},
+		},
+	}
+
+	err = GenerateFileNames(context.Background(), tmpDir, opts)
+	require.NoError(t, err)
+
+	// 画像はサブディレクトリに移動し、タグと更新日時のタイムスタンプが付与される
+	assert.FileExists(t, filepath.Join(tmpDir, "images", "20200102T030405--photo__camera_photo.jpg"))
+
+	// ノートはその場でリネームされる
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	require.Len(t, names, 2)
+	assert.Contains(t, names, "document.pdf", "files without a matching profile are not renamed")
+	for _, name := range names {
+		if name != "document.pdf" {
+			components, err := parakeet.ParseFileName(name)
+			require.NoError(t, err)
+			assert.Equal(t, "notes", components.Comment)
+			assert.Empty(t, components.Tags)
+		}
+	}
+}
+
+func TestGenerateFileNames_ProfileWithInvalidTags(t *testing.T) {
+	t.Parallel()
+	// Create temporary directory
+	tmpDir, err := os.MkdirTemp("", "parakeet-test-profile-tags-*")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	opts := RenameOptions{
+		Writer:   &bytes.Buffer{},
+		Profiles: []Profile{{Name: "images", Extensions: []string{"jpg"}, Tags: []string{"bad_tag"}}},
+	}
+
+	err = GenerateFileNames(context.Background(), tmpDir, opts)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid tags in profile images")
+}
+
+func TestGenerateFileNames_WithThrottle(t *testing.T) {
+	t.Parallel()
+	// Create temporary directory
+	tmpDir, err := os.MkdirTemp("", "parakeet-test-throttle-*")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for i := 0; i < 3; i++ {
+		err := os.WriteFile(filepath.Join(tmpDir, fmt.Sprintf("file%d.txt", i)), []byte("test content"), 0644)
+		require.NoError(t, err)
+	}
+
+	// 3ファイル x 2操作 = 6操作を 100/s で実行
+	start := time.Now()
+	buf := &bytes.Buffer{}
+	err = GenerateFileNames(context.Background(), tmpDir, RenameOptions{
+		Writer:        buf,
+		FilterOptions: FilterOptions{Extensions: []string{"txt"}},
+		Throttle:      NewThrottle(100),
+	})
+	require.NoError(t, err)
+
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.Contains(t, buf.String(), "Processed: 3")
+}
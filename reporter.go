@@ -0,0 +1,563 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// 出力フォーマット
+const (
+	FormatHuman  = "human"  // 人間向けのテキスト
+	FormatJSON   = "json"   // レコードの配列を最後にまとめて出力
+	FormatNDJSON = "ndjson" // レコードを1行ずつ出力
+	FormatJSONL  = "jsonl"  // ndjson の別名
+	FormatCSV    = "csv"    // レコードをカンマ区切りの表として出力
+	FormatTSV    = "tsv"    // レコードをタブ区切りの表として出力
+	FormatOrg    = "org"    // レコードを Org の表として出力
+	FormatDOT    = "dot"    // Graphviz の DOT（graph 向け。他のコマンドでは色なしの human と同じ）
+)
+
+// 色付けの設定
+const (
+	ColorAuto   = "auto"   // 出力先が端末の場合のみ色付けする
+	ColorAlways = "always" // 常に色付けする
+	ColorNever  = "never"  // 色付けしない
+)
+
+// Level は出力の詳細度を表す
+type Level int
+
+// 出力の詳細度
+const (
+	LevelQuiet   Level = iota - 1 // 警告とエラーのみ
+	LevelNormal                   // 通常
+	LevelVerbose                  // 詳細
+)
+
+// ANSIエスケープシーケンス
+const (
+	ansiReset  = "\x1b[0m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+)
+
+// ReporterConfig は Reporter の設定を表す
+type ReporterConfig struct {
+	Format string // 出力フォーマット（human, json, ndjson, jsonl, csv, tsv, org）
+	Color  bool   // 記号を色付けするかどうか
+	Level  Level  // 出力の詳細度
+}
+
+// Reporter はすべてのコマンドが共通で使う出力層
+// io.Writer を実装しているため、各オプションの Writer フィールドにそのまま渡せる
+type Reporter interface {
+	io.Writer
+	Printf(format string, args ...any)                                    // 通常のメッセージ（quiet では出力しない）
+	Successf(format string, args ...any)                                  // 成功（✓）
+	Warnf(format string, args ...any)                                     // 警告（⚠）
+	Errorf(format string, args ...any)                                    // エラー（✗）
+	Verbosef(format string, args ...any)                                  // 詳細メッセージ（verbose のみ）
+	Columns(event string, columns ...string)                              // レコードの列の順序（csv・tsv・org の表の列。Emit の前に宣言する）
+	Emit(event string, fields map[string]any, format string, args ...any) // 結果のレコード（human では format を出力）
+	Dataf(format string, args ...any)                                     // 表のヘッダーなど結果の構造の一部（human では quiet でも出力、レコードの形式では出力しない）
+	Summary(recordsKey string, fields map[string]any)                     // 結果の集計（json ではレコードを recordsKey、集計を summary に持つオブジェクトで出力）
+	Flush() error                                                         // 溜めている出力を書き出す
+}
+
+// NewReporter は設定に応じた Reporter を作成する
+func NewReporter(w io.Writer, config ReporterConfig) (Reporter, error) {
+	switch config.Format {
+	case FormatHuman, "":
+		return &humanReporter{w: w, color: config.Color, level: config.Level}, nil
+	case FormatJSON, FormatNDJSON, FormatJSONL:
+		return &jsonReporter{w: w, stream: config.Format != FormatJSON, level: config.Level}, nil
+	case FormatCSV, FormatTSV:
+		return newTableReporter(w, os.Stderr, config), nil
+	case FormatOrg:
+		return &orgReporter{w: w, errW: os.Stderr, level: config.Level, table: recordTable{format: config.Format}}, nil
+	case FormatDOT:
+		return &humanReporter{w: w, level: config.Level}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format: %s", config.Format)
+	}
+}
+
+// ReporterFor は Writer を Reporter として扱う
+// Writer が Reporter であればそのまま返し、そうでなければ色なしの human 形式で包む
+func ReporterFor(w io.Writer) Reporter {
+	if r, ok := w.(Reporter); ok {
+		return r
+	}
+	return &humanReporter{w: w, level: LevelNormal}
+}
+
+// reporterKey は context に Reporter を格納するキー
+type reporterKey struct{}
+
+// WithReporter は Reporter を格納した context を返す
+func WithReporter(ctx context.Context, r Reporter) context.Context {
+	return context.WithValue(ctx, reporterKey{}, r)
+}
+
+// ReporterFromContext は context に格納された Reporter を返す
+// 格納されていない場合は標準出力に色なしの human 形式で出力する
+func ReporterFromContext(ctx context.Context) Reporter {
+	if r, ok := ctx.Value(reporterKey{}).(Reporter); ok {
+		return r
+	}
+	return ReporterFor(os.Stdout)
+}
+
+// ResolveColor は色付けの設定と出力先から色付けするかどうかを決める
+func ResolveColor(mode string, f *os.File) (bool, error) {
+	switch mode {
+	case ColorAuto, "":
+		return os.Getenv("NO_COLOR") == "" && IsTerminal(f), nil
+	case ColorAlways:
+		return true, nil
+	case ColorNever:
+		return false, nil
+	default:
+		return false, fmt.Errorf("unknown color mode: %s", mode)
+	}
+}
+
+// IsTerminal はファイルが端末かどうかを返す
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// humanReporter は人間向けのテキストを出力する
+type humanReporter struct {
+	w     io.Writer
+	color bool
+	level Level
+}
+
+func (r *humanReporter) Write(p []byte) (int, error) {
+	return r.w.Write(p)
+}
+
+func (r *humanReporter) Printf(format string, args ...any) {
+	if r.level < LevelNormal {
+		return
+	}
+	_, _ = fmt.Fprintf(r.w, format, args...)
+}
+
+func (r *humanReporter) Successf(format string, args ...any) {
+	if r.level < LevelNormal {
+		return
+	}
+	r.printSymbol("✓", ansiGreen, format, args...)
+}
+
+func (r *humanReporter) Warnf(format string, args ...any) {
+	r.printSymbol("⚠", ansiYellow, format, args...)
+}
+
+func (r *humanReporter) Errorf(format string, args ...any) {
+	r.printSymbol("✗", ansiRed, format, args...)
+}
+
+func (r *humanReporter) Verbosef(format string, args ...any) {
+	if r.level < LevelVerbose {
+		return
+	}
+	_, _ = fmt.Fprintf(r.w, format, args...)
+}
+
+func (r *humanReporter) Columns(string, ...string) {}
+
+func (r *humanReporter) Emit(_ string, _ map[string]any, format string, args ...any) {
+	_, _ = fmt.Fprintf(r.w, format, args...)
+}
+
+func (r *humanReporter) Dataf(format string, args ...any) {
+	_, _ = fmt.Fprintf(r.w, format, args...)
+}
+
+func (r *humanReporter) Summary(string, map[string]any) {}
+
+func (r *humanReporter) Flush() error {
+	return nil
+}
+
+// printSymbol は記号付きのメッセージを出力する
+// format の先頭の改行は記号の前に出力する
+func (r *humanReporter) printSymbol(symbol, color, format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	body := strings.TrimLeft(message, "\n")
+	lead := message[:len(message)-len(body)]
+
+	if r.color {
+		symbol = color + symbol + ansiReset
+	}
+	_, _ = fmt.Fprintf(r.w, "%s%s %s", lead, symbol, body)
+}
+
+// jsonReporter は結果をJSONレコードとして出力する
+// 通常のメッセージは出力せず、警告とエラーはレコードとして出力する
+type jsonReporter struct {
+	w          io.Writer
+	stream     bool // true の場合は ndjson
+	level      Level
+	records    []map[string]any
+	recordsKey string         // 集計と一緒に出力する場合のレコードのキー
+	summary    map[string]any // 集計（nil の場合はレコードの配列のみ出力する）
+}
+
+func (r *jsonReporter) Write(p []byte) (int, error) {
+	return r.w.Write(p)
+}
+
+func (r *jsonReporter) Printf(string, ...any) {}
+
+func (r *jsonReporter) Successf(string, ...any) {}
+
+func (r *jsonReporter) Warnf(format string, args ...any) {
+	r.record("warning", map[string]any{"message": strings.TrimSpace(fmt.Sprintf(format, args...))})
+}
+
+func (r *jsonReporter) Errorf(format string, args ...any) {
+	r.record("error", map[string]any{"message": strings.TrimSpace(fmt.Sprintf(format, args...))})
+}
+
+func (r *jsonReporter) Verbosef(format string, args ...any) {
+	if r.level < LevelVerbose {
+		return
+	}
+	r.record("debug", map[string]any{"message": strings.TrimSpace(fmt.Sprintf(format, args...))})
+}
+
+func (r *jsonReporter) Columns(string, ...string) {}
+
+func (r *jsonReporter) Emit(event string, fields map[string]any, _ string, _ ...any) {
+	r.record(event, fields)
+}
+
+func (r *jsonReporter) Dataf(string, ...any) {}
+
+// Summary は ndjson では集計をレコードとして出力し、json では Flush でレコードと一緒にオブジェクトとして出力する
+func (r *jsonReporter) Summary(recordsKey string, fields map[string]any) {
+	if r.stream {
+		r.record("summary", fields)
+		return
+	}
+	r.recordsKey = recordsKey
+	r.summary = fields
+}
+
+func (r *jsonReporter) Flush() error {
+	if r.stream {
+		return nil
+	}
+
+	records := r.records
+	if records == nil {
+		records = []map[string]any{}
+	}
+	r.records = nil
+
+	enc := json.NewEncoder(r.w)
+	enc.SetIndent("", "  ")
+	if r.summary != nil {
+		summary := r.summary
+		r.summary = nil
+		return enc.Encode(map[string]any{r.recordsKey: records, "summary": summary})
+	}
+	return enc.Encode(records)
+}
+
+// record はレコードを出力または蓄積する
+func (r *jsonReporter) record(event string, fields map[string]any) {
+	record := make(map[string]any, len(fields)+1)
+	for k, v := range fields {
+		record[k] = v
+	}
+	record["event"] = event
+
+	if r.stream {
+		_ = json.NewEncoder(r.w).Encode(record)
+		return
+	}
+	r.records = append(r.records, record)
+}
+
+// recordTable は表の形式で出力するレコードの列を管理する
+// 列はコマンドが Columns で宣言した順で、1つの表には1種類のイベントのレコードだけを出力する
+// 列が宣言されていないイベントや、別のイベントのレコードを受け取った場合はエラーを記録し、以降のレコードは出力しない
+type recordTable struct {
+	format   string              // エラーメッセージに含める出力フォーマット
+	declared map[string][]string // イベントごとに宣言された列
+	event    string              // 表に出力しているイベント（最初のレコードで決まる）
+	columns  []string            // 表の列（最初のレコードで決まる）
+	err      error               // 表にできないレコードを受け取った場合のエラー
+}
+
+// declare はイベントの列を宣言する
+func (t *recordTable) declare(event string, columns []string) {
+	if t.declared == nil {
+		t.declared = make(map[string][]string)
+	}
+	t.declared[event] = columns
+}
+
+// accept はイベントのレコードを表に出力できるかどうかを返す
+// 最初に受け取ったレコードのイベントで表の列を決める
+func (t *recordTable) accept(event string) bool {
+	if t.err != nil {
+		return false
+	}
+	if t.columns == nil {
+		columns, ok := t.declared[event]
+		if !ok {
+			t.fail(fmt.Sprintf("%s records", event))
+			return false
+		}
+		t.event, t.columns = event, columns
+		return true
+	}
+	if event != t.event {
+		t.fail(fmt.Sprintf("mixing %s and %s records in one table", t.event, event))
+		return false
+	}
+	return true
+}
+
+// fail は表にできない出力を受け取ったことを記録する
+func (t *recordTable) fail(what string) {
+	if t.err == nil {
+		t.err = fmt.Errorf("--format %s does not support %s; use json or ndjson", t.format, what)
+	}
+}
+
+// tableFormat は Reporter が表の形式（csv, tsv, org）で出力するかどうかを返す
+func tableFormat(r Reporter) (string, bool) {
+	switch r := r.(type) {
+	case *tableReporter:
+		return r.table.format, true
+	case *orgReporter:
+		return r.table.format, true
+	}
+	return "", false
+}
+
+// rejectTableFormat は形の異なるレコードを出力するコマンドで、表の形式の出力をエラーにする
+// 処理を始める前に呼び出し、途中まで実行してから失敗しないようにする
+func rejectTableFormat(r Reporter, command string) error {
+	if format, ok := tableFormat(r); ok {
+		return fmt.Errorf("%s does not support --format %s; use json or ndjson", command, format)
+	}
+	return nil
+}
+
+// tableReporter は結果のレコードを CSV・TSV の表として出力する
+// 列はコマンドが Columns で宣言した順で、通常のメッセージは出力せず、警告とエラーは errW に出力する
+type tableReporter struct {
+	w      *csv.Writer
+	errW   io.Writer
+	level  Level
+	table  recordTable
+	header bool // ヘッダーを出力したかどうか
+}
+
+// newTableReporter は CSV（config.Format が tsv の場合は TSV）の表を出力する Reporter を作成する
+func newTableReporter(w, errW io.Writer, config ReporterConfig) *tableReporter {
+	writer := csv.NewWriter(w)
+	if config.Format == FormatTSV {
+		writer.Comma = '\t'
+	}
+	return &tableReporter{w: writer, errW: errW, level: config.Level, table: recordTable{format: config.Format}}
+}
+
+func (r *tableReporter) Write(p []byte) (int, error) {
+	return r.errW.Write(p)
+}
+
+func (r *tableReporter) Printf(string, ...any) {}
+
+func (r *tableReporter) Successf(string, ...any) {}
+
+func (r *tableReporter) Warnf(format string, args ...any) {
+	_, _ = fmt.Fprintf(r.errW, "⚠ %s\n", strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
+
+func (r *tableReporter) Errorf(format string, args ...any) {
+	_, _ = fmt.Fprintf(r.errW, "✗ %s\n", strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
+
+func (r *tableReporter) Verbosef(format string, args ...any) {
+	if r.level < LevelVerbose {
+		return
+	}
+	_, _ = fmt.Fprintf(r.errW, format, args...)
+}
+
+func (r *tableReporter) Columns(event string, columns ...string) {
+	r.table.declare(event, columns)
+}
+
+func (r *tableReporter) Emit(event string, fields map[string]any, _ string, _ ...any) {
+	if !r.table.accept(event) {
+		return
+	}
+	if !r.header {
+		_ = r.w.Write(r.table.columns)
+		r.header = true
+	}
+
+	row := make([]string, len(r.table.columns))
+	for i, column := range r.table.columns {
+		row[i] = tableCell(fields[column])
+	}
+	_ = r.w.Write(row)
+	r.w.Flush()
+}
+
+func (r *tableReporter) Dataf(string, ...any) {}
+
+func (r *tableReporter) Summary(string, map[string]any) {
+	r.table.fail("summaries")
+}
+
+func (r *tableReporter) Flush() error {
+	r.w.Flush()
+	if err := r.w.Error(); err != nil {
+		return err
+	}
+	return r.table.err
+}
+
+// tableCell はフィールドの値を表のセルの文字列にする（リストはカンマ区切り）
+func tableCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// orgReporter は結果のレコードを Org の表として出力する
+// 列はコマンドが Columns で宣言した順（csv・tsv と共通）で、列の幅を揃えるため Flush でまとめて出力する
+// title と link の両方の列がある場合は、title を [[file:link][title]] のリンクにして link 列は出力しない
+type orgReporter struct {
+	w     io.Writer
+	errW  io.Writer
+	level Level
+	table recordTable
+	rows  [][]string // 溜めている行
+}
+
+func (r *orgReporter) Write(p []byte) (int, error) {
+	return r.errW.Write(p)
+}
+
+func (r *orgReporter) Printf(string, ...any) {}
+
+func (r *orgReporter) Successf(string, ...any) {}
+
+func (r *orgReporter) Warnf(format string, args ...any) {
+	_, _ = fmt.Fprintf(r.errW, "⚠ %s\n", strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
+
+func (r *orgReporter) Errorf(format string, args ...any) {
+	_, _ = fmt.Fprintf(r.errW, "✗ %s\n", strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
+
+func (r *orgReporter) Verbosef(format string, args ...any) {
+	if r.level < LevelVerbose {
+		return
+	}
+	_, _ = fmt.Fprintf(r.errW, format, args...)
+}
+
+func (r *orgReporter) Columns(event string, columns ...string) {
+	if slices.Contains(columns, "title") {
+		columns = slices.DeleteFunc(slices.Clone(columns), func(column string) bool { return column == "link" })
+	}
+	r.table.declare(event, columns)
+}
+
+func (r *orgReporter) Emit(event string, fields map[string]any, _ string, _ ...any) {
+	if !r.table.accept(event) {
+		return
+	}
+
+	link, hasLink := fields["link"].(string)
+	row := make([]string, len(r.table.columns))
+	for i, column := range r.table.columns {
+		cell := orgCellEscaper.Replace(tableCell(fields[column]))
+		if column == "title" && hasLink {
+			cell = fmt.Sprintf("[[file:%s][%s]]", orgLinkEscaper.Replace(link), cell)
+		}
+		row[i] = cell
+	}
+	r.rows = append(r.rows, row)
+}
+
+func (r *orgReporter) Dataf(string, ...any) {}
+
+func (r *orgReporter) Summary(string, map[string]any) {
+	r.table.fail("summaries")
+}
+
+func (r *orgReporter) Flush() error {
+	if r.table.err != nil {
+		return r.table.err
+	}
+	if r.table.columns == nil {
+		return nil
+	}
+
+	columns := r.table.columns
+	widths := make([]int, len(columns))
+	for _, row := range append([][]string{columns}, r.rows...) {
+		for i, cell := range row {
+			widths[i] = max(widths[i], displayWidth(cell))
+		}
+	}
+
+	var b strings.Builder
+	writeRow := func(row []string) {
+		for i, cell := range row {
+			b.WriteString("| " + cell + strings.Repeat(" ", widths[i]-displayWidth(cell)) + " ")
+		}
+		b.WriteString("|\n")
+	}
+	writeRow(columns)
+	rules := make([]string, len(widths))
+	for i, w := range widths {
+		rules[i] = strings.Repeat("-", w+2)
+	}
+	b.WriteString("|" + strings.Join(rules, "+") + "|\n")
+	for _, row := range r.rows {
+		writeRow(row)
+	}
+
+	r.table.columns, r.rows = nil, nil
+	_, err := io.WriteString(r.w, b.String())
+	return err
+}
+
+// orgCellEscaper は Org の表のセルを区切らないように | をエスケープする
+var orgCellEscaper = strings.NewReplacer("|", `\vert{}`)
+
+// orgLinkEscaper は Org のリンク先の角括弧とバックスラッシュをエスケープする
+var orgLinkEscaper = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`)
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHumanReporter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		config   ReporterConfig
+		expected string
+	}{
+		{
+			name:     "normal",
+			config:   ReporterConfig{Format: FormatHuman},
+			expected: "info\n✓ done\n⚠ careful\n✗ broken\nrecord\n",
+		},
+		{
+			name:     "quiet",
+			config:   ReporterConfig{Format: FormatHuman, Level: LevelQuiet},
+			expected: "⚠ careful\n✗ broken\nrecord\n",
+		},
+		{
+			name:     "verbose",
+			config:   ReporterConfig{Format: FormatHuman, Level: LevelVerbose},
+			expected: "info\n✓ done\n⚠ careful\n✗ broken\ndetail\nrecord\n",
+		},
+		{
+			name:     "color",
+			config:   ReporterConfig{Format: FormatHuman, Color: true},
+			expected: "info\n\x1b[32m✓\x1b[0m done\n\x1b[33m⚠\x1b[0m careful\n\x1b[31m✗\x1b[0m broken\nrecord\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			reporter, err := NewReporter(&buf, tt.config)
+			require.NoError(t, err)
+
+			reporter.Printf("info\n")
+			reporter.Successf("done\n")
+			reporter.Warnf("careful\n")
+			reporter.Errorf("broken\n")
+			reporter.Verbosef("detail\n")
+			reporter.Emit("file", map[string]any{"file": "a"}, "record\n")
+			require.NoError(t, reporter.Flush())
+
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestHumanReporterLeadingNewline(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	reporter := ReporterFor(&buf)
+
+	reporter.Successf("\nAll files are properly formatted!\n")
+
+	assert.Equal(t, "\n✓ All files are properly formatted!\n", buf.String())
+}
+
+func TestJSONReporter(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	reporter, err := NewReporter(&buf, ReporterConfig{Format: FormatJSON})
+	require.NoError(t, err)
+
+	reporter.Printf("ignored\n")
+	reporter.Successf("ignored\n")
+	reporter.Warnf("careful\n")
+	reporter.Emit("file", map[string]any{"file": "a.pdf"}, "a.pdf\n")
+	assert.Empty(t, buf.String(), "json output should be written on Flush")
+
+	require.NoError(t, reporter.Flush())
+
+	var records []map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &records))
+	assert.Equal(t, []map[string]any{
+		{"event": "warning", "message": "careful"},
+		{"event": "file", "file": "a.pdf"},
+	}, records)
+}
+
+func TestJSONReporterEmpty(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	reporter, err := NewReporter(&buf, ReporterConfig{Format: FormatJSON})
+	require.NoError(t, err)
+
+	require.NoError(t, reporter.Flush())
+
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+func TestJSONReporterSummary(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	reporter, err := NewReporter(&buf, ReporterConfig{Format: FormatJSON})
+	require.NoError(t, err)
+
+	reporter.Warnf("careful\n")
+	reporter.Summary("issues", map[string]any{"total": 1})
+	require.NoError(t, reporter.Flush())
+
+	assert.JSONEq(t, `{"issues": [{"event": "warning", "message": "careful"}], "summary": {"total": 1}}`, buf.String())
+}
+
+func TestNDJSONReporter(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	reporter, err := NewReporter(&buf, ReporterConfig{Format: FormatNDJSON})
+	require.NoError(t, err)
+
+	reporter.Emit("file", map[string]any{"file": "a.pdf"}, "a.pdf\n")
+	reporter.Errorf("broken\n")
+	require.NoError(t, reporter.Flush())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.JSONEq(t, `{"event":"file","file":"a.pdf"}`, lines[0])
+	assert.JSONEq(t, `{"event":"error","message":"broken"}`, lines[1])
+}
+
+func TestNewReporterUnknownFormat(t *testing.T) {
+	t.Parallel()
+	_, err := NewReporter(&bytes.Buffer{}, ReporterConfig{Format: "xml"})
+	assert.Error(t, err)
+}
+
+func TestResolveColor(t *testing.T) {
+	t.Parallel()
+
+	color, err := ResolveColor(ColorAlways, nil)
+	require.NoError(t, err)
+	assert.True(t, color)
+
+	color, err = ResolveColor(ColorNever, nil)
+	require.NoError(t, err)
+	assert.False(t, color)
+
+	_, err = ResolveColor("sometimes", nil)
+	assert.Error(t, err)
+}
+
+func TestListFilesJSON(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-reporter-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250903T083109--tcpip__network.pdf"), []byte("test content"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "invalid-file.pdf"), []byte("test content"), 0644))
+
+	var buf bytes.Buffer
+	reporter, err := NewReporter(&buf, ReporterConfig{Format: FormatNDJSON})
+	require.NoError(t, err)
+
+	_, err = ListFiles(tmpDir, ListOptions{Writer: reporter})
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"event":"file","file":"20250903T083109--tcpip__network.pdf","id":"20250903T083109","title":"tcpip","tags":["network"],"ext":"pdf","size":12}`, strings.TrimSpace(buf.String()))
+}
+
+func TestTableReporter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("csv", func(t *testing.T) {
+		t.Parallel()
+		var out, errOut bytes.Buffer
+		reporter := newTableReporter(&out, &errOut, ReporterConfig{Format: FormatCSV})
+
+		reporter.Printf("ignored\n")
+		reporter.Columns("file", "title", "tags", "size")
+		reporter.Emit("file", map[string]any{"title": `TCP, "IP"`, "tags": []string{"network", "infra"}, "size": int64(3)}, "ignored\n")
+		reporter.Emit("file", map[string]any{"title": "memo", "tags": []string{}, "size": int64(0)}, "ignored\n")
+		reporter.Warnf("\nsomething odd\n")
+		require.NoError(t, reporter.Flush())
+
+		assert.Equal(t, "title,tags,size\n\"TCP, \"\"IP\"\"\",\"network,infra\",3\nmemo,,0\n", out.String())
+		assert.Equal(t, "⚠ something odd\n", errOut.String())
+	})
+
+	t.Run("tsv", func(t *testing.T) {
+		t.Parallel()
+		var out bytes.Buffer
+		reporter, err := NewReporter(&out, ReporterConfig{Format: FormatTSV})
+		require.NoError(t, err)
+
+		reporter.Columns("file", "id", "title")
+		reporter.Emit("file", map[string]any{"id": "20250903T083109", "title": "a | b, c"}, "ignored\n")
+		require.NoError(t, reporter.Flush())
+
+		assert.Equal(t, "id\ttitle\n20250903T083109\ta | b, c\n", out.String())
+	})
+
+	t.Run("列を宣言していないイベントはエラー", func(t *testing.T) {
+		t.Parallel()
+		var out bytes.Buffer
+		reporter := newTableReporter(&out, io.Discard, ReporterConfig{Format: FormatCSV})
+
+		reporter.Emit("only_in", map[string]any{"file": "a.txt"}, "ignored\n")
+		err := reporter.Flush()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--format csv does not support only_in records")
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("別のイベントのレコードはエラー", func(t *testing.T) {
+		t.Parallel()
+		var out bytes.Buffer
+		reporter := newTableReporter(&out, io.Discard, ReporterConfig{Format: FormatCSV})
+
+		reporter.Columns("copied", "file")
+		reporter.Columns("renamed", "from", "to")
+		reporter.Emit("copied", map[string]any{"file": "a.txt"}, "ignored\n")
+		reporter.Emit("renamed", map[string]any{"from": "b.txt", "to": "c.txt"}, "ignored\n")
+		reporter.Emit("copied", map[string]any{"file": "d.txt"}, "ignored\n")
+		err := reporter.Flush()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "mixing copied and renamed records")
+		assert.Equal(t, "file\na.txt\n", out.String())
+	})
+
+	t.Run("集計はエラー", func(t *testing.T) {
+		t.Parallel()
+		reporter := newTableReporter(io.Discard, io.Discard, ReporterConfig{Format: FormatTSV})
+
+		reporter.Summary("issues", map[string]any{"total": 1})
+		err := reporter.Flush()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--format tsv does not support summaries")
+	})
+}
+
+func TestTableFormatCommands(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "20250903T083109--tcpip__network.pdf"), []byte("test content"), 0644))
+
+	t.Run("list は宣言した列の順で出力", func(t *testing.T) {
+		t.Parallel()
+		var out bytes.Buffer
+		reporter, err := NewReporter(&out, ReporterConfig{Format: FormatCSV})
+		require.NoError(t, err)
+
+		_, err = ListFiles(dir, ListOptions{Writer: reporter})
+		require.NoError(t, err)
+		require.NoError(t, reporter.Flush())
+
+		assert.Equal(t, "id,title,tags,ext,size,file\n20250903T083109,tcpip,network,pdf,12,20250903T083109--tcpip__network.pdf\n", out.String())
+	})
+
+	t.Run("形の異なるレコードを出力するコマンドはエラー", func(t *testing.T) {
+		t.Parallel()
+		reporter, err := NewReporter(io.Discard, ReporterConfig{Format: FormatCSV})
+		require.NoError(t, err)
+
+		_, err = CompareDirectories(dir, t.TempDir(), DiffOptions{Writer: reporter})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "diff does not support --format csv")
+
+		_, err = ValidateFileNames(context.Background(), dir, ValidateOptions{Writer: reporter})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "validate does not support --format csv")
+	})
+}
+
+func TestOrgReporter(t *testing.T) {
+	t.Parallel()
+	var out, errOut bytes.Buffer
+	reporter, err := NewReporter(&out, ReporterConfig{Format: FormatOrg})
+	require.NoError(t, err)
+	reporter.(*orgReporter).errW = &errOut
+
+	reporter.Printf("ignored\n")
+	reporter.Columns("file", "id", "title", "tags", "link")
+	reporter.Emit("file", map[string]any{"id": "20250903T083109", "title": "a | b", "tags": []string{"network", "infra"}, "link": "docs/tcp ip [draft].pdf"}, "ignored\n")
+	reporter.Emit("file", map[string]any{"id": "20250904T000000", "title": "日本語", "tags": []string{}, "link": "memo.md"}, "ignored\n")
+	reporter.Warnf("something odd\n")
+	require.NoError(t, reporter.Flush())
+
+	assert.Equal(t, ""+
+		"| id              | title                                           | tags          |\n"+
+		"|-----------------+-------------------------------------------------+---------------|\n"+
+		"| 20250903T083109 | [[file:docs/tcp ip \\[draft\\].pdf][a \\vert{} b]] | network,infra |\n"+
+		"| 20250904T000000 | [[file:memo.md][日本語]]                        |               |\n", out.String())
+	assert.Equal(t, "⚠ something odd\n", errOut.String())
+
+	// レコードがない場合は何も出力しない
+	out.Reset()
+	require.NoError(t, reporter.Flush())
+	assert.Empty(t, out.String())
+
+	t.Run("csv と同じく形の異なるレコードはエラー", func(t *testing.T) {
+		t.Parallel()
+		reporter, err := NewReporter(io.Discard, ReporterConfig{Format: FormatOrg})
+		require.NoError(t, err)
+
+		_, err = CompareDirectories(t.TempDir(), t.TempDir(), DiffOptions{Writer: reporter})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "diff does not support --format org")
+
+		reporter.Emit("only_in", map[string]any{"file": "a.txt"}, "ignored\n")
+		err = reporter.Flush()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--format org does not support only_in records")
+	})
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// RetimeOptions はタイムスタンプ変更操作のオプションを表す
+type RetimeOptions struct {
+	Writer    io.Writer          // 出力先
+	To        string             // 新しいタイムスタンプ（例: 20250101T090000）
+	FromMtime bool               // To の代わりにファイルの更新日時からタイムスタンプを生成する
+	Precision parakeet.Precision // FromMtime で生成するタイムスタンプの精度（空の場合は秒まで）
+	Hooks     *HookRunner        // リネームの前後に実行するフック（nil の場合は実行しない）
+}
+
+// RetimeFile はファイルのタイムスタンプ（ID）のみを変更し、新しいファイルパスを返す
+// コメント・タグ・拡張子はそのまま保つ。To で指定したタイムスタンプが同じディレクトリの他のファイルと重複する場合はエラーにし、
+// FromMtime の場合は重複しないように以降のタイムスタンプにする
+func RetimeFile(filePath string, opts RetimeOptions) (string, error) {
+	reporter := ReporterFor(opts.Writer)
+
+	if opts.To != "" && opts.FromMtime {
+		return "", fmt.Errorf("--to and --from-mtime cannot be used together")
+	}
+	if opts.To == "" && !opts.FromMtime {
+		return "", fmt.Errorf("either --to or --from-mtime is required")
+	}
+	if opts.To != "" {
+		if err := parakeet.ValidateTimestamp(opts.To); err != nil {
+			return "", fmt.Errorf("invalid --to: %w", err)
+		}
+	}
+
+	// ファイルの存在チェック
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("file does not exist: %s", filePath)
+		}
+		return "", fmt.Errorf("failed to access file: %w", err)
+	}
+
+	if fileInfo.IsDir() {
+		return "", fmt.Errorf("cannot retime directory: %s", filePath)
+	}
+
+	fileName := filepath.Base(filePath)
+	dirPath := filepath.Dir(filePath)
+
+	// ファイル名をパース
+	components, err := parakeet.ParseFileName(fileName)
+	if err != nil {
+		return "", fmt.Errorf("file name is not in correct format: %w", err)
+	}
+
+	// 自分自身のタイムスタンプは重複とみなさない
+	existingTimestamps, err := CollectExistingTimestamps(dirPath)
+	if err != nil {
+		return "", err
+	}
+	delete(existingTimestamps, components.Timestamp)
+
+	timestamp := opts.To
+	if opts.FromMtime {
+		timestamp = parakeet.GenerateUniqueTimestampWithPrecision(fileInfo.ModTime(), existingTimestamps, opts.Precision)
+	} else if existingTimestamps[timestamp] {
+		return "", fmt.Errorf("timestamp is already used in %s: %s", dirPath, timestamp)
+	}
+
+	if timestamp == components.Timestamp {
+		reporter.Successf("No changes made\n")
+		return filePath, nil
+	}
+
+	components.Timestamp = timestamp
+	newFileName, err := components.FormatFileNameStrict()
+	if err != nil {
+		return "", err
+	}
+	newFilePath := filepath.Join(dirPath, newFileName)
+
+	if _, err := os.Stat(newFilePath); err == nil {
+		return "", fmt.Errorf("target file already exists: %s", newFileName)
+	}
+
+	// ファイルをリネーム
+	if err := opts.Hooks.RenameFile(filePath, newFilePath); err != nil {
+		return "", fmt.Errorf("failed to rename file: %w", err)
+	}
+
+	reporter.Successf("Renamed: %s → %s\n", fileName, newFileName)
+
+	return newFilePath, nil
+}
+
+// SyncMtimeOptions は更新日時の同期操作のオプションを表す
+type SyncMtimeOptions struct {
+	Writer io.Writer // 出力先
+	FilterOptions
+	DryRun bool // 実際には変更しない
+}
+
+// SyncMtimeResult は更新日時の同期操作の結果を表す
+type SyncMtimeResult struct {
+	Updated   []string // 更新日時を変更したファイル
+	Unchanged int      // 更新日時がタイムスタンプと一致していたファイル数
+}
+
+// SyncMtimes はディレクトリ直下のフォーマット済みファイルの更新日時を、ファイル名のタイムスタンプ（ローカル時刻）に合わせる
+// ファイルマネージャで更新日時順に並べたときに parakeet の順序と一致させる。アクセス日時は変更しない
+func SyncMtimes(targetDir string, opts SyncMtimeOptions) (*SyncMtimeResult, error) {
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("updated", "file", "mtime", "dry_run")
+
+	// ディレクトリの存在チェック
+	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", targetDir)
+	}
+
+	entries, err := os.ReadDir(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	prefix := ""
+	if opts.DryRun {
+		prefix = "[dry-run] "
+	}
+
+	result := &SyncMtimeResult{
+		Updated: []string{},
+	}
+	for _, entry := range entries {
+		// ディレクトリとシムはスキップ
+		if entry.IsDir() || IsShim(targetDir, entry) {
+			continue
+		}
+
+		fileName := entry.Name()
+		if !opts.Matches(fileName) {
+			continue
+		}
+
+		components, err := parakeet.ParseFileName(fileName)
+		if err != nil {
+			continue
+		}
+		t, err := time.ParseInLocation(timestampLayout, components.Timestamp, time.Local)
+		if err != nil {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get file info: %w", err)
+		}
+		if info.ModTime().Equal(t) {
+			result.Unchanged++
+			continue
+		}
+
+		if !opts.DryRun {
+			// ゼロ値のアクセス日時は変更されない
+			if err := os.Chtimes(filepath.Join(targetDir, fileName), time.Time{}, t); err != nil {
+				return nil, fmt.Errorf("failed to set modification time: %w", err)
+			}
+		}
+		result.Updated = append(result.Updated, fileName)
+		reporter.Emit("updated", map[string]any{"file": fileName, "mtime": t.Format(time.RFC3339Nano), "dry_run": opts.DryRun}, "%s✓ Updated mtime: %s → %s\n", prefix, fileName, t.Format("2006-01-02 15:04:05"))
+	}
+
+	// サマリーを出力
+	reporter.Printf("\nSync Mtime Summary:\n")
+	reporter.Printf("  Updated: %d\n", len(result.Updated))
+	reporter.Printf("  Unchanged: %d\n", result.Unchanged)
+
+	return result, nil
+}
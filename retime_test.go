@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetimeFile(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-retime-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	filePath := filepath.Join(tmpDir, "20250903T083109--memo__network.pdf")
+	require.NoError(t, os.WriteFile(filePath, []byte("test"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250101T090001--other.pdf"), []byte("test"), 0644))
+
+	// タイムスタンプのみ変更し、コメント・タグ・拡張子は保つ
+	buf := &bytes.Buffer{}
+	newPath, err := RetimeFile(filePath, RetimeOptions{Writer: buf, To: "20250101T090000"})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(tmpDir, "20250101T090000--memo__network.pdf"), newPath)
+	assert.NoFileExists(t, filePath)
+	assert.Contains(t, buf.String(), "Renamed: 20250903T083109--memo__network.pdf → 20250101T090000--memo__network.pdf")
+
+	// 同じタイムスタンプの場合は変更しない
+	buf.Reset()
+	samePath, err := RetimeFile(newPath, RetimeOptions{Writer: buf, To: "20250101T090000"})
+	require.NoError(t, err)
+	assert.Equal(t, newPath, samePath)
+	assert.Contains(t, buf.String(), "No changes made")
+
+	// 他のファイルと重複するタイムスタンプは指定できない
+	_, err = RetimeFile(newPath, RetimeOptions{Writer: buf, To: "20250101T090001"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timestamp is already used")
+
+	// 更新日時から重複しないタイムスタンプを生成する
+	mtime := time.Date(2025, 1, 1, 9, 0, 1, 0, time.Local)
+	require.NoError(t, os.Chtimes(newPath, mtime, mtime))
+	mtimePath, err := RetimeFile(newPath, RetimeOptions{Writer: buf, FromMtime: true})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(tmpDir, "20250101T090002--memo__network.pdf"), mtimePath)
+
+	mtimePath, err = RetimeFile(mtimePath, RetimeOptions{Writer: buf, FromMtime: true, Precision: parakeet.PrecisionMillisecond})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(tmpDir, "20250101T090001,000--memo__network.pdf"), mtimePath)
+}
+
+func TestRetimeFile_Errors(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-retime-errors-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	filePath := filepath.Join(tmpDir, "20250903T083109--memo.md")
+	require.NoError(t, os.WriteFile(filePath, []byte("test"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "unformatted.md"), []byte("test"), 0644))
+
+	tests := []struct {
+		name     string
+		path     string
+		opts     RetimeOptions
+		expected string
+	}{
+		{"指定なし", filePath, RetimeOptions{}, "either --to or --from-mtime is required"},
+		{"両方指定", filePath, RetimeOptions{To: "20250101T090000", FromMtime: true}, "cannot be used together"},
+		{"不正なタイムスタンプ", filePath, RetimeOptions{To: "20251399T256161"}, "invalid --to"},
+		{"存在しないファイル", filepath.Join(tmpDir, "missing.md"), RetimeOptions{To: "20250101T090000"}, "file does not exist"},
+		{"フォーマットされていないファイル", filepath.Join(tmpDir, "unformatted.md"), RetimeOptions{To: "20250101T090000"}, "not in correct format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tt.opts.Writer = &bytes.Buffer{}
+			_, err := RetimeFile(tt.path, tt.opts)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+	assert.FileExists(t, filePath)
+}
+
+func TestSyncMtimes(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-sync-mtime-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	synced := time.Date(2025, 9, 4, 10, 0, 0, 0, time.Local)
+	for _, name := range []string{"20250903T083109--memo.pdf", "20250903T083109,250--scan.pdf", "20250904T100000--synced.pdf", "unformatted.pdf", "20250903T083109--note.md"} {
+		path := filepath.Join(tmpDir, name)
+		require.NoError(t, os.WriteFile(path, []byte("test"), 0644))
+		require.NoError(t, os.Chtimes(path, synced, synced))
+	}
+
+	// dry-run では変更しない
+	result, err := SyncMtimes(tmpDir, SyncMtimeOptions{Writer: &bytes.Buffer{}, FilterOptions: FilterOptions{Extensions: []string{"pdf"}}, DryRun: true})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"20250903T083109--memo.pdf", "20250903T083109,250--scan.pdf"}, result.Updated)
+	info, err := os.Stat(filepath.Join(tmpDir, "20250903T083109--memo.pdf"))
+	require.NoError(t, err)
+	assert.True(t, info.ModTime().Equal(synced))
+
+	buf := &bytes.Buffer{}
+	result, err = SyncMtimes(tmpDir, SyncMtimeOptions{Writer: buf, FilterOptions: FilterOptions{Extensions: []string{"pdf"}}})
+	require.NoError(t, err)
+	assert.Len(t, result.Updated, 2)
+	assert.Equal(t, 1, result.Unchanged)
+	assert.Contains(t, buf.String(), "Updated: 2")
+
+	expected := map[string]time.Time{
+		"20250903T083109--memo.pdf":     time.Date(2025, 9, 3, 8, 31, 9, 0, time.Local),
+		"20250903T083109,250--scan.pdf": time.Date(2025, 9, 3, 8, 31, 9, 250*int(time.Millisecond), time.Local),
+		"unformatted.pdf":               synced,
+		"20250903T083109--note.md":      synced,
+	}
+	for name, mtime := range expected {
+		info, err := os.Stat(filepath.Join(tmpDir, name))
+		require.NoError(t, err)
+		assert.True(t, info.ModTime().Equal(mtime), "%s: %v", name, info.ModTime())
+	}
+}
+
+func TestRetimeFile_UndoInSubdirectory(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-retime-undo-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	// ID で再帰的に見つけたサブディレクトリのファイルのタイムスタンプを変更する
+	subDir := filepath.Join(tmpDir, "sub")
+	require.NoError(t, os.MkdirAll(subDir, 0755))
+	filePath := filepath.Join(subDir, "20250903T083109--memo.pdf")
+	require.NoError(t, os.WriteFile(filePath, []byte("test"), 0644))
+
+	found, err := FindFileByIDWithDepth(tmpDir, "20250903T083109", 2)
+	require.NoError(t, err)
+	newPath, err := RetimeFile(found, RetimeOptions{Writer: &bytes.Buffer{}, To: "20250101T090000"})
+	require.NoError(t, err)
+	recordFileRename("retime", found, newPath, &bytes.Buffer{})
+
+	// ジャーナルはファイルのディレクトリに記録し、そこで undo できる
+	assert.NoFileExists(t, JournalPath(tmpDir))
+	batch, err := UndoLastBatch(subDir, UndoOptions{Writer: &bytes.Buffer{}})
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+	assert.Equal(t, "retime", batch[0].Command)
+	assert.FileExists(t, filePath)
+	assert.NoFileExists(t, filepath.Join(subDir, "20250101T090000--memo.pdf"))
+}
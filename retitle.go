@@ -0,0 +1,210 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// RetitleFile はファイルのコメント（タイトル）を変更し、新しいファイルパスを返す
+// タイムスタンプ・タグ・拡張子はそのまま保つ。hooks が nil でない場合はリネームの前後にフックを実行する
+func RetitleFile(filePath, title string, w io.Writer, hooks *HookRunner) (string, error) {
+	reporter := ReporterFor(w)
+
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "", fmt.Errorf("title cannot be empty")
+	}
+
+	// ファイルの存在チェック
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("file does not exist: %s", filePath)
+		}
+		return "", fmt.Errorf("failed to access file: %w", err)
+	}
+
+	if fileInfo.IsDir() {
+		return "", fmt.Errorf("cannot retitle directory: %s", filePath)
+	}
+
+	fileName := filepath.Base(filePath)
+	dirPath := filepath.Dir(filePath)
+
+	// ファイル名をパース
+	components, err := parakeet.ParseFileName(fileName)
+	if err != nil {
+		return "", fmt.Errorf("file name is not in correct format: %w", err)
+	}
+
+	// タグの区切りやファイル名に使えない文字を取り除く
+	comment := parakeet.SanitizeComment(title)
+	if comment == components.Comment {
+		reporter.Successf("No changes made\n")
+		return filePath, nil
+	}
+
+	components.Comment = comment
+	newFileName, err := components.FormatFileNameStrict()
+	if err != nil {
+		return "", err
+	}
+	newFilePath := filepath.Join(dirPath, newFileName)
+
+	if _, err := os.Stat(newFilePath); err == nil {
+		return "", fmt.Errorf("target file already exists: %s", newFileName)
+	}
+
+	// ファイルをリネーム
+	if err := hooks.RenameFile(filePath, newFilePath); err != nil {
+		return "", fmt.Errorf("failed to rename file: %w", err)
+	}
+
+	reporter.Successf("Renamed: %s → %s\n", fileName, newFileName)
+
+	return newFilePath, nil
+}
+
+// TitleMapping はIDと新しいタイトルの対応を表す
+type TitleMapping struct {
+	Line  int    // CSVの行番号（エラー表示用）
+	ID    string // 対象ファイルのID
+	Title string // 新しいタイトル
+}
+
+// RetitleBatchOptions は一括タイトル変更操作のオプションを表す
+type RetitleBatchOptions struct {
+	Writer  io.Writer      // 出力先
+	DryRun  bool           // 実際にはリネームしない
+	Journal *Journal       // 実行したリネームを記録するジャーナル（nil の場合は記録しない）
+	Shims   bool           // リネーム後に旧ファイル名から新しいファイルへのシンボリックリンク（シム）を残す
+	Vault   *ObsidianVault // リネームに合わせて wiki リンクを書き換える Obsidian vault（nil の場合は書き換えない）
+	Hooks   *HookRunner    // リネームの前後に実行するフック（nil の場合は実行しない）
+}
+
+// RetitleBatchResult は一括タイトル変更操作の結果を表す
+type RetitleBatchResult struct {
+	Renamed map[string]string // リネームしたファイル: 旧ファイル名 -> 新ファイル名
+}
+
+// ReadTitleMapping はCSV（id,title の2列）からIDとタイトルの対応を読み込む
+// 先頭行が "id" で始まる場合はヘッダーとして読み飛ばす
+func ReadTitleMapping(r io.Reader) ([]TitleMapping, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read title mapping: %w", err)
+	}
+
+	mappings := []TitleMapping{}
+	for i, record := range records {
+		id := strings.TrimSpace(record[0])
+		if i == 0 && strings.EqualFold(id, "id") {
+			continue
+		}
+		mappings = append(mappings, TitleMapping{Line: i + 1, ID: id, Title: record[1]})
+	}
+
+	return mappings, nil
+}
+
+// RetitleFromMapping はディレクトリ内のファイルのタイトルをIDとタイトルの対応に従って一括で変更する
+// すべての行を事前に検証してから実行し、途中で失敗した場合は元に戻す
+func RetitleFromMapping(targetDir string, mappings []TitleMapping, opts RetitleBatchOptions) (*RetitleBatchResult, error) {
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("renamed", "from", "to", "dry_run")
+
+	// ディレクトリの存在チェック
+	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", targetDir)
+	}
+
+	seen := make(map[string]bool)
+	plans := []renamePlan{}
+	for i, mapping := range mappings {
+		line := mapping.Line
+		if line == 0 {
+			line = i + 1
+		}
+		if seen[mapping.ID] {
+			return nil, fmt.Errorf("line %d: duplicate ID: %s", line, mapping.ID)
+		}
+		seen[mapping.ID] = true
+
+		if strings.TrimSpace(mapping.Title) == "" {
+			return nil, fmt.Errorf("line %d: title cannot be empty", line)
+		}
+
+		filePath, err := FindFileByID(targetDir, mapping.ID)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		fileName := filepath.Base(filePath)
+		components, err := parakeet.ParseFileName(fileName)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: file name is not in correct format: %w", line, err)
+		}
+
+		// タグの区切りやファイル名に使えない文字を取り除く
+		comment := parakeet.SanitizeComment(strings.TrimSpace(mapping.Title))
+		if comment == components.Comment {
+			continue
+		}
+
+		components.Comment = comment
+		newName, err := components.FormatFileNameStrict()
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		plans = append(plans, renamePlan{From: fileName, To: newName})
+	}
+
+	if !opts.DryRun {
+		if err := applyRenames(opts.Hooks.Wrap(OSFileSystem), targetDir, plans); err != nil {
+			return nil, err
+		}
+		if err := opts.Journal.Record(targetDir, plans...); err != nil {
+			return nil, err
+		}
+		if err := opts.Vault.RewriteLinks(targetDir, plans...); err != nil {
+			return nil, err
+		}
+		if opts.Shims {
+			for _, plan := range plans {
+				if err := CreateShim(filepath.Join(targetDir, plan.From), filepath.Join(targetDir, plan.To)); err != nil {
+					reporter.Warnf("%s (%v)\n", plan.From, err)
+				}
+			}
+		}
+	}
+
+	prefix := ""
+	if opts.DryRun {
+		prefix = "[dry-run] "
+	}
+
+	result := &RetitleBatchResult{
+		Renamed: make(map[string]string),
+	}
+	for _, plan := range plans {
+		result.Renamed[plan.From] = plan.To
+		reporter.Emit("renamed", map[string]any{"from": plan.From, "to": plan.To, "dry_run": opts.DryRun}, "%s✓ Renamed: %s → %s\n", prefix, plan.From, plan.To)
+	}
+
+	// サマリーを出力
+	reporter.Printf("\nRetitle Summary:\n")
+	reporter.Printf("  Rows: %d\n", len(mappings))
+	reporter.Printf("  Files changed: %d\n", len(result.Renamed))
+
+	return result, nil
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetitleFile(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		fileName     string
+		title        string
+		expectedName string
+	}{
+		{
+			name:         "keep timestamp, tags and extension",
+			fileName:     "20250903T083109--old title__network_infra.pdf",
+			title:        "TCP/IP入門",
+			expectedName: "20250903T083109--TCP IP入門__network_infra.pdf",
+		},
+		{
+			name:         "file without tags",
+			fileName:     "20250903T083109--draft.md",
+			title:        "  final  ",
+			expectedName: "20250903T083109--final.md",
+		},
+		{
+			name:         "tag separator in title",
+			fileName:     "20250903T083109--draft__todo.md",
+			title:        "meeting__notes",
+			expectedName: "20250903T083109--meeting_notes__todo.md",
+		},
+		{
+			name:         "no change",
+			fileName:     "20250903T083109--same__todo.md",
+			title:        "same",
+			expectedName: "20250903T083109--same__todo.md",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir, err := os.MkdirTemp("", "parakeet-retitle-*")
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+			filePath := filepath.Join(tmpDir, tt.fileName)
+			require.NoError(t, os.WriteFile(filePath, []byte("test"), 0644))
+
+			buf := &bytes.Buffer{}
+			newPath, err := RetitleFile(filePath, tt.title, buf, nil)
+			require.NoError(t, err)
+
+			assert.Equal(t, filepath.Join(tmpDir, tt.expectedName), newPath)
+			assert.FileExists(t, newPath)
+			if tt.fileName != tt.expectedName {
+				assert.NoFileExists(t, filePath)
+				assert.Contains(t, buf.String(), "✓ Renamed:")
+			} else {
+				assert.Contains(t, buf.String(), "✓ No changes made")
+			}
+		})
+	}
+}
+
+func TestRetitleFile_Errors(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-retitle-errors-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	invalidPath := filepath.Join(tmpDir, "invalid-format.pdf")
+	require.NoError(t, os.WriteFile(invalidPath, []byte("test"), 0644))
+	validPath := filepath.Join(tmpDir, "20250903T083109--a.pdf")
+	require.NoError(t, os.WriteFile(validPath, []byte("test"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250903T083109--b.pdf"), []byte("test"), 0644))
+
+	tests := []struct {
+		name      string
+		filePath  string
+		title     string
+		errorText string
+	}{
+		{name: "empty title", filePath: validPath, title: "  ", errorText: "title cannot be empty"},
+		{name: "non existent file", filePath: "/non/existent/20250903T083109--a.pdf", title: "x", errorText: "does not exist"},
+		{name: "invalid format", filePath: invalidPath, title: "x", errorText: "not in correct format"},
+		{name: "target exists", filePath: validPath, title: "b", errorText: "target file already exists"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := RetitleFile(tt.filePath, tt.title, &bytes.Buffer{}, nil)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorText)
+		})
+	}
+	assert.FileExists(t, validPath)
+}
+
+func TestReadTitleMapping(t *testing.T) {
+	t.Parallel()
+
+	input := "id,title\n20250903T083109,TCP/IP入門\n20250903T083110, \"Notes, revised\"\n"
+	mappings, err := ReadTitleMapping(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, []TitleMapping{
+		{Line: 2, ID: "20250903T083109", Title: "TCP/IP入門"},
+		{Line: 3, ID: "20250903T083110", Title: "Notes, revised"},
+	}, mappings)
+
+	_, err = ReadTitleMapping(strings.NewReader("20250903T083109\n"))
+	assert.Error(t, err)
+}
+
+func setupRetitleBatchDir(t *testing.T) string {
+	t.Helper()
+
+	return newTestDir(t, map[string]string{
+		"20250903T083109--draft__network.pdf": "test",
+		"20250903T083110--memo.md":            "test",
+		"20250903T083111--same.txt":           "test",
+	})
+}
+
+func TestRetitleFromMapping(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupRetitleBatchDir(t)
+	journal := filepath.Join(tmpDir, "journal.jsonl")
+
+	mappings := []TitleMapping{
+		{ID: "20250903T083109", Title: "TCP/IP入門"},
+		{ID: "20250903T083110", Title: "meeting notes"},
+		{ID: "20250903T083111", Title: "same"},
+	}
+
+	buf := &bytes.Buffer{}
+	result, err := RetitleFromMapping(tmpDir, mappings, RetitleBatchOptions{Writer: buf, Journal: &Journal{Path: journal, Command: "retitle"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"20250903T083109--draft__network.pdf": "20250903T083109--TCP IP入門__network.pdf",
+		"20250903T083110--memo.md":            "20250903T083110--meeting notes.md",
+	}, result.Renamed)
+	for oldName, newName := range result.Renamed {
+		assert.NoFileExists(t, filepath.Join(tmpDir, oldName))
+		assert.FileExists(t, filepath.Join(tmpDir, newName))
+	}
+	assert.Contains(t, buf.String(), "Files changed: 2")
+
+	// ジャーナルに変更したファイルのみ記録される
+	data, err := os.ReadFile(journal)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"command":"retitle"`)
+	assert.Contains(t, lines[0], `"from":"20250903T083109--draft__network.pdf"`)
+}
+
+func TestRetitleFromMapping_DryRun(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupRetitleBatchDir(t)
+	journal := filepath.Join(tmpDir, "journal.jsonl")
+
+	buf := &bytes.Buffer{}
+	result, err := RetitleFromMapping(tmpDir, []TitleMapping{{ID: "20250903T083110", Title: "renamed"}}, RetitleBatchOptions{Writer: buf, DryRun: true, Journal: &Journal{Path: journal, Command: "retitle"}})
+	require.NoError(t, err)
+
+	assert.Len(t, result.Renamed, 1)
+	assert.FileExists(t, filepath.Join(tmpDir, "20250903T083110--memo.md"))
+	assert.NoFileExists(t, journal)
+	assert.Contains(t, buf.String(), "[dry-run]")
+}
+
+func TestRetitleFromMapping_Errors(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupRetitleBatchDir(t)
+
+	tests := []struct {
+		name      string
+		mappings  []TitleMapping
+		errorText string
+	}{
+		{
+			name:      "unknown ID",
+			mappings:  []TitleMapping{{Line: 2, ID: "20250903T083110", Title: "a"}, {Line: 3, ID: "20990101T000000", Title: "b"}},
+			errorText: "line 3: no file found with ID",
+		},
+		{
+			name:      "duplicate ID",
+			mappings:  []TitleMapping{{ID: "20250903T083110", Title: "a"}, {ID: "20250903T083110", Title: "b"}},
+			errorText: "duplicate ID",
+		},
+		{
+			name:      "empty title",
+			mappings:  []TitleMapping{{ID: "20250903T083110", Title: " "}},
+			errorText: "title cannot be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := RetitleFromMapping(tmpDir, tt.mappings, RetitleBatchOptions{Writer: &bytes.Buffer{}})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorText)
+		})
+	}
+
+	// エラーの場合はどのファイルも変更しない
+	assert.FileExists(t, filepath.Join(tmpDir, "20250903T083110--memo.md"))
+}
+
+func TestRetitleFile_UndoInSubdirectory(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-retitle-undo-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	// ID で再帰的に見つけたサブディレクトリのファイルをリネームする
+	subDir := filepath.Join(tmpDir, "sub")
+	require.NoError(t, os.MkdirAll(subDir, 0755))
+	filePath := filepath.Join(subDir, "20250903T083109--old__network.pdf")
+	require.NoError(t, os.WriteFile(filePath, []byte("test"), 0644))
+
+	found, err := FindFileByIDWithDepth(tmpDir, "20250903T083109", 2)
+	require.NoError(t, err)
+	newPath, err := RetitleFile(found, "new", &bytes.Buffer{}, nil)
+	require.NoError(t, err)
+	recordFileRename("retitle", found, newPath, &bytes.Buffer{})
+
+	// ジャーナルはファイルのディレクトリに記録し、そこで undo できる
+	assert.FileExists(t, JournalPath(subDir))
+	assert.NoFileExists(t, JournalPath(tmpDir))
+	_, err = UndoLastBatch(subDir, UndoOptions{Writer: &bytes.Buffer{}})
+	require.NoError(t, err)
+	assert.FileExists(t, filePath)
+	assert.NoFileExists(t, newPath)
+}
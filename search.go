@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// SearchOptions は検索操作のオプションを表す
+type SearchOptions struct {
+	Writer io.Writer // 出力先
+	FilterOptions
+	QueueFilter
+	SortOptions
+	Tags        []string // 検索するタグ
+	MatchAnyTag bool     // true の場合はいずれかのタグに一致（OR）、false の場合はすべてのタグに一致（AND）
+	Title       string   // タイトルの検索文字列（大文字小文字を区別しない部分一致）
+	TitleRegex  bool     // Title を正規表現として扱う
+}
+
+// SearchFiles はディレクトリ内のフォーマット済みファイルをタグとタイトルで検索する
+// 一致したファイル名を出力し、そのリストを返す
+func SearchFiles(targetDir string, opts SearchOptions) ([]string, error) {
+	// ディレクトリの存在チェック
+	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", targetDir)
+	}
+
+	// タイトルの条件を作成
+	matchTitle, err := newTitleMatcher(opts.Title, opts.TitleRegex)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := readDirCached(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("file", "file")
+
+	matches := []string{}
+	for _, entry := range entries {
+		// ディレクトリとシムはスキップ
+		if entry.IsDir() || IsShim(targetDir, entry) {
+			continue
+		}
+
+		fileName := entry.Name()
+
+		// 拡張子フィルタリング
+		if !opts.Matches(fileName) {
+			continue
+		}
+
+		// フォーマット済みファイルのみ処理
+		components, err := parseDirEntry(entry)
+		if err != nil {
+			continue
+		}
+
+		if !matchTags(components.Tags, opts.Tags, opts.MatchAnyTag) {
+			continue
+		}
+		if !opts.MatchesQueue(components.Tags) {
+			continue
+		}
+		if !matchTitle(components.Comment) {
+			continue
+		}
+
+		matches = append(matches, fileName)
+	}
+
+	if err := opts.SortFiles(matches); err != nil {
+		return nil, err
+	}
+	for _, fileName := range matches {
+		reporter.Emit("file", map[string]any{"file": fileName}, "%s\n", fileName)
+	}
+
+	return matches, nil
+}
+
+// newTitleMatcher はタイトルの一致判定関数を作成する
+// 検索文字列が空の場合はすべてに一致する
+func newTitleMatcher(query string, useRegex bool) (func(string) bool, error) {
+	if query == "" {
+		return func(string) bool { return true }, nil
+	}
+
+	if useRegex {
+		re, err := regexp.Compile("(?i)" + query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid title regex: %w", err)
+		}
+		return re.MatchString, nil
+	}
+
+	lowerQuery := strings.ToLower(query)
+	return func(title string) bool {
+		return strings.Contains(strings.ToLower(title), lowerQuery)
+	}, nil
+}
+
+// matchTags はファイルのタグが検索タグに一致するかチェックする
+// 検索タグが空の場合は常に true を返す
+func matchTags(fileTags, queryTags []string, matchAny bool) bool {
+	if len(queryTags) == 0 {
+		return true
+	}
+
+	tagSet := make(map[string]bool)
+	for _, tag := range fileTags {
+		tagSet[tag] = true
+	}
+
+	for _, tag := range queryTags {
+		if matchAny && tagSet[tag] {
+			return true
+		}
+		if !matchAny && !tagSet[tag] {
+			return false
+		}
+	}
+
+	return !matchAny
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchFiles(t *testing.T) {
+	t.Parallel()
+	// Create temporary directory
+	tmpDir, err := os.MkdirTemp("", "parakeet-search-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	testFiles := []string{
+		"20250903T083109--TCPIP入門__infra_network.pdf",
+		"20250903T083110--TCP Tuning__network.pdf",
+		"20250903T083111--Database Design__backend_database.pdf",
+		"20250903T083112--notes.md",
+		"invalid-file.pdf",
+	}
+	for _, name := range testFiles {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("test content"), 0644))
+	}
+
+	tests := []struct {
+		name     string
+		opts     SearchOptions
+		expected []string
+	}{
+		{
+			name:     "no conditions matches all formatted files",
+			opts:     SearchOptions{},
+			expected: testFiles[:4],
+		},
+		{
+			name:     "single tag",
+			opts:     SearchOptions{Tags: []string{"network"}},
+			expected: []string{testFiles[0], testFiles[1]},
+		},
+		{
+			name:     "multiple tags AND",
+			opts:     SearchOptions{Tags: []string{"network", "infra"}},
+			expected: []string{testFiles[0]},
+		},
+		{
+			name:     "multiple tags OR",
+			opts:     SearchOptions{Tags: []string{"infra", "database"}, MatchAnyTag: true},
+			expected: []string{testFiles[0], testFiles[2]},
+		},
+		{
+			name:     "case insensitive title substring",
+			opts:     SearchOptions{Title: "tcp"},
+			expected: []string{testFiles[0], testFiles[1]},
+		},
+		{
+			name:     "title regex",
+			opts:     SearchOptions{Title: "^tcp\\s", TitleRegex: true},
+			expected: []string{testFiles[1]},
+		},
+		{
+			name:     "tag and title",
+			opts:     SearchOptions{Tags: []string{"network"}, Title: "入門"},
+			expected: []string{testFiles[0]},
+		},
+		{
+			name:     "extension filter",
+			opts:     SearchOptions{FilterOptions: FilterOptions{Extensions: []string{"md"}}},
+			expected: []string{testFiles[3]},
+		},
+		{
+			name:     "no match",
+			opts:     SearchOptions{Tags: []string{"frontend"}},
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			buf := &bytes.Buffer{}
+			tt.opts.Writer = buf
+
+			matches, err := SearchFiles(tmpDir, tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, matches)
+			for _, match := range tt.expected {
+				assert.Contains(t, buf.String(), match)
+			}
+		})
+	}
+}
+
+func TestSearchFiles_Errors(t *testing.T) {
+	t.Parallel()
+	_, err := SearchFiles("/non/existent", SearchOptions{Writer: &bytes.Buffer{}})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "directory does not exist")
+
+	_, err = SearchFiles(os.TempDir(), SearchOptions{Writer: &bytes.Buffer{}, Title: "(", TitleRegex: true})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid title regex")
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+// shellCompletionFlag は補完スクリプトが候補を要求するときに最後に付けるフラグ（urfave/cli と同じ）
+const shellCompletionFlag = "--generate-shell-completion"
+
+// completionKind は補完する値の種類を表す
+type completionKind int
+
+const (
+	completeNothing completionKind = iota // 補完しない（シェルのファイル名の補完に任せる）
+	completeID                            // カレントディレクトリ（--dir）のファイルのID
+	completeTag                           // 使用中のタグと tags.toml に定義されたタグ
+)
+
+// completionSpec はコマンドの位置引数とフラグの値の補完方法を表す
+type completionSpec struct {
+	args     []completionKind // 位置引数ごとの補完の種類（最後の要素は以降のすべての位置引数に使う）
+	tagFlags []string         // 値としてタグを補完するフラグの名前（- を除く）
+}
+
+// kind は直前の単語と入力済みの位置引数の数から、補完する値の種類を返す
+func (s completionSpec) kind(prev string, position int) completionKind {
+	for _, flag := range s.tagFlags {
+		if prev == flagWithDashes(flag) {
+			return completeTag
+		}
+	}
+
+	if len(s.args) == 0 {
+		return completeNothing
+	}
+	return s.args[min(position, len(s.args)-1)]
+}
+
+// shellComplete は spec に従ってサブコマンド・フラグに加えてタグとIDを補完する関数を返す
+func shellComplete(spec completionSpec) cli.ShellCompleteFunc {
+	return func(_ context.Context, cmd *cli.Command) {
+		prev := lastCompletionWord(os.Args)
+		w := cmd.Root().Writer
+
+		if strings.HasPrefix(prev, "-") && !isBoolFlag(cmd, prev) {
+			switch {
+			case spec.kind(prev, 0) == completeTag:
+				// --set などの値
+				writeShellCompletions(w, completionCandidates(cmd, completeTag))
+			case isFlagName(cmd, prev):
+				// 他の値を取るフラグの値はシェルのファイル名の補完に任せる
+			default:
+				writeShellCompletions(w, flagCandidates(cmd, prev))
+			}
+			return
+		}
+
+		// サブコマンドと位置引数
+		candidates := commandCandidates(cmd, false)
+		candidates = append(candidates, completionCandidates(cmd, spec.kind(prev, cmd.Args().Len()))...)
+		writeShellCompletions(w, candidates)
+	}
+}
+
+// completeRoot はルートでグループに加えて従来のフラットなコマンド名も補完する
+func completeRoot(_ context.Context, cmd *cli.Command) {
+	prev := lastCompletionWord(os.Args)
+	if strings.HasPrefix(prev, "-") && !isBoolFlag(cmd, prev) {
+		if !isFlagName(cmd, prev) {
+			writeShellCompletions(cmd.Root().Writer, flagCandidates(cmd, prev))
+		}
+		return
+	}
+	writeShellCompletions(cmd.Root().Writer, commandCandidates(cmd, true))
+}
+
+// commandCandidates はサブコマンドの名前を補完候補として返す（includeHidden の場合は従来のフラットなコマンド名も含める）
+func commandCandidates(cmd *cli.Command, includeHidden bool) []Candidate {
+	seen := map[string]bool{}
+	candidates := []Candidate{}
+	for _, sub := range cmd.Commands {
+		if sub.Name == "help" || seen[sub.Name] || (sub.Hidden && !includeHidden) {
+			continue
+		}
+		seen[sub.Name] = true
+		candidates = append(candidates, Candidate{Value: sub.Name, Description: sub.Usage})
+	}
+	return candidates
+}
+
+// flagCandidates は入力途中のフラグに前方一致するフラグを補完候補として返す
+func flagCandidates(cmd *cli.Command, word string) []Candidate {
+	prefix := strings.TrimLeft(word, "-")
+	candidates := []Candidate{}
+	for _, flag := range cmd.Flags {
+		name := flag.Names()[0]
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		candidate := Candidate{Value: flagWithDashes(name)}
+		if doc, ok := flag.(cli.DocGenerationFlag); ok {
+			candidate.Description = doc.GetUsage()
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
+
+// lastCompletionWord は補完を要求したコマンドラインの最後の単語を返す
+func lastCompletionWord(args []string) string {
+	if len(args) > 0 && args[len(args)-1] == shellCompletionFlag {
+		args = args[:len(args)-1]
+	}
+	if len(args) < 2 {
+		return ""
+	}
+	return args[len(args)-1]
+}
+
+// isFlagName は word が値を取るフラグの名前と完全に一致するかどうかを返す
+func isFlagName(cmd *cli.Command, word string) bool {
+	flag := lookupFlag(cmd, word)
+	if flag == nil {
+		return false
+	}
+	_, isBool := flag.(*cli.BoolFlag)
+	return !isBool
+}
+
+// isBoolFlag は word が真偽値のフラグの名前と完全に一致するかどうかを返す（次の単語は位置引数になる）
+func isBoolFlag(cmd *cli.Command, word string) bool {
+	_, ok := lookupFlag(cmd, word).(*cli.BoolFlag)
+	return ok
+}
+
+// lookupFlag は word（1文字の名前は -s、それ以外は --set の形）と一致するフラグを返す
+func lookupFlag(cmd *cli.Command, word string) cli.Flag {
+	for _, flag := range cmd.Flags {
+		for _, n := range flag.Names() {
+			if word == flagWithDashes(n) {
+				return flag
+			}
+		}
+	}
+	return nil
+}
+
+// flagWithDashes はフラグの名前に - を付ける（1文字の名前は -s、それ以外は --set）
+func flagWithDashes(name string) string {
+	if len(name) == 1 {
+		return "-" + name
+	}
+	return "--" + name
+}
+
+// completionCandidates は種類に応じた補完候補を返す（エラーの場合は候補なし）
+func completionCandidates(cmd *cli.Command, kind completionKind) []Candidate {
+	dir := "."
+	if d := cmd.String("dir"); d != "" {
+		dir = d
+	}
+	tagsFile, err := tagsFileFromCommand(cmd, dir)
+	if err != nil {
+		return nil
+	}
+	opts := CompleteOptions{Writer: io.Discard, TagsFile: tagsFile}
+
+	var candidates []Candidate
+	switch kind {
+	case completeID:
+		candidates, err = CompleteIDs(dir, opts)
+	case completeTag:
+		candidates, err = CompleteTags(dir, opts)
+	}
+	if err != nil {
+		return nil
+	}
+	return candidates
+}
+
+// writeShellCompletions はシェルに合わせた書式で補完候補を出力する
+// zsh は「値:説明」、fish は「値<TAB>説明」、bash は値のみ
+func writeShellCompletions(w io.Writer, candidates []Candidate) {
+	shell := completionShell()
+	for _, c := range candidates {
+		switch {
+		case c.Description != "" && shell == "zsh":
+			_, _ = fmt.Fprintf(w, "%s:%s\n", strings.ReplaceAll(c.Value, ":", `\:`), c.Description)
+		case c.Description != "" && shell == "fish":
+			_, _ = fmt.Fprintf(w, "%s\t%s\n", c.Value, c.Description)
+		default:
+			_, _ = fmt.Fprintln(w, c.Value)
+		}
+	}
+}
+
+// completionShell は補完を要求したシェルを返す
+// fish の補完スクリプトは PARAKEET_COMPLETION_SHELL を設定する。それ以外は urfave/cli と同じく SHELL で判定する
+func completionShell() string {
+	if shell := os.Getenv("PARAKEET_COMPLETION_SHELL"); shell != "" {
+		return shell
+	}
+	if strings.HasSuffix(os.Getenv("SHELL"), "zsh") {
+		return "zsh"
+	}
+	return "bash"
+}
+
+// fishCompletionScript は実行時に候補を問い合わせる fish の補完スクリプト
+// urfave/cli の fish のスクリプトは静的なため、タグとIDを補完できるように置き換える
+const fishCompletionScript = `# parakeet の fish の補完スクリプト
+# parakeet completion fish > ~/.config/fish/completions/parakeet.fish
+
+function __%[1]s_complete
+    set -l args (commandline -opc)
+    set -l current (commandline -ct)
+    if string match -q -- '-*' $current
+        set args $args $current
+    end
+    env PARAKEET_COMPLETION_SHELL=fish $args %[2]s 2>/dev/null
+end
+
+complete -c %[1]s -a '(__%[1]s_complete)'
+`
+
+// configureCompletionCommand は urfave/cli の completion コマンドを表示し、fish のスクリプトを置き換える
+func configureCompletionCommand(cmd *cli.Command) {
+	cmd.Hidden = false
+	cmd.Usage = "bash, zsh, fish の補完スクリプトを出力する（サブコマンド・フラグに加えてタグとIDを補完する）"
+	cmd.ArgsUsage = "<bash|zsh|fish|pwsh>"
+
+	action := cmd.Action
+	cmd.Action = func(ctx context.Context, c *cli.Command) error {
+		if c.Args().First() == "fish" {
+			_, err := fmt.Fprintf(c.Root().Writer, fishCompletionScript, c.Root().Name, shellCompletionFlag)
+			return err
+		}
+		return action(ctx, c)
+	}
+}
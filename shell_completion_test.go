@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v3"
+)
+
+func TestCompletionSpec_Kind(t *testing.T) {
+	t.Parallel()
+
+	spec := completionSpec{args: []completionKind{completeTag, completeID}, tagFlags: []string{"tag", "t"}}
+	assert.Equal(t, completeTag, spec.kind("add", 0))
+	assert.Equal(t, completeID, spec.kind("network", 1))
+	assert.Equal(t, completeID, spec.kind("20250903T083109", 3))
+	assert.Equal(t, completeTag, spec.kind("--tag", 2))
+	assert.Equal(t, completeTag, spec.kind("-t", 2))
+	// 1文字の名前は - が1つのみ
+	assert.Equal(t, completeID, spec.kind("--t", 2))
+
+	assert.Equal(t, completeNothing, completionSpec{}.kind("list", 0))
+}
+
+func TestLastCompletionWord(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "--set", lastCompletionWord([]string{"parakeet", "tag", "--set", shellCompletionFlag}))
+	assert.Equal(t, "tag", lastCompletionWord([]string{"parakeet", "tag", shellCompletionFlag}))
+	assert.Equal(t, "", lastCompletionWord([]string{"parakeet", shellCompletionFlag}))
+}
+
+func TestCompletionCandidates_CommandsAndFlags(t *testing.T) {
+	t.Parallel()
+
+	cmd := &cli.Command{
+		Name: "tag",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{Name: "show", Aliases: []string{"s"}, Usage: "現在のタグを表示する"},
+			&cli.StringSliceFlag{Name: "set", Aliases: []string{"t"}},
+		},
+		Commands: []*cli.Command{
+			{Name: "add", Usage: "タグを追加する"},
+			{Name: "legacy", Hidden: true},
+		},
+	}
+
+	assert.Equal(t, []Candidate{{Value: "--show", Description: "現在のタグを表示する"}, {Value: "--set"}}, flagCandidates(cmd, "--s"))
+	assert.Equal(t, []Candidate{{Value: "add", Description: "タグを追加する"}}, commandCandidates(cmd, false))
+	assert.Len(t, commandCandidates(cmd, true), 2)
+
+	assert.True(t, isBoolFlag(cmd, "-s"))
+	assert.True(t, isFlagName(cmd, "--set"))
+	assert.False(t, isFlagName(cmd, "--show"))
+	assert.False(t, isFlagName(cmd, "--se"))
+}
+
+func TestWriteShellCompletions(t *testing.T) {
+	candidates := []Candidate{{Value: "network", Description: "ネットワーク関連"}, {Value: "infra"}}
+
+	t.Setenv("PARAKEET_COMPLETION_SHELL", "zsh")
+	var buf bytes.Buffer
+	writeShellCompletions(&buf, candidates)
+	assert.Equal(t, "network:ネットワーク関連\ninfra\n", buf.String())
+
+	t.Setenv("PARAKEET_COMPLETION_SHELL", "fish")
+	buf.Reset()
+	writeShellCompletions(&buf, candidates)
+	assert.Equal(t, "network\tネットワーク関連\ninfra\n", buf.String())
+
+	t.Setenv("PARAKEET_COMPLETION_SHELL", "bash")
+	buf.Reset()
+	writeShellCompletions(&buf, candidates)
+	assert.Equal(t, "network\ninfra\n", buf.String())
+}
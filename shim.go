@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// DefaultShimGracePeriod は clean-shims で削除するシムの経過時間のデフォルト
+const DefaultShimGracePeriod = 30 * 24 * time.Hour
+
+// CreateShim は旧ファイル名から新しいファイルへの相対シンボリックリンク（互換用のシム）を作成する
+// 移行期間中も旧ファイル名への外部からの参照が解決できるようにする
+func CreateShim(oldPath, newPath string) error {
+	return createShim(OSFileSystem, oldPath, newPath)
+}
+
+// createShim は fsys 上にシムを作成する
+func createShim(fsys FileSystem, oldPath, newPath string) error {
+	target, err := filepath.Rel(filepath.Dir(oldPath), newPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve shim target: %w", err)
+	}
+
+	if err := fsys.Symlink(target, oldPath); err != nil {
+		return fmt.Errorf("failed to create shim: %w", err)
+	}
+
+	return nil
+}
+
+// IsShim はディレクトリ内のエントリがシム（フォーマット済みファイルへの相対シンボリックリンク）かどうかを返す
+func IsShim(dirPath string, entry os.DirEntry) bool {
+	return isShim(OSFileSystem, dirPath, entry)
+}
+
+// isShim は fsys 上のエントリがシムかどうかを返す
+func isShim(fsys FileSystem, dirPath string, entry os.DirEntry) bool {
+	if entry.Type()&os.ModeSymlink == 0 {
+		return false
+	}
+
+	target, err := fsys.Readlink(filepath.Join(dirPath, entry.Name()))
+	if err != nil || filepath.IsAbs(target) {
+		return false
+	}
+
+	return parakeet.IsFormatted(filepath.Base(target))
+}
+
+// CleanShimsOptions はシムの削除操作のオプションを表す
+type CleanShimsOptions struct {
+	Writer    io.Writer     // 出力先
+	OlderThan time.Duration // この時間より前に作成したシムのみ削除する（リンク先がないシムは常に削除する）
+	DryRun    bool          // 実際には削除しない
+}
+
+// CleanShimsResult はシムの削除操作の結果を表す
+type CleanShimsResult struct {
+	Removed []string // 削除したシム
+	Kept    []string // 猶予期間中のため残したシム
+}
+
+// CleanShims はディレクトリ内の猶予期間を過ぎたシムとリンク先のないシムを削除する
+func CleanShims(targetDir string, opts CleanShimsOptions) (*CleanShimsResult, error) {
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("removed", "file", "dangling", "dry_run")
+
+	// ディレクトリの存在チェック
+	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", targetDir)
+	}
+
+	entries, err := os.ReadDir(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	prefix := ""
+	if opts.DryRun {
+		prefix = "[dry-run] "
+	}
+
+	result := &CleanShimsResult{
+		Removed: []string{},
+		Kept:    []string{},
+	}
+	now := time.Now()
+	for _, entry := range entries {
+		if !IsShim(targetDir, entry) {
+			continue
+		}
+
+		shimPath := filepath.Join(targetDir, entry.Name())
+		info, err := os.Lstat(shimPath)
+		if err != nil {
+			return result, fmt.Errorf("failed to get file info: %w", err)
+		}
+
+		// リンク先がないシムは猶予期間に関係なく削除する
+		_, statErr := os.Stat(shimPath)
+		dangling := os.IsNotExist(statErr)
+		if !dangling && now.Sub(info.ModTime()) < opts.OlderThan {
+			result.Kept = append(result.Kept, entry.Name())
+			reporter.Verbosef("%s (within grace period, kept)\n", entry.Name())
+			continue
+		}
+
+		if !opts.DryRun {
+			if err := os.Remove(shimPath); err != nil {
+				return result, fmt.Errorf("failed to remove shim: %w", err)
+			}
+		}
+
+		result.Removed = append(result.Removed, entry.Name())
+		reporter.Emit("removed", map[string]any{"file": entry.Name(), "dangling": dangling, "dry_run": opts.DryRun}, "%s✓ Removed: %s\n", prefix, entry.Name())
+	}
+
+	// サマリーを出力
+	reporter.Printf("\nClean Shims Summary:\n")
+	reporter.Printf("  Removed: %d\n", len(result.Removed))
+	reporter.Printf("  Kept: %d\n", len(result.Kept))
+
+	return result, nil
+}
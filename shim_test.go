@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateShim(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-shim-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	newPath := filepath.Join(tmpDir, "20250903T083109--report.pdf")
+	require.NoError(t, os.WriteFile(newPath, []byte("test"), 0644))
+	oldPath := filepath.Join(tmpDir, "report.pdf")
+
+	require.NoError(t, CreateShim(oldPath, newPath))
+
+	// 相対パスのシンボリックリンクになる
+	target, err := os.Readlink(oldPath)
+	require.NoError(t, err)
+	assert.Equal(t, "20250903T083109--report.pdf", target)
+
+	content, err := os.ReadFile(oldPath)
+	require.NoError(t, err)
+	assert.Equal(t, "test", string(content))
+
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	shims := []string{}
+	for _, entry := range entries {
+		if IsShim(tmpDir, entry) {
+			shims = append(shims, entry.Name())
+		}
+	}
+	assert.Equal(t, []string{"report.pdf"}, shims)
+
+	// 同じ名前のファイルがある場合はエラー
+	assert.Error(t, CreateShim(newPath, newPath))
+}
+
+func TestCleanShims(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-shim-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	for _, name := range []string{"20250903T083109--old.pdf", "20250903T083110--new.pdf"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("test"), 0644))
+	}
+	require.NoError(t, os.Symlink("20250903T083109--old.pdf", filepath.Join(tmpDir, "old.pdf")))
+	require.NoError(t, os.Symlink("20250903T083110--new.pdf", filepath.Join(tmpDir, "new.pdf")))
+	require.NoError(t, os.Symlink("20250903T083111--gone.pdf", filepath.Join(tmpDir, "gone.pdf")))
+	// フォーマット外のファイルへのリンクはシムではない
+	require.NoError(t, os.Symlink("notes.txt", filepath.Join(tmpDir, "link.txt")))
+
+	t.Run("dangling shims only within grace period", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		result, err := CleanShims(tmpDir, CleanShimsOptions{Writer: buf, OlderThan: time.Hour, DryRun: true})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"gone.pdf"}, result.Removed)
+		assert.Equal(t, []string{"new.pdf", "old.pdf"}, result.Kept)
+		assert.Contains(t, buf.String(), "[dry-run] ✓ Removed: gone.pdf")
+
+		_, err = os.Lstat(filepath.Join(tmpDir, "gone.pdf"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("remove expired shims", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		result, err := CleanShims(tmpDir, CleanShimsOptions{Writer: buf})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"gone.pdf", "new.pdf", "old.pdf"}, result.Removed)
+		assert.Contains(t, buf.String(), "Removed: 3")
+
+		for _, name := range []string{"gone.pdf", "new.pdf", "old.pdf"} {
+			_, err = os.Lstat(filepath.Join(tmpDir, name))
+			assert.True(t, os.IsNotExist(err), name)
+		}
+		for _, name := range []string{"link.txt", "20250903T083109--old.pdf", "20250903T083110--new.pdf"} {
+			_, err = os.Lstat(filepath.Join(tmpDir, name))
+			assert.NoError(t, err, name)
+		}
+	})
+}
+
+func TestCleanShims_NonExistentDirectory(t *testing.T) {
+	t.Parallel()
+	_, err := CleanShims("/nonexistent/directory", CleanShimsOptions{Writer: &bytes.Buffer{}})
+	assert.Error(t, err)
+}
+
+func TestGenerateFileNames_Shims(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-shim-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "report.pdf"), []byte("test"), 0644))
+
+	opts := RenameOptions{Writer: &bytes.Buffer{}, FilterOptions: FilterOptions{Extensions: []string{"pdf"}}, Shims: true}
+	require.NoError(t, GenerateFileNames(context.Background(), tmpDir, opts))
+
+	shim := filepath.Join(tmpDir, "report.pdf")
+	target, err := os.Readlink(shim)
+	require.NoError(t, err)
+	assert.True(t, parakeet.IsFormatted(target))
+
+	// 2回目の実行ではシムをリネームしない
+	require.NoError(t, GenerateFileNames(context.Background(), tmpDir, opts))
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	// シムは検証の対象外
+	invalid, err := GetInvalidFiles(tmpDir)
+	require.NoError(t, err)
+	assert.Empty(t, invalid)
+}
+
+func TestRetitleFromMapping_Shims(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-shim-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250903T083109--draft__todo.md"), []byte("test"), 0644))
+
+	mappings := []TitleMapping{{ID: "20250903T083109", Title: "final"}}
+	_, err = RetitleFromMapping(tmpDir, mappings, RetitleBatchOptions{Writer: &bytes.Buffer{}, Shims: true})
+	require.NoError(t, err)
+
+	target, err := os.Readlink(filepath.Join(tmpDir, "20250903T083109--draft__todo.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "20250903T083109--final__todo.md", target)
+
+	// シムはIDの検索で無視される
+	filePath, err := FindFileByID(tmpDir, "20250903T083109")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(tmpDir, "20250903T083109--final__todo.md"), filePath)
+}
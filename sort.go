@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// 並び順
+const (
+	SortByID        = "id"        // タイムスタンプ順（デフォルト）
+	SortByTimestamp = "timestamp" // タイムスタンプ順（id と同じ）
+	SortByTitle     = "title"     // タイトル順
+	SortByExt       = "ext"       // 拡張子順
+)
+
+// タイトルの照合順序
+const (
+	CollationBytes    = "bytes"   // バイト列の順（UTF-8のコードポイント順）
+	CollationUnicode  = "unicode" // Unicode照合アルゴリズム（言語に依存しない順序）
+	CollationJapanese = "ja"      // 日本語の照合順序
+)
+
+// SortOptions は一覧出力の並び順を表す
+// 各コマンドのオプションに埋め込んで使う
+type SortOptions struct {
+	SortBy    string // 並び順（id, timestamp, title, ext、空の場合は id）
+	Collation string // タイトル順のときの照合順序（bytes, unicode, ja、空の場合は unicode）
+	Reverse   bool   // 逆順にする
+}
+
+// Validate は並び順と照合順序の指定をチェックする
+func (o SortOptions) Validate() error {
+	switch o.SortBy {
+	case "", SortByID, SortByTimestamp, SortByTitle, SortByExt:
+	default:
+		return fmt.Errorf("unknown sort key: %s (expected id, timestamp, title or ext)", o.SortBy)
+	}
+
+	_, err := newTitleComparer(o.Collation)
+	return err
+}
+
+// SortFiles はフォーマット済みファイル名を指定した順に並べ替える
+// タイトル・拡張子が同じファイルはタイムスタンプ順に並べる
+func (o SortOptions) SortFiles(files []string) error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
+
+	switch o.SortBy {
+	case SortByTitle:
+		compare, err := newTitleComparer(o.Collation)
+		if err != nil {
+			return err
+		}
+		sortFilesBy(files, func(c parakeet.FileNameComponents) string { return c.Comment }, compare)
+	case SortByExt:
+		sortFilesBy(files, func(c parakeet.FileNameComponents) string { return c.Extension }, strings.Compare)
+	default:
+		sort.Strings(files)
+	}
+
+	if o.Reverse {
+		slices.Reverse(files)
+	}
+
+	return nil
+}
+
+// sortFilesBy はファイル名の要素で並べ替え、要素が同じファイルはファイル名（タイムスタンプ）順に並べる
+func sortFilesBy(files []string, key func(parakeet.FileNameComponents) string, compare func(a, b string) int) {
+	keys := make(map[string]string, len(files))
+	for _, file := range files {
+		if components, err := parakeet.ParseFileName(file); err == nil {
+			keys[file] = key(*components)
+		}
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		if c := compare(keys[files[i]], keys[files[j]]); c != 0 {
+			return c < 0
+		}
+		return files[i] < files[j]
+	})
+}
+
+// newTitleComparer は照合順序に応じたタイトルの比較関数を返す
+func newTitleComparer(collation string) (func(a, b string) int, error) {
+	var tag language.Tag
+	switch collation {
+	case CollationBytes:
+		return strings.Compare, nil
+	case "", CollationUnicode:
+		tag = language.Und
+	case CollationJapanese:
+		tag = language.Japanese
+	default:
+		return nil, fmt.Errorf("unknown collation: %s (expected bytes, unicode or ja)", collation)
+	}
+
+	collator := collate.New(tag)
+	return collator.CompareString, nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortOptions_SortFiles(t *testing.T) {
+	t.Parallel()
+
+	files := []string{
+		"20250101T000000--入門__go.md",
+		"20250102T000000--zeta.md",
+		"20250103T000000--ABC.md",
+		"20250104T000000--abc.md",
+		"20250105T000000--いろは.md",
+	}
+
+	tests := []struct {
+		name     string
+		opts     SortOptions
+		expected []string
+	}{
+		{
+			name:     "id",
+			opts:     SortOptions{},
+			expected: files,
+		},
+		{
+			name: "title bytes",
+			opts: SortOptions{SortBy: SortByTitle, Collation: CollationBytes},
+			expected: []string{
+				"20250103T000000--ABC.md",
+				"20250104T000000--abc.md",
+				"20250102T000000--zeta.md",
+				"20250105T000000--いろは.md",
+				"20250101T000000--入門__go.md",
+			},
+		},
+		{
+			name: "title unicode",
+			opts: SortOptions{SortBy: SortByTitle},
+			expected: []string{
+				"20250104T000000--abc.md",
+				"20250103T000000--ABC.md",
+				"20250102T000000--zeta.md",
+				"20250105T000000--いろは.md",
+				"20250101T000000--入門__go.md",
+			},
+		},
+		{
+			name: "title ja",
+			opts: SortOptions{SortBy: SortByTitle, Collation: CollationJapanese},
+			expected: []string{
+				"20250104T000000--abc.md",
+				"20250103T000000--ABC.md",
+				"20250102T000000--zeta.md",
+				"20250105T000000--いろは.md",
+				"20250101T000000--入門__go.md",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			sorted := []string{files[4], files[2], files[0], files[3], files[1]}
+			require.NoError(t, tt.opts.SortFiles(sorted))
+			assert.Equal(t, tt.expected, sorted)
+		})
+	}
+}
+
+func TestSortOptions_Validate(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, SortOptions{}.Validate())
+	assert.NoError(t, SortOptions{SortBy: SortByTitle, Collation: CollationJapanese}.Validate())
+	assert.Error(t, SortOptions{SortBy: "size"}.Validate())
+	assert.Error(t, SortOptions{Collation: "fr"}.Validate())
+}
+
+func TestListFilesAndMarkdown_SortByTitle(t *testing.T) {
+	t.Parallel()
+
+	tmpDir, err := os.MkdirTemp("", "parakeet-sort-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	for _, name := range []string{"20250101T000000--入門.md", "20250102T000000--zeta.md", "20250103T000000--Alpha.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("test content"), 0644))
+	}
+
+	sortOpts := SortOptions{SortBy: SortByTitle}
+	files, err := ListFiles(tmpDir, ListOptions{Writer: &bytes.Buffer{}, SortOptions: sortOpts})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"20250103T000000--Alpha.md", "20250102T000000--zeta.md", "20250101T000000--入門.md"}, files)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, GenerateMarkdownTable(tmpDir, MarkdownOptions{Writer: buf, SortOptions: sortOpts}))
+	expected := "| ID | Title | Tags |\n" +
+		"|---|---|---|\n" +
+		"| 20250103T000000 | Alpha |  |\n" +
+		"| 20250102T000000 | zeta |  |\n" +
+		"| 20250101T000000 | 入門 |  |\n"
+	assert.Equal(t, expected, buf.String())
+
+	// 不正な並び順はヘッダーを出力する前にエラーになる
+	buf.Reset()
+	err = GenerateMarkdownTable(tmpDir, MarkdownOptions{Writer: buf, SortOptions: SortOptions{SortBy: "size"}})
+	assert.Error(t, err)
+	assert.Empty(t, buf.String())
+}
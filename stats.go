@@ -0,0 +1,379 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// 増加傾向の出力形式
+const (
+	TrendTable     = "table"     // 月ごとの表（デフォルト）
+	TrendSparkline = "sparkline" // 月ごとの追加数と容量をスパークラインで表示
+	TrendCSV       = "csv"       // 月ごとの集計をCSVで出力
+)
+
+// sparkBlocks はスパークラインに使う文字（低い順）
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// StatsOptions は統計表示操作のオプションを表す
+type StatsOptions struct {
+	Writer io.Writer // 出力先
+	FilterOptions
+	Trend string      // 増加傾向の出力形式（table, sparkline, csv、空の場合は table）
+	Quota QuotaConfig // ディレクトリ・タグごとの上限（超えた場合は警告する）
+	JSON  bool        // 集計結果を1つの JSON オブジェクトとして出力する（ダッシュボード向け）
+}
+
+// MonthStat は1か月分の集計を表す
+type MonthStat struct {
+	Month           string `json:"month"`            // 対象月（2006-01 形式）
+	Added           int    `json:"added"`            // その月のタイムスタンプを持つファイル数
+	Bytes           int64  `json:"bytes"`            // その月に追加されたファイルの合計サイズ
+	Cumulative      int    `json:"cumulative"`       // その月までの累計ファイル数
+	CumulativeBytes int64  `json:"cumulative_bytes"` // その月までの累計サイズ
+}
+
+// CountStat は拡張子・タグごとの集計を表す
+type CountStat struct {
+	Name  string `json:"name"`  // 拡張子またはタグ
+	Files int    `json:"files"` // ファイル数
+	Bytes int64  `json:"bytes"` // 合計サイズ
+}
+
+// StatsResult は統計の集計結果を表す
+type StatsResult struct {
+	Files       int         `json:"files"`       // 対象ファイル数（フォーマット済み）
+	Unformatted int         `json:"unformatted"` // フォーマット外のファイル数
+	Bytes       int64       `json:"bytes"`       // 対象ファイルの合計サイズ
+	Months      []MonthStat `json:"months"`      // 最初の月から最後の月まで、ファイルのない月も含めた月ごとの集計
+	Extensions  []CountStat `json:"extensions"`  // 拡張子ごとの集計（ファイル数の多い順）
+	Tags        []CountStat `json:"tags"`        // タグごとの集計（ステータス・優先度タグを除く、ファイル数の多い順）
+	Statuses    []CountStat `json:"statuses"`    // ステータスタグ（todo, doing, done）ごとの集計（ファイル数の多い順）
+	Priorities  []CountStat `json:"priorities"`  // 優先度タグ（p1〜p9、複数ある場合は最も高いもの）ごとの集計（ファイル数の多い順）
+	// QuotaExceeded は上限を超えたディレクトリとタグ
+	QuotaExceeded []QuotaExceeded `json:"quota_exceeded,omitempty"`
+}
+
+// CollectStats はフォーマット済みファイルをタイムスタンプの月ごとに集計する
+func CollectStats(targetDir string, opts StatsOptions) (*StatsResult, error) {
+	// ディレクトリの存在チェック
+	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", targetDir)
+	}
+
+	entries, err := os.ReadDir(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	added := make(map[string]int)
+	bytes := make(map[string]int64)
+	extensions := make(map[string]*CountStat)
+	tags := make(map[string]*CountStat)
+	statuses := make(map[string]*CountStat)
+	priorities := make(map[string]*CountStat)
+	var first, last time.Time
+
+	result := &StatsResult{Months: []MonthStat{}, Extensions: []CountStat{}, Tags: []CountStat{}, Statuses: []CountStat{}, Priorities: []CountStat{}}
+	if result.QuotaExceeded, err = CheckQuotas(targetDir, opts.Quota); err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || IsShim(targetDir, entry) {
+			continue
+		}
+
+		fileName := entry.Name()
+		if !opts.Matches(fileName) {
+			continue
+		}
+
+		components, err := parakeet.ParseFileName(fileName)
+		if err != nil {
+			result.Unformatted++
+			continue
+		}
+
+		t, err := time.Parse(timestampLayout, components.Timestamp)
+		if err != nil {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get file info: %w", err)
+		}
+
+		month := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		if first.IsZero() || month.Before(first) {
+			first = month
+		}
+		if last.IsZero() || month.After(last) {
+			last = month
+		}
+
+		key := month.Format("2006-01")
+		added[key]++
+		bytes[key] += info.Size()
+		result.Files++
+		result.Bytes += info.Size()
+
+		countStat(extensions, strings.ToLower(components.Extension), info.Size())
+		for _, tag := range components.Tags {
+			if !isQueueTag(tag) {
+				countStat(tags, tag, info.Size())
+			}
+		}
+		if status := TagStatus(components.Tags); status != StatusNone {
+			countStat(statuses, status, info.Size())
+		}
+		if priority := TagPriority(components.Tags); priority > 0 {
+			countStat(priorities, fmt.Sprintf("p%d", priority), info.Size())
+		}
+	}
+
+	result.Extensions = sortedCountStats(extensions)
+	result.Tags = sortedCountStats(tags)
+	result.Statuses = sortedCountStats(statuses)
+	result.Priorities = sortedCountStats(priorities)
+
+	if result.Files == 0 {
+		return result, nil
+	}
+
+	// ファイルのない月も含めて累計を計算する
+	cumulative := 0
+	var cumulativeBytes int64
+	for month := first; !month.After(last); month = month.AddDate(0, 1, 0) {
+		key := month.Format("2006-01")
+		cumulative += added[key]
+		cumulativeBytes += bytes[key]
+		result.Months = append(result.Months, MonthStat{
+			Month:           key,
+			Added:           added[key],
+			Bytes:           bytes[key],
+			Cumulative:      cumulative,
+			CumulativeBytes: cumulativeBytes,
+		})
+	}
+
+	return result, nil
+}
+
+// countStat は名前ごとの集計にファイルを1件加える
+func countStat(stats map[string]*CountStat, name string, size int64) {
+	stat, ok := stats[name]
+	if !ok {
+		stat = &CountStat{Name: name}
+		stats[name] = stat
+	}
+	stat.Files++
+	stat.Bytes += size
+}
+
+// sortedCountStats は名前ごとの集計をファイル数の多い順（同数の場合は名前順）に並べる
+func sortedCountStats(stats map[string]*CountStat) []CountStat {
+	sorted := make([]CountStat, 0, len(stats))
+	for _, stat := range stats {
+		sorted = append(sorted, *stat)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Files != sorted[j].Files {
+			return sorted[i].Files > sorted[j].Files
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+// ShowStats はディレクトリの統計を集計し、指定した形式で出力する
+func ShowStats(targetDir string, opts StatsOptions) (*StatsResult, error) {
+	switch opts.Trend {
+	case "", TrendTable, TrendSparkline, TrendCSV:
+	default:
+		return nil, fmt.Errorf("unknown trend format: %s (expected table, sparkline or csv)", opts.Trend)
+	}
+
+	result, err := CollectStats(targetDir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	reporter := ReporterFor(opts.Writer)
+	if err := rejectTableFormat(reporter, "stats"); err != nil {
+		return nil, err
+	}
+
+	if opts.JSON {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode stats: %w", err)
+		}
+		var fields map[string]any
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, fmt.Errorf("failed to encode stats: %w", err)
+		}
+		reporter.Emit("stats", fields, "%s\n", data)
+		return result, nil
+	}
+
+	switch opts.Trend {
+	case TrendCSV:
+		reporter.Dataf("month,added,cumulative,bytes,cumulative_bytes\n")
+		for _, m := range result.Months {
+			reporter.Emit("month", monthFields(m), "%s,%d,%d,%d,%d\n", m.Month, m.Added, m.Cumulative, m.Bytes, m.CumulativeBytes)
+		}
+		return result, nil
+	case TrendSparkline:
+		if len(result.Months) > 0 {
+			added := make([]int64, len(result.Months))
+			bytes := make([]int64, len(result.Months))
+			for i, m := range result.Months {
+				added[i] = int64(m.Added)
+				bytes[i] = m.Bytes
+			}
+			period := result.Months[0].Month + ".." + result.Months[len(result.Months)-1].Month
+			reporter.Emit("trend", map[string]any{"metric": "added", "period": period, "sparkline": Sparkline(added)}, "Added %s %s\n", period, Sparkline(added))
+			reporter.Emit("trend", map[string]any{"metric": "bytes", "period": period, "sparkline": Sparkline(bytes)}, "Bytes %s %s\n", period, Sparkline(bytes))
+		}
+	default:
+		if len(result.Months) > 0 {
+			reporter.Dataf("%-7s %6s %10s %10s %10s\n", "Month", "Added", "Cumulative", "Size", "Total size")
+		}
+		for _, m := range result.Months {
+			reporter.Emit("month", monthFields(m), "%-7s %6d %10d %10s %10s\n", m.Month, m.Added, m.Cumulative, FormatBytes(m.Bytes), FormatBytes(m.CumulativeBytes))
+		}
+	}
+
+	// 拡張子・タグごとの集計を出力
+	if opts.Trend != TrendSparkline {
+		reportCountStats(reporter, "extension", "Extension", result.Extensions)
+		reportCountStats(reporter, "tag", "Tag", result.Tags)
+		reportCountStats(reporter, "status", "Status", result.Statuses)
+		reportCountStats(reporter, "priority", "Priority", result.Priorities)
+	}
+
+	// サマリーを出力
+	reporter.Printf("\nStats Summary:\n")
+	reporter.Printf("  Files: %d\n", result.Files)
+	reporter.Printf("  Unformatted: %d\n", result.Unformatted)
+	reporter.Printf("  Size: %s\n", FormatBytes(result.Bytes))
+	if len(result.Months) > 0 {
+		reporter.Printf("  Months: %d (%s - %s)\n", len(result.Months), result.Months[0].Month, result.Months[len(result.Months)-1].Month)
+		reporter.Printf("  Average per month: %.1f files\n", float64(result.Files)/float64(len(result.Months)))
+	}
+
+	if len(result.QuotaExceeded) > 0 {
+		reporter.Printf("\n")
+		reportQuotas(reporter, result.QuotaExceeded)
+	}
+
+	return result, nil
+}
+
+// reportCountStats は拡張子・タグごとの集計を表で出力する
+func reportCountStats(reporter Reporter, event, header string, stats []CountStat) {
+	if len(stats) == 0 {
+		return
+	}
+
+	width := len(header)
+	for _, stat := range stats {
+		width = max(width, displayWidth(stat.Name))
+	}
+
+	reporter.Dataf("\n%s%s %6s %10s\n", header, strings.Repeat(" ", width-len(header)), "Files", "Size")
+	for _, stat := range stats {
+		reporter.Emit(event, map[string]any{"name": stat.Name, "files": stat.Files, "bytes": stat.Bytes},
+			"%s%s %6d %10s\n", stat.Name, strings.Repeat(" ", width-displayWidth(stat.Name)), stat.Files, FormatBytes(stat.Bytes))
+	}
+}
+
+// monthFields は月ごとの集計を構造化出力のフィールドに変換する
+func monthFields(m MonthStat) map[string]any {
+	return map[string]any{
+		"month":            m.Month,
+		"added":            m.Added,
+		"cumulative":       m.Cumulative,
+		"bytes":            m.Bytes,
+		"cumulative_bytes": m.CumulativeBytes,
+	}
+}
+
+// Sparkline は値の列を最大値を基準にしたスパークラインに変換する
+// 値が0の要素は最も低い文字で表す
+func Sparkline(values []int64) string {
+	var peak int64
+	for _, v := range values {
+		if v > peak {
+			peak = v
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		level := 0
+		if peak > 0 {
+			level = int(v * int64(len(sparkBlocks)-1) / peak)
+		}
+		b.WriteRune(sparkBlocks[level])
+	}
+	return b.String()
+}
+
+// FormatBytes はバイト数を人間が読みやすい単位（B, KiB, MiB, ...）に変換する
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// byteUnits は ParseByteSize で使える単位（長い接尾辞から順に照合する）
+var byteUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"TiB", 1 << 40},
+	{"GiB", 1 << 30},
+	{"MiB", 1 << 20},
+	{"KiB", 1 << 10},
+	{"B", 1},
+}
+
+// ParseByteSize は 500MiB, 1.5GiB のような単位付きのサイズをバイト数に変換する
+// 単位を省略した場合はバイト数とみなす
+func ParseByteSize(s string) (int64, error) {
+	number := strings.TrimSpace(s)
+	size := int64(1)
+	for _, unit := range byteUnits {
+		if strings.HasSuffix(number, unit.suffix) {
+			number = strings.TrimSpace(strings.TrimSuffix(number, unit.suffix))
+			size = unit.size
+			break
+		}
+	}
+
+	v, err := strconv.ParseFloat(number, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid size: %q (e.g. 500MiB, 2GiB)", s)
+	}
+
+	return int64(v * float64(size)), nil
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupStatsDir(t *testing.T) string {
+	t.Helper()
+
+	return newTestDir(t, map[string]string{
+		"20250705T090000--memo.txt":       strings.Repeat("a", 100),
+		"20250720T090000--report__go.pdf": strings.Repeat("a", 300),
+		"20250903T083109--TCPIP入門.pdf":    strings.Repeat("a", 2048),
+		"20250930T235959--draft__go.md":   strings.Repeat("a", 10),
+		"notes.txt":                       strings.Repeat("a", 500),
+	})
+}
+
+func TestCollectStats(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupStatsDir(t)
+
+	result, err := CollectStats(tmpDir, StatsOptions{})
+	require.NoError(t, err)
+
+	// ファイルのない月も含めて累計が続く
+	assert.Equal(t, 4, result.Files)
+	assert.Equal(t, int64(2458), result.Bytes)
+	assert.Equal(t, []MonthStat{
+		{Month: "2025-07", Added: 2, Bytes: 400, Cumulative: 2, CumulativeBytes: 400},
+		{Month: "2025-08", Added: 0, Bytes: 0, Cumulative: 2, CumulativeBytes: 400},
+		{Month: "2025-09", Added: 2, Bytes: 2058, Cumulative: 4, CumulativeBytes: 2458},
+	}, result.Months)
+
+	// フォーマット外のファイル数と拡張子・タグごとの内訳
+	assert.Equal(t, 1, result.Unformatted)
+	assert.Equal(t, []CountStat{
+		{Name: "pdf", Files: 2, Bytes: 2348},
+		{Name: "md", Files: 1, Bytes: 10},
+		{Name: "txt", Files: 1, Bytes: 100},
+	}, result.Extensions)
+	assert.Equal(t, []CountStat{{Name: "go", Files: 2, Bytes: 310}}, result.Tags)
+
+	// 拡張子フィルタ
+	result, err = CollectStats(tmpDir, StatsOptions{FilterOptions: FilterOptions{Extensions: []string{"pdf"}}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Files)
+	assert.Len(t, result.Months, 3)
+}
+
+func TestCollectStats_StatusAndPriority(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-stats-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	testFiles := map[string]int{
+		"20250901T090000--a__go_p1_todo.md":   10,
+		"20250902T090000--b__p2_todo.md":      20,
+		"20250903T090000--c__doing_p1_p3.md":  30,
+		"20250904T090000--d__done_go.md":      40,
+		"20250905T090000--e.md":               50,
+		"20250906T090000--f__todo_network.md": 60,
+	}
+	for name, size := range testFiles {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), bytes.Repeat([]byte("a"), size), 0644))
+	}
+
+	result, err := CollectStats(tmpDir, StatsOptions{})
+	require.NoError(t, err)
+
+	// ステータス・優先度タグはタグの集計に含めず、それぞれ集計する
+	assert.Equal(t, []CountStat{
+		{Name: "go", Files: 2, Bytes: 50},
+		{Name: "network", Files: 1, Bytes: 60},
+	}, result.Tags)
+	assert.Equal(t, []CountStat{
+		{Name: "todo", Files: 3, Bytes: 90},
+		{Name: "doing", Files: 1, Bytes: 30},
+		{Name: "done", Files: 1, Bytes: 40},
+	}, result.Statuses)
+	assert.Equal(t, []CountStat{
+		{Name: "p1", Files: 2, Bytes: 40},
+		{Name: "p2", Files: 1, Bytes: 20},
+	}, result.Priorities)
+
+	buf := &bytes.Buffer{}
+	_, err = ShowStats(tmpDir, StatsOptions{Writer: buf})
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "Status  Files       Size\ntodo        3        90B\n")
+	assert.Contains(t, buf.String(), "Priority  Files       Size\np1            2        40B\n")
+}
+
+func TestShowStats(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupStatsDir(t)
+
+	t.Run("table", func(t *testing.T) {
+		t.Parallel()
+		buf := &bytes.Buffer{}
+		_, err := ShowStats(tmpDir, StatsOptions{Writer: buf})
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "2025-09      2          4     2.0KiB     2.4KiB\n")
+		assert.Contains(t, buf.String(), "Files: 4")
+		assert.Contains(t, buf.String(), "Months: 3 (2025-07 - 2025-09)")
+	})
+
+	t.Run("sparkline", func(t *testing.T) {
+		t.Parallel()
+		buf := &bytes.Buffer{}
+		_, err := ShowStats(tmpDir, StatsOptions{Writer: buf, Trend: TrendSparkline})
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "Added 2025-07..2025-09 █▁█\n")
+		assert.Contains(t, buf.String(), "Bytes 2025-07..2025-09 ▂▁█\n")
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		t.Parallel()
+		buf := &bytes.Buffer{}
+		_, err := ShowStats(tmpDir, StatsOptions{Writer: buf, Trend: TrendCSV})
+		require.NoError(t, err)
+		expected := "month,added,cumulative,bytes,cumulative_bytes\n" +
+			"2025-07,2,2,400,400\n" +
+			"2025-08,0,2,0,400\n" +
+			"2025-09,2,4,2058,2458\n"
+		assert.Equal(t, expected, buf.String())
+	})
+
+	t.Run("breakdown", func(t *testing.T) {
+		t.Parallel()
+		buf := &bytes.Buffer{}
+		_, err := ShowStats(tmpDir, StatsOptions{Writer: buf})
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "Extension  Files       Size\npdf            2     2.3KiB\n")
+		assert.Contains(t, buf.String(), "Tag  Files       Size\ngo       2       310B\n")
+		assert.Contains(t, buf.String(), "Unformatted: 1")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+		buf := &bytes.Buffer{}
+		_, err := ShowStats(tmpDir, StatsOptions{Writer: buf, JSON: true})
+		require.NoError(t, err)
+
+		var stats map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &stats))
+		assert.Equal(t, float64(4), stats["files"])
+		assert.Equal(t, float64(1), stats["unformatted"])
+		assert.Len(t, stats["months"], 3)
+		assert.Equal(t, map[string]any{"name": "go", "files": float64(2), "bytes": float64(310)}, stats["tags"].([]any)[0])
+	})
+
+	t.Run("unknown trend", func(t *testing.T) {
+		t.Parallel()
+		_, err := ShowStats(tmpDir, StatsOptions{Writer: &bytes.Buffer{}, Trend: "chart"})
+		assert.Error(t, err)
+	})
+}
+
+func TestSparklineAndFormatBytes(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "▁▄█", Sparkline([]int64{0, 4, 8}))
+	assert.Equal(t, "▁▁", Sparkline([]int64{0, 0}))
+
+	assert.Equal(t, "512B", FormatBytes(512))
+	assert.Equal(t, "1.5KiB", FormatBytes(1536))
+	assert.Equal(t, "3.0MiB", FormatBytes(3*1024*1024))
+}
+
+func TestParseByteSize(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"1024", 1024},
+		{"512B", 512},
+		{"1.5KiB", 1536},
+		{"500MiB", 500 << 20},
+		{" 2 GiB ", 2 << 30},
+		{"1TiB", 1 << 40},
+	}
+	for _, tt := range tests {
+		n, err := ParseByteSize(tt.input)
+		require.NoError(t, err, tt.input)
+		assert.Equal(t, tt.expected, n, tt.input)
+	}
+
+	for _, input := range []string{"", "lots", "-1MiB", "1PB"} {
+		_, err := ParseByteSize(input)
+		assert.Error(t, err, input)
+	}
+}
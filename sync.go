@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// SyncOptions は同期操作のオプションを表す
+type SyncOptions struct {
+	Writer io.Writer // 出力先
+	FilterOptions
+	DryRun          bool            // 実際にはコピー・リネームしない
+	DuplicatePolicy DuplicatePolicy // タイムスタンプ重複の扱い（重複を許可する場合はIDと拡張子でファイルを対応させる）
+	Throttle        *Throttle       // コピー・リネーム・stat操作の速度制限（nil の場合は制限なし）
+	Resume          *Checkpoint     // 再開する実行のチェックポイント（処理済みのファイルをスキップする）
+	Journal         *Journal        // 同期先で実行したリネームを記録するジャーナル（nil の場合は記録しない）
+	Hooks           *HookRunner     // リネームの前後に実行するフック（nil の場合は実行しない）
+}
+
+// SyncResult は同期操作の結果を表す
+type SyncResult struct {
+	Copied    []string    // コピーしたファイル名
+	Renamed   []DiffEntry // リネームしたファイル（FileB → FileA）
+	Conflicts []DiffEntry // 内容が異なるため同期しなかったファイル
+}
+
+// SyncDirectories は fromDir から toDir へIDをキーに一方向同期する
+// toDir に存在しないIDはコピーし、タイトル・タグの変更はリネームで反映する
+// 同じIDで内容が異なる場合は上書きせずコンフリクトとして報告する
+// ctx がキャンセルされた場合は処理中のファイルを終えた時点で止め、同期先にチェックポイントを書き出す
+func SyncDirectories(ctx context.Context, fromDir, toDir string, opts SyncOptions) (*SyncResult, error) {
+	reporter := ReporterFor(opts.Writer)
+	if err := rejectTableFormat(reporter, "sync"); err != nil {
+		return nil, err
+	}
+
+	diff, err := CompareDirectories(fromDir, toDir, DiffOptions{
+		Writer:          io.Discard,
+		FilterOptions:   opts.FilterOptions,
+		DuplicatePolicy: opts.DuplicatePolicy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SyncResult{
+		Copied:    []string{},
+		Renamed:   []DiffEntry{},
+		Conflicts: []DiffEntry{},
+	}
+
+	prefix := ""
+	if opts.DryRun {
+		prefix = "[dry-run] "
+	}
+
+	// 内容が異なるファイルはコンフリクトとして扱う（同じIDの添付ファイルは別に扱うため、同期先のファイル名で記録する）
+	conflicts := make(map[string]bool)
+	for _, entry := range diff.ContentMismatch {
+		conflicts[entry.FileB] = true
+		result.Conflicts = append(result.Conflicts, entry)
+		reporter.Warnf("%s (conflict: content differs)\n", entry.ID)
+	}
+
+	// チェックポイントに記録する処理済みのファイル名（コピーしたファイルと、リネーム前のファイル）
+	processed := []string{}
+
+	// 不足しているファイルをコピー
+	for _, fileName := range diff.OnlyInA {
+		// キャンセルされた場合は、処理中のファイルを終えた時点で止める
+		if ctx.Err() != nil {
+			break
+		}
+		if opts.Resume.Done(fileName) {
+			continue
+		}
+
+		if !opts.DryRun {
+			opts.Throttle.Wait()
+			if err := copyFile(filepath.Join(fromDir, fileName), filepath.Join(toDir, fileName)); err != nil {
+				return result, err
+			}
+		}
+		result.Copied = append(result.Copied, fileName)
+		processed = append(processed, fileName)
+		reporter.Emit("copied", map[string]any{"file": fileName, "dry_run": opts.DryRun}, "%s✓ Copied: %s\n", prefix, fileName)
+	}
+
+	// タイトル・タグの変更をリネームで反映
+	for _, entry := range diff.NameMismatches {
+		if ctx.Err() != nil {
+			break
+		}
+		if conflicts[entry.FileB] || opts.Resume.Done(entry.FileB) {
+			continue
+		}
+
+		newPath := filepath.Join(toDir, entry.FileA)
+		opts.Throttle.Wait()
+		if _, err := os.Stat(newPath); err == nil {
+			reporter.Warnf("target file already exists, skipping: %s\n", entry.FileA)
+			continue
+		}
+
+		if !opts.DryRun {
+			opts.Throttle.Wait()
+			if err := opts.Hooks.RenameFile(filepath.Join(toDir, entry.FileB), newPath); err != nil {
+				return result, fmt.Errorf("failed to rename file: %w", err)
+			}
+			if err := opts.Journal.Record(toDir, renamePlan{From: entry.FileB, To: entry.FileA}); err != nil {
+				reporter.Warnf("%s (%v)\n", entry.FileB, err)
+			}
+		}
+		result.Renamed = append(result.Renamed, entry)
+		processed = append(processed, entry.FileB)
+		reporter.Emit("renamed", map[string]any{"from": entry.FileB, "to": entry.FileA, "dry_run": opts.DryRun}, "%s✓ Renamed: %s → %s\n", prefix, entry.FileB, entry.FileA)
+	}
+
+	// サマリーを出力
+	reporter.Printf("\nSync Summary:\n")
+	reporter.Printf("  Copied: %d\n", len(result.Copied))
+	reporter.Printf("  Renamed: %d\n", len(result.Renamed))
+	reporter.Printf("  Conflicts: %d\n", len(result.Conflicts))
+
+	if err := ctx.Err(); err != nil {
+		return result, interruptRun(err, toDir, "sync", opts.Resume, processed, opts.DryRun)
+	}
+	return result, finishRun(toDir, "sync", opts.DryRun)
+}
+
+// copyFile はファイルを内容とパーミッション、更新日時を保ったままコピーする
+func copyFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return fmt.Errorf("failed to access file: %w", err)
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("failed to copy file: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to close file: %w", err)
+	}
+
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
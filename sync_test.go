@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupSyncDirs(t *testing.T) (string, string) {
+	t.Helper()
+
+	fromDir := newTestDir(t, map[string]string{
+		"20250903T083109--missing.pdf":            "missing",
+		"20250903T083110--new-title__network.pdf": "renamed",
+		"20250903T083111--conflict.pdf":           "content A",
+	})
+	toDir := newTestDir(t, map[string]string{
+		"20250903T083110--old-title.pdf":       "renamed",
+		"20250903T083111--conflict__infra.pdf": "content B",
+		"20250903T083112--only-in-to.pdf":      "keep",
+	})
+
+	return fromDir, toDir
+}
+
+func TestSyncDirectories(t *testing.T) {
+	t.Parallel()
+	fromDir, toDir := setupSyncDirs(t)
+
+	buf := &bytes.Buffer{}
+	result, err := SyncDirectories(context.Background(), fromDir, toDir, SyncOptions{Writer: buf})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"20250903T083109--missing.pdf"}, result.Copied)
+	require.Len(t, result.Renamed, 1)
+	assert.Equal(t, "20250903T083110", result.Renamed[0].ID)
+	require.Len(t, result.Conflicts, 1)
+	assert.Equal(t, "20250903T083111", result.Conflicts[0].ID)
+
+	// コピーされたファイルの内容
+	content, err := os.ReadFile(filepath.Join(toDir, "20250903T083109--missing.pdf"))
+	require.NoError(t, err)
+	assert.Equal(t, "missing", string(content))
+
+	// リネームが反映されている
+	assert.FileExists(t, filepath.Join(toDir, "20250903T083110--new-title__network.pdf"))
+	assert.NoFileExists(t, filepath.Join(toDir, "20250903T083110--old-title.pdf"))
+
+	// コンフリクトは変更されない
+	assert.FileExists(t, filepath.Join(toDir, "20250903T083111--conflict__infra.pdf"))
+
+	// 同期先にのみ存在するファイルは削除されない
+	assert.FileExists(t, filepath.Join(toDir, "20250903T083112--only-in-to.pdf"))
+
+	assert.Contains(t, buf.String(), "Sync Summary:")
+}
+
+func TestSyncDirectories_DryRun(t *testing.T) {
+	t.Parallel()
+	fromDir, toDir := setupSyncDirs(t)
+
+	buf := &bytes.Buffer{}
+	result, err := SyncDirectories(context.Background(), fromDir, toDir, SyncOptions{Writer: buf, DryRun: true})
+	require.NoError(t, err)
+
+	assert.Len(t, result.Copied, 1)
+	assert.Len(t, result.Renamed, 1)
+
+	// 同期先は変更されない
+	assert.NoFileExists(t, filepath.Join(toDir, "20250903T083109--missing.pdf"))
+	assert.FileExists(t, filepath.Join(toDir, "20250903T083110--old-title.pdf"))
+	assert.Contains(t, buf.String(), "[dry-run]")
+}
+
+func TestSyncDirectories_NonExistentDirectory(t *testing.T) {
+	t.Parallel()
+	_, err := SyncDirectories(context.Background(), "/nonexistent/from", "/nonexistent/to", SyncOptions{Writer: &bytes.Buffer{}})
+	assert.Error(t, err)
+}
+
+func TestSyncDirectories_DuplicatePolicy(t *testing.T) {
+	t.Parallel()
+	fromDir, toDir := t.TempDir(), t.TempDir()
+
+	for _, name := range []string{
+		"20250903T083109--new-title.pdf",
+		"20250903T083109--new-title.txt",
+		"20250903T083110--paper.pdf",
+		"20250903T083110--paper.txt",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(fromDir, name), []byte(filepath.Ext(name)), 0644))
+	}
+	for _, name := range []string{
+		"20250903T083109--old-title.pdf",
+		"20250903T083109--old-title.txt",
+		"20250903T083110--paper.pdf",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(toDir, name), []byte(filepath.Ext(name)), 0644))
+	}
+
+	result, err := SyncDirectories(context.Background(), fromDir, toDir, SyncOptions{Writer: &bytes.Buffer{}, DuplicatePolicy: DuplicatePolicyAllowSameBasename})
+	require.NoError(t, err)
+
+	// 添付ファイルもIDと拡張子で対応させてコピー・リネームする
+	assert.Equal(t, []string{"20250903T083110--paper.txt"}, result.Copied)
+	require.Len(t, result.Renamed, 2)
+	assert.Empty(t, result.Conflicts)
+	for _, name := range []string{
+		"20250903T083109--new-title.pdf",
+		"20250903T083109--new-title.txt",
+		"20250903T083110--paper.txt",
+	} {
+		assert.FileExists(t, filepath.Join(toDir, name))
+	}
+}
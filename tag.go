@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/kijimaD/parakeet/pkg/parakeet"
 	"github.com/pelletier/go-toml/v2"
 )
 
@@ -19,15 +20,35 @@ const (
 
 // TagOptions はタグ編集操作のオプションを表す
 type TagOptions struct {
-	Interactive bool      // インタラクティブモード（survey を使用）
-	Writer      io.Writer // 出力先
+	Interactive bool           // インタラクティブモード（survey を使用）
+	Writer      io.Writer      // 出力先
+	TagsFile    string         // タグ定義ファイル（空の場合は tags.toml、ResolveTagsFile で解決する）
+	FS          FileSystem     // ファイルシステム（nil の場合は OS のファイルシステム）
+	Journal     *Journal       // 実行したリネームを記録するジャーナル（nil の場合は記録しない）
+	Vault       *ObsidianVault // リネームに合わせて wiki リンクを書き換える Obsidian vault（nil の場合は書き換えない）
+	Xattr       bool           // タグをファイルの拡張属性（user.xdg.tags、Finder のタグ）にも書き込む
+	Hooks       *HookRunner    // リネームの前後に実行するフック（nil の場合は実行しない）
+}
+
+// mirrorXattrTags は opts.Xattr の場合にタグをファイルの拡張属性に書き込む
+// 拡張属性に対応していないファイルシステムもあるため、失敗しても警告のみにする
+func mirrorXattrTags(reporter Reporter, filePath string, tags []string, opts TagOptions) {
+	if !opts.Xattr {
+		return
+	}
+	if err := WriteXattrTags(filePath, tags); err != nil {
+		reporter.Warnf("%s (failed to mirror tags to extended attributes: %v)\n", filepath.Base(filePath), err)
+	}
 }
 
 // EditTags はファイルのタグをインタラクティブに編集する
 // インタラクティブモードでは、既存のタグを選択・解除し、新しいタグを追加できる
 func EditTags(filePath string, opts TagOptions) error {
+	reporter := ReporterFor(opts.Writer)
+	fsys := opts.Hooks.Wrap(fileSystemOrOS(opts.FS))
+
 	// ファイルの存在チェック
-	fileInfo, err := os.Stat(filePath)
+	fileInfo, err := fsys.Stat(filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return fmt.Errorf("file does not exist: %s", filePath)
@@ -44,34 +65,56 @@ func EditTags(filePath string, opts TagOptions) error {
 	dirPath := filepath.Dir(filePath)
 
 	// ファイル名が正しいフォーマットかチェック
-	components, err := ParseFileName(fileName)
+	components, err := parakeet.ParseFileName(fileName)
 	if err != nil {
 		return fmt.Errorf("file name is not in correct format: %w", err)
 	}
 
+	// ファイルのディレクトリから解決したタグ定義ファイルでバリデーションする
+	tomlPath := resolveTagsFile(fsys, dirPath, opts.TagsFile)
+	validator, err := newTagValidator(fsys, tomlPath)
+	if err != nil {
+		return err
+	}
+
 	// インタラクティブモードでタグを編集
 	if opts.Interactive {
-		newTags, err := promptForTags(components.Tags)
+		newTags, err := promptForTags(components.Tags, tomlPath, validator)
 		if err != nil {
 			return fmt.Errorf("failed to get tags: %w", err)
 		}
 
+		if err := validator.Validate(newTags); err != nil {
+			return err
+		}
+
 		// タグが変更されたかチェック
 		if !tagsEqual(components.Tags, newTags) {
 			// 新しいファイル名を生成
 			components.Tags = newTags
-			newFileName := components.FormatFileName()
+			newFileName, err := components.FormatFileNameStrict()
+			if err != nil {
+				return err
+			}
 			newFilePath := filepath.Join(dirPath, newFileName)
 
 			// ファイルをリネーム
-			if err := os.Rename(filePath, newFilePath); err != nil {
+			if err := fsys.Rename(filePath, newFilePath); err != nil {
 				return fmt.Errorf("failed to rename file: %w", err)
 			}
+			if err := opts.Journal.Record(dirPath, renamePlan{From: fileName, To: newFileName}); err != nil {
+				reporter.Warnf("%s (%v)\n", fileName, err)
+			}
+			if err := opts.Vault.RewriteLinks(dirPath, renamePlan{From: fileName, To: newFileName}); err != nil {
+				reporter.Warnf("%s (%v)\n", fileName, err)
+			}
 
-			_, _ = fmt.Fprintf(opts.Writer, "✓ Renamed: %s → %s\n", fileName, newFileName)
+			reporter.Successf("Renamed: %s → %s\n", fileName, newFileName)
+			filePath = newFilePath
 		} else {
-			_, _ = fmt.Fprintln(opts.Writer, "✓ No changes made")
+			reporter.Successf("No changes made\n")
 		}
+		mirrorXattrTags(reporter, filePath, newTags, opts)
 	}
 
 	return nil
@@ -90,13 +133,18 @@ type TagConfig struct {
 
 // LoadTagsFromTOML はTOMLファイルからタグ定義を読み込む
 func LoadTagsFromTOML(filePath string) ([]TagDefinition, error) {
+	return loadTagsFromTOML(OSFileSystem, filePath)
+}
+
+// loadTagsFromTOML は fsys 上のTOMLファイルからタグ定義を読み込む
+func loadTagsFromTOML(fsys FileSystem, filePath string) ([]TagDefinition, error) {
 	// ファイルが存在しない場合は空のスライスを返す
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+	if _, err := fsys.Stat(filePath); os.IsNotExist(err) {
 		return []TagDefinition{}, nil
 	}
 
 	// ファイルを読み込む
-	data, err := os.ReadFile(filePath)
+	data, err := fsys.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read tags file: %w", err)
 	}
@@ -110,35 +158,145 @@ func LoadTagsFromTOML(filePath string) ([]TagDefinition, error) {
 	return config.Tag, nil
 }
 
-// ValidateTags は指定されたタグがtags.tomlに定義されているかチェックする
-// tags.tomlが存在しない場合はエラーを返す
+// ValidateTags は指定されたタグを tomlPath のtags.tomlで検証する
+// ルールは TagValidator.Validate と同じで、tags.tomlがない場合は書式のみチェックする
 func ValidateTags(tags []string, tomlPath string) error {
-	// tags.tomlを読み込む
-	tagDefs, err := LoadTagsFromTOML(tomlPath)
+	validator, err := NewTagValidator(tomlPath)
 	if err != nil {
 		return err
 	}
 
-	// tags.tomlが存在しない（空のスライス）場合はエラー
-	if len(tagDefs) == 0 {
-		return fmt.Errorf("tags.toml not found or empty at: %s", tomlPath)
+	return validator.Validate(tags)
+}
+
+// invalidTagChars はタグに含めることができない文字
+// ファイル名の区切り文字と衝突するため使用できない
+const invalidTagChars = "/_-. "
+
+// TagValidator はタグの書式とtags.tomlの定義に基づいてタグを検証する
+// tag, tag add, tag rename, import, apply-map, validate などすべてのコマンドで共通のルールを適用するために使う
+// tags.tomlが存在しないか定義が空の場合は書式のみをチェックし、定義がある場合は未定義のタグを拒否する
+type TagValidator struct {
+	tomlPath  string          // 読み込んだtags.tomlのパス
+	validTags map[string]bool // 定義済みタグのセット
+}
+
+// NewTagValidator は指定されたtags.tomlからTagValidatorを作成する
+// ファイルが存在しない場合は定義なし（書式チェックのみ）のバリデーターを返す
+func NewTagValidator(tomlPath string) (*TagValidator, error) {
+	return newTagValidator(OSFileSystem, tomlPath)
+}
+
+// newTagValidator は fsys 上のtags.tomlからTagValidatorを作成する
+func newTagValidator(fsys FileSystem, tomlPath string) (*TagValidator, error) {
+	tagDefs, err := loadTagsFromTOML(fsys, tomlPath)
+	if err != nil {
+		return nil, err
 	}
 
-	// 定義されたタグのセットを作成
 	validTags := make(map[string]bool)
 	for _, tagDef := range tagDefs {
 		validTags[tagDef.Key] = true
 	}
 
-	// 各タグが定義されているかチェック
-	var invalidTags []string
+	return &TagValidator{
+		tomlPath:  tomlPath,
+		validTags: validTags,
+	}, nil
+}
+
+// NewTagValidatorForFile は対象ファイルのディレクトリから解決したtags.tomlからTagValidatorを作成する
+func NewTagValidatorForFile(filePath string) (*TagValidator, error) {
+	return NewTagValidator(TagsFilePathFor(filePath))
+}
+
+// TagsFilePathFor は対象ファイルに対応するtags.tomlのパスを返す
+func TagsFilePathFor(filePath string) string {
+	return ResolveTagsFile(filepath.Dir(filePath), "")
+}
+
+// ResolveTagsFile はディレクトリで使うタグ定義ファイルのパスを返す
+// tagsFile が空の場合は tags.toml を使う。絶対パスの場合はそのまま返し、
+// 相対パスの場合は .gitignore と同じように dir から親ディレクトリへ順に探して最初に見つかったものを返す
+// どこにも見つからない場合は dir 直下のパスを返す
+func ResolveTagsFile(dir, tagsFile string) string {
+	return resolveTagsFile(OSFileSystem, dir, tagsFile)
+}
+
+// resolveTagsFile は fsys 上でタグ定義ファイルのパスを解決する
+func resolveTagsFile(fsys FileSystem, dir, tagsFile string) string {
+	if tagsFile == "" {
+		tagsFile = TagsFileName
+	}
+	if filepath.IsAbs(tagsFile) {
+		return tagsFile
+	}
+
+	fallback := filepath.Join(dir, tagsFile)
+
+	current, err := fsys.Abs(dir)
+	if err != nil {
+		return fallback
+	}
+
+	for {
+		candidate := filepath.Join(current, tagsFile)
+		if _, err := fsys.Stat(candidate); err == nil {
+			return candidate
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return fallback
+		}
+		current = parent
+	}
+}
+
+// HasDefinitions はtags.tomlにタグ定義があるかどうかを返す
+func (v *TagValidator) HasDefinitions() bool {
+	return len(v.validTags) > 0
+}
+
+// UndefinedTags はtags.tomlに定義されていないタグを返す
+// 定義がない場合は常に空を返す
+func (v *TagValidator) UndefinedTags(tags []string) []string {
+	if !v.HasDefinitions() {
+		return nil
+	}
+
+	var undefinedTags []string
 	for _, tag := range tags {
-		if !validTags[tag] {
-			invalidTags = append(invalidTags, tag)
+		if !v.validTags[tag] {
+			undefinedTags = append(undefinedTags, tag)
 		}
 	}
 
-	if len(invalidTags) > 0 {
+	return undefinedTags
+}
+
+// ValidateSyntax はタグがファイル名に埋め込める書式かチェックする
+func (v *TagValidator) ValidateSyntax(tag string) error {
+	if tag == "" {
+		return fmt.Errorf("tag cannot be empty")
+	}
+
+	if strings.ContainsAny(tag, invalidTagChars) {
+		return fmt.Errorf("tag cannot contain special characters (/, _, -, ., space): %s", tag)
+	}
+
+	return nil
+}
+
+// Validate はタグの書式と定義をチェックする
+func (v *TagValidator) Validate(tags []string) error {
+	for _, tag := range tags {
+		if err := v.ValidateSyntax(tag); err != nil {
+			return err
+		}
+	}
+
+	if invalidTags := v.UndefinedTags(tags); len(invalidTags) > 0 {
 		return fmt.Errorf("undefined tags in tags.toml: %s", strings.Join(invalidTags, ", "))
 	}
 
@@ -146,7 +304,7 @@ func ValidateTags(tags []string, tomlPath string) error {
 }
 
 // promptForTags はインタラクティブにタグを選択・編集する
-func promptForTags(currentTags []string) ([]string, error) {
+func promptForTags(currentTags []string, tagsFilePath string, validator *TagValidator) ([]string, error) {
 	// 既存のタグをすべて選択状態にする
 	var selectedTags []string
 	if len(currentTags) > 0 {
@@ -155,8 +313,6 @@ func promptForTags(currentTags []string) ([]string, error) {
 	}
 
 	// TOMLファイルからタグ定義を読み込む
-	// デフォルトは ./tags.toml
-	tagsFilePath := TagsFileName
 	tagDefs, err := LoadTagsFromTOML(tagsFilePath)
 	if err != nil {
 		// エラーがあってもデフォルトのタグリストで続行
@@ -247,7 +403,7 @@ func promptForTags(currentTags []string) ([]string, error) {
 
 		// カスタムタグを追加
 		if addCustom {
-			customTag, err := promptForCustomTag()
+			customTag, err := promptForCustomTag(validator)
 			if err != nil {
 				return nil, err
 			}
@@ -275,7 +431,7 @@ func promptForTags(currentTags []string) ([]string, error) {
 }
 
 // promptForCustomTag はカスタムタグの入力を求める
-func promptForCustomTag() (string, error) {
+func promptForCustomTag(validator *TagValidator) (string, error) {
 	prompt := &survey.Input{
 		Message: "Enter custom tag:",
 	}
@@ -290,8 +446,8 @@ func promptForCustomTag() (string, error) {
 	tag = strings.TrimSpace(tag)
 
 	// タグに不正な文字が含まれていないかチェック
-	if strings.ContainsAny(tag, "/_--.") {
-		return "", fmt.Errorf("tag cannot contain special characters (/, _, -, .)")
+	if err := validator.ValidateSyntax(tag); err != nil {
+		return "", err
 	}
 
 	return tag, nil
@@ -323,6 +479,8 @@ func tagsEqual(a, b []string) bool {
 
 // ShowTags は指定されたファイルの現在のタグを表示する
 func ShowTags(filePath string, w io.Writer) error {
+	reporter := ReporterFor(w)
+
 	// ファイルの存在チェック
 	fileInfo, err := os.Stat(filePath)
 	if err != nil {
@@ -339,29 +497,32 @@ func ShowTags(filePath string, w io.Writer) error {
 	fileName := filepath.Base(filePath)
 
 	// ファイル名をパース
-	components, err := ParseFileName(fileName)
+	components, err := parakeet.ParseFileName(fileName)
 	if err != nil {
 		return fmt.Errorf("file name is not in correct format: %w", err)
 	}
 
 	// タグを表示
-	_, _ = fmt.Fprintf(w, "File: %s\n", fileName)
-	_, _ = fmt.Fprintf(w, "Timestamp: %s\n", components.Timestamp)
-	_, _ = fmt.Fprintf(w, "Comment: %s\n", components.Comment)
+	reporter.Printf("File: %s\n", fileName)
+	reporter.Printf("Timestamp: %s\n", components.Timestamp)
+	reporter.Printf("Comment: %s\n", components.Comment)
 
 	if len(components.Tags) > 0 {
-		_, _ = fmt.Fprintf(w, "Tags: %s\n", strings.Join(components.Tags, ", "))
+		reporter.Printf("Tags: %s\n", strings.Join(components.Tags, ", "))
 	} else {
-		_, _ = fmt.Fprintln(w, "Tags: (none)")
+		reporter.Printf("Tags: (none)\n")
 	}
 
 	return nil
 }
 
 // SetTags はファイルのタグを直接設定する（非インタラクティブ）
-func SetTags(filePath string, tags []string, w io.Writer) error {
+func SetTags(filePath string, tags []string, opts TagOptions) error {
+	reporter := ReporterFor(opts.Writer)
+	fsys := opts.Hooks.Wrap(fileSystemOrOS(opts.FS))
+
 	// ファイルの存在チェック
-	fileInfo, err := os.Stat(filePath)
+	fileInfo, err := fsys.Stat(filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return fmt.Errorf("file does not exist: %s", filePath)
@@ -377,11 +538,20 @@ func SetTags(filePath string, tags []string, w io.Writer) error {
 	dirPath := filepath.Dir(filePath)
 
 	// ファイル名をパース
-	components, err := ParseFileName(fileName)
+	components, err := parakeet.ParseFileName(fileName)
 	if err != nil {
 		return fmt.Errorf("file name is not in correct format: %w", err)
 	}
 
+	// ファイルのディレクトリから解決したタグ定義ファイルでバリデーションする
+	validator, err := newTagValidator(fsys, resolveTagsFile(fsys, dirPath, opts.TagsFile))
+	if err != nil {
+		return err
+	}
+	if err := validator.Validate(tags); err != nil {
+		return err
+	}
+
 	// タグをソート
 	sort.Strings(tags)
 
@@ -389,18 +559,29 @@ func SetTags(filePath string, tags []string, w io.Writer) error {
 	if !tagsEqual(components.Tags, tags) {
 		// 新しいファイル名を生成
 		components.Tags = tags
-		newFileName := components.FormatFileName()
+		newFileName, err := components.FormatFileNameStrict()
+		if err != nil {
+			return err
+		}
 		newFilePath := filepath.Join(dirPath, newFileName)
 
 		// ファイルをリネーム
-		if err := os.Rename(filePath, newFilePath); err != nil {
+		if err := fsys.Rename(filePath, newFilePath); err != nil {
 			return fmt.Errorf("failed to rename file: %w", err)
 		}
+		if err := opts.Journal.Record(dirPath, renamePlan{From: fileName, To: newFileName}); err != nil {
+			reporter.Warnf("%s (%v)\n", fileName, err)
+		}
+		if err := opts.Vault.RewriteLinks(dirPath, renamePlan{From: fileName, To: newFileName}); err != nil {
+			reporter.Warnf("%s (%v)\n", fileName, err)
+		}
 
-		_, _ = fmt.Fprintf(w, "✓ Renamed: %s → %s\n", fileName, newFileName)
+		reporter.Successf("Renamed: %s → %s\n", fileName, newFileName)
+		filePath = newFilePath
 	} else {
-		_, _ = fmt.Fprintln(w, "✓ No changes made")
+		reporter.Successf("No changes made\n")
 	}
+	mirrorXattrTags(reporter, filePath, tags, opts)
 
 	return nil
 }
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// TagBulkOptions はタグの一括追加・削除操作のオプションを表す
+type TagBulkOptions struct {
+	Writer io.Writer // 出力先
+	FilterOptions
+	IDs      []string    // 対象ファイルのID
+	All      bool        // ディレクトリ内のすべてのファイルを対象にする
+	WithTag  string      // このタグを持つファイルのみ対象（空の場合は制限なし）
+	DryRun   bool        // 実際にはリネームしない
+	TagsFile string      // タグ定義ファイル（空の場合は tags.toml、ResolveTagsFile で解決する）
+	FS       FileSystem  // ファイルシステム（nil の場合は OS のファイルシステム）
+	Journal  *Journal    // 実行したリネームを記録するジャーナル（nil の場合は記録しない）
+	Hooks    *HookRunner // リネームの前後に実行するフック（nil の場合は実行しない）
+}
+
+// TagBulkResult はタグの一括追加・削除操作の結果を表す
+type TagBulkResult struct {
+	Renamed map[string]string // リネームしたファイル: 旧ファイル名 -> 新ファイル名
+}
+
+// AddTag は選択したファイルにタグを追加する
+// すでにタグを持つファイルは変更しない
+func AddTag(targetDir, tag string, opts TagBulkOptions) (*TagBulkResult, error) {
+	// ディレクトリから解決したtags.tomlで追加するタグをバリデーションする
+	fsys := opts.Hooks.Wrap(fileSystemOrOS(opts.FS))
+	validator, err := newTagValidator(fsys, resolveTagsFile(fsys, targetDir, opts.TagsFile))
+	if err != nil {
+		return nil, err
+	}
+	if err := validator.Validate([]string{tag}); err != nil {
+		return nil, err
+	}
+
+	return updateTagsBulk(targetDir, opts, "Tag Add", tag, func(tags []string) ([]string, bool) {
+		if slices.Contains(tags, tag) {
+			return tags, false
+		}
+		added := append(slices.Clone(tags), tag)
+		sort.Strings(added)
+		return added, true
+	})
+}
+
+// RemoveTag は選択したファイルからタグを削除する
+// タグを持たないファイルは変更しない
+func RemoveTag(targetDir, tag string, opts TagBulkOptions) (*TagBulkResult, error) {
+	return updateTagsBulk(targetDir, opts, "Tag Remove", tag, func(tags []string) ([]string, bool) {
+		if !slices.Contains(tags, tag) {
+			return tags, false
+		}
+		removed := slices.DeleteFunc(slices.Clone(tags), func(t string) bool { return t == tag })
+		return removed, true
+	})
+}
+
+// updateTagsBulk は選択したファイルのタグを update で書き換えてリネームする
+// すべてのリネームを事前に検証してから実行し、途中で失敗した場合は元に戻す
+func updateTagsBulk(targetDir string, opts TagBulkOptions, title, tag string, update func([]string) ([]string, bool)) (*TagBulkResult, error) {
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("renamed", "from", "to", "dry_run")
+	fsys := opts.Hooks.Wrap(fileSystemOrOS(opts.FS))
+
+	// ディレクトリの存在チェック
+	if _, err := fsys.Stat(targetDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", targetDir)
+	}
+
+	if tag == "" {
+		return nil, fmt.Errorf("tag is required")
+	}
+
+	// 対象の指定方法をチェック
+	if len(opts.IDs) > 0 && opts.All {
+		return nil, fmt.Errorf("IDs and --all cannot be used together")
+	}
+	if len(opts.IDs) == 0 && !opts.All && opts.WithTag == "" && opts.Query == nil {
+		return nil, fmt.Errorf("ID, --all, --tag or --query is required")
+	}
+
+	entries, err := fsys.ReadDir(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	// IDごとに一致したファイルを記録する
+	matchedByID := make(map[string][]string)
+	for _, id := range opts.IDs {
+		matchedByID[id] = nil
+	}
+
+	plans := []renamePlan{}
+	for _, entry := range entries {
+		if entry.IsDir() || isShim(fsys, targetDir, entry) {
+			continue
+		}
+
+		fileName := entry.Name()
+		components, err := parakeet.ParseFileName(fileName)
+		if err != nil {
+			continue
+		}
+
+		if len(opts.IDs) > 0 {
+			if _, ok := matchedByID[components.Timestamp]; !ok {
+				continue
+			}
+			matchedByID[components.Timestamp] = append(matchedByID[components.Timestamp], fileName)
+		}
+
+		if !opts.Matches(fileName) {
+			continue
+		}
+		if opts.WithTag != "" && !slices.Contains(components.Tags, opts.WithTag) {
+			continue
+		}
+
+		tags, changed := update(components.Tags)
+		if !changed {
+			continue
+		}
+
+		components.Tags = tags
+		newName, err := components.FormatFileNameStrict()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fileName, err)
+		}
+		plans = append(plans, renamePlan{From: fileName, To: newName})
+	}
+
+	// 指定したIDがそれぞれ1つのファイルに一致することを確認する
+	for _, id := range opts.IDs {
+		switch files := matchedByID[id]; len(files) {
+		case 0:
+			return nil, fmt.Errorf("no file found with ID: %s", id)
+		case 1:
+		default:
+			return nil, fmt.Errorf("multiple files found with ID %s:\n%s", id, strings.Join(files, "\n"))
+		}
+	}
+
+	if !opts.DryRun {
+		if err := applyRenames(fsys, targetDir, plans); err != nil {
+			return nil, err
+		}
+		if err := opts.Journal.Record(targetDir, plans...); err != nil {
+			return nil, err
+		}
+	}
+
+	prefix := ""
+	if opts.DryRun {
+		prefix = "[dry-run] "
+	}
+
+	result := &TagBulkResult{
+		Renamed: make(map[string]string),
+	}
+	for _, plan := range plans {
+		result.Renamed[plan.From] = plan.To
+		reporter.Emit("renamed", map[string]any{"from": plan.From, "to": plan.To, "dry_run": opts.DryRun}, "%s✓ Renamed: %s → %s\n", prefix, plan.From, plan.To)
+	}
+
+	// サマリーを出力
+	reporter.Printf("\n%s Summary:\n", title)
+	reporter.Printf("  Tag: %s\n", tag)
+	reporter.Printf("  Files changed: %d\n", len(result.Renamed))
+
+	return result, nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTagBulkDir(t *testing.T) string {
+	t.Helper()
+
+	return newTestDir(t, map[string]string{
+		"20250903T083109--tcpip__net.pdf":        "test",
+		"20250903T083110--dns__infra_net.pdf":    "test",
+		"20250903T083111--memo.md":               "test",
+		"20250903T083112--other__infra_todo.pdf": "test",
+		"invalid__net.pdf":                       "test",
+	})
+}
+
+func TestAddTag(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		opts     TagBulkOptions
+		expected map[string]string
+	}{
+		{
+			name: "by IDs",
+			opts: TagBulkOptions{IDs: []string{"20250903T083109", "20250903T083111"}},
+			expected: map[string]string{
+				"20250903T083109--tcpip__net.pdf": "20250903T083109--tcpip__net_todo.pdf",
+				"20250903T083111--memo.md":        "20250903T083111--memo__todo.md",
+			},
+		},
+		{
+			name: "all with existing tag",
+			opts: TagBulkOptions{All: true, WithTag: "net"},
+			expected: map[string]string{
+				"20250903T083109--tcpip__net.pdf":     "20250903T083109--tcpip__net_todo.pdf",
+				"20250903T083110--dns__infra_net.pdf": "20250903T083110--dns__infra_net_todo.pdf",
+			},
+		},
+		{
+			name: "all with extension filter",
+			opts: TagBulkOptions{All: true, FilterOptions: FilterOptions{Extensions: []string{"md"}}},
+			expected: map[string]string{
+				"20250903T083111--memo.md": "20250903T083111--memo__todo.md",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir := setupTagBulkDir(t)
+
+			buf := &bytes.Buffer{}
+			tt.opts.Writer = buf
+			result, err := AddTag(tmpDir, "todo", tt.opts)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, result.Renamed)
+			for oldName, newName := range result.Renamed {
+				assert.NoFileExists(t, filepath.Join(tmpDir, oldName))
+				assert.FileExists(t, filepath.Join(tmpDir, newName))
+			}
+			// すでにタグを持つファイルは変更しない
+			assert.FileExists(t, filepath.Join(tmpDir, "20250903T083112--other__infra_todo.pdf"))
+			assert.FileExists(t, filepath.Join(tmpDir, "invalid__net.pdf"))
+		})
+	}
+}
+
+func TestRemoveTag(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupTagBulkDir(t)
+
+	buf := &bytes.Buffer{}
+	result, err := RemoveTag(tmpDir, "net", TagBulkOptions{Writer: buf, All: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"20250903T083109--tcpip__net.pdf":     "20250903T083109--tcpip.pdf",
+		"20250903T083110--dns__infra_net.pdf": "20250903T083110--dns__infra.pdf",
+	}, result.Renamed)
+	assert.FileExists(t, filepath.Join(tmpDir, "20250903T083109--tcpip.pdf"))
+	assert.FileExists(t, filepath.Join(tmpDir, "invalid__net.pdf"))
+	assert.Contains(t, buf.String(), "Files changed: 2")
+}
+
+func TestRemoveTag_DryRun(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupTagBulkDir(t)
+
+	buf := &bytes.Buffer{}
+	result, err := RemoveTag(tmpDir, "infra", TagBulkOptions{Writer: buf, WithTag: "todo", DryRun: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"20250903T083112--other__infra_todo.pdf": "20250903T083112--other__todo.pdf",
+	}, result.Renamed)
+	assert.FileExists(t, filepath.Join(tmpDir, "20250903T083112--other__infra_todo.pdf"))
+	assert.Contains(t, buf.String(), "[dry-run]")
+}
+
+func TestTagBulk_Errors(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupTagBulkDir(t)
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250903T083109--dup.txt"), []byte("dup"), 0644))
+
+	tests := []struct {
+		name      string
+		opts      TagBulkOptions
+		errorText string
+	}{
+		{name: "no selector", opts: TagBulkOptions{}, errorText: "ID, --all, --tag or --query is required"},
+		{name: "IDs and all", opts: TagBulkOptions{IDs: []string{"20250903T083110"}, All: true}, errorText: "cannot be used together"},
+		{name: "unknown ID", opts: TagBulkOptions{IDs: []string{"20250903T083110", "20990101T000000"}}, errorText: "no file found with ID: 20990101T000000"},
+		{name: "duplicate ID", opts: TagBulkOptions{IDs: []string{"20250903T083109"}}, errorText: "multiple files found with ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tt.opts.Writer = &bytes.Buffer{}
+			_, err := AddTag(tmpDir, "todo", tt.opts)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorText)
+		})
+	}
+
+	// エラーの場合はどのファイルも変更しない
+	assert.FileExists(t, filepath.Join(tmpDir, "20250903T083110--dns__infra_net.pdf"))
+}
+
+func TestAddTag_UndefinedTag(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupTagBulkDir(t)
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, TagsFileName), []byte("[[tag]]\nkey = \"net\"\n"), 0644))
+
+	_, err := AddTag(tmpDir, "todo", TagBulkOptions{Writer: &bytes.Buffer{}, All: true})
+	assert.Error(t, err)
+	assert.FileExists(t, filepath.Join(tmpDir, "20250903T083109--tcpip__net.pdf"))
+}
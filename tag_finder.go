@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// FinderImportOptions は Finder のタグの取り込み操作のオプションを表す
+type FinderImportOptions struct {
+	Writer io.Writer // 出力先
+	FilterOptions
+	DryRun   bool                                // 実際にはリネームしない
+	TagsFile string                              // タグ定義ファイル（空の場合は tags.toml、ResolveTagsFile で解決する）
+	Journal  *Journal                            // 実行したリネームを記録するジャーナル（nil の場合は記録しない）
+	Vault    *ObsidianVault                      // リネームに合わせて wiki リンクを書き換える Obsidian vault（nil の場合は書き換えない）
+	ReadTags func(path string) ([]string, error) // ファイルのタグを読み込む関数（nil の場合は ReadXattrTags）
+	Hooks    *HookRunner                         // リネームの前後に実行するフック（nil の場合は実行しない）
+}
+
+// FinderImportResult は Finder のタグの取り込み操作の結果を表す
+type FinderImportResult struct {
+	Renamed     map[string]string   // リネームしたファイル: 旧ファイル名 -> 新ファイル名
+	Undefined   map[string][]string // tags.toml に定義されていないため取り込まなかったタグ: タグ -> ファイル名リスト
+	Unformatted []string            // タグがあるがフォーマット済みでないため取り込まなかったファイル（先に generate を実行する）
+}
+
+// ImportFinderTags はディレクトリ直下のフォーマット済みファイルの Finder のタグ（Linux では user.xdg.tags）をファイル名のタグに取り込む
+// Finder のタグはファイル名に使える形（小文字、区切りと空白を除く）にし、既存のタグに追加する。
+// tags.toml に定義がある場合、定義されていないタグは取り込まずに報告する。
+// すべてのリネームを事前に検証してから実行し、途中で失敗した場合は元に戻す
+func ImportFinderTags(targetDir string, opts FinderImportOptions) (*FinderImportResult, error) {
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("renamed", "from", "to", "dry_run")
+
+	// ディレクトリの存在チェック
+	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", targetDir)
+	}
+
+	readTags := opts.ReadTags
+	if readTags == nil {
+		readTags = ReadXattrTags
+	}
+
+	validator, err := NewTagValidator(ResolveTagsFile(targetDir, opts.TagsFile))
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	result := &FinderImportResult{
+		Renamed:     make(map[string]string),
+		Undefined:   make(map[string][]string),
+		Unformatted: []string{},
+	}
+
+	plans := []renamePlan{}
+	for _, entry := range entries {
+		// ディレクトリとシムはスキップ
+		if entry.IsDir() || IsShim(targetDir, entry) {
+			continue
+		}
+
+		fileName := entry.Name()
+		if !opts.Matches(fileName) {
+			continue
+		}
+
+		finderTags, err := readTags(filepath.Join(targetDir, fileName))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tags of %s: %w", fileName, err)
+		}
+		if len(finderTags) == 0 {
+			continue
+		}
+
+		components, err := parakeet.ParseFileName(fileName)
+		if err != nil {
+			reporter.Warnf("not formatted, skipping (run generate first): %s\n", fileName)
+			result.Unformatted = append(result.Unformatted, fileName)
+			continue
+		}
+
+		tags := []string{}
+		for _, finderTag := range finderTags {
+			tag := finderTagToTag(finderTag)
+			if tag == "" {
+				reporter.Warnf("%s (tag %q has no usable characters)\n", fileName, finderTag)
+				continue
+			}
+			if len(validator.UndefinedTags([]string{tag})) > 0 {
+				result.Undefined[tag] = append(result.Undefined[tag], fileName)
+				continue
+			}
+			tags = append(tags, tag)
+		}
+
+		merged := mergeTags(components.Tags, tags)
+		if tagsEqual(components.Tags, merged) {
+			continue
+		}
+
+		components.Tags = merged
+		newName, err := components.FormatFileNameStrict()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fileName, err)
+		}
+		plans = append(plans, renamePlan{From: fileName, To: newName})
+	}
+
+	if !opts.DryRun {
+		if err := applyRenames(opts.Hooks.Wrap(OSFileSystem), targetDir, plans); err != nil {
+			return nil, err
+		}
+		if err := opts.Journal.Record(targetDir, plans...); err != nil {
+			return nil, err
+		}
+		if err := opts.Vault.RewriteLinks(targetDir, plans...); err != nil {
+			return nil, err
+		}
+	}
+
+	prefix := ""
+	if opts.DryRun {
+		prefix = "[dry-run] "
+	}
+
+	for _, plan := range plans {
+		result.Renamed[plan.From] = plan.To
+		reporter.Emit("renamed", map[string]any{"from": plan.From, "to": plan.To, "dry_run": opts.DryRun}, "%s✓ Renamed: %s → %s\n", prefix, plan.From, plan.To)
+	}
+
+	undefined := make([]string, 0, len(result.Undefined))
+	for tag := range result.Undefined {
+		undefined = append(undefined, tag)
+	}
+	sort.Strings(undefined)
+	for _, tag := range undefined {
+		reporter.Warnf("undefined tag, not imported: %s (%d files)\n", tag, len(result.Undefined[tag]))
+	}
+
+	// サマリーを出力
+	reporter.Printf("\nFinder Tag Import Summary:\n")
+	reporter.Printf("  Files changed: %d\n", len(result.Renamed))
+	reporter.Printf("  Undefined tags: %d\n", len(result.Undefined))
+	reporter.Printf("  Unformatted files: %d\n", len(result.Unformatted))
+
+	return result, nil
+}
+
+// finderTagToTag は Finder のタグの名前をファイル名のタグにする
+// 小文字にし、タグに使えない文字（区切り・空白・ファイル名に使えない文字・制御文字）を取り除く
+func finderTagToTag(name string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(invalidTagChars, r) || strings.ContainsRune(windowsInvalidChars, r) || unicode.IsSpace(r) || unicode.IsControl(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, finderTagName(name))
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinderTagToTag(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "work", finderTagToTag("Work\n4"))
+	assert.Equal(t, "workproject", finderTagToTag("Work Project"))
+	assert.Equal(t, "ab", finderTagToTag("a_b"))
+	assert.Equal(t, "", finderTagToTag("--"))
+	assert.Equal(t, "重要", finderTagToTag("重要\n6"))
+}
+
+func TestImportFinderTags(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-import-finder-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "tags.toml"), []byte("[[tag]]\nkey = \"work\"\ndesc = \"仕事\"\n\n[[tag]]\nkey = \"network\"\ndesc = \"ネットワーク\"\n"), 0644))
+	finderTags := map[string][]string{
+		"20250903T083109--memo__network.pdf": {"Work\n4", "Red\n6"},
+		"20250903T083110--report.pdf":        {"Network"},
+		"20250903T083111--same__work.pdf":    {"Work"},
+		"20250903T083112--plain.pdf":         nil,
+		"scan.pdf":                           {"Work"},
+	}
+	for name := range finderTags {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("content"), 0644))
+	}
+
+	opts := FinderImportOptions{
+		Writer: &bytes.Buffer{},
+		ReadTags: func(path string) ([]string, error) {
+			return finderTags[filepath.Base(path)], nil
+		},
+	}
+
+	// dry-run ではリネームしない
+	opts.DryRun = true
+	result, err := ImportFinderTags(tmpDir, opts)
+	require.NoError(t, err)
+	assert.Len(t, result.Renamed, 2)
+	assert.FileExists(t, filepath.Join(tmpDir, "20250903T083110--report.pdf"))
+
+	buf := &bytes.Buffer{}
+	opts.Writer = buf
+	opts.DryRun = false
+	result, err = ImportFinderTags(tmpDir, opts)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"20250903T083109--memo__network.pdf": "20250903T083109--memo__network_work.pdf",
+		"20250903T083110--report.pdf":        "20250903T083110--report__network.pdf",
+	}, result.Renamed)
+	assert.Equal(t, map[string][]string{"red": {"20250903T083109--memo__network.pdf"}}, result.Undefined)
+	assert.Equal(t, []string{"scan.pdf"}, result.Unformatted)
+	assert.FileExists(t, filepath.Join(tmpDir, "20250903T083109--memo__network_work.pdf"))
+	assert.FileExists(t, filepath.Join(tmpDir, "20250903T083111--same__work.pdf"))
+	assert.Contains(t, buf.String(), "undefined tag, not imported: red (1 files)")
+	assert.Contains(t, buf.String(), "Files changed: 2")
+}
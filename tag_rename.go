@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// TagRenameOptions はタグ一括リネーム操作のオプションを表す
+type TagRenameOptions struct {
+	Writer io.Writer // 出力先
+	FilterOptions
+	DryRun   bool        // 実際にはリネームしない
+	TagsFile string      // タグ定義ファイル（空の場合は tags.toml、ResolveTagsFile で解決する）
+	FS       FileSystem  // ファイルシステム（nil の場合は OS のファイルシステム）
+	Journal  *Journal    // 実行したリネームを記録するジャーナル（nil の場合は記録しない）
+	Hooks    *HookRunner // リネームの前後に実行するフック（nil の場合は実行しない）
+}
+
+// TagRenameResult はタグ一括リネーム操作の結果を表す
+type TagRenameResult struct {
+	Renamed map[string]string // リネームしたファイル: 旧ファイル名 -> 新ファイル名
+}
+
+// renamePlan はディレクトリ内で行うリネームを表す
+type renamePlan struct {
+	From string // 旧ファイル名
+	To   string // 新ファイル名
+}
+
+// RenameTag はディレクトリ内のファイルのタグ oldTag を newTag に置き換える
+// すべてのリネームを事前に検証してから実行し、途中で失敗した場合は元に戻す
+func RenameTag(targetDir, oldTag, newTag string, opts TagRenameOptions) (*TagRenameResult, error) {
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("renamed", "from", "to", "dry_run")
+	fsys := opts.Hooks.Wrap(fileSystemOrOS(opts.FS))
+
+	// ディレクトリの存在チェック
+	if _, err := fsys.Stat(targetDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", targetDir)
+	}
+
+	if oldTag == newTag {
+		return nil, fmt.Errorf("old and new tags are the same: %s", oldTag)
+	}
+
+	// ディレクトリから解決したtags.tomlで新しいタグをバリデーションする
+	validator, err := newTagValidator(fsys, resolveTagsFile(fsys, targetDir, opts.TagsFile))
+	if err != nil {
+		return nil, err
+	}
+	if err := validator.Validate([]string{newTag}); err != nil {
+		return nil, err
+	}
+
+	entries, err := fsys.ReadDir(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	plans := []renamePlan{}
+	for _, entry := range entries {
+		if entry.IsDir() || isShim(fsys, targetDir, entry) {
+			continue
+		}
+
+		fileName := entry.Name()
+		if !opts.Matches(fileName) {
+			continue
+		}
+
+		components, err := parakeet.ParseFileName(fileName)
+		if err != nil {
+			continue
+		}
+
+		tags, changed := replaceTag(components.Tags, oldTag, newTag)
+		if !changed {
+			continue
+		}
+
+		components.Tags = tags
+		newName, err := components.FormatFileNameStrict()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fileName, err)
+		}
+		plans = append(plans, renamePlan{From: fileName, To: newName})
+	}
+
+	if !opts.DryRun {
+		if err := applyRenames(fsys, targetDir, plans); err != nil {
+			return nil, err
+		}
+		if err := opts.Journal.Record(targetDir, plans...); err != nil {
+			return nil, err
+		}
+	}
+
+	prefix := ""
+	if opts.DryRun {
+		prefix = "[dry-run] "
+	}
+
+	result := &TagRenameResult{
+		Renamed: make(map[string]string),
+	}
+	for _, plan := range plans {
+		result.Renamed[plan.From] = plan.To
+		reporter.Emit("renamed", map[string]any{"from": plan.From, "to": plan.To, "dry_run": opts.DryRun}, "%s✓ Renamed: %s → %s\n", prefix, plan.From, plan.To)
+	}
+
+	// サマリーを出力
+	reporter.Printf("\nTag Rename Summary:\n")
+	reporter.Printf("  %s → %s\n", oldTag, newTag)
+	reporter.Printf("  Files changed: %d\n", len(result.Renamed))
+
+	return result, nil
+}
+
+// replaceTag はタグのリスト内の oldTag を newTag に置き換え、ソートして返す
+// newTag がすでにある場合は重複させない
+func replaceTag(tags []string, oldTag, newTag string) ([]string, bool) {
+	changed := false
+	seen := make(map[string]bool)
+	replaced := make([]string, 0, len(tags))
+
+	for _, tag := range tags {
+		if tag == oldTag {
+			tag = newTag
+			changed = true
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		replaced = append(replaced, tag)
+	}
+
+	sort.Strings(replaced)
+	return replaced, changed
+}
+
+// applyRenames はディレクトリ内でリネームをまとめて実行する
+// 実行前にリネーム先の衝突を検証し、途中で失敗した場合は実行済みのリネームを元に戻す
+func applyRenames(fsys FileSystem, dirPath string, plans []renamePlan) error {
+	targets := make(map[string]bool)
+	for _, plan := range plans {
+		if plan.From == plan.To {
+			continue
+		}
+		if targets[plan.To] {
+			return fmt.Errorf("multiple files would be renamed to: %s", plan.To)
+		}
+		targets[plan.To] = true
+
+		if _, err := fsys.Stat(filepath.Join(dirPath, plan.To)); err == nil {
+			return fmt.Errorf("target file already exists: %s", plan.To)
+		}
+	}
+
+	tx := newRenameTransaction(fsys)
+	for _, plan := range plans {
+		if plan.From == plan.To {
+			continue
+		}
+		tx.Add(filepath.Join(dirPath, plan.From), filepath.Join(dirPath, plan.To))
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTagRenameDir(t *testing.T) string {
+	t.Helper()
+
+	return newTestDir(t, map[string]string{
+		"20250903T083109--tcpip__net_zebra.pdf": "test",
+		"20250903T083110--dns__infra_net.pdf":   "test",
+		"20250903T083111--both__net_network.md": "test",
+		"20250903T083112--other__infra.pdf":     "test",
+		"invalid__net.pdf":                      "test",
+	})
+}
+
+func TestRenameTag(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupTagRenameDir(t)
+
+	buf := &bytes.Buffer{}
+	result, err := RenameTag(tmpDir, "net", "network", TagRenameOptions{Writer: buf})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"20250903T083109--tcpip__net_zebra.pdf": "20250903T083109--tcpip__network_zebra.pdf",
+		"20250903T083110--dns__infra_net.pdf":   "20250903T083110--dns__infra_network.pdf",
+		"20250903T083111--both__net_network.md": "20250903T083111--both__network.md",
+	}, result.Renamed)
+
+	for oldName, newName := range result.Renamed {
+		assert.NoFileExists(t, filepath.Join(tmpDir, oldName))
+		assert.FileExists(t, filepath.Join(tmpDir, newName))
+	}
+	assert.FileExists(t, filepath.Join(tmpDir, "20250903T083112--other__infra.pdf"))
+	assert.FileExists(t, filepath.Join(tmpDir, "invalid__net.pdf"))
+	assert.Contains(t, buf.String(), "Files changed: 3")
+}
+
+func TestRenameTag_DryRun(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupTagRenameDir(t)
+
+	buf := &bytes.Buffer{}
+	result, err := RenameTag(tmpDir, "net", "network", TagRenameOptions{Writer: buf, DryRun: true})
+	require.NoError(t, err)
+
+	assert.Len(t, result.Renamed, 3)
+	assert.FileExists(t, filepath.Join(tmpDir, "20250903T083109--tcpip__net_zebra.pdf"))
+	assert.Contains(t, buf.String(), "[dry-run]")
+}
+
+func TestRenameTag_Errors(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupTagRenameDir(t)
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250903T083110--dns__infra_network.pdf"), []byte("collision"), 0644))
+
+	tests := []struct {
+		name      string
+		oldTag    string
+		newTag    string
+		errorText string
+	}{
+		{name: "same tag", oldTag: "net", newTag: "net", errorText: "old and new tags are the same"},
+		{name: "invalid new tag", oldTag: "net", newTag: "net_work", errorText: "special characters"},
+		{name: "collision", oldTag: "net", newTag: "network", errorText: "target file already exists"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := RenameTag(tmpDir, tt.oldTag, tt.newTag, TagRenameOptions{Writer: &bytes.Buffer{}})
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorText)
+		})
+	}
+
+	// 衝突がある場合は1つもリネームしない
+	assert.FileExists(t, filepath.Join(tmpDir, "20250903T083109--tcpip__net_zebra.pdf"))
+	assert.FileExists(t, filepath.Join(tmpDir, "20250903T083111--both__net_network.md"))
+}
+
+func TestRenameTag_UndefinedTag(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupTagRenameDir(t)
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, TagsFileName), []byte("[[tag]]\nkey = \"infra\"\ndesc = \"Infrastructure\"\n"), 0644))
+
+	_, err := RenameTag(tmpDir, "net", "network", TagRenameOptions{Writer: &bytes.Buffer{}})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "undefined tags in tags.toml: network")
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+const (
+	// DefaultTagSummaryFileName は tagdef summary コマンドのデフォルト出力ファイル名
+	DefaultTagSummaryFileName = "README.md"
+	// TagSummaryBeginMarker はタグ一覧の開始マーカー
+	TagSummaryBeginMarker = "<!-- BEGIN parakeet tags -->"
+	// TagSummaryEndMarker はタグ一覧の終了マーカー
+	TagSummaryEndMarker = "<!-- END parakeet tags -->"
+)
+
+// TagSummaryOptions はタグ一覧の埋め込み操作のオプションを表す
+type TagSummaryOptions struct {
+	Writer io.Writer // 出力先
+	FilterOptions
+	Output   string // 出力ファイルのパス（空の場合は targetDir/README.md）
+	TagsFile string // タグ定義ファイル（空の場合は tags.toml、ResolveTagsFile で解決する）
+}
+
+// TagStat はタグの使用状況を表す
+type TagStat struct {
+	Key      string // タグのキー
+	Desc     string // タグの説明（tags.toml に定義がない場合は空）
+	Count    int    // タグが付いたファイル数
+	LastUsed string // タグが付いた最新のファイルのタイムスタンプ（使われていない場合は空）
+	Defined  bool   // tags.toml に定義されているかどうか
+}
+
+// CollectTagStats はディレクトリ内のファイルからタグの使用状況を集計する
+// tags.toml の定義順に並べ、定義されていないタグはその後に名前順で並べる
+func CollectTagStats(targetDir string, opts TagSummaryOptions) ([]TagStat, error) {
+	// ディレクトリの存在チェック
+	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", targetDir)
+	}
+
+	definitions, err := LoadTagsFromTOML(ResolveTagsFile(targetDir, opts.TagsFile))
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	stats := make(map[string]*TagStat)
+	for _, def := range definitions {
+		stats[def.Key] = &TagStat{Key: def.Key, Desc: def.Desc, Defined: true}
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || IsShim(targetDir, entry) {
+			continue
+		}
+
+		fileName := entry.Name()
+		if !opts.Matches(fileName) {
+			continue
+		}
+
+		components, err := parakeet.ParseFileName(fileName)
+		if err != nil {
+			continue
+		}
+
+		for _, tag := range components.Tags {
+			stat, ok := stats[tag]
+			if !ok {
+				stat = &TagStat{Key: tag}
+				stats[tag] = stat
+			}
+			stat.Count++
+			if components.Timestamp > stat.LastUsed {
+				stat.LastUsed = components.Timestamp
+			}
+		}
+	}
+
+	result := make([]TagStat, 0, len(stats))
+	for _, def := range definitions {
+		result = append(result, *stats[def.Key])
+		delete(stats, def.Key)
+	}
+
+	undefined := make([]string, 0, len(stats))
+	for key := range stats {
+		undefined = append(undefined, key)
+	}
+	sort.Strings(undefined)
+	for _, key := range undefined {
+		result = append(result, *stats[key])
+	}
+
+	return result, nil
+}
+
+// FormatTagSummary はタグの使用状況をMarkdown表に整形する
+func FormatTagSummary(stats []TagStat) string {
+	var b strings.Builder
+	b.WriteString("| Tag | Description | Count | Last used |\n")
+	b.WriteString("|---|---|---|---|\n")
+
+	for _, stat := range stats {
+		desc := stat.Desc
+		if !stat.Defined {
+			desc = "(undefined)"
+		}
+		_, _ = fmt.Fprintf(&b, "| %s | %s | %d | %s |\n", stat.Key, desc, stat.Count, formatLastUsed(stat.LastUsed))
+	}
+
+	return b.String()
+}
+
+// formatLastUsed はタイムスタンプを日付（YYYY-MM-DD）に整形する
+// 使われていない場合やパースできない場合は "-" またはそのままの値を返す
+func formatLastUsed(timestamp string) string {
+	if timestamp == "" {
+		return "-"
+	}
+
+	t, err := time.Parse("20060102T150405", timestamp)
+	if err != nil {
+		return timestamp
+	}
+	return t.Format("2006-01-02")
+}
+
+// UpdateTagSummaryFile はタグの使用状況の表でファイルのマーカー間を更新する
+// 既存のファイルはマーカーの間のみ書き換え、手書きの部分は保持する
+func UpdateTagSummaryFile(targetDir string, opts TagSummaryOptions) error {
+	reporter := ReporterFor(opts.Writer)
+
+	output := opts.Output
+	if output == "" {
+		output = filepath.Join(targetDir, DefaultTagSummaryFileName)
+	}
+
+	stats, err := CollectTagStats(targetDir, opts)
+	if err != nil {
+		return err
+	}
+
+	return updateMarkedFile(output, FormatTagSummary(stats), TagSummaryBeginMarker, TagSummaryEndMarker, reporter)
+}
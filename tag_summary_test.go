@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTagSummaryDir(t *testing.T) string {
+	t.Helper()
+
+	tomlContent := `[[tag]]
+key = "network"
+desc = "ネットワーク"
+
+[[tag]]
+key = "go"
+desc = "Go言語"
+
+[[tag]]
+key = "unused"
+desc = "未使用"
+`
+	return newTestDir(t, map[string]string{
+		TagsFileName: tomlContent,
+		"20250903T083109--TCPIP入門__network.pdf": "test content",
+		"20251002T120000--Go入門__go_network.pdf": "test content",
+		"20250101T000000--メモ__draft.txt":        "test content",
+		"notes.txt":                             "test content",
+	})
+}
+
+func TestCollectTagStats(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupTagSummaryDir(t)
+
+	stats, err := CollectTagStats(tmpDir, TagSummaryOptions{})
+	require.NoError(t, err)
+
+	// 定義順に並び、定義されていないタグは末尾に付く
+	expected := []TagStat{
+		{Key: "network", Desc: "ネットワーク", Count: 2, LastUsed: "20251002T120000", Defined: true},
+		{Key: "go", Desc: "Go言語", Count: 1, LastUsed: "20251002T120000", Defined: true},
+		{Key: "unused", Desc: "未使用", Count: 0, LastUsed: "", Defined: true},
+		{Key: "draft", Count: 1, LastUsed: "20250101T000000"},
+	}
+	assert.Equal(t, expected, stats)
+
+	// 拡張子フィルタ
+	stats, err = CollectTagStats(tmpDir, TagSummaryOptions{FilterOptions: FilterOptions{Extensions: []string{"txt"}}})
+	require.NoError(t, err)
+	require.Len(t, stats, 4)
+	assert.Equal(t, 0, stats[0].Count)
+	assert.Equal(t, 1, stats[3].Count)
+}
+
+func TestFormatTagSummary(t *testing.T) {
+	t.Parallel()
+
+	stats := []TagStat{
+		{Key: "network", Desc: "ネットワーク", Count: 2, LastUsed: "20251002T120000", Defined: true},
+		{Key: "unused", Desc: "未使用", Defined: true},
+		{Key: "draft", Count: 1, LastUsed: "20250101T000000"},
+	}
+
+	expected := "| Tag | Description | Count | Last used |\n" +
+		"|---|---|---|---|\n" +
+		"| network | ネットワーク | 2 | 2025-10-02 |\n" +
+		"| unused | 未使用 | 0 | - |\n" +
+		"| draft | (undefined) | 1 | 2025-01-01 |\n"
+	assert.Equal(t, expected, FormatTagSummary(stats))
+}
+
+func TestUpdateTagSummaryFile(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupTagSummaryDir(t)
+
+	readmePath := filepath.Join(tmpDir, DefaultTagSummaryFileName)
+	handWritten := "# Archive\n\n" + TagSummaryBeginMarker + "\nstale\n" + TagSummaryEndMarker + "\n\n## Notes\n"
+	require.NoError(t, os.WriteFile(readmePath, []byte(handWritten), 0644))
+
+	buf := &bytes.Buffer{}
+	err := UpdateTagSummaryFile(tmpDir, TagSummaryOptions{Writer: buf})
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "✓ Updated:")
+
+	data, err := os.ReadFile(readmePath)
+	require.NoError(t, err)
+	content := string(data)
+	assert.True(t, strings.HasPrefix(content, "# Archive\n\n"+TagSummaryBeginMarker))
+	assert.Contains(t, content, "| network | ネットワーク | 2 | 2025-10-02 |")
+	assert.NotContains(t, content, "stale")
+	assert.True(t, strings.HasSuffix(content, TagSummaryEndMarker+"\n\n## Notes\n"))
+
+	// 2回目は変更なし
+	buf.Reset()
+	err = UpdateTagSummaryFile(tmpDir, TagSummaryOptions{Writer: buf})
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "✓ No changes made")
+}
+
+func TestUpdateTagSummaryFile_KeepsIndexSection(t *testing.T) {
+	t.Parallel()
+	tmpDir := setupTagSummaryDir(t)
+
+	// インデックスのマーカーとは独立して更新される
+	output := filepath.Join(tmpDir, "index.md")
+	require.NoError(t, UpdateIndexFile(tmpDir, IndexOptions{Writer: &bytes.Buffer{}, Output: output}))
+	require.NoError(t, UpdateTagSummaryFile(tmpDir, TagSummaryOptions{Writer: &bytes.Buffer{}, Output: output}))
+
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	content := string(data)
+	assert.Contains(t, content, IndexBeginMarker)
+	assert.Contains(t, content, "| 20250903T083109 | TCPIP入門 | network |")
+	assert.Contains(t, content, TagSummaryBeginMarker)
+	assert.Contains(t, content, "| go | Go言語 | 1 | 2025-10-02 |")
+}
@@ -199,7 +199,7 @@ func TestSetTags(t *testing.T) {
 
 			// Set tags
 			buf := &bytes.Buffer{}
-			err = SetTags(filePath, tt.newTags, buf)
+			err = SetTags(filePath, tt.newTags, TagOptions{Writer: buf})
 			require.NoError(t, err)
 
 			// Verify new file exists
@@ -224,7 +224,7 @@ func TestSetTags(t *testing.T) {
 func TestSetTags_NonExistentFile(t *testing.T) {
 	t.Parallel()
 	buf := &bytes.Buffer{}
-	err := SetTags("/non/existent/file.pdf", []string{"tag1"}, buf)
+	err := SetTags("/non/existent/file.pdf", []string{"tag1"}, TagOptions{Writer: buf})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "does not exist")
 }
@@ -242,7 +242,7 @@ func TestSetTags_InvalidFormat(t *testing.T) {
 	require.NoError(t, err)
 
 	buf := &bytes.Buffer{}
-	err = SetTags(filePath, []string{"tag1"}, buf)
+	err = SetTags(filePath, []string{"tag1"}, TagOptions{Writer: buf})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not in correct format")
 }
@@ -255,7 +255,7 @@ func TestSetTags_Directory(t *testing.T) {
 	defer func() { _ = os.RemoveAll(tmpDir) }()
 
 	buf := &bytes.Buffer{}
-	err = SetTags(tmpDir, []string{"tag1"}, buf)
+	err = SetTags(tmpDir, []string{"tag1"}, TagOptions{Writer: buf})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "cannot set tags for directory")
 }
@@ -339,7 +339,7 @@ func TestIntegration_TagWorkflow(t *testing.T) {
 
 	// Step 2: Add tags
 	buf := &bytes.Buffer{}
-	err = SetTags(filePath, []string{"work", "important"}, buf)
+	err = SetTags(filePath, []string{"work", "important"}, TagOptions{Writer: buf})
 	require.NoError(t, err)
 
 	// Step 3: Verify new file exists
@@ -350,7 +350,7 @@ func TestIntegration_TagWorkflow(t *testing.T) {
 
 	// Step 4: Modify tags
 	buf = &bytes.Buffer{}
-	err = SetTags(newFilePath, []string{"work", "urgent", "review"}, buf)
+	err = SetTags(newFilePath, []string{"work", "urgent", "review"}, TagOptions{Writer: buf})
 	require.NoError(t, err)
 
 	// Step 5: Verify final file
@@ -366,7 +366,7 @@ func TestIntegration_TagWorkflow(t *testing.T) {
 
 	// Step 6: Remove all tags
 	buf = &bytes.Buffer{}
-	err = SetTags(finalFilePath, []string{}, buf)
+	err = SetTags(finalFilePath, []string{}, TagOptions{Writer: buf})
 	require.NoError(t, err)
 
 	// Step 7: Verify back to no tags
@@ -612,9 +612,13 @@ desc = "緊急"
 
 func TestValidateTags_NonExistentTOML(t *testing.T) {
 	t.Parallel()
+	// tags.tomlがない場合は書式のみチェックする
 	err := ValidateTags([]string{"tag1"}, "/non/existent/tags.toml")
+	assert.NoError(t, err)
+
+	err = ValidateTags([]string{"bad_tag"}, "/non/existent/tags.toml")
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "tags.toml not found or empty")
+	assert.Contains(t, err.Error(), "tag cannot contain special characters")
 }
 
 func TestValidateTags_EmptyTOML(t *testing.T) {
@@ -626,6 +630,158 @@ func TestValidateTags_EmptyTOML(t *testing.T) {
 	_ = tmpFile.Close()
 
 	err = ValidateTags([]string{"tag1"}, tmpFile.Name())
+	assert.NoError(t, err)
+}
+
+func TestTagValidator(t *testing.T) {
+	t.Parallel()
+	// Create temporary directory with tags.toml
+	tmpDir, err := os.MkdirTemp("", "parakeet-tagvalidator-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	content := `[[tag]]
+key = "infra"
+
+[[tag]]
+key = "network"
+`
+	err = os.WriteFile(filepath.Join(tmpDir, TagsFileName), []byte(content), 0644)
+	require.NoError(t, err)
+
+	validator, err := NewTagValidatorForFile(filepath.Join(tmpDir, "20250903T083109--test.pdf"))
+	require.NoError(t, err)
+	assert.True(t, validator.HasDefinitions())
+
+	tests := []struct {
+		name      string
+		tags      []string
+		wantError bool
+		errorText string
+	}{
+		{
+			name:      "defined tags",
+			tags:      []string{"infra", "network"},
+			wantError: false,
+		},
+		{
+			name:      "undefined tag",
+			tags:      []string{"infra", "unknown"},
+			wantError: true,
+			errorText: "undefined tags in tags.toml: unknown",
+		},
+		{
+			name:      "tag with separator",
+			tags:      []string{"in_fra"},
+			wantError: true,
+			errorText: "tag cannot contain special characters",
+		},
+		{
+			name:      "empty tag",
+			tags:      []string{""},
+			wantError: true,
+			errorText: "tag cannot be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := validator.Validate(tt.tags)
+			if tt.wantError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorText)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTagValidator_NoDefinitions(t *testing.T) {
+	t.Parallel()
+	validator, err := NewTagValidator("/non/existent/tags.toml")
+	require.NoError(t, err)
+
+	assert.False(t, validator.HasDefinitions())
+	assert.Empty(t, validator.UndefinedTags([]string{"any"}))
+	assert.NoError(t, validator.Validate([]string{"any"}))
+	assert.Error(t, validator.Validate([]string{"a.b"}))
+}
+
+func TestSetTags_UsesTagsFileInFileDirectory(t *testing.T) {
+	t.Parallel()
+	// Create temporary directory with tags.toml next to the file
+	tmpDir, err := os.MkdirTemp("", "parakeet-settags-toml-*")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	err = os.WriteFile(filepath.Join(tmpDir, TagsFileName), []byte("[[tag]]\nkey = \"infra\"\n"), 0644)
+	require.NoError(t, err)
+
+	filePath := filepath.Join(tmpDir, "20250903T083109--test-file.pdf")
+	err = os.WriteFile(filePath, []byte("test content"), 0644)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	err = SetTags(filePath, []string{"undefined"}, TagOptions{Writer: buf})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "undefined tags in tags.toml: undefined")
+
+	err = SetTags(filePath, []string{"infra"}, TagOptions{Writer: buf})
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(tmpDir, "20250903T083109--test-file__infra.pdf"))
+}
+
+func TestResolveTagsFile(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+
+	// tmpDir/tags.toml, tmpDir/shared/custom.toml, tmpDir/a/b（tags.tomlなし）
+	rootTags := filepath.Join(tmpDir, TagsFileName)
+	require.NoError(t, os.WriteFile(rootTags, []byte("[[tag]]\nkey = \"infra\"\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "shared"), 0755))
+	customTags := filepath.Join(tmpDir, "shared", "custom.toml")
+	require.NoError(t, os.WriteFile(customTags, []byte("[[tag]]\nkey = \"custom\"\n"), 0644))
+	nested := filepath.Join(tmpDir, "a", "b")
+	require.NoError(t, os.MkdirAll(nested, 0755))
+
+	tests := []struct {
+		name     string
+		dir      string
+		tagsFile string
+		expected string
+	}{
+		{name: "same directory", dir: tmpDir, tagsFile: "", expected: rootTags},
+		{name: "found in parent", dir: nested, tagsFile: "", expected: rootTags},
+		{name: "relative path in parent", dir: nested, tagsFile: "shared/custom.toml", expected: customTags},
+		{name: "absolute path", dir: nested, tagsFile: customTags, expected: customTags},
+		{name: "not found", dir: nested, tagsFile: "missing.toml", expected: filepath.Join(nested, "missing.toml")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, ResolveTagsFile(tt.dir, tt.tagsFile))
+		})
+	}
+}
+
+func TestSetTags_TagsFile(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "custom.toml"), []byte("[[tag]]\nkey = \"custom\"\n"), 0644))
+	docsDir := filepath.Join(tmpDir, "docs")
+	require.NoError(t, os.Mkdir(docsDir, 0755))
+	filePath := filepath.Join(docsDir, "20250903T083109--test-file.pdf")
+	require.NoError(t, os.WriteFile(filePath, []byte("test content"), 0644))
+
+	// 親ディレクトリの custom.toml で検証する
+	err := SetTags(filePath, []string{"infra"}, TagOptions{Writer: &bytes.Buffer{}, TagsFile: "custom.toml"})
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "tags.toml not found or empty")
+
+	err = SetTags(filePath, []string{"custom"}, TagOptions{Writer: &bytes.Buffer{}, TagsFile: "custom.toml"})
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(docsDir, "20250903T083109--test-file__custom.pdf"))
 }
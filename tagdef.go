@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// ListTagDefinitions はディレクトリから解決した tags.toml に定義されたタグを一覧表示し、そのリストを返す
+// tagsFile が空の場合は tags.toml を使う
+func ListTagDefinitions(dirPath, tagsFile string, w io.Writer) ([]TagDefinition, error) {
+	reporter := ReporterFor(w)
+	reporter.Columns("tag", "key", "desc")
+
+	tomlPath := ResolveTagsFile(dirPath, tagsFile)
+	if _, err := os.Stat(tomlPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("tags file not found: %s", tomlPath)
+	}
+
+	definitions, err := LoadTagsFromTOML(tomlPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, def := range definitions {
+		reporter.Emit("tag", map[string]any{"key": def.Key, "desc": def.Desc}, "%s\t%s\n", def.Key, def.Desc)
+	}
+
+	return definitions, nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListTagDefinitions(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-tagdef-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	tomlContent := `[[tag]]
+key = "network"
+desc = "Network related"
+
+[[tag]]
+key = "infra"
+desc = "Infrastructure"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, TagsFileName), []byte(tomlContent), 0644))
+
+	buf := &bytes.Buffer{}
+	definitions, err := ListTagDefinitions(tmpDir, "", buf)
+	require.NoError(t, err)
+
+	require.Len(t, definitions, 2)
+	assert.Equal(t, "network\tNetwork related\ninfra\tInfrastructure\n", buf.String())
+}
+
+func TestListTagDefinitions_NoTagsFile(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-tagdef-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	_, err = ListTagDefinitions(tmpDir, "", &bytes.Buffer{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "tags file not found")
+}
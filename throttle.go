@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Throttle はファイルシステム操作の実行回数を一定の速度に制限する
+// ネットワークファイルシステムでメタデータ操作が集中するのを避けるために使う
+// nil の場合は制限しない
+type Throttle struct {
+	interval time.Duration // 操作の最小間隔
+	mu       sync.Mutex
+	next     time.Time // 次に操作できる時刻
+}
+
+// NewThrottle は1秒あたりの操作回数を指定してThrottleを作成する
+func NewThrottle(perSecond float64) *Throttle {
+	return &Throttle{
+		interval: time.Duration(float64(time.Second) / perSecond),
+	}
+}
+
+// ParseThrottle は "50/s" や "600/m" 形式の文字列からThrottleを作成する
+// 空文字列の場合は nil（制限なし）を返す
+func ParseThrottle(s string) (*Throttle, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	countStr, unit, ok := strings.Cut(s, "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid throttle format (expected N/s or N/m): %s", s)
+	}
+
+	count, err := strconv.ParseFloat(countStr, 64)
+	if err != nil || count <= 0 {
+		return nil, fmt.Errorf("invalid throttle count: %s", s)
+	}
+
+	switch unit {
+	case "s":
+		return NewThrottle(count), nil
+	case "m":
+		return NewThrottle(count / 60), nil
+	default:
+		return nil, fmt.Errorf("invalid throttle unit (expected s or m): %s", s)
+	}
+}
+
+// Wait は次の操作が許可されるまで待機する
+func (t *Throttle) Wait() {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	now := time.Now()
+	wait := t.next.Sub(now)
+	if wait < 0 {
+		wait = 0
+		t.next = now
+	}
+	t.next = t.next.Add(t.interval)
+	t.mu.Unlock()
+
+	time.Sleep(wait)
+}
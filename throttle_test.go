@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseThrottle(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name             string
+		input            string
+		expectedInterval time.Duration
+		wantError        bool
+	}{
+		{
+			name:             "per second",
+			input:            "50/s",
+			expectedInterval: 20 * time.Millisecond,
+		},
+		{
+			name:             "per minute",
+			input:            "120/m",
+			expectedInterval: 500 * time.Millisecond,
+		},
+		{
+			name:      "missing unit",
+			input:     "50",
+			wantError: true,
+		},
+		{
+			name:      "invalid unit",
+			input:     "50/h",
+			wantError: true,
+		},
+		{
+			name:      "zero",
+			input:     "0/s",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			throttle, err := ParseThrottle(tt.input)
+			if tt.wantError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedInterval, throttle.interval)
+		})
+	}
+}
+
+func TestParseThrottle_Empty(t *testing.T) {
+	t.Parallel()
+	throttle, err := ParseThrottle("")
+	require.NoError(t, err)
+	assert.Nil(t, throttle)
+
+	// nil の場合は待機しない
+	throttle.Wait()
+}
+
+func TestThrottle_Wait(t *testing.T) {
+	t.Parallel()
+	throttle := NewThrottle(100) // 10ms 間隔
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		throttle.Wait()
+	}
+	elapsed := time.Since(start)
+
+	// 最初の操作は即時、残り5回で少なくとも50ms
+	assert.GreaterOrEqual(t, elapsed, 50*time.Millisecond)
+}
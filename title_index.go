@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// TitleIndex はタイトルとタグの前方一致検索を行うためのインメモリインデックス
+// 単語ごとにトライ木へ登録し、ファイルの追加・削除に合わせて差分更新できる
+type TitleIndex struct {
+	root  *trieNode                  // トライ木の根
+	terms map[string][]string        // ID -> 登録した単語（削除用）
+	files map[string]string          // ID -> ファイル名
+	tags  map[string]map[string]bool // タグ -> そのタグを持つID（補完用）
+}
+
+// trieNode はトライ木のノード
+type trieNode struct {
+	children map[rune]*trieNode
+	ids      map[string]bool // この単語で終わるID
+}
+
+func newTrieNode() *trieNode {
+	return &trieNode{
+		children: make(map[rune]*trieNode),
+		ids:      make(map[string]bool),
+	}
+}
+
+// NewTitleIndex は空のインデックスを作成する
+func NewTitleIndex() *TitleIndex {
+	return &TitleIndex{
+		root:  newTrieNode(),
+		terms: make(map[string][]string),
+		files: make(map[string]string),
+		tags:  make(map[string]map[string]bool),
+	}
+}
+
+// BuildTitleIndex はディレクトリ内のフォーマット済みファイルからインデックスを作成する
+func BuildTitleIndex(dirPath string, filter FilterOptions) (*TitleIndex, error) {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	index := NewTitleIndex()
+	for _, entry := range entries {
+		if entry.IsDir() || IsShim(dirPath, entry) || !filter.Matches(entry.Name()) {
+			continue
+		}
+		index.AddFile(entry.Name())
+	}
+
+	return index, nil
+}
+
+// Len は登録されているファイル数を返す
+func (idx *TitleIndex) Len() int {
+	return len(idx.files)
+}
+
+// AddFile はフォーマット済みファイル名をインデックスに追加する
+// 同じIDが既に登録されている場合は置き換える。フォーマット外のファイルは無視する
+func (idx *TitleIndex) AddFile(fileName string) bool {
+	components, err := parakeet.ParseFileName(fileName)
+	if err != nil {
+		return false
+	}
+
+	id := components.Timestamp
+	idx.Remove(id)
+
+	terms := indexTerms(components.Comment, components.Tags)
+	for _, term := range terms {
+		node := idx.root
+		for _, r := range term {
+			child, ok := node.children[r]
+			if !ok {
+				child = newTrieNode()
+				node.children[r] = child
+			}
+			node = child
+		}
+		node.ids[id] = true
+	}
+
+	for _, tag := range components.Tags {
+		if idx.tags[tag] == nil {
+			idx.tags[tag] = make(map[string]bool)
+		}
+		idx.tags[tag][id] = true
+	}
+
+	idx.terms[id] = terms
+	idx.files[id] = fileName
+
+	return true
+}
+
+// Remove はIDをインデックスから削除する
+func (idx *TitleIndex) Remove(id string) {
+	terms, ok := idx.terms[id]
+	if !ok {
+		return
+	}
+
+	for _, term := range terms {
+		idx.removeTerm(term, id)
+	}
+
+	if components, err := parakeet.ParseFileName(idx.files[id]); err == nil {
+		for _, tag := range components.Tags {
+			delete(idx.tags[tag], id)
+			if len(idx.tags[tag]) == 0 {
+				delete(idx.tags, tag)
+			}
+		}
+	}
+
+	delete(idx.terms, id)
+	delete(idx.files, id)
+}
+
+// removeTerm は単語からIDを削除し、不要になったノードを刈り取る
+func (idx *TitleIndex) removeTerm(term, id string) {
+	path := []*trieNode{idx.root}
+	runes := []rune(term)
+	node := idx.root
+	for _, r := range runes {
+		child, ok := node.children[r]
+		if !ok {
+			return
+		}
+		node = child
+		path = append(path, node)
+	}
+	delete(node.ids, id)
+
+	// 葉から順に空のノードを削除
+	for i := len(runes); i > 0; i-- {
+		n := path[i]
+		if len(n.ids) > 0 || len(n.children) > 0 {
+			break
+		}
+		delete(path[i-1].children, runes[i-1])
+	}
+}
+
+// PrefixSearch は前方一致するタイトルの単語またはタグを持つファイル名をID順で返す
+// 複数の単語を空白区切りで指定した場合は、すべての単語に一致するファイルを返す
+func (idx *TitleIndex) PrefixSearch(query string) []string {
+	words := splitTerms(query)
+	if len(words) == 0 {
+		return []string{}
+	}
+
+	var matched map[string]bool
+	for _, word := range words {
+		ids := idx.prefixIDs(word)
+		if matched == nil {
+			matched = ids
+			continue
+		}
+		for id := range matched {
+			if !ids[id] {
+				delete(matched, id)
+			}
+		}
+	}
+
+	ids := make([]string, 0, len(matched))
+	for id := range matched {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	files := make([]string, 0, len(ids))
+	for _, id := range ids {
+		files = append(files, idx.files[id])
+	}
+
+	return files
+}
+
+// CompleteTags は前方一致する（大文字小文字を区別しない）タグを名前順で返す
+func (idx *TitleIndex) CompleteTags(prefix string) []string {
+	lowerPrefix := strings.ToLower(prefix)
+	tags := []string{}
+	for tag := range idx.tags {
+		if strings.HasPrefix(strings.ToLower(tag), lowerPrefix) {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// CompleteIDs は前方一致するIDを順に返す
+func (idx *TitleIndex) CompleteIDs(prefix string) []string {
+	ids := []string{}
+	for id := range idx.files {
+		if strings.HasPrefix(id, prefix) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// File はIDに対応するファイル名を返す
+func (idx *TitleIndex) File(id string) (string, bool) {
+	fileName, ok := idx.files[id]
+	return fileName, ok
+}
+
+// prefixIDs は前方一致する単語を持つIDの集合を返す
+func (idx *TitleIndex) prefixIDs(prefix string) map[string]bool {
+	ids := make(map[string]bool)
+
+	node := idx.root
+	for _, r := range prefix {
+		child, ok := node.children[r]
+		if !ok {
+			return ids
+		}
+		node = child
+	}
+
+	// 部分木を走査してIDを収集
+	stack := []*trieNode{node}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for id := range n.ids {
+			ids[id] = true
+		}
+		for _, child := range n.children {
+			stack = append(stack, child)
+		}
+	}
+
+	return ids
+}
+
+// indexTerms はタイトルとタグから登録する単語を作成する
+// タイトル全体も1つの単語として登録し、空白を含む前方一致にも対応する
+func indexTerms(title string, tags []string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	add := func(term string) {
+		if term != "" && !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+
+	add(strings.ToLower(title))
+	for _, word := range splitTerms(title) {
+		add(word)
+	}
+	for _, tag := range tags {
+		add(strings.ToLower(tag))
+	}
+
+	return terms
+}
+
+// splitTerms は文字列を小文字の単語に分割する
+func splitTerms(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTitleIndex_PrefixSearch(t *testing.T) {
+	t.Parallel()
+	index := NewTitleIndex()
+	index.AddFile("20250903T083109--TCPIP入門__network_infra.pdf")
+	index.AddFile("20250903T083110--Go Programming__backend.pdf")
+	index.AddFile("20250903T083111--Golang Tips.md")
+	assert.False(t, index.AddFile("invalid-file.txt"))
+	assert.Equal(t, 3, index.Len())
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{
+			name:     "title word prefix",
+			query:    "go",
+			expected: []string{"20250903T083110--Go Programming__backend.pdf", "20250903T083111--Golang Tips.md"},
+		},
+		{
+			name:     "case insensitive",
+			query:    "TCP",
+			expected: []string{"20250903T083109--TCPIP入門__network_infra.pdf"},
+		},
+		{
+			name:     "tag prefix",
+			query:    "net",
+			expected: []string{"20250903T083109--TCPIP入門__network_infra.pdf"},
+		},
+		{
+			name:     "multiple words are AND",
+			query:    "go prog",
+			expected: []string{"20250903T083110--Go Programming__backend.pdf"},
+		},
+		{
+			name:     "no match",
+			query:    "rust",
+			expected: []string{},
+		},
+		{
+			name:     "empty query",
+			query:    "",
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, index.PrefixSearch(tt.query))
+		})
+	}
+}
+
+func TestTitleIndex_IncrementalUpdate(t *testing.T) {
+	t.Parallel()
+	index := NewTitleIndex()
+	index.AddFile("20250903T083109--old title.pdf")
+	assert.Len(t, index.PrefixSearch("old"), 1)
+
+	// 同じIDで追加すると置き換わる
+	index.AddFile("20250903T083109--new title__infra.pdf")
+	assert.Empty(t, index.PrefixSearch("old"))
+	assert.Equal(t, []string{"20250903T083109--new title__infra.pdf"}, index.PrefixSearch("new"))
+	assert.Equal(t, 1, index.Len())
+
+	// 削除すると検索されない
+	index.Remove("20250903T083109")
+	assert.Empty(t, index.PrefixSearch("new"))
+	assert.Empty(t, index.PrefixSearch("infra"))
+	assert.Equal(t, 0, index.Len())
+	assert.Empty(t, index.root.children, "empty nodes should be pruned")
+}
+
+func TestTitleIndex_Complete(t *testing.T) {
+	t.Parallel()
+	index := NewTitleIndex()
+	index.AddFile("20250903T083109--TCPIP入門__network_infra.pdf")
+	index.AddFile("20250910T120000--Go入門__go_network.pdf")
+	index.AddFile("20251001T000000--メモ__News.md")
+
+	assert.Equal(t, []string{"News", "network"}, index.CompleteTags("ne"))
+	assert.Equal(t, []string{"News", "go", "infra", "network"}, index.CompleteTags(""))
+	assert.Equal(t, []string{"20250903T083109", "20250910T120000"}, index.CompleteIDs("202509"))
+
+	// 削除したファイルのタグとIDは候補から外れる
+	index.Remove("20250903T083109")
+	assert.Equal(t, []string{"News", "go", "network"}, index.CompleteTags(""))
+	assert.Empty(t, index.CompleteTags("infra"))
+	assert.Equal(t, []string{"20250910T120000"}, index.CompleteIDs("202509"))
+}
+
+func TestBuildTitleIndex(t *testing.T) {
+	t.Parallel()
+	// Create temporary directory
+	tmpDir, err := os.MkdirTemp("", "parakeet-title-index-*")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for i := 0; i < 5; i++ {
+		name := fmt.Sprintf("20250903T08310%d--note %d.md", i, i)
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("x"), 0644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250903T083109--note pdf.pdf"), []byte("x"), 0644))
+
+	index, err := BuildTitleIndex(tmpDir, FilterOptions{Extensions: []string{"md"}})
+	require.NoError(t, err)
+	assert.Equal(t, 5, index.Len())
+	assert.Len(t, index.PrefixSearch("note"), 5)
+
+	_, err = BuildTitleIndex("/non/existent", FilterOptions{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// renameStep はトランザクション内の1件のリネームを表す
+type renameStep struct {
+	From string // 旧パス
+	To   string // 新パス
+}
+
+// renameTransaction は予定したリネームを記録してまとめて実行する
+// 途中のリネームが失敗した場合は、実行済みのリネームを逆順に元に戻してディレクトリを実行前の状態にする
+type renameTransaction struct {
+	fsys     FileSystem
+	throttle *Throttle // リネームの速度制限（nil の場合は制限なし）
+	steps    []renameStep
+	applied  int // 実行済みのリネームの数
+}
+
+// newRenameTransaction は fsys 上のリネームのトランザクションを作成する
+func newRenameTransaction(fsys FileSystem) *renameTransaction {
+	return &renameTransaction{fsys: fsys}
+}
+
+// Add はリネームを予定に加える
+func (tx *renameTransaction) Add(from, to string) {
+	tx.steps = append(tx.steps, renameStep{From: from, To: to})
+}
+
+// Commit は予定したリネームを順に実行する
+// 失敗した場合は実行済みのリネームを元に戻し、元に戻せなかったリネームもエラーに含める
+func (tx *renameTransaction) Commit() error {
+	for tx.applied < len(tx.steps) {
+		step := tx.steps[tx.applied]
+		tx.throttle.Wait()
+		if err := tx.fsys.Rename(step.From, step.To); err != nil {
+			rolledBack := tx.applied
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				return fmt.Errorf("failed to rename file: %w (rollback failed: %v)", err, rollbackErr)
+			}
+			return fmt.Errorf("failed to rename file: %w (rolled back %d renames)", err, rolledBack)
+		}
+		tx.applied++
+	}
+
+	return nil
+}
+
+// Rollback は実行済みのリネームを逆順に元に戻す
+// 元に戻せなかったリネームがあっても残りは続け、まとめてエラーを返す
+func (tx *renameTransaction) Rollback() error {
+	var errs []error
+	for ; tx.applied > 0; tx.applied-- {
+		step := tx.steps[tx.applied-1]
+		if err := tx.fsys.Rename(step.To, step.From); err != nil {
+			errs = append(errs, fmt.Errorf("%s → %s: %w", step.To, step.From, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"sort"
+	"testing"
+	"testing/fstest"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingRenameFS は旧ファイル名が failOn のファイルのリネームを失敗させるファイルシステム
+type failingRenameFS struct {
+	*MemFileSystem
+	failOn string
+}
+
+func (f failingRenameFS) Rename(oldpath, newpath string) error {
+	if filepath.Base(oldpath) == f.failOn {
+		return errors.New("disk full")
+	}
+	return f.MemFileSystem.Rename(oldpath, newpath)
+}
+
+func TestRenameTransaction(t *testing.T) {
+	t.Parallel()
+
+	t.Run("commit", func(t *testing.T) {
+		t.Parallel()
+		files := fstest.MapFS{"a": {}, "b": {}}
+		tx := newRenameTransaction(NewMemFileSystem(files))
+		tx.Add("a", "x")
+		tx.Add("b", "y")
+		require.NoError(t, tx.Commit())
+		assert.Contains(t, files, "x")
+		assert.Contains(t, files, "y")
+	})
+
+	t.Run("rollback on failure", func(t *testing.T) {
+		t.Parallel()
+		files := fstest.MapFS{"a": {}, "b": {}, "c": {}}
+		tx := newRenameTransaction(failingRenameFS{MemFileSystem: NewMemFileSystem(files), failOn: "c"})
+		tx.Add("a", "x")
+		tx.Add("b", "y")
+		tx.Add("c", "z")
+		err := tx.Commit()
+		assert.ErrorContains(t, err, "disk full (rolled back 2 renames)")
+		assert.Equal(t, []string{"a", "b", "c"}, memFileNames(files))
+	})
+}
+
+func TestGenerateFileNames_Atomic(t *testing.T) {
+	t.Parallel()
+	newFiles := func() fstest.MapFS {
+		return fstest.MapFS{
+			"a.pdf": {Data: []byte("a")},
+			"b.pdf": {Data: []byte("b")},
+			"c.pdf": {Data: []byte("c")},
+		}
+	}
+
+	t.Run("all renamed", func(t *testing.T) {
+		t.Parallel()
+		files := newFiles()
+		opts := RenameOptions{Writer: &bytes.Buffer{}, FilterOptions: FilterOptions{Extensions: []string{"pdf"}}, Atomic: true, FS: NewMemFileSystem(files)}
+		require.NoError(t, GenerateFileNames(context.Background(), ".", opts))
+		for name := range files {
+			assert.True(t, parakeet.IsFormatted(name), name)
+		}
+	})
+
+	t.Run("rolled back on failure", func(t *testing.T) {
+		t.Parallel()
+		files := newFiles()
+		opts := RenameOptions{Writer: &bytes.Buffer{}, FilterOptions: FilterOptions{Extensions: []string{"pdf"}}, Atomic: true, FS: failingRenameFS{MemFileSystem: NewMemFileSystem(files), failOn: "c.pdf"}}
+		err := GenerateFileNames(context.Background(), ".", opts)
+		assert.ErrorContains(t, err, "rolled back 2 renames")
+		assert.Equal(t, []string{"a.pdf", "b.pdf", "c.pdf"}, memFileNames(files))
+	})
+
+	t.Run("without atomic the other files are renamed", func(t *testing.T) {
+		t.Parallel()
+		files := newFiles()
+		buf := &bytes.Buffer{}
+		opts := RenameOptions{Writer: buf, FilterOptions: FilterOptions{Extensions: []string{"pdf"}}, FS: failingRenameFS{MemFileSystem: NewMemFileSystem(files), failOn: "c.pdf"}}
+		require.NoError(t, GenerateFileNames(context.Background(), ".", opts))
+		assert.Contains(t, buf.String(), "c.pdf (rename failed: disk full)")
+		formatted := 0
+		for name := range files {
+			if parakeet.IsFormatted(name) {
+				formatted++
+			}
+		}
+		assert.Equal(t, 2, formatted)
+	})
+}
+
+// memFileNames はメモリ上のファイルシステムのファイル名をソートして返す
+func memFileNames(files fstest.MapFS) []string {
+	keys := []string{}
+	for name := range files {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+	return keys
+}
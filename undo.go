@@ -0,0 +1,195 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// UndoOptions は直前のリネームの取り消しのオプションを表す
+type UndoOptions struct {
+	Writer io.Writer   // 出力先
+	DryRun bool        // 実際には元に戻さない
+	Hooks  *HookRunner // リネームの前後に実行するフック（nil の場合は実行しない）
+}
+
+// UndoLastBatch はディレクトリのジャーナルに記録された直近のバッチ（1回のコマンドの実行）のリネームを元に戻す
+// 取り消したバッチは undo として記録し、続けて実行するとさらに前のバッチを取り消す
+// リネーム後にファイルが変更・移動された場合や旧ファイル名が使われている場合は何も元に戻さずにエラーを返す
+func UndoLastBatch(dirPath string, opts UndoOptions) ([]JournalEntry, error) {
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("restored", "command", "from", "to", "dry_run")
+
+	// ディレクトリの存在チェック
+	if _, err := os.Stat(dirPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", dirPath)
+	}
+
+	entries, err := ReadJournal(JournalPath(dirPath))
+	if err != nil {
+		return nil, err
+	}
+
+	batch := lastUndoableBatch(entries)
+	if len(batch) == 0 {
+		reporter.Printf("No operations to undo\n")
+		return batch, nil
+	}
+	key := batch[0].BatchKey()
+
+	// すべてのファイルを確認してから元に戻す
+	shims := []string{}
+	for _, entry := range batch {
+		shim, err := checkUndoable(entry)
+		if err != nil {
+			return nil, err
+		}
+		if shim != "" {
+			shims = append(shims, shim)
+		}
+	}
+
+	prefix := ""
+	if opts.DryRun {
+		prefix = "[dry-run] "
+	}
+
+	if !opts.DryRun {
+		// 旧ファイル名に残したシムは退避し、元に戻せた場合のみ削除する
+		aside, err := moveShimsAside(shims)
+		if err != nil {
+			return nil, err
+		}
+
+		// 後に実行したリネームから順に元に戻す
+		tx := newRenameTransaction(opts.Hooks.Wrap(OSFileSystem))
+		for i := len(batch) - 1; i >= 0; i-- {
+			entry := batch[i]
+			tx.Add(filepath.Join(entry.Dir, entry.To), filepath.Join(entry.Dir, entry.From))
+		}
+		if err := tx.Commit(); err != nil {
+			if restoreErr := restoreShims(shims, aside); restoreErr != nil {
+				return nil, fmt.Errorf("%w (failed to restore shims: %v)", err, restoreErr)
+			}
+			return nil, err
+		}
+
+		for _, path := range aside {
+			if err := os.Remove(path); err != nil {
+				reporter.Warnf("%s (failed to remove shim: %v)\n", path, err)
+			}
+		}
+
+		journal := NewJournal(dirPath, "undo")
+		journal.Undo = key
+		for i := len(batch) - 1; i >= 0; i-- {
+			entry := batch[i]
+			if err := journal.Record(entry.Dir, renamePlan{From: entry.To, To: entry.From}); err != nil {
+				reporter.Warnf("%s (%v)\n", entry.To, err)
+			}
+		}
+	}
+
+	for i := len(batch) - 1; i >= 0; i-- {
+		entry := batch[i]
+		reporter.Emit("restored", map[string]any{"command": entry.Command, "from": entry.To, "to": entry.From, "dry_run": opts.DryRun}, "%s✓ Restored: %s → %s\n", prefix, entry.To, entry.From)
+	}
+
+	// サマリーを出力
+	reporter.Printf("\nUndo Summary:\n")
+	reporter.Printf("  Command: %s (%s)\n", batch[0].Command, batch[0].Time)
+	reporter.Printf("  Restored: %d\n", len(batch))
+
+	return batch, nil
+}
+
+// moveShimsAside はシムを同じディレクトリの一時的な名前に退避し、退避先のパスを返す
+// 途中で失敗した場合は退避したシムを戻す
+func moveShimsAside(shims []string) ([]string, error) {
+	aside := make([]string, 0, len(shims))
+	for _, shim := range shims {
+		path := filepath.Join(filepath.Dir(shim), ".parakeet-undo-"+filepath.Base(shim))
+		if err := os.Rename(shim, path); err != nil {
+			if restoreErr := restoreShims(shims, aside); restoreErr != nil {
+				return nil, fmt.Errorf("failed to move shim: %w (failed to restore shims: %v)", err, restoreErr)
+			}
+			return nil, fmt.Errorf("failed to move shim: %w", err)
+		}
+		aside = append(aside, path)
+	}
+	return aside, nil
+}
+
+// restoreShims は退避したシムを元のパスに戻す
+func restoreShims(shims, aside []string) error {
+	var errs []error
+	for i, path := range aside {
+		if err := os.Rename(path, shims[i]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", shims[i], err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// lastUndoableBatch はまだ取り消していない直近のバッチの記録を古い順に返す
+// undo 自体の記録は取り消しの対象にしない
+func lastUndoableBatch(entries []JournalEntry) []JournalEntry {
+	undone := map[string]bool{}
+	for _, entry := range entries {
+		if entry.Command == "undo" && entry.Undo != "" {
+			undone[entry.Undo] = true
+		}
+	}
+
+	key := ""
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if entry.Command != "undo" && !undone[entry.BatchKey()] {
+			key = entry.BatchKey()
+			break
+		}
+	}
+
+	batch := []JournalEntry{}
+	if key == "" {
+		return batch
+	}
+	for _, entry := range entries {
+		if entry.Command != "undo" && entry.BatchKey() == key {
+			batch = append(batch, entry)
+		}
+	}
+	return batch
+}
+
+// checkUndoable はリネームを元に戻せるかを確認する
+// 旧ファイル名に新ファイルへのシムがある場合はそのパスを返す
+func checkUndoable(entry JournalEntry) (string, error) {
+	fromPath := filepath.Join(entry.Dir, entry.From)
+	toPath := filepath.Join(entry.Dir, entry.To)
+
+	info, err := os.Stat(toPath)
+	if err != nil {
+		return "", fmt.Errorf("file has been renamed or removed since %s: %s", entry.Command, entry.To)
+	}
+	if entry.ModTime != "" {
+		modTime, err := time.Parse(time.RFC3339Nano, entry.ModTime)
+		if err == nil && (!info.ModTime().Equal(modTime) || info.Size() != entry.Size) {
+			return "", fmt.Errorf("file has been modified since %s: %s", entry.Command, entry.To)
+		}
+	}
+
+	fromInfo, err := os.Lstat(fromPath)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err == nil && fromInfo.Mode()&os.ModeSymlink != 0 {
+		if target, err := os.Readlink(fromPath); err == nil && filepath.Join(filepath.Dir(fromPath), target) == toPath {
+			return fromPath, nil
+		}
+	}
+	return "", fmt.Errorf("target file already exists: %s", entry.From)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUndoLastBatch(t *testing.T) {
+	t.Parallel()
+
+	// generate の後に tag を実行したディレクトリを作る
+	setup := func(t *testing.T) string {
+		t.Helper()
+		tmpDir, err := os.MkdirTemp("", "parakeet-undo-*")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "a.pdf"), []byte("a"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "b.pdf"), []byte("b"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250903T083109--notes.pdf"), []byte("notes"), 0644))
+
+		require.NoError(t, GenerateFileNames(context.Background(), tmpDir, RenameOptions{
+			Writer:        &bytes.Buffer{},
+			FilterOptions: FilterOptions{Extensions: []string{"pdf"}},
+			Journal:       NewJournal(tmpDir, "generate"),
+		}))
+		require.NoError(t, SetTags(filepath.Join(tmpDir, "20250903T083109--notes.pdf"), []string{"work"}, TagOptions{
+			Writer:  &bytes.Buffer{},
+			Journal: NewJournal(tmpDir, "tag"),
+		}))
+		return tmpDir
+	}
+
+	t.Run("undo batches in reverse order", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setup(t)
+
+		batch, err := UndoLastBatch(tmpDir, UndoOptions{Writer: &bytes.Buffer{}})
+		require.NoError(t, err)
+		require.Len(t, batch, 1)
+		assert.Equal(t, "tag", batch[0].Command)
+		assert.FileExists(t, filepath.Join(tmpDir, "20250903T083109--notes.pdf"))
+
+		batch, err = UndoLastBatch(tmpDir, UndoOptions{Writer: &bytes.Buffer{}})
+		require.NoError(t, err)
+		assert.Len(t, batch, 2)
+		assert.FileExists(t, filepath.Join(tmpDir, "a.pdf"))
+		assert.FileExists(t, filepath.Join(tmpDir, "b.pdf"))
+
+		buf := &bytes.Buffer{}
+		batch, err = UndoLastBatch(tmpDir, UndoOptions{Writer: buf})
+		require.NoError(t, err)
+		assert.Empty(t, batch)
+		assert.Contains(t, buf.String(), "No operations to undo")
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setup(t)
+
+		buf := &bytes.Buffer{}
+		_, err := UndoLastBatch(tmpDir, UndoOptions{Writer: buf, DryRun: true})
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "[dry-run] ✓ Restored: 20250903T083109--notes__work.pdf → 20250903T083109--notes.pdf")
+		assert.FileExists(t, filepath.Join(tmpDir, "20250903T083109--notes__work.pdf"))
+	})
+
+	t.Run("refuse modified file", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setup(t)
+
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250903T083109--notes__work.pdf"), []byte("edited"), 0644))
+		_, err := UndoLastBatch(tmpDir, UndoOptions{Writer: &bytes.Buffer{}})
+		assert.ErrorContains(t, err, "file has been modified since tag")
+		assert.FileExists(t, filepath.Join(tmpDir, "20250903T083109--notes__work.pdf"))
+	})
+
+	t.Run("refuse renamed file", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setup(t)
+
+		require.NoError(t, os.Rename(filepath.Join(tmpDir, "20250903T083109--notes__work.pdf"), filepath.Join(tmpDir, "20250903T083109--other.pdf")))
+		_, err := UndoLastBatch(tmpDir, UndoOptions{Writer: &bytes.Buffer{}})
+		assert.ErrorContains(t, err, "file has been renamed or removed since tag")
+	})
+
+	t.Run("replace shim", func(t *testing.T) {
+		t.Parallel()
+		tmpDir, err := os.MkdirTemp("", "parakeet-undo-*")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "report.pdf"), []byte("report"), 0644))
+		require.NoError(t, GenerateFileNames(context.Background(), tmpDir, RenameOptions{
+			Writer:        &bytes.Buffer{},
+			FilterOptions: FilterOptions{Extensions: []string{"pdf"}},
+			Shims:         true,
+			Journal:       NewJournal(tmpDir, "generate"),
+		}))
+
+		_, err = UndoLastBatch(tmpDir, UndoOptions{Writer: &bytes.Buffer{}})
+		require.NoError(t, err)
+		info, err := os.Lstat(filepath.Join(tmpDir, "report.pdf"))
+		require.NoError(t, err)
+		assert.True(t, info.Mode().IsRegular())
+	})
+
+	t.Run("keep shim when undo fails", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setupHooksDir(t, map[string]string{HookPreRename: "exit 1"})
+
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "report.pdf"), []byte("report"), 0644))
+		require.NoError(t, GenerateFileNames(context.Background(), tmpDir, RenameOptions{
+			Writer:        &bytes.Buffer{},
+			FilterOptions: FilterOptions{Extensions: []string{"pdf"}},
+			Shims:         true,
+			Journal:       NewJournal(tmpDir, "generate"),
+		}))
+
+		_, err := UndoLastBatch(tmpDir, UndoOptions{Writer: &bytes.Buffer{}, Hooks: NewHookRunner(&bytes.Buffer{})})
+		require.Error(t, err)
+		info, err := os.Lstat(filepath.Join(tmpDir, "report.pdf"))
+		require.NoError(t, err)
+		assert.NotZero(t, info.Mode()&os.ModeSymlink)
+		entries, err := os.ReadDir(tmpDir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 3) // シム・リネームしたファイル・.parakeet
+	})
+}
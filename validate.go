@@ -1,16 +1,33 @@
 package main
 
 import (
+	"bufio"
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
 )
 
 // ValidateOptions はバリデーション操作のオプションを表す
 type ValidateOptions struct {
-	Writer     io.Writer // 出力先
-	Extensions []string  // 対象拡張子（空の場合は全ファイル）
+	Writer io.Writer // 出力先
+	FilterOptions
+	TagCoverage     TagCoverageRule // 未定義タグのしきい値（未設定の場合は未定義タグが1つでもあれば失敗）
+	DuplicatePolicy DuplicatePolicy // タイムスタンプ重複の扱い（空の場合は error）
+	TagsFile        string          // タグ定義ファイル（空の場合は tags.toml、ResolveTagsFile で解決する）
+	Quota           QuotaConfig     // ディレクトリ・タグごとの上限（超えた場合は警告のみ）
+	FS              FileSystem      // ファイルシステム（nil の場合は OS のファイルシステム）
+	Strict          bool            // 重複（warn の場合も）と未定義タグ（しきい値に関係なく）も失敗とする
+	Vault           string          // wiki リンクを検証する Obsidian vault のルート（空の場合は検証しない）
+	Rules           RuleSeverities  // ルールごとの重さ（設定がないルールはデフォルト）
+	MaxNameBytes    int             // max-length ルールのファイル名の長さの上限（バイト数、0 以下の場合は DefaultMaxNameBytes）
+	CustomRules     []CustomRule    // 設定ファイルで定義したルール（CompileCustomRules でコンパイルしたもの）
+	Jobs            int             // エントリの stat・パース・タグの定義チェックを並列に行う数（1 以下の場合は並列にしない）
 }
 
 // ValidateResult はバリデーション結果を表す
@@ -18,153 +35,500 @@ type ValidateResult struct {
 	TotalFiles        int                 // 総ファイル数
 	ValidFiles        int                 // 有効なファイル数
 	InvalidFiles      []string            // 無効なファイル名のリスト
+	InvalidTimestamps []string            // 無効なファイル名のうち、タイムスタンプが実在する日時でないもののリスト
 	DuplicateFiles    []string            // 重複するタイムスタンプを持つファイルのリスト
-	HasDuplicates     bool                // 重複があるかどうか
+	HasDuplicates     bool                // 許可されていない重複があるかどうか
+	DuplicatesFail    bool                // 重複を失敗とするかどうか
 	UndefinedTagFiles map[string][]string // 未定義タグを持つファイル: ファイル名 -> 未定義タグリスト
 	HasUndefinedTags  bool                // 未定義タグがあるかどうか
+	SimilarTitleFiles map[string][]string // 正規化すると同じになるタイトルを持つファイル: 正規化タイトル -> ファイル名リスト
+	UndefinedTags     []string            // 異なる未定義タグのリスト（ソート済み）
+	UndefinedPercent  float64             // 未定義タグを持つファイルの割合（有効なファイルに対する%）
+	UndefinedTagsFail bool                // 未定義タグがしきい値を超えて失敗とするかどうか
+	QuotaExceeded     []QuotaExceeded     // 上限を超えたディレクトリとタグ（警告のみ）
+	BrokenLinks       []BrokenWikiLink    // ノートに解決できない wiki リンク（Obsidian vault の場合のみ、警告のみ）
+	LongNames         []string            // 無効なファイル名のうち、ファイル名が長すぎるもののリスト（max-length が error の場合）
+	NonPortableNames  []string            // 無効なファイル名のうち、Windows で使えないもののリスト（windows-name, max-path が error の場合）
+	RuleWarnings      map[string][]string // 重さを warning にしたルールの違反: ルールID -> ファイル名リスト
+	CustomRuleErrors  map[string]string   // 無効なファイル名のうち、設定ファイルで定義したルールに違反したもの: ファイル名 -> ルール名
+}
+
+// HasErrors は validate を失敗とすべき問題があるかどうかを返す
+func (r *ValidateResult) HasErrors() bool {
+	return len(r.InvalidFiles) > 0 || r.DuplicatesFail || r.UndefinedTagsFail
+}
+
+// HasWarnings は警告のみの問題があるかどうかを返す
+func (r *ValidateResult) HasWarnings() bool {
+	return len(r.RuleWarnings) > 0 ||
+		(r.HasDuplicates && !r.DuplicatesFail) ||
+		(r.HasUndefinedTags && !r.UndefinedTagsFail) ||
+		len(r.SimilarTitleFiles) > 0 ||
+		len(r.BrokenLinks) > 0 ||
+		len(r.QuotaExceeded) > 0
 }
 
 // ValidateFileNames はディレクトリ内のファイル名をバリデーションする
-func ValidateFileNames(targetDir string, opts ValidateOptions) (*ValidateResult, error) {
+// ctx がキャンセルされた場合は検証を止めてキャンセルの原因を返す
+func ValidateFileNames(ctx context.Context, targetDir string, opts ValidateOptions) (*ValidateResult, error) {
 	// ディレクトリの存在チェック
-	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+	if _, err := fileSystemOrOS(opts.FS).Stat(targetDir); os.IsNotExist(err) {
 		return nil, fmt.Errorf("directory does not exist: %s", targetDir)
 	}
 
-	// ディレクトリを読み込む
-	entries, err := os.ReadDir(targetDir)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read directory: %w", err)
+	return validateDirectories(ctx, []string{targetDir}, nil, opts)
+}
+
+// ValidateFilePaths は指定されたファイルのファイル名のみをバリデーションする
+// タイムスタンプの重複や類似タイトルは同じディレクトリの他のファイルとも比較する
+// 存在しないパス（削除されたファイルなど）とディレクトリは無視する
+func ValidateFilePaths(ctx context.Context, paths []string, opts ValidateOptions) (*ValidateResult, error) {
+	targets := make(map[string]bool)
+	dirs := []string{}
+	seenDirs := make(map[string]bool)
+	fsys := fileSystemOrOS(opts.FS)
+
+	for _, path := range paths {
+		info, err := fsys.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+
+		path = filepath.Clean(path)
+		targets[path] = true
+
+		if dir := filepath.Dir(path); !seenDirs[dir] {
+			seenDirs[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+
+	return validateDirectories(ctx, dirs, targets, opts)
+}
+
+// ReadPathList は1行に1つのパスが書かれたリストを読み込む
+// 空行は無視する
+func ReadPathList(r io.Reader) ([]string, error) {
+	paths := []string{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if path := strings.TrimSpace(scanner.Text()); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read path list: %w", err)
 	}
 
+	return paths, nil
+}
+
+// validateDirectories はディレクトリ内のファイル名をバリデーションする
+// targets が nil の場合はすべてのファイルを検証し、ファイル名で出力する
+// targets を指定した場合はそのパスのファイルのみを検証し、パスで出力する
+func validateDirectories(ctx context.Context, dirs []string, targets map[string]bool, opts ValidateOptions) (*ValidateResult, error) {
+	reporter := ReporterFor(opts.Writer)
+	if err := rejectTableFormat(reporter, "validate"); err != nil {
+		return nil, err
+	}
+	fsys := fileSystemOrOS(opts.FS)
+
 	result := &ValidateResult{
 		InvalidFiles:      []string{},
+		InvalidTimestamps: []string{},
 		DuplicateFiles:    []string{},
+		QuotaExceeded:     []QuotaExceeded{},
+		BrokenLinks:       []BrokenWikiLink{},
+		LongNames:         []string{},
+		NonPortableNames:  []string{},
 		UndefinedTagFiles: make(map[string][]string),
+		SimilarTitleFiles: make(map[string][]string),
+		RuleWarnings:      make(map[string][]string),
+		CustomRuleErrors:  make(map[string]string),
 	}
 
-	// タイムスタンプの出現回数を記録
-	timestampMap := make(map[string][]string)
+	// duplicate_policy が warn の場合、duplicate ルールのデフォルトは warning
+	duplicateSeverity := opts.Rules.Severity(RuleDuplicate)
+	if _, ok := opts.Rules[RuleDuplicate]; !ok && !opts.DuplicatePolicy.Fails() {
+		duplicateSeverity = SeverityWarning
+	}
+	undefinedTagSeverity := opts.Rules.Severity(RuleUndefinedTag)
+	maxLengthSeverity := opts.Rules.Severity(RuleMaxLength)
+	windowsNameSeverity := opts.Rules.Severity(RuleWindowsName)
+	maxPathSeverity := opts.Rules.Severity(RuleMaxPath)
+	maxNameBytes := opts.MaxNameBytes
+	if maxNameBytes <= 0 {
+		maxNameBytes = DefaultMaxNameBytes
+	}
 
-	// tags.tomlを読み込む（targetDir内に存在する場合）
-	tomlPath := filepath.Join(targetDir, TagsFileName)
-	tagDefs, err := LoadTagsFromTOML(tomlPath)
-	if err != nil {
-		// エラーがあっても続行（tags.tomlがない場合はタグチェックをスキップ）
-		tagDefs = []TagDefinition{}
+	// violateAs はルールの違反を重さに応じて出力し、無効なファイルとする（error の場合）と true を返す
+	violateAs := func(severity Severity, rule, name, format string, args ...any) bool {
+		switch severity {
+		case SeverityError:
+			result.InvalidFiles = append(result.InvalidFiles, name)
+			reporter.Errorf(format, args...)
+			return true
+		case SeverityWarning:
+			result.RuleWarnings[rule] = append(result.RuleWarnings[rule], name)
+			reporter.Warnf(format, args...)
+		}
+		return false
+	}
+	violate := func(rule, name, format string, args ...any) bool {
+		return violateAs(opts.Rules.Severity(rule), rule, name, format, args...)
 	}
 
-	// 定義済みタグのセットを作成
-	validTags := make(map[string]bool)
-	for _, tagDef := range tagDefs {
-		validTags[tagDef.Key] = true
+	// タイムスタンプの出現回数を記録（ディレクトリごと）
+	timestampMap := make(map[string][]string)
+
+	// 正規化したタイトルの出現回数を記録
+	titleMap := make(map[string][]string)
+
+	// 検証対象のファイルを含むかどうか
+	touched := func(files []string) bool {
+		if targets == nil {
+			return true
+		}
+		for _, file := range files {
+			if targets[file] {
+				return true
+			}
+		}
+		return false
 	}
-	hasTagDefinitions := len(validTags) > 0
 
-	for _, entry := range entries {
-		// ディレクトリはスキップ
-		if entry.IsDir() {
-			continue
+	for _, dir := range dirs {
+		// ディレクトリを読み込む
+		entries, err := fsys.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read directory: %w", err)
 		}
 
-		fileName := entry.Name()
+		// ディレクトリ・タグごとの上限をチェックする
+		exceeded, err := checkQuotas(fsys, dir, opts.Quota)
+		if err != nil {
+			return nil, err
+		}
+		result.QuotaExceeded = append(result.QuotaExceeded, exceeded...)
 
-		// 拡張子フィルタリング
-		if !MatchesExtensions(fileName, opts.Extensions) {
-			continue
+		// tags.tomlを読み込む（ディレクトリまたは親ディレクトリに存在する場合）
+		validator, err := newTagValidator(fsys, resolveTagsFile(fsys, dir, opts.TagsFile))
+		if err != nil {
+			// エラーがあっても続行（tags.tomlが読めない場合はタグチェックをスキップ）
+			validator = &TagValidator{validTags: map[string]bool{}}
 		}
 
-		result.TotalFiles++
+		// エントリごとの stat・パース・タグの定義チェックは先に並列で済ませ、結果の集計と出力はエントリの順に行う
+		facts := parallelMap(opts.Jobs, entries, func(entry os.DirEntry) validateEntry {
+			if ctx.Err() != nil {
+				return validateEntry{}
+			}
+			return inspectValidateEntry(fsys, dir, entry, validator, opts, maxPathSeverity != SeverityOff)
+		})
 
-		// ファイル名が正しいフォーマットかチェック
-		if IsFormatted(fileName) {
-			result.ValidFiles++
+		for i, entry := range entries {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
 
-			// タイムスタンプを抽出して重複チェック
-			if components, err := ParseFileName(fileName); err == nil {
-				timestampMap[components.Timestamp] = append(timestampMap[components.Timestamp], fileName)
-
-				// タグの定義チェック（tags.tomlが存在する場合のみ）
-				if hasTagDefinitions && len(components.Tags) > 0 {
-					var undefinedTags []string
-					for _, tag := range components.Tags {
-						if !validTags[tag] {
-							undefinedTags = append(undefinedTags, tag)
-						}
-					}
-					if len(undefinedTags) > 0 {
-						result.HasUndefinedTags = true
-						result.UndefinedTagFiles[fileName] = undefinedTags
+			// ディレクトリとシム、拡張子フィルタリングで対象外のファイルはスキップ
+			if facts[i].Skip {
+				continue
+			}
+
+			fileName := entry.Name()
+
+			// 出力に使う名前（ディレクトリ指定の場合はファイル名、パス指定の場合はパス）
+			name := fileName
+			if targets != nil {
+				name = filepath.Join(dir, fileName)
+			}
+
+			// 重複と類似タイトルは対象外のファイルとも比較する
+			components, err := facts[i].Components, facts[i].ParseErr
+			if err == nil {
+				key := filepath.Join(dir, components.Timestamp)
+				timestampMap[key] = append(timestampMap[key], name)
+
+				if title := NormalizeTitle(components.Comment); title != "" {
+					titleMap[title] = append(titleMap[title], name)
+				}
+			}
+
+			if targets != nil && !targets[name] {
+				continue
+			}
+
+			result.TotalFiles++
+
+			// ファイル名が正しいフォーマットかチェック（フォーマットに従わないファイルはこれ以上検査できない）
+			if err != nil {
+				violate(RuleFormat, name, "%s (invalid format)\n", name)
+				continue
+			}
+
+			// タイムスタンプが実在する日時かチェック
+			if err := parakeet.ValidateTimestamp(components.Timestamp); err != nil {
+				if violate(RuleTimestampValid, name, "%s (invalid timestamp: %s)\n", name, components.Timestamp) {
+					result.InvalidTimestamps = append(result.InvalidTimestamps, name)
+					continue
+				}
+			}
+
+			// コメントとタグに区切りと誤認される文字列がないかチェック
+			if err := components.Validate(); err != nil {
+				if violate(RuleCommentCharset, name, "%s (%v)\n", name, err) {
+					continue
+				}
+			}
+
+			// Windows で使えるファイル名かチェック
+			if windowsNameSeverity != SeverityOff {
+				if problem := windowsNameProblem(fileName); problem != "" && violate(RuleWindowsName, name, "%s (%s)\n", name, problem) {
+					result.NonPortableNames = append(result.NonPortableNames, name)
+					continue
+				}
+			}
+			if maxPathSeverity != SeverityOff {
+				if absPath := facts[i].AbsPath; len(absPath) > DefaultMaxPathLength {
+					if violate(RuleMaxPath, name, "%s (path is %d characters, limit %d)\n", name, len(absPath), DefaultMaxPathLength) {
+						result.NonPortableNames = append(result.NonPortableNames, name)
+						continue
 					}
 				}
 			}
-		} else {
-			result.InvalidFiles = append(result.InvalidFiles, fileName)
-			_, _ = fmt.Fprintf(opts.Writer, "✗ %s (invalid format)\n", fileName)
+
+			// ファイル名の長さの上限をチェック
+			if maxLengthSeverity != SeverityOff && len(fileName) > maxNameBytes {
+				if violate(RuleMaxLength, name, "%s (name too long: %d bytes, max %d)\n", name, len(fileName), maxNameBytes) {
+					result.LongNames = append(result.LongNames, name)
+					continue
+				}
+			}
+
+			// 設定ファイルで定義したルールをチェック（error の違反があればそこで無効とする）
+			invalid := false
+			for _, rule := range opts.CustomRules {
+				violation := rule.Check(fileName, *components)
+				if violation != "" && violateAs(rule.Severity, rule.Name, name, "%s (%s: %s)\n", name, rule.Name, violation) {
+					result.CustomRuleErrors[name] = rule.Name
+					invalid = true
+					break
+				}
+			}
+			if invalid {
+				continue
+			}
+
+			result.ValidFiles++
+
+			// タグの定義チェック（tags.tomlが存在する場合のみ）
+			if undefinedTagSeverity == SeverityOff {
+				continue
+			}
+			if undefinedTags := facts[i].UndefinedTags; len(undefinedTags) > 0 {
+				result.HasUndefinedTags = true
+				result.UndefinedTagFiles[name] = undefinedTags
+			}
 		}
 	}
 
-	// 重複チェック
-	for timestamp, files := range timestampMap {
-		if len(files) > 1 {
+	// 重複チェック（ポリシーで許可された組は除く）。出力の順序が実行ごとに変わらないようにキーの順に調べる
+	for _, key := range sortedKeys(timestampMap) {
+		if duplicateSeverity == SeverityOff {
+			break
+		}
+		files := timestampMap[key]
+		if len(files) > 1 && touched(files) && !opts.DuplicatePolicy.Allows(files) {
 			result.HasDuplicates = true
 			for _, file := range files {
 				result.DuplicateFiles = append(result.DuplicateFiles, file)
-				_, _ = fmt.Fprintf(opts.Writer, "⚠ %s (duplicate timestamp: %s)\n", file, timestamp)
+				reporter.Warnf("%s (duplicate timestamp: %s)\n", file, filepath.Base(key))
 			}
 		}
 	}
 
+	// 類似タイトルのチェック（警告のみ）
+	// 拡張子のみ異なる同じファイル名の組は、ポリシーで許可されていれば同じ文書の別形式とみなして警告しない
+	for title, files := range titleMap {
+		if DuplicatePolicyAllowSameBasename.Allows(files) && opts.DuplicatePolicy.Allows(files) {
+			continue
+		}
+		if len(files) > 1 && touched(files) {
+			result.SimilarTitleFiles[title] = files
+		}
+	}
+	for _, title := range sortedKeys(result.SimilarTitleFiles) {
+		for _, file := range result.SimilarTitleFiles[title] {
+			reporter.Warnf("%s (similar title: %s)\n", file, title)
+		}
+	}
+
 	// 未定義タグの出力
 	if result.HasUndefinedTags {
-		for fileName, tags := range result.UndefinedTagFiles {
-			_, _ = fmt.Fprintf(opts.Writer, "⚠ %s (undefined tags: %v)\n", fileName, tags)
+		for _, fileName := range sortedKeys(result.UndefinedTagFiles) {
+			reporter.Warnf("%s (undefined tags: %v)\n", fileName, result.UndefinedTagFiles[fileName])
 		}
 	}
 
+	// vault 内の wiki リンクのチェック（警告のみ）
+	if opts.Vault != "" {
+		broken, err := FindBrokenWikiLinks(opts.Vault)
+		if err != nil {
+			return nil, err
+		}
+		result.BrokenLinks = broken
+		for _, link := range broken {
+			reporter.Warnf("%s:%d (broken link: [[%s]])\n", link.File, link.Line, link.Target)
+		}
+	}
+
+	// 未定義タグのしきい値チェック
+	result.UndefinedTags = distinctTags(result.UndefinedTagFiles)
+	if result.ValidFiles > 0 {
+		result.UndefinedPercent = float64(len(result.UndefinedTagFiles)) * 100 / float64(result.ValidFiles)
+	}
+	result.UndefinedTagsFail = (undefinedTagSeverity == SeverityError && opts.TagCoverage.Exceeded(result.UndefinedPercent, len(result.UndefinedTags))) || (opts.Strict && result.HasUndefinedTags)
+	result.DuplicatesFail = result.HasDuplicates && (opts.Strict || duplicateSeverity == SeverityError)
+
 	// サマリーを出力
-	_, _ = fmt.Fprintf(opts.Writer, "\nValidation Summary:\n")
-	_, _ = fmt.Fprintf(opts.Writer, "  Total files: %d\n", result.TotalFiles)
-	_, _ = fmt.Fprintf(opts.Writer, "  Valid: %d\n", result.ValidFiles)
-	_, _ = fmt.Fprintf(opts.Writer, "  Invalid: %d\n", len(result.InvalidFiles))
-	_, _ = fmt.Fprintf(opts.Writer, "  Duplicates: %d\n", len(result.DuplicateFiles))
-	_, _ = fmt.Fprintf(opts.Writer, "  Undefined tags: %d\n", len(result.UndefinedTagFiles))
-
-	if len(result.InvalidFiles) == 0 && !result.HasDuplicates && !result.HasUndefinedTags {
-		_, _ = fmt.Fprintf(opts.Writer, "\n✓ All files are properly formatted!\n")
+	reporter.Printf("\nValidation Summary:\n")
+	reporter.Printf("  Total files: %d\n", result.TotalFiles)
+	reporter.Printf("  Valid: %d\n", result.ValidFiles)
+	reporter.Printf("  Invalid: %d\n", len(result.InvalidFiles))
+	reporter.Printf("  Invalid timestamps: %d\n", len(result.InvalidTimestamps))
+	reporter.Printf("  Duplicates: %d\n", len(result.DuplicateFiles))
+	reporter.Printf("  Undefined tags: %d\n", len(result.UndefinedTagFiles))
+	reporter.Printf("  Similar titles: %d\n", len(result.SimilarTitleFiles))
+	if maxLengthSeverity != SeverityOff {
+		reporter.Printf("  Names too long: %d\n", len(result.LongNames)+len(result.RuleWarnings[RuleMaxLength]))
+	}
+	if windowsNameSeverity != SeverityOff || maxPathSeverity != SeverityOff {
+		reporter.Printf("  Not portable: %d\n", len(result.NonPortableNames)+len(result.RuleWarnings[RuleWindowsName])+len(result.RuleWarnings[RuleMaxPath]))
+	}
+	if len(result.RuleWarnings) > 0 {
+		reporter.Printf("  Rule warnings: %d\n", countRuleWarnings(result.RuleWarnings))
+	}
+	if opts.Quota.Configured() {
+		reporter.Printf("  Quota exceeded: %d\n", len(result.QuotaExceeded))
+	}
+	if opts.Vault != "" {
+		reporter.Printf("  Broken links: %d\n", len(result.BrokenLinks))
+	}
+	if opts.TagCoverage.Configured() {
+		reporter.Printf("  Undefined tag coverage: %.1f%% of files, %d distinct tags\n", result.UndefinedPercent, len(result.UndefinedTags))
+	}
+
+	summary := map[string]any{
+		"total":              result.TotalFiles,
+		"valid":              result.ValidFiles,
+		"invalid":            len(result.InvalidFiles),
+		"invalid_timestamps": len(result.InvalidTimestamps),
+		"duplicates":         len(result.DuplicateFiles),
+		"undefined_tags":     len(result.UndefinedTagFiles),
+		"similar_titles":     len(result.SimilarTitleFiles),
+		"rule_warnings":      countRuleWarnings(result.RuleWarnings),
+	}
+	if opts.Quota.Configured() {
+		summary["quota_exceeded"] = len(result.QuotaExceeded)
+	}
+	if opts.Vault != "" {
+		summary["broken_links"] = len(result.BrokenLinks)
+	}
+	reporter.Summary("issues", summary)
+
+	if len(result.InvalidFiles) == 0 && !result.HasDuplicates && !result.HasUndefinedTags && len(result.RuleWarnings) == 0 {
+		reporter.Successf("\nAll files are properly formatted!\n")
 	} else {
 		if len(result.InvalidFiles) > 0 {
-			_, _ = fmt.Fprintf(opts.Writer, "\n✗ Some files have invalid format.\n")
+			reporter.Errorf("\nSome files have invalid format.\n")
+		}
+		if len(result.RuleWarnings) > 0 {
+			reporter.Warnf("\nSome files violate rules set to warning: %s\n", strings.Join(sortedKeys(result.RuleWarnings), ", "))
 		}
 		if result.HasDuplicates {
-			_, _ = fmt.Fprintf(opts.Writer, "\n⚠ Some files have duplicate timestamps.\n")
+			reporter.Warnf("\nSome files have duplicate timestamps.\n")
 		}
-		if result.HasUndefinedTags {
-			_, _ = fmt.Fprintf(opts.Writer, "\n⚠ Some files have undefined tags.\n")
+		if result.UndefinedTagsFail && opts.Strict {
+			reporter.Errorf("\nSome files have undefined tags (--strict).\n")
+		} else if result.UndefinedTagsFail && opts.TagCoverage.Configured() {
+			reporter.Errorf("\nUndefined tags exceed the allowed threshold.\n")
+		} else if result.HasUndefinedTags {
+			reporter.Warnf("\nSome files have undefined tags.\n")
 		}
 	}
 
+	if len(result.SimilarTitleFiles) > 0 {
+		reporter.Warnf("\nSome files have similar titles.\n")
+	}
+
+	if len(result.BrokenLinks) > 0 {
+		reporter.Warnf("\nSome wiki links do not resolve to notes.\n")
+	}
+
+	if len(result.QuotaExceeded) > 0 {
+		reporter.Printf("\n")
+		reportQuotas(reporter, result.QuotaExceeded)
+	}
+
 	return result, nil
 }
 
-// ValidateFileName は単一のファイル名をバリデーションする
-func ValidateFileName(filename string) error {
-	components, err := ParseFileName(filename)
-	if err != nil {
-		return err
+// validateEntry は validate で集計の前に調べたエントリの情報を表す
+type validateEntry struct {
+	Skip          bool                         // ディレクトリ・シム、またはフィルタに一致しないため対象外
+	Components    *parakeet.FileNameComponents // ファイル名をパースした結果
+	ParseErr      error                        // ファイル名をパースできなかった場合のエラー
+	AbsPath       string                       // max-path ルールで使う絶対パス（ルールが無効な場合と取得できない場合は空）
+	UndefinedTags []string                     // tags.toml に定義されていないタグ
+}
+
+// inspectValidateEntry はエントリが対象かどうかを調べ、ファイル名のパースとタグの定義チェックを行う
+// parallelMap から並列に呼ばれるため、validator・opts・fsys は読み込みのみ行う
+func inspectValidateEntry(fsys FileSystem, dir string, entry os.DirEntry, validator *TagValidator, opts ValidateOptions, needAbs bool) validateEntry {
+	fileName := entry.Name()
+	if entry.IsDir() || isShim(fsys, dir, entry) || !opts.Matches(fileName) {
+		return validateEntry{Skip: true}
 	}
 
-	// タイムスタンプの形式チェック（YYYYMMDDTHHMMSS）
-	if len(components.Timestamp) != 15 {
-		return fmt.Errorf("invalid timestamp length: expected 15, got %d", len(components.Timestamp))
+	components, err := parakeet.ParseFileName(fileName)
+	facts := validateEntry{Components: components, ParseErr: err}
+	if needAbs {
+		if absPath, err := fsys.Abs(filepath.Join(dir, fileName)); err == nil {
+			facts.AbsPath = absPath
+		}
+	}
+	if err == nil {
+		facts.UndefinedTags = validator.UndefinedTags(components.Tags)
 	}
+	return facts
+}
 
-	// コメントが空でないかチェック
-	if components.Comment == "" {
-		return fmt.Errorf("comment cannot be empty")
+// countRuleWarnings は警告にしたルールの違反の数を返す
+func countRuleWarnings(warnings map[string][]string) int {
+	count := 0
+	for _, files := range warnings {
+		count += len(files)
 	}
+	return count
+}
 
-	return nil
+// distinctTags はファイルごとのタグから重複を除いたタグをソートして返す
+func distinctTags(fileTags map[string][]string) []string {
+	seen := make(map[string]bool)
+	tags := []string{}
+	for _, list := range fileTags {
+		for _, tag := range list {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
 }
 
 // GetInvalidFiles はディレクトリ内の無効なファイル名のリストを返す
@@ -183,15 +547,15 @@ func GetInvalidFiles(targetDir string) ([]string, error) {
 	var invalidFiles []string
 
 	for _, entry := range entries {
-		// ディレクトリはスキップ
-		if entry.IsDir() {
+		// ディレクトリとシムはスキップ
+		if entry.IsDir() || IsShim(targetDir, entry) {
 			continue
 		}
 
 		fileName := entry.Name()
 
 		// ファイル名が正しいフォーマットかチェック
-		if !IsFormatted(fileName) {
+		if !parakeet.IsFormatted(fileName) {
 			invalidFiles = append(invalidFiles, filepath.Join(targetDir, fileName))
 		}
 	}
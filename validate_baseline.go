@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// validate を失敗させる問題の種類
+const (
+	ProblemInvalid       = "invalid"        // 無効なファイル名
+	ProblemDuplicate     = "duplicate"      // 許可されていないタイムスタンプの重複
+	ProblemUndefinedTags = "undefined_tags" // しきい値を超えた未定義タグ
+)
+
+// ValidationProblem は validate を失敗させる1件の問題を表す
+type ValidationProblem struct {
+	File   string `json:"file"`             // ファイル名（パスを指定して検証した場合はパス）
+	Kind   string `json:"kind"`             // 問題の種類
+	Detail string `json:"detail,omitempty"` // 補足（未定義タグなど）
+}
+
+// ValidateBaseline は以前の validate で見つかった問題の一覧（ベースライン）を表す
+// 既存の問題を記録しておくことで、新しく増えた問題のみで失敗させられる
+type ValidateBaseline struct {
+	Created  string              `json:"created"`  // 作成日時（RFC3339）
+	Problems []ValidationProblem `json:"problems"` // 既知の問題
+}
+
+// BaselineComparison はベースラインと現在の検証結果の比較を表す
+type BaselineComparison struct {
+	New   []ValidationProblem // ベースラインにない新しい問題
+	Known int                 // ベースラインにもある問題の数
+	Fixed int                 // ベースラインにあり、現在は解消した問題の数
+}
+
+// HasErrors は新しい問題があるかどうかを返す
+func (c *BaselineComparison) HasErrors() bool {
+	return len(c.New) > 0
+}
+
+// Problems は validate を失敗させる問題を種類・ファイル名順に返す
+// 重複と未定義タグは、設定により失敗とする場合のみ含める
+func (r *ValidateResult) Problems() []ValidationProblem {
+	invalidTimestamps := make(map[string]bool)
+	for _, file := range r.InvalidTimestamps {
+		invalidTimestamps[file] = true
+	}
+	longNames := make(map[string]bool)
+	for _, file := range r.LongNames {
+		longNames[file] = true
+	}
+	nonPortable := make(map[string]bool)
+	for _, file := range r.NonPortableNames {
+		nonPortable[file] = true
+	}
+
+	problems := []ValidationProblem{}
+	for _, file := range r.InvalidFiles {
+		problem := ValidationProblem{File: file, Kind: ProblemInvalid}
+		if invalidTimestamps[file] {
+			problem.Detail = "invalid timestamp"
+		} else if longNames[file] {
+			problem.Detail = "name too long"
+		} else if nonPortable[file] {
+			problem.Detail = "not portable"
+		} else if rule, ok := r.CustomRuleErrors[file]; ok {
+			problem.Detail = "rule: " + rule
+		}
+		problems = append(problems, problem)
+	}
+
+	if r.DuplicatesFail {
+		for _, file := range r.DuplicateFiles {
+			problems = append(problems, ValidationProblem{File: file, Kind: ProblemDuplicate})
+		}
+	}
+
+	if r.UndefinedTagsFail {
+		for file, tags := range r.UndefinedTagFiles {
+			problems = append(problems, ValidationProblem{File: file, Kind: ProblemUndefinedTags, Detail: strings.Join(tags, ",")})
+		}
+	}
+
+	sort.Slice(problems, func(i, j int) bool {
+		if problems[i].Kind != problems[j].Kind {
+			return problems[i].Kind < problems[j].Kind
+		}
+		return problems[i].File < problems[j].File
+	})
+
+	return problems
+}
+
+// LoadValidateBaseline はベースラインのJSONファイルを読み込む
+func LoadValidateBaseline(path string) (*ValidateBaseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("baseline does not exist: %s (create it with --update-baseline)", path)
+		}
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	var baseline ValidateBaseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline: %w", err)
+	}
+
+	return &baseline, nil
+}
+
+// WriteValidateBaseline は検証結果の問題をベースラインとしてJSONファイルに書き出す
+func WriteValidateBaseline(path string, result *ValidateResult) (*ValidateBaseline, error) {
+	baseline := &ValidateBaseline{
+		Created:  time.Now().Format(time.RFC3339),
+		Problems: result.Problems(),
+	}
+
+	data, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode baseline: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write baseline: %w", err)
+	}
+
+	return baseline, nil
+}
+
+// CompareBaseline は現在の検証結果をベースラインと比較し、新しく増えた問題を出力する
+// 問題はファイル名と種類の組で照合する
+func CompareBaseline(result *ValidateResult, baseline *ValidateBaseline, w io.Writer) *BaselineComparison {
+	reporter := ReporterFor(w)
+
+	key := func(p ValidationProblem) string {
+		return p.Kind + "\x00" + p.File
+	}
+
+	known := make(map[string]bool)
+	for _, problem := range baseline.Problems {
+		known[key(problem)] = true
+	}
+
+	comparison := &BaselineComparison{
+		New: []ValidationProblem{},
+	}
+	for _, problem := range result.Problems() {
+		if known[key(problem)] {
+			comparison.Known++
+			delete(known, key(problem))
+			continue
+		}
+		comparison.New = append(comparison.New, problem)
+		reporter.Errorf("%s (new problem: %s)\n", problem.File, problem.Kind)
+	}
+	comparison.Fixed = len(known)
+
+	// サマリーを出力
+	reporter.Printf("\nBaseline Summary:\n")
+	reporter.Printf("  New problems: %d\n", len(comparison.New))
+	reporter.Printf("  Known problems: %d\n", comparison.Known)
+	reporter.Printf("  Fixed since baseline: %d\n", comparison.Fixed)
+
+	if comparison.HasErrors() {
+		reporter.Errorf("\nNew problems were introduced since the baseline.\n")
+	} else {
+		reporter.Successf("\nNo new problems since the baseline.\n")
+	}
+
+	return comparison
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateResult_Problems(t *testing.T) {
+	t.Parallel()
+	result := &ValidateResult{
+		InvalidFiles:      []string{"b.pdf", "20251399T256161--a.pdf"},
+		InvalidTimestamps: []string{"20251399T256161--a.pdf"},
+		DuplicateFiles:    []string{"20250903T083109--x.pdf", "20250903T083109--y.pdf"},
+		UndefinedTagFiles: map[string][]string{"20250903T083110--z__foo_bar.pdf": {"foo", "bar"}},
+	}
+
+	// 失敗としない重複と未定義タグは含めない
+	assert.Equal(t, []ValidationProblem{
+		{File: "20251399T256161--a.pdf", Kind: ProblemInvalid, Detail: "invalid timestamp"},
+		{File: "b.pdf", Kind: ProblemInvalid},
+	}, result.Problems())
+
+	result.DuplicatesFail = true
+	result.UndefinedTagsFail = true
+	assert.Equal(t, []ValidationProblem{
+		{File: "20250903T083109--x.pdf", Kind: ProblemDuplicate},
+		{File: "20250903T083109--y.pdf", Kind: ProblemDuplicate},
+		{File: "20251399T256161--a.pdf", Kind: ProblemInvalid, Detail: "invalid timestamp"},
+		{File: "b.pdf", Kind: ProblemInvalid},
+		{File: "20250903T083110--z__foo_bar.pdf", Kind: ProblemUndefinedTags, Detail: "foo,bar"},
+	}, result.Problems())
+}
+
+func TestValidateBaseline(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-baseline-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	baselinePath := filepath.Join(tmpDir, "report.json")
+	for _, name := range []string{"20250903T083109--valid.pdf", "legacy one.pdf", "legacy two.pdf"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("test"), 0644))
+	}
+
+	opts := ValidateOptions{Writer: &bytes.Buffer{}, FilterOptions: FilterOptions{Extensions: []string{"pdf"}}}
+	result, err := ValidateFileNames(context.Background(), tmpDir, opts)
+	require.NoError(t, err)
+	require.True(t, result.HasErrors())
+
+	written, err := WriteValidateBaseline(baselinePath, result)
+	require.NoError(t, err)
+	assert.Len(t, written.Problems, 2)
+
+	baseline, err := LoadValidateBaseline(baselinePath)
+	require.NoError(t, err)
+	assert.Equal(t, written.Problems, baseline.Problems)
+
+	t.Run("only known problems", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		comparison := CompareBaseline(result, baseline, buf)
+		assert.False(t, comparison.HasErrors())
+		assert.Equal(t, 2, comparison.Known)
+		assert.Contains(t, buf.String(), "No new problems since the baseline.")
+	})
+
+	t.Run("new and fixed problems", func(t *testing.T) {
+		current := &ValidateResult{InvalidFiles: []string{"legacy one.pdf", "new.pdf"}}
+		buf := &bytes.Buffer{}
+		comparison := CompareBaseline(current, baseline, buf)
+		assert.True(t, comparison.HasErrors())
+		assert.Equal(t, []ValidationProblem{{File: "new.pdf", Kind: ProblemInvalid}}, comparison.New)
+		assert.Equal(t, 1, comparison.Known)
+		assert.Equal(t, 1, comparison.Fixed)
+
+		output := buf.String()
+		assert.Contains(t, output, "new.pdf (new problem: invalid)")
+		assert.Contains(t, output, "Fixed since baseline: 1")
+	})
+}
+
+func TestLoadValidateBaseline_Errors(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-baseline-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	_, err = LoadValidateBaseline(filepath.Join(tmpDir, "missing.json"))
+	assert.ErrorContains(t, err, "baseline does not exist")
+
+	brokenPath := filepath.Join(tmpDir, "broken.json")
+	require.NoError(t, os.WriteFile(brokenPath, []byte("{"), 0644))
+	_, err = LoadValidateBaseline(brokenPath)
+	assert.ErrorContains(t, err, "failed to parse baseline")
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// CustomRule は parakeet.toml の [[custom_rule]] で定義する validate のルールを表す
+// 対象のファイル（Extensions）の、コメント・タグ・タグの数に対する条件を指定する
+type CustomRule struct {
+	Name        string   `toml:"name"`        // ルール名（報告用、組み込みのルールIDとは別にする）
+	Description string   `toml:"description"` // 違反したときに表示する説明（空の場合は条件から作る）
+	Extensions  []string `toml:"extensions"`  // 対象拡張子（空の場合はすべてのファイル）
+	Comment     string   `toml:"comment"`     // コメントが一致しなければならない正規表現（例: ^[a-z0-9]+(-[a-z0-9]+)*$）
+	Tag         string   `toml:"tag"`         // すべてのタグが一致しなければならない正規表現
+	MinTags     int      `toml:"min_tags"`    // タグの数の下限
+	Severity    Severity `toml:"severity"`    // 違反の重さ（空の場合は error）
+
+	comment *regexp.Regexp
+	tag     *regexp.Regexp
+}
+
+// CompileCustomRules はルールを検証し、正規表現をコンパイルしたルールを返す
+func CompileCustomRules(rules []CustomRule) ([]CustomRule, error) {
+	compiled := make([]CustomRule, 0, len(rules))
+	seen := make(map[string]bool)
+	for _, rule := range rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("custom rule has no name")
+		}
+		if _, ok := findValidationRule(rule.Name); ok || seen[rule.Name] {
+			return nil, fmt.Errorf("duplicate rule name: %s", rule.Name)
+		}
+		seen[rule.Name] = true
+
+		if rule.Severity == "" {
+			rule.Severity = SeverityError
+		}
+		if _, err := ParseSeverity(string(rule.Severity)); err != nil {
+			return nil, fmt.Errorf("rule %s: %w", rule.Name, err)
+		}
+
+		if rule.Comment == "" && rule.Tag == "" && rule.MinTags <= 0 {
+			return nil, fmt.Errorf("rule %s has no conditions (comment, tag or min_tags)", rule.Name)
+		}
+
+		var err error
+		if rule.Comment != "" {
+			if rule.comment, err = regexp.Compile(rule.Comment); err != nil {
+				return nil, fmt.Errorf("rule %s: invalid comment pattern: %w", rule.Name, err)
+			}
+		}
+		if rule.Tag != "" {
+			if rule.tag, err = regexp.Compile(rule.Tag); err != nil {
+				return nil, fmt.Errorf("rule %s: invalid tag pattern: %w", rule.Name, err)
+			}
+		}
+
+		compiled = append(compiled, rule)
+	}
+	return compiled, nil
+}
+
+// Check はファイル名の構成要素がルールに違反しているかを調べ、違反している場合はその説明を返す
+// 対象外のファイルとルールを満たすファイルは空文字列を返す
+func (r CustomRule) Check(fileName string, components parakeet.FileNameComponents) string {
+	if r.Severity == SeverityOff || !parakeet.MatchesExtensions(fileName, r.Extensions) {
+		return ""
+	}
+
+	violation := ""
+	switch {
+	case r.comment != nil && !r.comment.MatchString(components.Comment):
+		violation = fmt.Sprintf("comment does not match %s", r.Comment)
+	case len(components.Tags) < r.MinTags:
+		violation = fmt.Sprintf("%d tags, requires at least %d", len(components.Tags), r.MinTags)
+	case r.tag != nil:
+		for _, tag := range components.Tags {
+			if !r.tag.MatchString(tag) {
+				violation = fmt.Sprintf("tag %s does not match %s", tag, r.Tag)
+				break
+			}
+		}
+	}
+
+	if violation != "" && r.Description != "" {
+		return r.Description
+	}
+	return violation
+}
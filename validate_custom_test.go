@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileCustomRules(t *testing.T) {
+	t.Parallel()
+
+	rules, err := CompileCustomRules([]CustomRule{{Name: "kebab-case", Comment: `^[a-z0-9]+(-[a-z0-9]+)*$`}})
+	require.NoError(t, err)
+	assert.Equal(t, SeverityError, rules[0].Severity)
+
+	errorTests := []struct {
+		name     string
+		rules    []CustomRule
+		expected string
+	}{
+		{"名前なし", []CustomRule{{MinTags: 1}}, "no name"},
+		{"組み込みのルールIDと同じ名前", []CustomRule{{Name: RuleFormat, MinTags: 1}}, "duplicate rule name"},
+		{"条件なし", []CustomRule{{Name: "empty"}}, "no conditions"},
+		{"不正な正規表現", []CustomRule{{Name: "broken", Comment: "("}}, "invalid comment pattern"},
+		{"不正な重さ", []CustomRule{{Name: "fatal", MinTags: 1, Severity: "fatal"}}, "unknown severity"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := CompileCustomRules(tt.rules)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestCustomRule_Check(t *testing.T) {
+	t.Parallel()
+
+	rules, err := CompileCustomRules([]CustomRule{
+		{Name: "kebab-case", Comment: `^[a-z0-9]+(-[a-z0-9]+)*$`},
+		{Name: "pdf-tagged", Extensions: []string{"pdf"}, MinTags: 1, Description: "pdf files must carry at least one tag"},
+		{Name: "lower-tags", Tag: `^[a-z]+$`},
+	})
+	require.NoError(t, err)
+	kebab, pdfTagged, lowerTags := rules[0], rules[1], rules[2]
+
+	check := func(rule CustomRule, fileName string) string {
+		components, err := parakeet.ParseFileName(fileName)
+		require.NoError(t, err)
+		return rule.Check(fileName, *components)
+	}
+
+	assert.Empty(t, check(kebab, "20250903T083109--tcp-ip-notes.md"))
+	assert.Equal(t, "comment does not match ^[a-z0-9]+(-[a-z0-9]+)*$", check(kebab, "20250903T083109--TCP IP notes.md"))
+	assert.Equal(t, "pdf files must carry at least one tag", check(pdfTagged, "20250903T083109--report.pdf"))
+	assert.Empty(t, check(pdfTagged, "20250903T083109--report__work.pdf"))
+	assert.Empty(t, check(pdfTagged, "20250903T083109--memo.md"), "対象外の拡張子")
+	assert.Equal(t, "tag Go does not match ^[a-z]+$", check(lowerTags, "20250903T083109--memo__Go_work.md"))
+}
+
+func TestValidateFileNames_CustomRules(t *testing.T) {
+	t.Parallel()
+
+	tmpDir, err := os.MkdirTemp("", "parakeet-validate-custom-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	for _, name := range []string{"20250903T083109--report.pdf", "20250903T083110--Meeting Notes.md", "20250903T083111--ok__work.pdf"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("content"), 0644))
+	}
+
+	rules, err := CompileCustomRules([]CustomRule{
+		{Name: "pdf-tagged", Extensions: []string{"pdf"}, MinTags: 1},
+		{Name: "kebab-case", Comment: `^[a-z0-9]+(-[a-z0-9]+)*$`, Severity: SeverityWarning},
+	})
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	result, err := ValidateFileNames(context.Background(), tmpDir, ValidateOptions{Writer: buf, CustomRules: rules})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"20250903T083109--report.pdf"}, result.InvalidFiles)
+	assert.Equal(t, map[string]string{"20250903T083109--report.pdf": "pdf-tagged"}, result.CustomRuleErrors)
+	assert.Equal(t, map[string][]string{"kebab-case": {"20250903T083110--Meeting Notes.md"}}, result.RuleWarnings)
+	assert.Equal(t, 2, result.ValidFiles)
+	assert.Contains(t, buf.String(), "20250903T083109--report.pdf (pdf-tagged: 0 tags, requires at least 1)")
+
+	problems := result.Problems()
+	require.Len(t, problems, 1)
+	assert.Equal(t, "rule: pdf-tagged", problems[0].Detail)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Severity は validate のルールに違反した場合の重さを表す
+type Severity string
+
+// ルールの違反の重さ
+const (
+	SeverityError   Severity = "error"   // validate を失敗させる
+	SeverityWarning Severity = "warning" // 警告のみ（--fail-on warning の場合は失敗させる）
+	SeverityOff     Severity = "off"     // 検査しない
+)
+
+// ParseSeverity は文字列からルールの違反の重さを取得する
+func ParseSeverity(s string) (Severity, error) {
+	switch severity := Severity(s); severity {
+	case SeverityError, SeverityWarning, SeverityOff:
+		return severity, nil
+	default:
+		return "", fmt.Errorf("unknown severity: %s (expected error, warning or off)", s)
+	}
+}
+
+// validate のルールID
+const (
+	RuleFormat         = "format"          // ファイル名がフォーマットに従っている
+	RuleTimestampValid = "timestamp-valid" // タイムスタンプが実在する日時
+	RuleDuplicate      = "duplicate"       // タイムスタンプが重複していない（duplicate_policy で許可した組は除く）
+	RuleUndefinedTag   = "undefined-tag"   // タグが tags.toml に定義されている（[validate] のしきい値を超えた場合のみ失敗）
+	RuleCommentCharset = "comment-charset" // コメントとタグに区切りと誤認される文字列がない
+	RuleMaxLength      = "max-length"      // ファイル名が max_name_bytes 以下
+	RuleWindowsName    = "windows-name"    // ファイル名が Windows で使える（使えない文字・予約されたデバイス名・末尾のドットと空白がない）
+	RuleMaxPath        = "max-path"        // 絶対パスが Windows の MAX_PATH（260文字）以下
+)
+
+// ValidationRule は validate のルールを表す
+type ValidationRule struct {
+	ID       string   // ルールID
+	Usage    string   // 説明
+	Severity Severity // デフォルトの重さ
+}
+
+// ValidationRules は validate のルールの一覧
+var ValidationRules = []ValidationRule{
+	{RuleFormat, "ファイル名がフォーマットに従っている", SeverityError},
+	{RuleTimestampValid, "タイムスタンプが実在する日時", SeverityError},
+	{RuleDuplicate, "タイムスタンプが重複していない（duplicate_policy が warn の場合は warning）", SeverityError},
+	{RuleUndefinedTag, "タグが tags.toml に定義されている（error の場合も [validate] のしきい値を超えた場合のみ失敗）", SeverityError},
+	{RuleCommentCharset, "コメントとタグに区切りと誤認される文字列がない", SeverityError},
+	{RuleMaxLength, "ファイル名が max_name_bytes 以下", SeverityOff},
+	{RuleWindowsName, "ファイル名が Windows で使える（Windows 以外では --portable で有効）", portableSeverity()},
+	{RuleMaxPath, "絶対パスが MAX_PATH 以下（Windows 以外では --portable で有効）", portableSeverity()},
+}
+
+// PortableRules は --portable で有効にするルール
+var PortableRules = []string{RuleWindowsName, RuleMaxPath}
+
+// findValidationRule はIDのルールを返す
+func findValidationRule(id string) (ValidationRule, bool) {
+	for _, rule := range ValidationRules {
+		if rule.ID == id {
+			return rule, true
+		}
+	}
+	return ValidationRule{}, false
+}
+
+// RuleSeverities はルールごとの重さの設定を表す（parakeet.toml の [rules]）
+// 設定がないルールはデフォルトの重さを使う
+type RuleSeverities map[string]Severity
+
+// Validate は設定にあるルールIDと重さが正しいかを確認する
+func (s RuleSeverities) Validate() error {
+	for _, id := range sortedRuleIDs(s) {
+		if _, ok := findValidationRule(id); !ok {
+			return fmt.Errorf("unknown validation rule: %s (expected %s)", id, ruleIDList())
+		}
+		if _, err := ParseSeverity(string(s[id])); err != nil {
+			return fmt.Errorf("rule %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// Severity はルールの重さを返す
+func (s RuleSeverities) Severity(id string) Severity {
+	if severity, ok := s[id]; ok {
+		return severity
+	}
+	rule, _ := findValidationRule(id)
+	return rule.Severity
+}
+
+// With は enable のルールを有効にし（デフォルトが off のルールは error）、disable のルールを off にした設定を返す
+func (s RuleSeverities) With(enable, disable []string) (RuleSeverities, error) {
+	merged := make(RuleSeverities, len(s))
+	for id, severity := range s {
+		merged[id] = severity
+	}
+
+	for _, id := range enable {
+		rule, ok := findValidationRule(id)
+		if !ok {
+			return nil, fmt.Errorf("unknown validation rule: %s (expected %s)", id, ruleIDList())
+		}
+		if merged.Severity(id) != SeverityOff {
+			continue
+		}
+		if rule.Severity != SeverityOff {
+			merged[id] = rule.Severity
+		} else {
+			merged[id] = SeverityError
+		}
+	}
+
+	for _, id := range disable {
+		if _, ok := findValidationRule(id); !ok {
+			return nil, fmt.Errorf("unknown validation rule: %s (expected %s)", id, ruleIDList())
+		}
+		merged[id] = SeverityOff
+	}
+
+	return merged, nil
+}
+
+// ruleIDList はルールIDをカンマ区切りで返す
+func ruleIDList() string {
+	ids := make([]string, 0, len(ValidationRules))
+	for _, rule := range ValidationRules {
+		ids = append(ids, rule.ID)
+	}
+	return strings.Join(ids, ", ")
+}
+
+// sortedRuleIDs は設定にあるルールIDをソートして返す
+func sortedRuleIDs(s RuleSeverities) []string {
+	ids := make([]string, 0, len(s))
+	for id := range s {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
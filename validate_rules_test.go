@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleSeverities_With(t *testing.T) {
+	t.Parallel()
+
+	rules := RuleSeverities{RuleDuplicate: SeverityWarning}
+	merged, err := rules.With([]string{RuleMaxLength, RuleDuplicate}, []string{RuleUndefinedTag})
+	require.NoError(t, err)
+
+	assert.Equal(t, SeverityError, merged.Severity(RuleMaxLength), "デフォルトが off のルールは error で有効にする")
+	assert.Equal(t, SeverityWarning, merged.Severity(RuleDuplicate), "有効なルールの重さは変えない")
+	assert.Equal(t, SeverityOff, merged.Severity(RuleUndefinedTag))
+	assert.Equal(t, SeverityError, merged.Severity(RuleFormat))
+	assert.Equal(t, SeverityWarning, rules[RuleDuplicate], "元の設定は変えない")
+	assert.NotContains(t, rules, RuleMaxLength)
+
+	_, err = rules.With([]string{"unknown"}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown validation rule: unknown")
+}
+
+func TestRuleSeverities_Validate(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, RuleSeverities{RuleMaxLength: SeverityWarning}.Validate())
+	assert.Error(t, RuleSeverities{"kebab-case": SeverityError}.Validate())
+	assert.Error(t, RuleSeverities{RuleFormat: "fatal"}.Validate())
+}
+
+func TestLoadConfig_Rules(t *testing.T) {
+	t.Parallel()
+
+	tmpDir, err := os.MkdirTemp("", "parakeet-test-rules-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	path := filepath.Join(tmpDir, ConfigFileName)
+	require.NoError(t, os.WriteFile(path, []byte("[rules]\nmax-length = \"warning\"\nduplicate = \"off\"\n"), 0644))
+	config, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, RuleSeverities{RuleMaxLength: SeverityWarning, RuleDuplicate: SeverityOff}, config.Rules)
+
+	require.NoError(t, os.WriteFile(path, []byte("[rules]\nformat = \"fatal\"\n"), 0644))
+	_, err = LoadConfig(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid [rules]")
+}
+
+func TestValidateFileNames_RuleSeverities(t *testing.T) {
+	t.Parallel()
+
+	longName := "20250903T083112--" + strings.Repeat("a", 40) + ".md"
+	files := []string{
+		"invalid.pdf",
+		"20251399T256161--bad-date.pdf",
+		"20250903T083110--memo.txt",
+		"20250903T083110--other.txt",
+		longName,
+	}
+
+	tests := []struct {
+		name         string
+		rules        RuleSeverities
+		invalid      int
+		warnings     map[string]int
+		hasErrors    bool
+		hasWarnings  bool
+		duplicates   bool
+		summaryLines []string
+	}{
+		{
+			name:        "デフォルト",
+			invalid:     2,
+			warnings:    map[string]int{},
+			hasErrors:   true,
+			hasWarnings: false,
+			duplicates:  true,
+		},
+		{
+			name:         "format と timestamp-valid を警告にする",
+			rules:        RuleSeverities{RuleFormat: SeverityWarning, RuleTimestampValid: SeverityWarning, RuleDuplicate: SeverityWarning},
+			invalid:      0,
+			warnings:     map[string]int{RuleFormat: 1, RuleTimestampValid: 1},
+			hasErrors:    false,
+			hasWarnings:  true,
+			duplicates:   true,
+			summaryLines: []string{"Rule warnings: 2", "violate rules set to warning: format, timestamp-valid"},
+		},
+		{
+			name:        "ルールを無効にする",
+			rules:       RuleSeverities{RuleFormat: SeverityOff, RuleTimestampValid: SeverityOff, RuleDuplicate: SeverityOff},
+			invalid:     0,
+			warnings:    map[string]int{},
+			hasErrors:   false,
+			hasWarnings: false,
+			duplicates:  false,
+		},
+		{
+			name:         "max-length を有効にする",
+			rules:        RuleSeverities{RuleFormat: SeverityOff, RuleTimestampValid: SeverityOff, RuleDuplicate: SeverityOff, RuleMaxLength: SeverityError},
+			invalid:      1,
+			warnings:     map[string]int{},
+			hasErrors:    true,
+			summaryLines: []string{"Names too long: 1", "(name too long: 60 bytes, max 50)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir, err := os.MkdirTemp("", "parakeet-validate-rules-*")
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+			for _, name := range files {
+				require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("content"), 0644))
+			}
+
+			buf := &bytes.Buffer{}
+			opts := ValidateOptions{
+				Writer:       buf,
+				Rules:        tt.rules,
+				MaxNameBytes: 50,
+			}
+			result, err := ValidateFileNames(context.Background(), tmpDir, opts)
+			require.NoError(t, err)
+
+			assert.Len(t, result.InvalidFiles, tt.invalid)
+			warnings := map[string]int{}
+			for rule, files := range result.RuleWarnings {
+				warnings[rule] = len(files)
+			}
+			assert.Equal(t, tt.warnings, warnings)
+			assert.Equal(t, tt.hasErrors, result.HasErrors())
+			assert.Equal(t, tt.hasWarnings, result.HasWarnings())
+			assert.Equal(t, tt.duplicates, result.HasDuplicates)
+			for _, line := range tt.summaryLines {
+				assert.Contains(t, buf.String(), line)
+			}
+		})
+	}
+}
+
+func TestValidateFileNames_UndefinedTagSeverity(t *testing.T) {
+	t.Parallel()
+
+	tmpDir, err := os.MkdirTemp("", "parakeet-validate-undefined-rule-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "tags.toml"), []byte("[[tag]]\nkey = \"go\"\ndesc = \"Go\"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250903T083109--memo__unknown.md"), []byte("content"), 0644))
+
+	result, err := ValidateFileNames(context.Background(), tmpDir, ValidateOptions{Writer: &bytes.Buffer{}})
+	require.NoError(t, err)
+	assert.True(t, result.UndefinedTagsFail)
+
+	result, err = ValidateFileNames(context.Background(), tmpDir, ValidateOptions{Writer: &bytes.Buffer{}, Rules: RuleSeverities{RuleUndefinedTag: SeverityWarning}})
+	require.NoError(t, err)
+	assert.True(t, result.HasUndefinedTags)
+	assert.False(t, result.UndefinedTagsFail)
+	assert.True(t, result.HasWarnings())
+
+	result, err = ValidateFileNames(context.Background(), tmpDir, ValidateOptions{Writer: &bytes.Buffer{}, Rules: RuleSeverities{RuleUndefinedTag: SeverityOff}})
+	require.NoError(t, err)
+	assert.False(t, result.HasUndefinedTags)
+	assert.False(t, result.HasErrors())
+}
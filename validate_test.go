@@ -2,6 +2,8 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
@@ -86,11 +88,11 @@ func TestValidateFileNames(t *testing.T) {
 			// Run validation
 			buf := &bytes.Buffer{}
 			opts := ValidateOptions{
-				Writer:     buf,
-				Extensions: nil,
+				Writer:        buf,
+				FilterOptions: FilterOptions{Extensions: nil},
 			}
 
-			result, err := ValidateFileNames(tmpDir, opts)
+			result, err := ValidateFileNames(context.Background(), tmpDir, opts)
 			require.NoError(t, err)
 			require.NotNil(t, result)
 
@@ -106,16 +108,15 @@ func TestValidateFileNames(t *testing.T) {
 	}
 }
 
-
 func TestValidateFileNames_NonExistentDirectory(t *testing.T) {
 	t.Parallel()
 	buf := &bytes.Buffer{}
 	opts := ValidateOptions{
-		Writer:     buf,
-		Extensions: nil,
+		Writer:        buf,
+		FilterOptions: FilterOptions{Extensions: nil},
 	}
 
-	result, err := ValidateFileNames("/non/existent/directory", opts)
+	result, err := ValidateFileNames(context.Background(), "/non/existent/directory", opts)
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "directory does not exist")
@@ -141,11 +142,11 @@ func TestValidateFileNames_SkipsDirectories(t *testing.T) {
 	// Run validation
 	buf := &bytes.Buffer{}
 	opts := ValidateOptions{
-		Writer:     buf,
-		Extensions: nil,
+		Writer:        buf,
+		FilterOptions: FilterOptions{Extensions: nil},
 	}
 
-	result, err := ValidateFileNames(tmpDir, opts)
+	result, err := ValidateFileNames(context.Background(), tmpDir, opts)
 	require.NoError(t, err)
 	require.NotNil(t, result)
 
@@ -154,48 +155,6 @@ func TestValidateFileNames_SkipsDirectories(t *testing.T) {
 	assert.Equal(t, 1, result.ValidFiles, "File should be valid")
 }
 
-func TestValidateFileName(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		name     string
-		filename string
-		wantErr  bool
-	}{
-		{
-			name:     "valid filename",
-			filename: "20250903T083109--test__tag1.txt",
-			wantErr:  false,
-		},
-		{
-			name:     "valid filename without tags",
-			filename: "20250903T083109--test.txt",
-			wantErr:  false,
-		},
-		{
-			name:     "invalid filename format",
-			filename: "invalid.txt",
-			wantErr:  true,
-		},
-		{
-			name:     "invalid timestamp",
-			filename: "2025--test.txt",
-			wantErr:  true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
-			err := ValidateFileName(tt.filename)
-			if tt.wantErr {
-				assert.Error(t, err)
-			} else {
-				assert.NoError(t, err)
-			}
-		})
-	}
-}
-
 func TestGetInvalidFiles(t *testing.T) {
 	t.Parallel()
 	// Create temporary directory
@@ -291,11 +250,11 @@ func TestValidateFileNames_WithExtensionFilter(t *testing.T) {
 	// Test with extension filter (txt, pdf only)
 	buf := &bytes.Buffer{}
 	opts := ValidateOptions{
-		Writer:     buf,
-		Extensions: []string{"txt", "pdf"},
+		Writer:        buf,
+		FilterOptions: FilterOptions{Extensions: []string{"txt", "pdf"}},
 	}
 
-	result, err := ValidateFileNames(tmpDir, opts)
+	result, err := ValidateFileNames(context.Background(), tmpDir, opts)
 	require.NoError(t, err)
 	require.NotNil(t, result)
 
@@ -336,11 +295,11 @@ func TestValidateFileNames_AllValidOutput(t *testing.T) {
 	// Run validation
 	buf := &bytes.Buffer{}
 	opts := ValidateOptions{
-		Writer:     buf,
-		Extensions: nil,
+		Writer:        buf,
+		FilterOptions: FilterOptions{Extensions: nil},
 	}
 
-	result, err := ValidateFileNames(tmpDir, opts)
+	result, err := ValidateFileNames(context.Background(), tmpDir, opts)
 	require.NoError(t, err)
 	require.NotNil(t, result)
 
@@ -357,6 +316,34 @@ func TestValidateFileNames_AllValidOutput(t *testing.T) {
 	assert.Contains(t, output, "Invalid: 0", "Should show zero invalid")
 }
 
+func TestValidateFileNames_JSONOutput(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-validate-json-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	for _, name := range []string{"20250903T083109--document.pdf", "no-format.doc"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("content"), 0644))
+	}
+
+	var buf bytes.Buffer
+	reporter, err := NewReporter(&buf, ReporterConfig{Format: FormatJSON})
+	require.NoError(t, err)
+	_, err = ValidateFileNames(context.Background(), tmpDir, ValidateOptions{Writer: reporter})
+	require.NoError(t, err)
+	require.NoError(t, reporter.Flush())
+
+	var output struct {
+		Issues  []map[string]any `json:"issues"`
+		Summary map[string]int   `json:"summary"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &output))
+	assert.Contains(t, output.Issues, map[string]any{"event": "error", "message": "no-format.doc (invalid format)"})
+	assert.Equal(t, 2, output.Summary["total"])
+	assert.Equal(t, 1, output.Summary["valid"])
+	assert.Equal(t, 1, output.Summary["invalid"])
+}
+
 func TestValidateFileNames_CaseInsensitiveExtension(t *testing.T) {
 	t.Parallel()
 	// Create temporary directory
@@ -381,11 +368,11 @@ func TestValidateFileNames_CaseInsensitiveExtension(t *testing.T) {
 	// Test with lowercase extension filter
 	buf := &bytes.Buffer{}
 	opts := ValidateOptions{
-		Writer:     buf,
-		Extensions: []string{"pdf", "txt"},
+		Writer:        buf,
+		FilterOptions: FilterOptions{Extensions: []string{"pdf", "txt"}},
 	}
 
-	result, err := ValidateFileNames(tmpDir, opts)
+	result, err := ValidateFileNames(context.Background(), tmpDir, opts)
 	require.NoError(t, err)
 	require.NotNil(t, result)
 
@@ -409,10 +396,10 @@ func TestValidateFileNames_WithDuplicateTimestamps(t *testing.T) {
 	// Create files with duplicate timestamps
 	testFiles := []string{
 		"20250903T083109--file1.txt",
-		"20250903T083109--file2.pdf",  // 同じタイムスタンプ
+		"20250903T083109--file2.pdf", // 同じタイムスタンプ
 		"20250903T083110--file3.doc",
-		"20250903T083110--file4.jpg",  // 同じタイムスタンプ
-		"20250903T083111--file5.md",   // ユニーク
+		"20250903T083110--file4.jpg", // 同じタイムスタンプ
+		"20250903T083111--file5.md",  // ユニーク
 	}
 
 	for _, name := range testFiles {
@@ -424,11 +411,11 @@ func TestValidateFileNames_WithDuplicateTimestamps(t *testing.T) {
 	// Run validation
 	buf := &bytes.Buffer{}
 	opts := ValidateOptions{
-		Writer:     buf,
-		Extensions: nil,
+		Writer:        buf,
+		FilterOptions: FilterOptions{Extensions: nil},
 	}
 
-	result, err := ValidateFileNames(tmpDir, opts)
+	result, err := ValidateFileNames(context.Background(), tmpDir, opts)
 	require.NoError(t, err)
 	require.NotNil(t, result)
 
@@ -469,11 +456,11 @@ func TestValidateFileNames_NoDuplicates(t *testing.T) {
 	// Run validation
 	buf := &bytes.Buffer{}
 	opts := ValidateOptions{
-		Writer:     buf,
-		Extensions: nil,
+		Writer:        buf,
+		FilterOptions: FilterOptions{Extensions: nil},
 	}
 
-	result, err := ValidateFileNames(tmpDir, opts)
+	result, err := ValidateFileNames(context.Background(), tmpDir, opts)
 	require.NoError(t, err)
 	require.NotNil(t, result)
 
@@ -516,10 +503,10 @@ desc = "Security related"
 
 	// Create files with valid and invalid tags
 	testFiles := []string{
-		"20250903T083109--file1__network_infra.txt",      // 定義済みタグ
-		"20250903T083110--file2__undefined_tag.pdf",      // 未定義タグ
-		"20250903T083111--file3__security.doc",           // 定義済みタグ
-		"20250903T083112--file4__network_invalid.jpg",   // 1つ定義済み、1つ未定義
+		"20250903T083109--file1__network_infra.txt",   // 定義済みタグ
+		"20250903T083110--file2__undefined_tag.pdf",   // 未定義タグ
+		"20250903T083111--file3__security.doc",        // 定義済みタグ
+		"20250903T083112--file4__network_invalid.jpg", // 1つ定義済み、1つ未定義
 	}
 
 	for _, name := range testFiles {
@@ -531,18 +518,18 @@ desc = "Security related"
 	// Run validation
 	buf := &bytes.Buffer{}
 	opts := ValidateOptions{
-		Writer:     buf,
-		Extensions: nil,
+		Writer:        buf,
+		FilterOptions: FilterOptions{Extensions: nil},
 	}
 
-	result, err := ValidateFileNames(tmpDir, opts)
+	result, err := ValidateFileNames(context.Background(), tmpDir, opts)
 	require.NoError(t, err)
 	require.NotNil(t, result)
 
-	// Check result (tags.toml is counted as invalid file)
-	assert.Equal(t, 5, result.TotalFiles, "Should count 4 test files + tags.toml")
+	// Check result (tags.toml is skipped as a metadata file)
+	assert.Equal(t, 4, result.TotalFiles, "Should count 4 test files")
 	assert.Equal(t, 4, result.ValidFiles)
-	assert.Equal(t, 1, len(result.InvalidFiles), "tags.toml is invalid format")
+	assert.Empty(t, result.InvalidFiles, "tags.toml is not reported as invalid format")
 	assert.True(t, result.HasUndefinedTags, "Should detect undefined tags")
 	assert.Equal(t, 2, len(result.UndefinedTagFiles), "Should have 2 files with undefined tags")
 
@@ -562,6 +549,65 @@ desc = "Security related"
 	assert.Contains(t, output, "Undefined tags: 2", "Should show undefined tag count")
 }
 
+func TestValidateFileNames_TagCoverage(t *testing.T) {
+	t.Parallel()
+	// Create temporary directory
+	tmpDir, err := os.MkdirTemp("", "parakeet-validate-coverage-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "tags.toml"), []byte("[[tag]]\nkey = \"network\"\ndesc = \"Network related\"\n"), 0644))
+
+	testFiles := []string{
+		"20250903T083109--file1__network.txt",
+		"20250903T083110--file2__draft.txt",
+		"20250903T083111--file3__draft_todo.txt",
+		"20250903T083112--file4.txt",
+	}
+	for _, name := range testFiles {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("content"), 0644))
+	}
+
+	percent := 50.0
+	count := 1
+
+	tests := []struct {
+		name     string
+		rule     TagCoverageRule
+		strict   bool
+		expected bool
+	}{
+		{name: "no threshold", rule: TagCoverageRule{}, expected: true},
+		{name: "percent within", rule: TagCoverageRule{MaxUndefinedPercent: &percent}, expected: false},
+		{name: "distinct tags exceeded", rule: TagCoverageRule{MaxUndefinedTags: &count}, expected: true},
+		{name: "percent within but strict", rule: TagCoverageRule{MaxUndefinedPercent: &percent}, strict: true, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			buf := &bytes.Buffer{}
+			result, err := ValidateFileNames(context.Background(), tmpDir, ValidateOptions{
+				Writer:        buf,
+				FilterOptions: FilterOptions{Extensions: []string{"txt"}},
+				TagCoverage:   tt.rule,
+				Strict:        tt.strict,
+			})
+			require.NoError(t, err)
+
+			assert.True(t, result.HasUndefinedTags)
+			assert.Equal(t, []string{"draft", "todo"}, result.UndefinedTags)
+			assert.Equal(t, 50.0, result.UndefinedPercent)
+			assert.Equal(t, tt.expected, result.UndefinedTagsFail)
+			assert.Equal(t, tt.expected, result.HasErrors())
+
+			if tt.rule.Configured() {
+				assert.Contains(t, buf.String(), "Undefined tag coverage: 50.0% of files, 2 distinct tags")
+			}
+		})
+	}
+}
+
 func TestValidateFileNames_NoTagToml(t *testing.T) {
 	t.Parallel()
 	// Create temporary directory
@@ -584,11 +630,11 @@ func TestValidateFileNames_NoTagToml(t *testing.T) {
 	// Run validation
 	buf := &bytes.Buffer{}
 	opts := ValidateOptions{
-		Writer:     buf,
-		Extensions: nil,
+		Writer:        buf,
+		FilterOptions: FilterOptions{Extensions: nil},
 	}
 
-	result, err := ValidateFileNames(tmpDir, opts)
+	result, err := ValidateFileNames(context.Background(), tmpDir, opts)
 	require.NoError(t, err)
 	require.NotNil(t, result)
 
@@ -602,3 +648,207 @@ func TestValidateFileNames_NoTagToml(t *testing.T) {
 	output := buf.String()
 	assert.Contains(t, output, "All files are properly formatted", "Should show success message")
 }
+
+func TestValidateFileNames_SimilarTitles(t *testing.T) {
+	t.Parallel()
+	// Create temporary directory
+	tmpDir, err := os.MkdirTemp("", "parakeet-validate-similar-*")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	testFiles := []string{
+		"20250903T083109--The TCP-IP Guide.pdf",
+		"20250903T083110--tcp ip guide__network.pdf",
+		"20250903T083111--other.pdf",
+	}
+	for _, name := range testFiles {
+		err := os.WriteFile(filepath.Join(tmpDir, name), []byte("test content"), 0644)
+		require.NoError(t, err)
+	}
+
+	buf := &bytes.Buffer{}
+	result, err := ValidateFileNames(context.Background(), tmpDir, ValidateOptions{Writer: buf})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string][]string{
+		"tcp ip guide": {"20250903T083109--The TCP-IP Guide.pdf", "20250903T083110--tcp ip guide__network.pdf"},
+	}, result.SimilarTitleFiles)
+
+	output := buf.String()
+	assert.Contains(t, output, "(similar title: tcp ip guide)")
+	assert.Contains(t, output, "Similar titles: 1")
+	// 類似タイトルは警告のみで、フォーマットは有効
+	assert.Contains(t, output, "All files are properly formatted!")
+}
+
+func TestValidateFileNames_InvalidTimestamps(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-validate-timestamp-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	testFiles := []string{
+		"20250903T083109--valid.pdf",
+		"20251399T256161--bogus date.pdf",
+		"2025--short.pdf",
+		"invalid.pdf",
+	}
+	for _, name := range testFiles {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("test content"), 0644))
+	}
+
+	buf := &bytes.Buffer{}
+	result, err := ValidateFileNames(context.Background(), tmpDir, ValidateOptions{Writer: buf})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, result.ValidFiles)
+	assert.ElementsMatch(t, []string{"20251399T256161--bogus date.pdf", "2025--short.pdf", "invalid.pdf"}, result.InvalidFiles)
+	assert.ElementsMatch(t, []string{"20251399T256161--bogus date.pdf", "2025--short.pdf"}, result.InvalidTimestamps)
+	assert.True(t, result.HasErrors())
+
+	output := buf.String()
+	assert.Contains(t, output, "20251399T256161--bogus date.pdf (invalid timestamp: 20251399T256161)")
+	assert.Contains(t, output, "invalid.pdf (invalid format)")
+	assert.Contains(t, output, "Invalid timestamps: 2")
+}
+
+func TestValidateFileNames_ReservedSeparators(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-validate-separator-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	testFiles := []string{
+		"20250903T083109--valid__tag1.pdf",
+		"20250903T083110--a--b.pdf",
+		"20250903T083111--draft__.pdf",
+	}
+	for _, name := range testFiles {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("test content"), 0644))
+	}
+
+	buf := &bytes.Buffer{}
+	result, err := ValidateFileNames(context.Background(), tmpDir, ValidateOptions{Writer: buf})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, result.ValidFiles)
+	assert.ElementsMatch(t, []string{"20250903T083110--a--b.pdf", "20250903T083111--draft__.pdf"}, result.InvalidFiles)
+	assert.Empty(t, result.InvalidTimestamps)
+	assert.Contains(t, buf.String(), "20250903T083110--a--b.pdf (reserved separator in comment: \"a--b\" contains \"--\")")
+	assert.Contains(t, buf.String(), "20250903T083111--draft__.pdf (reserved separator in tags: empty tag)")
+}
+
+func TestValidateFilePaths(t *testing.T) {
+	t.Parallel()
+	// Create temporary directory
+	tmpDir, err := os.MkdirTemp("", "parakeet-validate-paths-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	docsDir := filepath.Join(tmpDir, "docs")
+	require.NoError(t, os.MkdirAll(docsDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(docsDir, "tags.toml"), []byte("[[tag]]\nkey = \"network\"\ndesc = \"Network related\"\n"), 0644))
+
+	testFiles := []string{
+		filepath.Join(tmpDir, "untouched-invalid.pdf"),
+		filepath.Join(tmpDir, "20250903T083109--touched.pdf"),
+		filepath.Join(docsDir, "20250903T083110--existing.pdf"),
+		filepath.Join(docsDir, "20250903T083110--new__network_draft.pdf"),
+		filepath.Join(docsDir, "invalid.pdf"),
+	}
+	for _, path := range testFiles {
+		require.NoError(t, os.WriteFile(path, []byte("content"), 0644))
+	}
+
+	paths := []string{
+		testFiles[1],
+		testFiles[3],
+		testFiles[4],
+		filepath.Join(tmpDir, "deleted.pdf"), // 削除されたファイルは無視する
+		docsDir,                              // ディレクトリは無視する
+	}
+
+	buf := &bytes.Buffer{}
+	result, err := ValidateFilePaths(context.Background(), paths, ValidateOptions{Writer: buf})
+	require.NoError(t, err)
+
+	// 指定したファイルのみ検証する
+	assert.Equal(t, 3, result.TotalFiles)
+	assert.Equal(t, 2, result.ValidFiles)
+	assert.Equal(t, []string{testFiles[4]}, result.InvalidFiles)
+
+	// 重複は同じディレクトリの既存ファイルとも比較する
+	assert.True(t, result.HasDuplicates)
+	assert.ElementsMatch(t, []string{testFiles[2], testFiles[3]}, result.DuplicateFiles)
+
+	// タグはファイルと同じディレクトリの tags.toml で検証する
+	assert.Equal(t, map[string][]string{testFiles[3]: {"draft"}}, result.UndefinedTagFiles)
+
+	output := buf.String()
+	assert.NotContains(t, output, "untouched-invalid.pdf")
+	assert.Contains(t, output, testFiles[4]+" (invalid format)")
+}
+
+func TestValidateFileNames_DuplicatePolicy(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-validate-dup-policy-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	testFiles := []string{
+		"20250903T083109--report.md",
+		"20250903T083109--report.pdf", // 添付ファイル（拡張子以外が同じ）
+		"20250903T083110--memo.txt",
+		"20250903T083110--other.txt", // 偶然の重複
+	}
+	for _, name := range testFiles {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("content"), 0644))
+	}
+
+	// similarTitle は拡張子のみ異なる report.md と report.pdf を類似タイトルとして警告するかどうか
+	tests := []struct {
+		policy       DuplicatePolicy
+		duplicates   []string
+		hasErrors    bool
+		similarTitle bool
+	}{
+		{policy: DuplicatePolicyError, duplicates: testFiles, hasErrors: true, similarTitle: true},
+		{policy: DuplicatePolicyWarn, duplicates: testFiles, hasErrors: false, similarTitle: true},
+		{policy: DuplicatePolicyAllowSameBasename, duplicates: testFiles[2:], hasErrors: true, similarTitle: false},
+		{policy: DuplicatePolicyAllowAll, duplicates: []string{}, hasErrors: false, similarTitle: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			t.Parallel()
+			result, err := ValidateFileNames(context.Background(), tmpDir, ValidateOptions{Writer: &bytes.Buffer{}, DuplicatePolicy: tt.policy})
+			require.NoError(t, err)
+
+			assert.ElementsMatch(t, tt.duplicates, result.DuplicateFiles)
+			assert.Equal(t, tt.hasErrors, result.HasErrors())
+			_, similar := result.SimilarTitleFiles["report"]
+			assert.Equal(t, tt.similarTitle, similar)
+		})
+	}
+}
+
+func TestReadPathList(t *testing.T) {
+	t.Parallel()
+	paths, err := ReadPathList(strings.NewReader("a.pdf\n\n  docs/b.md  \n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.pdf", "docs/b.md"}, paths)
+}
+
+func TestValidateFileNames_Canceled(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-validate-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250903T083109--a.pdf"), []byte("test"), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ValidateFileNames(ctx, tmpDir, ValidateOptions{Writer: &bytes.Buffer{}})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// VerifyOptions はチェックサムの検証操作のオプションを表す
+type VerifyOptions struct {
+	Writer io.Writer // 出力先
+	FilterOptions
+	Jobs int // ハッシュを並列に計算する数（1 以下の場合は並列にしない）
+}
+
+// VerifyResult はチェックサムの検証操作の結果を表す
+type VerifyResult struct {
+	Verified  int               // ハッシュが一致したファイル数
+	Modified  []string          // 更新日時またはサイズが変わり、内容が変わったファイル
+	Corrupted []string          // 更新日時とサイズが変わらないのに内容が変わったファイル（ビット腐敗の疑い）
+	Renamed   map[string]string // 内容が同じでファイル名が変わったファイル: 記録時のファイル名 -> 現在のファイル名
+	Missing   []string          // 記録したがなくなったファイル
+	Unindexed []string          // チェックサムを記録していないファイル（記録後に追加したファイル）
+}
+
+// HasErrors は内容が変わったファイルがあるかどうかを返す
+func (r *VerifyResult) HasErrors() bool {
+	return len(r.Modified) > 0 || len(r.Corrupted) > 0
+}
+
+// VerifyChecksums はディレクトリ直下のフォーマット済みファイルのハッシュを計算し直し、
+// reindex --checksums で記録したハッシュと比べて、記録後の内容の変更やビット腐敗を報告する
+// 記録したファイルと内容が同じでファイル名だけが変わったファイルはリネームとみなす
+func VerifyChecksums(targetDir string, opts VerifyOptions) (*VerifyResult, error) {
+	reporter := ReporterFor(opts.Writer)
+
+	// ディレクトリの存在チェック
+	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", targetDir)
+	}
+
+	cache, err := loadDirCache(targetDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	recorded := make(map[string]DirCacheEntry)
+	if cache != nil {
+		for _, entry := range cache.Entries {
+			if entry.SHA256 != "" && opts.Matches(entry.Name) {
+				recorded[entry.Name] = entry
+			}
+		}
+	}
+	if len(recorded) == 0 {
+		return nil, fmt.Errorf("no checksums recorded in %s: run reindex --checksums first", DirCachePath(targetDir))
+	}
+
+	entries, err := os.ReadDir(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	result := &VerifyResult{
+		Modified:  []string{},
+		Corrupted: []string{},
+		Renamed:   make(map[string]string),
+		Missing:   []string{},
+		Unindexed: []string{},
+	}
+
+	// 対象のファイルのハッシュを並列に計算し、結果はディレクトリの順に比べる
+	targets := []os.DirEntry{}
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && opts.Matches(entry.Name()) && parakeet.IsFormatted(entry.Name()) {
+			targets = append(targets, entry)
+		}
+	}
+	type hashed struct {
+		sum string
+		err error
+	}
+	sums := parallelMap(opts.Jobs, targets, func(entry os.DirEntry) hashed {
+		sum, err := hashFile(filepath.Join(targetDir, entry.Name()))
+		return hashed{sum: sum, err: err}
+	})
+
+	seen := make(map[string]bool)
+	unindexed := make(map[string]string) // 記録していないファイル: ファイル名 -> ハッシュ
+	for i, entry := range targets {
+		fileName := entry.Name()
+		sum, err := sums[i].sum, sums[i].err
+		if err != nil {
+			return nil, err
+		}
+
+		record, ok := recorded[fileName]
+		if !ok {
+			unindexed[fileName] = sum
+			continue
+		}
+		seen[fileName] = true
+
+		if sum == record.SHA256 {
+			result.Verified++
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get file info: %w", err)
+		}
+		if info.Size() == record.Size && info.ModTime().Equal(record.ModTime) {
+			reporter.Errorf("%s (content changed without modification time change, possible bit rot)\n", fileName)
+			result.Corrupted = append(result.Corrupted, fileName)
+		} else {
+			reporter.Errorf("%s (content changed since last reindex)\n", fileName)
+			result.Modified = append(result.Modified, fileName)
+		}
+	}
+
+	// なくなったファイルと同じ内容の記録していないファイルはリネームとみなす
+	missingBySum := make(map[string][]string)
+	for _, name := range sortedKeys(recorded) {
+		if !seen[name] {
+			missingBySum[recorded[name].SHA256] = append(missingBySum[recorded[name].SHA256], name)
+		}
+	}
+	for _, name := range sortedKeys(unindexed) {
+		if candidates := missingBySum[unindexed[name]]; len(candidates) > 0 {
+			result.Renamed[candidates[0]] = name
+			missingBySum[unindexed[name]] = candidates[1:]
+			result.Verified++
+			continue
+		}
+		reporter.Warnf("%s (no checksum recorded)\n", name)
+		result.Unindexed = append(result.Unindexed, name)
+	}
+	for _, names := range missingBySum {
+		result.Missing = append(result.Missing, names...)
+	}
+	sort.Strings(result.Missing)
+	for _, name := range result.Missing {
+		reporter.Warnf("%s (recorded but missing)\n", name)
+	}
+
+	// サマリーを出力
+	reporter.Printf("\nVerify Summary:\n")
+	reporter.Printf("  Verified: %d\n", result.Verified)
+	reporter.Printf("  Modified: %d\n", len(result.Modified))
+	reporter.Printf("  Possible bit rot: %d\n", len(result.Corrupted))
+	reporter.Printf("  Renamed: %d\n", len(result.Renamed))
+	reporter.Printf("  Missing: %d\n", len(result.Missing))
+	reporter.Printf("  Not indexed: %d\n", len(result.Unindexed))
+
+	if result.HasErrors() {
+		reporter.Errorf("\nSome files have changed since the checksums were recorded.\n")
+	} else {
+		reporter.Successf("\nAll recorded checksums match!\n")
+	}
+
+	return result, nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kijimaD/parakeet/pkg/parakeet"
+)
+
+// VerifyLinksOptions はリンク検証操作のオプションを表す
+type VerifyLinksOptions struct {
+	Writer io.Writer // 出力先
+	Dir    string    // IDを検索するディレクトリ（空の場合はMarkdownファイルのディレクトリ）
+}
+
+// DanglingLink は解決できなかったリンクを表す
+type DanglingLink struct {
+	Line   int    // 行番号（1始まり）
+	Target string // リンク先のパスまたはID
+	Reason string // 解決できなかった理由
+}
+
+// VerifyLinksResult はリンク検証操作の結果を表す
+type VerifyLinksResult struct {
+	Checked  int            // 検証したリンクとIDの数
+	Dangling []DanglingLink // 解決できなかったリンク
+}
+
+// HasDangling は解決できなかったリンクがあるかどうかを返す
+func (r *VerifyLinksResult) HasDangling() bool {
+	return len(r.Dangling) > 0
+}
+
+var (
+	// markdownLinkPattern はMarkdownのリンク・画像のリンク先
+	markdownLinkPattern = regexp.MustCompile(`!?\[[^\]]*\]\(([^)\s]+)(?:\s+"[^"]*")?\)`)
+	// tableIDPattern は表の先頭列にあるID
+	tableIDPattern = regexp.MustCompile(`^\|\s*(\d{8}T\d{6}(?:,\d{3})?)\s*\|`)
+	// urlSchemePattern は外部リンクのスキーム
+	urlSchemePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+)
+
+// VerifyLinks はMarkdownファイル内のリンクとIDがファイルに解決できるかを検証する
+// 相対パスのリンクはファイルの存在を、表の先頭列のIDはディレクトリ内のファイルの存在を確認する
+// 外部URLとページ内リンクは検証しない
+func VerifyLinks(markdownPath string, opts VerifyLinksOptions) (*VerifyLinksResult, error) {
+	reporter := ReporterFor(opts.Writer)
+	reporter.Columns("dangling", "line", "target", "reason")
+
+	file, err := os.Open(markdownPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	baseDir := filepath.Dir(markdownPath)
+	idDir := opts.Dir
+	if idDir == "" {
+		idDir = baseDir
+	}
+
+	ids, err := CollectExistingTimestamps(idDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect existing timestamps: %w", err)
+	}
+
+	result := &VerifyLinksResult{
+		Dangling: []DanglingLink{},
+	}
+	addDangling := func(line int, target, reason string) {
+		result.Dangling = append(result.Dangling, DanglingLink{Line: line, Target: target, Reason: reason})
+		reporter.Emit("dangling", map[string]any{"line": line, "target": target, "reason": reason}, "✗ %s:%d: %s (%s)\n", markdownPath, line, target, reason)
+	}
+
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+
+		// 表の先頭列のIDをチェック
+		if match := tableIDPattern.FindStringSubmatch(line); match != nil {
+			result.Checked++
+			if !ids[match[1]] {
+				addDangling(lineNumber, match[1], "no file with this ID")
+			}
+		}
+
+		// 相対パスのリンクをチェック
+		for _, match := range markdownLinkPattern.FindAllStringSubmatch(line, -1) {
+			target := match[1]
+			if strings.HasPrefix(target, "#") || urlSchemePattern.MatchString(target) {
+				continue
+			}
+
+			// ページ内のアンカーを除き、パーセントエンコーディングを戻す
+			if i := strings.Index(target, "#"); i >= 0 {
+				target = target[:i]
+			}
+			if unescaped, err := url.PathUnescape(target); err == nil {
+				target = unescaped
+			}
+
+			result.Checked++
+			if reason := checkLinkTarget(filepath.Join(baseDir, filepath.FromSlash(target))); reason != "" {
+				addDangling(lineNumber, match[1], reason)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	// サマリーを出力
+	reporter.Printf("\nVerify Links Summary:\n")
+	reporter.Printf("  Checked: %d\n", result.Checked)
+	reporter.Printf("  Dangling: %d\n", len(result.Dangling))
+
+	if result.HasDangling() {
+		reporter.Errorf("\nSome links do not resolve to existing files.\n")
+	} else {
+		reporter.Successf("\nAll links resolve to existing files.\n")
+	}
+
+	return result, nil
+}
+
+// checkLinkTarget はリンク先のファイルが存在するかを確認し、存在しない場合は理由を返す
+// パスが見つからなくても同じIDのファイルがあれば、リネームされたファイル名を理由に含める
+func checkLinkTarget(path string) string {
+	if _, err := os.Stat(path); err == nil {
+		return ""
+	}
+
+	components, err := parakeet.ParseFileName(filepath.Base(path))
+	if err != nil {
+		return "file not found"
+	}
+
+	if current, err := FindFileByID(filepath.Dir(path), components.Timestamp); err == nil {
+		return fmt.Sprintf("file not found, ID now points to %s", filepath.Base(current))
+	}
+	return "file not found"
+}
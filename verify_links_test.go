@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyLinks(t *testing.T) {
+	t.Parallel()
+
+	tmpDir, err := os.MkdirTemp("", "parakeet-verify-links-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	require.NoError(t, os.Mkdir(filepath.Join(tmpDir, "docs"), 0755))
+	testFiles := []string{
+		"20250903T083109--TCPIP入門__network.pdf",
+		"20250903T083110--dns__infra.pdf",
+		"docs/guide.md",
+	}
+	for _, name := range testFiles {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("x"), 0644))
+	}
+
+	markdown := `# Index
+
+- [TCP](20250903T083109--TCPIP%E5%85%A5%E9%96%80__network.pdf)
+- [Guide](docs/guide.md#intro)
+- [External](https://example.com/missing.pdf)
+- [Top](#index)
+- [Renamed](20250903T083110--old-title__infra.pdf)
+- [Missing](missing.md)
+
+| ID | Title | Tags |
+|---|---|---|
+| 20250903T083109 | TCPIP入門 | network |
+| 20990101T000000 | gone |  |
+`
+	markdownPath := filepath.Join(tmpDir, DefaultIndexFileName)
+	require.NoError(t, os.WriteFile(markdownPath, []byte(markdown), 0644))
+
+	buf := &bytes.Buffer{}
+	result, err := VerifyLinks(markdownPath, VerifyLinksOptions{Writer: buf})
+	require.NoError(t, err)
+
+	assert.Equal(t, 6, result.Checked)
+	assert.True(t, result.HasDangling())
+	assert.Equal(t, []DanglingLink{
+		{Line: 7, Target: "20250903T083110--old-title__infra.pdf", Reason: "file not found, ID now points to 20250903T083110--dns__infra.pdf"},
+		{Line: 8, Target: "missing.md", Reason: "file not found"},
+		{Line: 13, Target: "20990101T000000", Reason: "no file with this ID"},
+	}, result.Dangling)
+	assert.Contains(t, buf.String(), "Dangling: 3")
+}
+
+func TestVerifyLinks_AllResolve(t *testing.T) {
+	t.Parallel()
+
+	tmpDir, err := os.MkdirTemp("", "parakeet-verify-links-ok-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250903T083109--TCPIP入門__network.pdf"), []byte("x"), 0644))
+	require.NoError(t, UpdateIndexFile(tmpDir, IndexOptions{Writer: &bytes.Buffer{}}))
+
+	buf := &bytes.Buffer{}
+	result, err := VerifyLinks(filepath.Join(tmpDir, DefaultIndexFileName), VerifyLinksOptions{Writer: buf})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, result.Checked)
+	assert.False(t, result.HasDangling())
+	assert.Contains(t, buf.String(), "All links resolve")
+
+	// ファイルがなくなると検出される
+	require.NoError(t, os.Remove(filepath.Join(tmpDir, "20250903T083109--TCPIP入門__network.pdf")))
+	result, err = VerifyLinks(filepath.Join(tmpDir, DefaultIndexFileName), VerifyLinksOptions{Writer: &bytes.Buffer{}})
+	require.NoError(t, err)
+	assert.Len(t, result.Dangling, 1)
+}
+
+func TestVerifyLinks_MissingFile(t *testing.T) {
+	t.Parallel()
+
+	_, err := VerifyLinks(filepath.Join(t.TempDir(), "missing.md"), VerifyLinksOptions{Writer: &bytes.Buffer{}})
+	assert.Error(t, err)
+}
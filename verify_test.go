@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyChecksums(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-verify-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	mtime := time.Date(2025, 9, 3, 8, 31, 9, 0, time.Local)
+	files := []string{"20250903T083109--ok.pdf", "20250903T083110--edited.pdf", "20250903T083111--rotten.pdf", "20250903T083112--old-name.pdf", "20250903T083113--deleted.pdf"}
+	for i, name := range files {
+		path := filepath.Join(tmpDir, name)
+		require.NoError(t, os.WriteFile(path, []byte{'a' + byte(i), 'x'}, 0644))
+		require.NoError(t, os.Chtimes(path, mtime, mtime))
+	}
+
+	// チェックサムがない場合はエラー
+	_, err = VerifyChecksums(tmpDir, VerifyOptions{Writer: &bytes.Buffer{}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "run reindex --checksums first")
+
+	cache, err := Reindex(tmpDir, ReindexOptions{Writer: &bytes.Buffer{}, Checksums: true, Jobs: 4})
+	require.NoError(t, err)
+	checksums := 0
+	for _, entry := range cache.Entries {
+		if entry.SHA256 != "" {
+			assert.Len(t, entry.SHA256, 64, entry.Name)
+			checksums++
+		}
+	}
+	assert.Equal(t, len(files), checksums)
+
+	// 記録後に内容を変更・破損・リネーム・削除・追加する
+	later := mtime.Add(time.Hour)
+	edited := filepath.Join(tmpDir, "20250903T083110--edited.pdf")
+	require.NoError(t, os.WriteFile(edited, []byte("edited content"), 0644))
+	require.NoError(t, os.Chtimes(edited, later, later))
+	rotten := filepath.Join(tmpDir, "20250903T083111--rotten.pdf")
+	require.NoError(t, os.WriteFile(rotten, []byte("zz"), 0644))
+	require.NoError(t, os.Chtimes(rotten, mtime, mtime))
+	require.NoError(t, os.Rename(filepath.Join(tmpDir, "20250903T083112--old-name.pdf"), filepath.Join(tmpDir, "20250903T083112--new-name.pdf")))
+	require.NoError(t, os.Remove(filepath.Join(tmpDir, "20250903T083113--deleted.pdf")))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "20250903T083114--added.pdf"), []byte("new"), 0644))
+
+	buf := &bytes.Buffer{}
+	result, err := VerifyChecksums(tmpDir, VerifyOptions{Writer: buf, Jobs: 4})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, result.Verified)
+	assert.Equal(t, []string{"20250903T083110--edited.pdf"}, result.Modified)
+	assert.Equal(t, []string{"20250903T083111--rotten.pdf"}, result.Corrupted)
+	assert.Equal(t, map[string]string{"20250903T083112--old-name.pdf": "20250903T083112--new-name.pdf"}, result.Renamed)
+	assert.Equal(t, []string{"20250903T083113--deleted.pdf"}, result.Missing)
+	assert.Equal(t, []string{"20250903T083114--added.pdf"}, result.Unindexed)
+	assert.True(t, result.HasErrors())
+	assert.Contains(t, buf.String(), "possible bit rot")
+
+	// 更新日時とサイズが変わっていないファイルは前回のハッシュを引き継ぐため、破損は reindex 後も検出する
+	_, err = Reindex(tmpDir, ReindexOptions{Writer: &bytes.Buffer{}})
+	require.NoError(t, err)
+	result, err = VerifyChecksums(tmpDir, VerifyOptions{Writer: &bytes.Buffer{}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"20250903T083111--rotten.pdf"}, result.Corrupted)
+	assert.Empty(t, result.Modified)
+	assert.Empty(t, result.Unindexed)
+}
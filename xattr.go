@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"strings"
+)
+
+// ファイルマネージャのタグを保存する拡張属性
+const (
+	xdgTagsAttr    = "user.xdg.tags"                       // Linux（Nautilus, Dolphin など）: カンマ区切りのタグ
+	finderTagsAttr = "com.apple.metadata:_kMDItemUserTags" // macOS（Finder）: 文字列の配列のバイナリプロパティリスト
+)
+
+// ErrXattrUnsupported は拡張属性のタグに対応していないプラットフォームの場合のエラー
+var ErrXattrUnsupported = errors.New("extended attribute tags are not supported on this platform")
+
+// WriteXattrTags はタグをファイルの拡張属性（Linux では user.xdg.tags、macOS では Finder のタグ）に書き込む
+// タグが空の場合は拡張属性を削除する。macOS では既存の Finder のタグの色を引き継ぐ
+func WriteXattrTags(path string, tags []string) error {
+	return writeXattrTags(path, tags)
+}
+
+// ReadXattrTags はファイルの拡張属性からタグを読み込む（拡張属性がない場合は空）
+// Finder のタグの色の番号は取り除き、タグ名のみを返す
+func ReadXattrTags(path string) ([]string, error) {
+	return readXattrTags(path)
+}
+
+// formatXDGTags はタグを user.xdg.tags の値（カンマ区切り）にする
+func formatXDGTags(tags []string) string {
+	return strings.Join(tags, ",")
+}
+
+// parseXDGTags は user.xdg.tags の値からタグを取り出す
+func parseXDGTags(value string) []string {
+	tags := []string{}
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// finderTagName は Finder のタグ（"名前\n色の番号"）から名前を取り出す
+func finderTagName(entry string) string {
+	name, _, _ := strings.Cut(entry, "\n")
+	return name
+}
+
+// finderTagEntries はタグを Finder のタグにする。existing に同じ名前のタグがある場合は色の番号を引き継ぐ
+func finderTagEntries(tags, existing []string) []string {
+	colors := make(map[string]string)
+	for _, entry := range existing {
+		colors[finderTagName(entry)] = entry
+	}
+
+	entries := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if entry, ok := colors[tag]; ok {
+			entries = append(entries, entry)
+		} else {
+			entries = append(entries, tag)
+		}
+	}
+	return entries
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+// writeXattrTags はタグを Finder のタグに書き込む（既存のタグの色は引き継ぐ）
+func writeXattrTags(path string, tags []string) error {
+	if len(tags) == 0 {
+		if err := unix.Removexattr(path, finderTagsAttr); err != nil && !errors.Is(err, unix.ENOATTR) {
+			return err
+		}
+		return nil
+	}
+
+	existing, err := readFinderTagEntries(path)
+	if err != nil {
+		existing = nil
+	}
+	return unix.Setxattr(path, finderTagsAttr, encodeStringArrayPlist(finderTagEntries(tags, existing)), 0)
+}
+
+// readXattrTags は Finder のタグの名前を読み込む
+func readXattrTags(path string) ([]string, error) {
+	entries, err := readFinderTagEntries(path)
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		tags = append(tags, finderTagName(entry))
+	}
+	return tags, nil
+}
+
+// readFinderTagEntries は Finder のタグ（"名前\n色の番号"）を読み込む
+func readFinderTagEntries(path string) ([]string, error) {
+	size, err := unix.Getxattr(path, finderTagsAttr, nil)
+	if errors.Is(err, unix.ENOATTR) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	value := make([]byte, size)
+	if size, err = unix.Getxattr(path, finderTagsAttr, value); err != nil {
+		return nil, err
+	}
+	return decodeStringArrayPlist(value[:size])
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+// writeXattrTags はタグを user.xdg.tags に書き込む
+func writeXattrTags(path string, tags []string) error {
+	if len(tags) == 0 {
+		if err := unix.Removexattr(path, xdgTagsAttr); err != nil && !errors.Is(err, unix.ENODATA) {
+			return err
+		}
+		return nil
+	}
+	return unix.Setxattr(path, xdgTagsAttr, []byte(formatXDGTags(tags)), 0)
+}
+
+// readXattrTags は user.xdg.tags からタグを読み込む
+func readXattrTags(path string) ([]string, error) {
+	value, err := getXattr(path, xdgTagsAttr)
+	if err != nil || value == nil {
+		return []string{}, err
+	}
+	return parseXDGTags(string(value)), nil
+}
+
+// getXattr は拡張属性の値を読み込む（拡張属性がない場合は nil）
+func getXattr(path, attr string) ([]byte, error) {
+	size, err := unix.Getxattr(path, attr, nil)
+	if errors.Is(err, unix.ENODATA) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	value := make([]byte, size)
+	size, err = unix.Getxattr(path, attr, value)
+	if err != nil {
+		return nil, err
+	}
+	return value[:size], nil
+}
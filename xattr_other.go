@@ -0,0 +1,13 @@
+//go:build !linux && !darwin
+
+package main
+
+// writeXattrTags は拡張属性のタグに対応していないため常にエラーを返す
+func writeXattrTags(string, []string) error {
+	return ErrXattrUnsupported
+}
+
+// readXattrTags は拡張属性のタグに対応していないため常にエラーを返す
+func readXattrTags(string) ([]string, error) {
+	return nil, ErrXattrUnsupported
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestXDGTags(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "go,network", formatXDGTags([]string{"go", "network"}))
+	assert.Equal(t, []string{"go", "network"}, parseXDGTags("go, network,,"))
+	assert.Equal(t, []string{}, parseXDGTags(""))
+}
+
+func TestFinderTagEntries(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "work", finderTagName("work\n4"))
+	assert.Equal(t, "work", finderTagName("work"))
+
+	// 既存のタグの色を引き継ぐ
+	entries := finderTagEntries([]string{"network", "work"}, []string{"work\n4", "old\n2"})
+	assert.Equal(t, []string{"network", "work\n4"}, entries)
+}
+
+func TestSetTags_Xattr(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := os.MkdirTemp("", "parakeet-xattr-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	probe := filepath.Join(tmpDir, "probe")
+	require.NoError(t, os.WriteFile(probe, []byte("test"), 0644))
+	if err := WriteXattrTags(probe, []string{"probe"}); errors.Is(err, ErrXattrUnsupported) || errors.Is(err, syscall.ENOTSUP) {
+		t.Skipf("extended attributes are not supported: %v", err)
+	}
+
+	filePath := filepath.Join(tmpDir, "20250903T083109--memo.md")
+	require.NoError(t, os.WriteFile(filePath, []byte("test"), 0644))
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, SetTags(filePath, []string{"work", "network"}, TagOptions{Writer: buf, Xattr: true}))
+	newPath := filepath.Join(tmpDir, "20250903T083109--memo__network_work.md")
+	tags, err := ReadXattrTags(newPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"network", "work"}, tags)
+	assert.NotContains(t, buf.String(), "failed to mirror")
+
+	// タグを外すと拡張属性も削除する
+	require.NoError(t, SetTags(newPath, []string{}, TagOptions{Writer: buf, Xattr: true}))
+	tags, err = ReadXattrTags(filepath.Join(tmpDir, "20250903T083109--memo.md"))
+	require.NoError(t, err)
+	assert.Empty(t, tags)
+
+	// Xattr が false の場合は書き込まない
+	require.NoError(t, SetTags(filepath.Join(tmpDir, "20250903T083109--memo.md"), []string{"work"}, TagOptions{Writer: buf}))
+	tags, err = ReadXattrTags(filepath.Join(tmpDir, "20250903T083109--memo__work.md"))
+	require.NoError(t, err)
+	assert.Empty(t, tags)
+}